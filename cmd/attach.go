@@ -67,7 +67,7 @@ func init() {
 
 // runAttach runs the attach modctl.
 func runAttach(ctx context.Context, filepath string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -32,16 +32,34 @@ var buildConfig = config.NewBuild()
 
 // buildCmd represents the modctl command for build.
 var buildCmd = &cobra.Command{
-	Use:               "build [flags] <path>",
-	Short:             "Build the model artifact with the context by specified path.",
+	Use:   "build [flags] <path>",
+	Short: "Build the model artifact with the context by specified path, which can be a directory or an uncompressed tarball.",
+	Example: `  # Build from the local directory
+  modctl build -t example.com/models/llama:v1 ./llama
+
+  # Download the model from HuggingFace and build it by the generated Modelfile, the argument is the target
+  modctl build --from-hf meta-llama/Llama-3.2-1B example.com/models/llama:v1`,
 	Args:              cobra.ExactArgs(1),
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// the argument is the target instead of the path when building from HuggingFace.
+		if buildConfig.FromHF != "" {
+			if buildConfig.Target != "" && buildConfig.Target != args[0] {
+				return fmt.Errorf("the target %s conflicts with --target %s", args[0], buildConfig.Target)
+			}
+
+			buildConfig.Target = args[0]
+		}
+
 		if err := buildConfig.Validate(); err != nil {
 			return err
 		}
 
+		if buildConfig.FromHF != "" {
+			return runBuildFromHF(cmd.Context())
+		}
+
 		return runBuild(cmd.Context(), args[0])
 	},
 }
@@ -62,6 +80,41 @@ func init() {
 	flags.BoolVar(&buildConfig.Raw, "raw", true, "turning on this flag will build model artifact layers in raw format")
 	flags.BoolVar(&buildConfig.Reasoning, "reasoning", false, "turning on this flag will mark this model as reasoning model in the config")
 	flags.BoolVar(&buildConfig.NoCreationTime, "no-creation-time", false, "turning on this flag will not set createdAt in the config, which will be helpful for repeated builds")
+	flags.BoolVar(&buildConfig.CanonicalJSON, "canonical-json", false, "turning on this flag will marshal the config and manifest in the canonical JSON with the keys sorted and no HTML escaping, so the digests are deterministic for the reproducible builds and signing")
+	flags.IntVar(&buildConfig.JSONIndent, "json-indent", 0, "indent the config and manifest JSON by the number of spaces, 0 marshals them compactly, notice that the indentation changes the digests")
+	flags.StringArrayVar(&buildConfig.ExecPatterns, "exec-pattern", []string{}, "mark the files matching the pattern as executable, which will be extracted with the exec bit regardless of the source permissions, such as '*.sh'")
+	flags.StringVar(&buildConfig.LayerOrder, "layer-order", "", "specify the order of the layers in the manifest, metadata-first places the weight configs, docs and code before the weights to speed up inspecting over the network")
+	flags.StringVar(&buildConfig.LoadOrder, "load-order", "", "record the load order of the weights in the layer annotations for the inference loaders requiring the shards loaded in order, shard orders them by the shard naming with the numbers compared numerically and name by the lexical order of the filepaths, which is surfaced in inspect and the extraction index")
+	flags.Lookup("load-order").NoOptDefVal = config.LoadOrderShard
+	flags.StringVar(&buildConfig.Only, "only", "", "only build the layers of the specified kind, weights builds the weights only and skips the weight configs, code and docs in the Modelfile")
+	flags.StringVar(&buildConfig.EncryptionKey, "encryption-key", "", "encrypt the layers by AES-256-GCM with a random data key per layer wrapped by the key, the path of the key file, env:<name> or cmd:<command> printing the key, the key is 32 bytes in raw, hex or base64 encoding")
+	flags.StringVar(&buildConfig.Progress, "progress", buildConfig.Progress, "specify the progress mode of the layers, file displays one bar per file, aggregate displays a single bar per file type with the number of the files and the total bytes")
+	flags.IntVar(&buildConfig.MaxLayers, "max-layers", buildConfig.MaxLayers, "warn when the model artifact has more layers than the threshold, which usually results from many small files, 0 disables the check")
+	flags.BoolVar(&buildConfig.StrictLimits, "strict-limits", false, "turning on this flag will fail the build instead of warning when the model artifact has more layers than --max-layers")
+	flags.IntVar(&buildConfig.ReportSlow, "report-slow", 0, "report the N slowest files after the build to help to find the bottlenecks, 0 disables the report")
+	flags.BoolVar(&buildConfig.MerkleRoot, "merkle-root", false, "turning on this flag will annotate the manifest with the merkle root over the layers, which can be verified by fsck in one comparison")
+	flags.BoolVar(&buildConfig.NoOverwrite, "no-overwrite", false, "turning on this flag will fail the build if the target tag already exists locally, or remotely with --output-remote")
+	flags.BoolVar(&buildConfig.Force, "force", false, "turning on this flag will overwrite the existing target tag even if --no-overwrite is set")
+	flags.BoolVar(&buildConfig.ModelfileReferrer, "modelfile-referrer", false, "turning on this flag will store the Modelfile as a referrer artifact of the model artifact instead of the manifest annotation, which can be fetched independently by modelfile extract")
+	flags.BoolVar(&buildConfig.AllowLFSPointers, "allow-lfs-pointers", false, "turning on this flag will warn instead of failing the build if the model files are git-lfs pointers whose objects are not pulled")
+	flags.BoolVar(&buildConfig.Compress, "compress", false, "turning on this flag will compress the layers by gzip in tar format regardless of --raw, the incompressible files such as *.gguf and *.png are stored uncompressed")
+	flags.StringArrayVar(&buildConfig.CompressPatterns, "compress-pattern", []string{}, "only compress the files matching the pattern with --compress, such as '*.py', all the compressible files are compressed by default")
+	flags.StringArrayVar(&buildConfig.NoCompressPatterns, "no-compress-pattern", []string{}, "store the files matching the pattern uncompressed with --compress in addition to the default incompressible files, such as '*.bin'")
+	flags.BoolVar(&buildConfig.FastChecksum, "fast-checksum", false, "turning on this flag will annotate the layers with the fast xxhash checksum, which helps fsck to detect the corruption of the local storage quickly")
+	flags.Float64Var(&buildConfig.VerifyCache, "verify-cache", 0, "recompute the digests of the fraction of the files hitting the digest cache and correct the corrupted cache, such as 0.1 for 10% of them, the cache keyed by the mtime and size is stale if the file is edited without changing them")
+	flags.Lookup("verify-cache").NoOptDefVal = "1"
+	flags.StringArrayVar(&buildConfig.AsConfig, "as-config", []string{}, "build the files matching the pattern as the weight configs regardless of the Modelfile, such as 'tokenizer*.bin'")
+	flags.StringArrayVar(&buildConfig.AsModel, "as-model", []string{}, "build the files matching the pattern as the weights regardless of the Modelfile")
+	flags.StringArrayVar(&buildConfig.AsCode, "as-code", []string{}, "build the files matching the pattern as the code regardless of the Modelfile")
+	flags.StringArrayVar(&buildConfig.AsDoc, "as-doc", []string{}, "build the files matching the pattern as the docs regardless of the Modelfile")
+	flags.StringArrayVar(&buildConfig.AsDataset, "as-dataset", []string{}, "build the files matching the pattern as the datasets regardless of the Modelfile")
+	flags.StringVar(&buildConfig.VerifyMetadata, "verify-metadata", "", "verify the paramsize and precision in the Modelfile against the ones detected from the safetensors, warn reports the mismatches and error fails the build")
+	flags.Lookup("verify-metadata").NoOptDefVal = config.VerifyMetadataWarn
+	flags.StringVar(&buildConfig.FromHF, "from-hf", "", "download the model from HuggingFace by the pure-Go downloader, such as owner/repo, and build it by the generated Modelfile, the argument is the target instead of the path and the download is removed after the build")
+	flags.BoolVar(&buildConfig.StripMetadata, "strip-metadata", false, "turning on this flag will omit the mode, owner and mtime of the files from the layer annotations, only the name, size and type are kept for the extraction, the tar layers still carry them in the tar headers")
+	flags.BoolVar(&buildConfig.KeepEmptyDirs, "keep-empty-dirs", false, "turning on this flag will build the empty directories in the work directory as the layers, which are recreated on extraction for the loaders expecting them, the hidden and ignored paths are skipped")
+	flags.BoolVar(&buildConfig.OllamaModelfile, "ollama-modelfile", false, "turning on this flag will parse the Modelfile in the interop mode recognizing the FROM, PARAMETER, TEMPLATE and SYSTEM directives of Ollama, the local file referenced by FROM is built as the weight unless MODEL is specified, and the directives are recorded in the manifest annotations")
+	flags.BoolVar(&buildConfig.ResumeBuild, "resume-build", false, "turning on this flag will record the built layers in the build journal under the storage directory, and skip the layers recorded by the interrupted build of the same target and Modelfile if the files are unchanged and the blobs exist, the journal is removed once the build succeeds")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind build flags to viper: %w", err))
@@ -72,7 +125,7 @@ func init() {
 func runBuild(ctx context.Context, workDir string) error {
 	envinfo.LogDiskInfo("buildWorkDir", workDir)
 
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -85,3 +138,19 @@ func runBuild(ctx context.Context, workDir string) error {
 
 	return nil
 }
+
+// runBuildFromHF runs the build modctl from the HuggingFace model.
+func runBuildFromHF(ctx context.Context) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if err := b.BuildFromHF(ctx, buildConfig.FromHF, buildConfig.Target, buildConfig); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully built model artifact: %s\n", buildConfig.Target)
+
+	return nil
+}
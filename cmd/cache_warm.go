@@ -0,0 +1,82 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var cacheWarmConfig = config.NewCacheWarm()
+
+// cacheWarmCmd represents the modctl command for cache warm.
+var cacheWarmCmd = &cobra.Command{
+	Use:               "warm [flags] <target>",
+	Short:             "Prefetch the blobs of the remote model artifact into the local storage without extracting them.",
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cacheWarmConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runCacheWarm(cmd.Context(), args[0])
+	},
+}
+
+// init initializes cache warm command.
+func init() {
+	flags := cacheWarmCmd.Flags()
+	flags.IntVar(&cacheWarmConfig.Concurrency, "concurrency", cacheWarmConfig.Concurrency, "specify the number of concurrent fetch operations")
+	flags.BoolVar(&cacheWarmConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
+	flags.BoolVar(&cacheWarmConfig.Insecure, "insecure", false, "use insecure connection for the fetch operation and skip TLS verification")
+	flags.StringVar(&cacheWarmConfig.Proxy, "proxy", "", "use proxy for the fetch operation")
+	flags.StringSliceVar(&cacheWarmConfig.Only, "only", []string{}, fmt.Sprintf("only prefetch the layers of the kinds, such as weights, all the layers are prefetched by default, supported kinds: %s", strings.Join(config.LayerKinds, ", ")))
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind cache warm flags to viper: %w", err))
+	}
+}
+
+// runCacheWarm runs the cache warm modctl.
+func runCacheWarm(ctx context.Context, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if target == "" {
+		return fmt.Errorf("target is required")
+	}
+
+	report, err := b.CacheWarm(ctx, target, cacheWarmConfig)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully warmed cache for %s: %d cached, %d fetched (%s), %d filtered out\n", target, report.Hits, report.Fetched, humanize.IBytes(uint64(report.FetchedSize)), report.Filtered)
+	return nil
+}
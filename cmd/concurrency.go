@@ -0,0 +1,67 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// concurrencyAuto is the value of the concurrency flag to adapt the concurrency automatically.
+const concurrencyAuto = "auto"
+
+// concurrencyValue is the value of the concurrency flag, which accepts a number or auto.
+type concurrencyValue struct {
+	value *int
+	auto  *bool
+}
+
+// newConcurrencyValue creates the concurrency flag value bound to the concurrency and the auto mode.
+func newConcurrencyValue(value *int, auto *bool) *concurrencyValue {
+	return &concurrencyValue{value: value, auto: auto}
+}
+
+// String returns the concurrency or auto.
+func (c *concurrencyValue) String() string {
+	if *c.auto {
+		return concurrencyAuto
+	}
+
+	return strconv.Itoa(*c.value)
+}
+
+// Set parses the concurrency from the number or auto.
+func (c *concurrencyValue) Set(s string) error {
+	if s == concurrencyAuto {
+		*c.auto = true
+		return nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("must be a number or %s", concurrencyAuto)
+	}
+
+	*c.value = n
+	*c.auto = false
+	return nil
+}
+
+// Type returns the type of the flag value.
+func (c *concurrencyValue) Type() string {
+	return "string"
+}
@@ -0,0 +1,105 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var deltaConfig = config.NewDelta()
+
+// deltaCmd represents the modctl command for delta.
+var deltaCmd = &cobra.Command{
+	Use:               "delta [flags] <base> <derived> <target>",
+	Short:             "Create the delta between two model artifacts in the local storage, which stores the binary patches of the changed files and references the unchanged files of the base.",
+	Args:              cobra.ExactArgs(3),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := deltaConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runDelta(cmd.Context(), args[0], args[1], args[2])
+	},
+}
+
+// deltaApplyCmd represents the modctl command for applying delta.
+var deltaApplyCmd = &cobra.Command{
+	Use:               "apply [flags] <base> <delta> <target>",
+	Short:             "Reconstruct the derived model artifact from the base and the delta in the local storage.",
+	Args:              cobra.ExactArgs(3),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := deltaConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runDeltaApply(cmd.Context(), args[0], args[1], args[2])
+	},
+}
+
+// init initializes delta command.
+func init() {
+	flags := deltaCmd.PersistentFlags()
+	flags.IntVarP(&deltaConfig.Concurrency, "concurrency", "c", deltaConfig.Concurrency, "specify the number of files diffed or patched concurrently")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind delta flags to viper: %w", err))
+	}
+
+	// Add sub command.
+	deltaCmd.AddCommand(deltaApplyCmd)
+}
+
+// runDelta runs the delta modctl.
+func runDelta(ctx context.Context, base, derived, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if err := b.Delta(ctx, base, derived, target, deltaConfig); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully created delta: %s\n", target)
+	return nil
+}
+
+// runDeltaApply runs the delta apply modctl.
+func runDeltaApply(ctx context.Context, base, delta, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if err := b.ApplyDelta(ctx, base, delta, target, deltaConfig); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully applied delta: %s\n", target)
+	return nil
+}
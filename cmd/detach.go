@@ -0,0 +1,72 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var detachConfig = config.NewDetach()
+
+// detachCmd represents the modctl command for detach.
+var detachCmd = &cobra.Command{
+	Use:               "detach [flags] <target> <path>",
+	Short:             "Remove the file from the model artifact in the local storage by rewriting the config and manifest, the other blobs are reused without reprocessing.",
+	Args:              cobra.ExactArgs(2),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := detachConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runDetach(cmd.Context(), args[0], args[1])
+	},
+}
+
+// init initializes detach command.
+func init() {
+	flags := detachCmd.Flags()
+	flags.StringVarP(&detachConfig.Target, "target", "t", "", "tag the detached model artifact as the target instead of retagging the source")
+	flags.BoolVar(&detachConfig.GC, "gc", false, "turning on this flag will remove the source manifest if it is no longer tagged and garbage collect the blobs unreferenced after the detach, which are kept by default")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind detach flags to viper: %w", err))
+	}
+}
+
+// runDetach runs the detach modctl.
+func runDetach(ctx context.Context, source, path string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if err := b.Detach(ctx, source, path, detachConfig); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully detached %s\n", path)
+	return nil
+}
@@ -31,12 +31,17 @@ var extractConfig = config.NewExtract()
 
 // extractCmd represents the modctl command for extract.
 var extractCmd = &cobra.Command{
-	Use:               "extract <target> --output <output>",
+	Use:               "extract <target> [output]",
 	Short:             "Extract the model artifact to the output path, which can restore the initial state of the model files.",
-	Args:              cobra.ExactArgs(1),
+	Args:              cobra.RangeArgs(1, 2),
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// the output can be specified by the positional argument as well.
+		if len(args) == 2 {
+			extractConfig.Output = args[1]
+		}
+
 		if err := extractConfig.Validate(); err != nil {
 			return err
 		}
@@ -50,6 +55,23 @@ func init() {
 	flags := extractCmd.Flags()
 	flags.StringVar(&extractConfig.Output, "output", "", "specify the output for extracting the model artifact")
 	flags.IntVar(&extractConfig.Concurrency, "concurrency", extractConfig.Concurrency, "specify the concurrency for extracting the model artifact")
+	flags.BoolVar(&extractConfig.Flatten, "flatten", false, "lay out all the files in the output directory without the directory structure, which is the layout expected by some inference engines")
+	flags.BoolVar(&extractConfig.Preallocate, "preallocate", false, "preallocate the disk space of the extracted files before writing to reduce the fragmentation, which is skipped where unsupported")
+	flags.BoolVar(&extractConfig.SkipSpaceCheck, "skip-space-check", false, "turning on this flag will skip checking the output directory has enough free space for the layers before extracting, which fails early by default")
+	flags.StringVar(&extractConfig.OnConflict, "on-conflict", extractConfig.OnConflict, "specify the policy for the existing files in the output directory which differ from the model artifact, overwrite, skip, error or backup which renames them with the .bak suffix")
+	flags.BoolVar(&extractConfig.ExtractDatasets, "extract-datasets", false, "turning on this flag will also extract the dataset archives such as *.zip and *.tar.gz stored as is in the model artifact next to them")
+	flags.BoolVar(&extractConfig.VerifySafetensors, "verify-safetensors", false, "check the headers of the extracted safetensors files are loadable and the tensor data is not truncated after the extraction")
+	flags.StringVar(&extractConfig.DecryptionKey, "decryption-key", "", "specify the key to decrypt the encrypted layers, the path of the key file, env:<name> or cmd:<command> printing the key, the key is 32 bytes in raw, hex or base64 encoding")
+	flags.StringVar(&extractConfig.Chown, "chown", "", "set the owner of all the extracted files in the uid:gid format, or current for the current user, the files are owned by the extracting user by default")
+	flags.StringArrayVar(&extractConfig.MapUID, "map-uid", []string{}, "map the uid stored in the model artifact to the uid of the extracted files in the from:to format, such as 1000:0, the unmapped uids are kept as stored")
+	flags.StringArrayVar(&extractConfig.MapGID, "map-gid", []string{}, "map the gid stored in the model artifact to the gid of the extracted files in the from:to format, such as 1000:0, the unmapped gids are kept as stored")
+	flags.BoolVar(&extractConfig.ShortenLongPaths, "shorten-long-paths", false, "turning on this flag will shorten the paths exceeding the limits of the filesystem to the names by their hashes instead of failing the extraction, the mapping to the original paths is written to '.modctl-longpaths.json' in the output directory")
+	flags.BoolVar(&extractConfig.Force, "force", false, "extract the model artifact even if the output directory already contains the complete extraction")
+	flags.BoolVar(&extractConfig.JoinShards, "join-shards", false, "join the files split into the parts named with the .part-<i>-of-<n> suffix, such as model.safetensors.part-1-of-3, into the files named without the suffix and remove the parts after extracting")
+	flags.BoolVar(&extractConfig.Pull, "pull", extractConfig.Pull, "pull the model artifact from the remote registry if it does not exist in the local storage, use --pull=false to extract from the local storage only")
+	flags.BoolVar(&extractConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS when pulling the model artifact")
+	flags.BoolVar(&extractConfig.Insecure, "insecure", false, "use insecure connection and skip TLS verification when pulling the model artifact")
+	flags.StringVar(&extractConfig.Proxy, "proxy", "", "use proxy when pulling the model artifact")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind extract flags to viper: %w", err))
@@ -58,7 +80,7 @@ func init() {
 
 // runExtract runs the extract modctl.
 func runExtract(ctx context.Context, target string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
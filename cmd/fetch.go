@@ -32,7 +32,7 @@ var fetchConfig = config.NewFetch()
 // fetchCmd represents the modctl command for fetch.
 var fetchCmd = &cobra.Command{
 	Use:               "fetch [flags] <target>",
-	Short:             "Fetch can retrieve files from the remote model repository, enabling selective download of partial model files by filtering based on file path patterns.",
+	Short:             "Fetch can retrieve files from the remote model repository, enabling selective download of partial model files by filtering based on file path patterns and media types.",
 	Args:              cobra.ExactArgs(1),
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
@@ -48,12 +48,16 @@ var fetchCmd = &cobra.Command{
 // init initializes fetch command.
 func init() {
 	flags := fetchCmd.Flags()
-	flags.IntVar(&fetchConfig.Concurrency, "concurrency", fetchConfig.Concurrency, "specify the number of concurrent fetch operations")
+	flags.Var(newConcurrencyValue(&fetchConfig.Concurrency, &fetchConfig.AutoConcurrency), "concurrency", "specify the number of concurrent fetch operations, or auto to adapt it by the throughput and the failures")
 	flags.BoolVar(&fetchConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
 	flags.BoolVar(&fetchConfig.Insecure, "insecure", false, "use insecure connection for the fetch operation and skip TLS verification")
 	flags.StringVar(&fetchConfig.Proxy, "proxy", "", "use proxy for the fetch operation")
 	flags.StringVar(&fetchConfig.Output, "output", "", "specify the directory for fetching the model artifact")
+	flags.StringVar(&fetchConfig.DecryptionKey, "decryption-key", "", "specify the key to decrypt the encrypted layers, the path of the key file, env:<name> or cmd:<command> printing the key, the key is 32 bytes in raw, hex or base64 encoding")
+	flags.BoolVar(&fetchConfig.KeepTar, "keep-tar", false, "turning on this flag will keep the staged tar of the tar layers after extracting them by dragonfly and print its path, which helps to debug the extraction")
+	flags.StringVar(&fetchConfig.OnConflict, "on-conflict", fetchConfig.OnConflict, "specify the policy for the existing files in the output directory which differ from the model artifact, overwrite, skip, error or backup which renames them with the .bak suffix")
 	flags.StringSliceVar(&fetchConfig.Patterns, "patterns", []string{}, "specify the patterns for fetching the model artifact")
+	flags.StringSliceVar(&fetchConfig.MediaTypes, "media-type", []string{}, "specify the kinds of the layers to fetch, weights, config, code, doc or dataset, or the full media types, which are combined with --patterns")
 	flags.StringVar(&fetchConfig.DragonflyEndpoint, "dragonfly-endpoint", "", "specify the dragonfly endpoint for the pull operation, which will download and hardlink the blob by dragonfly GRPC service.")
 
 	if err := viper.BindPFlags(flags); err != nil {
@@ -63,7 +67,7 @@ func init() {
 
 // runFetch runs the fetch modctl.
 func runFetch(ctx context.Context, target string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var fsckConfig = config.NewFsck()
+
+// fsckCmd represents the modctl command for fsck.
+var fsckCmd = &cobra.Command{
+	Use:               "fsck [flags] [<target>]",
+	Short:             "Fsck checks the integrity of the model artifacts in the local storage, all the model artifacts are checked if no target is specified.",
+	Args:              cobra.MaximumNArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := fsckConfig.Validate(); err != nil {
+			return err
+		}
+
+		var target string
+		if len(args) > 0 {
+			target = args[0]
+		}
+
+		if fsckConfig.MerkleRoot != "" && target == "" {
+			return fmt.Errorf("the target must be specified to verify the merkle root")
+		}
+
+		return runFsck(cmd.Context(), target)
+	},
+}
+
+// init initializes fsck command.
+func init() {
+	flags := fsckCmd.Flags()
+	flags.BoolVar(&fsckConfig.Full, "full", false, "always verify the sha256 digest of the blobs even if the fast checksum matches")
+	flags.StringVar(&fsckConfig.MerkleRoot, "merkle-root", "", "verify the merkle root over the layers of the target matches the known root, such as sha256:<hex>")
+	flags.StringVar(&fsckConfig.CheckpointDir, "checkpoint-dir", "", "persist the progress of hashing the blobs in the directory, the interrupted check resumes from the last checkpoint instead of restarting from zero")
+	flags.IntVar(&fsckConfig.Concurrency, "concurrency", fsckConfig.Concurrency, "specify the number of blobs checked concurrently")
+	flags.StringVar(&fsckConfig.Format, "format", fsckConfig.Format, "specify the output format, one of table, json and yaml")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind fsck flags to viper: %w", err))
+	}
+}
+
+// runFsck runs the fsck modctl.
+func runFsck(ctx context.Context, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	report, err := b.Fsck(ctx, target, fsckConfig)
+	if err != nil {
+		return err
+	}
+
+	if err := output.Render(os.Stdout, fsckConfig.Format, report, func(tw *tabwriter.Writer) error {
+		if len(report.Corrupted) == 0 && len(report.Invalid) == 0 {
+			fmt.Fprintf(tw, "Successfully checked %d blobs, no corruption found\n", report.Checked)
+			return nil
+		}
+
+		if len(report.Corrupted) > 0 {
+			fmt.Fprintln(tw, "REPOSITORY\tREFERENCE\tDIGEST\tFILEPATH\tREASON")
+			for _, blob := range report.Corrupted {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", blob.Repository, blob.Reference, blob.Digest, blob.Filepath, blob.Reason)
+			}
+		}
+
+		if len(report.Invalid) > 0 {
+			if len(report.Corrupted) > 0 {
+				fmt.Fprintln(tw)
+			}
+
+			fmt.Fprintln(tw, "REPOSITORY\tREFERENCE\tPROBLEM")
+			for _, artifact := range report.Invalid {
+				for _, problem := range artifact.Problems {
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", artifact.Repository, artifact.Reference, problem)
+				}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// fail on the corruption so the check can be scripted by the exit code.
+	if len(report.Corrupted) > 0 || len(report.Invalid) > 0 {
+		return fmt.Errorf("found %d corrupted blobs in %d checked blobs and %d invalid model artifacts", len(report.Corrupted), report.Checked, len(report.Invalid))
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/internal/fieldpath"
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var getConfig = config.NewGet()
+
+// getCmd represents the modctl command for get.
+var getCmd = &cobra.Command{
+	Use:   "get [flags] <target>",
+	Short: "Get a single field of the model config by the dot-separated path for the scripts, such as config.precision.",
+	Example: `  # Print the precision of the model
+  modctl get registry.com/models/llama3:v1.0.0 --field config.precision
+
+  # Print the capabilities of the model as JSON
+  modctl get registry.com/models/llama3:v1.0.0 --field config.capabilities --json
+
+  # Print the descriptor of the model as YAML
+  modctl get registry.com/models/llama3:v1.0.0 --field descriptor --format yaml`,
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := getConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runGet(cmd.Context(), args[0])
+	},
+}
+
+// init initializes get command.
+func init() {
+	flags := getCmd.Flags()
+	flags.StringVar(&getConfig.Field, "field", "", "specify the dot-separated path of the field in the model config, such as config.precision, descriptor.name or config.capabilities.reasoning")
+	flags.BoolVar(&getConfig.JSON, "json", false, "print the field as JSON, which is the shorthand of --format json")
+	flags.StringVar(&getConfig.Format, "format", getConfig.Format, "specify the output format, one of table, json and yaml, the fields which are not scalars such as config.capabilities require json or yaml")
+	flags.BoolVar(&getConfig.Remote, "remote", false, "get the field from the model artifact in the remote registry")
+	flags.BoolVar(&getConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
+	flags.BoolVar(&getConfig.Insecure, "insecure", false, "allow insecure connections")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind get flags to viper: %w", err))
+	}
+}
+
+// runGet runs the get modctl.
+func runGet(ctx context.Context, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	value, err := b.Get(ctx, target, getConfig)
+	if err != nil {
+		return err
+	}
+
+	format := getConfig.Format
+	if getConfig.JSON {
+		format = config.OutputFormatJSON
+	}
+
+	if format != config.OutputFormatTable {
+		return output.Render(os.Stdout, format, value, nil)
+	}
+
+	// the table format prints the scalar as the plain text for the scripts.
+	text, err := fieldpath.Format(value, false)
+	if errors.Is(err, fieldpath.ErrNotScalar) {
+		return fmt.Errorf("field %s is not a scalar, use --format json or --format yaml to print it", getConfig.Field)
+	} else if err != nil {
+		return err
+	}
+
+	fmt.Println(text)
+	return nil
+}
@@ -18,12 +18,17 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/internal/shortdigest"
 	"github.com/modelpack/modctl/pkg/backend"
 	"github.com/modelpack/modctl/pkg/config"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,6 +43,10 @@ var inspectCmd = &cobra.Command{
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := inspectConfig.Validate(); err != nil {
+			return err
+		}
+
 		return runInspect(cmd.Context(), args[0])
 	},
 }
@@ -49,6 +58,7 @@ func init() {
 	flags.BoolVar(&inspectConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
 	flags.BoolVar(&inspectConfig.Insecure, "insecure", false, "allow insecure connections")
 	flags.BoolVar(&inspectConfig.Config, "config", false, "inspect the config of the model artifact")
+	flags.StringVar(&inspectConfig.Format, "format", inspectConfig.Format, "specify the output format, one of table, json and yaml")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind inspect flags to viper: %w", err))
@@ -57,7 +67,7 @@ func init() {
 
 // runInspect runs the inspect modctl.
 func runInspect(ctx context.Context, target string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -71,11 +81,83 @@ func runInspect(ctx context.Context, target string) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(inspected, "", "	")
-	if err != nil {
-		return err
+	if artifact, ok := inspected.(*backend.InspectedModelArtifact); ok && rootConfig.ShortDigest {
+		shortenInspected(artifact)
+	}
+
+	return output.Render(os.Stdout, inspectConfig.Format, inspected, func(tw *tabwriter.Writer) error {
+		if artifact, ok := inspected.(*backend.InspectedModelArtifact); ok {
+			return inspectTable(tw, artifact)
+		}
+
+		// the config is flattened into the fields as it is nested.
+		fields, err := output.Fields(inspected)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(tw, "FIELD\tVALUE")
+		for _, field := range fields {
+			fmt.Fprintf(tw, "%s\t%s\n", field.Path, field.Value)
+		}
+
+		return nil
+	})
+}
+
+// inspectTable writes the inspected model artifact as the table of the properties followed by
+// the table of the layers.
+func inspectTable(tw *tabwriter.Writer, artifact *backend.InspectedModelArtifact) error {
+	properties := [][2]string{
+		{"ID", artifact.ID},
+		{"DIGEST", artifact.Digest},
+		{"NAME", artifact.Name},
+		{"ARCHITECTURE", artifact.Architecture},
+		{"FAMILY", artifact.Family},
+		{"FORMAT", artifact.Format},
+		{"PARAM SIZE", artifact.ParamSize},
+		{"PRECISION", artifact.Precision},
+		{"QUANTIZATION", artifact.Quantization},
+		{"CREATED", artifact.CreatedAt},
+		{"MERKLE ROOT", artifact.MerkleRoot},
+	}
+
+	for _, property := range properties {
+		if property[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", property[0], property[1])
+		}
+	}
+
+	for _, problem := range artifact.Problems {
+		fmt.Fprintf(tw, "PROBLEM:\t%s\n", problem)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "FILEPATH\tMEDIA TYPE\tDIGEST\tSIZE\tLOAD ORDER")
+	for _, layer := range artifact.Layers {
+		loadOrder := ""
+		if layer.LoadOrder != nil {
+			loadOrder = strconv.Itoa(*layer.LoadOrder)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", layer.Filepath, layer.MediaType, layer.Digest, humanize.IBytes(uint64(layer.Size)), loadOrder)
 	}
 
-	fmt.Println(string(data))
 	return nil
 }
+
+// shortenInspected replaces the digests of the inspected model artifact by the short digests,
+// which are unique among the digests of the model artifact.
+func shortenInspected(artifact *backend.InspectedModelArtifact) {
+	digests := []string{artifact.ID, artifact.Digest}
+	for _, layer := range artifact.Layers {
+		digests = append(digests, layer.Digest)
+	}
+
+	shorts := shortdigest.Unique(digests, shortdigest.DefaultLength)
+	artifact.ID = shorts[artifact.ID]
+	artifact.Digest = shorts[artifact.Digest]
+	for i := range artifact.Layers {
+		artifact.Layers[i].Digest = shorts[artifact.Layers[i].Digest]
+	}
+}
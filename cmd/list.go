@@ -22,13 +22,18 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/internal/shortdigest"
 	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var listConfig = config.NewList()
+
 // listCmd represents the modctl command for list.
 var listCmd = &cobra.Command{
 	Use:               "ls",
@@ -37,6 +42,10 @@ var listCmd = &cobra.Command{
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := listConfig.Validate(); err != nil {
+			return err
+		}
+
 		return runList(cmd.Context())
 	},
 }
@@ -44,6 +53,9 @@ var listCmd = &cobra.Command{
 // init initializes list command.
 func init() {
 	flags := listCmd.Flags()
+	flags.StringVar(&listConfig.Since, "since", "", "show model artifacts created since the timestamp (RFC3339, date or relative duration like 24h, 7d)")
+	flags.StringVar(&listConfig.Until, "until", "", "show model artifacts created until the timestamp (RFC3339, date including the whole day or relative duration like 24h, 7d)")
+	flags.StringVar(&listConfig.Format, "format", listConfig.Format, "specify the output format, one of table, json and yaml")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind list flags to viper: %w", err))
@@ -52,23 +64,39 @@ func init() {
 
 // runList runs the list modctl.
 func runList(ctx context.Context) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
 
-	artifacts, err := b.List(ctx)
+	artifacts, err := b.List(ctx, listConfig)
 	if err != nil {
 		return err
 	}
 
-	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
-	defer tw.Flush()
-	fmt.Fprintln(tw, "REPOSITORY\tTAG\tDIGEST\tCREATED\tSIZE")
+	digests := make([]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		digests = append(digests, artifact.Digest)
+	}
+
+	// Display the short digests which are unique among the listed model artifacts.
+	shorts := map[string]string{}
+	if rootConfig.ShortDigest {
+		shorts = shortdigest.Unique(digests, shortdigest.DefaultLength)
+	}
 
 	for _, artifact := range artifacts {
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", artifact.Repository, artifact.Tag, artifact.Digest, humanize.Time(artifact.CreatedAt), humanize.IBytes(uint64(artifact.Size)))
+		if short, ok := shorts[artifact.Digest]; ok {
+			artifact.Digest = short
+		}
 	}
 
-	return nil
+	return output.Render(os.Stdout, listConfig.Format, artifacts, func(tw *tabwriter.Writer) error {
+		fmt.Fprintln(tw, "REPOSITORY\tTAG\tDIGEST\tCREATED\tSIZE")
+		for _, artifact := range artifacts {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", artifact.Repository, artifact.Tag, artifact.Digest, humanize.Time(artifact.CreatedAt), humanize.IBytes(uint64(artifact.Size)))
+		}
+
+		return nil
+	})
 }
@@ -0,0 +1,76 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var loadConfig = config.NewLoad()
+
+// loadCmd represents the modctl command for load.
+var loadCmd = &cobra.Command{
+	Use:               "load [flags]",
+	Short:             "Load the model artifacts from the archive saved by save into the local storage.",
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runLoad(cmd.Context())
+	},
+}
+
+// init initializes load command.
+func init() {
+	flags := loadCmd.Flags()
+	flags.StringVarP(&loadConfig.Input, "input", "i", "", "specify the path of the archive saved by save")
+	flags.StringVarP(&loadConfig.Target, "target", "t", "", "load the artifact as the target reference instead of the one saved in the archive")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind load flags to viper: %w", err))
+	}
+}
+
+// runLoad runs the load modctl.
+func runLoad(ctx context.Context) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	names, err := b.Load(ctx, loadConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fmt.Printf("Successfully loaded model artifact: %s\n", name)
+	}
+
+	return nil
+}
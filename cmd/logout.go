@@ -49,7 +49,7 @@ func init() {
 
 // runLogout runs the logout modctl.
 func runLogout(ctx context.Context, registry string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,95 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+)
+
+var extractConfig = configmodelfile.NewExtractConfig()
+
+// extractCmd represents the modelfile tools command for extracting modelfile.
+var extractCmd = &cobra.Command{
+	Use:   "extract [flags] <target>",
+	Short: "Extract the modelfile from the model artifact",
+	Long: `Extract the modelfile which the model artifact was built from.
+
+The modelfile is read from the manifest annotation, or discovered from the modelfile
+referrer of the model artifact if it was built with --modelfile-referrer.`,
+	Example: `  # Extract the modelfile from the local model artifact
+  modctl modelfile extract registry.com/models/llama3:v1.0.0
+
+  # Extract the modelfile from the remote model artifact into a file
+  modctl modelfile extract registry.com/models/llama3:v1.0.0 --remote --output ./Modelfile`,
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := extractConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runExtract(cmd.Context(), args[0])
+	},
+}
+
+// init initializes extract command.
+func init() {
+	flags := extractCmd.Flags()
+	flags.BoolVar(&extractConfig.Remote, "remote", false, "extract the modelfile from the remote registry")
+	flags.BoolVar(&extractConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
+	flags.BoolVar(&extractConfig.Insecure, "insecure", false, "allow insecure connections")
+	flags.StringVarP(&extractConfig.Output, "output", "O", "", "specify the output file of modelfile, the modelfile is printed to stdout by default")
+	flags.BoolVar(&extractConfig.Overwrite, "overwrite", false, "overwrite the existing modelfile")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind extract flags to viper: %w", err))
+	}
+}
+
+// runExtract runs the extract modelfile.
+func runExtract(ctx context.Context, target string) error {
+	b, err := backend.New(viper.GetString("storage-dir"), backend.WithPersistentManifestCache(viper.GetBool("persist-manifest-cache")))
+	if err != nil {
+		return err
+	}
+
+	content, err := b.ExtractModelfile(ctx, target, extractConfig)
+	if err != nil {
+		return err
+	}
+
+	if extractConfig.Output == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	if err := os.WriteFile(extractConfig.Output, content, 0644); err != nil {
+		return fmt.Errorf("failed to write modelfile: %w", err)
+	}
+
+	fmt.Printf("Successfully extracted modelfile to %s\n", extractConfig.Output)
+	return nil
+}
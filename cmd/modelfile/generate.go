@@ -73,7 +73,10 @@ Full URLs with domain names will auto-detect the provider.`,
   modctl modelfile generate ./my-model-dir --include ".weights/**"
 
   # Include hidden files but exclude sensitive ones
-  modctl modelfile generate ./my-model-dir --include "**/.*" --exclude "**/.env"`,
+  modctl modelfile generate ./my-model-dir --include "**/.*" --exclude "**/.env"
+
+  # Classify the files with the custom rules
+  modctl modelfile generate ./my-model-dir --rules-file ./rules.yaml`,
 	Args:              cobra.MaximumNArgs(1),
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
@@ -120,12 +123,16 @@ func init() {
 	flags.StringVar(&generateConfig.ModelURL, "model-url", "", "download model from a supported provider (full URL or short-form with --provider)")
 	flags.StringVarP(&generateConfig.Provider, "provider", "p", "", "explicitly specify the provider for short-form URLs (huggingface, modelscope)")
 	flags.StringVar(&generateConfig.DownloadDir, "download-dir", "", "custom directory for downloading models (default: system temp directory)")
+	flags.BoolVar(&generateConfig.Resume, "resume", false, "resume the partial files of the interrupted downloads in --download-dir instead of downloading them from scratch, which applies to the providers downloading over HTTP")
+	flags.IntVar(&generateConfig.DownloadConcurrency, "download-concurrency", generateConfig.DownloadConcurrency, "specify the number of the files downloaded concurrently, which applies to the providers downloading over HTTP, lower it to respect the rate limits of the provider")
 	flags.StringArrayVar(&generateConfig.ExcludePatterns, "exclude", []string{}, "specify glob patterns to exclude files/directories (e.g. *.log, checkpoints/*)")
 	flags.StringArrayVar(&generateConfig.IncludePatterns, "include", []string{},
 		"glob patterns to include files/directories that are normally skipped (e.g. hidden files).\n"+
 			"Uses doublestar syntax (*, **, ?, [...], {a,b}), matching against relative paths from workspace root.\n"+
 			"Note: broad patterns like **/.*  may include large directories (.git) or sensitive files (.env)")
 
+	flags.StringVar(&generateConfig.RulesFile, "rules-file", "", "specify the YAML file of the rules to extend or override the patterns classifying the files and the skip patterns")
+
 	// Mark the ignore-unrecognized-file-types flag as deprecated and hidden
 	flags.MarkDeprecated("ignore-unrecognized-file-types", "this flag will be removed in the next release")
 	flags.MarkHidden("ignore-unrecognized-file-types")
@@ -150,6 +157,14 @@ func runGenerate(ctx context.Context) error {
 
 		fmt.Printf("Using provider: %s\n", provider.Name())
 
+		if resumable, ok := provider.(modelprovider.Resumable); ok {
+			resumable.SetResume(generateConfig.Resume)
+		}
+
+		if concurrent, ok := provider.(modelprovider.Concurrent); ok {
+			concurrent.SetConcurrency(generateConfig.DownloadConcurrency)
+		}
+
 		// Check if user is authenticated with the provider
 		if err := provider.CheckAuth(); err != nil {
 			return fmt.Errorf("%s authentication check failed: %w", provider.Name(), err)
@@ -195,6 +210,15 @@ func runGenerate(ctx context.Context) error {
 		fmt.Printf("Using downloaded model at: %s\n", downloadPath)
 	}
 
+	if generateConfig.RulesFile != "" {
+		rules, err := modelfile.LoadClassificationRules(generateConfig.RulesFile)
+		if err != nil {
+			return err
+		}
+
+		modelfile.SetClassificationRules(rules)
+	}
+
 	fmt.Printf("Generating modelfile for %s\n", generateConfig.Workspace)
 	modelfile, err := modelfile.NewModelfileByWorkspace(generateConfig.Workspace, generateConfig)
 	if err != nil {
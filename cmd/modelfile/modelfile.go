@@ -43,4 +43,5 @@ func init() {
 
 	// Add sub command.
 	RootCmd.AddCommand(generateCmd)
+	RootCmd.AddCommand(extractCmd)
 }
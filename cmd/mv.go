@@ -0,0 +1,71 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var mvConfig = config.NewMove()
+
+// mvCmd represents the modctl command for mv.
+var mvCmd = &cobra.Command{
+	Use:               "mv [flags] <target> <old-path> <new-path>",
+	Short:             "Rename the file in the model artifact in the local storage by rewriting the manifest, the blobs are reused without reprocessing or re-uploading.",
+	Args:              cobra.ExactArgs(3),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := mvConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runMove(cmd.Context(), args[0], args[1], args[2])
+	},
+}
+
+// init initializes mv command.
+func init() {
+	flags := mvCmd.Flags()
+	flags.StringVarP(&mvConfig.Target, "target", "t", "", "tag the renamed model artifact as the target instead of retagging the source")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind mv flags to viper: %w", err))
+	}
+}
+
+// runMove runs the mv modctl.
+func runMove(ctx context.Context, source, oldPath, newPath string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if err := b.Move(ctx, source, oldPath, newPath, mvConfig); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully renamed %s to %s\n", oldPath, newPath)
+	return nil
+}
@@ -37,6 +37,10 @@ var pruneCmd = &cobra.Command{
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := pruneConfig.Validate(); err != nil {
+			return err
+		}
+
 		return runPrune(cmd.Context())
 	},
 }
@@ -46,6 +50,8 @@ func init() {
 	flags := pruneCmd.Flags()
 	flags.BoolVar(&pruneConfig.DryRun, "dry-run", false, "do not remove any blobs, just print what would be removed")
 	flags.BoolVar(&pruneConfig.RemoveUntagged, "remove-untagged", true, "remove untagged manifests")
+	flags.StringVar(&pruneConfig.Since, "since", "", "remove model artifacts created since the timestamp (RFC3339, date or relative duration like 24h, 7d)")
+	flags.StringVar(&pruneConfig.Until, "until", "", "remove model artifacts created until the timestamp (RFC3339, date including the whole day or relative duration like 24h, 7d)")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind prune flags to viper: %w", err))
@@ -54,10 +60,10 @@ func init() {
 
 // runPrune runs the prune modctl.
 func runPrune(ctx context.Context) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
 
-	return b.Prune(ctx, pruneConfig.DryRun, pruneConfig.RemoveUntagged)
+	return b.Prune(ctx, pruneConfig)
 }
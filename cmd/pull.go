@@ -18,7 +18,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -33,7 +35,7 @@ var pullConfig = config.NewPull()
 // pullCmd represents the modctl command for pull.
 var pullCmd = &cobra.Command{
 	Use:               "pull [flags] <target>",
-	Short:             "Pull a model artifact from the remote registry.",
+	Short:             "Pull a model artifact from the remote registry, the target is a repository without tag when pulling by the tag pattern.",
 	Args:              cobra.ExactArgs(1),
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
@@ -49,13 +51,30 @@ var pullCmd = &cobra.Command{
 // init initializes pull command.
 func init() {
 	flags := pullCmd.Flags()
-	flags.IntVar(&pullConfig.Concurrency, "concurrency", pullConfig.Concurrency, "specify the number of concurrent pull operations")
+	flags.Var(newConcurrencyValue(&pullConfig.Concurrency, &pullConfig.AutoConcurrency), "concurrency", "specify the number of concurrent pull operations, or auto to adapt it by the throughput and the failures")
 	flags.BoolVar(&pullConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
 	flags.BoolVar(&pullConfig.Insecure, "insecure", false, "use insecure connection for the pull operation and skip TLS verification")
 	flags.StringVar(&pullConfig.Proxy, "proxy", "", "use proxy for the pull operation")
 	flags.StringVar(&pullConfig.ExtractDir, "extract-dir", "", "specify the extract dir for extracting the model artifact")
 	flags.BoolVar(&pullConfig.ExtractFromRemote, "extract-from-remote", false, "turning on this flag will pull and extract the data from remote registry and no longer store model artifact locally, so user must specify extract-dir as the output directory")
 	flags.StringVar(&pullConfig.DragonflyEndpoint, "dragonfly-endpoint", "", "specify the dragonfly endpoint for the pull operation, which will download and hardlink the blob by dragonfly GRPC service, this mode requires extract-from-remote must be true")
+	flags.StringVar(&pullConfig.DecryptionKey, "decryption-key", "", "specify the key to decrypt the encrypted layers extracted to --extract-dir, the path of the key file, env:<name> or cmd:<command> printing the key, the key is 32 bytes in raw, hex or base64 encoding")
+	flags.BoolVar(&pullConfig.KeepTar, "keep-tar", false, "turning on this flag will keep the staged tar of the tar layers after extracting them by dragonfly and print its path, which helps to debug the extraction")
+	flags.StringVar(&pullConfig.OnConflict, "on-conflict", pullConfig.OnConflict, "specify the policy for the existing files in the output directory which differ from the model artifact, overwrite, skip, error or backup which renames them with the .bak suffix")
+	flags.IntVar(&pullConfig.ExtractConcurrency, "extract-concurrency", pullConfig.ExtractConcurrency, "specify the number of the layers extracted concurrently with --extract-dir")
+	flags.BoolVar(&pullConfig.ExtractDatasets, "extract-datasets", false, "turning on this flag will also extract the dataset archives such as *.zip and *.tar.gz stored as is in the model artifact next to them, which requires --extract-dir")
+	flags.BoolVar(&pullConfig.ShortenLongPaths, "shorten-long-paths", false, "turning on this flag will shorten the paths exceeding the limits of the filesystem to the names by their hashes instead of failing the extraction with --extract-dir, the mapping to the original paths is written to '.modctl-longpaths.json' in the extract dir")
+	flags.BoolVar(&pullConfig.ManifestOnly, "manifest-only", false, "turning on this flag will pull the manifest and the config only without the layers, which helps to mirror the metadata of many model artifacts cheaply, the model artifact cannot be extracted until it is pulled again without this flag")
+	flags.BoolVar(&pullConfig.Preallocate, "preallocate", false, "preallocate the disk space of the extracted files before writing to reduce the fragmentation, which is skipped where unsupported")
+	flags.BoolVar(&pullConfig.SkipSpaceCheck, "skip-space-check", false, "turning on this flag will skip checking the storage and the extract dir have enough free space for the layers before pulling, which fails early by default")
+	flags.StringVar(&pullConfig.Tags, "tags", "", "pull the tags of the repository matching the pattern, such as 'v*', the target must be a repository without tag")
+	flags.BoolVar(&pullConfig.AllTags, "all-tags", false, "pull all the tags of the repository to mirror it, the target must be a repository without tag, the blobs shared by the tags are fetched only once")
+	flags.IntVar(&pullConfig.TagConcurrency, "tag-concurrency", pullConfig.TagConcurrency, "specify the number of the tags pulled concurrently with --tags or --all-tags")
+	flags.StringVar(&pullConfig.Expect, "expect", "", "fail the pull if the digest of the resolved manifest does not match the expected digest, such as 'sha256:...'")
+	flags.BoolVar(&pullConfig.VerifySignature, "verify-signature", false, "turning on this flag will verify the signature of the resolved manifest signed by cosign with --key before pulling the layers, the signature is discovered by the referrers API or the sha256-<hex>.sig tag, and the pull is aborted if no signature is verified")
+	flags.StringVar(&pullConfig.SignatureKey, "key", "", "specify the path of the PEM encoded public key to verify the signature with --verify-signature, the ECDSA, Ed25519 and RSA keys are supported")
+	flags.IntVar(&pullConfig.Latest, "latest", 0, "only pull the newest N tags matching the tag pattern sorted by the creation time of the model artifact, all the matched tags are pulled if it is 0")
+	flags.StringToStringVar(&pullConfig.Select, "select", nil, "select the manifest from the index by the annotations, such as quantization=Q4_K_M,format=gguf")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind pull flags to viper: %w", err))
@@ -68,7 +87,7 @@ func runPull(ctx context.Context, target string) error {
 		envinfo.LogDiskInfo("pullExtractDir", pullConfig.ExtractDir)
 	}
 
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -78,6 +97,11 @@ func runPull(ctx context.Context, target string) error {
 	}
 
 	if err := b.Pull(ctx, target, pullConfig); err != nil {
+		var pullErr *backend.PullError
+		if errors.As(err, &pullErr) {
+			pullErr.Report(os.Stderr)
+		}
+
 		return err
 	}
 
@@ -31,9 +31,9 @@ var pushConfig = config.NewPush()
 
 // pushCmd represents the modctl command for push.
 var pushCmd = &cobra.Command{
-	Use:               "push [flags] <target>",
-	Short:             "Push a model artifact to the remote registry.",
-	Args:              cobra.ExactArgs(1),
+	Use:               "push [flags] <source> [destination]",
+	Short:             "Push a model artifact to the remote registry, optionally as the destination reference without tagging it locally.",
+	Args:              cobra.RangeArgs(1, 2),
 	DisableAutoGenTag: true,
 	SilenceUsage:      true,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -41,6 +41,10 @@ var pushCmd = &cobra.Command{
 			return err
 		}
 
+		if len(args) > 1 {
+			pushConfig.Destination = args[1]
+		}
+
 		return runPush(cmd.Context(), args[0])
 	},
 }
@@ -48,11 +52,20 @@ var pushCmd = &cobra.Command{
 // init initializes push command.
 func init() {
 	flags := pushCmd.Flags()
-	flags.IntVar(&pushConfig.Concurrency, "concurrency", pushConfig.Concurrency, "specify the number of concurrent push operations")
+	flags.Var(newConcurrencyValue(&pushConfig.Concurrency, &pushConfig.AutoConcurrency), "concurrency", "specify the number of concurrent push operations, or auto to adapt it by the throughput and the failures")
 	flags.BoolVar(&pushConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
 	flags.BoolVar(&pushConfig.Insecure, "insecure", false, "turning on this flag will disable TLS verification")
+	flags.StringVar(&pushConfig.Proxy, "proxy", "", "use proxy for the push operation")
 	flags.BoolVar(&pushConfig.Nydusify, "nydusify", false, "[EXPERIMENTAL] nydusify the model artifact")
 	flags.MarkHidden("nydusify")
+	flags.IntVar(&pushConfig.MaxLayers, "max-layers", pushConfig.MaxLayers, "warn when the model artifact has more layers than the registry accepts, 0 disables the check")
+	flags.Var(newSizeValue(&pushConfig.MaxManifestSize), "max-manifest-size", "warn when the manifest is larger than the registry accepts, such as 4MiB, 0 disables the check")
+	flags.Var(newSizeValue(&pushConfig.MaxBlobSize), "max-blob-size", "warn when a layer is larger than the registry accepts, such as 10GiB, 0 disables the check")
+	flags.BoolVar(&pushConfig.StrictLimits, "strict-limits", false, "turning on this flag will fail the push instead of warning when the model artifact exceeds the registry limits")
+	flags.BoolVar(&pushConfig.VerifyRemote, "verify-remote", false, "turning on this flag will compare the local artifact with the existing remote tag before the push, which skips the identical one and fails on the differing one")
+	flags.BoolVar(&pushConfig.Force, "force", false, "turning on this flag will overwrite the differing remote tag with --verify-remote")
+	flags.StringArrayVar(&pushConfig.ShareFrom, "share-from", []string{}, "specify the remote reference sharing the content with the artifact, such as the sibling tag sharing the docs and configs, the shared blobs are reported and mounted from it instead of uploaded")
+	flags.BoolVar(&pushConfig.OnlyChanged, "only-changed", false, "turning on this flag will diff the artifact against the existing remote tag and only upload the changed blobs, the unchanged ones are skipped without checking or mounting and the summary of the uploaded and reused blobs is reported")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		panic(fmt.Errorf("bind push flags to viper: %w", err))
@@ -61,7 +74,7 @@ func init() {
 
 // runPush runs the push modctl.
 func runPush(ctx context.Context, target string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
@@ -70,6 +83,10 @@ func runPush(ctx context.Context, target string) error {
 		return err
 	}
 
+	if pushConfig.Destination != "" {
+		target = pushConfig.Destination
+	}
+
 	fmt.Printf("Successfully pushed model artifact: %s\n", target)
 
 	return nil
@@ -30,12 +30,16 @@ import (
 
 	"github.com/modelpack/modctl/cmd/modelfile"
 	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
 	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/pkg/envinfo"
+	"github.com/modelpack/modctl/pkg/logging"
 )
 
 var rootConfig *config.Root
-var logFile *os.File
+var logFile *logging.RotatingFile
 
 // rootCmd represents the modctl command.
 var rootCmd = &cobra.Command{
@@ -56,14 +60,14 @@ var rootCmd = &cobra.Command{
 			}()
 		}
 
-		// Ensure log directory exists.
-		if err := os.MkdirAll(rootConfig.LogDir, 0755); err != nil {
-			return err
+		// The logs are written to the log directory unless the log file is specified.
+		logPath := rootConfig.LogFile
+		if logPath == "" {
+			logPath = filepath.Join(rootConfig.LogDir, "modctl.log")
 		}
 
-		// Ensure log file exists.
 		var err error
-		logFile, err = os.OpenFile(filepath.Join(rootConfig.LogDir, "modctl.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		logFile, err = logging.OpenRotatingFile(logPath, int64(rootConfig.LogMaxSize)<<20, rootConfig.LogMaxBackups)
 		if err != nil {
 			return err
 		}
@@ -73,12 +77,37 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
+		logFormatter, err := logging.NewFormatter(rootConfig.LogFormat)
+		if err != nil {
+			return err
+		}
+
+		// The logs are written to the log file only, which never interleaves with the progress bar.
 		logrus.SetOutput(logFile)
 		logrus.SetLevel(logLevel)
-		logrus.SetFormatter(&logrus.TextFormatter{})
+		logrus.SetFormatter(logFormatter)
 
 		// TODO: need refactor as currently use a global flag to control the progress bar render.
 		internalpb.SetDisableProgress(rootConfig.DisableProgress)
+		internalpb.SetShortDigest(rootConfig.ShortDigest)
+		internalpb.SetProgressWidth(rootConfig.ProgressWidth)
+		internalpb.SetTopView(rootConfig.ProgressTop)
+
+		// Authenticate all the remote clients with the registry token file if specified.
+		remote.SetTokenFile(rootConfig.RegistryTokenFile)
+
+		// Identify the requests of all the remote clients by the user agent if specified.
+		remote.SetUserAgent(rootConfig.UserAgent)
+
+		// Resolve the short names of the references by the aliases and prefix.
+		if err := backend.SetReferenceResolver(rootConfig.ReferencePrefix, rootConfig.ReferenceAliases); err != nil {
+			return err
+		}
+
+		// Compute all the sha256 digests by the backend if specified.
+		if err := checksum.SetSHA256Backend(rootConfig.SHA256Backend); err != nil {
+			return err
+		}
 
 		// Log environment information for debugging.
 		envinfo.LogEnvironment(rootConfig.StorageDir)
@@ -123,8 +152,21 @@ func init() {
 	flags.BoolVar(&rootConfig.Pprof, "pprof", rootConfig.Pprof, "enable pprof")
 	flags.StringVar(&rootConfig.PprofAddr, "pprof-addr", rootConfig.PprofAddr, "specify the address for pprof")
 	flags.BoolVar(&rootConfig.DisableProgress, "no-progress", rootConfig.DisableProgress, "disable progress bar")
+	flags.IntVar(&rootConfig.ProgressWidth, "progress-width", rootConfig.ProgressWidth, "specify the width of the progress bar, 0 uses the default width, the progress is printed as the plain lines periodically instead of the bars if the output is not a terminal")
+	flags.BoolVar(&rootConfig.ProgressTop, "progress-top", rootConfig.ProgressTop, "display the live summary of the ongoing transfers like top instead of one progress bar per blob, which lists the active blobs by their rates with the overall progress, rate and ETA")
 	flags.StringVar(&rootConfig.LogDir, "log-dir", rootConfig.LogDir, "specify the log directory for modctl")
 	flags.StringVar(&rootConfig.LogLevel, "log-level", rootConfig.LogLevel, "specify the log level for modctl")
+	flags.StringVar(&rootConfig.LogFile, "log-file", rootConfig.LogFile, "specify the log file for modctl instead of modctl.log in the log directory, which is useful to keep the logs of the long unattended operations separately")
+	flags.IntVar(&rootConfig.LogMaxSize, "log-max-size", rootConfig.LogMaxSize, "rotate the log file once it grows beyond the size in megabytes, 0 disables the rotation")
+	flags.IntVar(&rootConfig.LogMaxBackups, "log-max-backups", rootConfig.LogMaxBackups, "specify the number of the rotated log files to keep, such as modctl.log.1 for the latest one")
+	flags.StringVar(&rootConfig.LogFormat, "log-format", rootConfig.LogFormat, "specify the log format for modctl, text or json, the json logs include the structured fields such as operation, reference, digest and duration of the key events")
+	flags.BoolVar(&rootConfig.ShortDigest, "short-digest", rootConfig.ShortDigest, "display the 12-char truncated digests in the progress, inspect and list output, which are extended if they collide")
+	flags.BoolVar(&rootConfig.PersistManifestCache, "persist-manifest-cache", rootConfig.PersistManifestCache, "persist the fetched manifests and model configs in the storage directory by digest to speed up the repeated inspects and lists across the runs")
+	flags.StringVar(&rootConfig.ReferencePrefix, "reference-prefix", rootConfig.ReferencePrefix, "specify the repository prefix of the short names without the registry domain, such as registry.com/models expands llama3 to registry.com/models/llama3:latest, defaults to $"+config.EnvReferencePrefix)
+	flags.StringToStringVar(&rootConfig.ReferenceAliases, "reference-alias", rootConfig.ReferenceAliases, "map the short name to the fully qualified reference, such as llama3=registry.com/models/llama3:v1, the explicit tag or digest of the short name overrides the one of the reference")
+	flags.StringVar(&rootConfig.RegistryTokenFile, "registry-token-file", rootConfig.RegistryTokenFile, "specify the file of the bearer token to authenticate with the registry, which takes precedence over the login credentials, defaults to $"+config.EnvRegistryTokenFile)
+	flags.StringVar(&rootConfig.UserAgent, "user-agent", rootConfig.UserAgent, "specify the User-Agent of the registry requests for the registry-side analytics and troubleshooting, defaults to modctl/<version>")
+	flags.StringVar(&rootConfig.SHA256Backend, "sha256-backend", rootConfig.SHA256Backend, "specify the backend of the sha256 digest computations, simd for the SIMD accelerated implementation or stdlib for the one of the Go standard library such as for FIPS, the digests are identical, defaults to $"+config.EnvSHA256Backend+" or the one selected at build time, which is stdlib with the stdlib_sha256 build tag and simd otherwise")
 
 	// Bind common flags.
 	if err := viper.BindPFlags(flags); err != nil {
@@ -137,15 +179,27 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(logoutCmd)
+	rootCmd.AddCommand(whoamiCmd)
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(pushCmd)
 	rootCmd.AddCommand(rmCmd)
 	rootCmd.AddCommand(pruneCmd)
 	rootCmd.AddCommand(inspectCmd)
+	rootCmd.AddCommand(getCmd)
 	rootCmd.AddCommand(extractCmd)
 	rootCmd.AddCommand(tagCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(saveCmd)
+	rootCmd.AddCommand(loadCmd)
 	rootCmd.AddCommand(fetchCmd)
 	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(detachCmd)
 	rootCmd.AddCommand(uploadCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(fsckCmd)
+	rootCmd.AddCommand(sbomCmd)
+	rootCmd.AddCommand(deltaCmd)
+	rootCmd.AddCommand(syncCheckCmd)
 	rootCmd.AddCommand(modelfile.RootCmd)
 }
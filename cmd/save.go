@@ -0,0 +1,71 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var saveConfig = config.NewSave()
+
+// saveCmd represents the modctl command for save.
+var saveCmd = &cobra.Command{
+	Use:               "save [flags] <target>",
+	Short:             "Save the model artifact in the local storage to a single archive of the OCI image layout, which can be loaded by load for the air-gapped transfer.",
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := saveConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runSave(cmd.Context(), args[0])
+	},
+}
+
+// init initializes save command.
+func init() {
+	flags := saveCmd.Flags()
+	flags.StringVarP(&saveConfig.Output, "output", "o", "", "specify the path of the archive, such as model.modelpack")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind save flags to viper: %w", err))
+	}
+}
+
+// runSave runs the save modctl.
+func runSave(ctx context.Context, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	if err := b.Save(ctx, target, saveConfig); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully saved model artifact %s to %s\n", target, saveConfig.Output)
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var sbomConfig = config.NewSBOM()
+
+// sbomCmd represents the modctl command for sbom.
+var sbomCmd = &cobra.Command{
+	Use:               "sbom [flags] <target>",
+	Short:             "Export the SBOM of the model artifact in SPDX or CycloneDX format, which lists the files, digests, licenses and source provenance.",
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := sbomConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runSBOM(cmd.Context(), args[0])
+	},
+}
+
+// init initializes sbom command.
+func init() {
+	flags := sbomCmd.Flags()
+	flags.StringVar(&sbomConfig.Format, "format", sbomConfig.Format, "specify the format of the sbom, spdx or cyclonedx")
+	flags.StringVarP(&sbomConfig.Output, "output", "o", "", "specify the file to write the sbom, stdout is used if empty")
+	flags.BoolVar(&sbomConfig.Remote, "remote", false, "generate the sbom from the model artifact in the remote registry")
+	flags.BoolVar(&sbomConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
+	flags.BoolVar(&sbomConfig.Insecure, "insecure", false, "allow insecure connections")
+	flags.BoolVar(&sbomConfig.Attach, "attach", false, "attach the sbom to the model artifact in the remote registry as a referrer")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind sbom flags to viper: %w", err))
+	}
+}
+
+// runSBOM runs the sbom modctl.
+func runSBOM(ctx context.Context, target string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	doc, err := b.SBOM(ctx, target, sbomConfig)
+	if err != nil {
+		return err
+	}
+
+	if sbomConfig.Output == "" {
+		fmt.Println(string(doc))
+		return nil
+	}
+
+	if err := os.WriteFile(sbomConfig.Output, doc, 0644); err != nil {
+		return fmt.Errorf("failed to write sbom: %w", err)
+	}
+
+	fmt.Printf("Successfully exported sbom: %s\n", sbomConfig.Output)
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"math"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// sizeValue is the value of the size flag, which accepts the bytes or the human readable size such as 4MiB.
+type sizeValue struct {
+	value *int64
+}
+
+// newSizeValue creates the size flag value bound to the size.
+func newSizeValue(value *int64) *sizeValue {
+	return &sizeValue{value: value}
+}
+
+// String returns the human readable size.
+func (s *sizeValue) String() string {
+	return humanize.IBytes(uint64(*s.value))
+}
+
+// Set parses the size from the bytes or the human readable size.
+func (s *sizeValue) Set(str string) error {
+	size, err := humanize.ParseBytes(str)
+	if err != nil {
+		return fmt.Errorf("must be a size such as 4MiB: %w", err)
+	}
+
+	if size > math.MaxInt64 {
+		return fmt.Errorf("size %s is too large", str)
+	}
+
+	*s.value = int64(size)
+	return nil
+}
+
+// Type returns the type of the flag value.
+func (s *sizeValue) Type() string {
+	return "string"
+}
@@ -0,0 +1,98 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cleanUploadsConfig = config.NewCleanUploads()
+
+// storageCleanUploadsCmd represents the modctl command for cleaning the dangling uploads.
+var storageCleanUploadsCmd = &cobra.Command{
+	Use:               "clean-uploads [flags]",
+	Short:             "Clean up the dangling uploads left by the interrupted pushes and builds in the local storage.",
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cleanUploadsConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runStorageCleanUploads(cmd.Context())
+	},
+}
+
+// init initializes storage clean-uploads command.
+func init() {
+	flags := storageCleanUploadsCmd.Flags()
+	flags.BoolVar(&cleanUploadsConfig.DryRun, "dry-run", false, "do not remove any uploads, just print what would be removed")
+	flags.DurationVar(&cleanUploadsConfig.OlderThan, "older-than", 0, "only remove the uploads started earlier than the duration ago, such as 1h, which keeps the uploads of the running pushes and builds")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind storage clean-uploads flags to viper: %w", err))
+	}
+}
+
+// runStorageCleanUploads runs the storage clean-uploads modctl.
+func runStorageCleanUploads(ctx context.Context) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	uploads, err := b.CleanUploads(ctx, cleanUploadsConfig)
+	if err != nil {
+		return err
+	}
+
+	var reclaimed int64
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tUPLOAD\tSIZE\tSTARTED")
+	for _, upload := range uploads {
+		started := "unknown"
+		if !upload.StartedAt.IsZero() {
+			started = humanize.Time(upload.StartedAt)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", upload.Repository, upload.ID, humanize.IBytes(uint64(upload.Size)), started)
+		reclaimed += upload.Size
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if cleanUploadsConfig.DryRun {
+		fmt.Printf("\nWould reclaim: %s from %d uploads\n", humanize.IBytes(uint64(reclaimed)), len(uploads))
+		return nil
+	}
+
+	fmt.Printf("\nReclaimed: %s from %d uploads\n", humanize.IBytes(uint64(reclaimed)), len(uploads))
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var duConfig = config.NewDiskUsage()
+
+// storageDuCmd represents the modctl command for storage disk usage.
+var storageDuCmd = &cobra.Command{
+	Use:               "du [flags]",
+	Short:             "Du shows the disk usage of the local storage by repository and tag, the shared blobs are counted once.",
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := duConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runStorageDu(cmd.Context())
+	},
+}
+
+// init initializes storage du command.
+func init() {
+	flags := storageDuCmd.Flags()
+	flags.BoolVar(&duConfig.JSON, "json", false, "output the disk usage in JSON format, which is the shorthand of --format json")
+	flags.StringVar(&duConfig.Format, "format", duConfig.Format, "specify the output format, one of table, json and yaml")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind storage du flags to viper: %w", err))
+	}
+}
+
+// runStorageDu runs the storage du modctl.
+func runStorageDu(ctx context.Context) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	usage, err := b.DiskUsage(ctx)
+	if err != nil {
+		return err
+	}
+
+	format := duConfig.Format
+	if duConfig.JSON {
+		format = config.OutputFormatJSON
+	}
+
+	return output.Render(os.Stdout, format, usage, func(tw *tabwriter.Writer) error {
+		fmt.Fprintln(tw, "REPOSITORY\tTAG\tSIZE\tUNIQUE SIZE\tSHARED SIZE")
+		for _, repo := range usage.Repositories {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", repo.Repository, "*", humanize.IBytes(uint64(repo.Size)), humanize.IBytes(uint64(repo.UniqueSize)), humanize.IBytes(uint64(repo.SharedSize)))
+			for _, tag := range repo.Tags {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", repo.Repository, tag.Tag, humanize.IBytes(uint64(tag.Size)), humanize.IBytes(uint64(tag.UniqueSize)), humanize.IBytes(uint64(tag.SharedSize)))
+			}
+		}
+
+		fmt.Fprintf(tw, "\nTotal: %s\n", humanize.IBytes(uint64(usage.Size)))
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var syncCheckConfig = config.NewSyncCheck()
+
+// syncCheckCmd represents the modctl command for sync check.
+var syncCheckCmd = &cobra.Command{
+	Use:               "sync-check [flags] <repository>",
+	Short:             "Compare the tags of the repository in the local storage with the remote ones by the manifest digests, which reports the missing, extra and divergent tags.",
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := syncCheckConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runSyncCheck(cmd.Context(), args[0])
+	},
+}
+
+// init initializes sync check command.
+func init() {
+	flags := syncCheckCmd.Flags()
+	flags.IntVarP(&syncCheckConfig.Concurrency, "concurrency", "c", syncCheckConfig.Concurrency, "specify the number of the remote tags resolved concurrently")
+	flags.BoolVar(&syncCheckConfig.JSON, "json", false, "output the drift report in JSON format, which is the shorthand of --format json")
+	flags.StringVar(&syncCheckConfig.Format, "format", syncCheckConfig.Format, "specify the output format, one of table, json and yaml")
+	flags.BoolVar(&syncCheckConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
+	flags.BoolVar(&syncCheckConfig.Insecure, "insecure", false, "use insecure connection for the remote repository and skip the TLS verification")
+	flags.StringVar(&syncCheckConfig.Proxy, "proxy", "", "use proxy for the remote repository")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind sync check flags to viper: %w", err))
+	}
+}
+
+// runSyncCheck runs the sync check modctl.
+func runSyncCheck(ctx context.Context, repo string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	report, err := b.SyncCheck(ctx, repo, syncCheckConfig)
+	if err != nil {
+		return err
+	}
+
+	format := syncCheckConfig.Format
+	if syncCheckConfig.JSON {
+		format = config.OutputFormatJSON
+	}
+
+	if err := output.Render(os.Stdout, format, report, func(tw *tabwriter.Writer) error {
+		fmt.Fprintln(tw, "TAG\tSTATUS\tLOCAL DIGEST\tREMOTE DIGEST")
+		for _, tag := range report.InSync {
+			fmt.Fprintf(tw, "%s\tin-sync\t\t\n", tag)
+		}
+		for _, tag := range report.Missing {
+			fmt.Fprintf(tw, "%s\tmissing\t\t\n", tag)
+		}
+		for _, tag := range report.Extra {
+			fmt.Fprintf(tw, "%s\textra\t\t\n", tag)
+		}
+		for _, tag := range report.Divergent {
+			fmt.Fprintf(tw, "%s\tdivergent\t%s\t%s\n", tag.Tag, tag.LocalDigest, tag.RemoteDigest)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// fail on the drift so the mirror validation can be scripted by the exit code.
+	if report.Drifted() {
+		return fmt.Errorf("repository %s drifted from the remote: %d missing, %d extra and %d divergent tags", report.Repository, len(report.Missing), len(report.Extra), len(report.Divergent))
+	}
+
+	return nil
+}
@@ -49,7 +49,7 @@ func init() {
 
 // runTag runs the tag modctl.
 func runTag(ctx context.Context, source, target string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
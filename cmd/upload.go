@@ -61,7 +61,7 @@ func init() {
 
 // runUpload runs the upload modctl.
 func runUpload(ctx context.Context, filepath string) error {
-	b, err := backend.New(rootConfig.StorageDir)
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
 	if err != nil {
 		return err
 	}
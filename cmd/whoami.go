@@ -0,0 +1,102 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/modelpack/modctl/internal/output"
+	"github.com/modelpack/modctl/pkg/backend"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+var whoamiConfig = config.NewWhoami()
+
+// whoamiCmd represents the modctl command for whoami.
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami [flags] <registry>",
+	Short: "Show the identity authenticated by the registry with the credential used by the other commands.",
+	Example: `
+# show the identity of docker hub:
+modctl whoami registry-1.docker.io
+`,
+	Args:              cobra.ExactArgs(1),
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := whoamiConfig.Validate(); err != nil {
+			return err
+		}
+
+		return runWhoami(cmd.Context(), args[0])
+	},
+}
+
+// init initializes whoami command.
+func init() {
+	flags := whoamiCmd.Flags()
+	flags.BoolVar(&whoamiConfig.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS")
+	flags.BoolVar(&whoamiConfig.Insecure, "insecure", false, "allow insecure connections")
+	flags.StringVar(&whoamiConfig.Proxy, "proxy", "", "use proxy for the registry")
+	flags.StringVar(&whoamiConfig.Format, "format", whoamiConfig.Format, "specify the output format, one of table, json and yaml")
+
+	if err := viper.BindPFlags(flags); err != nil {
+		panic(fmt.Errorf("bind whoami flags to viper: %w", err))
+	}
+}
+
+// runWhoami runs the whoami modctl.
+func runWhoami(ctx context.Context, registry string) error {
+	b, err := backend.New(rootConfig.StorageDir, backend.WithPersistentManifestCache(rootConfig.PersistManifestCache))
+	if err != nil {
+		return err
+	}
+
+	identity, err := b.Whoami(ctx, registry, whoamiConfig)
+	if err != nil {
+		return err
+	}
+
+	return output.Render(os.Stdout, whoamiConfig.Format, identity, func(tw *tabwriter.Writer) error {
+		account := identity.Account
+		switch {
+		case identity.Anonymous:
+			account = "anonymous"
+		case account == "":
+			account = "unknown (authenticated by token)"
+		}
+
+		fmt.Fprintf(tw, "Registry: %s\n", identity.Registry)
+		fmt.Fprintf(tw, "Account:  %s\n", account)
+		if identity.Scheme != "" {
+			fmt.Fprintf(tw, "Scheme:   %s\n", identity.Scheme)
+		}
+
+		if len(identity.Scopes) > 0 {
+			fmt.Fprintf(tw, "Scopes:   %s\n", strings.Join(identity.Scopes, " "))
+		}
+
+		return nil
+	})
+}
@@ -61,6 +61,9 @@ type Item struct {
 	// Digest is the SHA-256 digest of the file.
 	Digest string `json:"digest"`
 
+	// FastChecksum is the fast checksum of the file, empty if it was not computed.
+	FastChecksum string `json:"fast_checksum,omitempty"`
+
 	// CreatedAt is the time when the item was created.
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fieldpath evaluates the dot-separated paths over the fields of the decoded values,
+// such as config.capabilities.reasoning over the model config.
+package fieldpath
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrNotScalar is returned when formatting the struct, map or slice as the plain text.
+var ErrNotScalar = errors.New("not a scalar")
+
+// Lookup returns the value at the path of v, the keys of the path are the JSON names of the struct
+// fields matched case-insensitively, the keys of the maps or the indexes of the slices, such as
+// descriptor.licenses.0. The unset field returns nil, and the field which does not exist returns an error.
+func Lookup(v any, path string) (any, error) {
+	value := reflect.ValueOf(v)
+	unset := false
+	if path == "" {
+		return v, nil
+	}
+
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		// walk into the zero value of the nil pointers to validate the rest of the path.
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				unset = true
+				if value.Kind() == reflect.Interface {
+					return nil, nil
+				}
+
+				value = reflect.Zero(value.Type().Elem())
+				continue
+			}
+
+			value = value.Elem()
+		}
+
+		parent := strings.Join(keys[:i], ".")
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := structField(value, key)
+			if !ok {
+				return nil, fmt.Errorf("field %q not found in %q", key, parent)
+			}
+
+			value = field
+		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("unsupported map key of %q", parent)
+			}
+
+			elem := value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key()))
+			if !elem.IsValid() {
+				return nil, fmt.Errorf("key %q not found in %q", key, parent)
+			}
+
+			value = elem
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= value.Len() {
+				return nil, fmt.Errorf("invalid index %q of %q with %d elements", key, parent, value.Len())
+			}
+
+			value = value.Index(index)
+		default:
+			return nil, fmt.Errorf("field %q not found in the %s value of %q", key, value.Kind(), parent)
+		}
+	}
+
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if unset {
+		return nil, nil
+	}
+
+	return value.Interface(), nil
+}
+
+// structField returns the field of the struct by the JSON name, the exact match takes precedence
+// over the case-insensitive one.
+func structField(value reflect.Value, key string) (reflect.Value, bool) {
+	var fold reflect.Value
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		if name == key {
+			return value.Field(i), true
+		}
+
+		if !fold.IsValid() && strings.EqualFold(name, key) {
+			fold = value.Field(i)
+		}
+	}
+
+	return fold, fold.IsValid()
+}
+
+// Format returns the value as the plain text for the scripts, such as the string without the
+// quotes and the empty text for the unset value. The structs, maps and slices are formatted as
+// the indented JSON if asJSON, otherwise they are rejected as they are not scalars.
+func Format(v any, asJSON bool) (string, error) {
+	if asJSON {
+		data, err := json.MarshalIndent(v, "", "	")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		return string(data), nil
+	}
+
+	if v == nil {
+		return "", nil
+	}
+
+	// the values such as the time and the digest have their own text forms.
+	if marshaler, ok := v.(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		return string(text), nil
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return "", ErrNotScalar
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fieldpath
+
+import (
+	"testing"
+	"time"
+
+	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	reasoning := true
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	model := &legacymodelspec.Model{
+		Descriptor: legacymodelspec.ModelDescriptor{
+			Name:      "llama3",
+			CreatedAt: &createdAt,
+			Licenses:  []string{"Apache-2.0", "MIT"},
+		},
+		ModelFS: legacymodelspec.ModelFS{
+			Type:    "layers",
+			DiffIDs: []godigest.Digest{"sha256:abc"},
+		},
+		Config: legacymodelspec.ModelConfig{
+			Precision: "bf16",
+			Capabilities: &legacymodelspec.ModelCapabilities{
+				Reasoning:  &reasoning,
+				InputTypes: []legacymodelspec.Modality{legacymodelspec.TextModality},
+			},
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "config.precision", expected: "bf16"},
+		{path: "Config.Precision", expected: "bf16"},
+		{path: "descriptor.name", expected: "llama3"},
+		{path: "descriptor.createdAt", expected: "2025-01-02T03:04:05Z"},
+		{path: "descriptor.licenses.1", expected: "MIT"},
+		{path: "modelfs.diff_ids.0", expected: "sha256:abc"},
+		{path: "config.capabilities.reasoning", expected: "true"},
+		{path: "config.capabilities.input_types.0", expected: "text"},
+		// the unset fields are empty.
+		{path: "config.quantization", expected: ""},
+		{path: "config.capabilities.tool_usage", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := Lookup(model, tt.path)
+			require.NoError(t, err)
+
+			text, err := Format(value, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+		})
+	}
+
+	t.Run("unset parent", func(t *testing.T) {
+		value, err := Lookup(&legacymodelspec.Model{}, "config.capabilities.reasoning")
+		require.NoError(t, err)
+		assert.Nil(t, value)
+
+		_, err = Lookup(&legacymodelspec.Model{}, "config.capabilities.unknown")
+		assert.ErrorContains(t, err, `field "unknown" not found in "config.capabilities"`)
+	})
+
+	t.Run("invalid paths", func(t *testing.T) {
+		for _, path := range []string{"config.unknown", "descriptor.licenses.2", "descriptor.licenses.x", "config.precision.x"} {
+			_, err := Lookup(model, path)
+			assert.Error(t, err, path)
+		}
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		value, err := Lookup(model, "config.capabilities")
+		require.NoError(t, err)
+
+		_, err = Format(value, false)
+		assert.ErrorIs(t, err, ErrNotScalar)
+
+		text, err := Format(value, true)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"input_types":["text"],"reasoning":true}`, text)
+	})
+}
@@ -0,0 +1,156 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package output renders the results of the read commands in the table, JSON or YAML format,
+// so the results can be consumed by both the humans and the scripts.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// Field is the leaf field of the value flattened by Fields.
+type Field struct {
+	// Path is the dot-separated path of the field, the indexes of the slices are the keys.
+	Path string
+	// Value is the scalar value of the field.
+	Value string
+}
+
+// Render writes the value to the writer in the format. The table format is written by the table
+// function as the columns differ by the commands, and the JSON and YAML formats are rendered by the
+// JSON encoding of the value, so the field names are the same in both formats.
+func Render(w io.Writer, format string, v any, table func(tw *tabwriter.Writer) error) error {
+	switch format {
+	case config.OutputFormatTable:
+		tw := NewTabWriter(w)
+		if err := table(tw); err != nil {
+			return err
+		}
+
+		return tw.Flush()
+	case config.OutputFormatJSON:
+		data, err := json.MarshalIndent(v, "", "\t")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the output to JSON: %w", err)
+		}
+
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case config.OutputFormatYAML:
+		node, err := toNode(v)
+		if err != nil {
+			return err
+		}
+
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(node); err != nil {
+			return fmt.Errorf("failed to marshal the output to YAML: %w", err)
+		}
+
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// NewTabWriter creates the tab writer aligning the columns of the tables.
+func NewTabWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 4, ' ', 0)
+}
+
+// Fields flattens the value into the leaf fields by its JSON encoding in the order of the fields,
+// which is useful to render the nested values as the table, the nulls, empty objects and
+// empty arrays are omitted.
+func Fields(v any) ([]Field, error) {
+	node, err := toNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []Field
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				walk(node.Content[i+1], join(path, node.Content[i].Value))
+			}
+		case yaml.SequenceNode:
+			for i, child := range node.Content {
+				walk(child, join(path, strconv.Itoa(i)))
+			}
+		case yaml.ScalarNode:
+			if node.Tag == "!!null" {
+				return
+			}
+
+			fields = append(fields, Field{Path: path, Value: node.Value})
+		}
+	}
+
+	walk(node, "")
+	return fields, nil
+}
+
+// join joins the key to the dot-separated path.
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// toNode converts the value into the YAML node by its JSON encoding, which keeps the names and
+// the order of the fields.
+func toNode(v any) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the output to JSON: %w", err)
+	}
+
+	// JSON is a subset of YAML, so it is decoded as is.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert the output to YAML: %w", err)
+	}
+
+	resetStyle(&node)
+	return &node, nil
+}
+
+// resetStyle resets the flow and quoted styles of the JSON syntax recursively, so the node is
+// rendered in the block style and the scalars are only quoted if required.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
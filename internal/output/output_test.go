@@ -0,0 +1,139 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// artifact is the model artifact listed by ls.
+type artifact struct {
+	Repository string
+	Tag        string
+	Digest     string
+	Size       int64
+	CreatedAt  time.Time
+}
+
+// listTable renders the model artifacts as the table of ls.
+func listTable(artifacts []*artifact) func(tw *tabwriter.Writer) error {
+	return func(tw *tabwriter.Writer) error {
+		fmt.Fprintln(tw, "REPOSITORY\tTAG\tDIGEST\tSIZE")
+		for _, a := range artifacts {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", a.Repository, a.Tag, a.Digest, a.Size)
+		}
+
+		return nil
+	}
+}
+
+func TestRender(t *testing.T) {
+	artifacts := []*artifact{
+		{Repository: "example.com/models/llama", Tag: "v1", Digest: "sha256:abc", Size: 1024, CreatedAt: time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{Repository: "example.com/models/qwen", Tag: "1.0", Digest: "sha256:def", Size: 2048, CreatedAt: time.Date(2025, 3, 2, 8, 0, 0, 0, time.UTC)},
+	}
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: config.OutputFormatTable,
+			want: `REPOSITORY                  TAG    DIGEST        SIZE
+example.com/models/llama    v1     sha256:abc    1024
+example.com/models/qwen     1.0    sha256:def    2048
+`,
+		},
+		{
+			format: config.OutputFormatJSON,
+			want: `[
+	{
+		"Repository": "example.com/models/llama",
+		"Tag": "v1",
+		"Digest": "sha256:abc",
+		"Size": 1024,
+		"CreatedAt": "2025-03-01T08:00:00Z"
+	},
+	{
+		"Repository": "example.com/models/qwen",
+		"Tag": "1.0",
+		"Digest": "sha256:def",
+		"Size": 2048,
+		"CreatedAt": "2025-03-02T08:00:00Z"
+	}
+]
+`,
+		},
+		{
+			format: config.OutputFormatYAML,
+			want: `- Repository: example.com/models/llama
+  Tag: v1
+  Digest: sha256:abc
+  Size: 1024
+  CreatedAt: "2025-03-01T08:00:00Z"
+- Repository: example.com/models/qwen
+  Tag: "1.0"
+  Digest: sha256:def
+  Size: 2048
+  CreatedAt: "2025-03-02T08:00:00Z"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Render(&buf, tc.format, artifacts, listTable(artifacts)))
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+
+	assert.Error(t, Render(&bytes.Buffer{}, "xml", artifacts, listTable(artifacts)))
+}
+
+func TestFields(t *testing.T) {
+	v := map[string]any{
+		"descriptor": map[string]any{
+			"name":     "llama",
+			"licenses": []string{"MIT", "Apache-2.0"},
+			"authors":  []string{},
+		},
+		"config": map[string]any{
+			"paramSize":    "8b",
+			"capabilities": map[string]any{},
+			"precision":    nil,
+		},
+	}
+
+	fields, err := Fields(v)
+	require.NoError(t, err)
+	assert.Equal(t, []Field{
+		{Path: "config.paramSize", Value: "8b"},
+		{Path: "descriptor.licenses.0", Value: "MIT"},
+		{Path: "descriptor.licenses.1", Value: "Apache-2.0"},
+		{Path: "descriptor.name", Value: "llama"},
+	}, fields)
+}
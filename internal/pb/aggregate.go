@@ -0,0 +1,180 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pb
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	mpbv8 "github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+)
+
+// Aggregate is the single progress bar of a group of files, which displays the number of the
+// completed files and the total bytes instead of one bar per file, the details of the files are
+// logged at the debug level.
+type Aggregate struct {
+	mu  sync.Mutex
+	bar *mpbv8.Bar
+
+	prompt string
+	group  string
+
+	// files are the added files by name, completed is the number of the completed ones.
+	files     map[string]*aggregateFile
+	completed int
+
+	// total is the sum of the sizes of the added files, current is the number of the bytes read.
+	total   int64
+	current atomic.Int64
+}
+
+// aggregateFile is the file added to the aggregate progress bar.
+type aggregateFile struct {
+	size int64
+	read atomic.Int64
+}
+
+// AggregateStats is the accounting of the files of the aggregate progress bar.
+type AggregateStats struct {
+	Files     int
+	Completed int
+	Total     int64
+	Current   int64
+}
+
+// NewAggregate creates the aggregate progress bar of the group of files, the total of the bar
+// grows as the files are added.
+func (p *ProgressBar) NewAggregate(prompt, group string) *Aggregate {
+	a := &Aggregate{prompt: prompt, group: group, files: make(map[string]*aggregateFile)}
+	if disableProgress.Load() {
+		return a
+	}
+
+	p.mu.Lock()
+	p.aggregates = append(p.aggregates, a)
+	p.mu.Unlock()
+
+	startTime := time.Now()
+	a.bar = p.mpb.New(0,
+		mpbv8.BarStyle(),
+		mpbv8.BarFillerOnComplete("|"),
+		mpbv8.PrependDecorators(
+			decor.Any(func(s decor.Statistics) string {
+				stats := a.Stats()
+				return fmt.Sprintf("%s %s files %d/%d", a.prompt, a.group, stats.Completed, stats.Files)
+			}, decor.WCSyncSpaceR),
+		),
+		mpbv8.AppendDecorators(
+			decor.Counters(decor.SizeB1000(0), "% .2f / % .2f"),
+			decor.OnComplete(decor.Name(" | ", decor.WCSyncWidthR), " | "),
+			decor.OnCompleteMeta(
+				decor.AverageSpeed(decor.SizeB1000(0), "% .2f", decor.WCSyncWidthR),
+				func(_ string) string {
+					return fmt.Sprintf("done(%.1fs)", time.Since(startTime).Seconds())
+				},
+			),
+		),
+	)
+
+	return a
+}
+
+// Add adds the file to the aggregate progress bar and returns the reader which accounts the bytes read,
+// the file added again by the retry replaces the previous accounting of it.
+func (a *Aggregate) Add(name string, size int64, reader io.Reader) io.Reader {
+	file := &aggregateFile{size: size}
+	a.mu.Lock()
+	if old, ok := a.files[name]; ok {
+		a.total -= old.size
+		a.current.Add(-old.read.Load())
+	}
+	a.files[name] = file
+	a.total += size
+	total := a.total
+	a.mu.Unlock()
+
+	logrus.Debugf("progress: adding %s to the %s files [size: %d]", name, a.group, size)
+	if a.bar != nil {
+		a.bar.SetTotal(total, false)
+		a.bar.SetCurrent(a.current.Load())
+	}
+
+	if reader == nil {
+		return nil
+	}
+
+	return &aggregateReader{reader: reader, file: file, aggregate: a}
+}
+
+// Complete completes the file of the aggregate progress bar.
+func (a *Aggregate) Complete(name string, msg string) {
+	a.mu.Lock()
+	a.completed++
+	a.mu.Unlock()
+
+	logrus.Debugf("progress: %s %s", name, msg)
+}
+
+// Abort logs the failure of the file, the aggregate progress bar keeps going for the retries and other files.
+func (a *Aggregate) Abort(name string, err error) {
+	logrus.Errorf("progress: aborting %s of the %s files: %v", name, a.group, err)
+}
+
+// Done completes the aggregate progress bar after all the files are processed.
+func (a *Aggregate) Done() {
+	if a.bar != nil {
+		a.bar.SetTotal(-1, true)
+	}
+}
+
+// Stats returns the accounting of the files of the aggregate progress bar.
+func (a *Aggregate) Stats() AggregateStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return AggregateStats{
+		Files:     len(a.files),
+		Completed: a.completed,
+		Total:     a.total,
+		Current:   a.current.Load(),
+	}
+}
+
+// aggregateReader accounts the bytes read from the reader of the file to the aggregate progress bar.
+type aggregateReader struct {
+	reader    io.Reader
+	file      *aggregateFile
+	aggregate *Aggregate
+}
+
+func (r *aggregateReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.file.read.Add(int64(n))
+		r.aggregate.current.Add(int64(n))
+		if r.aggregate.bar != nil {
+			r.aggregate.bar.IncrBy(n)
+		}
+	}
+
+	return n, err
+}
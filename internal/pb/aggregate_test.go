@@ -0,0 +1,77 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	p := NewProgressBar(io.Discard)
+	defer p.Stop()
+
+	aggregate := p.NewAggregate(NormalizePrompt("Building layers"), "code")
+
+	var (
+		wg    sync.WaitGroup
+		total int64
+	)
+	for i := 1; i <= 50; i++ {
+		content := bytes.Repeat([]byte("x"), i*10)
+		total += int64(len(content))
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			reader := aggregate.Add(name, int64(len(content)), bytes.NewReader(content))
+			_, err := io.Copy(io.Discard, reader)
+			assert.NoError(t, err)
+			aggregate.Complete(name, "done")
+		}(fmt.Sprintf("file-%d.py", i))
+	}
+	wg.Wait()
+	aggregate.Done()
+
+	assert.Equal(t, AggregateStats{Files: 50, Completed: 50, Total: total, Current: total}, aggregate.Stats())
+}
+
+func TestAggregateRetry(t *testing.T) {
+	p := NewProgressBar(io.Discard)
+	defer p.Stop()
+
+	aggregate := p.NewAggregate(NormalizePrompt("Building layers"), "doc")
+
+	// the partially read file is added again by the retry, which replaces the previous accounting.
+	reader := aggregate.Add("README.md", 8, bytes.NewReader([]byte("partial!")))
+	_, err := reader.Read(make([]byte, 4))
+	require.NoError(t, err)
+
+	reader = aggregate.Add("README.md", 8, bytes.NewReader([]byte("complete")))
+	_, err = io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	aggregate.Complete("README.md", "done")
+	aggregate.Done()
+
+	assert.Equal(t, AggregateStats{Files: 1, Completed: 1, Total: 8, Current: 8}, aggregate.Stats())
+}
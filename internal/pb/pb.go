@@ -28,6 +28,8 @@ import (
 	"github.com/sirupsen/logrus"
 	mpbv8 "github.com/vbauerster/mpb/v8"
 	"github.com/vbauerster/mpb/v8/decor"
+
+	"github.com/modelpack/modctl/internal/shortdigest"
 )
 
 var (
@@ -36,13 +38,42 @@ var (
 	// goroutine may flip it via SetDisableProgress, so the access is guarded
 	// by sync/atomic to stay race-free under `go test -race`.
 	disableProgress atomic.Bool
+
+	// shortDigest is the flag to display the short digests in the progress bar.
+	shortDigest atomic.Bool
+
+	// progressWidth is the width of the progress bar, the default width is used if it is not positive.
+	progressWidth atomic.Int64
+
+	// topView is the flag to display the live summary of the transfers instead of the bars.
+	topView atomic.Bool
 )
 
+// defaultProgressWidth is the default width of the progress bar.
+const defaultProgressWidth = 60
+
 // SetDisableProgress disables the progress bar.
 func SetDisableProgress(disable bool) {
 	disableProgress.Store(disable)
 }
 
+// SetShortDigest displays the short digests in the progress bar, the digests are
+// extended if they collide with other digests displayed by the same progress bar.
+func SetShortDigest(short bool) {
+	shortDigest.Store(short)
+}
+
+// SetProgressWidth sets the width of the progress bar, the default width is used if it is not positive.
+func SetProgressWidth(width int) {
+	progressWidth.Store(int64(width))
+}
+
+// SetTopView displays the live summary of the ongoing transfers like top instead of one bar
+// per transfer, which lists the active transfers by their rates with the overall progress.
+func SetTopView(top bool) {
+	topView.Store(top)
+}
+
 // NormalizePrompt normalizes the prompt string.
 func NormalizePrompt(prompt string) string {
 	return fmt.Sprintf("%s =>", prompt)
@@ -53,6 +84,16 @@ type ProgressBar struct {
 	mu   sync.RWMutex
 	mpb  *mpbv8.Progress
 	bars map[string]*progressBar
+
+	// digests are the digests displayed by the progress bar, shorts maps them to the short digests.
+	digests []string
+	shorts  map[string]string
+
+	// aggregates are the aggregate progress bars, plain prints the progress as the plain lines
+	// if the output is not a terminal, top displays the live summary of the transfers instead.
+	aggregates []*Aggregate
+	plain      *plainReporter
+	top        *topReporter
 }
 
 type progressBar struct {
@@ -62,28 +103,51 @@ type progressBar struct {
 	startTime time.Time
 }
 
-// NewProgressBar creates a new progress bar.
+// NewProgressBar creates a new progress bar, the ANSI bars are rendered if the output is a terminal,
+// otherwise the progress is printed as the plain lines periodically.
 func NewProgressBar(writers ...io.Writer) *ProgressBar {
+	// If no writer specified, use stdout.
+	var output io.Writer
+	switch len(writers) {
+	case 0:
+		output = os.Stdout
+	case 1:
+		output = writers[0]
+	default:
+		output = io.MultiWriter(writers...)
+	}
+
+	width := int(progressWidth.Load())
+	if width <= 0 {
+		width = defaultProgressWidth
+	}
+
 	opts := []mpbv8.ContainerOption{
 		mpbv8.PopCompletedMode(),
-		mpbv8.WithAutoRefresh(),
-		mpbv8.WithWidth(60),
+		mpbv8.WithWidth(width),
 		mpbv8.WithRefreshRate(300 * time.Millisecond),
 	}
 
-	// If no writer specified, use stdout.
-	if len(writers) == 0 {
-		opts = append(opts, mpbv8.WithOutput(os.Stdout))
-	} else if len(writers) == 1 {
-		opts = append(opts, mpbv8.WithOutput(writers[0]))
-	} else {
-		opts = append(opts, mpbv8.WithOutput(io.MultiWriter(writers...)))
+	p := &ProgressBar{
+		bars:   make(map[string]*progressBar),
+		shorts: make(map[string]string),
 	}
 
-	return &ProgressBar{
-		mpb:  mpbv8.New(opts...),
-		bars: make(map[string]*progressBar),
+	switch {
+	case topView.Load():
+		// The bars only account the progress for the top view.
+		opts = append(opts, mpbv8.WithOutput(io.Discard))
+		p.top = newTopReporter(output, isTerminal(output))
+	case isTerminal(output):
+		opts = append(opts, mpbv8.WithAutoRefresh(), mpbv8.WithOutput(output))
+	default:
+		// The bars only account the progress as the ANSI bars render poorly in the redirected output.
+		opts = append(opts, mpbv8.WithOutput(io.Discard))
+		p.plain = newPlainReporter(output)
 	}
+
+	p.mpb = mpbv8.New(opts...)
+	return p
 }
 
 // Add adds a new progress bar.
@@ -107,13 +171,15 @@ func (p *ProgressBar) Add(prompt, name string, size int64, reader io.Reader) io.
 		msg:       fmt.Sprintf("%s %s", prompt, name),
 		startTime: time.Now(),
 	}
+	p.register(newBar.msg)
+
 	// Create a new bar if it does not exist.
 	newBar.Bar = p.mpb.New(size,
 		mpbv8.BarStyle(),
 		mpbv8.BarFillerOnComplete("|"),
 		mpbv8.PrependDecorators(
 			decor.Any(func(s decor.Statistics) string {
-				return newBar.msg
+				return p.display(newBar.msg)
 			}, decor.WCSyncSpaceR),
 		),
 		mpbv8.AppendDecorators(
@@ -156,7 +222,10 @@ func (p *ProgressBar) Complete(name string, msg string) {
 	p.mu.RUnlock()
 
 	if ok {
+		p.register(msg)
+		p.mu.Lock()
 		bar.msg = msg
+		p.mu.Unlock()
 		bar.Bar.SetCurrent(bar.size)
 	}
 }
@@ -173,10 +242,61 @@ func (p *ProgressBar) Abort(name string, err error) {
 	}
 }
 
-// Start starts the progress bar.
-func (p *ProgressBar) Start() {}
+// register registers the digests in the message to compute the unique short digests.
+func (p *ProgressBar) register(msg string) {
+	if !shortDigest.Load() {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	added := false
+	for _, digest := range shortdigest.Find(msg) {
+		if _, ok := p.shorts[digest]; !ok {
+			p.shorts[digest] = digest
+			p.digests = append(p.digests, digest)
+			added = true
+		}
+	}
+
+	if added {
+		p.shorts = shortdigest.Unique(p.digests, shortdigest.DefaultLength)
+	}
+}
+
+// display returns the message to display, the digests are replaced by the short digests if enabled.
+func (p *ProgressBar) display(msg string) string {
+	if !shortDigest.Load() {
+		return msg
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return shortdigest.Replace(msg, p.shorts)
+}
+
+// Start starts printing the progress periodically for the outputs other than the terminal and
+// the top view, the printing is stopped by Stop.
+func (p *ProgressBar) Start() {
+	if p.plain != nil {
+		p.plain.start(p)
+	}
+
+	if p.top != nil {
+		p.top.start(p)
+	}
+}
 
 // Stop waits for the progress bar to finish.
 func (p *ProgressBar) Stop() {
 	p.mpb.Shutdown()
+	if p.plain != nil {
+		p.plain.stop(p)
+	}
+
+	if p.top != nil {
+		p.top.stop(p)
+	}
 }
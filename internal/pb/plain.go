@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pb
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"golang.org/x/term"
+)
+
+// plainRefreshInterval is the interval of printing the progress as the plain lines.
+var plainRefreshInterval = 10 * time.Second
+
+// isTerminal returns whether the output is a terminal, the outputs other than the files are never terminals.
+func isTerminal(output io.Writer) bool {
+	f, ok := output.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// plainReporter prints the progress of the bars as the plain lines periodically instead of the ANSI
+// bars, which is used when the output is redirected to a file or piped to another command.
+type plainReporter struct {
+	mu     sync.Mutex
+	output io.Writer
+
+	// reported is the last printed line of the bars, the unchanged lines are not printed again.
+	reported map[any]string
+
+	done      chan struct{}
+	stopped   chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// newPlainReporter creates the plain reporter printing to the output.
+func newPlainReporter(output io.Writer) *plainReporter {
+	return &plainReporter{
+		output:   output,
+		reported: make(map[any]string),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// start starts printing the progress of the bars periodically, it's a no-op once the reporter is stopped.
+func (r *plainReporter) start(p *ProgressBar) {
+	r.startOnce.Do(func() {
+		go r.run(p)
+	})
+}
+
+// run prints the progress of the bars periodically until the reporter is stopped.
+func (r *plainReporter) run(p *ProgressBar) {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(plainRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report(p)
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// stop stops the periodic printing and prints the final progress of the bars.
+func (r *plainReporter) stop(p *ProgressBar) {
+	r.stopOnce.Do(func() {
+		// the reporter which never started has no goroutine to wait for.
+		r.startOnce.Do(func() {
+			close(r.stopped)
+		})
+
+		close(r.done)
+		<-r.stopped
+		r.report(p)
+	})
+}
+
+// report prints the progress of the bars which changed since the last report.
+func (r *plainReporter) report(p *ProgressBar) {
+	if disableProgress.Load() {
+		return
+	}
+
+	p.mu.RLock()
+	bars := make([]*progressBar, 0, len(p.bars))
+	msgs := make(map[*progressBar]string, len(p.bars))
+	for _, bar := range p.bars {
+		bars = append(bars, bar)
+		msgs[bar] = bar.msg
+	}
+	aggregates := append([]*Aggregate(nil), p.aggregates...)
+	p.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := []string{}
+	update := func(key any, line string) {
+		if r.reported[key] != line {
+			r.reported[key] = line
+			lines = append(lines, line)
+		}
+	}
+
+	for _, bar := range bars {
+		if bar.Aborted() {
+			continue
+		}
+
+		msg := p.display(msgs[bar])
+		current := bar.Current()
+		if bar.Completed() || (bar.size > 0 && current >= bar.size) {
+			update(bar, fmt.Sprintf("%s done %s", msg, humanize.Bytes(uint64(bar.size))))
+			continue
+		}
+
+		update(bar, fmt.Sprintf("%s %d%% (%s / %s)", msg, percent(current, bar.size), humanize.Bytes(uint64(current)), humanize.Bytes(uint64(bar.size))))
+	}
+
+	for _, a := range aggregates {
+		stats := a.Stats()
+		prefix := fmt.Sprintf("%s %s files %d/%d", a.prompt, a.group, stats.Completed, stats.Files)
+		if a.bar != nil && a.bar.Completed() {
+			update(a, fmt.Sprintf("%s done %s", prefix, humanize.Bytes(uint64(stats.Total))))
+			continue
+		}
+
+		update(a, fmt.Sprintf("%s %d%% (%s / %s)", prefix, percent(stats.Current, stats.Total), humanize.Bytes(uint64(stats.Current)), humanize.Bytes(uint64(stats.Total))))
+	}
+
+	// Sort the lines to print the bars in a stable order as the bars are stored in the map.
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(r.output, line)
+	}
+}
+
+// percent returns the percentage of the current in the total.
+func percent(current, total int64) int64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return min(current*100/total, 100)
+}
@@ -0,0 +1,119 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pb
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is the buffer safe to be written by the reporter and read by the test concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, isTerminal(&bytes.Buffer{}))
+	assert.False(t, isTerminal(io.Discard))
+}
+
+func TestProgressBarPlain(t *testing.T) {
+	out := &syncBuffer{}
+	p := NewProgressBar(out)
+	require.NotNil(t, p.plain)
+
+	reader := p.Add(NormalizePrompt("Pulling blob"), "model.safetensors", 100, bytes.NewReader(bytes.Repeat([]byte("x"), 100)))
+	_, err := io.CopyN(io.Discard, reader, 50)
+	require.NoError(t, err)
+
+	p.plain.report(p)
+	assert.Equal(t, "Pulling blob => model.safetensors 50% (50 B / 100 B)\n", out.String())
+
+	// the unchanged progress is not printed again.
+	p.plain.report(p)
+	assert.Equal(t, "Pulling blob => model.safetensors 50% (50 B / 100 B)\n", out.String())
+
+	_, err = io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	p.Complete("model.safetensors", NormalizePrompt("Pulled blob")+" model.safetensors")
+	p.Stop()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, []string{
+		"Pulling blob => model.safetensors 50% (50 B / 100 B)",
+		"Pulled blob => model.safetensors done 100 B",
+	}, lines)
+	assert.NotContains(t, out.String(), "\x1b[")
+}
+
+func TestProgressBarPlainPeriodic(t *testing.T) {
+	interval := plainRefreshInterval
+	plainRefreshInterval = 10 * time.Millisecond
+	defer func() { plainRefreshInterval = interval }()
+
+	out := &syncBuffer{}
+	p := NewProgressBar(out)
+	p.Start()
+	defer p.Stop()
+
+	aggregate := p.NewAggregate(NormalizePrompt("Building layers"), "code")
+	reader := aggregate.Add("main.py", 10, bytes.NewReader([]byte("print(1)\n\n")))
+	_, err := io.CopyN(io.Discard, reader, 4)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "Building layers => code files 0/1 40% (4 B / 10 B)\n")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NotContains(t, out.String(), "\x1b[")
+}
+
+func TestProgressBarPlainStop(t *testing.T) {
+	// the reporter of the bar which never started has no goroutine to wait for, and it is not
+	// started after the bar is stopped.
+	p := NewProgressBar(&syncBuffer{})
+	p.Stop()
+	p.Start()
+	_, ok := <-p.plain.stopped
+	assert.False(t, ok)
+
+	// the reporter goroutine of the started bar exits on stop.
+	p = NewProgressBar(&syncBuffer{})
+	p.Start()
+	p.Stop()
+	_, ok = <-p.plain.stopped
+	assert.False(t, ok)
+}
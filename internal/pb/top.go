@@ -0,0 +1,290 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pb
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+const (
+	// topMaxRows is the maximum number of the active transfers displayed by the top view,
+	// the slower ones are folded into one line.
+	topMaxRows = 10
+)
+
+// topRefreshInterval is the interval of redrawing the top view on the terminal, the view is
+// printed every plainRefreshInterval if the output is not a terminal.
+var topRefreshInterval = time.Second
+
+// transferSample is the progress of a transfer sampled by the top view.
+type transferSample struct {
+	name      string
+	msg       string
+	current   int64
+	size      int64
+	completed bool
+	aborted   bool
+	startTime time.Time
+}
+
+// transferRate is the progress and the rate of an active transfer.
+type transferRate struct {
+	msg     string
+	current int64
+	size    int64
+	rate    float64
+}
+
+// topSummary is the summary of the transfers displayed by the top view.
+type topSummary struct {
+	// active is the active transfers sorted by the rates in descending order.
+	active []transferRate
+
+	total     int
+	completed int
+	aborted   int
+
+	// current and size are the bytes transferred and the total bytes of the transfers not aborted.
+	current int64
+	size    int64
+
+	// rate is the overall rate in bytes per second since the last sample.
+	rate float64
+}
+
+// topAggregator aggregates the samples of the transfers into the summary, the rates are
+// computed by the bytes transferred between the consecutive samples.
+type topAggregator struct {
+	last     map[string]int64
+	lastTime time.Time
+}
+
+// newTopAggregator creates the aggregator of the transfers.
+func newTopAggregator() *topAggregator {
+	return &topAggregator{last: make(map[string]int64)}
+}
+
+// aggregate aggregates the samples of the transfers at the time into the summary.
+func (a *topAggregator) aggregate(samples []transferSample, now time.Time) topSummary {
+	summary := topSummary{total: len(samples)}
+	var transferred int64
+	var elapsed time.Duration
+	last := make(map[string]int64, len(samples))
+	for _, sample := range samples {
+		last[sample.name] = sample.current
+		if !sample.aborted {
+			summary.current += sample.current
+			summary.size += sample.size
+		}
+
+		// the transfer started after the last sample is measured since its start.
+		since := a.lastTime
+		if since.Before(sample.startTime) {
+			since = sample.startTime
+		}
+
+		// the transfer restarted by the retry transfers less than the last sample.
+		delta := max(sample.current-a.last[sample.name], 0)
+		transferred += delta
+		elapsed = max(elapsed, now.Sub(since))
+
+		switch {
+		case sample.aborted:
+			summary.aborted++
+		case sample.completed || (sample.size > 0 && sample.current >= sample.size):
+			summary.completed++
+		default:
+			var rate float64
+			if d := now.Sub(since); d > 0 {
+				rate = float64(delta) / d.Seconds()
+			}
+
+			summary.active = append(summary.active, transferRate{msg: sample.msg, current: sample.current, size: sample.size, rate: rate})
+		}
+	}
+
+	if elapsed > 0 {
+		summary.rate = float64(transferred) / elapsed.Seconds()
+	}
+
+	sort.SliceStable(summary.active, func(i, j int) bool {
+		if summary.active[i].rate != summary.active[j].rate {
+			return summary.active[i].rate > summary.active[j].rate
+		}
+
+		return summary.active[i].msg < summary.active[j].msg
+	})
+
+	a.last, a.lastTime = last, now
+	return summary
+}
+
+// eta returns the estimated time to complete all the transfers at the overall rate, or zero if unknown.
+func (s topSummary) eta() time.Duration {
+	if s.rate <= 0 || s.current >= s.size {
+		return 0
+	}
+
+	return time.Duration(float64(s.size-s.current) / s.rate * float64(time.Second)).Round(time.Second)
+}
+
+// lines returns the lines of the top view, the active transfers beyond the rows are folded into one line.
+func (s topSummary) lines(rows int) []string {
+	header := fmt.Sprintf("Transfers: %d active, %d/%d done", len(s.active), s.completed, s.total)
+	if s.aborted > 0 {
+		header += fmt.Sprintf(", %d failed", s.aborted)
+	}
+
+	header += fmt.Sprintf(" | %s / %s (%d%%) | %s/s", humanize.Bytes(uint64(s.current)), humanize.Bytes(uint64(s.size)), percent(s.current, s.size), humanize.Bytes(uint64(s.rate)))
+	if eta := s.eta(); eta > 0 {
+		header += fmt.Sprintf(" | ETA %s", eta)
+	}
+
+	lines := []string{header}
+	for i, transfer := range s.active {
+		if i == rows {
+			lines = append(lines, fmt.Sprintf("  ... %d more", len(s.active)-rows))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("  %10s/s %3d%% %21s  %s", humanize.Bytes(uint64(transfer.rate)), percent(transfer.current, transfer.size),
+			humanize.Bytes(uint64(transfer.current))+" / "+humanize.Bytes(uint64(transfer.size)), transfer.msg))
+	}
+
+	return lines
+}
+
+// topReporter displays the top view of the bars, which is redrawn in place on the terminal
+// and printed periodically otherwise.
+type topReporter struct {
+	mu         sync.Mutex
+	output     io.Writer
+	terminal   bool
+	aggregator *topAggregator
+
+	// drawn is the number of the lines drawn last time on the terminal, which are erased on redrawing.
+	drawn int
+
+	done      chan struct{}
+	stopped   chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// newTopReporter creates the top reporter displaying to the output.
+func newTopReporter(output io.Writer, terminal bool) *topReporter {
+	return &topReporter{
+		output:     output,
+		terminal:   terminal,
+		aggregator: newTopAggregator(),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// start starts displaying the top view periodically, it's a no-op once the reporter is stopped.
+func (r *topReporter) start(p *ProgressBar) {
+	r.startOnce.Do(func() {
+		go r.run(p)
+	})
+}
+
+// run displays the top view periodically until the reporter is stopped.
+func (r *topReporter) run(p *ProgressBar) {
+	defer close(r.stopped)
+
+	interval := plainRefreshInterval
+	if r.terminal {
+		interval = topRefreshInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report(p)
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// stop stops the periodic display and displays the final top view.
+func (r *topReporter) stop(p *ProgressBar) {
+	r.stopOnce.Do(func() {
+		// the reporter which never started has no goroutine to wait for.
+		r.startOnce.Do(func() {
+			close(r.stopped)
+		})
+
+		close(r.done)
+		<-r.stopped
+		r.report(p)
+	})
+}
+
+// report samples the bars and displays the top view.
+func (r *topReporter) report(p *ProgressBar) {
+	if disableProgress.Load() {
+		return
+	}
+
+	p.mu.RLock()
+	samples := make([]transferSample, 0, len(p.bars))
+	for name, bar := range p.bars {
+		samples = append(samples, transferSample{
+			name:      name,
+			msg:       bar.msg,
+			current:   bar.Current(),
+			size:      bar.size,
+			completed: bar.Completed(),
+			aborted:   bar.Aborted(),
+			startTime: bar.startTime,
+		})
+	}
+	p.mu.RUnlock()
+
+	for i := range samples {
+		samples[i].msg = p.display(samples[i].msg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := r.aggregator.aggregate(samples, time.Now()).lines(topMaxRows)
+	var frame strings.Builder
+	if r.terminal && r.drawn > 0 {
+		// move the cursor up to the first line drawn last time and erase the lines below.
+		fmt.Fprintf(&frame, "\x1b[%dA\x1b[J", r.drawn)
+	}
+
+	for _, line := range lines {
+		frame.WriteString(line + "\n")
+	}
+
+	r.drawn = len(lines)
+	fmt.Fprint(r.output, frame.String())
+}
@@ -0,0 +1,145 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopAggregator(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTopAggregator()
+
+	summary := a.aggregate([]transferSample{
+		{name: "a", msg: "Pulling blob => a", current: 100, size: 1000, startTime: start},
+		{name: "b", msg: "Pulling blob => b", current: 300, size: 1000, startTime: start},
+		{name: "c", msg: "Pulling blob => c", current: 0, size: 1000, startTime: start.Add(time.Second)},
+	}, start.Add(2*time.Second))
+
+	assert.Equal(t, 3, summary.total)
+	assert.Equal(t, 0, summary.completed)
+	assert.Equal(t, int64(400), summary.current)
+	assert.Equal(t, int64(3000), summary.size)
+	assert.Equal(t, float64(200), summary.rate)
+	assert.Equal(t, []transferRate{
+		{msg: "Pulling blob => b", current: 300, size: 1000, rate: 150},
+		{msg: "Pulling blob => a", current: 100, size: 1000, rate: 50},
+		{msg: "Pulling blob => c", current: 0, size: 1000, rate: 0},
+	}, summary.active)
+
+	// the rates are computed by the bytes transferred since the last sample.
+	summary = a.aggregate([]transferSample{
+		{name: "a", msg: "Pulled blob => a", current: 1000, size: 1000, completed: true, startTime: start},
+		{name: "b", msg: "Pulling blob => b", current: 400, size: 1000, startTime: start},
+		{name: "c", msg: "Pulling blob => c", current: 0, size: 1000, aborted: true, startTime: start.Add(time.Second)},
+		{name: "d", msg: "Pulling blob => d", current: 500, size: 1000, startTime: start.Add(3 * time.Second)},
+	}, start.Add(4*time.Second))
+
+	assert.Equal(t, 4, summary.total)
+	assert.Equal(t, 1, summary.completed)
+	assert.Equal(t, 1, summary.aborted)
+	assert.Equal(t, int64(1900), summary.current)
+	assert.Equal(t, int64(3000), summary.size)
+	assert.Equal(t, float64(750), summary.rate)
+	assert.Equal(t, []transferRate{
+		{msg: "Pulling blob => d", current: 500, size: 1000, rate: 500},
+		{msg: "Pulling blob => b", current: 400, size: 1000, rate: 50},
+	}, summary.active)
+	assert.Equal(t, time.Second, summary.eta())
+
+	// the transfer restarted by the retry never has the negative rate.
+	summary = a.aggregate([]transferSample{
+		{name: "b", msg: "Pulling blob => b", current: 100, size: 1000, startTime: start.Add(5 * time.Second)},
+	}, start.Add(6*time.Second))
+	assert.Equal(t, float64(0), summary.active[0].rate)
+	assert.Equal(t, float64(0), summary.rate)
+	assert.Equal(t, time.Duration(0), summary.eta())
+}
+
+func TestTopSummaryLines(t *testing.T) {
+	summary := topSummary{
+		active: []transferRate{
+			{msg: "Pulling blob => b", current: 400, size: 1000, rate: 1500},
+			{msg: "Pulling blob => a", current: 100, size: 1000, rate: 50},
+			{msg: "Pulling blob => c", current: 0, size: 1000, rate: 0},
+		},
+		total:     5,
+		completed: 1,
+		aborted:   1,
+		current:   2500,
+		size:      5000,
+		rate:      500,
+	}
+
+	assert.Equal(t, []string{
+		"Transfers: 3 active, 1/5 done, 1 failed | 2.5 kB / 5.0 kB (50%) | 500 B/s | ETA 5s",
+		"      1.5 kB/s  40%        400 B / 1.0 kB  Pulling blob => b",
+		"        50 B/s  10%        100 B / 1.0 kB  Pulling blob => a",
+		"  ... 1 more",
+	}, summary.lines(2))
+}
+
+func TestProgressBarTop(t *testing.T) {
+	SetTopView(true)
+	defer SetTopView(false)
+
+	out := &syncBuffer{}
+	p := NewProgressBar(out)
+	require.NotNil(t, p.top)
+	require.Nil(t, p.plain)
+
+	reader := p.Add(NormalizePrompt("Pulling blob"), "model.safetensors", 100, bytes.NewReader(bytes.Repeat([]byte("x"), 100)))
+	_, err := io.CopyN(io.Discard, reader, 50)
+	require.NoError(t, err)
+	p.Add(NormalizePrompt("Pulling blob"), "config.json", 10, nil)
+	p.Abort("config.json", errors.New("connection reset"))
+
+	p.top.report(p)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "Transfers: 1 active, 0/2 done, 1 failed | 50 B / 100 B (50%)"), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], " 50%          50 B / 100 B  Pulling blob => model.safetensors"), lines[1])
+
+	_, err = io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	p.Complete("model.safetensors", NormalizePrompt("Pulled blob")+" model.safetensors")
+	p.Stop()
+
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "Transfers: 0 active, 1/2 done, 1 failed | 100 B / 100 B (100%)"), lines[len(lines)-1])
+	assert.NotContains(t, out.String(), "\x1b[")
+}
+
+func TestTopReporterRedraw(t *testing.T) {
+	out := &syncBuffer{}
+	r := newTopReporter(out, true)
+	p := &ProgressBar{bars: map[string]*progressBar{}}
+
+	r.report(p)
+	r.report(p)
+
+	// the lines drawn last time are erased on the terminal.
+	assert.Equal(t, "Transfers: 0 active, 0/0 done | 0 B / 0 B (0%) | 0 B/s\n\x1b[1A\x1b[JTransfers: 0 active, 0/0 done | 0 B / 0 B (0%) | 0 B/s\n", out.String())
+}
@@ -0,0 +1,122 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shortdigest
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLength is the default length of the short digest, the algorithm prefix is excluded.
+const DefaultLength = 12
+
+// digestRegexp matches the digests embedded in the text, such as the progress messages.
+var digestRegexp = regexp.MustCompile(`\b[a-z0-9]+:[a-f0-9]{32,}\b`)
+
+// Shorten returns the first length characters of the encoded part of the digest, the algorithm
+// prefix such as sha256: is dropped. The value which is not a digest is returned as is.
+func Shorten(digest string, length int) string {
+	encoded, ok := encodedPart(digest)
+	if !ok || len(encoded) <= length {
+		return digest
+	}
+
+	return encoded[:length]
+}
+
+// Unique returns the short digests of the set keyed by the full digests, the short digest is
+// extended beyond the length if its prefix collides with another digest in the set, so every
+// distinct digest is displayed uniquely. The values which are not digests are mapped as is.
+func Unique(digests []string, length int) map[string]string {
+	shorts := make(map[string]string, len(digests))
+	encodeds := []string{}
+	seen := map[string]bool{}
+	for _, digest := range digests {
+		encoded, ok := encodedPart(digest)
+		if !ok {
+			shorts[digest] = digest
+			continue
+		}
+
+		if !seen[encoded] {
+			seen[encoded] = true
+			encodeds = append(encodeds, encoded)
+		}
+	}
+
+	// the longest common prefix with any other digest is shared with one of the neighbours in the sorted order.
+	sort.Strings(encodeds)
+	lengths := make(map[string]int, len(encodeds))
+	for i, encoded := range encodeds {
+		n := length
+		if i > 0 {
+			n = max(n, commonPrefix(encoded, encodeds[i-1])+1)
+		}
+
+		if i < len(encodeds)-1 {
+			n = max(n, commonPrefix(encoded, encodeds[i+1])+1)
+		}
+
+		lengths[encoded] = n
+	}
+
+	for _, digest := range digests {
+		if encoded, ok := encodedPart(digest); ok {
+			shorts[digest] = Shorten(digest, lengths[encoded])
+		}
+	}
+
+	return shorts
+}
+
+// Find returns the digests embedded in the text.
+func Find(text string) []string {
+	return digestRegexp.FindAllString(text, -1)
+}
+
+// Replace replaces the digests embedded in the text by the short digests, the digests which
+// are not in the shorts are kept as is.
+func Replace(text string, shorts map[string]string) string {
+	return digestRegexp.ReplaceAllStringFunc(text, func(digest string) string {
+		if short, ok := shorts[digest]; ok {
+			return short
+		}
+
+		return digest
+	})
+}
+
+// encodedPart returns the encoded part of the digest in the algorithm:encoded form.
+func encodedPart(digest string) (string, bool) {
+	algorithm, encoded, ok := strings.Cut(digest, ":")
+	if !ok || algorithm == "" || encoded == "" {
+		return "", false
+	}
+
+	return encoded, true
+}
+
+// commonPrefix returns the length of the common prefix of a and b.
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
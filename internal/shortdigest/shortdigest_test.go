@@ -0,0 +1,65 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shortdigest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	digestA = "sha256:9ca701e8784e5656e2c36f10f82410a0af4c44f859590a28a3d1519ee1eea89d"
+	// digestB shares the first 14 characters with digestA.
+	digestB = "sha256:9ca701e8784e56ffe2c36f10f82410a0af4c44f859590a28a3d1519ee1eea89d"
+	digestC = "sha256:e31b55920173ba79526491fbd01efe609c1d0d72c3a83df85b2c4fe74df2eea2"
+)
+
+func TestShorten(t *testing.T) {
+	assert.Equal(t, "9ca701e8784e", Shorten(digestA, DefaultLength))
+	assert.Equal(t, "9ca701", Shorten(digestA, 6))
+	assert.Equal(t, "sha256:abc", Shorten("sha256:abc", DefaultLength))
+	assert.Equal(t, "not-a-digest", Shorten("not-a-digest", DefaultLength))
+}
+
+func TestUnique(t *testing.T) {
+	t.Run("truncated", func(t *testing.T) {
+		assert.Equal(t, map[string]string{
+			digestA: "9ca701e8784e",
+			digestC: "e31b55920173",
+		}, Unique([]string{digestA, digestC, digestA}, DefaultLength))
+	})
+
+	t.Run("extended on collision", func(t *testing.T) {
+		shorts := Unique([]string{digestA, digestB, digestC}, DefaultLength)
+		assert.Equal(t, "9ca701e8784e565", shorts[digestA])
+		assert.Equal(t, "9ca701e8784e56f", shorts[digestB])
+		assert.Equal(t, "e31b55920173", shorts[digestC])
+		assert.NotEqual(t, shorts[digestA], shorts[digestB])
+	})
+
+	t.Run("not digests", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"": "", "latest": "latest"}, Unique([]string{"", "latest"}, DefaultLength))
+	})
+}
+
+func TestFindAndReplace(t *testing.T) {
+	text := "Pulling blob => " + digestA + ", skipped " + digestB
+	assert.Equal(t, []string{digestA, digestB}, Find(text))
+	assert.Equal(t, "Pulling blob => 9ca701e8784e565, skipped "+digestB, Replace(text, map[string]string{digestA: "9ca701e8784e565"}))
+	assert.Empty(t, Find("Building config => config"))
+}
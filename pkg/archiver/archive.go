@@ -0,0 +1,266 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveEntry is the regular file entry in the tar archive.
+type ArchiveEntry struct {
+	// Header is the tar header of the entry, the name is normalized to the clean relative path.
+	Header *tar.Header
+	// offset is the offset of the entry content in the archive.
+	offset int64
+}
+
+// Archive is the index of an uncompressed tar archive, which allows reading the
+// entries randomly and concurrently without extracting them to the disk.
+type Archive struct {
+	file    io.ReaderAt
+	entries map[string]*ArchiveEntry
+	names   []string
+}
+
+// OpenArchive opens the uncompressed tar archive and indexes its regular file entries.
+func OpenArchive(archivePath string) (*Archive, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	archive := &Archive{file: file, entries: make(map[string]*ArchiveEntry)}
+	if err := archive.index(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to index archive %s: %w", archivePath, err)
+	}
+
+	return archive, nil
+}
+
+// NewMemoryArchive creates the archive of the in-memory files keyed by the relative
+// path, which can be built in the same way as the tar archive without a workspace.
+func NewMemoryArchive(files map[string][]byte) (*Archive, error) {
+	cleanedFiles := make(map[string][]byte, len(files))
+	archive := &Archive{entries: make(map[string]*ArchiveEntry)}
+	for name, data := range files {
+		cleaned, err := cleanEntryName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := cleanedFiles[cleaned]; ok {
+			return nil, fmt.Errorf("duplicate file %s", name)
+		}
+
+		cleanedFiles[cleaned] = data
+		archive.names = append(archive.names, cleaned)
+	}
+	sort.Strings(archive.names)
+
+	// Lay out the files in a single buffer in the order of the names, so the
+	// entries are read by the offsets in the same way as the tar archive.
+	var (
+		content []byte
+		offset  int64
+	)
+	for _, name := range archive.names {
+		data := cleanedFiles[name]
+		archive.entries[name] = &ArchiveEntry{
+			Header: &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     name,
+				Mode:     0644,
+				Size:     int64(len(data)),
+				ModTime:  time.Unix(0, 0),
+			},
+			offset: offset,
+		}
+		content = append(content, data...)
+		offset += int64(len(data))
+	}
+
+	archive.file = bytes.NewReader(content)
+	return archive, nil
+}
+
+// index walks the tar headers and records the offset of each regular file, the
+// content is skipped by seeking so the archive is not read entirely.
+func (a *Archive) index(file io.ReadSeeker) error {
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name, err := cleanEntryName(header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg:
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+			continue
+		case tar.TypeGNUSparse:
+			return fmt.Errorf("sparse entry %s is not supported", header.Name)
+		default:
+			return fmt.Errorf("entry %s with type %q is not supported", header.Name, header.Typeflag)
+		}
+
+		// The tar reader consumes the header blocks exactly, so the current
+		// position is the beginning of the entry content.
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := a.entries[name]; !ok {
+			a.names = append(a.names, name)
+		}
+
+		header.Name = name
+		a.entries[name] = &ArchiveEntry{Header: header, offset: offset}
+	}
+
+	sort.Strings(a.names)
+	return nil
+}
+
+// Names returns the sorted names of the regular file entries.
+func (a *Archive) Names() []string {
+	return a.names
+}
+
+// Entry returns the entry by name.
+func (a *Archive) Entry(name string) (*ArchiveEntry, bool) {
+	entry, ok := a.entries[path.Clean(name)]
+	return entry, ok
+}
+
+// IsDir returns whether the name is a directory containing the entries, the
+// directory entries themselves are not indexed so it is derived from the names.
+func (a *Archive) IsDir(name string) bool {
+	prefix := path.Clean(name) + "/"
+	i := sort.SearchStrings(a.names, prefix)
+	return i < len(a.names) && strings.HasPrefix(a.names[i], prefix)
+}
+
+// Match returns the sorted names of the entries matching the pattern, the
+// pattern follows the syntax of path.Match.
+func (a *Archive) Match(pattern string) ([]string, error) {
+	pattern = path.Clean(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, name := range a.names {
+		if ok, _ := path.Match(pattern, name); ok {
+			matches = append(matches, name)
+		}
+	}
+
+	return matches, nil
+}
+
+// Open returns the reader of the entry content, which is safe to use concurrently
+// with the readers of other entries.
+func (a *Archive) Open(name string) (*io.SectionReader, error) {
+	entry, ok := a.Entry(name)
+	if !ok {
+		return nil, fmt.Errorf("entry %s does not exist in archive", name)
+	}
+
+	return io.NewSectionReader(a.file, entry.offset, entry.Header.Size), nil
+}
+
+// Tar returns the single entry as a tar stream, which is the same as the
+// result of Tar for a file with the entry name as the relative path.
+func (a *Archive) Tar(name string) (io.Reader, error) {
+	entry, ok := a.Entry(name)
+	if !ok {
+		return nil, fmt.Errorf("entry %s does not exist in archive", name)
+	}
+
+	content, err := a.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		// Only keep the fields of the header written by Tar for the files, the owner names and
+		// the PAX records of the source archive would make the layer differ from the same file.
+		header := tar.Header{
+			Typeflag: entry.Header.Typeflag,
+			Name:     entry.Header.Name,
+			Mode:     entry.Header.Mode,
+			Uid:      entry.Header.Uid,
+			Gid:      entry.Header.Gid,
+			Size:     entry.Header.Size,
+			ModTime:  entry.Header.ModTime,
+		}
+		if err := tw.WriteHeader(&header); err != nil {
+			pw.CloseWithError(fmt.Errorf("failed to write header: %w", err))
+			return
+		}
+
+		if _, err := io.Copy(tw, content); err != nil {
+			pw.CloseWithError(fmt.Errorf("failed to copy entry to tar: %w", err))
+			return
+		}
+
+		pw.CloseWithError(tw.Close())
+	}()
+
+	return pr, nil
+}
+
+// Close closes the archive.
+func (a *Archive) Close() error {
+	if closer, ok := a.file.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// cleanEntryName normalizes the entry name to the relative path and rejects
+// the names escaping the archive root.
+func cleanEntryName(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("archive contains invalid path: %s", name)
+	}
+
+	return cleaned, nil
+}
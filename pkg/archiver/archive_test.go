@@ -0,0 +1,281 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeArchive writes the tar archive with the files, the directories are added as well.
+func writeArchive(t *testing.T, archivePath string, files map[string]string, names ...string) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "./", Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		content := files[name]
+		header := &tar.Header{
+			Typeflag:   tar.TypeReg,
+			Name:       name,
+			Mode:       0640,
+			Size:       int64(len(content)),
+			Uname:      "builder",
+			Gname:      "builder",
+			PAXRecords: map[string]string{"comment": "exported by the build host"},
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"./config.json":                "{}",
+		"model-00001.safetensors":      string(bytes.Repeat([]byte("a"), 1500)),
+		"model-00002.safetensors":      string(bytes.Repeat([]byte("b"), 700)),
+		"tokenizer/tokenizer.json":     "tokenizer",
+		"tokenizer/special_tokens.txt": "",
+	}
+	archivePath := filepath.Join(tmpDir, "model.tar")
+	writeArchive(t, archivePath, files, "model-00002.safetensors", "./config.json", "tokenizer/tokenizer.json", "model-00001.safetensors", "tokenizer/special_tokens.txt")
+
+	archive, err := OpenArchive(archivePath)
+	if err != nil {
+		t.Fatalf("OpenArchive error: %v", err)
+	}
+	defer archive.Close()
+
+	expectedNames := []string{"config.json", "model-00001.safetensors", "model-00002.safetensors", "tokenizer/special_tokens.txt", "tokenizer/tokenizer.json"}
+	if !reflect.DeepEqual(archive.Names(), expectedNames) {
+		t.Fatalf("unexpected names: %v", archive.Names())
+	}
+
+	for name, content := range files {
+		reader, err := archive.Open(name)
+		if err != nil {
+			t.Fatalf("Open %s error: %v", name, err)
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("read %s error: %v", name, err)
+		}
+
+		if string(data) != content {
+			t.Fatalf("unexpected content of %s", name)
+		}
+	}
+
+	matches, err := archive.Match("*.safetensors")
+	if err != nil {
+		t.Fatalf("Match error: %v", err)
+	}
+	if !reflect.DeepEqual(matches, []string{"model-00001.safetensors", "model-00002.safetensors"}) {
+		t.Fatalf("unexpected matches: %v", matches)
+	}
+
+	matches, err = archive.Match("tokenizer/*.json")
+	if err != nil {
+		t.Fatalf("Match error: %v", err)
+	}
+	if !reflect.DeepEqual(matches, []string{"tokenizer/tokenizer.json"}) {
+		t.Fatalf("unexpected matches: %v", matches)
+	}
+
+	if _, err := archive.Match("["); err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+
+	if _, err := archive.Open("missing.txt"); err == nil {
+		t.Fatal("expected error for missing entry")
+	}
+}
+
+func TestArchiveTar(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"config.json":              "{\"model_type\": \"llama\"}",
+		"tokenizer/tokenizer.json": string(bytes.Repeat([]byte("t"), 1024)),
+	}
+	archivePath := filepath.Join(tmpDir, "model.tar")
+	writeArchive(t, archivePath, files, "config.json", "tokenizer/tokenizer.json")
+
+	archive, err := OpenArchive(archivePath)
+	if err != nil {
+		t.Fatalf("OpenArchive error: %v", err)
+	}
+	defer archive.Close()
+
+	extractDir := filepath.Join(tmpDir, "extracted")
+	for _, name := range archive.Names() {
+		reader, err := archive.Tar(name)
+		if err != nil {
+			t.Fatalf("Tar %s error: %v", name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := Untar(io.TeeReader(reader, &buf), extractDir); err != nil {
+			t.Fatalf("Untar %s error: %v", name, err)
+		}
+
+		// The owner names and the PAX records of the source archive are not kept.
+		header, err := tar.NewReader(&buf).Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Uname != "" || header.Gname != "" || len(header.PAXRecords) != 0 {
+			t.Fatalf("unexpected header of %s: %+v", name, header)
+		}
+	}
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(extractDir, name))
+		if err != nil {
+			t.Fatalf("read extracted %s error: %v", name, err)
+		}
+
+		if string(data) != content {
+			t.Fatalf("unexpected extracted content of %s", name)
+		}
+
+		info, err := os.Stat(filepath.Join(extractDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0640 {
+			t.Fatalf("unexpected mode of %s: %v", name, info.Mode().Perm())
+		}
+	}
+}
+
+func TestOpenArchiveInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	escaped := filepath.Join(tmpDir, "escaped.tar")
+	writeArchive(t, escaped, map[string]string{"../evil.txt": "evil"}, "../evil.txt")
+	if _, err := OpenArchive(escaped); err == nil {
+		t.Fatal("expected error for path escaping the archive")
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "link", Linkname: "/etc/passwd"}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	symlink := filepath.Join(tmpDir, "symlink.tar")
+	if err := os.WriteFile(symlink, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenArchive(symlink); err == nil {
+		t.Fatal("expected error for symlink entry")
+	}
+
+	if _, err := OpenArchive(filepath.Join(tmpDir, "missing.tar")); err == nil {
+		t.Fatal("expected error for missing archive")
+	}
+}
+
+func TestNewMemoryArchive(t *testing.T) {
+	files := map[string][]byte{
+		"./config.json":            []byte("{}"),
+		"model.safetensors":        bytes.Repeat([]byte("w"), 1500),
+		"tokenizer/tokenizer.json": []byte("tokenizer"),
+		"empty.txt":                {},
+	}
+
+	archive, err := NewMemoryArchive(files)
+	if err != nil {
+		t.Fatalf("NewMemoryArchive error: %v", err)
+	}
+	defer archive.Close()
+
+	expectedNames := []string{"config.json", "empty.txt", "model.safetensors", "tokenizer/tokenizer.json"}
+	if !reflect.DeepEqual(archive.Names(), expectedNames) {
+		t.Fatalf("unexpected names: %v", archive.Names())
+	}
+
+	for name, expected := range map[string]bool{"tokenizer": true, "tokenizer/": true, "tokenizer/tokenizer.json": false, "token": false, "config.json": false} {
+		if archive.IsDir(name) != expected {
+			t.Fatalf("unexpected IsDir of %s: %v", name, !expected)
+		}
+	}
+
+	extractDir := t.TempDir()
+	for name, content := range files {
+		reader, err := archive.Open(name)
+		if err != nil {
+			t.Fatalf("Open %s error: %v", name, err)
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("read %s error: %v", name, err)
+		}
+
+		if !bytes.Equal(data, content) {
+			t.Fatalf("unexpected content of %s", name)
+		}
+
+		tarReader, err := archive.Tar(name)
+		if err != nil {
+			t.Fatalf("Tar %s error: %v", name, err)
+		}
+
+		if err := Untar(tarReader, extractDir); err != nil {
+			t.Fatalf("Untar %s error: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(extractDir, "tokenizer", "tokenizer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "tokenizer" {
+		t.Fatalf("unexpected extracted content: %s", data)
+	}
+
+	if _, err := NewMemoryArchive(map[string][]byte{"../escape": nil}); err == nil {
+		t.Fatal("expected error for invalid path")
+	}
+
+	if _, err := NewMemoryArchive(map[string][]byte{"a.txt": nil, "./a.txt": nil}); err == nil {
+		t.Fatal("expected error for duplicate file")
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/modelpack/modctl/pkg/config"
 )
 
 // Tar creates a tar archive of the specified path (file or directory)
@@ -62,7 +64,7 @@ func Tar(srcPath string, workDir string) (io.Reader, error) {
 				}
 
 				// Set the header name to preserve directory structure.
-				header.Name = relPath
+				header.Name = tarHeaderName(relPath)
 				if err := tw.WriteHeader(header); err != nil {
 					return fmt.Errorf("failed to write header: %w", err)
 				}
@@ -110,7 +112,7 @@ func Tar(srcPath string, workDir string) (io.Reader, error) {
 			}
 
 			// Use the relative path (including directories) as the header name.
-			header.Name = relPath
+			header.Name = tarHeaderName(relPath)
 			if err := tw.WriteHeader(header); err != nil {
 				pw.CloseWithError(fmt.Errorf("failed to write header: %w", err))
 				return
@@ -126,9 +128,86 @@ func Tar(srcPath string, workDir string) (io.Reader, error) {
 	return pr, nil
 }
 
+// tarHeaderName returns the tar header name of the relative path, the separators of the OS
+// are normalized to the forward slashes required by the tar format, otherwise the archive
+// built on Windows contains the backslashes which break the extraction on Linux.
+func tarHeaderName(relPath string) string {
+	return toSlash(relPath, os.PathSeparator)
+}
+
+// toSlash replaces the separators in the path with the forward slashes.
+func toSlash(path string, separator rune) string {
+	if separator == '/' {
+		return path
+	}
+
+	return strings.ReplaceAll(path, string(separator), "/")
+}
+
+// UntarOption is the option of Untar.
+type UntarOption func(*untarOptions)
+
+type untarOptions struct {
+	// flatten indicates whether to extract the files into the destination
+	// path directly without the directory structure.
+	flatten bool
+	// conflictPolicy is the policy to resolve the conflict with the existing files.
+	conflictPolicy config.ConflictPolicy
+	// ownership maps the owners in the tar headers to the owners of the extracted files.
+	ownership *Ownership
+	// shortener shortens the paths exceeding the limits of the filesystem.
+	shortener *PathShortener
+	// rawNames indicates whether to keep the backslashes in the header names as is.
+	rawNames bool
+}
+
+// WithFlatten extracts the files into the destination path directly by the
+// base name, the directories in the archive are skipped.
+func WithFlatten() UntarOption {
+	return func(o *untarOptions) {
+		o.flatten = true
+	}
+}
+
+// WithConflictPolicy resolves the conflict with the existing files by the policy.
+func WithConflictPolicy(policy config.ConflictPolicy) UntarOption {
+	return func(o *untarOptions) {
+		o.conflictPolicy = policy
+	}
+}
+
+// WithOwnership sets the owners of the extracted files mapped from the owners in the tar headers,
+// the files are owned by the extracting user by default.
+func WithOwnership(ownership *Ownership) UntarOption {
+	return func(o *untarOptions) {
+		o.ownership = ownership
+	}
+}
+
+// WithPathShortener shortens the paths exceeding the limits of the filesystem by the shortener,
+// the extraction fails with ErrPathTooLong by default.
+func WithPathShortener(shortener *PathShortener) UntarOption {
+	return func(o *untarOptions) {
+		o.shortener = shortener
+	}
+}
+
+// WithRawNames keeps the backslashes in the tar header names as the characters of the file
+// names, which are legal on Linux, instead of the separators of the archives built on Windows.
+func WithRawNames() UntarOption {
+	return func(o *untarOptions) {
+		o.rawNames = true
+	}
+}
+
 // Untar extracts the contents of a tar archive from the provided reader
 // to the specified destination path.
-func Untar(reader io.Reader, destPath string) error {
+func Untar(reader io.Reader, destPath string, opts ...UntarOption) error {
+	options := &untarOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	tarReader := tar.NewReader(reader)
 
 	// Ensure destination directory exists.
@@ -136,6 +215,7 @@ func Untar(reader io.Reader, destPath string) error {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
+	var dirs []dirEntry
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
@@ -145,13 +225,39 @@ func Untar(reader io.Reader, destPath string) error {
 			return fmt.Errorf("error reading tar: %w", err)
 		}
 
+		// The header names of the archives built on Windows may be separated by the backslashes.
+		name := header.Name
+		if !options.rawNames {
+			name = toSlash(name, '\\')
+		}
+
 		// Sanitize file paths to prevent directory traversal.
-		cleanPath := filepath.Clean(header.Name)
+		cleanPath := filepath.Clean(filepath.FromSlash(name))
 		if strings.Contains(cleanPath, "..") || strings.HasPrefix(cleanPath, "/") || strings.HasPrefix(cleanPath, ":\\") {
 			return fmt.Errorf("tar file contains invalid path: %s", cleanPath)
 		}
 
+		if options.flatten {
+			if header.Typeflag == tar.TypeDir {
+				continue
+			}
+
+			cleanPath = filepath.Base(cleanPath)
+		}
+
+		if shortened := options.shortener.Shorten(destPath, cleanPath); shortened != cleanPath {
+			// the directories are created by the files within them, which are shortened independently.
+			if header.Typeflag == tar.TypeDir {
+				continue
+			}
+
+			cleanPath = shortened
+		}
+
 		targetPath := filepath.Join(destPath, cleanPath)
+		if err := CheckPathLength(targetPath); err != nil {
+			return err
+		}
 
 		// Create directories for all path components.
 		dirPath := filepath.Dir(targetPath)
@@ -161,48 +267,85 @@ func Untar(reader io.Reader, destPath string) error {
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err := os.MkdirAll(targetPath, os.FileMode(header.Mode)); err != nil {
+			// The directory is kept writable as the files are written into it by this and the
+			// concurrently extracted layers, its metadata is restored after all the entries.
+			if err := os.MkdirAll(targetPath, 0755); err != nil {
 				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
 			}
-			// Set correct permissions for the directory.
-			if err := os.Chmod(targetPath, os.FileMode(header.Mode)); err != nil {
-				return fmt.Errorf("failed to set directory permissions %s: %w", targetPath, err)
+
+			dirs = append(dirs, dirEntry{path: targetPath, header: header})
+
+		case tar.TypeReg:
+			extract, err := ResolveConflict(targetPath, options.conflictPolicy)
+			if err != nil {
+				return err
 			}
-			// Set modification time for the directory.
-			if err := os.Chtimes(targetPath, header.ModTime, header.ModTime); err != nil {
-				return fmt.Errorf("failed to set directory mtime %s: %w", targetPath, err)
+
+			if !extract {
+				continue
 			}
 
-		case tar.TypeReg:
-			file, err := os.OpenFile(
-				targetPath,
-				os.O_CREATE|os.O_RDWR|os.O_TRUNC,
-				os.FileMode(header.Mode),
-			)
+			// Write to the temporary file and rename it on success, so an interrupted
+			// extraction never leaves a partially-written file at the target path.
+			file, err := CreateAtomic(targetPath, os.FileMode(header.Mode))
 			if err != nil {
-				return fmt.Errorf("failed to create file %s: %w", targetPath, err)
+				return err
 			}
 
 			if _, err := io.Copy(file, tarReader); err != nil {
-				file.Close()
+				file.Abort()
 				return fmt.Errorf("failed to write to file %s: %w", targetPath, err)
 			}
-			file.Close()
 
-			// Set correct permissions for the directory.
-			if err := os.Chmod(targetPath, os.FileMode(header.Mode)); err != nil {
-				return fmt.Errorf("failed to set directory permissions %s: %w", targetPath, err)
-			}
-			// Set modification time for the file.
-			if err := os.Chtimes(targetPath, header.ModTime, header.ModTime); err != nil {
+			// Set modification time for the file before committing, which is preserved by the rename.
+			if err := os.Chtimes(file.Name(), header.ModTime, header.ModTime); err != nil {
+				file.Abort()
 				return fmt.Errorf("failed to set file mtime %s: %w", targetPath, err)
 			}
 
+			if err := options.ownership.Chown(file.Name(), header.Uid, header.Gid); err != nil {
+				file.Abort()
+				return err
+			}
+
+			if err := file.CommitWithPolicy(options.conflictPolicy); err != nil {
+				return err
+			}
+
 		default:
 			// Skip other types.
 			continue
 		}
 	}
 
+	return restoreDirs(dirs, options.ownership)
+}
+
+// dirEntry is the directory extracted from the tar archive with its header.
+type dirEntry struct {
+	path   string
+	header *tar.Header
+}
+
+// restoreDirs restores the permissions, modification times and owners of the directories after
+// the files are written into them, the nested directories are restored before their parents so
+// the modification times are not changed by the later writes and the read-only directories are
+// not locked before they are filled.
+func restoreDirs(dirs []dirEntry, ownership *Ownership) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+		if err := os.Chmod(dir.path, os.FileMode(dir.header.Mode)); err != nil {
+			return fmt.Errorf("failed to set directory permissions %s: %w", dir.path, err)
+		}
+
+		if err := os.Chtimes(dir.path, dir.header.ModTime, dir.header.ModTime); err != nil {
+			return fmt.Errorf("failed to set directory mtime %s: %w", dir.path, err)
+		}
+
+		if err := ownership.Chown(dir.path, dir.header.Uid, dir.header.Gid); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -17,11 +17,13 @@
 package archiver
 
 import (
+	"archive/tar"
 	"bytes"
 	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestTar(t *testing.T) {
@@ -93,3 +95,207 @@ func TestUntar(t *testing.T) {
 		t.Errorf("expected 'hello', got '%s'", string(data))
 	}
 }
+
+func TestUntarFlatten(t *testing.T) {
+	tmpDir := t.TempDir()
+	nestedDir := filepath.Join(tmpDir, "tokenizer", "nested")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(nestedDir, "vocab.json"), []byte("vocab"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	tarReader, err := Tar(filepath.Join(tmpDir, "tokenizer"), tmpDir)
+	if err != nil {
+		t.Fatalf("Tar error: %v", err)
+	}
+
+	extractDir := t.TempDir()
+	if err := Untar(tarReader, extractDir, WithFlatten()); err != nil {
+		t.Fatalf("Untar error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(extractDir, "vocab.json"))
+	if err != nil {
+		t.Fatalf("read extracted file error: %v", err)
+	}
+
+	if string(data) != "vocab" {
+		t.Errorf("expected 'vocab', got '%s'", string(data))
+	}
+
+	if _, err := os.Stat(filepath.Join(extractDir, "tokenizer")); !os.IsNotExist(err) {
+		t.Errorf("expected directory not to be extracted, got %v", err)
+	}
+}
+
+func TestToSlash(t *testing.T) {
+	testCases := []struct {
+		path      string
+		separator rune
+		expected  string
+	}{
+		{path: `tokenizer\nested\vocab.json`, separator: '\\', expected: "tokenizer/nested/vocab.json"},
+		{path: "tokenizer/nested/vocab.json", separator: '\\', expected: "tokenizer/nested/vocab.json"},
+		{path: `tokenizer\vocab.json`, separator: '/', expected: `tokenizer\vocab.json`},
+	}
+
+	for _, tc := range testCases {
+		if actual := toSlash(tc.path, tc.separator); actual != tc.expected {
+			t.Errorf("toSlash(%q, %q): expected %q, got %q", tc.path, tc.separator, tc.expected, actual)
+		}
+	}
+}
+
+func TestTarHeaderName(t *testing.T) {
+	tmpDir := t.TempDir()
+	nestedDir := filepath.Join(tmpDir, "tokenizer", "nested")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(nestedDir, "vocab.json"), []byte("vocab"), 0644); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	tarReader, err := Tar(filepath.Join(tmpDir, "tokenizer"), tmpDir)
+	if err != nil {
+		t.Fatalf("Tar error: %v", err)
+	}
+
+	names := []string{}
+	tr := tar.NewReader(tarReader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar error: %v", err)
+		}
+
+		names = append(names, header.Name)
+	}
+
+	expected := []string{"tokenizer", "tokenizer/nested", "tokenizer/nested/vocab.json"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected names %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("expected names %v, got %v", expected, names)
+		}
+	}
+}
+
+// windowsTar returns the tar archive with the header names separated by the backslashes
+// as the archives built on Windows without the normalization.
+func windowsTar(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: 5}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte("vocab")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestUntarWindowsSeparators(t *testing.T) {
+	content := windowsTar(t, `tokenizer\nested\vocab.json`, "tokenizer/merges.txt")
+
+	extractDir := t.TempDir()
+	if err := Untar(bytes.NewReader(content), extractDir); err != nil {
+		t.Fatalf("Untar error: %v", err)
+	}
+
+	for _, name := range []string{"tokenizer/nested/vocab.json", "tokenizer/merges.txt"} {
+		data, err := os.ReadFile(filepath.Join(extractDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("read extracted file error: %v", err)
+		}
+
+		if string(data) != "vocab" {
+			t.Errorf("expected 'vocab', got '%s'", string(data))
+		}
+	}
+
+	// the backslashes are kept as the characters of the file names with the raw names.
+	if filepath.Separator == '/' {
+		rawDir := t.TempDir()
+		if err := Untar(bytes.NewReader(content), rawDir, WithRawNames()); err != nil {
+			t.Fatalf("Untar error: %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(rawDir, `tokenizer\nested\vocab.json`)); err != nil {
+			t.Errorf("expected file with the raw name to be extracted, got %v", err)
+		}
+	}
+
+	// the traversal by the backslashes is rejected.
+	if err := Untar(bytes.NewReader(windowsTar(t, `..\..\evil.json`)), t.TempDir()); err == nil {
+		t.Error("expected error for the path traversal")
+	}
+}
+
+func TestUntarRestoreDirs(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range []*tar.Header{
+		{Name: "weights/", Typeflag: tar.TypeDir, Mode: 0555, ModTime: modTime},
+		{Name: "weights/nested/", Typeflag: tar.TypeDir, Mode: 0750, ModTime: modTime},
+		{Name: "weights/nested/model.bin", Typeflag: tar.TypeReg, Mode: 0644, Size: 7, ModTime: modTime},
+		{Name: "weights/config.json", Typeflag: tar.TypeReg, Mode: 0644, Size: 7, ModTime: modTime},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("write header error: %v", err)
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte("content")); err != nil {
+				t.Fatalf("write content error: %v", err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar writer error: %v", err)
+	}
+
+	// the files are written into the read-only directory, whose metadata is restored at last.
+	extractDir := t.TempDir()
+	t.Cleanup(func() { os.Chmod(filepath.Join(extractDir, "weights"), 0755) })
+	if err := Untar(bytes.NewReader(buf.Bytes()), extractDir); err != nil {
+		t.Fatalf("Untar error: %v", err)
+	}
+
+	for name, mode := range map[string]os.FileMode{"weights": 0555, "weights/nested": 0750} {
+		info, err := os.Stat(filepath.Join(extractDir, name))
+		if err != nil {
+			t.Fatalf("stat %s error: %v", name, err)
+		}
+
+		if info.Mode().Perm() != mode {
+			t.Errorf("expected the mode %o of %s, got %o", mode, name, info.Mode().Perm())
+		}
+
+		if !info.ModTime().Equal(modTime) {
+			t.Errorf("expected the mtime %s of %s, got %s", modTime, name, info.ModTime())
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(extractDir, "weights", "nested", "model.bin")); err != nil {
+		t.Errorf("expected the file in the nested directory to be extracted, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// tempNameOverhead is the length added to the base name by the temporary file of CreateAtomic,
+// which is the leading and middle dots, the pid and the sequence of up to 10 digits joined by the
+// dash and the .tmp suffix.
+const tempNameOverhead = 27
+
+// maxTempAttempts is the max number of the attempts to create the temporary file, the name may
+// exist if it is left by the crashed process with the same pid.
+const maxTempAttempts = 100
+
+// tempSeq is the sequence of the temporary files created by the process.
+var tempSeq atomic.Uint32
+
+// rename renames the file, which is replaced in tests to simulate the cross-device rename.
+var rename = os.Rename
+
+// AtomicFile is the file written to a temporary file in the directory of the target path,
+// which is renamed to the target path on commit, so an interrupted write never leaves a
+// partially-written file at the target path.
+type AtomicFile struct {
+	*os.File
+
+	// path is the target path of the file.
+	path string
+
+	// done indicates the file is committed or aborted.
+	done bool
+}
+
+// CreateAtomic creates the temporary file with the perm for writing the file of the path atomically.
+func CreateAtomic(path string, perm os.FileMode) (*AtomicFile, error) {
+	var (
+		file *os.File
+		err  error
+	)
+	for range maxTempAttempts {
+		file, err = os.OpenFile(tempName(path), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if !errors.Is(err, fs.ErrExist) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to set permissions of temporary file for %s: %w", path, err)
+	}
+
+	return &AtomicFile{File: file, path: path}, nil
+}
+
+// tempName returns the path of the temporary file in the directory of the path, such as
+// .model.bin.1234-5.tmp, which is unique across the processes by the pid and across the
+// goroutines of the process by the sequence, so the concurrent writes of the same path in
+// the overlapping extractions never share the temporary file.
+func tempName(path string) string {
+	name := fmt.Sprintf(".%s.%d-%d.tmp", filepath.Base(path), os.Getpid(), tempSeq.Add(1))
+	return filepath.Join(filepath.Dir(path), name)
+}
+
+// Path returns the target path of the file.
+func (f *AtomicFile) Path() string {
+	return f.path
+}
+
+// Commit closes the temporary file and renames it to the target path, the content is copied
+// to the target path instead if the rename fails across devices.
+func (f *AtomicFile) Commit() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to close temporary file for %s: %w", f.path, err)
+	}
+
+	err := rename(f.Name(), f.path)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, syscall.EXDEV) {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to rename temporary file to %s: %w", f.path, err)
+	}
+
+	defer os.Remove(f.Name())
+	if err := copyFile(f.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to copy temporary file to %s: %w", f.path, err)
+	}
+
+	return nil
+}
+
+// CommitWithPolicy commits the file like Commit, but for the backup policy, the existing file of
+// the target path is backed up before the rename unless it has the identical content, which is
+// kept and the temporary file is removed instead.
+func (f *AtomicFile) CommitWithPolicy(policy config.ConflictPolicy) error {
+	if f.done || policy != config.ConflictBackup {
+		return f.Commit()
+	}
+
+	if _, err := os.Lstat(f.path); err != nil {
+		if os.IsNotExist(err) {
+			return f.Commit()
+		}
+
+		f.Abort()
+		return err
+	}
+
+	identical, err := identicalFiles(f.Name(), f.path)
+	if err != nil {
+		f.Abort()
+		return fmt.Errorf("failed to compare %s with the existing file: %w", f.path, err)
+	}
+
+	if identical {
+		f.Abort()
+		return nil
+	}
+
+	if err := backup(f.path); err != nil {
+		f.Abort()
+		return err
+	}
+
+	return f.Commit()
+}
+
+// Abort closes and removes the temporary file, it does nothing if the file is committed.
+func (f *AtomicFile) Abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+// copyFile copies the content, permissions and modification time of the src file to the dst file.
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
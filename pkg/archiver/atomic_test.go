@@ -0,0 +1,272 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+func TestUntarInterrupted(t *testing.T) {
+	// build the tar with a large file and truncate it in the middle of the file content.
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := bytes.Repeat([]byte("weights"), 4096)
+	if err := tw.WriteHeader(&tar.Header{Name: "model.bin", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	truncated := bytes.NewReader(buf.Bytes()[:buf.Len()/2])
+	if err := Untar(truncated, outputDir); err == nil {
+		t.Fatal("expected error for the truncated tar")
+	}
+
+	// neither the partial file nor the temporary file remains.
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no files after the interrupted extraction, got %d", len(entries))
+	}
+}
+
+func TestAtomicFile(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		file, err := CreateAtomic(path, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.WriteString("new"); err != nil {
+			t.Fatal(err)
+		}
+
+		// the target file is untouched until commit.
+		if got := readFile(t, path); got != "old" {
+			t.Fatalf("expected the old content before commit, got %q", got)
+		}
+
+		if err := file.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		if got := readFile(t, path); got != "new" {
+			t.Fatalf("expected the new content after commit, got %q", got)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Fatalf("expected mode 0600, got %o", info.Mode().Perm())
+		}
+
+		// abort after commit does nothing.
+		file.Abort()
+		if _, err := os.Stat(path); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		dir := t.TempDir()
+		file, err := CreateAtomic(filepath.Join(dir, "config.json"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.WriteString("partial"); err != nil {
+			t.Fatal(err)
+		}
+		file.Abort()
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("expected no files after abort, got %d", len(entries))
+		}
+	})
+
+	t.Run("cross device", func(t *testing.T) {
+		rename = func(oldpath, newpath string) error {
+			return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+		}
+		defer func() { rename = os.Rename }()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.json")
+		file, err := CreateAtomic(path, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.WriteString("new"); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Commit(); err != nil {
+			t.Fatal(err)
+		}
+
+		// the content is copied and the temporary file is removed.
+		if got := readFile(t, path); got != "new" {
+			t.Fatalf("expected the new content after commit, got %q", got)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected only the committed file, got %d", len(entries))
+		}
+	})
+}
+
+func TestTempName(t *testing.T) {
+	path := filepath.Join("models", "model.bin")
+	first, second := tempName(path), tempName(path)
+	if first == second {
+		t.Fatalf("expected the unique temporary names, got %s twice", first)
+	}
+
+	prefix := filepath.Join("models", ".model.bin."+strconv.Itoa(os.Getpid())+"-")
+	for _, name := range []string{first, second} {
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".tmp") {
+			t.Fatalf("expected the temporary name with the prefix %s, got %s", prefix, name)
+		}
+	}
+
+	// the overhead covers the pid and the sequence of the max length.
+	if overhead := len(fmt.Sprintf("..%d-%d.tmp", math.MaxInt32, uint32(math.MaxUint32))); overhead > tempNameOverhead {
+		t.Fatalf("expected the overhead of at least %d, got %d", overhead, tempNameOverhead)
+	}
+}
+
+func TestCreateAtomicStaleTemp(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	// the temporary file left by the crashed process with the same pid is skipped.
+	stale := filepath.Join(dir, fmt.Sprintf(".config.json.%d-%d.tmp", os.Getpid(), tempSeq.Load()+1))
+	if err := os.WriteFile(stale, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := CreateAtomic(path, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Name() == stale {
+		t.Fatalf("expected the stale temporary file to be skipped")
+	}
+	if _, err := file.WriteString("new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, path); got != "new" {
+		t.Fatalf("expected the new content after commit, got %q", got)
+	}
+	if got := readFile(t, stale); got != "stale" {
+		t.Fatalf("expected the stale temporary file untouched, got %q", got)
+	}
+}
+
+func TestUntarConcurrent(t *testing.T) {
+	files := map[string][]byte{
+		"model.bin":         bytes.Repeat([]byte("weights"), 64<<10),
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+		"nested/vocab.json": bytes.Repeat([]byte("tokens"), 1<<10),
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// extract the overlapping content to the same directory concurrently.
+	outputDir := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- Untar(bytes.NewReader(buf.Bytes()), outputDir)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, content := range files {
+		if got := readFile(t, filepath.Join(outputDir, name)); got != string(content) {
+			t.Fatalf("expected the content of %s intact, got %d bytes", name, len(got))
+		}
+	}
+
+	// no temporary file is left.
+	err := filepath.WalkDir(outputDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(d.Name(), ".tmp") {
+			t.Fatalf("expected no temporary file left, got %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// BackupSuffix is the suffix of the backup of the existing file, a sequence number is
+// appended if the backup exists as well, such as config.json.bak.1.
+const BackupSuffix = ".bak"
+
+// ErrConflict is returned when the file exists and the conflict policy is error.
+var ErrConflict = errors.New("file already exists")
+
+// ResolveConflict resolves the conflict with the existing file of the path by the policy
+// before extracting the file, it returns false if the file should not be extracted. The
+// existing file is not backed up here for the backup policy, as its content is unknown
+// until extracted, but on committing the extracted file by CommitWithPolicy.
+func ResolveConflict(path string, policy config.ConflictPolicy) (bool, error) {
+	if _, err := os.Lstat(path); err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	switch policy {
+	case config.ConflictSkip:
+		return false, nil
+	case config.ConflictError:
+		return false, fmt.Errorf("%w: %s", ErrConflict, path)
+	default:
+		return true, nil
+	}
+}
+
+// ResolveConflictByDigest resolves the conflict like ResolveConflict for the file written to
+// the path in place, whose content is known by the digest before extracting. The existing file
+// is backed up for the backup policy unless it has the digest, which is kept and not extracted.
+func ResolveConflictByDigest(path string, policy config.ConflictPolicy, digest godigest.Digest) (bool, error) {
+	extract, err := ResolveConflict(path, policy)
+	if err != nil || !extract || policy != config.ConflictBackup {
+		return extract, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+
+		return false, err
+	}
+	defer file.Close()
+
+	actual, _, err := checksum.SHA256(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to compute the digest of %s: %w", path, err)
+	}
+
+	if actual == digest.String() {
+		return false, nil
+	}
+
+	return true, backup(path)
+}
+
+// backup renames the existing file of the path to the first backup path which does not exist.
+func backup(path string) error {
+	backupPath, err := nextBackupPath(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, backupPath); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// nextBackupPath returns the first backup path of the path which does not exist.
+func nextBackupPath(path string) (string, error) {
+	backupPath := path + BackupSuffix
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(backupPath); err != nil {
+			if os.IsNotExist(err) {
+				return backupPath, nil
+			}
+
+			return "", err
+		}
+
+		backupPath = fmt.Sprintf("%s%s.%d", path, BackupSuffix, i)
+	}
+}
+
+// identicalFiles reports whether the regular files have the same content.
+func identicalFiles(a, b string) (bool, error) {
+	infoA, err := os.Lstat(a)
+	if err != nil {
+		return false, err
+	}
+
+	infoB, err := os.Lstat(b)
+	if err != nil {
+		return false, err
+	}
+
+	if !infoA.Mode().IsRegular() || !infoB.Mode().IsRegular() || infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	fileA, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fileA.Close()
+
+	fileB, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fileB.Close()
+
+	bufA, bufB := make([]byte, 64*1024), make([]byte, 64*1024)
+	for {
+		nA, errA := io.ReadFull(fileA, bufA)
+		nB, errB := io.ReadFull(fileB, bufB)
+		if !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+
+		if errA != nil {
+			return false, errA
+		}
+
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
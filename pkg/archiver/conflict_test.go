@@ -0,0 +1,173 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// untarConflict untars the file with the new content into the output directory
+// pre-populated with the old content by the policy.
+func untarConflict(t *testing.T, policy config.ConflictPolicy) (string, error) {
+	return untarConflictWith(t, policy, "old")
+}
+
+// untarConflictWith untars the file with the new content into the output directory
+// pre-populated with the existing content by the policy.
+func untarConflictWith(t *testing.T, policy config.ConflictPolicy, existing string) (string, error) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "config.json"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tarReader, err := Tar(filepath.Join(srcDir, "config.json"), srcDir)
+	if err != nil {
+		t.Fatalf("Tar error: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "config.json"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return outputDir, Untar(tarReader, outputDir, WithConflictPolicy(policy))
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file error: %v", err)
+	}
+
+	return string(data)
+}
+
+func TestUntarConflictPolicy(t *testing.T) {
+	t.Run("overwrite", func(t *testing.T) {
+		outputDir, err := untarConflict(t, config.ConflictOverwrite)
+		if err != nil {
+			t.Fatalf("Untar error: %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "config.json")); got != "new" {
+			t.Errorf("expected 'new', got '%s'", got)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		outputDir, err := untarConflict(t, config.ConflictSkip)
+		if err != nil {
+			t.Fatalf("Untar error: %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "config.json")); got != "old" {
+			t.Errorf("expected 'old', got '%s'", got)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		outputDir, err := untarConflict(t, config.ConflictError)
+		if !errors.Is(err, ErrConflict) {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "config.json")); got != "old" {
+			t.Errorf("expected 'old', got '%s'", got)
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		outputDir, err := untarConflict(t, config.ConflictBackup)
+		if err != nil {
+			t.Fatalf("Untar error: %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "config.json")); got != "new" {
+			t.Errorf("expected 'new', got '%s'", got)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "config.json"+BackupSuffix)); got != "old" {
+			t.Errorf("expected backup 'old', got '%s'", got)
+		}
+	})
+
+	t.Run("backup identical", func(t *testing.T) {
+		outputDir, err := untarConflictWith(t, config.ConflictBackup, "new")
+		if err != nil {
+			t.Fatalf("Untar error: %v", err)
+		}
+
+		if got := readFile(t, filepath.Join(outputDir, "config.json")); got != "new" {
+			t.Errorf("expected 'new', got '%s'", got)
+		}
+
+		if _, err := os.Lstat(filepath.Join(outputDir, "config.json"+BackupSuffix)); !os.IsNotExist(err) {
+			t.Errorf("expected no backup of the identical file, got %v", err)
+		}
+	})
+}
+
+func TestResolveConflictBackupSequence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	for _, name := range []string{"config.json", "config.json.bak"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	extract, err := ResolveConflictByDigest(path, config.ConflictBackup, godigest.FromString("new"))
+	if err != nil || !extract {
+		t.Fatalf("expected to extract, got %v, %v", extract, err)
+	}
+
+	if got := readFile(t, path+".bak.1"); got != "config.json" {
+		t.Errorf("expected backup 'config.json', got '%s'", got)
+	}
+
+	if got := readFile(t, path+".bak"); got != "config.json.bak" {
+		t.Errorf("expected the previous backup to be kept, got '%s'", got)
+	}
+
+	// the existing file with the identical content is kept without the backup.
+	identical := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(identical, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	extract, err = ResolveConflictByDigest(identical, config.ConflictBackup, godigest.FromString("new"))
+	if err != nil || extract {
+		t.Fatalf("expected not to extract, got %v, %v", extract, err)
+	}
+
+	if _, err := os.Lstat(identical + BackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected no backup of the identical file, got %v", err)
+	}
+
+	// the missing file is always extracted.
+	extract, err = ResolveConflict(filepath.Join(dir, "missing.json"), config.ConflictError)
+	if err != nil || !extract {
+		t.Fatalf("expected to extract, got %v, %v", extract, err)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+const (
+	// MaxNameLength is the max length in bytes of a file name, which is NAME_MAX of the common filesystems.
+	MaxNameLength = 255
+
+	// MaxPathLength is the max length in bytes of a path, which is PATH_MAX of linux.
+	MaxPathLength = 4096
+
+	// PathMappingFilename is the filename of the mapping from the shortened paths to the original
+	// ones in the destination directory.
+	PathMappingFilename = ".modctl-longpaths.json"
+
+	// shortHashLength is the length of the hash prefix of the shortened name.
+	shortHashLength = 16
+)
+
+// ErrPathTooLong is returned when the path exceeds the limits of the filesystem.
+var ErrPathTooLong = errors.New("path too long")
+
+// CheckPathLength checks the path and its components fit the limits of the filesystem, including
+// the temporary file written before renaming to the path.
+func CheckPathLength(path string) error {
+	if len(path) > MaxPathLength {
+		return fmt.Errorf("%w: %s has %d bytes exceeding the limit of %d bytes", ErrPathTooLong, path, len(path), MaxPathLength)
+	}
+
+	// only the file at the final component is written to the temporary file with the longer name.
+	names := strings.Split(path, string(filepath.Separator))
+	for i, name := range names {
+		limit := MaxNameLength
+		if i == len(names)-1 {
+			limit -= tempNameOverhead
+		}
+
+		if len(name) > limit {
+			return fmt.Errorf("%w: the name %s has %d bytes exceeding the limit of %d bytes", ErrPathTooLong, name, len(name), limit)
+		}
+	}
+
+	return nil
+}
+
+// PathShortener shortens the relative paths exceeding the limits of the filesystem and records
+// the mapping from the shortened paths to the original ones, the nil shortener keeps the paths.
+type PathShortener struct {
+	mu sync.Mutex
+	// mappings is the mapping from the shortened paths to the original ones by the destination directories.
+	mappings map[string]map[string]string
+}
+
+// NewPathShortener creates a new path shortener.
+func NewPathShortener() *PathShortener {
+	return &PathShortener{mappings: map[string]map[string]string{}}
+}
+
+// Shorten returns the relative path in the destination directory as is if it fits the limits,
+// otherwise it is replaced by the file at the top level of the destination directory named by the
+// hash of the path and the tail of the base name, which keeps the extension. The shortening is
+// deterministic, so the same path is always shortened to the same one.
+func (s *PathShortener) Shorten(destDir, relPath string) string {
+	if s == nil || CheckPathLength(filepath.Join(destDir, relPath)) == nil {
+		return relPath
+	}
+
+	hash := checksum.Sum256([]byte(filepath.ToSlash(relPath)))
+	prefix := hex.EncodeToString(hash[:])[:shortHashLength] + "-"
+	base := filepath.Base(relPath)
+	if maxBase := MaxNameLength - tempNameOverhead - len(prefix); len(base) > maxBase {
+		base = base[len(base)-maxBase:]
+	}
+
+	shortened := prefix + base
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mappings[destDir] == nil {
+		s.mappings[destDir] = map[string]string{}
+	}
+
+	// warn once as the path is shortened again to locate the extracted file.
+	if _, ok := s.mappings[destDir][shortened]; !ok {
+		logrus.Warnf("archiver: shortened the long path %s to %s in %s", relPath, shortened, destDir)
+		s.mappings[destDir][shortened] = filepath.ToSlash(relPath)
+	}
+
+	return shortened
+}
+
+// WriteMappings writes the mappings of the shortened paths to the mapping file in each destination
+// directory, which are merged with the existing ones.
+func (s *PathShortener) WriteMappings() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirs := make([]string, 0, len(s.mappings))
+	for dir := range s.mappings {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		mappingPath := filepath.Join(dir, PathMappingFilename)
+		mapping := map[string]string{}
+		if data, err := os.ReadFile(mappingPath); err == nil {
+			if err := json.Unmarshal(data, &mapping); err != nil {
+				return fmt.Errorf("failed to unmarshal path mapping %s: %w", mappingPath, err)
+			}
+		}
+
+		for shortened, original := range s.mappings[dir] {
+			mapping[shortened] = original
+		}
+
+		data, err := json.MarshalIndent(mapping, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal path mapping: %w", err)
+		}
+
+		if err := os.WriteFile(mappingPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write path mapping %s: %w", mappingPath, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPathLength(t *testing.T) {
+	assert.NoError(t, CheckPathLength(filepath.Join("models", "model.safetensors")))
+	assert.ErrorIs(t, CheckPathLength(filepath.Join("models", strings.Repeat("a", MaxNameLength))), ErrPathTooLong)
+	// the name fitting the limit is too long for the temporary file written before renaming.
+	assert.ErrorIs(t, CheckPathLength(strings.Repeat("a", MaxNameLength-1)), ErrPathTooLong)
+	// the directories are not written to the temporary files.
+	assert.NoError(t, CheckPathLength(filepath.Join(strings.Repeat("a", MaxNameLength-1), "model.safetensors")))
+	assert.ErrorIs(t, CheckPathLength(strings.Repeat(strings.Repeat("a", 100)+"/", 41)), ErrPathTooLong)
+}
+
+func TestPathShortener(t *testing.T) {
+	destDir := t.TempDir()
+	longPath := filepath.Join("deeply", "nested", strings.Repeat("a", 300)+".safetensors")
+
+	var nilShortener *PathShortener
+	assert.Equal(t, longPath, nilShortener.Shorten(destDir, longPath))
+
+	shortener := NewPathShortener()
+	assert.Equal(t, "README.md", shortener.Shorten(destDir, "README.md"))
+
+	shortened := shortener.Shorten(destDir, longPath)
+	assert.NotEqual(t, longPath, shortened)
+	assert.Equal(t, shortened, shortener.Shorten(destDir, longPath))
+	assert.Equal(t, ".safetensors", filepath.Ext(shortened))
+	assert.NotContains(t, shortened, string(filepath.Separator))
+	assert.NoError(t, CheckPathLength(filepath.Join(destDir, shortened)))
+
+	require.NoError(t, shortener.WriteMappings())
+	data, err := os.ReadFile(filepath.Join(destDir, PathMappingFilename))
+	require.NoError(t, err)
+	var mapping map[string]string
+	require.NoError(t, json.Unmarshal(data, &mapping))
+	assert.Equal(t, map[string]string{shortened: filepath.ToSlash(longPath)}, mapping)
+}
+
+func TestUntarLongPath(t *testing.T) {
+	longPath := strings.Repeat("nested/", 10) + strings.Repeat("b", 250) + ".json"
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range map[string]string{"config.json": "{}", longPath: `{"long": true}`} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	t.Run("error", func(t *testing.T) {
+		err := Untar(bytes.NewReader(buf.Bytes()), t.TempDir())
+		assert.ErrorIs(t, err, ErrPathTooLong)
+	})
+
+	t.Run("shorten", func(t *testing.T) {
+		destDir := t.TempDir()
+		shortener := NewPathShortener()
+		require.NoError(t, Untar(bytes.NewReader(buf.Bytes()), destDir, WithPathShortener(shortener)))
+		require.NoError(t, shortener.WriteMappings())
+
+		content, err := os.ReadFile(filepath.Join(destDir, "config.json"))
+		require.NoError(t, err)
+		assert.Equal(t, "{}", string(content))
+
+		data, err := os.ReadFile(filepath.Join(destDir, PathMappingFilename))
+		require.NoError(t, err)
+		var mapping map[string]string
+		require.NoError(t, json.Unmarshal(data, &mapping))
+		require.Len(t, mapping, 1)
+		for shortened, original := range mapping {
+			assert.Equal(t, longPath, original)
+			content, err := os.ReadFile(filepath.Join(destDir, shortened))
+			require.NoError(t, err)
+			assert.Equal(t, `{"long": true}`, string(content))
+		}
+	})
+}
@@ -0,0 +1,240 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// Format is the format of the nested archive, such as a dataset shipped as a zip file.
+type Format string
+
+const (
+	// FormatUnknown is not a supported archive.
+	FormatUnknown Format = ""
+	// FormatZip is the zip archive.
+	FormatZip Format = "zip"
+	// FormatTar is the uncompressed tar archive.
+	FormatTar Format = "tar"
+	// FormatTarGzip is the gzip compressed tar archive.
+	FormatTarGzip Format = "tar+gzip"
+)
+
+// archiveExts are the extensions of the supported archives, the longer ones go first.
+var archiveExts = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// IsArchiveName returns whether the file name has the extension of the supported archives.
+func IsArchiveName(name string) bool {
+	return TrimArchiveExt(name) != name
+}
+
+// TrimArchiveExt returns the name without the extension of the supported archives,
+// which is the directory to extract the archive into.
+func TrimArchiveExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lower, ext) && len(name) > len(ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+
+	return name
+}
+
+// DetectFormat detects the format of the archive file by the magic bytes regardless of the extension,
+// the gzip file is only a tar archive if the decompressed content has the tar magic.
+func DetectFormat(path string) (Format, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FormatUnknown, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic, _ := reader.Peek(4)
+	switch {
+	case bytes.Equal(magic, []byte("PK\x03\x04")) || bytes.Equal(magic, []byte("PK\x05\x06")):
+		return FormatZip, nil
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return FormatUnknown, nil
+		}
+		defer gzipReader.Close()
+
+		if isTar(bufio.NewReader(gzipReader)) {
+			return FormatTarGzip, nil
+		}
+	case isTar(reader):
+		return FormatTar, nil
+	}
+
+	return FormatUnknown, nil
+}
+
+// isTar returns whether the content starts with the tar header of the ustar or gnu magic.
+func isTar(reader *bufio.Reader) bool {
+	header, err := reader.Peek(263)
+	if err != nil {
+		return false
+	}
+
+	return bytes.HasPrefix(header[257:], []byte("ustar"))
+}
+
+// ExtractArchive extracts the zip, tar or gzip compressed tar archive into the destination
+// directory by the detected format, the conflict policy of the options is respected.
+func ExtractArchive(path, destPath string, opts ...UntarOption) error {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatZip:
+		return unzip(path, destPath, opts...)
+	case FormatTar, FormatTarGzip:
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer file.Close()
+
+		var reader io.Reader = file
+		if format == FormatTarGzip {
+			gzipReader, err := gzip.NewReader(file)
+			if err != nil {
+				return fmt.Errorf("failed to create gzip reader: %w", err)
+			}
+			defer gzipReader.Close()
+			reader = gzipReader
+		}
+
+		return Untar(reader, destPath, opts...)
+	default:
+		return fmt.Errorf("unsupported archive format of %s", path)
+	}
+}
+
+// unzip extracts the regular files of the zip archive into the destination directory,
+// the symlinks are skipped as Untar does.
+func unzip(path, destPath string, opts ...UntarOption) error {
+	options := &untarOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open zip: %w", err)
+	}
+	defer zipReader.Close()
+
+	if err := os.MkdirAll(destPath, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	for _, entry := range zipReader.File {
+		// Sanitize file paths to prevent directory traversal.
+		cleanPath := filepath.Clean(filepath.FromSlash(entry.Name))
+		if strings.Contains(cleanPath, "..") || filepath.IsAbs(cleanPath) || strings.HasPrefix(cleanPath, string(filepath.Separator)) {
+			return fmt.Errorf("zip file contains invalid path: %s", entry.Name)
+		}
+
+		mode := entry.Mode()
+		if mode.IsDir() {
+			if options.flatten {
+				continue
+			}
+
+			if err := os.MkdirAll(filepath.Join(destPath, cleanPath), 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", cleanPath, err)
+			}
+			continue
+		}
+
+		if !mode.IsRegular() {
+			continue
+		}
+
+		if options.flatten {
+			cleanPath = filepath.Base(cleanPath)
+		}
+
+		if err := unzipFile(entry, filepath.Join(destPath, cleanPath), options.conflictPolicy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unzipFile extracts the zip entry to the target path atomically.
+func unzipFile(entry *zip.File, targetPath string, policy config.ConflictPolicy) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(targetPath), err)
+	}
+
+	extract, err := ResolveConflict(targetPath, policy)
+	if err != nil {
+		return err
+	}
+
+	if !extract {
+		return nil
+	}
+
+	reader, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open zip entry %s: %w", entry.Name, err)
+	}
+	defer reader.Close()
+
+	perm := entry.Mode().Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+
+	file, err := CreateAtomic(targetPath, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Abort()
+		return fmt.Errorf("failed to write to file %s: %w", targetPath, err)
+	}
+
+	if modTime := entry.Modified; !modTime.IsZero() {
+		if err := os.Chtimes(file.Name(), modTime, modTime); err != nil {
+			file.Abort()
+			return fmt.Errorf("failed to set file mtime %s: %w", targetPath, err)
+		}
+	}
+
+	return file.CommitWithPolicy(policy)
+}
@@ -0,0 +1,209 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// zipBytes returns the zip archive of the files.
+func zipBytes(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// tarBytes returns the tar archive of the files, which is gzip compressed if compress is set.
+func tarBytes(t *testing.T, files map[string]string, compress bool) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !compress {
+		return buf.Bytes()
+	}
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	if _, err := gw.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return gzipped.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	files := map[string]string{"train/data.csv": "a,b\n1,2\n"}
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte("a,b\n1,2\n"))
+	gw.Close()
+
+	testCases := []struct {
+		name     string
+		content  []byte
+		expected Format
+	}{
+		{name: "data.zip", content: zipBytes(t, files), expected: FormatZip},
+		{name: "data.tar", content: tarBytes(t, files, false), expected: FormatTar},
+		{name: "data.tar.gz", content: tarBytes(t, files, true), expected: FormatTarGzip},
+		// the format is detected by the content regardless of the extension.
+		{name: "data.bin", content: zipBytes(t, files), expected: FormatZip},
+		{name: "data.csv.gz", content: gzipped.Bytes(), expected: FormatUnknown},
+		{name: "data.csv", content: []byte("a,b\n1,2\n"), expected: FormatUnknown},
+		{name: "empty.zip", content: []byte{}, expected: FormatUnknown},
+	}
+
+	dir := t.TempDir()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			if err := os.WriteFile(path, tc.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			format, err := DetectFormat(path)
+			if err != nil {
+				t.Fatalf("DetectFormat error: %v", err)
+			}
+
+			if format != tc.expected {
+				t.Fatalf("unexpected format: %q, expected %q", format, tc.expected)
+			}
+		})
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	files := map[string]string{"train/data.csv": "a,b\n1,2\n", "README.md": "# dataset"}
+	archives := map[string][]byte{
+		"data.zip":    zipBytes(t, files),
+		"data.tar":    tarBytes(t, files, false),
+		"data.tar.gz": tarBytes(t, files, true),
+	}
+
+	for name, content := range archives {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			destPath := filepath.Join(dir, TrimArchiveExt(name))
+			if err := ExtractArchive(path, destPath); err != nil {
+				t.Fatalf("ExtractArchive error: %v", err)
+			}
+
+			for file, expected := range files {
+				data, err := os.ReadFile(filepath.Join(destPath, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(data) != expected {
+					t.Fatalf("unexpected content of %s: %s", file, data)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid path", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "evil.zip")
+		if err := os.WriteFile(path, zipBytes(t, map[string]string{"../evil.txt": "evil"}), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ExtractArchive(path, filepath.Join(dir, "evil")); err == nil {
+			t.Fatal("expected error for invalid path")
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "evil.txt")); !os.IsNotExist(err) {
+			t.Fatal("unexpected file outside the destination")
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data.zip")
+		if err := os.WriteFile(path, []byte("not a zip"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ExtractArchive(path, t.TempDir()); err == nil {
+			t.Fatal("expected error for unsupported format")
+		}
+	})
+}
+
+func TestTrimArchiveExt(t *testing.T) {
+	testCases := map[string]string{
+		"data/train.zip":    "data/train",
+		"data/train.TAR.GZ": "data/train",
+		"train.tgz":         "train",
+		"train.tar":         "train",
+		"train.csv":         "train.csv",
+		".zip":              ".zip",
+	}
+
+	for name, expected := range testCases {
+		if got := TrimArchiveExt(name); got != expected {
+			t.Errorf("TrimArchiveExt(%q) = %q, expected %q", name, got, expected)
+		}
+
+		if IsArchiveName(name) != (expected != name) {
+			t.Errorf("unexpected IsArchiveName(%q)", name)
+		}
+	}
+}
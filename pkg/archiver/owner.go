@@ -0,0 +1,182 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OwnerCurrent is the owner of the current user.
+const OwnerCurrent = "current"
+
+// Ownership maps the owners stored in the archives or the annotations to the owners of the
+// extracted files, which makes the extraction work in the rootless environments where the
+// stored owners can not be set.
+type Ownership struct {
+	// UID and GID are the owner of all the extracted files if not negative, which take
+	// precedence over the maps.
+	UID int
+	GID int
+
+	// UIDMap and GIDMap map the stored ids to the ones of the extracted files, the unmapped
+	// ids are kept as stored.
+	UIDMap map[int]int
+	GIDMap map[int]int
+
+	// warnOnce warns the owner can not be set only once instead of for every file.
+	warnOnce sync.Once
+}
+
+// NewOwnership creates the ownership from the owner in the uid:gid format or current for
+// the current user, and the id maps in the from:to format, the empty owner keeps the stored
+// owners mapped by the id maps.
+func NewOwnership(owner string, uidMap, gidMap []string) (*Ownership, error) {
+	o := &Ownership{UID: -1, GID: -1}
+	if owner != "" {
+		uid, gid, err := parseOwner(owner)
+		if err != nil {
+			return nil, err
+		}
+
+		o.UID, o.GID = uid, gid
+	}
+
+	var err error
+	if o.UIDMap, err = parseIDMap(uidMap); err != nil {
+		return nil, fmt.Errorf("invalid uid map: %w", err)
+	}
+
+	if o.GIDMap, err = parseIDMap(gidMap); err != nil {
+		return nil, fmt.Errorf("invalid gid map: %w", err)
+	}
+
+	return o, nil
+}
+
+// parseOwner parses the owner in the uid:gid format, or current for the current user.
+func parseOwner(owner string) (int, int, error) {
+	if owner == OwnerCurrent {
+		return os.Getuid(), os.Getgid(), nil
+	}
+
+	uid, gid, ok := strings.Cut(owner, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid owner %q, must be uid:gid or %s", owner, OwnerCurrent)
+	}
+
+	uidInt, err := parseID(uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid owner %q: %w", owner, err)
+	}
+
+	gidInt, err := parseID(gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid owner %q: %w", owner, err)
+	}
+
+	return uidInt, gidInt, nil
+}
+
+// parseIDMap parses the id maps in the from:to format.
+func parseIDMap(mappings []string) (map[int]int, error) {
+	idMap := make(map[int]int, len(mappings))
+	for _, mapping := range mappings {
+		from, to, ok := strings.Cut(mapping, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid id map %q, must be from:to", mapping)
+		}
+
+		fromID, err := parseID(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id map %q: %w", mapping, err)
+		}
+
+		toID, err := parseID(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id map %q: %w", mapping, err)
+		}
+
+		idMap[fromID] = toID
+	}
+
+	return idMap, nil
+}
+
+// parseID parses the non-negative uid or gid.
+func parseID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid id %q", id)
+	}
+
+	return n, nil
+}
+
+// Map returns the owner of the extracted file by the stored owner, the negative ids are unknown
+// and kept unknown unless the owner of all the files is specified.
+func (o *Ownership) Map(uid, gid int) (int, int) {
+	if o.UID >= 0 {
+		uid = o.UID
+	} else if mapped, ok := o.UIDMap[uid]; ok {
+		uid = mapped
+	}
+
+	if o.GID >= 0 {
+		gid = o.GID
+	} else if mapped, ok := o.GIDMap[gid]; ok {
+		gid = mapped
+	}
+
+	return uid, gid
+}
+
+// Chown sets the owner of the extracted file mapped from the stored owner, the nil ownership
+// keeps the owner of the extracting user. The owner which is not permitted to set, such as the
+// unmapped ids in the rootless environments, is warned instead of failing the extraction.
+func (o *Ownership) Chown(path string, uid, gid int) error {
+	if o == nil {
+		return nil
+	}
+
+	uid, gid = o.Map(uid, gid)
+	if uid < 0 && gid < 0 {
+		return nil
+	}
+
+	if err := os.Lchown(path, uid, gid); err != nil {
+		if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EINVAL) || errors.Is(err, errors.ErrUnsupported) {
+			o.warnOnce.Do(func() {
+				logrus.Warnf("archiver: not permitted to set the owner %d:%d of %s, the files are kept owned by the current user: %s", uid, gid, path, err)
+			})
+
+			return nil
+		}
+
+		return fmt.Errorf("failed to set the owner of %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archiver
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOwnership(t *testing.T) {
+	testCases := []struct {
+		name     string
+		owner    string
+		uidMap   []string
+		gidMap   []string
+		expected *Ownership
+		err      string
+	}{
+		{name: "empty", expected: &Ownership{UID: -1, GID: -1, UIDMap: map[int]int{}, GIDMap: map[int]int{}}},
+		{name: "owner", owner: "1000:100", expected: &Ownership{UID: 1000, GID: 100, UIDMap: map[int]int{}, GIDMap: map[int]int{}}},
+		{name: "current", owner: OwnerCurrent, expected: &Ownership{UID: os.Getuid(), GID: os.Getgid(), UIDMap: map[int]int{}, GIDMap: map[int]int{}}},
+		{name: "maps", uidMap: []string{"0:1000", "1:1001"}, gidMap: []string{"0:100"}, expected: &Ownership{UID: -1, GID: -1, UIDMap: map[int]int{0: 1000, 1: 1001}, GIDMap: map[int]int{0: 100}}},
+		{name: "invalid owner", owner: "1000", err: "must be uid:gid or current"},
+		{name: "negative owner", owner: "-1:0", err: "invalid id"},
+		{name: "invalid uid map", uidMap: []string{"root:1000"}, err: "invalid uid map"},
+		{name: "invalid gid map", gidMap: []string{"100"}, err: "invalid gid map"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ownership, err := NewOwnership(tc.owner, tc.uidMap, tc.gidMap)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ownership)
+		})
+	}
+}
+
+func TestOwnershipMap(t *testing.T) {
+	mapped := &Ownership{UID: -1, GID: -1, UIDMap: map[int]int{0: 1000}, GIDMap: map[int]int{0: 100}}
+	uid, gid := mapped.Map(0, 0)
+	assert.Equal(t, []int{1000, 100}, []int{uid, gid})
+
+	// the unmapped and unknown ids are kept.
+	uid, gid = mapped.Map(1, -1)
+	assert.Equal(t, []int{1, -1}, []int{uid, gid})
+
+	// the owner takes precedence over the maps.
+	owned := &Ownership{UID: 2000, GID: 200, UIDMap: map[int]int{0: 1000}}
+	uid, gid = owned.Map(0, -1)
+	assert.Equal(t, []int{2000, 200}, []int{uid, gid})
+}
+
+// fileOwner returns the uid and gid of the file.
+func fileOwner(t *testing.T, path string) (int, int) {
+	info, err := os.Lstat(path)
+	require.NoError(t, err)
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		t.Skip("the owner of the files is not supported")
+	}
+
+	return int(stat.Uid), int(stat.Gid)
+}
+
+func TestUntarWithOwnership(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("setting the arbitrary owners requires root")
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "model", Mode: 0755, Uid: 1000, Gid: 1000, ModTime: time.Now()}))
+	content := []byte("weight")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "model/weight.bin", Mode: 0644, Size: int64(len(content)), Uid: 1000, Gid: 1001, ModTime: time.Now()}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	ownership, err := NewOwnership("", []string{"1000:2000"}, []string{"1000:3000"})
+	require.NoError(t, err)
+
+	outputDir := t.TempDir()
+	require.NoError(t, Untar(bytes.NewReader(buf.Bytes()), outputDir, WithOwnership(ownership)))
+
+	uid, gid := fileOwner(t, filepath.Join(outputDir, "model"))
+	assert.Equal(t, []int{2000, 3000}, []int{uid, gid})
+
+	// the unmapped gid is kept as stored.
+	uid, gid = fileOwner(t, filepath.Join(outputDir, "model", "weight.bin"))
+	assert.Equal(t, []int{2000, 1001}, []int{uid, gid})
+}
+
+func TestOwnershipChown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+
+	// the nil ownership keeps the owner of the extracting user.
+	var ownership *Ownership
+	require.NoError(t, ownership.Chown(path, 1000, 1000))
+	uid, gid := fileOwner(t, path)
+	assert.Equal(t, []int{os.Getuid(), os.Getgid()}, []int{uid, gid})
+
+	// the current user is always permitted.
+	ownership, err := NewOwnership(OwnerCurrent, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, ownership.Chown(path, 1000, 1000))
+	uid, gid = fileOwner(t, path)
+	assert.Equal(t, []int{os.Getuid(), os.Getgid()}, []int{uid, gid})
+
+	// the owner not permitted to set is warned instead of failing in the rootless environments.
+	if os.Getuid() != 0 {
+		ownership, err = NewOwnership("0:0", nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, ownership.Chown(path, 1000, 1000))
+		uid, gid = fileOwner(t, path)
+		assert.Equal(t, []int{os.Getuid(), os.Getgid()}, []int{uid, gid})
+	}
+}
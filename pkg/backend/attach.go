@@ -39,6 +39,7 @@ import (
 	"github.com/modelpack/modctl/pkg/backend/build/hooks"
 	"github.com/modelpack/modctl/pkg/backend/processor"
 	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
 	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/pkg/modelfile"
 )
@@ -50,6 +51,33 @@ const (
 	modelDocPriority
 )
 
+const (
+	// annotationLayerOrder is the annotation key of the manifest recording the layer order
+	// chosen at build time, so that attach preserves it.
+	annotationLayerOrder = "org.cncf.modctl.layer-order"
+)
+
+const (
+	metadataFirstWeightConfigPriority = iota
+	metadataFirstDocPriority
+	metadataFirstCodePriority
+	metadataFirstOtherPriority
+	metadataFirstWeightPriority
+)
+
+var (
+	// metadataFirstPriorityMap defines the priority for the metadata-first layer ordering, the
+	// weights are placed last and the other media types are placed before the weights.
+	metadataFirstPriorityMap = map[string]int{
+		modelspec.MediaTypeModelWeightConfig:    metadataFirstWeightConfigPriority,
+		modelspec.MediaTypeModelWeightConfigRaw: metadataFirstWeightConfigPriority,
+		modelspec.MediaTypeModelDoc:             metadataFirstDocPriority,
+		modelspec.MediaTypeModelDocRaw:          metadataFirstDocPriority,
+		modelspec.MediaTypeModelCode:            metadataFirstCodePriority,
+		modelspec.MediaTypeModelCodeRaw:         metadataFirstCodePriority,
+	}
+)
+
 var (
 	// mediaTypePriorityMap defines the priority for layer sorting by group.
 	mediaTypePriorityMap = map[string]int{
@@ -127,9 +155,9 @@ func (b *backend) Attach(ctx context.Context, filepath string, cfg *config.Attac
 			return fmt.Errorf("failed to process layers: %w", err)
 		}
 
-		// Append the new layers to the original layers.
+		// Append the new layers to the original layers, and keep the layer order chosen at build time.
 		layers = append(layers, newLayers...)
-		sortLayers(layers)
+		orderLayers(layers, srcManifest.Annotations[annotationLayerOrder])
 
 		logrus.Debugf("attach: generated sorted layers [layers: %+v]", layers)
 
@@ -194,6 +222,11 @@ func (b *backend) Attach(ctx context.Context, filepath string, cfg *config.Attac
 		return fmt.Errorf("failed to build model config: %w", err)
 	}
 
+	// Update the merkle root of the source as the layers are changed.
+	if _, ok := srcManifest.Annotations[checksum.AnnotationMerkleRoot]; ok {
+		srcManifest.Annotations[checksum.AnnotationMerkleRoot] = merkleRoot(layers)
+	}
+
 	// Build the model manifest.
 	_, err = builder.BuildManifest(ctx, layers, configDesc, srcManifest.Annotations, hooks.NewHooks(
 		hooks.WithOnStart(func(name string, size int64, reader io.Reader) io.Reader {
@@ -214,6 +247,9 @@ func (b *backend) Attach(ctx context.Context, filepath string, cfg *config.Attac
 	return nil
 }
 
+// getManifest gets the manifest of the reference from the local storage or the remote. The local
+// manifest is served from the cache if the tag is resolved to a cached digest, and the remote one
+// is fetched conditionally by the ETag of the cached one, which is reused on 304 Not Modified.
 func (b *backend) getManifest(ctx context.Context, reference string, fromRemote, plainHTTP, insecure bool) (*ocispec.Manifest, error) {
 	ref, err := ParseReference(reference)
 	if err != nil {
@@ -227,38 +263,62 @@ func (b *backend) getManifest(ctx context.Context, reference string, fromRemote,
 
 	// Fetch from local storage if it is not remote.
 	if !fromRemote {
-		manifestRaw, _, err := b.store.PullManifest(ctx, repo, tag)
+		if digest, ok := b.cache.resolve(repo, tag); ok {
+			if manifestRaw, ok := b.cache.get(digest); ok {
+				logrus.Debugf("manifest cache: hit manifest %s of %s", digest, reference)
+				return decodeManifest(manifestRaw)
+			}
+		}
+
+		manifestRaw, digest, err := b.store.PullManifest(ctx, repo, tag)
 		if err != nil {
 			return nil, fmt.Errorf("failed to pull manifest: %w", err)
 		}
 
-		var manifest ocispec.Manifest
-		if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
-		}
+		b.cache.tag(repo, tag, godigest.Digest(digest))
+		b.cache.put(godigest.Digest(digest), manifestRaw)
+		return decodeManifest(manifestRaw)
+	}
 
-		return &manifest, nil
+	// Fetch the manifest conditionally by the ETag of the cached one to detect the tag change,
+	// the unchanged manifest is served from the cache on 304 Not Modified.
+	opts := []remote.Option{remote.WithPlainHTTP(plainHTTP), remote.WithInsecure(insecure)}
+	if b.cache != nil {
+		opts = append(opts, remote.WithManifestCache(b.cache))
 	}
 
-	client, err := remote.New(repo, remote.WithPlainHTTP(plainHTTP), remote.WithInsecure(insecure))
+	client, err := remote.New(repo, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create remote client: %w", err)
 	}
 
-	_, manifestReader, err := client.Manifests().FetchReference(ctx, reference)
+	desc, manifestReader, err := client.Manifests().FetchReference(ctx, reference)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
 	defer manifestReader.Close()
 
+	manifestRaw, err := io.ReadAll(manifestReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	b.cache.put(desc.Digest, manifestRaw)
+	return decodeManifest(manifestRaw)
+}
+
+// decodeManifest decodes the manifest.
+func decodeManifest(manifestRaw []byte) (*ocispec.Manifest, error) {
 	var manifest ocispec.Manifest
-	if err := json.NewDecoder(manifestReader).Decode(&manifest); err != nil {
-		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
 	}
 
 	return &manifest, nil
 }
 
+// getModelConfig gets the model config of the descriptor from the local storage or the remote,
+// the config is served from the cache by the digest.
 func (b *backend) getModelConfig(ctx context.Context, reference string, desc ocispec.Descriptor, fromRemote, plainHTTP, insecure bool) (*modelspec.Model, error) {
 	ref, err := ParseReference(reference)
 	if err != nil {
@@ -270,48 +330,93 @@ func (b *backend) getModelConfig(ctx context.Context, reference string, desc oci
 		return nil, fmt.Errorf("repository name cannot be empty")
 	}
 
-	// Fetch from local storage if it is not remote.
+	if configRaw, ok := b.cache.get(desc.Digest); ok {
+		logrus.Debugf("manifest cache: hit config %s of %s", desc.Digest, reference)
+		return decodeModelConfig(configRaw)
+	}
+
+	var reader io.ReadCloser
 	if !fromRemote {
-		reader, err := b.store.PullBlob(ctx, repo, desc.Digest.String())
+		// Fetch from local storage if it is not remote.
+		reader, err = b.store.PullBlob(ctx, repo, desc.Digest.String())
 		if err != nil {
 			return nil, fmt.Errorf("failed to pull blob: %w", err)
 		}
-		defer reader.Close()
-
-		var model modelspec.Model
-		if err := json.NewDecoder(reader).Decode(&model); err != nil {
-			return nil, fmt.Errorf("failed to decode model config: %w", err)
+	} else {
+		client, err := remote.New(repo, remote.WithPlainHTTP(plainHTTP), remote.WithInsecure(insecure))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create remote client: %w", err)
 		}
 
-		return &model, nil
+		reader, err = client.Blobs().Fetch(ctx, desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch blob: %w", err)
+		}
 	}
+	defer reader.Close()
 
-	client, err := remote.New(repo, remote.WithPlainHTTP(plainHTTP), remote.WithInsecure(insecure))
+	configRaw, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create remote client: %w", err)
+		return nil, fmt.Errorf("failed to read model config: %w", err)
 	}
 
-	reader, err := client.Blobs().Fetch(ctx, desc)
+	model, err := decodeModelConfig(configRaw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch blob: %w", err)
+		return nil, err
 	}
-	defer reader.Close()
 
+	// only cache the config matching the digest, as the descriptor may be partial.
+	if checksum.Matches(desc.Digest, configRaw) {
+		b.cache.put(desc.Digest, configRaw)
+	}
+
+	return model, nil
+}
+
+// decodeModelConfig decodes the model config.
+func decodeModelConfig(configRaw []byte) (*modelspec.Model, error) {
 	var model modelspec.Model
-	if err := json.NewDecoder(reader).Decode(&model); err != nil {
-		return nil, fmt.Errorf("failed to decode model: %w", err)
+	if err := json.Unmarshal(configRaw, &model); err != nil {
+		return nil, fmt.Errorf("failed to decode model config: %w", err)
 	}
 
 	return &model, nil
 }
 
+// attachedPath returns the path of the attached file relative to the work dir, which is the
+// path in the model artifact classified by the rules of the paths such as data/*.txt.
+func attachedPath(destDir, filepath string) string {
+	if destDir != "" {
+		return pathfilepath.Join(destDir, pathfilepath.Base(filepath))
+	}
+
+	relPath := filepath
+	if pathfilepath.IsAbs(filepath) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return pathfilepath.Base(filepath)
+		}
+
+		if relPath, err = pathfilepath.Rel(wd, filepath); err != nil {
+			return pathfilepath.Base(filepath)
+		}
+	}
+
+	if pathfilepath.IsLocal(relPath) {
+		return pathfilepath.Clean(relPath)
+	}
+
+	// the file out of the work dir is classified by the base name.
+	return pathfilepath.Base(filepath)
+}
+
 func (b *backend) getProcessor(destDir, filepath string, rawMediaType bool) (processor.Processor, error) {
 	info, err := os.Stat(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat file %s: %w", filepath, err)
 	}
 
-	fileType := modelfile.InferFileType(filepath, info.Size())
+	fileType := modelfile.InferFileType(attachedPath(destDir, filepath), info.Size())
 
 	var mediaType string
 	switch fileType {
@@ -339,6 +444,12 @@ func (b *backend) getProcessor(destDir, filepath string, rawMediaType bool) (pro
 			mediaType = modelspec.MediaTypeModelDocRaw
 		}
 		return processor.NewDocProcessor(b.store, mediaType, []string{filepath}, destDir), nil
+	case modelfile.FileTypeDataset:
+		mediaType = modelspec.MediaTypeModelDataset
+		if rawMediaType {
+			mediaType = modelspec.MediaTypeModelDatasetRaw
+		}
+		return processor.NewDatasetProcessor(b.store, mediaType, []string{filepath}, destDir), nil
 	}
 
 	// Unreachable: InferFileType always returns a valid FileType.
@@ -374,11 +485,42 @@ func (b *backend) getBuilder(reference string, cfg *config.Attach) (build.Builde
 	return builder, nil
 }
 
+// orderLayers sorts the layers by the layer order, the layers are sorted by sortLayers if
+// the layer order is not specified.
+func orderLayers(layers []ocispec.Descriptor, order string) {
+	switch order {
+	case config.LayerOrderMetadataFirst:
+		sortLayersByPriority(layers, metadataFirstPriority)
+	default:
+		sortLayers(layers)
+	}
+}
+
+// metadataFirstPriority returns the priority of the media type for the metadata-first layer ordering.
+func metadataFirstPriority(mediaType string) int {
+	if _, ok := weightMediaTypes[mediaType]; ok {
+		return metadataFirstWeightPriority
+	}
+
+	if priority, ok := metadataFirstPriorityMap[mediaType]; ok {
+		return priority
+	}
+
+	return metadataFirstOtherPriority
+}
+
 // sortLayers sorts the layers group by mediaType and sort by the filepath.
 func sortLayers(layers []ocispec.Descriptor) {
+	sortLayersByPriority(layers, func(mediaType string) int {
+		return mediaTypePriorityMap[mediaType]
+	})
+}
+
+// sortLayersByPriority sorts the layers group by the priority of the mediaType and sort by the filepath.
+func sortLayersByPriority(layers []ocispec.Descriptor, priority func(mediaType string) int) {
 	sort.SliceStable(layers, func(i, j int) bool {
-		priorityI := mediaTypePriorityMap[layers[i].MediaType]
-		priorityJ := mediaTypePriorityMap[layers[j].MediaType]
+		priorityI := priority(layers[i].MediaType)
+		priorityJ := priority(layers[j].MediaType)
 
 		if priorityI != priorityJ {
 			return priorityI < priorityJ
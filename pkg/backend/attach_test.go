@@ -98,6 +98,30 @@ func TestGetProcessor(t *testing.T) {
 	}
 }
 
+func TestAttachedPath(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		destDir  string
+		filepath string
+		expected string
+	}{
+		{name: "relative", filepath: "data/labels.txt", expected: filepath.Join("data", "labels.txt")},
+		{name: "absolute in the work dir", filepath: filepath.Join(wd, "data", "labels.txt"), expected: filepath.Join("data", "labels.txt")},
+		{name: "absolute out of the work dir", filepath: filepath.Join(filepath.Dir(wd), "other", "labels.txt"), expected: "labels.txt"},
+		{name: "relative out of the work dir", filepath: "../data/labels.txt", expected: "labels.txt"},
+		{name: "destination dir", destDir: "data", filepath: "/tmp/labels.txt", expected: filepath.Join("data", "labels.txt")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, attachedPath(tc.destDir, tc.filepath))
+		})
+	}
+}
+
 func TestGetProcessorFileNotFound(t *testing.T) {
 	b := &backend{store: &mockstore.Storage{}}
 
@@ -333,3 +357,25 @@ func TestSortLayers(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderLayers(t *testing.T) {
+	layer := func(mediaType, filepath string) ocispec.Descriptor {
+		return ocispec.Descriptor{MediaType: mediaType, Annotations: map[string]string{modelspec.AnnotationFilepath: filepath}}
+	}
+
+	layers := []ocispec.Descriptor{
+		layer(modelspec.MediaTypeModelWeightRaw, "model-00002.safetensors"),
+		layer(modelspec.MediaTypeModelWeightRaw, "model-00001.safetensors"),
+		layer(modelspec.MediaTypeModelDocRaw, "README.md"),
+		layer("application/vnd.example.custom", "custom.bin"),
+		layer(modelspec.MediaTypeModelCode, "run.py"),
+		layer(modelspec.MediaTypeModelWeightConfigRaw, "config.json"),
+	}
+
+	orderLayers(layers, config.LayerOrderMetadataFirst)
+	filepaths := []string{}
+	for _, layer := range layers {
+		filepaths = append(filepaths, layer.Annotations[modelspec.AnnotationFilepath])
+	}
+	assert.Equal(t, []string{"config.json", "README.md", "run.py", "custom.bin", "model-00001.safetensors", "model-00002.safetensors"}, filepaths)
+}
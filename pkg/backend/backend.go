@@ -18,8 +18,10 @@ package backend
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/modelpack/modctl/pkg/config"
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
 	"github.com/modelpack/modctl/pkg/storage"
 )
 
@@ -31,6 +33,9 @@ type Backend interface {
 	// Logout logs out from a registry.
 	Logout(ctx context.Context, registry string) error
 
+	// Whoami reports the identity authenticated by the registry.
+	Whoami(ctx context.Context, registry string, cfg *config.Whoami) (*Identity, error)
+
 	// Attach attaches user materials into the model artifact which follows the Model Spec.
 	Attach(ctx context.Context, filepath string, cfg *config.Attach) error
 
@@ -40,9 +45,18 @@ type Backend interface {
 	// Build builds the user materials into the model artifact which follows the Model Spec.
 	Build(ctx context.Context, modelfilePath, workDir, target string, cfg *config.Build) error
 
+	// BuildFromFiles builds the in-memory files keyed by the relative path into the model artifact without a workspace.
+	BuildFromFiles(ctx context.Context, modelfilePath string, files map[string][]byte, target string, cfg *config.Build) error
+
+	// BuildFromHF downloads the HuggingFace model and builds it by the generated Modelfile into the model artifact.
+	BuildFromHF(ctx context.Context, modelURL, target string, cfg *config.Build) error
+
 	// Pull pulls an artifact from a registry.
 	Pull(ctx context.Context, target string, cfg *config.Pull) error
 
+	// CacheWarm prefetches the blobs of the remote model artifact into the local storage.
+	CacheWarm(ctx context.Context, target string, cfg *config.CacheWarm) (*CacheWarmReport, error)
+
 	// Fetch fetches partial files to the output.
 	Fetch(ctx context.Context, target string, cfg *config.Fetch) error
 
@@ -50,37 +64,111 @@ type Backend interface {
 	Push(ctx context.Context, target string, cfg *config.Push) error
 
 	// List lists all the model artifacts.
-	List(ctx context.Context) ([]*ModelArtifact, error)
+	List(ctx context.Context, cfg *config.List) ([]*ModelArtifact, error)
 
 	// Remove deletes the model artifact.
 	Remove(ctx context.Context, target string) (string, error)
 
 	// Prune prunes the unused blobs and clean up the storage.
-	Prune(ctx context.Context, dryRun, removeUntagged bool) error
+	Prune(ctx context.Context, cfg *config.Prune) error
+
+	// DiskUsage calculates the disk usage of the local storage by repository and tag.
+	DiskUsage(ctx context.Context) (*DiskUsage, error)
+
+	// SyncCheck compares the tags of the repository in the local storage with the remote ones.
+	SyncCheck(ctx context.Context, repo string, cfg *config.SyncCheck) (*SyncCheckReport, error)
+
+	// CleanUploads removes the dangling uploads in the local storage.
+	CleanUploads(ctx context.Context, cfg *config.CleanUploads) ([]storage.Upload, error)
+
+	// Fsck checks the integrity of the blobs in the local storage.
+	Fsck(ctx context.Context, target string, cfg *config.Fsck) (*FsckReport, error)
 
 	// Inspect inspects the model artifact.
 	Inspect(ctx context.Context, target string, cfg *config.Inspect) (any, error)
 
+	// Get gets the field of the model config by the dot-separated path.
+	Get(ctx context.Context, target string, cfg *config.Get) (any, error)
+
+	// SBOM generates the SBOM document of the model artifact.
+	SBOM(ctx context.Context, target string, cfg *config.SBOM) ([]byte, error)
+
+	// ExtractModelfile returns the Modelfile of the model artifact.
+	ExtractModelfile(ctx context.Context, target string, cfg *configmodelfile.ExtractConfig) ([]byte, error)
+
 	// Extract extracts the model artifact.
 	Extract(ctx context.Context, target string, cfg *config.Extract) error
 
 	// Tag creates a new tag that refers to the source model artifact.
 	Tag(ctx context.Context, source, target string) error
+
+	// Move renames the file in the model artifact without re-uploading the blobs.
+	Move(ctx context.Context, source, oldPath, newPath string, cfg *config.Move) error
+
+	// Detach removes the file from the model artifact by rewriting the config and manifest.
+	Detach(ctx context.Context, source, filepath string, cfg *config.Detach) error
+
+	// Save saves the model artifact to a single archive of the OCI image layout.
+	Save(ctx context.Context, target string, cfg *config.Save) error
+
+	// Load loads the model artifacts from the archive saved by Save.
+	Load(ctx context.Context, cfg *config.Load) ([]string, error)
+
+	// Delta creates the delta between the base and derived model artifacts.
+	Delta(ctx context.Context, base, derived, target string, cfg *config.Delta) error
+
+	// ApplyDelta reconstructs the derived model artifact from the base and the delta.
+	ApplyDelta(ctx context.Context, base, delta, target string, cfg *config.Delta) error
 }
 
 // backend is the implementation of Backend.
 type backend struct {
 	store storage.Storage
+
+	// storageDir is the directory of the local storage, which is empty if unknown.
+	storageDir string
+
+	// cache caches the manifests and the model configs, nil disables the caching.
+	cache *manifestCache
+}
+
+// Option is the option of the backend.
+type Option func(*options)
+
+// options is the options of the backend.
+type options struct {
+	persistManifestCache bool
+}
+
+// WithPersistentManifestCache persists the cached manifests and model configs in the storage
+// directory to be shared across the runs, they are only cached in memory by default.
+func WithPersistentManifestCache(persist bool) Option {
+	return func(o *options) {
+		o.persistManifestCache = persist
+	}
 }
 
 // New creates a new backend.
-func New(storageDir string) (Backend, error) {
+func New(storageDir string, opts ...Option) (Backend, error) {
+	backendOpts := &options{}
+	for _, opt := range opts {
+		opt(backendOpts)
+	}
+
 	store, err := storage.New("", storageDir)
 	if err != nil {
 		return nil, err
 	}
 
+	var cacheDir string
+	if backendOpts.persistManifestCache {
+		cacheDir = filepath.Join(storageDir, manifestCacheDir)
+	}
+
+	cache := newManifestCache(cacheDir)
 	return &backend{
-		store: store,
+		store:      &cacheInvalidatingStorage{Storage: store, cache: cache},
+		storageDir: storageDir,
+		cache:      cache,
 	}, nil
 }
@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"time"
 
 	retry "github.com/avast/retry-go/v4"
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
@@ -29,12 +31,19 @@ import (
 	"github.com/sirupsen/logrus"
 
 	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/archiver"
 	"github.com/modelpack/modctl/pkg/backend/build"
 	buildconfig "github.com/modelpack/modctl/pkg/backend/build/config"
 	"github.com/modelpack/modctl/pkg/backend/build/hooks"
 	"github.com/modelpack/modctl/pkg/backend/processor"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/codec"
 	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/encryption"
+	"github.com/modelpack/modctl/pkg/logging"
 	"github.com/modelpack/modctl/pkg/modelfile"
+	"github.com/modelpack/modctl/pkg/modelfile/parser"
 	"github.com/modelpack/modctl/pkg/source"
 )
 
@@ -45,6 +54,24 @@ const (
 
 // Build builds the user materials into the model artifact which follows the Model Spec.
 func (b *backend) Build(ctx context.Context, modelfilePath, workDir, target string, cfg *config.Build) error {
+	return b.build(ctx, modelfilePath, workDir, nil, target, cfg)
+}
+
+// BuildFromFiles builds the in-memory files keyed by the relative path into the model artifact,
+// which bypasses the walk of the work directory, such as for the generated files.
+func (b *backend) BuildFromFiles(ctx context.Context, modelfilePath string, files map[string][]byte, target string, cfg *config.Build) error {
+	archive, err := archiver.NewMemoryArchive(files)
+	if err != nil {
+		return fmt.Errorf("failed to create archive of the files: %w", err)
+	}
+	defer archive.Close()
+
+	return b.build(ctx, modelfilePath, "", archive, target, cfg)
+}
+
+// build builds the files of the work directory, or the archive if specified, into the model artifact.
+func (b *backend) build(ctx context.Context, modelfilePath, workDir string, archive *archiver.Archive, target string, cfg *config.Build) error {
+	start := time.Now()
 	logrus.Infof("build: building artifact %s", target)
 	// parse the repo name and tag name from target.
 	ref, err := ParseReference(target)
@@ -52,16 +79,62 @@ func (b *backend) Build(ctx context.Context, modelfilePath, workDir, target stri
 		return fmt.Errorf("failed to parse target: %w", err)
 	}
 
-	modelfile, err := modelfile.NewModelfile(modelfilePath)
+	var parserOpts []parser.Option
+	if cfg.OllamaModelfile {
+		parserOpts = append(parserOpts, parser.WithOllama())
+	}
+
+	modelfile, err := modelfile.NewModelfile(modelfilePath, parserOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to parse modelfile: %w", err)
 	}
 
+	// store the raw Modelfile in the artifact, as the generated content carries the build time.
+	modelfileContent, err := os.ReadFile(modelfilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read modelfile: %w", err)
+	}
+
 	repo, tag := ref.Repository(), ref.Tag()
 	if tag == "" {
 		return fmt.Errorf("tag is required")
 	}
 
+	if err := b.checkTargetOverwrite(ctx, repo, tag, cfg); err != nil {
+		return err
+	}
+
+	// build from the entries of the tar archive directly if the work dir is a tarball.
+	if archive != nil {
+		logrus.Infof("build: building from %d in-memory files", len(archive.Names()))
+	} else if info, err := os.Stat(workDir); err == nil && !info.IsDir() {
+		logrus.Infof("build: building from archive %s", workDir)
+		archive, err = archiver.OpenArchive(workDir)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer archive.Close()
+	}
+
+	// The git-lfs pointers and the metadata are checked by reading the files in the work
+	// directory, so they are skipped explicitly for the archive builds.
+	if archive == nil {
+		if err := checkLFSPointers(workDir, modelfile.GetModels(), cfg); err != nil {
+			return err
+		}
+
+		if err := checkMetadata(workDir, modelfile, cfg); err != nil {
+			return err
+		}
+	} else if cfg.VerifyMetadata != "" {
+		logrus.Warnf("build: skip verifying the metadata, it is not supported when building from archive")
+	}
+
+	// the archive carries the files only, so the empty directories cannot be kept.
+	if archive != nil && cfg.KeepEmptyDirs {
+		return fmt.Errorf("keeping the empty directories is not supported when building from the archive")
+	}
+
 	sourceInfo, err := getSourceInfo(workDir, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to get source info: %w", err)
@@ -76,6 +149,21 @@ func (b *backend) Build(ctx context.Context, modelfilePath, workDir, target stri
 	opts := []build.Option{
 		build.WithPlainHTTP(cfg.PlainHTTP),
 		build.WithInsecure(cfg.Insecure),
+		build.WithFastChecksum(cfg.FastChecksum),
+		build.WithVerifyCache(cfg.VerifyCache),
+		build.WithExecPatterns(cfg.ExecPatterns),
+		build.WithStripMetadata(cfg.StripMetadata),
+		build.WithCanonicalJSON(cfg.CanonicalJSON),
+		build.WithJSONIndent(cfg.JSONIndent),
+	}
+
+	if cfg.EncryptionKey != "" {
+		key, err := encryption.LoadKey(cfg.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed to load the encryption key: %w", err)
+		}
+
+		opts = append(opts, build.WithEncryptionKey(key))
 	}
 
 	builder, err := build.NewBuilder(outputType, b.store, repo, tag, opts...)
@@ -83,19 +171,65 @@ func (b *backend) Build(ctx context.Context, modelfilePath, workDir, target stri
 		return fmt.Errorf("failed to create builder: %w", err)
 	}
 
+	// skip the layers built by the interrupted build and record the new ones to resume the build.
+	var journal *buildJournal
+	if cfg.ResumeBuild {
+		if b.storageDir == "" {
+			return fmt.Errorf("resume build requires the local storage directory")
+		}
+
+		journal, err = openBuildJournal(buildJournalPath(b.storageDir, target, modelfileContent, cfg))
+		if err != nil {
+			return err
+		}
+		defer journal.Close()
+
+		builder = &journalBuilder{Builder: builder, journal: journal, store: b.store, repo: repo}
+	}
+
+	// collect the build duration of the files to report the slowest ones, the report is deferred
+	// ahead of the progress bar to run after it stops, which avoids interleaving with it.
+	var timings *processor.Timings
+	if cfg.ReportSlow > 0 {
+		timings = processor.NewTimings()
+		defer reportSlowFiles(timings, cfg)
+	}
+
 	pb := internalpb.NewProgressBar()
 	pb.Start()
 	defer pb.Stop()
 
 	layers := []ocispec.Descriptor{}
-	layerDescs, err := b.process(ctx, builder, workDir, pb, cfg, b.getProcessors(modelfile, cfg)...)
+	layerDescs, err := b.process(ctx, builder, workDir, archive, pb, timings, cfg, b.getProcessors(modelfile, cfg)...)
 	if err != nil {
 		return fmt.Errorf("failed to process files: %w", err)
 	}
 
 	layers = append(layers, layerDescs...)
+	// the empty directories are not matched by the Modelfile, which only lists the files.
+	if cfg.KeepEmptyDirs && cfg.Only != config.OnlyWeights {
+		emptyDirDescs, err := b.buildEmptyDirs(ctx, builder, workDir)
+		if err != nil {
+			return err
+		}
+
+		layers = append(layers, emptyDirDescs...)
+	}
+
+	if err := checkBuildLimits(target, layers, cfg); err != nil {
+		return err
+	}
+
+	if cfg.LayerOrder != "" {
+		orderLayers(layers, cfg.LayerOrder)
+	}
+
+	if cfg.LoadOrder != "" {
+		annotateLoadOrder(layers, cfg.LoadOrder)
+	}
 
 	logrus.Infof("build: processed layers [count: %d, layers: %+v]", len(layers), layers)
+	licenses := detectLicenses(workDir, archive, layers)
 
 	revision := sourceInfo.Commit
 	if revision != "" && sourceInfo.Dirty {
@@ -135,14 +269,15 @@ func (b *backend) Build(ctx context.Context, modelfilePath, workDir, target stri
 				pb.Complete(name, fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Built config"), desc.Digest))
 			}),
 		))
-		return err
+		return remote.UnrecoverableOnUnsupportedMediaType(err)
 	}, append(defaultRetryOpts, retry.Context(ctx))...); err != nil {
 		return fmt.Errorf("failed to build model config: %w", err)
 	}
 
 	// Build the model manifest.
+	var manifestDesc ocispec.Descriptor
 	if err := retry.Do(func() error {
-		_, err = builder.BuildManifest(ctx, layers, configDesc, manifestAnnotation(modelfile), hooks.NewHooks(
+		manifestDesc, err = builder.BuildManifest(ctx, layers, configDesc, manifestAnnotation(modelfile, modelfileContent, layers, config, licenses, cfg), hooks.NewHooks(
 			hooks.WithOnStart(func(name string, size int64, reader io.Reader) io.Reader {
 				return pb.Add(internalpb.NormalizePrompt("Building manifest"), name, size, reader)
 			}),
@@ -153,58 +288,136 @@ func (b *backend) Build(ctx context.Context, modelfilePath, workDir, target stri
 				pb.Complete(name, fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Built manifest"), desc.Digest))
 			}),
 		))
-		return err
+		return remote.UnrecoverableOnUnsupportedMediaType(err)
 	}, append(defaultRetryOpts, retry.Context(ctx))...); err != nil {
 		return fmt.Errorf("failed to build model manifest: %w", err)
 	}
 
-	logrus.Infof("build: built artifact %s", target)
+	if cfg.ModelfileReferrer {
+		if err := b.attachModelfile(ctx, repo, manifestDesc, modelfileContent, cfg); err != nil {
+			return fmt.Errorf("failed to attach modelfile: %w", err)
+		}
+	}
+
+	if journal != nil {
+		if err := journal.Remove(); err != nil {
+			logrus.Warnf("build: %s", err)
+		}
+	}
+
+	logging.Event("build", target, manifestDesc.Digest.String(), start).Infof("build: built artifact %s", target)
 	return nil
 }
 
 func (b *backend) getProcessors(modelfile modelfile.Modelfile, cfg *config.Build) []processor.Processor {
 	processors := []processor.Processor{}
 
-	if configs := modelfile.GetConfigs(); len(configs) > 0 {
+	// filter out the weight configs, code and docs for the weights-only artifact.
+	weightsOnly := cfg.Only == config.OnlyWeights
+	if weightsOnly {
+		logrus.Infof("build: building weights only, skipping %d configs, %d codes, %d docs and %d datasets in the Modelfile",
+			len(modelfile.GetConfigs()), len(modelfile.GetCodes()), len(modelfile.GetDocs()), len(modelfile.GetDatasets()))
+	}
+
+	if configs := modelfile.GetConfigs(); (len(configs) > 0 || len(cfg.AsConfig) > 0) && !weightsOnly {
 		mediaType := modelspec.MediaTypeModelWeightConfig
-		if cfg.Raw {
+		if cfg.Compress {
+			mediaType = modelspec.MediaTypeModelWeightConfigGzip
+		} else if cfg.Raw {
 			mediaType = modelspec.MediaTypeModelWeightConfigRaw
 		}
 		processors = append(processors, processor.NewModelConfigProcessor(b.store, mediaType, configs, ""))
 	}
 
-	if models := modelfile.GetModels(); len(models) > 0 {
+	if models := modelfile.GetModels(); len(models) > 0 || len(cfg.AsModel) > 0 {
 		mediaType := modelspec.MediaTypeModelWeight
-		if cfg.Raw {
+		if cfg.Compress {
+			mediaType = modelspec.MediaTypeModelWeightGzip
+		} else if cfg.Raw {
 			mediaType = modelspec.MediaTypeModelWeightRaw
 		}
 		processors = append(processors, processor.NewModelProcessor(b.store, mediaType, models, ""))
 	}
 
-	if codes := modelfile.GetCodes(); len(codes) > 0 {
+	if codes := modelfile.GetCodes(); (len(codes) > 0 || len(cfg.AsCode) > 0) && !weightsOnly {
 		mediaType := modelspec.MediaTypeModelCode
-		if cfg.Raw {
+		if cfg.Compress {
+			mediaType = modelspec.MediaTypeModelCodeGzip
+		} else if cfg.Raw {
 			mediaType = modelspec.MediaTypeModelCodeRaw
 		}
 		processors = append(processors, processor.NewCodeProcessor(b.store, mediaType, codes, ""))
 	}
 
-	if docs := modelfile.GetDocs(); len(docs) > 0 {
+	if docs := modelfile.GetDocs(); (len(docs) > 0 || len(cfg.AsDoc) > 0) && !weightsOnly {
 		mediaType := modelspec.MediaTypeModelDoc
-		if cfg.Raw {
+		if cfg.Compress {
+			mediaType = modelspec.MediaTypeModelDocGzip
+		} else if cfg.Raw {
 			mediaType = modelspec.MediaTypeModelDocRaw
 		}
 		processors = append(processors, processor.NewDocProcessor(b.store, mediaType, docs, ""))
 	}
 
+	if datasets := modelfile.GetDatasets(); (len(datasets) > 0 || len(cfg.AsDataset) > 0) && !weightsOnly {
+		mediaType := modelspec.MediaTypeModelDataset
+		if cfg.Compress {
+			mediaType = modelspec.MediaTypeModelDatasetGzip
+		} else if cfg.Raw {
+			mediaType = modelspec.MediaTypeModelDatasetRaw
+		}
+		processors = append(processors, processor.NewDatasetProcessor(b.store, mediaType, datasets, ""))
+	}
+
 	return processors
 }
 
-// process walks the user work directory and process the identified files.
-func (b *backend) process(ctx context.Context, builder build.Builder, workDir string, pb *internalpb.ProgressBar, cfg *config.Build, processors ...processor.Processor) ([]ocispec.Descriptor, error) {
+// classOverrides returns the patterns overriding the classification of the files keyed by the
+// names of the processors of the layer kinds.
+func classOverrides(cfg *config.Build) map[string][]string {
+	names := map[string]string{
+		config.LayerKindConfig:  processor.ModelConfigProcessorName,
+		config.LayerKindWeights: processor.ModelProcessorName,
+		config.LayerKindCode:    processor.CodeProcessorName,
+		config.LayerKindDoc:     processor.DocProcessorName,
+		config.LayerKindDataset: processor.DatasetProcessorName,
+	}
+
+	overrides := map[string][]string{}
+	for kind, patterns := range cfg.ClassOverrides() {
+		overrides[names[kind]] = patterns
+	}
+
+	return overrides
+}
+
+// process walks the user work directory or the archive and process the identified files.
+func (b *backend) process(ctx context.Context, builder build.Builder, workDir string, archive *archiver.Archive, pb *internalpb.ProgressBar, timings *processor.Timings, cfg *config.Build, processors ...processor.Processor) ([]ocispec.Descriptor, error) {
+	opts := []processor.ProcessOption{processor.WithConcurrency(cfg.Concurrency), processor.WithProgressTracker(pb), processor.WithAggregateProgress(cfg.Progress == config.ProgressAggregate)}
+	if archive != nil {
+		opts = append(opts, processor.WithArchive(archive))
+	}
+
+	if timings != nil {
+		opts = append(opts, processor.WithTimings(timings))
+	}
+
+	if cfg.Compress {
+		policy, err := codec.NewCompressionPolicy(cfg.CompressPatterns, cfg.NoCompressPatterns)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, processor.WithCompressionPolicy(policy))
+	}
+
+	if overrides := classOverrides(cfg); len(overrides) > 0 {
+		opts = append(opts, processor.WithOverrides(overrides))
+	}
+
 	descriptors := []ocispec.Descriptor{}
 	for _, p := range processors {
-		descs, err := p.Process(ctx, builder, workDir, processor.WithConcurrency(cfg.Concurrency), processor.WithProgressTracker(pb))
+		descs, err := p.Process(ctx, builder, workDir, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -215,10 +428,57 @@ func (b *backend) process(ctx context.Context, builder build.Builder, workDir st
 	return descriptors, nil
 }
 
+// reportSlowFiles reports the slowest files of the build.
+func reportSlowFiles(timings *processor.Timings, cfg *config.Build) {
+	for _, timing := range timings.Slowest(cfg.ReportSlow) {
+		logrus.Infof("build: slow file %s [duration: %s, size: %d]", timing.Path, timing.Duration, timing.Size)
+	}
+
+	if cfg.ReportWriter != nil {
+		timings.Report(cfg.ReportWriter, cfg.ReportSlow)
+	}
+}
+
 // manifestAnnotation returns the annotations for the manifest.
-func manifestAnnotation(modelfile modelfile.Modelfile) map[string]string {
-	anno := map[string]string{
-		annotationModelfile: string(modelfile.Content()),
+func manifestAnnotation(modelfile modelfile.Modelfile, modelfileContent []byte, layers []ocispec.Descriptor, model modelspec.Model, licenses string, cfg *config.Build) map[string]string {
+	anno := map[string]string{}
+
+	// mirror the descriptor of the model config by the standard keys displayed by the generic OCI tooling.
+	if model.Descriptor.CreatedAt != nil {
+		anno[ocispec.AnnotationCreated] = model.Descriptor.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	if model.Descriptor.SourceURL != "" {
+		anno[ocispec.AnnotationSource] = model.Descriptor.SourceURL
+	}
+
+	if model.Descriptor.Revision != "" {
+		anno[ocispec.AnnotationRevision] = model.Descriptor.Revision
+	}
+
+	// record the licenses detected from the license files for the catalogs to filter by.
+	if licenses != "" {
+		anno[ocispec.AnnotationLicenses] = licenses
+	}
+
+	// the Modelfile is stored as the referrer instead of the annotation if required.
+	if !cfg.ModelfileReferrer {
+		anno[annotationModelfile] = string(modelfileContent)
+	}
+
+	// record the directives of the Ollama Modelfile for the interop.
+	if cfg.OllamaModelfile {
+		maps.Copy(anno, ollamaAnnotations(modelfile))
+	}
+
+	// record the layer order for attach to preserve it.
+	if cfg.LayerOrder != "" {
+		anno[annotationLayerOrder] = cfg.LayerOrder
+	}
+
+	// record the merkle root over the layers as the integrity token of the whole artifact.
+	if cfg.MerkleRoot {
+		anno[checksum.AnnotationMerkleRoot] = merkleRoot(layers)
 	}
 	return anno
 }
@@ -230,8 +490,8 @@ func getSourceInfo(workspace string, buildConfig *config.Build) (*source.Info, e
 		Commit: buildConfig.SourceRevision,
 	}
 
-	// Try to parse the source information if user not specified.
-	if info.URL == "" {
+	// Try to parse the source information if user not specified, the in-memory files have no workspace.
+	if info.URL == "" && workspace != "" {
 		var parser source.Parser
 
 		gitPath := filepath.Join(workspace, ".git")
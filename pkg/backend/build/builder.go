@@ -23,13 +23,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	sha256 "github.com/minio/sha256-simd"
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
 	godigest "github.com/opencontainers/go-digest"
 	spec "github.com/opencontainers/image-spec/specs-go"
@@ -37,10 +39,14 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/modelpack/modctl/internal/cache"
+	"github.com/modelpack/modctl/pkg/archiver"
 	buildconfig "github.com/modelpack/modctl/pkg/backend/build/config"
 	"github.com/modelpack/modctl/pkg/backend/build/hooks"
 	"github.com/modelpack/modctl/pkg/backend/build/interceptor"
+	"github.com/modelpack/modctl/pkg/canonicaljson"
+	"github.com/modelpack/modctl/pkg/checksum"
 	pkgcodec "github.com/modelpack/modctl/pkg/codec"
+	"github.com/modelpack/modctl/pkg/encryption"
 	"github.com/modelpack/modctl/pkg/storage"
 )
 
@@ -59,6 +65,9 @@ type Builder interface {
 	// BuildLayer builds the layer blob from the given file path.
 	BuildLayer(ctx context.Context, mediaType, workDir, path, destPath string, hooks hooks.Hooks) (ocispec.Descriptor, error)
 
+	// BuildLayerFromArchive builds the layer blob from the entry of the tar archive.
+	BuildLayerFromArchive(ctx context.Context, mediaType string, archive *archiver.Archive, name, destPath string, hooks hooks.Hooks) (ocispec.Descriptor, error)
+
 	// BuildConfig builds the config blob of the artifact.
 	BuildConfig(ctx context.Context, config modelspec.Model, hooks hooks.Hooks) (ocispec.Descriptor, error)
 
@@ -109,12 +118,19 @@ func NewBuilder(outputType OutputType, store storage.Storage, repo, tag string,
 	}
 
 	return &abstractBuilder{
-		store:       store,
-		repo:        repo,
-		tag:         tag,
-		strategy:    strategy,
-		interceptor: cfg.interceptor,
-		cache:       cache,
+		store:           store,
+		repo:            repo,
+		tag:             tag,
+		strategy:        strategy,
+		interceptor:     cfg.interceptor,
+		cache:           cache,
+		fastChecksum:    cfg.fastChecksum,
+		verifyCacheRate: cfg.verifyCacheRate,
+		execPatterns:    cfg.execPatterns,
+		encryptionKey:   cfg.encryptionKey,
+		stripMetadata:   cfg.stripMetadata,
+		canonicalJSON:   cfg.canonicalJSON,
+		jsonIndent:      cfg.jsonIndent,
 	}, nil
 }
 
@@ -123,6 +139,20 @@ type abstractBuilder struct {
 	store storage.Storage
 	repo  string
 	tag   string
+	// fastChecksum indicates whether to annotate the layers with the fast checksum.
+	fastChecksum bool
+	// verifyCacheRate is the fraction of the digest cache hits to recompute and compare.
+	verifyCacheRate float64
+	// execPatterns is the patterns of the files to be marked as executable.
+	execPatterns []string
+	// encryptionKey is the key to encrypt the layers, the layers are not encrypted if it is empty.
+	encryptionKey []byte
+	// stripMetadata indicates whether to omit the mode, owner and mtime from the file metadata.
+	stripMetadata bool
+	// canonicalJSON indicates whether to marshal the config and manifest in the canonical JSON.
+	canonicalJSON bool
+	// jsonIndent is the number of spaces to indent the config and manifest JSON, zero is compact.
+	jsonIndent int
 	// strategy is the output strategy used to output the blob.
 	strategy OutputStrategy
 	// interceptor is the interceptor used to intercept the build process.
@@ -137,8 +167,9 @@ func (ab *abstractBuilder) BuildLayer(ctx context.Context, mediaType, workDir, p
 		return ocispec.Descriptor{}, fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	if info.IsDir() {
-		return ocispec.Descriptor{}, fmt.Errorf("%s is a directory and not supported yet", path)
+	// The directory is packed into a single layer, which requires the tar format.
+	if info.IsDir() && pkgcodec.TypeFromMediaType(mediaType) == pkgcodec.Raw {
+		return ocispec.Descriptor{}, fmt.Errorf("%s is a directory which can only be built in tar format", path)
 	}
 
 	workDirPath, err := filepath.Abs(workDir)
@@ -160,17 +191,228 @@ func (ab *abstractBuilder) BuildLayer(ctx context.Context, mediaType, workDir, p
 
 	logrus.Debugf("builder: starting build layer for file %s", relPath)
 
-	// Encode the content by codec depends on the media type.
-	reader, err := codec.Encode(path, workDirPath)
+	var (
+		reader               io.Reader
+		digest               string
+		size                 int64
+		fastChecksum         string
+		encryptionAnnotation string
+	)
+	if len(ab.encryptionKey) == 0 {
+		// Encode the content by codec depends on the media type.
+		reader, err = codec.Encode(path, workDirPath)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to encode file: %w", err)
+		}
+
+		reader, digest, size, fastChecksum, err = ab.computeDigestAndSize(ctx, mediaType, path, workDirPath, info, reader, codec)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to compute digest and size: %w", err)
+		}
+	} else {
+		// The digest is computed over the ciphertext, so the digest cache of the
+		// plaintext is bypassed.
+		encode, annotation, err := ab.encrypt(func() (io.Reader, error) {
+			return codec.Encode(path, workDirPath)
+		})
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+
+		reader, digest, size, fastChecksum, err = ab.digestEncoded(encode, "file "+path)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to compute digest and size: %w", err)
+		}
+
+		encryptionAnnotation, err = annotation()
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	desc, err := ab.outputLayer(ctx, mediaType, relPath, destPath, codec.Type(), digest, size, fastChecksum, reader, hooks)
+	if err != nil {
+		return desc, err
+	}
+
+	annotateEncryption(&desc, encryptionAnnotation)
+
+	// Add file metadata to descriptor.
+	if err := addFileMetadata(&desc, path, relPath, ab.stripMetadata); err != nil {
+		return desc, err
+	}
+
+	return desc, nil
+}
+
+func (ab *abstractBuilder) BuildLayerFromArchive(ctx context.Context, mediaType string, archive *archiver.Archive, name, destPath string, hooks hooks.Hooks) (ocispec.Descriptor, error) {
+	entry, ok := archive.Entry(name)
+	if !ok {
+		return ocispec.Descriptor{}, fmt.Errorf("entry %s does not exist in archive", name)
+	}
+
+	// Encode the entry by codec depends on the media type, the entry is read
+	// from the archive directly without extracting to the disk.
+	codecType := pkgcodec.TypeFromMediaType(mediaType)
+	encode := func() (io.Reader, error) {
+		switch codecType {
+		case pkgcodec.Raw:
+			return archive.Open(entry.Header.Name)
+		case pkgcodec.Tar:
+			return archive.Tar(entry.Header.Name)
+		case pkgcodec.TarGzip:
+			reader, err := archive.Tar(entry.Header.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			return pkgcodec.Gzip(reader), nil
+		default:
+			return nil, fmt.Errorf("unsupported codec type: %s", codecType)
+		}
+	}
+
+	logrus.Debugf("builder: starting build layer for archive entry %s", entry.Header.Name)
+
+	encode, annotation, err := ab.encrypt(encode)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("failed to encode file: %w", err)
+		return ocispec.Descriptor{}, err
 	}
 
-	reader, digest, size, err := ab.computeDigestAndSize(ctx, mediaType, path, workDirPath, info, reader, codec)
+	reader, digest, size, fastChecksum, err := ab.digestEncoded(encode, "archive entry "+entry.Header.Name)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	encryptionAnnotation, err := annotation()
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc, err := ab.outputLayer(ctx, mediaType, entry.Header.Name, destPath, codecType, digest, size, fastChecksum, reader, hooks)
+	if err != nil {
+		return desc, err
+	}
+
+	annotateEncryption(&desc, encryptionAnnotation)
+
+	// Add the file metadata from the tar header to descriptor.
+	metadata := modelspec.FileMetadata{
+		Name:     filepath.Base(entry.Header.Name),
+		Mode:     uint32(entry.Header.FileInfo().Mode().Perm()),
+		Uid:      uint32(entry.Header.Uid),
+		Gid:      uint32(entry.Header.Gid),
+		Size:     entry.Header.Size,
+		ModTime:  entry.Header.ModTime,
+		Typeflag: 0, // Regular file
+	}
+
+	if ab.stripMetadata {
+		metadata = stripFileMetadata(metadata)
+	}
+
+	metadataStr, err := json.Marshal(metadata)
 	if err != nil {
-		return ocispec.Descriptor{}, fmt.Errorf("failed to compute digest and size: %w", err)
+		return desc, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	if desc.Annotations == nil {
+		desc.Annotations = make(map[string]string)
+	}
+	desc.Annotations[modelspec.AnnotationFileMetadata] = string(metadataStr)
+	return desc, nil
+}
+
+// digestEncoded computes the digest and size of the encoded content, then encodes
+// again for the output as the reader has been consumed.
+func (ab *abstractBuilder) digestEncoded(encode func() (io.Reader, error), name string) (io.Reader, string, int64, string, error) {
+	reader, err := encode()
+	if err != nil {
+		return nil, "", 0, "", fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	hash, fast := checksum.NewSHA256(), checksum.New()
+	var writer io.Writer = hash
+	if ab.fastChecksum {
+		writer = io.MultiWriter(hash, fast)
+	}
+
+	size, err := io.Copy(writer, reader)
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+	if err != nil {
+		return nil, "", 0, "", fmt.Errorf("failed to copy content to hash: %w", err)
+	}
+	digest := fmt.Sprintf("sha256:%x", hash.Sum(nil))
+
+	var fastChecksum string
+	if ab.fastChecksum {
+		fastChecksum = checksum.Format(fast)
+	}
+
+	logrus.Infof("builder: calculated digest for %s [digest: %s]", name, digest)
+
+	// Encode again for the output as the reader has been consumed.
+	reader, err = encode()
+	if err != nil {
+		return nil, "", 0, "", fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	return reader, digest, size, fastChecksum, nil
+}
+
+// encrypt wraps the encode function to encrypt the encoded content by a new envelope
+// if the encryption key is set, and returns the function returning the encryption annotation
+// of the layer, which records the plaintext digest once the encoded content is read.
+func (ab *abstractBuilder) encrypt(encode func() (io.Reader, error)) (func() (io.Reader, error), func() (string, error), error) {
+	if len(ab.encryptionKey) == 0 {
+		return encode, func() (string, error) { return "", nil }, nil
+	}
+
+	envelope, err := encryption.NewEnvelope(ab.encryptionKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create encryption envelope: %w", err)
+	}
+
+	return func() (io.Reader, error) {
+		reader, err := encode()
+		if err != nil {
+			return nil, err
+		}
+
+		encrypted, err := envelope.Encrypt(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep the source closable after it is wrapped by the encryption.
+		if closer, ok := reader.(io.Closer); ok {
+			return struct {
+				io.Reader
+				io.Closer
+			}{encrypted, closer}, nil
+		}
+
+		return encrypted, nil
+	}, envelope.Annotation, nil
+}
+
+// annotateEncryption records the encryption metadata in the descriptor of the encrypted layer.
+func annotateEncryption(desc *ocispec.Descriptor, annotation string) {
+	if annotation == "" {
+		return
+	}
+
+	if desc.Annotations == nil {
+		desc.Annotations = make(map[string]string)
+	}
+	desc.Annotations[encryption.AnnotationEncryption] = annotation
+}
+
+// outputLayer outputs the encoded layer by the strategy, then applies the interceptor
+// and the fast checksum to the descriptor.
+func (ab *abstractBuilder) outputLayer(ctx context.Context, mediaType, relPath, destPath string, codecType pkgcodec.Type, digest string, size int64, fastChecksum string, reader io.Reader, hooks hooks.Hooks) (ocispec.Descriptor, error) {
 	var (
 		wg        sync.WaitGroup
 		itErr     error
@@ -184,7 +426,7 @@ func (ab *abstractBuilder) BuildLayer(ctx context.Context, mediaType, workDir, p
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			applyDesc, itErr = ab.interceptor.Intercept(ctx, mediaType, relPath, codec.Type(), itReader)
+			applyDesc, itErr = ab.interceptor.Intercept(ctx, mediaType, relPath, codecType, itReader)
 		}()
 	}
 
@@ -203,21 +445,47 @@ func (ab *abstractBuilder) BuildLayer(ctx context.Context, mediaType, workDir, p
 		applyDesc(&desc)
 	}
 
-	// Add file metadata to descriptor.
-	if err := addFileMetadata(&desc, path, relPath); err != nil {
-		return desc, err
+	if fastChecksum != "" {
+		if desc.Annotations == nil {
+			desc.Annotations = make(map[string]string)
+		}
+		desc.Annotations[checksum.AnnotationFastChecksum] = fastChecksum
+	}
+
+	if matchExecPatterns(ab.execPatterns, relPath) {
+		if desc.Annotations == nil {
+			desc.Annotations = make(map[string]string)
+		}
+		desc.Annotations[pkgcodec.AnnotationExecutable] = "true"
 	}
 
 	return desc, nil
 }
 
+// matchExecPatterns checks whether the relative path matches any of the exec patterns,
+// the pattern is matched against both the relative path and the base name.
+func matchExecPatterns(patterns []string, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(relPath)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (ab *abstractBuilder) BuildConfig(ctx context.Context, config modelspec.Model, hooks hooks.Hooks) (ocispec.Descriptor, error) {
-	configJSON, err := json.Marshal(config)
+	configJSON, err := ab.marshalJSON(config)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(configJSON))
+	digest := fmt.Sprintf("sha256:%x", checksum.Sum256(configJSON))
 	return ab.strategy.OutputConfig(ctx, modelspec.MediaTypeModelConfig, digest, int64(len(configJSON)), bytes.NewReader(configJSON), hooks)
 }
 
@@ -237,55 +505,97 @@ func (ab *abstractBuilder) BuildManifest(ctx context.Context, layers []ocispec.D
 		Layers:    layers,
 	}
 
-	manifestJSON, err := json.Marshal(manifest)
+	manifestJSON, err := ab.marshalJSON(manifest)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal manifest: %w", err)
 	}
 
-	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifestJSON))
+	digest := fmt.Sprintf("sha256:%x", checksum.Sum256(manifestJSON))
 	return ab.strategy.OutputManifest(ctx, manifest.MediaType, digest, int64(len(manifestJSON)), bytes.NewReader(manifestJSON), hooks)
 }
 
+// marshalJSON marshals the config or manifest in the canonical JSON if enabled, which is
+// indented by the configured spaces, otherwise by the field order of the types.
+func (ab *abstractBuilder) marshalJSON(v any) ([]byte, error) {
+	if ab.canonicalJSON {
+		return canonicaljson.MarshalIndent(v, ab.jsonIndent)
+	}
+
+	if ab.jsonIndent > 0 {
+		return json.MarshalIndent(v, "", strings.Repeat(" ", ab.jsonIndent))
+	}
+
+	return json.Marshal(v)
+}
+
 // computeDigestAndSize computes the digest and size for the encoded content, using cache if available.
-func (ab *abstractBuilder) computeDigestAndSize(ctx context.Context, mediaType, path, workDirPath string, info os.FileInfo, reader io.Reader, codec pkgcodec.Codec) (io.Reader, string, int64, error) {
+// The fast checksum is also computed if enabled, otherwise it returns empty.
+func (ab *abstractBuilder) computeDigestAndSize(ctx context.Context, mediaType, path, workDirPath string, info os.FileInfo, reader io.Reader, codec pkgcodec.Codec) (io.Reader, string, int64, string, error) {
 	// Try to retrieve valid digest from cache for raw model weights.
+	var cachedDigest string
 	if mediaType == modelspec.MediaTypeModelWeightRaw {
-		if digest, size, ok := ab.retrieveCache(ctx, path, info); ok {
-			return reader, digest, size, nil
+		item, ok := ab.retrieveCache(ctx, path, info)
+		if ok && ab.shouldVerifyCache() {
+			// Recompute the digest to compare with the cached one instead of trusting it.
+			logrus.Infof("builder: verifying cached digest for file %s", path)
+			cachedDigest, ok = item.Digest, false
+		}
+
+		// The cache item without the fast checksum is recomputed if the fast checksum is
+		// enabled, which also fills the fast checksum into the cache for the next build.
+		if ok && (!ab.fastChecksum || item.FastChecksum != "") {
+			if !ab.fastChecksum {
+				return reader, item.Digest, item.Size, "", nil
+			}
+
+			return reader, item.Digest, item.Size, item.FastChecksum, nil
 		}
 	}
 
 	logrus.Infof("builder: calculating digest for file %s", path)
 
-	hash := sha256.New()
-	size, err := io.Copy(hash, reader)
+	hash, fast := checksum.NewSHA256(), checksum.New()
+	var writer io.Writer = hash
+	if ab.fastChecksum {
+		writer = io.MultiWriter(hash, fast)
+	}
+
+	size, err := io.Copy(writer, reader)
 	if err != nil {
-		return reader, "", 0, fmt.Errorf("failed to copy content to hash: %w", err)
+		return reader, "", 0, "", fmt.Errorf("failed to copy content to hash: %w", err)
 	}
 	digest := fmt.Sprintf("sha256:%x", hash.Sum(nil))
 
+	var fastChecksum string
+	if ab.fastChecksum {
+		fastChecksum = checksum.Format(fast)
+	}
+
 	logrus.Infof("builder: calculated digest for file %s [digest: %s]", path, digest)
+	if cachedDigest != "" && cachedDigest != digest {
+		logrus.Warnf("builder: cached digest for file %s is corrupted, correcting the cache [cached: %s, calculated: %s]", path, cachedDigest, digest)
+	}
 
 	// Reset reader for subsequent use.
 	reader, err = resetReader(reader, path, workDirPath, codec)
 	if err != nil {
-		return reader, "", 0, err
+		return reader, "", 0, "", err
 	}
 
 	// Update cache.
 	if mediaType == modelspec.MediaTypeModelWeightRaw {
-		if err := ab.updateCache(ctx, path, info.ModTime(), size, digest); err != nil {
+		if err := ab.updateCache(ctx, path, info.ModTime(), size, digest, fastChecksum); err != nil {
 			logrus.Warnf("builder: failed to update cache for file %s: %s", path, err)
 		}
 	}
 
-	return reader, digest, size, nil
+	return reader, digest, size, fastChecksum, nil
 }
 
-// retrieveCache checks if mtime and size match, then returns the cached digest.
-func (ab *abstractBuilder) retrieveCache(ctx context.Context, path string, info os.FileInfo) (string, int64, bool) {
+// retrieveCache checks if mtime and size match, then returns the cached item.
+func (ab *abstractBuilder) retrieveCache(ctx context.Context, path string, info os.FileInfo) (*cache.Item, bool) {
 	if ab.cache == nil {
-		return "", 0, false
+		return nil, false
 	}
 
 	item, err := ab.cache.Get(ctx, path)
@@ -294,30 +604,38 @@ func (ab *abstractBuilder) retrieveCache(ctx context.Context, path string, info
 			logrus.Errorf("builder: failed to retrieve cache item for file %s: %s", path, err)
 		}
 
-		return "", 0, false
+		return nil, false
 	}
 
-	if item.ModTime != info.ModTime() || item.Size != info.Size() {
+	// The mtime is compared by Equal as the location of the cached one may differ after decoding.
+	if !item.ModTime.Equal(info.ModTime()) || item.Size != info.Size() {
 		logrus.Warnf("builder: cache item for file %s is stale, skip cache", path)
-		return "", 0, false
+		return nil, false
 	}
 
 	logrus.Infof("builder: cache hit for file %s [digest: %s]", path, item.Digest)
-	return item.Digest, item.Size, true
+	return item, true
+}
+
+// shouldVerifyCache returns whether to recompute the digest of the file hitting the cache, which
+// is sampled by the verify cache rate.
+func (ab *abstractBuilder) shouldVerifyCache() bool {
+	return ab.verifyCacheRate >= 1 || (ab.verifyCacheRate > 0 && rand.Float64() < ab.verifyCacheRate)
 }
 
-// updateCache writes mtime, size, and digest to cache.
-func (ab *abstractBuilder) updateCache(ctx context.Context, path string, mtime time.Time, size int64, digest string) error {
+// updateCache writes mtime, size, digest and fast checksum to cache.
+func (ab *abstractBuilder) updateCache(ctx context.Context, path string, mtime time.Time, size int64, digest, fastChecksum string) error {
 	if ab.cache == nil {
 		return errors.New("cache is not initialized")
 	}
 
 	item := &cache.Item{
-		Path:      path,
-		ModTime:   mtime,
-		Size:      size,
-		Digest:    digest,
-		CreatedAt: time.Now(),
+		Path:         path,
+		ModTime:      mtime,
+		Size:         size,
+		Digest:       digest,
+		FastChecksum: fastChecksum,
+		CreatedAt:    time.Now(),
 	}
 
 	return ab.cache.Put(ctx, item)
@@ -387,12 +705,16 @@ func resetReader(reader io.Reader, path, workDirPath string, codec pkgcodec.Code
 }
 
 // addFileMetadata adds file metadata to the descriptor.
-func addFileMetadata(desc *ocispec.Descriptor, path, relPath string) error {
+func addFileMetadata(desc *ocispec.Descriptor, path, relPath string, strip bool) error {
 	metadata, err := getFileMetadata(path)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve file metadata: %w", err)
 	}
 
+	if strip {
+		metadata = stripFileMetadata(metadata)
+	}
+
 	metadataStr, err := json.Marshal(metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
@@ -409,6 +731,16 @@ func addFileMetadata(desc *ocispec.Descriptor, path, relPath string) error {
 	return nil
 }
 
+// stripFileMetadata keeps the name, size and type of the file metadata and zeros the
+// mode, owner and mtime, the extraction falls back to the defaults for the zero values.
+func stripFileMetadata(metadata modelspec.FileMetadata) modelspec.FileMetadata {
+	return modelspec.FileMetadata{
+		Name:     metadata.Name,
+		Size:     metadata.Size,
+		Typeflag: metadata.Typeflag,
+	}
+}
+
 // splitReader splits the original reader into two readers.
 func splitReader(original io.Reader) (io.Reader, io.Reader) {
 	r1, w1 := io.Pipe()
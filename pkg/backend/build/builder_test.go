@@ -17,7 +17,10 @@
 package build
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"os"
@@ -27,6 +30,7 @@ import (
 	"sync"
 	"syscall"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
@@ -34,10 +38,15 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/modelpack/modctl/internal/cache"
+	"github.com/modelpack/modctl/pkg/archiver"
 	buildconfig "github.com/modelpack/modctl/pkg/backend/build/config"
 	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	"github.com/modelpack/modctl/pkg/checksum"
+	pkgcodec "github.com/modelpack/modctl/pkg/codec"
 	buildmock "github.com/modelpack/modctl/test/mocks/backend/build"
 	storagemock "github.com/modelpack/modctl/test/mocks/storage"
 )
@@ -138,13 +147,194 @@ func (s *BuilderTestSuite) TestBuildLayer() {
 		s.Error(err)
 	})
 
-	s.Run("directory not supported", func() {
-		_, err := s.builder.BuildLayer(context.Background(), "test/media-type.tar", s.tempDir, s.tempDir, "", hooks.NewHooks())
+	s.Run("directory in raw format", func() {
+		_, err := s.builder.BuildLayer(context.Background(), "test/media-type.raw", s.tempDir, s.tempDir, "", hooks.NewHooks())
 		s.Error(err)
-		s.True(strings.Contains(err.Error(), "is a directory and not supported yet"))
+		s.True(strings.Contains(err.Error(), "can only be built in tar format"))
 	})
 }
 
+func (s *BuilderTestSuite) TestBuildLayerDirectory() {
+	srcDir := filepath.Join(s.tempDir, "src")
+	files := map[string]string{
+		"main.py":        "print('hello')",
+		"utils/utils.py": "def noop(): pass",
+	}
+	for name, content := range files {
+		s.Require().NoError(os.MkdirAll(filepath.Dir(filepath.Join(srcDir, name)), 0755))
+		s.Require().NoError(os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644))
+	}
+
+	// Capture the content of the directory layer to unpack it.
+	var content []byte
+	s.mockOutputStrategy.On("OutputLayer", mock.Anything, "test/media-type.tar", "src", "", mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			var err error
+			content, err = io.ReadAll(args.Get(6).(io.Reader))
+			s.Require().NoError(err)
+		}).
+		Return(ocispec.Descriptor{MediaType: "test/media-type.tar", Digest: "sha256:test"}, nil)
+
+	desc, err := s.builder.BuildLayer(context.Background(), "test/media-type.tar", s.tempDir, srcDir, "", hooks.NewHooks())
+	s.Require().NoError(err)
+
+	// The metadata annotation reflects the directory.
+	var metadata modelspec.FileMetadata
+	s.Require().NoError(json.Unmarshal([]byte(desc.Annotations[modelspec.AnnotationFileMetadata]), &metadata))
+	s.Equal("src", metadata.Name)
+	s.Equal(byte(5), metadata.Typeflag)
+
+	codec, err := pkgcodec.New(pkgcodec.Tar)
+	s.Require().NoError(err)
+
+	outputDir := s.T().TempDir()
+	s.Require().NoError(codec.Decode(outputDir, "src", bytes.NewReader(content), desc))
+	for name, expected := range files {
+		actual, err := os.ReadFile(filepath.Join(outputDir, "src", name))
+		s.Require().NoError(err)
+		s.Equal(expected, string(actual))
+	}
+}
+
+func (s *BuilderTestSuite) TestBuildLayerStripMetadata() {
+	s.Require().NoError(os.Chmod(s.tempFile, 0600))
+	s.builder.stripMetadata = true
+
+	var content []byte
+	s.mockOutputStrategy.On("OutputLayer", mock.Anything, modelspec.MediaTypeModelWeightRaw, "test-file.txt", "", mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			var err error
+			content, err = io.ReadAll(args.Get(6).(io.Reader))
+			s.Require().NoError(err)
+		}).
+		Return(ocispec.Descriptor{MediaType: modelspec.MediaTypeModelWeightRaw, Digest: "sha256:test", Size: 12}, nil)
+
+	desc, err := s.builder.BuildLayer(context.Background(), modelspec.MediaTypeModelWeightRaw, s.tempDir, s.tempFile, "", hooks.NewHooks())
+	s.Require().NoError(err)
+
+	// Only the name, size and type are kept in the metadata.
+	var metadata modelspec.FileMetadata
+	s.Require().NoError(json.Unmarshal([]byte(desc.Annotations[modelspec.AnnotationFileMetadata]), &metadata))
+	s.Equal(modelspec.FileMetadata{Name: "test-file.txt", Size: 12}, metadata)
+
+	// The extraction falls back to the default mode and the current time.
+	codec, err := pkgcodec.New(pkgcodec.Raw)
+	s.Require().NoError(err)
+
+	outputDir := s.T().TempDir()
+	s.Require().NoError(codec.Decode(outputDir, "test-file.txt", bytes.NewReader(content), desc))
+
+	info, err := os.Stat(filepath.Join(outputDir, "test-file.txt"))
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0644), info.Mode().Perm())
+	s.WithinDuration(time.Now(), info.ModTime(), time.Minute)
+
+	extracted, err := os.ReadFile(filepath.Join(outputDir, "test-file.txt"))
+	s.Require().NoError(err)
+	s.Equal("test content", string(extracted))
+}
+
+func (s *BuilderTestSuite) TestBuildLayerFromArchive() {
+	files := map[string]string{
+		"model.safetensors": strings.Repeat("w", 2048),
+		"docs/README.md":    "# model",
+	}
+
+	// Create the tar archive as the build source.
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"model.safetensors", "docs/README.md"} {
+		s.Require().NoError(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600, Size: int64(len(files[name])), ModTime: time.Unix(1700000000, 0)}))
+		_, err := tw.Write([]byte(files[name]))
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(tw.Close())
+
+	archivePath := filepath.Join(s.tempDir, "model.tar")
+	s.Require().NoError(os.WriteFile(archivePath, buf.Bytes(), 0644))
+	archive, err := archiver.OpenArchive(archivePath)
+	s.Require().NoError(err)
+	defer archive.Close()
+
+	extractDir := filepath.Join(s.tempDir, "extracted")
+	testCases := []struct {
+		name      string
+		entry     string
+		mediaType string
+	}{
+		{name: "raw entry", entry: "model.safetensors", mediaType: modelspec.MediaTypeModelWeightRaw},
+		{name: "tar entry", entry: "docs/README.md", mediaType: modelspec.MediaTypeModelDoc},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			s.mockOutputStrategy.EXPECT().OutputLayer(mock.Anything, tc.mediaType, tc.entry, "", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				RunAndReturn(func(ctx context.Context, mediaType, relPath, destPath, digest string, size int64, reader io.Reader, hooks hooks.Hooks) (ocispec.Descriptor, error) {
+					content, err := io.ReadAll(reader)
+					if err != nil {
+						return ocispec.Descriptor{}, err
+					}
+
+					desc := ocispec.Descriptor{
+						MediaType:   mediaType,
+						Digest:      godigest.Digest(digest),
+						Size:        size,
+						Annotations: map[string]string{modelspec.AnnotationFilepath: relPath},
+					}
+					if godigest.FromBytes(content) != desc.Digest || int64(len(content)) != size {
+						return desc, errors.New("digest or size mismatch")
+					}
+
+					// Extract the layer to compare with the content in the archive.
+					codec, err := pkgcodec.New(pkgcodec.TypeFromMediaType(mediaType))
+					if err != nil {
+						return desc, err
+					}
+
+					return desc, codec.Decode(extractDir, relPath, bytes.NewReader(content), desc)
+				}).Once()
+
+			desc, err := s.builder.BuildLayerFromArchive(context.Background(), tc.mediaType, archive, tc.entry, "", hooks.NewHooks())
+			s.Require().NoError(err)
+			s.Contains(desc.Annotations[modelspec.AnnotationFileMetadata], `"mode":384`)
+
+			extracted, err := os.ReadFile(filepath.Join(extractDir, tc.entry))
+			s.Require().NoError(err)
+			s.Equal(files[tc.entry], string(extracted))
+		})
+	}
+
+	s.Run("entry not found", func() {
+		_, err := s.builder.BuildLayerFromArchive(context.Background(), modelspec.MediaTypeModelWeightRaw, archive, "missing.bin", "", hooks.NewHooks())
+		s.Error(err)
+	})
+}
+
+func (s *BuilderTestSuite) TestBuildLayerExecPatterns() {
+	script := filepath.Join(s.tempDir, "serve.sh")
+	s.Require().NoError(os.WriteFile(script, []byte("#!/bin/sh"), 0644))
+	s.builder.execPatterns = []string{"*.sh"}
+
+	s.mockOutputStrategy.On("OutputLayer", mock.Anything, "test/media-type.tar", mock.Anything, "", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(ocispec.Descriptor{MediaType: "test/media-type.tar"}, nil)
+
+	desc, err := s.builder.BuildLayer(context.Background(), "test/media-type.tar", s.tempDir, script, "", hooks.NewHooks())
+	s.Require().NoError(err)
+	s.Equal("true", desc.Annotations[pkgcodec.AnnotationExecutable])
+
+	desc, err = s.builder.BuildLayer(context.Background(), "test/media-type.tar", s.tempDir, s.tempFile, "", hooks.NewHooks())
+	s.Require().NoError(err)
+	s.NotContains(desc.Annotations, pkgcodec.AnnotationExecutable)
+}
+
+func TestMatchExecPatterns(t *testing.T) {
+	assert.True(t, matchExecPatterns([]string{"*.sh"}, "serve.sh"))
+	assert.True(t, matchExecPatterns([]string{"*.sh"}, "scripts/serve.sh"))
+	assert.True(t, matchExecPatterns([]string{"scripts/*"}, "scripts/run"))
+	assert.False(t, matchExecPatterns([]string{"scripts/*"}, "bin/run"))
+	assert.False(t, matchExecPatterns(nil, "serve.sh"))
+}
+
 func (s *BuilderTestSuite) TestBuildConfig() {
 	s.Run("successful build config", func() {
 		expectedDesc := ocispec.Descriptor{
@@ -199,6 +389,45 @@ func (s *BuilderTestSuite) TestBuildConfig() {
 	})
 }
 
+func (s *BuilderTestSuite) TestBuildCanonicalJSON() {
+	layers := []ocispec.Descriptor{{
+		MediaType:   modelspec.MediaTypeModelWeightRaw,
+		Digest:      godigest.FromString("weights"),
+		Size:        7,
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "model.safetensors", "org.example.a": "<a&b>"},
+	}}
+	config := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromString("config"), Size: 6}
+
+	buildManifest := func(builder *abstractBuilder) []byte {
+		var manifestJSON []byte
+		s.mockOutputStrategy.On("OutputManifest", mock.Anything, ocispec.MediaTypeImageManifest, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				data, err := io.ReadAll(args.Get(4).(io.Reader))
+				s.Require().NoError(err)
+				s.Equal(godigest.FromBytes(data).String(), args.String(2))
+				manifestJSON = data
+			}).
+			Return(ocispec.Descriptor{}, nil).Once()
+
+		_, err := builder.BuildManifest(context.Background(), layers, config, map[string]string{"org.example.b": "1", "org.example.a": "2"}, hooks.NewHooks())
+		s.Require().NoError(err)
+		return manifestJSON
+	}
+
+	s.builder.canonicalJSON = true
+	canonical := buildManifest(s.builder)
+	s.Equal(canonical, buildManifest(s.builder))
+	s.Contains(string(canonical), `"annotations":{"org.example.a":"2","org.example.b":"1"},"artifactType"`)
+	s.Contains(string(canonical), `"<a&b>"`)
+
+	s.builder.jsonIndent = 2
+	s.Contains(string(buildManifest(s.builder)), "\n  \"annotations\": {\n    \"org.example.a\": \"2\",")
+
+	s.builder.canonicalJSON = false
+	indented := buildManifest(s.builder)
+	s.Contains(string(indented), "\n  \"schemaVersion\": 2,\n  \"mediaType\"")
+}
+
 func (s *BuilderTestSuite) TestBuildManifest() {
 	s.Run("successful build manifest", func() {
 		layers := []ocispec.Descriptor{
@@ -278,6 +507,87 @@ func (s *BuilderTestSuite) TestBuildModelConfig() {
 	s.Equal("sha256:layer-2", model.ModelFS.DiffIDs[1].String())
 }
 
+func TestComputeDigestAndSizeVerifyCache(t *testing.T) {
+	ctx := context.Background()
+	workDir := t.TempDir()
+	path := filepath.Join(workDir, "model.safetensors")
+	content := []byte("weight")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	codec, err := pkgcodec.New(pkgcodec.Raw)
+	require.NoError(t, err)
+
+	// plant the wrong digest in the cache with the matching mtime and size.
+	wrongDigest := godigest.FromString("stale").String()
+	digestCache, err := cache.New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, digestCache.Put(ctx, &cache.Item{Path: path, ModTime: info.ModTime(), Size: info.Size(), Digest: wrongDigest, CreatedAt: time.Now()}))
+
+	compute := func(rate float64) string {
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		ab := &abstractBuilder{cache: digestCache, verifyCacheRate: rate}
+		_, digest, size, _, err := ab.computeDigestAndSize(ctx, modelspec.MediaTypeModelWeightRaw, path, workDir, info, file, codec)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), size)
+		return digest
+	}
+
+	// the cache is trusted without the verification.
+	assert.Equal(t, wrongDigest, compute(0))
+
+	// the verification recomputes the digest and corrects the cache.
+	assert.Equal(t, godigest.FromBytes(content).String(), compute(1))
+	item, err := digestCache.Get(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, godigest.FromBytes(content).String(), item.Digest)
+	assert.Equal(t, godigest.FromBytes(content).String(), compute(0))
+}
+
+func TestComputeDigestAndSizeCacheFastChecksum(t *testing.T) {
+	ctx := context.Background()
+	workDir := t.TempDir()
+	path := filepath.Join(workDir, "model.safetensors")
+	content := []byte("weight")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	codec, err := pkgcodec.New(pkgcodec.Raw)
+	require.NoError(t, err)
+
+	digestCache, err := cache.New(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, digestCache.Put(ctx, &cache.Item{Path: path, ModTime: info.ModTime(), Size: info.Size(), Digest: godigest.FromBytes(content).String(), CreatedAt: time.Now()}))
+
+	ab := &abstractBuilder{cache: digestCache, fastChecksum: true}
+	fastChecksum, err := checksum.Compute(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	// the cache item without the fast checksum is recomputed and filled.
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	_, digest, _, got, err := ab.computeDigestAndSize(ctx, modelspec.MediaTypeModelWeightRaw, path, workDir, info, file, codec)
+	require.NoError(t, err)
+	assert.Equal(t, godigest.FromBytes(content).String(), digest)
+	assert.Equal(t, fastChecksum, got)
+	item, err := digestCache.Get(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, fastChecksum, item.FastChecksum)
+
+	// the cache hit returns the cached digest and fast checksum without reading the file.
+	_, digest, size, got, err := ab.computeDigestAndSize(ctx, modelspec.MediaTypeModelWeightRaw, path, workDir, info, iotest.ErrReader(errors.New("read")), codec)
+	require.NoError(t, err)
+	assert.Equal(t, godigest.FromBytes(content).String(), digest)
+	assert.Equal(t, int64(len(content)), size)
+	assert.Equal(t, fastChecksum, got)
+}
+
 func TestBuilderSuite(t *testing.T) {
 	suite.Run(t, new(BuilderTestSuite))
 }
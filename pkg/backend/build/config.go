@@ -27,6 +27,20 @@ type config struct {
 	plainHTTP   bool
 	insecure    bool
 	interceptor interceptor.Interceptor
+	// fastChecksum indicates whether to annotate the layers with the fast checksum.
+	fastChecksum bool
+	// verifyCacheRate is the fraction of the digest cache hits to recompute and compare.
+	verifyCacheRate float64
+	// execPatterns is the patterns of the files to be marked as executable.
+	execPatterns []string
+	// encryptionKey is the key to encrypt the layers, the layers are not encrypted if it is empty.
+	encryptionKey []byte
+	// stripMetadata indicates whether to omit the mode, owner and mtime from the file metadata.
+	stripMetadata bool
+	// canonicalJSON indicates whether to marshal the config and manifest in the canonical JSON.
+	canonicalJSON bool
+	// jsonIndent is the number of spaces to indent the config and manifest JSON, zero is compact.
+	jsonIndent int
 }
 
 func WithPlainHTTP(plainHTTP bool) Option {
@@ -46,3 +60,59 @@ func WithInterceptor(interceptor interceptor.Interceptor) Option {
 		c.interceptor = interceptor
 	}
 }
+
+// WithFastChecksum annotates the layers with the fast non-cryptographic checksum,
+// which is used to detect the corruption of the local storage quickly.
+func WithFastChecksum(fastChecksum bool) Option {
+	return func(c *config) {
+		c.fastChecksum = fastChecksum
+	}
+}
+
+// WithVerifyCache recomputes the digests of the fraction of the files hitting the digest cache
+// and compares them with the cached ones, the corrupted cache items are corrected. The cache is
+// keyed by the mtime and size, so the files edited without changing them hit the stale digests.
+func WithVerifyCache(rate float64) Option {
+	return func(c *config) {
+		c.verifyCacheRate = rate
+	}
+}
+
+// WithExecPatterns marks the layers of the files matching the patterns as executable,
+// which sets the exec bit on extraction regardless of the source permissions.
+func WithExecPatterns(patterns []string) Option {
+	return func(c *config) {
+		c.execPatterns = patterns
+	}
+}
+
+// WithEncryptionKey encrypts the layers by the envelope encryption with the key,
+// the digest of the layer is computed over the ciphertext.
+func WithEncryptionKey(key []byte) Option {
+	return func(c *config) {
+		c.encryptionKey = key
+	}
+}
+
+// WithStripMetadata omits the mode, owner and mtime from the file metadata annotations of
+// the layers, only the name, size and type are kept, which are enough for the extraction.
+func WithStripMetadata(stripMetadata bool) Option {
+	return func(c *config) {
+		c.stripMetadata = stripMetadata
+	}
+}
+
+// WithCanonicalJSON marshals the config and manifest in the canonical JSON with the keys sorted,
+// so the digests are deterministic regardless of the field order of the types.
+func WithCanonicalJSON(canonicalJSON bool) Option {
+	return func(c *config) {
+		c.canonicalJSON = canonicalJSON
+	}
+}
+
+// WithJSONIndent indents the config and manifest JSON by the number of spaces.
+func WithJSONIndent(indent int) Option {
+	return func(c *config) {
+		c.jsonIndent = indent
+	}
+}
@@ -99,7 +99,7 @@ func (lo *localOutput) OutputManifest(ctx context.Context, mediaType, digest str
 		return ocispec.Descriptor{}, fmt.Errorf("failed to read manifest JSON: %w", err)
 	}
 
-	digest, err = lo.store.PushManifest(ctx, lo.repo, lo.tag, manifestJSON)
+	digest, err = lo.store.PushManifest(ctx, lo.repo, lo.tag, mediaType, manifestJSON)
 	if err != nil {
 		hooks.OnError(digest, err)
 		return ocispec.Descriptor{}, fmt.Errorf("failed to push manifest to storage: %w", err)
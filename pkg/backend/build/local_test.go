@@ -133,7 +133,7 @@ func (s *LocalOutputTestSuite) TestOutputManifest() {
 		manifestJSON := []byte(`{"manifest": "test"}`)
 		expectedDigest := "sha256:manifest5678"
 
-		s.mockStorage.On("PushManifest", s.ctx, "test-repo", "test-tag", manifestJSON).
+		s.mockStorage.On("PushManifest", s.ctx, "test-repo", "test-tag", "test/manifesttype", manifestJSON).
 			Return(expectedDigest, nil).Once()
 
 		desc, err := s.localOutput.OutputManifest(s.ctx, "test/manifesttype", expectedDigest, int64(len(manifestJSON)), bytes.NewReader(manifestJSON), hooks.NewHooks())
@@ -148,7 +148,7 @@ func (s *LocalOutputTestSuite) TestOutputManifest() {
 	s.Run("storage error", func() {
 		manifestJSON := []byte(`{"manifest": "test"}`)
 
-		s.mockStorage.On("PushManifest", s.ctx, "test-repo", "test-tag", manifestJSON).
+		s.mockStorage.On("PushManifest", s.ctx, "test-repo", "test-tag", "test/manifesttype", manifestJSON).
 			Return("", errors.New("manifest error")).Once()
 
 		_, err := s.localOutput.OutputManifest(s.ctx, "test/manifesttype", "", int64(0), bytes.NewReader(manifestJSON), hooks.NewHooks())
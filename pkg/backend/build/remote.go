@@ -112,6 +112,7 @@ func (ro *remoteOutput) OutputConfig(ctx context.Context, mediaType, digest stri
 	}
 
 	if err = ro.remote.Blobs().Push(ctx, desc, reader); err != nil {
+		err = remote.WrapUnsupportedMediaType(err)
 		hooks.OnError(digest, err)
 		return ocispec.Descriptor{}, fmt.Errorf("failed to push config to storage: %w", err)
 	}
@@ -141,6 +142,7 @@ func (ro *remoteOutput) OutputManifest(ctx context.Context, mediaType, digest st
 	}
 
 	if err = ro.remote.Manifests().Push(ctx, desc, reader); err != nil {
+		err = remote.WrapUnsupportedMediaType(err)
 		hooks.OnError(digest, err)
 		return ocispec.Descriptor{}, fmt.Errorf("failed to push manifest to storage: %w", err)
 	}
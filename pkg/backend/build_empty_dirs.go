@@ -0,0 +1,151 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/build"
+	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	"github.com/modelpack/modctl/pkg/modelfile"
+)
+
+// findEmptyDirs returns the sorted relative paths of the empty directories in the work
+// directory, the paths left out of the workspace scan such as the hidden directories and the
+// ones in the .modctlignore are skipped with their contents, and the directories containing
+// only such paths, such as the .gitkeep, are regarded as empty.
+func findEmptyDirs(workDir string) ([]string, error) {
+	ignored, err := modelfile.NewWorkspaceIgnore(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []string{}
+	err = filepath.WalkDir(workDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() || path == workDir {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(workDir, path)
+		if err != nil {
+			return err
+		}
+
+		if ignored(relPath, true) {
+			return filepath.SkipDir
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+
+		empty := true
+		for _, entry := range entries {
+			if !ignored(filepath.Join(relPath, entry.Name()), entry.IsDir()) {
+				empty = false
+				break
+			}
+		}
+
+		if empty {
+			dirs = append(dirs, relPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find empty directories: %w", err)
+	}
+
+	return dirs, nil
+}
+
+// buildEmptyDirs builds the empty directories in the work directory as the code layers in tar
+// format, each of which contains the directory entry only, so they are recreated on extraction.
+// The directories are built from the copies in a shadow work directory, which leaves out the
+// ignored contents such as the .gitkeep.
+func (b *backend) buildEmptyDirs(ctx context.Context, builder build.Builder, workDir string) ([]ocispec.Descriptor, error) {
+	dirs, err := findEmptyDirs(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+
+	shadowDir, err := os.MkdirTemp("", "modctl-empty-dirs-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shadow directory: %w", err)
+	}
+	defer os.RemoveAll(shadowDir)
+
+	descs := make([]ocispec.Descriptor, 0, len(dirs))
+	for _, dir := range dirs {
+		shadowPath, err := shadowEmptyDir(workDir, shadowDir, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		desc, err := builder.BuildLayer(ctx, modelspec.MediaTypeModelCode, shadowDir, shadowPath, "", hooks.NewHooks())
+		if err != nil {
+			return nil, fmt.Errorf("failed to build empty directory %s: %w", dir, err)
+		}
+
+		logrus.Infof("build: built empty directory %s [digest: %s]", dir, desc.Digest)
+		descs = append(descs, desc)
+	}
+
+	return descs, nil
+}
+
+// shadowEmptyDir creates the empty directory of the relative path in the shadow directory with
+// the mode and the modification time of the one in the work directory.
+func shadowEmptyDir(workDir, shadowDir, dir string) (string, error) {
+	info, err := os.Stat(filepath.Join(workDir, dir))
+	if err != nil {
+		return "", fmt.Errorf("failed to stat empty directory %s: %w", dir, err)
+	}
+
+	shadowPath := filepath.Join(shadowDir, dir)
+	if err := os.MkdirAll(shadowPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create shadow directory %s: %w", dir, err)
+	}
+
+	if err := os.Chmod(shadowPath, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to set mode of shadow directory %s: %w", dir, err)
+	}
+
+	if err := os.Chtimes(shadowPath, info.ModTime(), info.ModTime()); err != nil {
+		return "", fmt.Errorf("failed to set modification time of shadow directory %s: %w", dir, err)
+	}
+
+	return shadowPath, nil
+}
@@ -0,0 +1,116 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// newEmptyDirsWorkDir returns the work directory with the empty directories required by the loaders.
+func newEmptyDirsWorkDir(t *testing.T) string {
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "Modelfile"), []byte("NAME test\nMODEL *.safetensors\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "model.safetensors"), []byte("weights"), 0644))
+	for _, dir := range []string{"cache", "keep", "outputs/checkpoints", "outputs/logs", ".cache/hub"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(workDir, dir), 0755))
+	}
+
+	// the directory containing only the hidden files is regarded as empty, and the ignored one is skipped.
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "keep", ".gitkeep"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".modctlignore"), []byte("outputs/logs/\n"), 0644))
+
+	return workDir
+}
+
+func TestFindEmptyDirs(t *testing.T) {
+	dirs, err := findEmptyDirs(newEmptyDirsWorkDir(t))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cache", "keep", "outputs/checkpoints"}, dirs)
+}
+
+func TestBuildEmptyDirs(t *testing.T) {
+	workDir := newEmptyDirsWorkDir(t)
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	build := func(tag string, keepEmptyDirs bool) ocispec.Manifest {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		cfg.KeepEmptyDirs = keepEmptyDirs
+		require.NoError(t, b.Build(ctx, filepath.Join(workDir, "Modelfile"), workDir, "example.com/repo:"+tag, cfg))
+
+		manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", tag)
+		require.NoError(t, err)
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+		return manifest
+	}
+
+	// the empty directories are not captured by default.
+	manifest := build("v1", false)
+	require.Len(t, manifest.Layers, 1)
+
+	manifest = build("v2", true)
+	filepaths := []string{}
+	for _, layer := range manifest.Layers {
+		filepaths = append(filepaths, layerFilepath(layer))
+	}
+	assert.Equal(t, []string{"model.safetensors", "cache", "keep", "outputs/checkpoints"}, filepaths)
+
+	// the directory layers are in tar format with the directory metadata.
+	var metadata modelspec.FileMetadata
+	require.NoError(t, json.Unmarshal([]byte(manifest.Layers[1].Annotations[modelspec.AnnotationFileMetadata]), &metadata))
+	assert.Equal(t, modelspec.MediaTypeModelCode, manifest.Layers[1].MediaType)
+	assert.Equal(t, byte(5), metadata.Typeflag)
+
+	outputDir := t.TempDir()
+	require.NoError(t, b.Extract(ctx, "example.com/repo:v2", &config.Extract{Concurrency: 2, Output: outputDir}))
+	for _, dir := range []string{"cache", "keep", "outputs/checkpoints"} {
+		info, err := os.Stat(filepath.Join(outputDir, dir))
+		require.NoError(t, err, dir)
+		assert.True(t, info.IsDir(), dir)
+
+		entries, err := os.ReadDir(filepath.Join(outputDir, dir))
+		require.NoError(t, err)
+		assert.Empty(t, entries, dir)
+	}
+
+	for _, dir := range []string{".cache", "outputs/logs"} {
+		_, err := os.Stat(filepath.Join(outputDir, dir))
+		assert.True(t, os.IsNotExist(err), dir)
+	}
+
+	t.Run("archive", func(t *testing.T) {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		cfg.KeepEmptyDirs = true
+		err := b.BuildFromFiles(ctx, filepath.Join(workDir, "Modelfile"), map[string][]byte{"model.safetensors": []byte("weights")}, "example.com/repo:archive", cfg)
+		assert.ErrorContains(t, err, "not supported when building from the archive")
+	})
+}
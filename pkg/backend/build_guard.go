@@ -0,0 +1,96 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+// checkTargetOverwrite returns the error if the target tag already exists in the output of the
+// build, which prevents clobbering the published tag accidentally. The check is skipped unless
+// the no-overwrite guard is turned on, and the force flag overwrites the tag anyway.
+func (b *backend) checkTargetOverwrite(ctx context.Context, repo, tag string, cfg *config.Build) error {
+	if !cfg.NoOverwrite {
+		return nil
+	}
+
+	var (
+		exist bool
+		err   error
+	)
+	if cfg.OutputRemote {
+		exist, err = remoteTagExists(ctx, repo, tag, cfg)
+	} else {
+		exist, err = b.localTagExists(ctx, repo, tag)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check the target %s:%s: %w", repo, tag, err)
+	}
+
+	if !exist {
+		return nil
+	}
+
+	if cfg.Force {
+		logrus.Warnf("build: overwriting the existing target %s:%s by force", repo, tag)
+		return nil
+	}
+
+	return fmt.Errorf("target %s:%s already exists, use --force to overwrite it", repo, tag)
+}
+
+// localTagExists returns whether the tag exists in the local storage.
+func (b *backend) localTagExists(ctx context.Context, repo, tag string) (bool, error) {
+	_, digest, err := b.store.PullManifest(ctx, repo, tag)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			logrus.Debugf("build: target %s:%s is not found in the local storage: %v", repo, tag, err)
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to pull manifest: %w", err)
+	}
+
+	return b.store.StatManifest(ctx, repo, digest)
+}
+
+// remoteTagExists returns whether the tag exists in the remote registry.
+func remoteTagExists(ctx context.Context, repo, tag string, cfg *config.Build) (bool, error) {
+	client, err := remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure))
+	if err != nil {
+		return false, fmt.Errorf("failed to create remote client: %w", err)
+	}
+
+	if _, err := client.Resolve(ctx, tag); err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
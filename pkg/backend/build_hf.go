@@ -0,0 +1,63 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+	"github.com/modelpack/modctl/pkg/modelfile"
+	"github.com/modelpack/modctl/pkg/modelprovider/huggingface"
+)
+
+// BuildFromHF downloads the snapshot of the HuggingFace model, generates the Modelfile by the
+// downloaded files and builds them into the model artifact, the snapshot is removed after the build.
+func (b *backend) BuildFromHF(ctx context.Context, modelURL, target string, cfg *config.Build) error {
+	downloadDir, err := os.MkdirTemp("", "modctl-hf-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+	defer os.RemoveAll(downloadDir)
+
+	logrus.Infof("build: downloading snapshot of %s from huggingface", modelURL)
+	workDir, err := huggingface.New().DownloadSnapshot(ctx, modelURL, downloadDir)
+	if err != nil {
+		return fmt.Errorf("failed to download model from huggingface: %w", err)
+	}
+
+	generateConfig := configmodelfile.NewGenerateConfig()
+	generateConfig.Workspace = workDir
+	mf, err := modelfile.NewModelfileByWorkspace(workDir, generateConfig)
+	if err != nil {
+		return fmt.Errorf("failed to generate modelfile: %w", err)
+	}
+
+	// write the Modelfile beside the snapshot, which keeps it out of the files of the model.
+	modelfilePath := filepath.Join(downloadDir, configmodelfile.DefaultModelfileName)
+	if err := os.WriteFile(modelfilePath, mf.Content(), 0644); err != nil {
+		return fmt.Errorf("failed to write modelfile: %w", err)
+	}
+
+	logrus.Infof("build: generated modelfile %s for %s", modelfilePath, modelURL)
+	return b.Build(ctx, modelfilePath, workDir, target, cfg)
+}
@@ -0,0 +1,114 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// newHFServer returns the mock HuggingFace Hub serving the files of owner/repo.
+func newHFServer(t *testing.T, files map[string]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/owner/repo/revision/main", func(w http.ResponseWriter, r *http.Request) {
+		var siblings []string
+		for name := range files {
+			siblings = append(siblings, `{"rfilename":"`+name+`"}`)
+		}
+		io.WriteString(w, `{"siblings":[`+strings.Join(siblings, ",")+`]}`)
+	})
+	mux.HandleFunc("/owner/repo/resolve/main/", func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/owner/repo/resolve/main/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		io.WriteString(w, content)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBuildFromHF(t *testing.T) {
+	server := newHFServer(t, map[string]string{
+		"config.json":       `{"model_type": "llama"}`,
+		"model.safetensors": "weights",
+		"README.md":         "# repo",
+	})
+	t.Setenv("HF_ENDPOINT", server.URL)
+	t.Setenv("HF_TOKEN", "")
+	t.Setenv("HF_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewBuild()
+	cfg.Raw = true
+	require.NoError(t, b.BuildFromHF(ctx, "owner/repo", "example.com/models/repo:v1", cfg))
+
+	manifestRaw, _, err := store.PullManifest(ctx, "example.com/models/repo", "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+	filepaths := []string{}
+	for _, layer := range manifest.Layers {
+		filepaths = append(filepaths, layer.Annotations[modelspec.AnnotationFilepath])
+	}
+	sort.Strings(filepaths)
+	assert.Equal(t, []string{"README.md", "config.json", "model.safetensors"}, filepaths)
+	assert.Contains(t, manifest.Annotations[annotationModelfile], "NAME repo")
+
+	var model modelspec.Model
+	require.NoError(t, json.Unmarshal(blobs["example.com/models/repo"][manifest.Config.Digest.String()], &model))
+	assert.Equal(t, "repo", model.Descriptor.Name)
+	assert.Equal(t, "llama", model.Descriptor.Family)
+
+	// the download is removed after the build.
+	snapshots, err := filepath.Glob(filepath.Join(tmpDir, "modctl-hf-snapshot-*"))
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	t.Run("download failure", func(t *testing.T) {
+		err := b.BuildFromHF(ctx, "owner/missing", "example.com/models/repo:v2", cfg)
+		assert.ErrorContains(t, err, "failed to download model from huggingface")
+
+		snapshots, err := filepath.Glob(filepath.Join(tmpDir, "modctl-hf-snapshot-*"))
+		require.NoError(t, err)
+		assert.Empty(t, snapshots)
+	})
+}
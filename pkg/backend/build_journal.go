@@ -0,0 +1,316 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/build"
+	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+const (
+	// buildJournalDir is the directory of the build journals in the storage directory.
+	buildJournalDir = "build-journals.v1"
+
+	// buildJournalMaxAge is the age after which the journal of the build never resumed is removed.
+	buildJournalMaxAge = 7 * 24 * time.Hour
+)
+
+// journalEntry is the entry of the build journal recording the layer built from the file.
+type journalEntry struct {
+	// Path is the path of the file.
+	Path string `json:"path"`
+	// MediaType is the media type requested to build the layer.
+	MediaType string `json:"mediaType"`
+	// DestPath is the destination path of the file in the layer, which is empty by default.
+	DestPath string `json:"destPath,omitempty"`
+	// Digest is the digest of the file content when the layer was built.
+	Digest godigest.Digest `json:"digest"`
+	// Size is the size of the file when the layer was built.
+	Size int64 `json:"size"`
+	// Descriptor is the descriptor of the built layer.
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+}
+
+// journalKey returns the key of the layer built from the file in the build journal.
+func journalKey(mediaType, path, destPath string) string {
+	return mediaType + "\x00" + path + "\x00" + destPath
+}
+
+// buildJournal records the layers to the disk as they are built, so the interrupted build can be
+// resumed by skipping the layers whose files are unchanged and blobs exist in the storage.
+type buildJournal struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	entries map[string]journalEntry
+}
+
+// journalSettings is the settings of the build changing the built layers, which are part of
+// the key of the build journal, so the layers built by other settings are not reused.
+type journalSettings struct {
+	Raw                bool     `json:"raw"`
+	Compress           bool     `json:"compress"`
+	CompressPatterns   []string `json:"compressPatterns"`
+	NoCompressPatterns []string `json:"noCompressPatterns"`
+	ExecPatterns       []string `json:"execPatterns"`
+	StripMetadata      bool     `json:"stripMetadata"`
+	FastChecksum       bool     `json:"fastChecksum"`
+	EncryptionKey      string   `json:"encryptionKey"`
+}
+
+// buildJournalPath returns the path of the build journal in the storage directory, which is
+// keyed by the target, the raw content of the Modelfile and the settings changing the built
+// layers. The name is prefixed by the hash of the target to find the stale journals of it.
+func buildJournalPath(storageDir, target string, modelfile []byte, cfg *config.Build) string {
+	settings, _ := json.Marshal(journalSettings{
+		Raw:                cfg.Raw,
+		Compress:           cfg.Compress,
+		CompressPatterns:   cfg.CompressPatterns,
+		NoCompressPatterns: cfg.NoCompressPatterns,
+		ExecPatterns:       cfg.ExecPatterns,
+		StripMetadata:      cfg.StripMetadata,
+		FastChecksum:       cfg.FastChecksum,
+		EncryptionKey:      cfg.EncryptionKey,
+	})
+
+	targetSum := checksum.Sum256([]byte(target))
+	sum := checksum.Sum256(bytes.Join([][]byte{[]byte(target), modelfile, settings}, []byte{0}))
+	return filepath.Join(storageDir, buildJournalDir, hex.EncodeToString(targetSum[:8])+"-"+hex.EncodeToString(sum[:])+".jsonl")
+}
+
+// removeStaleBuildJournals removes the other journals of the same target, which are left by
+// the builds of the changed Modelfile or settings, and the journals never resumed for long.
+func removeStaleBuildJournals(path string) {
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	targetPrefix, _, _ := strings.Cut(filepath.Base(path), "-")
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == filepath.Base(path) || !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+
+		stale := strings.HasPrefix(name, targetPrefix+"-")
+		if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > buildJournalMaxAge {
+			stale = true
+		}
+
+		if stale {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+				logrus.Warnf("build: failed to remove stale build journal %s: %s", name, err)
+				continue
+			}
+
+			logrus.Debugf("build: removed stale build journal %s", name)
+		}
+	}
+}
+
+// openBuildJournal opens the build journal of the path and loads the entries recorded by the
+// previous build, the corrupted entries such as the one truncated by a crash are skipped.
+func openBuildJournal(path string) (*buildJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create build journal directory: %w", err)
+	}
+	removeStaleBuildJournals(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read build journal: %w", err)
+	}
+
+	entries := map[string]journalEntry{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			logrus.Warnf("build: skipping corrupted entry of build journal %s: %s", path, err)
+			continue
+		}
+
+		entries[journalKey(entry.MediaType, entry.Path, entry.DestPath)] = entry
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open build journal: %w", err)
+	}
+
+	// terminate the entry truncated by a crash, so it is not concatenated with the next one.
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := file.Write([]byte("\n")); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write build journal: %w", err)
+		}
+	}
+
+	logrus.Infof("build: opened build journal %s [entries: %d]", path, len(entries))
+	return &buildJournal{path: path, file: file, entries: entries}, nil
+}
+
+// lookup returns the descriptor of the layer recorded for the file if the digest of the file
+// is unchanged since then and the blob of the layer exists in the repository of the storage.
+func (j *buildJournal) lookup(ctx context.Context, store storage.Storage, repo, mediaType, path, destPath string, digest godigest.Digest, size int64) (ocispec.Descriptor, bool) {
+	j.mu.Lock()
+	entry, ok := j.entries[journalKey(mediaType, path, destPath)]
+	j.mu.Unlock()
+	if !ok {
+		return ocispec.Descriptor{}, false
+	}
+
+	if entry.Size != size || entry.Digest != digest {
+		logrus.Infof("build: file %s changed since recorded in build journal, rebuilding", path)
+		return ocispec.Descriptor{}, false
+	}
+
+	exist, err := store.StatBlob(ctx, repo, entry.Descriptor.Digest.String())
+	if err != nil || !exist {
+		logrus.Infof("build: blob %s of file %s recorded in build journal is missing, rebuilding", entry.Descriptor.Digest, path)
+		return ocispec.Descriptor{}, false
+	}
+
+	return entry.Descriptor, true
+}
+
+// record appends the entry of the layer built from the file to the journal and syncs it to the
+// disk, so it survives a crash.
+func (j *buildJournal) record(mediaType, path, destPath string, digest godigest.Digest, size int64, desc ocispec.Descriptor) error {
+	entry := journalEntry{
+		Path:       path,
+		MediaType:  mediaType,
+		DestPath:   destPath,
+		Digest:     digest,
+		Size:       size,
+		Descriptor: desc,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal build journal entry: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write build journal: %w", err)
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync build journal: %w", err)
+	}
+
+	j.entries[journalKey(mediaType, path, destPath)] = entry
+	return nil
+}
+
+// Close closes the build journal, which is kept to resume the build.
+func (j *buildJournal) Close() error {
+	return j.file.Close()
+}
+
+// Remove closes and removes the build journal once the build succeeds.
+func (j *buildJournal) Remove() error {
+	j.file.Close()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove build journal: %w", err)
+	}
+
+	return nil
+}
+
+// journalBuilder is the builder skipping the layers recorded in the build journal and recording
+// the newly built ones. The directories and the entries of the archives are always built, as
+// their contents are not recorded by the digest of a single file.
+type journalBuilder struct {
+	build.Builder
+	journal *buildJournal
+	store   storage.Storage
+	repo    string
+}
+
+// BuildLayer builds the layer of the file unless it is recorded in the build journal.
+func (jb *journalBuilder) BuildLayer(ctx context.Context, mediaType, workDir, path, destPath string, hooks hooks.Hooks) (ocispec.Descriptor, error) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return jb.Builder.BuildLayer(ctx, mediaType, workDir, path, destPath, hooks)
+	}
+
+	// digest the file ahead of the build, so the file changed during the build is rebuilt on resume.
+	digest, size, err := fileDigest(path)
+	if err != nil {
+		logrus.Warnf("build: failed to digest file %s for build journal: %s", path, err)
+		return jb.Builder.BuildLayer(ctx, mediaType, workDir, path, destPath, hooks)
+	}
+
+	if desc, ok := jb.journal.lookup(ctx, jb.store, jb.repo, mediaType, path, destPath, digest, size); ok {
+		logrus.Infof("build: skipping file %s built by interrupted build [digest: %s]", path, desc.Digest)
+		return desc, nil
+	}
+
+	desc, err := jb.Builder.BuildLayer(ctx, mediaType, workDir, path, destPath, hooks)
+	if err != nil {
+		return desc, err
+	}
+
+	// the journal is best effort, the build goes on without it.
+	if err := jb.journal.record(mediaType, path, destPath, digest, size, desc); err != nil {
+		logrus.Warnf("build: failed to record file %s in build journal: %s", path, err)
+	}
+
+	return desc, nil
+}
+
+// fileDigest returns the sha256 digest and the size of the file content.
+func fileDigest(path string) (godigest.Digest, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	digest, size, err := checksum.SHA256(file)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return godigest.Digest(digest), size, nil
+}
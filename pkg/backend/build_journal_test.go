@@ -0,0 +1,203 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	"github.com/modelpack/modctl/pkg/config"
+	buildmock "github.com/modelpack/modctl/test/mocks/backend/build"
+)
+
+// journalFiles writes the files into the directory and returns their layers keyed by the paths.
+func journalFiles(t *testing.T, dir string, names ...string) map[string]ocispec.Descriptor {
+	layers := map[string]ocispec.Descriptor{}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		content := []byte("content of " + name)
+		require.NoError(t, os.WriteFile(path, content, 0644))
+		layers[path] = ocispec.Descriptor{
+			MediaType:   modelspec.MediaTypeModelWeightRaw,
+			Digest:      godigest.FromBytes(content),
+			Size:        int64(len(content)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		}
+	}
+
+	return layers
+}
+
+func TestBuildJournalResume(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := "example.com/models/llama"
+	store, blobs := newMemoryStore()
+	layers := journalFiles(t, dir, "a.bin", "b.bin", "c.bin")
+	pathA, pathB, pathC := filepath.Join(dir, "a.bin"), filepath.Join(dir, "b.bin"), filepath.Join(dir, "c.bin")
+	journalPath := buildJournalPath(filepath.Join(dir, "storage"), repo+":v1", []byte("MODEL *.bin"), config.NewBuild())
+
+	// the first build crashes after building a.bin and b.bin.
+	journal, err := openBuildJournal(journalPath)
+	require.NoError(t, err)
+	builder := &buildmock.Builder{}
+	for _, path := range []string{pathA, pathB} {
+		builder.On("BuildLayer", mock.Anything, modelspec.MediaTypeModelWeightRaw, dir, path, "", mock.Anything).Return(layers[path], nil).Once()
+	}
+	builder.On("BuildLayer", mock.Anything, modelspec.MediaTypeModelWeightRaw, dir, pathC, "", mock.Anything).Return(ocispec.Descriptor{}, errors.New("crashed")).Once()
+
+	blobs[repo] = map[string][]byte{}
+	jb := &journalBuilder{Builder: builder, journal: journal, store: store, repo: repo}
+	for _, path := range []string{pathA, pathB} {
+		desc, err := jb.BuildLayer(ctx, modelspec.MediaTypeModelWeightRaw, dir, path, "", hooks.NewHooks())
+		require.NoError(t, err)
+		blobs[repo][desc.Digest.String()] = []byte("blob")
+	}
+
+	_, err = jb.BuildLayer(ctx, modelspec.MediaTypeModelWeightRaw, dir, pathC, "", hooks.NewHooks())
+	require.Error(t, err)
+	require.NoError(t, journal.Close())
+
+	// simulate the entry truncated by the crash.
+	file, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"path":"` + pathC)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// the resumed build skips a.bin and b.bin.
+	journal, err = openBuildJournal(journalPath)
+	require.NoError(t, err)
+	builder = &buildmock.Builder{}
+	builder.On("BuildLayer", mock.Anything, modelspec.MediaTypeModelWeightRaw, dir, pathC, "", mock.Anything).Return(layers[pathC], nil).Once()
+
+	jb = &journalBuilder{Builder: builder, journal: journal, store: store, repo: repo}
+	for _, path := range []string{pathA, pathB, pathC} {
+		desc, err := jb.BuildLayer(ctx, modelspec.MediaTypeModelWeightRaw, dir, path, "", hooks.NewHooks())
+		require.NoError(t, err)
+		assert.Equal(t, layers[path], desc)
+	}
+
+	builder.AssertNumberOfCalls(t, "BuildLayer", 1)
+	builder.AssertExpectations(t)
+
+	// the entry appended after the truncated one is loaded.
+	require.NoError(t, journal.Close())
+	journal, err = openBuildJournal(journalPath)
+	require.NoError(t, err)
+	assert.Len(t, journal.entries, 3)
+
+	// the journal is removed once the build succeeds.
+	require.NoError(t, journal.Remove())
+	assert.NoFileExists(t, journalPath)
+}
+
+func TestBuildJournalRebuild(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := "example.com/models/llama"
+	store, blobs := newMemoryStore()
+	layers := journalFiles(t, dir, "changed.bin", "missing.bin", "other.bin")
+	changed, missing, other := filepath.Join(dir, "changed.bin"), filepath.Join(dir, "missing.bin"), filepath.Join(dir, "other.bin")
+
+	journal, err := openBuildJournal(buildJournalPath(filepath.Join(dir, "storage"), repo+":v1", nil, config.NewBuild()))
+	require.NoError(t, err)
+	defer journal.Close()
+
+	blobs[repo] = map[string][]byte{}
+	for _, path := range []string{changed, missing, other} {
+		digest, size, err := fileDigest(path)
+		require.NoError(t, err)
+		require.NoError(t, journal.record(modelspec.MediaTypeModelWeightRaw, path, "", digest, size, layers[path]))
+		blobs[repo][layers[path].Digest.String()] = []byte("blob")
+	}
+
+	// the changed file, the missing blob and the different media type are rebuilt, the file is
+	// changed in place with the same size and mtime, which is only caught by the digest.
+	info, err := os.Stat(changed)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(changed, []byte("CONTENT OF changed.bin"), 0644))
+	require.NoError(t, os.Chtimes(changed, info.ModTime(), info.ModTime()))
+	delete(blobs[repo], layers[missing].Digest.String())
+
+	builder := &buildmock.Builder{}
+	builder.On("BuildLayer", mock.Anything, modelspec.MediaTypeModelWeightRaw, dir, changed, "", mock.Anything).Return(layers[changed], nil).Once()
+	builder.On("BuildLayer", mock.Anything, modelspec.MediaTypeModelWeightRaw, dir, missing, "", mock.Anything).Return(layers[missing], nil).Once()
+	builder.On("BuildLayer", mock.Anything, modelspec.MediaTypeModelWeightGzip, dir, other, "", mock.Anything).Return(layers[other], nil).Once()
+
+	jb := &journalBuilder{Builder: builder, journal: journal, store: store, repo: repo}
+	for path, mediaType := range map[string]string{changed: modelspec.MediaTypeModelWeightRaw, missing: modelspec.MediaTypeModelWeightRaw, other: modelspec.MediaTypeModelWeightGzip} {
+		_, err := jb.BuildLayer(ctx, mediaType, dir, path, "", hooks.NewHooks())
+		require.NoError(t, err)
+	}
+
+	builder.AssertExpectations(t)
+}
+
+func TestBuildJournalPath(t *testing.T) {
+	storageDir := t.TempDir()
+	target := "example.com/models/llama:v1"
+	cfg := config.NewBuild()
+
+	// the path is stable across the reruns of the same build.
+	path := buildJournalPath(storageDir, target, []byte("MODEL *.bin"), cfg)
+	assert.Equal(t, path, buildJournalPath(storageDir, target, []byte("MODEL *.bin"), config.NewBuild()))
+
+	// the Modelfile and the settings changing the layers key the journal.
+	assert.NotEqual(t, path, buildJournalPath(storageDir, target, []byte("MODEL *.safetensors"), cfg))
+	for _, update := range []func(*config.Build){
+		func(cfg *config.Build) { cfg.Raw = true },
+		func(cfg *config.Build) { cfg.StripMetadata = true },
+		func(cfg *config.Build) { cfg.ExecPatterns = []string{"*.sh"} },
+		func(cfg *config.Build) { cfg.FastChecksum = true },
+		func(cfg *config.Build) { cfg.EncryptionKey = "key.pem" },
+	} {
+		changed := config.NewBuild()
+		update(changed)
+		assert.NotEqual(t, path, buildJournalPath(storageDir, target, []byte("MODEL *.bin"), changed))
+	}
+
+	// opening the journal removes the stale journals of the same target and the expired ones.
+	stale := buildJournalPath(storageDir, target, []byte("MODEL *.safetensors"), cfg)
+	expired := buildJournalPath(storageDir, "example.com/models/other:v1", nil, cfg)
+	kept := buildJournalPath(storageDir, "example.com/models/other:v2", nil, cfg)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	for _, p := range []string{stale, expired, kept} {
+		require.NoError(t, os.WriteFile(p, nil, 0644))
+	}
+	mtime := time.Now().Add(-buildJournalMaxAge - time.Hour)
+	require.NoError(t, os.Chtimes(expired, mtime, mtime))
+
+	journal, err := openBuildJournal(path)
+	require.NoError(t, err)
+	defer journal.Close()
+	assert.NoFileExists(t, stale)
+	assert.NoFileExists(t, expired)
+	assert.FileExists(t, kept)
+}
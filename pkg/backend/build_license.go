@@ -0,0 +1,109 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/license"
+)
+
+// detectLicenses detects the SPDX identifiers of the licenses from the license files of the
+// layers, which are read from the work directory or the archive. It returns the SPDX expression
+// of the detected licenses combined by OR, or empty if none of them is known.
+func detectLicenses(workDir string, archive *archiver.Archive, layers []ocispec.Descriptor) string {
+	ids := map[string]struct{}{}
+	for _, layer := range layers {
+		path := layerFilepath(layer)
+		if !license.IsLicenseFile(path) {
+			continue
+		}
+
+		content, err := readLicenseFile(workDir, archive, path)
+		if err != nil {
+			logrus.Warnf("build: failed to detect license from %s: %v", path, err)
+			continue
+		}
+
+		if id := license.Detect(content); id != "" {
+			logrus.Infof("build: detected license %s from %s", id, path)
+			ids[id] = struct{}{}
+		}
+	}
+
+	return licenseExpression(ids)
+}
+
+// licenseExpression returns the SPDX expression of the detected licenses combined by OR in the
+// sorted order as the multiple licenses of the model are normally the choices of the dual
+// licensing, or empty if there is no license.
+func licenseExpression(ids map[string]struct{}) string {
+	licenses := make([]string, 0, len(ids))
+	for id := range ids {
+		licenses = append(licenses, id)
+	}
+	sort.Strings(licenses)
+
+	return strings.Join(licenses, " OR ")
+}
+
+// readLicenseFile reads the license file from the work directory or the archive, the
+// directory packed into the layer is skipped.
+func readLicenseFile(workDir string, archive *archiver.Archive, path string) ([]byte, error) {
+	var reader io.Reader
+	if archive != nil {
+		section, err := archive.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = section
+	} else {
+		file, err := os.Open(filepath.Join(workDir, path))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil, nil
+		}
+
+		reader = file
+	}
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxLicenseFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read license file: %w", err)
+	}
+
+	return content, nil
+}
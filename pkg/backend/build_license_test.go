@@ -0,0 +1,127 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+const (
+	apacheLicenseText = "                                 Apache License\n                           Version 2.0, January 2004\n                        http://www.apache.org/licenses/\n"
+	mitLicenseText    = "MIT License\n\nCopyright (c) 2024\n\nPermission is hereby granted, free of charge, to any person obtaining a copy\nof this software"
+)
+
+func TestDetectLicenses(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"LICENSE":                     apacheLicenseText,
+		"third_party/MIT/LICENSE-MIT": mitLicenseText,
+		"COPYING":                     "LLAMA 3 COMMUNITY LICENSE AGREEMENT",
+		"README.md":                   mitLicenseText,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(workDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	layer := func(path string) ocispec.Descriptor {
+		return ocispec.Descriptor{Annotations: map[string]string{modelspec.AnnotationFilepath: path}}
+	}
+
+	testCases := []struct {
+		name     string
+		layers   []ocispec.Descriptor
+		expected string
+	}{
+		{
+			name:     "single license",
+			layers:   []ocispec.Descriptor{layer("LICENSE"), layer("README.md")},
+			expected: "Apache-2.0",
+		},
+		{
+			name:     "multiple licenses",
+			layers:   []ocispec.Descriptor{layer("third_party/MIT/LICENSE-MIT"), layer("LICENSE"), layer("COPYING")},
+			expected: "Apache-2.0 OR MIT",
+		},
+		{
+			name:     "unknown license",
+			layers:   []ocispec.Descriptor{layer("COPYING")},
+			expected: "",
+		},
+		{
+			name:     "non license files",
+			layers:   []ocispec.Descriptor{layer("README.md")},
+			expected: "",
+		},
+		{
+			name:     "missing license file",
+			layers:   []ocispec.Descriptor{layer("LICENSE.txt"), layer("LICENSE")},
+			expected: "Apache-2.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, detectLicenses(workDir, nil, tc.layers))
+		})
+	}
+}
+
+func TestBuildLicenseAnnotation(t *testing.T) {
+	modelfilePath := filepath.Join(t.TempDir(), "Modelfile")
+	require.NoError(t, os.WriteFile(modelfilePath, []byte("NAME licensed\nMODEL model.safetensors\nDOC LICENSE*\n"), 0644))
+
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	build := func(tag string, files map[string][]byte) ocispec.Manifest {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		require.NoError(t, b.BuildFromFiles(ctx, modelfilePath, files, "example.com/repo:"+tag, cfg))
+
+		manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", tag)
+		require.NoError(t, err)
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+		return manifest
+	}
+
+	manifest := build("v1", map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"LICENSE":           []byte(apacheLicenseText),
+		"LICENSE-MIT":       []byte(mitLicenseText),
+	})
+	assert.Equal(t, "Apache-2.0 OR MIT", manifest.Annotations[ocispec.AnnotationLicenses])
+
+	// the annotation is omitted if the license is unknown.
+	manifest = build("v2", map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"LICENSE":           []byte("LLAMA 3 COMMUNITY LICENSE AGREEMENT"),
+	})
+	assert.NotContains(t, manifest.Annotations, ocispec.AnnotationLicenses)
+}
@@ -0,0 +1,80 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// buildLimitsHint is the suggestion for the artifact with too many layers of small files.
+const buildLimitsHint = "consider packing the directories of many small files into a single layer by the directory form in the Modelfile, such as `CODE src/`"
+
+// checkBuildLimits checks the number of the built layers against the max layers, which is warned,
+// or returned as the error in the strict mode.
+func checkBuildLimits(target string, layers []ocispec.Descriptor, cfg *config.Build) error {
+	if cfg.MaxLayers <= 0 || len(layers) <= cfg.MaxLayers {
+		return nil
+	}
+
+	violation := fmt.Sprintf("the artifact has %d layers, exceeding the limit of %d layers%s", len(layers), cfg.MaxLayers, dominantLayerKind(layers))
+	if cfg.StrictLimits {
+		return fmt.Errorf("artifact %s exceeds the build limits: %s, %s", target, violation, buildLimitsHint)
+	}
+
+	logrus.Warnf("build: artifact %s %s", target, violation)
+	if cfg.ReportWriter != nil {
+		fmt.Fprintf(cfg.ReportWriter, "Warning: %s, %s\n", violation, buildLimitsHint)
+	}
+
+	return nil
+}
+
+// dominantLayerKind describes the kind of the layers with the most layers, which is usually
+// the source of the huge number of the layers.
+func dominantLayerKind(layers []ocispec.Descriptor) string {
+	counts := map[string]int{}
+	for _, layer := range layers {
+		if kind := layerKind(layer.MediaType); kind != "" {
+			counts[kind]++
+		}
+	}
+
+	if len(counts) == 0 {
+		return ""
+	}
+
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		if counts[kinds[i]] != counts[kinds[j]] {
+			return counts[kinds[i]] > counts[kinds[j]]
+		}
+
+		return kinds[i] < kinds[j]
+	})
+
+	return fmt.Sprintf(" (%d of them are %s)", counts[kinds[0]], kinds[0])
+}
@@ -0,0 +1,71 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestCheckBuildLimits(t *testing.T) {
+	layers := []ocispec.Descriptor{{MediaType: modelspec.MediaTypeModelWeightRaw, Digest: godigest.FromString("weights")}}
+	for i := range 10 {
+		layers = append(layers, ocispec.Descriptor{MediaType: modelspec.MediaTypeModelCodeRaw, Digest: godigest.FromString(fmt.Sprintf("code-%d", i))})
+	}
+
+	newConfig := func(maxLayers int, strict bool) (*config.Build, *bytes.Buffer) {
+		var buf bytes.Buffer
+		cfg := config.NewBuild()
+		cfg.MaxLayers = maxLayers
+		cfg.StrictLimits = strict
+		cfg.ReportWriter = &buf
+		return cfg, &buf
+	}
+
+	t.Run("at the threshold", func(t *testing.T) {
+		cfg, buf := newConfig(11, false)
+		assert.NoError(t, checkBuildLimits("llama3:v1", layers, cfg))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("above the threshold", func(t *testing.T) {
+		cfg, buf := newConfig(10, false)
+		assert.NoError(t, checkBuildLimits("llama3:v1", layers, cfg))
+		assert.Contains(t, buf.String(), "Warning: the artifact has 11 layers, exceeding the limit of 10 layers (10 of them are code)")
+		assert.Contains(t, buf.String(), "`CODE src/`")
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		cfg, buf := newConfig(10, true)
+		assert.ErrorContains(t, checkBuildLimits("llama3:v1", layers, cfg), "exceeding the limit of 10 layers")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg, buf := newConfig(0, true)
+		assert.NoError(t, checkBuildLimits("llama3:v1", layers, cfg))
+		assert.Empty(t, buf.String())
+	})
+}
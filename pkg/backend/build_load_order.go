@@ -0,0 +1,121 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"sort"
+	"strconv"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+const (
+	// annotationLoadOrder is the annotation key of the weight layer recording the zero-based
+	// index of the weight in the load order, which is consumed by the inference loaders
+	// requiring the shards loaded in order.
+	annotationLoadOrder = "org.cncf.modctl.load-order"
+)
+
+// annotateLoadOrder records the load order of the weight layers in their annotations by the
+// order of their filepaths, the other layers are not annotated.
+func annotateLoadOrder(layers []ocispec.Descriptor, order string) {
+	less := naturalLess
+	if order == config.LoadOrderName {
+		less = func(a, b string) bool { return a < b }
+	}
+
+	var weights []int
+	for i, layer := range layers {
+		if _, ok := weightMediaTypes[layer.MediaType]; ok {
+			weights = append(weights, i)
+		}
+	}
+
+	sort.SliceStable(weights, func(i, j int) bool {
+		return less(layerFilepath(layers[weights[i]]), layerFilepath(layers[weights[j]]))
+	})
+
+	for index, i := range weights {
+		if layers[i].Annotations == nil {
+			layers[i].Annotations = map[string]string{}
+		}
+
+		layers[i].Annotations[annotationLoadOrder] = strconv.Itoa(index)
+	}
+}
+
+// loadOrder returns the load order of the layer recorded on build, or nil if it is not recorded.
+func loadOrder(layer ocispec.Descriptor) *int {
+	index, err := strconv.Atoi(layer.Annotations[annotationLoadOrder])
+	if err != nil || index < 0 {
+		return nil
+	}
+
+	return &index
+}
+
+// naturalLess compares the strings with the runs of the digits compared numerically, so the
+// shards are ordered by their numbers regardless of the zero padding, such as model-2.bin is
+// less than model-10.bin.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		digitsA, digitsB := leadingDigits(a), leadingDigits(b)
+		if digitsA == "" || digitsB == "" {
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+
+			a, b = a[1:], b[1:]
+			continue
+		}
+
+		// compare the numbers without the leading zeros by the length then the digits.
+		numA, numB := trimLeadingZeros(digitsA), trimLeadingZeros(digitsB)
+		if len(numA) != len(numB) {
+			return len(numA) < len(numB)
+		}
+
+		if numA != numB {
+			return numA < numB
+		}
+
+		a, b = a[len(digitsA):], b[len(digitsB):]
+	}
+
+	return len(a) < len(b)
+}
+
+// leadingDigits returns the run of the digits at the beginning of the string.
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	return s[:i]
+}
+
+// trimLeadingZeros trims the leading zeros of the digits, the zero is kept as is.
+func trimLeadingZeros(digits string) string {
+	for len(digits) > 1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+
+	return digits
+}
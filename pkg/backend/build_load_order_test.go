@@ -0,0 +1,166 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestNaturalLess(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		less bool
+	}{
+		{"model-2.bin", "model-10.bin", true},
+		{"model-10.bin", "model-2.bin", false},
+		{"model-00001-of-00003.safetensors", "model-00002-of-00003.safetensors", true},
+		{"model-01.bin", "model-1.bin", false},
+		{"model-1.bin", "model-01.bin", false},
+		{"a/model-1.bin", "b/model-0.bin", true},
+		{"model.bin", "model-1.bin", false},
+		{"model", "model-1", true},
+		{"", "model", true},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.less, naturalLess(tc.a, tc.b), "%s < %s", tc.a, tc.b)
+	}
+}
+
+// loadOrderLayers returns the layers of the files with the media types.
+func loadOrderLayers(files map[string]string, order []string) []ocispec.Descriptor {
+	layers := make([]ocispec.Descriptor, 0, len(order))
+	for _, name := range order {
+		layers = append(layers, ocispec.Descriptor{
+			MediaType:   files[name],
+			Digest:      godigest.FromString(name),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		})
+	}
+
+	return layers
+}
+
+func TestAnnotateLoadOrder(t *testing.T) {
+	files := map[string]string{
+		"config.json":                  modelspec.MediaTypeModelWeightConfigRaw,
+		"model-10-of-12.safetensors":   modelspec.MediaTypeModelWeightRaw,
+		"model-2-of-12.safetensors":    modelspec.MediaTypeModelWeightRaw,
+		"model-1-of-12.safetensors":    modelspec.MediaTypeModelWeightRaw,
+		"README.md":                    modelspec.MediaTypeModelDocRaw,
+		"vision/model-1-of-2.bin":      modelspec.MediaTypeModelWeight,
+		"model-11-of-12.safetensors":   modelspec.MediaTypeModelWeightRaw,
+		"vision/model-2-of-2.bin":      modelspec.MediaTypeModelWeight,
+		"model-12-of-12.safetensors":   modelspec.MediaTypeModelWeightRaw,
+		"model-3-of-12.safetensors.gz": modelspec.MediaTypeModelWeightGzip,
+	}
+	order := []string{
+		"config.json", "model-10-of-12.safetensors", "model-2-of-12.safetensors", "README.md",
+		"vision/model-2-of-2.bin", "model-1-of-12.safetensors", "model-11-of-12.safetensors",
+		"vision/model-1-of-2.bin", "model-12-of-12.safetensors", "model-3-of-12.safetensors.gz",
+	}
+
+	loadOrders := func(layers []ocispec.Descriptor) []string {
+		sorted := make([]string, len(layers))
+		count := 0
+		for _, layer := range layers {
+			index := loadOrder(layer)
+			if index == nil {
+				continue
+			}
+
+			count++
+			sorted[*index] = layerFilepath(layer)
+		}
+
+		return sorted[:count]
+	}
+
+	layers := loadOrderLayers(files, order)
+	annotateLoadOrder(layers, config.LoadOrderShard)
+	assert.Equal(t, []string{
+		"model-1-of-12.safetensors", "model-2-of-12.safetensors", "model-3-of-12.safetensors.gz",
+		"model-10-of-12.safetensors", "model-11-of-12.safetensors", "model-12-of-12.safetensors",
+		"vision/model-1-of-2.bin", "vision/model-2-of-2.bin",
+	}, loadOrders(layers))
+
+	// the layers are kept in place and only the weights are annotated.
+	for i, layer := range layers {
+		assert.Equal(t, order[i], layerFilepath(layer))
+		_, isWeight := weightMediaTypes[layer.MediaType]
+		assert.Equal(t, isWeight, layer.Annotations[annotationLoadOrder] != "", layerFilepath(layer))
+	}
+
+	layers = loadOrderLayers(files, order)
+	annotateLoadOrder(layers, config.LoadOrderName)
+	assert.Equal(t, []string{
+		"model-1-of-12.safetensors", "model-10-of-12.safetensors", "model-11-of-12.safetensors",
+		"model-12-of-12.safetensors", "model-2-of-12.safetensors", "model-3-of-12.safetensors.gz",
+		"vision/model-1-of-2.bin", "vision/model-2-of-2.bin",
+	}, loadOrders(layers))
+}
+
+func TestLoadOrder(t *testing.T) {
+	assert.Nil(t, loadOrder(ocispec.Descriptor{}))
+	assert.Nil(t, loadOrder(ocispec.Descriptor{Annotations: map[string]string{annotationLoadOrder: "first"}}))
+	assert.Nil(t, loadOrder(ocispec.Descriptor{Annotations: map[string]string{annotationLoadOrder: "-1"}}))
+
+	index := loadOrder(ocispec.Descriptor{Annotations: map[string]string{annotationLoadOrder: "0"}})
+	require.NotNil(t, index)
+	assert.Equal(t, 0, *index)
+}
+
+func TestExtractIndexLoadOrder(t *testing.T) {
+	outputDir := t.TempDir()
+	layers := loadOrderLayers(map[string]string{
+		"config.json":              modelspec.MediaTypeModelWeightConfigRaw,
+		"model-2-of-2.safetensors": modelspec.MediaTypeModelWeightRaw,
+		"model-1-of-2.safetensors": modelspec.MediaTypeModelWeightRaw,
+	}, []string{"config.json", "model-2-of-2.safetensors", "model-1-of-2.safetensors"})
+	annotateLoadOrder(layers, config.LoadOrderShard)
+
+	for _, layer := range layers {
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, layerFilepath(layer)), []byte(layer.Digest), 0644))
+	}
+	indexDir := t.TempDir()
+	require.NoError(t, writeExtractIndex(indexDir, outputDir, layers, false))
+
+	indexPath, _, err := extractIndexPath(indexDir, outputDir)
+	require.NoError(t, err)
+	data, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+
+	var index extractIndex
+	require.NoError(t, json.Unmarshal(data, &index))
+	require.Len(t, index.Files, 3)
+	assert.Nil(t, index.Files[0].LoadOrder)
+	require.NotNil(t, index.Files[1].LoadOrder)
+	assert.Equal(t, 1, *index.Files[1].LoadOrder)
+	require.NotNil(t, index.Files[2].LoadOrder)
+	assert.Equal(t, 0, *index.Files[2].LoadOrder)
+}
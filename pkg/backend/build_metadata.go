@@ -0,0 +1,99 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/modelfile"
+)
+
+// paramsizeTolerance is the relative difference of the paramsize tolerated by the metadata
+// check, as the paramsize is usually rounded, such as 7B for 6.7B parameters.
+const paramsizeTolerance = 0.25
+
+// checkMetadata compares the paramsize and the precision in the Modelfile with the ones detected
+// from the safetensors files in the work directory, the discrepancies are warned, or returned as
+// the error in the error mode.
+func checkMetadata(workDir string, mf modelfile.Modelfile, cfg *config.Build) error {
+	if cfg.VerifyMetadata == "" || (mf.GetParamsize() == "" && mf.GetPrecision() == "") {
+		return nil
+	}
+
+	paths := []string{}
+	for _, pattern := range mf.GetModels() {
+		matches, err := filepath.Glob(filepath.Join(workDir, pattern))
+		if err != nil {
+			return fmt.Errorf("failed to match pattern %s: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			if strings.EqualFold(filepath.Ext(match), ".safetensors") {
+				paths = append(paths, match)
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		logrus.Infof("build: no safetensors files to verify the metadata")
+		return nil
+	}
+
+	detected, err := modelfile.DetectMetadata(paths)
+	if err != nil {
+		return fmt.Errorf("failed to detect the metadata: %w", err)
+	}
+
+	discrepancies := []string{}
+	if paramsize := mf.GetParamsize(); paramsize != "" && detected.ParamCount > 0 {
+		declared, err := modelfile.ParseParamsize(paramsize)
+		if err != nil {
+			discrepancies = append(discrepancies, fmt.Sprintf("paramsize %s is not a number of parameters", paramsize))
+		} else if math.Abs(declared-float64(detected.ParamCount)) > paramsizeTolerance*float64(detected.ParamCount) {
+			discrepancies = append(discrepancies, fmt.Sprintf("paramsize %s mismatches %s parameters in the safetensors", paramsize, modelfile.FormatParamsize(detected.ParamCount)))
+		}
+	}
+
+	if precision := mf.GetPrecision(); precision != "" && detected.Precision != "" {
+		if modelfile.NormalizePrecision(precision) != modelfile.NormalizePrecision(detected.Precision) {
+			discrepancies = append(discrepancies, fmt.Sprintf("precision %s mismatches %s in the safetensors", precision, detected.Precision))
+		}
+	}
+
+	if len(discrepancies) == 0 {
+		logrus.Infof("build: verified the metadata [params: %d, precision: %s]", detected.ParamCount, detected.Precision)
+		return nil
+	}
+
+	msg := fmt.Sprintf("the metadata in the Modelfile mismatches the model files: %s", strings.Join(discrepancies, ", "))
+	if cfg.VerifyMetadata == config.VerifyMetadataError {
+		return fmt.Errorf("%s", msg)
+	}
+
+	logrus.Warnf("build: %s", msg)
+	if cfg.ReportWriter != nil {
+		fmt.Fprintf(cfg.ReportWriter, "Warning: %s\n", msg)
+	}
+
+	return nil
+}
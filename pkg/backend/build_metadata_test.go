@@ -0,0 +1,87 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/modelfile"
+)
+
+func TestCheckMetadata(t *testing.T) {
+	workDir := t.TempDir()
+	header, err := json.Marshal(map[string]interface{}{
+		"embed": map[string]interface{}{"dtype": "BF16", "shape": []int{1000, 1000}, "data_offsets": []int{0, 0}},
+		"lm":    map[string]interface{}{"dtype": "BF16", "shape": []int{1000, 500}, "data_offsets": []int{0, 0}},
+	})
+	require.NoError(t, err)
+	content := binary.LittleEndian.AppendUint64(nil, uint64(len(header)))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "model.safetensors"), append(content, header...), 0644))
+
+	testCases := []struct {
+		name      string
+		paramsize string
+		precision string
+		mode      string
+		warning   string
+		expectErr string
+	}{
+		{"matching", "1.5M", "bf16", config.VerifyMetadataError, "", ""},
+		{"rounded paramsize", "1.4M", "torch.bfloat16", config.VerifyMetadataError, "", ""},
+		{"disabled", "7B", "fp16", "", "", ""},
+		{"conflicting warned", "7B", "fp16", config.VerifyMetadataWarn, "paramsize 7B mismatches 1.5M parameters", ""},
+		{"conflicting precision", "1.5M", "fp16", config.VerifyMetadataError, "", "precision fp16 mismatches bfloat16"},
+		{"conflicting paramsize", "7B", "bf16", config.VerifyMetadataError, "", "paramsize 7B mismatches 1.5M parameters"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mf := &modelfile.Modelfile{}
+			mf.On("GetModels").Return([]string{"*.safetensors"})
+			mf.On("GetParamsize").Return(tc.paramsize)
+			mf.On("GetPrecision").Return(tc.precision)
+
+			var warnings bytes.Buffer
+			cfg := config.NewBuild()
+			cfg.VerifyMetadata = tc.mode
+			cfg.ReportWriter = &warnings
+
+			err := checkMetadata(workDir, mf, cfg)
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.warning != "" {
+				assert.Contains(t, warnings.String(), "Warning: the metadata in the Modelfile mismatches the model files")
+				assert.Contains(t, warnings.String(), tc.warning)
+			} else {
+				assert.Empty(t, warnings.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/modelfile"
+)
+
+const (
+	// annotationOllamaFrom is the annotation key for the FROM directive of the Ollama Modelfile.
+	annotationOllamaFrom = "org.cncf.modctl.ollama.from"
+
+	// annotationOllamaParameters is the annotation key for the PARAMETER directives of the
+	// Ollama Modelfile, which is the JSON object of the parameter names to the values.
+	annotationOllamaParameters = "org.cncf.modctl.ollama.parameters"
+
+	// annotationOllamaTemplate is the annotation key for the TEMPLATE directive of the Ollama Modelfile.
+	annotationOllamaTemplate = "org.cncf.modctl.ollama.template"
+
+	// annotationOllamaSystem is the annotation key for the SYSTEM directive of the Ollama Modelfile.
+	annotationOllamaSystem = "org.cncf.modctl.ollama.system"
+)
+
+// ollamaAnnotations returns the manifest annotations recording the directives of the Ollama
+// Modelfile, the directives not present are omitted.
+func ollamaAnnotations(mf modelfile.Modelfile) map[string]string {
+	anno := map[string]string{}
+	if from := mf.GetFrom(); from != "" {
+		anno[annotationOllamaFrom] = from
+	}
+
+	if params := mf.GetParameters(); len(params) > 0 {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			logrus.Warnf("build: failed to marshal the Ollama parameters: %s", err)
+		} else {
+			anno[annotationOllamaParameters] = string(paramsJSON)
+		}
+	}
+
+	if template := mf.GetTemplate(); template != "" {
+		anno[annotationOllamaTemplate] = template
+	}
+
+	if system := mf.GetSystem(); system != "" {
+		anno[annotationOllamaSystem] = system
+	}
+
+	return anno
+}
@@ -0,0 +1,51 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/modelfile"
+	"github.com/modelpack/modctl/pkg/modelfile/parser"
+)
+
+func TestOllamaAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Modelfile")
+	content := `FROM ./model.gguf
+PARAMETER temperature 0.7
+PARAMETER stop "<|end|>"
+PARAMETER stop "<|user|>"
+TEMPLATE """{{ .System }}
+{{ .Prompt }}"""
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	mf, err := modelfile.NewModelfile(path, parser.WithOllama())
+	require.NoError(t, err)
+
+	anno := ollamaAnnotations(mf)
+	assert.Equal(t, "./model.gguf", anno[annotationOllamaFrom])
+	assert.JSONEq(t, `{"stop":["<|end|>","<|user|>"],"temperature":["0.7"]}`, anno[annotationOllamaParameters])
+	assert.Equal(t, "{{ .System }}\n{{ .Prompt }}", anno[annotationOllamaTemplate])
+	assert.NotContains(t, anno, annotationOllamaSystem)
+}
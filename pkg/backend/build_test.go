@@ -17,12 +17,27 @@
 package backend
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/checksum"
 	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/test/mocks/modelfile"
-
-	"github.com/stretchr/testify/assert"
+	"github.com/modelpack/modctl/test/mocks/storage"
 )
 
 func TestGetProcessors(t *testing.T) {
@@ -31,13 +46,365 @@ func TestGetProcessors(t *testing.T) {
 	modelfile.On("GetModels").Return([]string{"model1", "model2"})
 	modelfile.On("GetCodes").Return([]string{"1.py", "2.py"})
 	modelfile.On("GetDocs").Return([]string{"doc1", "doc2"})
+	modelfile.On("GetDatasets").Return([]string{"data.zip"})
 
 	b := &backend{}
 	processors := b.getProcessors(modelfile, &config.Build{})
 
-	assert.Len(t, processors, 4)
+	assert.Len(t, processors, 5)
 	assert.Equal(t, "config", processors[0].Name())
 	assert.Equal(t, "model", processors[1].Name())
 	assert.Equal(t, "code", processors[2].Name())
 	assert.Equal(t, "doc", processors[3].Name())
+	assert.Equal(t, "dataset", processors[4].Name())
+
+	processors = b.getProcessors(modelfile, &config.Build{Only: config.OnlyWeights})
+	assert.Len(t, processors, 1)
+	assert.Equal(t, "model", processors[0].Name())
+}
+
+func TestBuildClassOverrides(t *testing.T) {
+	modelfilePath := filepath.Join(t.TempDir(), "Modelfile")
+	require.NoError(t, os.WriteFile(modelfilePath, []byte("NAME test\nMODEL *.bin\n"), 0644))
+
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewBuild()
+	cfg.Raw = true
+	cfg.AsConfig = []string{"tokenizer*.bin"}
+	require.NoError(t, b.BuildFromFiles(ctx, modelfilePath, map[string][]byte{
+		"model.bin":     []byte("weights"),
+		"tokenizer.bin": []byte("tokenizer"),
+	}, "example.com/repo:v1", cfg))
+
+	manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+	mediaTypes := map[string]string{}
+	for _, layer := range manifest.Layers {
+		mediaTypes[layer.Annotations[modelspec.AnnotationFilepath]] = layer.MediaType
+	}
+	assert.Equal(t, map[string]string{
+		"model.bin":     modelspec.MediaTypeModelWeightRaw,
+		"tokenizer.bin": modelspec.MediaTypeModelWeightConfigRaw,
+	}, mediaTypes)
+}
+
+func TestBuildOnlyWeights(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"Modelfile":         "NAME test\nCONFIG config.json\nMODEL *.safetensors\nCODE *.py\nDOC README.md\n",
+		"config.json":       "{}",
+		"model.safetensors": "weights",
+		"run.py":            "print('hello')",
+		"README.md":         "# test",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewBuild()
+	cfg.Raw = true
+	cfg.Only = config.OnlyWeights
+	require.NoError(t, b.Build(ctx, filepath.Join(workDir, "Modelfile"), workDir, "example.com/repo:v1", cfg))
+
+	manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, modelspec.MediaTypeModelWeightRaw, manifest.Layers[0].MediaType)
+	assert.Equal(t, "model.safetensors", manifest.Layers[0].Annotations[modelspec.AnnotationFilepath])
+
+	var model modelspec.Model
+	require.NoError(t, json.Unmarshal(blobs["example.com/repo"][manifest.Config.Digest.String()], &model))
+	assert.Equal(t, []godigest.Digest{manifest.Layers[0].Digest}, model.ModelFS.DiffIDs)
+}
+
+func TestBuildLayerOrder(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"Modelfile":               "NAME test\nCONFIG config.json\nMODEL *.safetensors\nCODE run.py\nDOC README.md\n",
+		"config.json":             "{}",
+		"model-00001.safetensors": "weights 1",
+		"model-00002.safetensors": "weights 2",
+		"run.py":                  "print()",
+		"README.md":               "# test",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	testCases := []struct {
+		name       string
+		layerOrder string
+		expected   []string
+	}{
+		{
+			name:     "processing order",
+			expected: []string{"config.json", "model-00001.safetensors", "model-00002.safetensors", "run.py", "README.md"},
+		},
+		{
+			name:       "metadata first",
+			layerOrder: config.LayerOrderMetadataFirst,
+			expected:   []string{"config.json", "README.md", "run.py", "model-00001.safetensors", "model-00002.safetensors"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStore := &storage.Storage{}
+			mockStore.On("PushBlob", mock.Anything, "example.com/repo", mock.Anything, mock.Anything).Return(
+				func(ctx context.Context, repo string, body io.Reader, desc ocispec.Descriptor) (string, int64, error) {
+					content, err := io.ReadAll(body)
+					if err != nil {
+						return "", 0, err
+					}
+
+					return godigest.FromBytes(content).String(), int64(len(content)), nil
+				},
+			)
+
+			var manifest ocispec.Manifest
+			mockStore.On("PushManifest", mock.Anything, "example.com/repo", "v1", mock.Anything, mock.Anything).Return(
+				func(ctx context.Context, repo, reference, mediaType string, body []byte) (string, error) {
+					if err := json.Unmarshal(body, &manifest); err != nil {
+						return "", err
+					}
+
+					return godigest.FromBytes(body).String(), nil
+				},
+			)
+
+			cfg := config.NewBuild()
+			cfg.Raw = true
+			cfg.NoCreationTime = true
+			cfg.LayerOrder = tc.layerOrder
+			b := &backend{store: mockStore}
+			require.NoError(t, b.Build(context.Background(), filepath.Join(workDir, "Modelfile"), workDir, "example.com/repo:v1", cfg))
+
+			filepaths := []string{}
+			for _, layer := range manifest.Layers {
+				filepaths = append(filepaths, layer.Annotations[modelspec.AnnotationFilepath])
+			}
+			assert.Equal(t, tc.expected, filepaths)
+			if tc.layerOrder != "" {
+				assert.Equal(t, tc.layerOrder, manifest.Annotations[annotationLayerOrder])
+			} else {
+				assert.NotContains(t, manifest.Annotations, annotationLayerOrder)
+			}
+		})
+	}
+}
+
+func TestBuildMerkleRoot(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"Modelfile":               "NAME test\nCONFIG config.json\nMODEL *.safetensors\n",
+		"config.json":             "{}",
+		"model-00001.safetensors": "weights 1",
+		"model-00002.safetensors": "weights 2",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	build := func(tag string) ocispec.Manifest {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		cfg.NoCreationTime = true
+		cfg.MerkleRoot = true
+		require.NoError(t, b.Build(context.Background(), filepath.Join(workDir, "Modelfile"), workDir, "example.com/repo:"+tag, cfg))
+
+		manifestRaw, _, err := store.PullManifest(context.Background(), "example.com/repo", tag)
+		require.NoError(t, err)
+
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+		return manifest
+	}
+
+	v1 := build("v1")
+	root := v1.Annotations[checksum.AnnotationMerkleRoot]
+	assert.Regexp(t, `^sha256:[a-f0-9]{64}$`, root)
+	assert.Empty(t, validateManifest(&v1))
+
+	// the root is stable across the rebuilds of the identical content.
+	v2 := build("v2")
+	assert.Equal(t, root, v2.Annotations[checksum.AnnotationMerkleRoot])
+
+	// the root changes when a layer changes.
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "model-00002.safetensors"), []byte("weights 2 tuned"), 0644))
+	v3 := build("v3")
+	assert.NotEqual(t, root, v3.Annotations[checksum.AnnotationMerkleRoot])
+	assert.Equal(t, merkleRoot(v3.Layers), v3.Annotations[checksum.AnnotationMerkleRoot])
+
+	// the tampered layers do not match the root.
+	v3.Layers = v3.Layers[:len(v3.Layers)-1]
+	assert.Contains(t, validateManifest(&v3)[0], "does not match the layers")
+}
+
+func TestBuildNoOverwrite(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"Modelfile":         "NAME test\nCONFIG config.json\nMODEL *.safetensors\n",
+		"config.json":       "{}",
+		"model.safetensors": "weights",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	build := func(target string, noOverwrite, force bool) error {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		cfg.NoOverwrite = noOverwrite
+		cfg.Force = force
+		return b.Build(context.Background(), filepath.Join(workDir, "Modelfile"), workDir, target, cfg)
+	}
+
+	require.NoError(t, build("example.com/repo:v1", true, false))
+
+	// the existing tag is overwritten without the guard.
+	assert.NoError(t, build("example.com/repo:v1", false, false))
+
+	err := build("example.com/repo:v1", true, false)
+	assert.ErrorContains(t, err, "example.com/repo:v1 already exists")
+
+	assert.NoError(t, build("example.com/repo:v1", true, true))
+	assert.NoError(t, build("example.com/repo:v2", true, false))
+}
+
+func TestBuildFromFiles(t *testing.T) {
+	modelfileDir := t.TempDir()
+	modelfilePath := filepath.Join(modelfileDir, "Modelfile")
+	require.NoError(t, os.WriteFile(modelfilePath, []byte("NAME generated\nCONFIG config.json\nMODEL *.safetensors\nCODE src/*.py\nDOC README.md\n"), 0644))
+
+	files := map[string][]byte{
+		"config.json":       []byte(`{"hidden_size": 16}`),
+		"model.safetensors": []byte("generated weights"),
+		"src/infer.py":      []byte("print('infer')"),
+		"README.md":         []byte("# generated"),
+	}
+
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewBuild()
+	cfg.Raw = true
+	require.NoError(t, b.BuildFromFiles(ctx, modelfilePath, files, "example.com/repo:v1", cfg))
+
+	manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+	filepaths := []string{}
+	for _, layer := range manifest.Layers {
+		filepaths = append(filepaths, layer.Annotations[modelspec.AnnotationFilepath])
+		assert.Contains(t, layer.Annotations, modelspec.AnnotationFileMetadata)
+	}
+	assert.Equal(t, []string{"config.json", "model.safetensors", "src/infer.py", "README.md"}, filepaths)
+
+	outputDir := t.TempDir()
+	require.NoError(t, b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 2, Output: outputDir}))
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, data, name)
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		err := b.BuildFromFiles(ctx, modelfilePath, map[string][]byte{"config.json": []byte("{}")}, "example.com/repo:v2", cfg)
+		assert.ErrorContains(t, err, "does not exist in archive")
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		err := b.BuildFromFiles(ctx, modelfilePath, map[string][]byte{"../config.json": []byte("{}")}, "example.com/repo:v2", cfg)
+		assert.ErrorContains(t, err, "invalid path")
+	})
+}
+
+func TestBuildStandardAnnotations(t *testing.T) {
+	modelfilePath := filepath.Join(t.TempDir(), "Modelfile")
+	require.NoError(t, os.WriteFile(modelfilePath, []byte("NAME annotated\nMODEL model.safetensors\n"), 0644))
+	files := map[string][]byte{"model.safetensors": []byte("weights")}
+
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	build := func(tag string, noCreationTime bool) (ocispec.Manifest, modelspec.Model) {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		cfg.SourceURL = "https://github.com/example/annotated"
+		cfg.SourceRevision = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+		cfg.NoCreationTime = noCreationTime
+		require.NoError(t, b.BuildFromFiles(ctx, modelfilePath, files, "example.com/repo:"+tag, cfg))
+
+		manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", tag)
+		require.NoError(t, err)
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+		configRaw, err := b.readBlob(ctx, "example.com/repo", manifest.Config)
+		require.NoError(t, err)
+		var model modelspec.Model
+		require.NoError(t, json.Unmarshal(configRaw, &model))
+		return manifest, model
+	}
+
+	manifest, model := build("v1", false)
+	assert.Equal(t, model.Descriptor.SourceURL, manifest.Annotations[ocispec.AnnotationSource])
+	assert.Equal(t, model.Descriptor.Revision, manifest.Annotations[ocispec.AnnotationRevision])
+	require.NotNil(t, model.Descriptor.CreatedAt)
+	created, err := time.Parse(time.RFC3339Nano, manifest.Annotations[ocispec.AnnotationCreated])
+	require.NoError(t, err)
+	assert.True(t, model.Descriptor.CreatedAt.Equal(created))
+
+	// the creation time is omitted for the repeated builds.
+	manifest, _ = build("v2", true)
+	assert.NotContains(t, manifest.Annotations, ocispec.AnnotationCreated)
+	assert.Equal(t, "https://github.com/example/annotated", manifest.Annotations[ocispec.AnnotationSource])
+}
+
+func TestCheckTargetOverwriteLocalError(t *testing.T) {
+	mockStore := &storage.Storage{}
+	mockStore.On("PullManifest", mock.Anything, "example.com/repo", "v1").Return(nil, "", errors.New("permission denied"))
+	b := &backend{store: mockStore}
+
+	cfg := config.NewBuild()
+	cfg.NoOverwrite = true
+
+	// the storage error is not mistaken for the missing target.
+	err := b.checkTargetOverwrite(context.Background(), "example.com/repo", "v1", cfg)
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestCheckTargetOverwriteRemote(t *testing.T) {
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+	contents["models/llama3/manifests/v1"] = []byte(`{"schemaVersion":2}`)
+
+	b := &backend{}
+	cfg := config.NewBuild()
+	cfg.OutputRemote = true
+	cfg.PlainHTTP = true
+	cfg.NoOverwrite = true
+
+	err := b.checkTargetOverwrite(context.Background(), repo, "v1", cfg)
+	assert.ErrorContains(t, err, "already exists")
+	assert.NoError(t, b.checkTargetOverwrite(context.Background(), repo, "v2", cfg))
+
+	cfg.Force = true
+	assert.NoError(t, b.checkTargetOverwrite(context.Background(), repo, "v1", cfg))
 }
@@ -0,0 +1,153 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	retry "github.com/avast/retry-go/v4"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/iometrics"
+)
+
+// CacheWarmReport is the report of the cache warm.
+type CacheWarmReport struct {
+	// Hits is the number of the blobs already in the local storage.
+	Hits int `json:"hits"`
+	// Fetched is the number of the blobs fetched from the remote.
+	Fetched int `json:"fetched"`
+	// FetchedSize is the total size of the blobs fetched from the remote.
+	FetchedSize int64 `json:"fetchedSize"`
+	// Filtered is the number of the layers filtered out by the kinds.
+	Filtered int `json:"filtered"`
+}
+
+// CacheWarm prefetches the blobs referenced by the manifest of the remote model artifact into the
+// local storage without extracting them, so the later pull or build only fetches the missing ones.
+// The manifest is not stored, so the prefetched blobs are removed by prune until they are referenced.
+func (b *backend) CacheWarm(ctx context.Context, target string, cfg *config.CacheWarm) (*CacheWarmReport, error) {
+	logrus.Infof("cache: warming blobs of %s [only: %v]", target, cfg.Only)
+	ref, err := ParseReference(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the target: %w", err)
+	}
+
+	repo, tag := ref.Repository(), ref.Tag()
+	src, err := remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure), remote.WithProxy(cfg.Proxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the remote client: %w", err)
+	}
+
+	_, manifest, err := fetchManifest(ctx, src, tag, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CacheWarmReport{}
+	blobs := []ocispec.Descriptor{manifest.Config}
+	for _, layer := range manifest.Layers {
+		if len(cfg.Only) > 0 && !slices.Contains(cfg.Only, layerKind(layer.MediaType)) {
+			logrus.Debugf("cache: layer %s filtered out [mediaType: %s]", layer.Digest, layer.MediaType)
+			report.Filtered++
+			continue
+		}
+
+		blobs = append(blobs, layer)
+	}
+
+	if cfg.DisableProgress {
+		internalpb.SetDisableProgress(true)
+	}
+
+	pb := internalpb.NewProgressBar(cfg.ProgressWriter)
+	pb.Start()
+	defer pb.Stop()
+
+	tracker := iometrics.NewTracker("cache")
+
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.Concurrency)
+	for _, blob := range blobs {
+		g.Go(func() error {
+			exist, err := b.store.StatBlob(gctx, repo, blob.Digest.String())
+			if err != nil {
+				return fmt.Errorf("failed to check blob %s: %w", blob.Digest, err)
+			}
+
+			if exist {
+				logrus.Debugf("cache: blob %s is already cached", blob.Digest)
+				pb.Complete(blob.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), blob.Digest))
+				mu.Lock()
+				report.Hits++
+				mu.Unlock()
+				return nil
+			}
+
+			if err := retry.Do(func() error {
+				return tracker.TrackTransfer(func() error {
+					return pullIfNotExist(gctx, pb, internalpb.NormalizePrompt("Fetching blob"), src, b.store, blob, repo, tag, tracker)
+				})
+			}, append(defaultRetryOpts, retry.Context(gctx))...); err != nil {
+				return fmt.Errorf("failed to fetch blob %s: %w", blob.Digest, err)
+			}
+
+			mu.Lock()
+			report.Fetched++
+			report.FetchedSize += blob.Size
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	tracker.Summary()
+	logrus.Infof("cache: warmed blobs of %s [hits: %d, fetched: %d, filtered: %d]", target, report.Hits, report.Fetched, report.Filtered)
+	return report, nil
+}
+
+// layerKind returns the kind of the layer by the media type, such as weights for
+// application/vnd.cncf.model.weight.v1.raw, or empty if the media type is unknown.
+func layerKind(mediaType string) string {
+	switch {
+	case strings.Contains(mediaType, ".model.weight.config."):
+		return config.LayerKindConfig
+	case strings.Contains(mediaType, ".model.weight."):
+		return config.LayerKindWeights
+	case strings.Contains(mediaType, ".model.code."):
+		return config.LayerKindCode
+	case strings.Contains(mediaType, ".model.doc."):
+		return config.LayerKindDoc
+	case strings.Contains(mediaType, ".model.dataset."):
+		return config.LayerKindDataset
+	default:
+		return ""
+	}
+}
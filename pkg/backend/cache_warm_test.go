@@ -0,0 +1,87 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestCacheWarm(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	repo := host + "/models/llama3"
+
+	// serve the model artifact with two weights, a weight config and a doc.
+	files := []remoteFile{
+		{"model-00001.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights 1")},
+		{"model-00002.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights 2")},
+		{"config.json", modelspec.MediaTypeModelWeightConfigRaw, []byte("{}")},
+		{"README.md", modelspec.MediaTypeModelDocRaw, []byte("# llama3")},
+	}
+	manifest, _ := serveModel(t, contents, "models/llama3", "v1", files)
+	layers, configDesc, configRaw := manifest.Layers, manifest.Config, contents["models/llama3/blobs/"+manifest.Config.Digest.String()]
+
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	// the first weight is already cached.
+	_, _, err := store.PushBlob(ctx, repo, bytes.NewReader(files[0].content), layers[0])
+	require.NoError(t, err)
+
+	cfg := config.NewCacheWarm()
+	cfg.PlainHTTP = true
+	cfg.Only = []string{config.LayerKindWeights}
+	cfg.ProgressWriter = io.Discard
+	report, err := b.CacheWarm(ctx, repo+":v1", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, &CacheWarmReport{Hits: 1, Fetched: 2, FetchedSize: layers[1].Size + configDesc.Size, Filtered: 2}, report)
+
+	// only the weights and the model config are fetched, the manifest is not stored.
+	assert.Len(t, blobs[repo], 3)
+	assert.Equal(t, files[1].content, blobs[repo][layers[1].Digest.String()])
+	assert.Equal(t, configRaw, blobs[repo][configDesc.Digest.String()])
+	assert.NotContains(t, blobs[repo], layers[2].Digest.String())
+	assert.NotContains(t, blobs[repo], layers[3].Digest.String())
+
+	// warm all the layers, the cached ones are reported as the hits.
+	cfg.Only = []string{}
+	report, err = b.CacheWarm(ctx, repo+":v1", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, &CacheWarmReport{Hits: 3, Fetched: 2, FetchedSize: layers[2].Size + layers[3].Size}, report)
+	assert.Len(t, blobs[repo], 5)
+}
+
+func TestLayerKind(t *testing.T) {
+	assert.Equal(t, config.LayerKindWeights, layerKind(modelspec.MediaTypeModelWeightRaw))
+	assert.Equal(t, config.LayerKindWeights, layerKind(modelspec.MediaTypeModelWeightZstd))
+	assert.Equal(t, config.LayerKindConfig, layerKind(modelspec.MediaTypeModelWeightConfig))
+	assert.Equal(t, config.LayerKindCode, layerKind(modelspec.MediaTypeModelCodeRaw))
+	assert.Equal(t, config.LayerKindDoc, layerKind(modelspec.MediaTypeModelDocRaw))
+	assert.Equal(t, config.LayerKindDataset, layerKind(modelspec.MediaTypeModelDatasetRaw))
+	assert.Empty(t, layerKind("application/octet-stream"))
+}
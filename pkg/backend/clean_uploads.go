@@ -0,0 +1,64 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+// CleanUploads removes the dangling uploads left by the interrupted pushes and builds
+// in the storage, and returns the removed uploads, or the uploads which would be
+// removed in the dry run mode.
+func (b *backend) CleanUploads(ctx context.Context, cfg *config.CleanUploads) ([]storage.Upload, error) {
+	uploads, err := b.store.ListUploads(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+
+	logrus.Infof("clean-uploads: found %d uploads", len(uploads))
+
+	deadline := time.Now().Add(-cfg.OlderThan)
+	cleaned := []storage.Upload{}
+	for _, upload := range uploads {
+		// the upload without the start time is always treated as dangling.
+		if !upload.StartedAt.IsZero() && upload.StartedAt.After(deadline) {
+			logrus.Debugf("clean-uploads: skipping upload %s of %s started at %s", upload.ID, upload.Repository, upload.StartedAt)
+			continue
+		}
+
+		if cfg.DryRun {
+			logrus.Infof("clean-uploads: would remove upload %s of %s", upload.ID, upload.Repository)
+		} else {
+			if err := b.store.DeleteUpload(ctx, upload.Repository, upload.ID); err != nil {
+				return cleaned, fmt.Errorf("failed to delete upload %s of %s: %w", upload.ID, upload.Repository, err)
+			}
+
+			logrus.Infof("clean-uploads: removed upload %s of %s", upload.ID, upload.Repository)
+		}
+
+		cleaned = append(cleaned, upload)
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,62 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/storage"
+	mockstore "github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestCleanUploads(t *testing.T) {
+	ctx := context.Background()
+	uploads := []storage.Upload{
+		{Repository: "example.com/models/interrupted", ID: "dangling", Size: 1024, StartedAt: time.Now().Add(-2 * time.Hour)},
+		{Repository: "example.com/models/running", ID: "running", Size: 512, StartedAt: time.Now()},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		mockStore := &mockstore.Storage{}
+		mockStore.On("ListUploads", ctx).Return(uploads, nil)
+		b := &backend{store: mockStore}
+
+		cleaned, err := b.CleanUploads(ctx, &config.CleanUploads{DryRun: true})
+		require.NoError(t, err)
+		assert.Len(t, cleaned, 2)
+		mockStore.AssertNotCalled(t, "DeleteUpload")
+	})
+
+	t.Run("older than", func(t *testing.T) {
+		mockStore := &mockstore.Storage{}
+		mockStore.On("ListUploads", ctx).Return(uploads, nil)
+		mockStore.On("DeleteUpload", ctx, "example.com/models/interrupted", "dangling").Return(nil).Once()
+		b := &backend{store: mockStore}
+
+		cleaned, err := b.CleanUploads(ctx, &config.CleanUploads{OlderThan: time.Hour})
+		require.NoError(t, err)
+		require.Len(t, cleaned, 1)
+		assert.Equal(t, "dangling", cleaned[0].ID)
+		mockStore.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,500 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/delta"
+)
+
+const (
+	// ArtifactTypeDelta is the artifact type of the delta between two model artifacts.
+	ArtifactTypeDelta = "application/vnd.cncf.modctl.delta.manifest.v1+json"
+
+	// MediaTypeDeltaConfig is the media type of the config of the delta.
+	MediaTypeDeltaConfig = "application/vnd.cncf.modctl.delta.config.v1+json"
+
+	// MediaTypeDeltaPatch is the media type of the binary patch of a file.
+	MediaTypeDeltaPatch = "application/vnd.cncf.modctl.delta.patch.v1+gzip"
+
+	// annotationDeltaBase is the annotation of the delta manifest for the base model artifact.
+	annotationDeltaBase = "org.cncf.modctl.delta.base"
+)
+
+// deltaConfig is the config of the delta, which carries the manifest and config of the derived
+// model artifact so the apply reconstructs them byte by byte.
+type deltaConfig struct {
+	// Base is the manifest digest of the base model artifact.
+	Base godigest.Digest `json:"base"`
+	// Manifest is the raw manifest of the derived model artifact.
+	Manifest []byte `json:"manifest"`
+	// Config is the raw config of the derived model artifact.
+	Config []byte `json:"config"`
+	// Patches is the patches of the changed files.
+	Patches []deltaPatch `json:"patches"`
+}
+
+// deltaPatch is the patch reconstructing the layer of the derived model artifact from the
+// layer of the same filepath in the base model artifact.
+type deltaPatch struct {
+	// Target is the digest of the layer in the derived model artifact.
+	Target godigest.Digest `json:"target"`
+	// Base is the digest of the layer in the base model artifact.
+	Base godigest.Digest `json:"base"`
+	// Patch is the digest of the patch blob.
+	Patch godigest.Digest `json:"patch"`
+}
+
+// Delta creates the delta between the base and derived model artifacts in the local storage as
+// the target. The layers unchanged are referenced from the base, the changed files are stored as
+// the binary patches against the files of the same filepath in the base, and the other layers are
+// stored as is.
+func (b *backend) Delta(ctx context.Context, base, derived, target string, cfg *config.Delta) error {
+	logrus.Infof("delta: creating delta from %s to %s as %s", base, derived, target)
+	baseRef, derivedRef, targetRef, err := parseDeltaReferences(base, derived, target)
+	if err != nil {
+		return err
+	}
+
+	baseManifestRaw, baseDigest, err := b.store.PullManifest(ctx, baseRef.Repository(), baseRef.Tag())
+	if err != nil {
+		return fmt.Errorf("failed to pull base manifest: %w", err)
+	}
+
+	var baseManifest ocispec.Manifest
+	if err := json.Unmarshal(baseManifestRaw, &baseManifest); err != nil {
+		return fmt.Errorf("failed to unmarshal base manifest: %w", err)
+	}
+
+	derivedManifestRaw, _, err := b.store.PullManifest(ctx, derivedRef.Repository(), derivedRef.Tag())
+	if err != nil {
+		return fmt.Errorf("failed to pull derived manifest: %w", err)
+	}
+
+	var derivedManifest ocispec.Manifest
+	if err := json.Unmarshal(derivedManifestRaw, &derivedManifest); err != nil {
+		return fmt.Errorf("failed to unmarshal derived manifest: %w", err)
+	}
+
+	derivedConfig, err := b.readBlob(ctx, derivedRef.Repository(), derivedManifest.Config)
+	if err != nil {
+		return fmt.Errorf("failed to read derived config: %w", err)
+	}
+
+	baseDigests := map[godigest.Digest]bool{}
+	baseLayers := map[string]ocispec.Descriptor{}
+	for _, layer := range baseManifest.Layers {
+		baseDigests[layer.Digest] = true
+		baseLayers[layerFilepath(layer)] = layer
+	}
+
+	// collect the results by the index of the layers to keep the order regardless of the concurrency.
+	results := make([]*ocispec.Descriptor, len(derivedManifest.Layers))
+	patchResults := make([]*deltaPatch, len(derivedManifest.Layers))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.Concurrency)
+	for i, layer := range derivedManifest.Layers {
+		filepath := layerFilepath(layer)
+		if baseDigests[layer.Digest] {
+			logrus.Debugf("delta: referenced unchanged file %s from base", filepath)
+			continue
+		}
+
+		g.Go(func() error {
+			if baseLayer, ok := baseLayers[filepath]; ok && filepath != "" {
+				patchDesc, err := b.createPatch(gctx, baseRef.Repository(), baseLayer, derivedRef.Repository(), layer, targetRef.Repository())
+				if err != nil {
+					return fmt.Errorf("failed to create patch for %s: %w", filepath, err)
+				}
+
+				if patchDesc != nil {
+					logrus.Infof("delta: created patch for %s [size: %d, original: %d]", filepath, patchDesc.Size, layer.Size)
+					results[i] = patchDesc
+					patchResults[i] = &deltaPatch{Target: layer.Digest, Base: baseLayer.Digest, Patch: patchDesc.Digest}
+					return nil
+				}
+			}
+
+			logrus.Infof("delta: stored file %s as is", filepath)
+			if err := b.store.MountBlob(gctx, derivedRef.Repository(), targetRef.Repository(), layer); err != nil {
+				return fmt.Errorf("failed to mount blob %s: %w", layer.Digest, err)
+			}
+
+			results[i] = &layer
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	patches := []deltaPatch{}
+	layers := []ocispec.Descriptor{}
+	for i := range results {
+		if patchResults[i] != nil {
+			patches = append(patches, *patchResults[i])
+		}
+
+		if results[i] != nil {
+			layers = append(layers, *results[i])
+		}
+	}
+
+	configRaw, err := json.Marshal(deltaConfig{
+		Base:     godigest.Digest(baseDigest),
+		Manifest: derivedManifestRaw,
+		Config:   derivedConfig,
+		Patches:  patches,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal delta config: %w", err)
+	}
+
+	configDesc := ocispec.Descriptor{MediaType: MediaTypeDeltaConfig, Digest: checksum.FromBytes(configRaw), Size: int64(len(configRaw))}
+	if _, _, err := b.store.PushBlob(ctx, targetRef.Repository(), bytes.NewReader(configRaw), configDesc); err != nil {
+		return fmt.Errorf("failed to push delta config: %w", err)
+	}
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: ArtifactTypeDelta,
+		Config:       configDesc,
+		Layers:       layers,
+		Annotations:  map[string]string{annotationDeltaBase: baseDigest},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal delta manifest: %w", err)
+	}
+
+	if _, err := b.store.PushManifest(ctx, targetRef.Repository(), targetRef.Tag(), ocispec.MediaTypeImageManifest, manifestRaw); err != nil {
+		return fmt.Errorf("failed to push delta manifest: %w", err)
+	}
+
+	logrus.Infof("delta: created delta %s with %d patches and %d files", target, len(patches), len(layers)-len(patches))
+	return nil
+}
+
+// ApplyDelta reconstructs the derived model artifact from the base and the delta as the target,
+// the reconstructed layers, config and manifest are identical to the derived ones.
+func (b *backend) ApplyDelta(ctx context.Context, base, deltaTarget, target string, cfg *config.Delta) error {
+	logrus.Infof("delta: applying delta %s to %s as %s", deltaTarget, base, target)
+	baseRef, deltaRef, targetRef, err := parseDeltaReferences(base, deltaTarget, target)
+	if err != nil {
+		return err
+	}
+
+	deltaManifestRaw, _, err := b.store.PullManifest(ctx, deltaRef.Repository(), deltaRef.Tag())
+	if err != nil {
+		return fmt.Errorf("failed to pull delta manifest: %w", err)
+	}
+
+	var deltaManifest ocispec.Manifest
+	if err := json.Unmarshal(deltaManifestRaw, &deltaManifest); err != nil {
+		return fmt.Errorf("failed to unmarshal delta manifest: %w", err)
+	}
+
+	if deltaManifest.ArtifactType != ArtifactTypeDelta {
+		return fmt.Errorf("%s is not a delta, the artifact type is %q", deltaTarget, deltaManifest.ArtifactType)
+	}
+
+	deltaConfigRaw, err := b.readBlob(ctx, deltaRef.Repository(), deltaManifest.Config)
+	if err != nil {
+		return fmt.Errorf("failed to read delta config: %w", err)
+	}
+
+	var dc deltaConfig
+	if err := json.Unmarshal(deltaConfigRaw, &dc); err != nil {
+		return fmt.Errorf("failed to unmarshal delta config: %w", err)
+	}
+
+	baseManifestRaw, baseDigest, err := b.store.PullManifest(ctx, baseRef.Repository(), baseRef.Tag())
+	if err != nil {
+		return fmt.Errorf("failed to pull base manifest: %w", err)
+	}
+
+	if godigest.Digest(baseDigest) != dc.Base {
+		return fmt.Errorf("base %s (%s) does not match the base of the delta (%s)", base, baseDigest, dc.Base)
+	}
+
+	var baseManifest ocispec.Manifest
+	if err := json.Unmarshal(baseManifestRaw, &baseManifest); err != nil {
+		return fmt.Errorf("failed to unmarshal base manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(dc.Manifest, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal derived manifest: %w", err)
+	}
+
+	baseLayers := map[godigest.Digest]ocispec.Descriptor{}
+	for _, layer := range baseManifest.Layers {
+		baseLayers[layer.Digest] = layer
+	}
+
+	deltaLayers := map[godigest.Digest]ocispec.Descriptor{}
+	for _, layer := range deltaManifest.Layers {
+		deltaLayers[layer.Digest] = layer
+	}
+
+	patches := map[godigest.Digest]deltaPatch{}
+	for _, patch := range dc.Patches {
+		patches[patch.Target] = patch
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.Concurrency)
+	for _, layer := range manifest.Layers {
+		g.Go(func() error {
+			exist, err := b.store.StatBlob(gctx, targetRef.Repository(), layer.Digest.String())
+			if err != nil {
+				return fmt.Errorf("failed to stat blob %s: %w", layer.Digest, err)
+			}
+
+			switch patch, patched := patches[layer.Digest]; {
+			case exist:
+			case baseLayers[layer.Digest].Digest != "":
+				err = b.store.MountBlob(gctx, baseRef.Repository(), targetRef.Repository(), layer)
+			case patched:
+				baseLayer, ok := baseLayers[patch.Base]
+				if !ok {
+					return fmt.Errorf("base layer %s of the patch is missing in %s", patch.Base, base)
+				}
+
+				patchLayer, ok := deltaLayers[patch.Patch]
+				if !ok {
+					return fmt.Errorf("patch %s is missing in %s", patch.Patch, deltaTarget)
+				}
+
+				err = b.applyPatch(gctx, baseRef.Repository(), baseLayer, deltaRef.Repository(), patchLayer, targetRef.Repository(), layer)
+			case deltaLayers[layer.Digest].Digest != "":
+				err = b.store.MountBlob(gctx, deltaRef.Repository(), targetRef.Repository(), layer)
+			default:
+				return fmt.Errorf("layer %s is missing in both the base and the delta", layer.Digest)
+			}
+
+			if err != nil {
+				return fmt.Errorf("failed to reconstruct %s: %w", layerFilepath(layer), err)
+			}
+
+			logrus.Debugf("delta: reconstructed %s", layerFilepath(layer))
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if _, _, err := b.store.PushBlob(ctx, targetRef.Repository(), bytes.NewReader(dc.Config), manifest.Config); err != nil {
+		return fmt.Errorf("failed to push config: %w", err)
+	}
+
+	digest, err := b.store.PushManifest(ctx, targetRef.Repository(), targetRef.Tag(), manifest.MediaType, dc.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to push manifest: %w", err)
+	}
+
+	logrus.Infof("delta: applied delta %s as %s [digest: %s]", deltaTarget, target, digest)
+	return nil
+}
+
+// createPatch diffs the derived layer against the base layer and pushes the patch to the target
+// repository, it returns nil if the patch is not smaller than the derived layer.
+func (b *backend) createPatch(ctx context.Context, baseRepo string, baseLayer ocispec.Descriptor, derivedRepo string, layer ocispec.Descriptor, targetRepo string) (*ocispec.Descriptor, error) {
+	baseReader, closeBase, err := b.blobReaderAt(ctx, baseRepo, baseLayer)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBase()
+
+	derivedReader, closeDerived, err := b.blobReaderAt(ctx, derivedRepo, layer)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDerived()
+
+	patchFile, err := os.CreateTemp("", "modctl-delta-*.patch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create patch file: %w", err)
+	}
+	defer os.Remove(patchFile.Name())
+	defer patchFile.Close()
+
+	hash := checksum.NewSHA256()
+	if err := delta.Encode(io.MultiWriter(patchFile, hash), baseReader, baseLayer.Size, derivedReader, layer.Size); err != nil {
+		return nil, fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	size, err := patchFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	if size >= layer.Size {
+		return nil, nil
+	}
+
+	if _, err := patchFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: MediaTypeDeltaPatch,
+		Digest:    godigest.NewDigest(godigest.SHA256, hash),
+		Size:      size,
+	}
+	if _, _, err := b.store.PushBlob(ctx, targetRepo, patchFile, desc); err != nil {
+		return nil, fmt.Errorf("failed to push patch: %w", err)
+	}
+
+	return &desc, nil
+}
+
+// applyPatch reconstructs the layer from the base layer and the patch, and pushes it to the
+// target repository after verifying the digest.
+func (b *backend) applyPatch(ctx context.Context, baseRepo string, baseLayer ocispec.Descriptor, deltaRepo string, patchLayer ocispec.Descriptor, targetRepo string, layer ocispec.Descriptor) error {
+	baseReader, closeBase, err := b.blobReaderAt(ctx, baseRepo, baseLayer)
+	if err != nil {
+		return err
+	}
+	defer closeBase()
+
+	patchReader, err := b.store.PullBlob(ctx, deltaRepo, patchLayer.Digest.String())
+	if err != nil {
+		return fmt.Errorf("failed to pull patch: %w", err)
+	}
+	defer patchReader.Close()
+
+	pr, pw := io.Pipe()
+	verifier := layer.Digest.Verifier()
+	go func() {
+		pw.CloseWithError(delta.Decode(io.MultiWriter(pw, verifier), baseReader, patchReader))
+	}()
+	defer pr.Close()
+
+	if _, _, err := b.store.PushBlob(ctx, targetRepo, pr, layer); err != nil {
+		return fmt.Errorf("failed to push blob: %w", err)
+	}
+
+	if !verifier.Verified() {
+		return fmt.Errorf("digest of the reconstructed blob mismatches %s", layer.Digest)
+	}
+
+	return nil
+}
+
+// blobReaderAt opens the blob for the random access, the blob is spooled to a temporary file
+// if the storage does not support seeking.
+func (b *backend) blobReaderAt(ctx context.Context, repo string, desc ocispec.Descriptor) (io.ReaderAt, func(), error) {
+	reader, err := b.store.PullBlob(ctx, repo, desc.Digest.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pull blob %s: %w", desc.Digest, err)
+	}
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		return &seekReaderAt{rs: seeker}, func() { reader.Close() }, nil
+	}
+	defer reader.Close()
+
+	file, err := os.CreateTemp("", "modctl-delta-*.blob")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	if _, err := io.Copy(file, reader); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to spool blob %s: %w", desc.Digest, err)
+	}
+
+	return file, cleanup, nil
+}
+
+// readBlob reads the whole blob, which is used for the small blobs like the config.
+func (b *backend) readBlob(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	reader, err := b.store.PullBlob(ctx, repo, desc.Digest.String())
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// seekReaderAt adapts the io.ReadSeeker to the io.ReaderAt.
+type seekReaderAt struct {
+	mu sync.Mutex
+	rs io.ReadSeeker
+}
+
+// ReadAt reads len(p) bytes at the offset.
+func (s *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	n, err := io.ReadFull(s.rs, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// parseDeltaReferences parses the three references of the delta operations, all of them must be tagged.
+func parseDeltaReferences(targets ...string) (Referencer, Referencer, Referencer, error) {
+	refs := make([]Referencer, 0, len(targets))
+	for _, target := range targets {
+		ref, err := ParseReference(target)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to parse %s: %w", target, err)
+		}
+
+		if ref.Repository() == "" || ref.Tag() == "" {
+			return nil, nil, nil, fmt.Errorf("invalid repository or tag of %s", target)
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs[0], refs[1], refs[2], nil
+}
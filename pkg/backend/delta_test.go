@@ -0,0 +1,298 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/storage"
+	mockstore "github.com/modelpack/modctl/test/mocks/storage"
+)
+
+// newMemoryStore returns the mock storage backed by the in-memory blobs and manifests.
+func newMemoryStore() (*mockstore.Storage, map[string]map[string][]byte) {
+	var mu sync.Mutex
+	blobs := map[string]map[string][]byte{}
+	manifests := map[string]map[string][]byte{}
+	put := func(m map[string]map[string][]byte, repo, key string, content []byte) {
+		if m[repo] == nil {
+			m[repo] = map[string][]byte{}
+		}
+		m[repo][key] = content
+	}
+
+	s := &mockstore.Storage{}
+	s.On("PullManifest", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo, reference string) ([]byte, string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			content, ok := manifests[repo][reference]
+			if !ok {
+				return nil, "", fmt.Errorf("manifest %s:%s %w", repo, reference, storage.ErrNotFound)
+			}
+
+			return content, godigest.FromBytes(content).String(), nil
+		},
+	)
+	s.On("PushManifest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo, reference, mediaType string, body []byte) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			put(manifests, repo, reference, body)
+			return godigest.FromBytes(body).String(), nil
+		},
+	)
+	s.On("StatManifest", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo, digest string) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, content := range manifests[repo] {
+				if godigest.FromBytes(content).String() == digest {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		},
+	)
+	s.On("DeleteManifest", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo, reference string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, err := godigest.Parse(reference); err != nil {
+				delete(manifests[repo], reference)
+				return nil
+			}
+
+			for key, content := range manifests[repo] {
+				if godigest.FromBytes(content).String() == reference {
+					delete(manifests[repo], key)
+				}
+			}
+
+			return nil
+		},
+	)
+	s.On("ListRepositories", mock.Anything).Return(
+		func(ctx context.Context) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			repos := []string{}
+			for repo := range manifests {
+				repos = append(repos, repo)
+			}
+
+			sort.Strings(repos)
+			return repos, nil
+		},
+	)
+	s.On("ListTags", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo string) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			tags := []string{}
+			for tag := range manifests[repo] {
+				tags = append(tags, tag)
+			}
+
+			sort.Strings(tags)
+			return tags, nil
+		},
+	)
+	s.On("PullBlob", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo, digest string) (io.ReadCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			content, ok := blobs[repo][digest]
+			if !ok {
+				return nil, fmt.Errorf("blob %s not found in %s", digest, repo)
+			}
+
+			return io.NopCloser(bytes.NewReader(content)), nil
+		},
+	)
+	s.On("PushBlob", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo string, body io.Reader, desc ocispec.Descriptor) (string, int64, error) {
+			content, err := io.ReadAll(body)
+			if err != nil {
+				return "", 0, err
+			}
+
+			digest := godigest.FromBytes(content)
+			if desc.Digest != "" && desc.Digest != digest {
+				return "", 0, fmt.Errorf("digest mismatch %s != %s", desc.Digest, digest)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			put(blobs, repo, digest.String(), content)
+			return digest.String(), int64(len(content)), nil
+		},
+	)
+	s.On("MountBlob", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fromRepo, toRepo string, desc ocispec.Descriptor) error {
+			mu.Lock()
+			defer mu.Unlock()
+			content, ok := blobs[fromRepo][desc.Digest.String()]
+			if !ok {
+				return fmt.Errorf("blob %s not found in %s", desc.Digest, fromRepo)
+			}
+
+			put(blobs, toRepo, desc.Digest.String(), content)
+			return nil
+		},
+	)
+	s.On("StatBlob", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, repo, digest string) (bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			_, ok := blobs[repo][digest]
+			return ok, nil
+		},
+	)
+
+	return s, blobs
+}
+
+// storeModel stores the model artifact of the files into the store.
+func storeModel(t *testing.T, b *backend, repo, tag string, files map[string][]byte, order []string) []byte {
+	ctx := context.Background()
+	layers := []ocispec.Descriptor{}
+	for _, name := range order {
+		content := files[name]
+		desc := ocispec.Descriptor{
+			MediaType:   modelspec.MediaTypeModelWeightRaw,
+			Digest:      godigest.FromBytes(content),
+			Size:        int64(len(content)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		}
+		_, _, err := b.store.PushBlob(ctx, repo, bytes.NewReader(content), desc)
+		require.NoError(t, err)
+		layers = append(layers, desc)
+	}
+
+	configRaw := []byte(fmt.Sprintf(`{"descriptor":{"name":%q},"modelfs":{"type":"layers"}}`, tag))
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configRaw), Size: int64(len(configRaw))}
+	_, _, err := b.store.PushBlob(ctx, repo, bytes.NewReader(configRaw), configDesc)
+	require.NoError(t, err)
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: modelspec.ArtifactTypeModelManifest,
+		Config:       configDesc,
+		Layers:       layers,
+	})
+	require.NoError(t, err)
+
+	_, err = b.store.PushManifest(ctx, repo, tag, ocispec.MediaTypeImageManifest, manifestRaw)
+	require.NoError(t, err)
+	return manifestRaw
+}
+
+func TestDeltaAndApply(t *testing.T) {
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	r := rand.New(rand.NewSource(1))
+	weight := make([]byte, 1<<20)
+	r.Read(weight)
+
+	// fine-tuning changes the weights slightly in place.
+	tuned := bytes.Clone(weight)
+	for i := 0; i < len(tuned); i += 97 {
+		tuned[i]++
+	}
+
+	storeModel(t, b, "example.com/models/base", "v1", map[string][]byte{
+		"model.safetensors": weight,
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+		"README.md":         []byte("# base"),
+	}, []string{"model.safetensors", "config.json", "README.md"})
+
+	derivedFiles := map[string][]byte{
+		"model.safetensors":   tuned,
+		"config.json":         []byte(`{"hidden_size": 4096}`),
+		"README.md":           []byte("# fine-tuned"),
+		"adapter.safetensors": []byte("adapter"),
+	}
+	derivedManifestRaw := storeModel(t, b, "example.com/models/tuned", "v1", derivedFiles, []string{"model.safetensors", "config.json", "README.md", "adapter.safetensors"})
+
+	cfg := config.NewDelta()
+	require.NoError(t, b.Delta(ctx, "example.com/models/base:v1", "example.com/models/tuned:v1", "example.com/models/delta:v1", cfg))
+
+	deltaManifestRaw, _, err := store.PullManifest(ctx, "example.com/models/delta", "v1")
+	require.NoError(t, err)
+
+	var deltaManifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(deltaManifestRaw, &deltaManifest))
+	assert.Equal(t, ArtifactTypeDelta, deltaManifest.ArtifactType)
+
+	// the weights are patched, the small files are stored as is and the unchanged config.json is referenced.
+	require.Len(t, deltaManifest.Layers, 3)
+	assert.Equal(t, MediaTypeDeltaPatch, deltaManifest.Layers[0].MediaType)
+	assert.Less(t, deltaManifest.Layers[0].Size, int64(len(tuned)/4))
+	assert.Equal(t, "README.md", layerFilepath(deltaManifest.Layers[1]))
+	assert.Equal(t, "adapter.safetensors", layerFilepath(deltaManifest.Layers[2]))
+
+	t.Run("apply", func(t *testing.T) {
+		require.NoError(t, b.ApplyDelta(ctx, "example.com/models/base:v1", "example.com/models/delta:v1", "example.com/models/restored:v1", cfg))
+
+		restoredManifestRaw, _, err := store.PullManifest(ctx, "example.com/models/restored", "v1")
+		require.NoError(t, err)
+		assert.Equal(t, derivedManifestRaw, restoredManifestRaw)
+
+		var restored ocispec.Manifest
+		require.NoError(t, json.Unmarshal(restoredManifestRaw, &restored))
+		for _, layer := range restored.Layers {
+			content, ok := blobs["example.com/models/restored"][layer.Digest.String()]
+			require.True(t, ok, layerFilepath(layer))
+			assert.True(t, bytes.Equal(derivedFiles[layerFilepath(layer)], content), layerFilepath(layer))
+		}
+
+		assert.Equal(t, blobs["example.com/models/tuned"][restored.Config.Digest.String()], blobs["example.com/models/restored"][restored.Config.Digest.String()])
+	})
+
+	t.Run("apply to mismatched base", func(t *testing.T) {
+		storeModel(t, b, "example.com/models/other", "v1", map[string][]byte{"model.safetensors": weight}, []string{"model.safetensors"})
+		err := b.ApplyDelta(ctx, "example.com/models/other:v1", "example.com/models/delta:v1", "example.com/models/restored:v2", cfg)
+		assert.ErrorContains(t, err, "does not match the base of the delta")
+	})
+
+	t.Run("apply non-delta", func(t *testing.T) {
+		err := b.ApplyDelta(ctx, "example.com/models/base:v1", "example.com/models/tuned:v1", "example.com/models/restored:v3", cfg)
+		assert.ErrorContains(t, err, "is not a delta")
+	})
+}
@@ -0,0 +1,337 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/canonicaljson"
+	"github.com/modelpack/modctl/pkg/checksum"
+	pkgcodec "github.com/modelpack/modctl/pkg/codec"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/encryption"
+	"github.com/modelpack/modctl/pkg/license"
+	modelfilecommand "github.com/modelpack/modctl/pkg/modelfile/command"
+)
+
+// modelfileFileCommands is the Modelfile commands specifying the files of the model artifact.
+var modelfileFileCommands = []string{
+	modelfilecommand.CONFIG,
+	modelfilecommand.MODEL,
+	modelfilecommand.CODE,
+	modelfilecommand.DATASET,
+	modelfilecommand.DOC,
+}
+
+// Detach removes the file from the model artifact by dropping its layer from the manifest and its
+// diff id from the config, the other blobs are reused as is. The detached artifact is tagged by the
+// target, or the source tag if the target is empty. The blob of the removed layer is kept in the
+// storage as it may be referenced by the other artifacts, unless the gc is enabled.
+func (b *backend) Detach(ctx context.Context, source, filePath string, cfg *config.Detach) error {
+	logrus.Infof("detach: removing %s from %s", filePath, source)
+	srcRef, err := ParseReference(source)
+	if err != nil {
+		return fmt.Errorf("failed to parse source: %w", err)
+	}
+
+	target := source
+	if cfg.Target != "" {
+		target = cfg.Target
+	}
+
+	targetRef, err := ParseReference(target)
+	if err != nil {
+		return fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	if targetRef.Tag() == "" {
+		return fmt.Errorf("the target %s must be tagged", target)
+	}
+
+	reference := srcRef.Tag()
+	if srcRef.Digest() != "" {
+		reference = srcRef.Digest()
+	}
+
+	manifestRaw, srcDigest, err := b.store.PullManifest(ctx, srcRef.Repository(), reference)
+	if err != nil {
+		return fmt.Errorf("failed to pull manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	filePath = path.Clean(filepath.ToSlash(filePath))
+	index := slices.IndexFunc(manifest.Layers, func(layer ocispec.Descriptor) bool {
+		return layerFilepath(layer) == filePath
+	})
+	if index < 0 {
+		return fmt.Errorf("file %s is not found in %s", filePath, source)
+	}
+
+	removed := manifest.Layers[index]
+	layers := slices.Delete(slices.Clone(manifest.Layers), index, index+1)
+	if len(layers) == 0 {
+		return fmt.Errorf("file %s is the only file in %s, remove the model artifact instead", filePath, source)
+	}
+
+	// keep the layer order chosen at build time and the load order of the remaining weights.
+	orderLayers(layers, manifest.Annotations[annotationLayerOrder])
+	renumberLoadOrder(layers)
+
+	configReader, err := b.store.PullBlob(ctx, srcRef.Repository(), manifest.Config.Digest.String())
+	if err != nil {
+		return fmt.Errorf("failed to pull config: %w", err)
+	}
+	defer configReader.Close()
+
+	srcConfigRaw, err := io.ReadAll(configReader)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var modelConfig modelspec.Model
+	if err := json.Unmarshal(srcConfigRaw, &modelConfig); err != nil {
+		return fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	modelConfig.ModelFS.DiffIDs = make([]godigest.Digest, 0, len(layers))
+	for _, layer := range layers {
+		modelConfig.ModelFS.DiffIDs = append(modelConfig.ModelFS.DiffIDs, layer.Digest)
+	}
+
+	// keep the JSON format of the source, such as the canonical JSON chosen at build time.
+	configRaw, err := marshalJSONLike(srcConfigRaw, modelConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// mount the blobs if the detached artifact is tagged in another repository.
+	if targetRef.Repository() != srcRef.Repository() {
+		for _, desc := range layers {
+			if err := b.store.MountBlob(ctx, srcRef.Repository(), targetRef.Repository(), desc); err != nil {
+				return fmt.Errorf("failed to mount blob %s: %w", desc.Digest, err)
+			}
+		}
+	}
+
+	configDesc := ocispec.Descriptor{
+		MediaType: manifest.Config.MediaType,
+		Digest:    checksum.FromBytes(configRaw),
+		Size:      int64(len(configRaw)),
+	}
+	if _, _, err := b.store.PushBlob(ctx, targetRef.Repository(), bytes.NewReader(configRaw), configDesc); err != nil {
+		return fmt.Errorf("failed to push config: %w", err)
+	}
+
+	manifest.Config = configDesc
+	manifest.Layers = layers
+	// Update the merkle root as the layers are changed.
+	if _, ok := manifest.Annotations[checksum.AnnotationMerkleRoot]; ok {
+		manifest.Annotations[checksum.AnnotationMerkleRoot] = merkleRoot(layers)
+	}
+
+	// Drop the detached file from the Modelfile and the licenses recorded at build time.
+	if content, ok := manifest.Annotations[annotationModelfile]; ok {
+		manifest.Annotations[annotationModelfile] = detachModelfileFile(content, filePath)
+	}
+
+	if _, ok := manifest.Annotations[ocispec.AnnotationLicenses]; ok && license.IsLicenseFile(filePath) {
+		licenses, err := b.detectStoredLicenses(ctx, srcRef.Repository(), layers)
+		if err != nil {
+			return err
+		}
+
+		if licenses == "" {
+			delete(manifest.Annotations, ocispec.AnnotationLicenses)
+		} else {
+			manifest.Annotations[ocispec.AnnotationLicenses] = licenses
+		}
+	}
+
+	detachedRaw, err := marshalJSONLike(manifestRaw, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	digest, err := b.store.PushManifest(ctx, targetRef.Repository(), targetRef.Tag(), manifest.MediaType, detachedRaw)
+	if err != nil {
+		return fmt.Errorf("failed to push manifest: %w", err)
+	}
+
+	logrus.Infof("detach: removed %s as %s [manifest: %s, blob: %s]", filePath, target, digest, removed.Digest)
+	if cfg.GC {
+		if err := b.collectDetached(ctx, srcRef.Repository(), srcDigest); err != nil {
+			return fmt.Errorf("failed to garbage collect: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// detachModelfileFile removes the directives of the file from the Modelfile content, the
+// directives of the patterns are kept as they may match the other files.
+func detachModelfileFile(content, filePath string) string {
+	lines := strings.SplitAfter(content, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		index := strings.IndexAny(trimmed, " \t")
+		if index < 0 {
+			kept = append(kept, line)
+			continue
+		}
+
+		cmd, arg := strings.ToUpper(trimmed[:index]), strings.TrimSpace(trimmed[index:])
+		if len(arg) >= 2 && strings.HasPrefix(arg, `"`) && strings.HasSuffix(arg, `"`) {
+			arg = strings.ReplaceAll(arg[1:len(arg)-1], `\"`, `"`)
+		}
+
+		if slices.Contains(modelfileFileCommands, cmd) && path.Clean(filepath.ToSlash(arg)) == filePath {
+			continue
+		}
+
+		kept = append(kept, line)
+	}
+
+	return strings.Join(kept, "")
+}
+
+// detectStoredLicenses detects the licenses from the license files of the layers in the storage,
+// the license files in the encrypted layers and the archived layers are skipped as on build.
+func (b *backend) detectStoredLicenses(ctx context.Context, repo string, layers []ocispec.Descriptor) (string, error) {
+	ids := map[string]struct{}{}
+	for _, layer := range layers {
+		path := layerFilepath(layer)
+		if !license.IsLicenseFile(path) || pkgcodec.TypeFromMediaType(layer.MediaType) != pkgcodec.Raw {
+			continue
+		}
+
+		if _, ok := layer.Annotations[encryption.AnnotationEncryption]; ok {
+			continue
+		}
+
+		reader, err := b.store.PullBlob(ctx, repo, layer.Digest.String())
+		if err != nil {
+			return "", fmt.Errorf("failed to pull license file %s: %w", path, err)
+		}
+
+		content, err := io.ReadAll(io.LimitReader(reader, maxLicenseFileSize))
+		reader.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read license file %s: %w", path, err)
+		}
+
+		if id := license.Detect(content); id != "" {
+			ids[id] = struct{}{}
+		}
+	}
+
+	return licenseExpression(ids), nil
+}
+
+// marshalJSONLike marshals the value in the JSON format of the source, which is the canonical
+// JSON or the standard one, compact or indented as the source, so the artifacts built with
+// --canonical-json and --json-indent keep their format after the modification.
+func marshalJSONLike(source []byte, v any) ([]byte, error) {
+	indent := 0
+	if _, rest, ok := bytes.Cut(source, []byte("\n")); ok {
+		indent = len(rest) - len(bytes.TrimLeft(rest, " "))
+	}
+
+	if canonical, err := canonicaljson.MarshalIndent(json.RawMessage(source), indent); err == nil && bytes.Equal(canonical, source) {
+		return canonicaljson.MarshalIndent(v, indent)
+	}
+
+	if indent > 0 {
+		return json.MarshalIndent(v, "", strings.Repeat(" ", indent))
+	}
+
+	return json.Marshal(v)
+}
+
+// renumberLoadOrder renumbers the load order of the weight layers recorded on build to close the
+// gap of the removed weight, the relative order is kept.
+func renumberLoadOrder(layers []ocispec.Descriptor) {
+	var weights []int
+	for i, layer := range layers {
+		if loadOrder(layer) != nil {
+			weights = append(weights, i)
+		}
+	}
+
+	sort.SliceStable(weights, func(i, j int) bool {
+		return *loadOrder(layers[weights[i]]) < *loadOrder(layers[weights[j]])
+	})
+
+	for index, i := range weights {
+		layers[i].Annotations[annotationLoadOrder] = strconv.Itoa(index)
+	}
+}
+
+// collectDetached deletes the source manifest if it is no longer tagged after the detach, then
+// garbage collects the blobs which are not referenced by any manifest in the storage.
+func (b *backend) collectDetached(ctx context.Context, repo, digest string) error {
+	tags, err := b.store.ListTags(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	tagged := false
+	for _, tag := range tags {
+		_, tagDigest, err := b.store.PullManifest(ctx, repo, tag)
+		if err != nil {
+			return fmt.Errorf("failed to pull manifest %s: %w", tag, err)
+		}
+
+		if tagDigest == digest {
+			logrus.Infof("detach: keep the source manifest %s tagged as %s", digest, tag)
+			tagged = true
+			break
+		}
+	}
+
+	if !tagged {
+		if err := b.store.DeleteManifest(ctx, repo, digest); err != nil {
+			return fmt.Errorf("failed to delete manifest %s: %w", digest, err)
+		}
+	}
+
+	if err := b.store.PerformGC(ctx, false, false); err != nil {
+		return fmt.Errorf("failed to perform gc: %w", err)
+	}
+
+	logrus.Infof("detach: garbage collected the unreferenced blobs")
+	return nil
+}
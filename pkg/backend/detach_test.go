@@ -0,0 +1,233 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/canonicaljson"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestDetach(t *testing.T) {
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	repo := "example.com/models/llama3"
+	manifestRaw := storeModel(t, b, repo, "v1", map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+		"README.md":         []byte("# llama3"),
+	}, []string{"model.safetensors", "config.json", "README.md"})
+
+	var original ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &original))
+
+	pullManifest := func(t *testing.T, repo, tag string) ocispec.Manifest {
+		raw, _, err := store.PullManifest(ctx, repo, tag)
+		require.NoError(t, err)
+
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(raw, &manifest))
+		return manifest
+	}
+
+	require.NoError(t, b.Detach(ctx, repo+":v1", "README.md", config.NewDetach()))
+
+	detached := pullManifest(t, repo, "v1")
+	require.Len(t, detached.Layers, 2)
+	var digests []godigest.Digest
+	for _, layer := range detached.Layers {
+		assert.NotEqual(t, "README.md", layerFilepath(layer))
+		digests = append(digests, layer.Digest)
+	}
+
+	// the config is rebuilt with the diff ids of the remaining layers.
+	assert.NotEqual(t, original.Config.Digest, detached.Config.Digest)
+	var modelConfig modelspec.Model
+	require.NoError(t, json.Unmarshal(blobs[repo][detached.Config.Digest.String()], &modelConfig))
+	assert.Equal(t, digests, modelConfig.ModelFS.DiffIDs)
+	assert.Equal(t, "v1", modelConfig.Descriptor.Name)
+
+	// the blob of the removed file is kept without gc.
+	assert.Contains(t, blobs[repo], godigest.FromString("# llama3").String())
+
+	outputDir := t.TempDir()
+	require.NoError(t, b.Extract(ctx, repo+":v1", &config.Extract{Concurrency: 1, Output: outputDir}))
+	assert.FileExists(t, filepath.Join(outputDir, "model.safetensors"))
+	assert.FileExists(t, filepath.Join(outputDir, "config.json"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "README.md"))
+
+	t.Run("as target", func(t *testing.T) {
+		cfg := &config.Detach{Target: "example.com/models/detached:v1"}
+		require.NoError(t, b.Detach(ctx, repo+":v1", "./config.json", cfg))
+
+		moved := pullManifest(t, "example.com/models/detached", "v1")
+		require.Len(t, moved.Layers, 1)
+		assert.Equal(t, "model.safetensors", layerFilepath(moved.Layers[0]))
+		assert.Len(t, pullManifest(t, repo, "v1").Layers, 2)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := b.Detach(ctx, repo+":v1", "README.md", config.NewDetach())
+		assert.ErrorContains(t, err, "is not found")
+	})
+
+	t.Run("only file", func(t *testing.T) {
+		err := b.Detach(ctx, "example.com/models/detached:v1", "model.safetensors", config.NewDetach())
+		assert.ErrorContains(t, err, "is the only file")
+	})
+}
+
+func TestDetachAnnotations(t *testing.T) {
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	repo := "example.com/models/llama3"
+	manifestRaw := storeModel(t, b, repo, "v1", map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"LICENSE":           []byte(apacheLicenseText),
+		"LICENSE-MIT":       []byte(mitLicenseText),
+	}, []string{"model.safetensors", "LICENSE", "LICENSE-MIT"})
+
+	// annotate the manifest as built with --canonical-json and --json-indent.
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+	manifest.Annotations = map[string]string{
+		ocispec.AnnotationLicenses: "Apache-2.0 OR MIT",
+		annotationModelfile:        "# Model files\nMODEL model.safetensors\n\n# Documentation files\nDOC LICENSE\nDOC LICENSE-MIT\nDOC *.md\n",
+	}
+	manifestRaw, err := canonicaljson.MarshalIndent(manifest, 2)
+	require.NoError(t, err)
+	_, err = store.PushManifest(ctx, repo, "v1", ocispec.MediaTypeImageManifest, manifestRaw)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Detach(ctx, repo+":v1", "LICENSE-MIT", config.NewDetach()))
+
+	detachedRaw, _, err := store.PullManifest(ctx, repo, "v1")
+	require.NoError(t, err)
+	var detached ocispec.Manifest
+	require.NoError(t, json.Unmarshal(detachedRaw, &detached))
+	assert.Equal(t, "Apache-2.0", detached.Annotations[ocispec.AnnotationLicenses])
+	assert.Equal(t, "# Model files\nMODEL model.safetensors\n\n# Documentation files\nDOC LICENSE\nDOC *.md\n", detached.Annotations[annotationModelfile])
+
+	// the manifest and the config keep the canonical JSON of the source.
+	canonical, err := canonicaljson.MarshalIndent(detached, 2)
+	require.NoError(t, err)
+	assert.Equal(t, string(canonical), string(detachedRaw))
+	configRaw := blobs[repo][detached.Config.Digest.String()]
+	canonical, err = canonicaljson.Marshal(json.RawMessage(configRaw))
+	require.NoError(t, err)
+	assert.Equal(t, string(canonical), string(configRaw))
+
+	// the license annotation is dropped with the last known license.
+	require.NoError(t, b.Detach(ctx, repo+":v1", "LICENSE", config.NewDetach()))
+	detachedRaw, _, err = store.PullManifest(ctx, repo, "v1")
+	require.NoError(t, err)
+	detached = ocispec.Manifest{}
+	require.NoError(t, json.Unmarshal(detachedRaw, &detached))
+	assert.NotContains(t, detached.Annotations, ocispec.AnnotationLicenses)
+	assert.Equal(t, "# Model files\nMODEL model.safetensors\n\n# Documentation files\nDOC *.md\n", detached.Annotations[annotationModelfile])
+}
+
+func TestDetachLoadOrder(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	repo := "example.com/models/shards"
+	files := []string{"model-00001.safetensors", "model-00002.safetensors", "model-00003.safetensors"}
+	storeModel(t, b, repo, "v1", map[string][]byte{
+		files[0]: []byte("shard 1"),
+		files[1]: []byte("shard 2"),
+		files[2]: []byte("shard 3"),
+	}, files)
+
+	// record the load order as built with --load-order.
+	raw, _, err := store.PullManifest(ctx, repo, "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(raw, &manifest))
+	annotateLoadOrder(manifest.Layers, config.LoadOrderShard)
+	raw, err = json.Marshal(manifest)
+	require.NoError(t, err)
+	_, err = store.PushManifest(ctx, repo, "v1", manifest.MediaType, raw)
+	require.NoError(t, err)
+
+	require.NoError(t, b.Detach(ctx, repo+":v1", files[1], config.NewDetach()))
+
+	raw, _, err = store.PullManifest(ctx, repo, "v1")
+	require.NoError(t, err)
+	var detached ocispec.Manifest
+	require.NoError(t, json.Unmarshal(raw, &detached))
+	require.Len(t, detached.Layers, 2)
+	for i, layer := range detached.Layers {
+		assert.Equal(t, []string{files[0], files[2]}[i], layerFilepath(layer))
+		require.NotNil(t, loadOrder(layer))
+		assert.Equal(t, i, *loadOrder(layer))
+	}
+}
+
+func TestDetachGC(t *testing.T) {
+	ctx := context.Background()
+	repo := "example.com/models/llama3"
+	files := map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"README.md":         []byte("# llama3"),
+	}
+
+	t.Run("untagged source", func(t *testing.T) {
+		store, _ := newMemoryStore()
+		b := &backend{store: store}
+		manifestRaw := storeModel(t, b, repo, "v1", files, []string{"model.safetensors", "README.md"})
+
+		store.On("ListTags", mock.Anything, repo).Return([]string{"v1"}, nil)
+		store.On("DeleteManifest", mock.Anything, repo, godigest.FromBytes(manifestRaw).String()).Return(nil)
+		store.On("PerformGC", mock.Anything, false, false).Return(nil)
+
+		require.NoError(t, b.Detach(ctx, repo+":v1", "README.md", &config.Detach{GC: true}))
+		store.AssertCalled(t, "DeleteManifest", mock.Anything, repo, godigest.FromBytes(manifestRaw).String())
+		store.AssertCalled(t, "PerformGC", mock.Anything, false, false)
+	})
+
+	t.Run("tagged source", func(t *testing.T) {
+		store, _ := newMemoryStore()
+		b := &backend{store: store}
+		manifestRaw := storeModel(t, b, repo, "v1", files, []string{"model.safetensors", "README.md"})
+		_, err := store.PushManifest(ctx, repo, "v2", ocispec.MediaTypeImageManifest, manifestRaw)
+		require.NoError(t, err)
+
+		store.On("ListTags", mock.Anything, repo).Return([]string{"v1", "v2"}, nil)
+		store.On("PerformGC", mock.Anything, false, false).Return(nil)
+
+		require.NoError(t, b.Detach(ctx, repo+":v1", "README.md", &config.Detach{GC: true}))
+		store.AssertNotCalled(t, "DeleteManifest", mock.Anything, mock.Anything, mock.Anything)
+		store.AssertCalled(t, "PerformGC", mock.Anything, false, false)
+	})
+}
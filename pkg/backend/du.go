@@ -0,0 +1,178 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// DiskUsage is the data model to represent the disk usage of the local storage.
+type DiskUsage struct {
+	// Size is the total size of the blobs referenced by all the tags,
+	// the shared blobs are only counted once.
+	Size int64 `json:"size"`
+	// Repositories is the disk usage of each repository.
+	Repositories []*RepositoryDiskUsage `json:"repositories"`
+}
+
+// RepositoryDiskUsage is the data model to represent the disk usage of a repository.
+type RepositoryDiskUsage struct {
+	// Repository is the name of the repository.
+	Repository string `json:"repository"`
+	// Size is the total size of the blobs referenced by the repository.
+	Size int64 `json:"size"`
+	// UniqueSize is the size of the blobs only referenced by the repository.
+	UniqueSize int64 `json:"uniqueSize"`
+	// SharedSize is the size of the blobs also referenced by other repositories.
+	SharedSize int64 `json:"sharedSize"`
+	// Tags is the disk usage of each tag in the repository.
+	Tags []*TagDiskUsage `json:"tags"`
+}
+
+// TagDiskUsage is the data model to represent the disk usage of a tag.
+type TagDiskUsage struct {
+	// Tag is the name of the tag.
+	Tag string `json:"tag"`
+	// Digest is the digest of the manifest referenced by the tag.
+	Digest string `json:"digest"`
+	// Size is the total size of the manifest, config and layers referenced by the tag.
+	Size int64 `json:"size"`
+	// UniqueSize is the size of the blobs only referenced by the tag.
+	UniqueSize int64 `json:"uniqueSize"`
+	// SharedSize is the size of the blobs also referenced by other tags.
+	SharedSize int64 `json:"sharedSize"`
+}
+
+// DiskUsage calculates the disk usage of the local storage by repository and tag.
+func (b *backend) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	logrus.Infof("du: calculating disk usage")
+
+	repos, err := b.store.ListRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	// blobSizes is the size of each blob by digest.
+	blobSizes := map[string]int64{}
+	// tagBlobs is the set of blobs referenced by each tag.
+	tagBlobs := map[*TagDiskUsage]map[string]struct{}{}
+	// tagRefs is the number of tags referencing the blob.
+	tagRefs := map[string]int{}
+	// repoRefs is the set of repositories referencing the blob.
+	repoRefs := map[string]map[string]struct{}{}
+
+	usage := &DiskUsage{Repositories: []*RepositoryDiskUsage{}}
+	for _, repo := range repos {
+		tags, err := b.store.ListTags(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags in repository %s: %w", repo, err)
+		}
+
+		repoUsage := &RepositoryDiskUsage{Repository: repo, Tags: []*TagDiskUsage{}}
+		for _, tag := range tags {
+			blobs, digest, err := b.referencedBlobs(ctx, repo, tag)
+			if err != nil {
+				return nil, err
+			}
+
+			tagUsage := &TagDiskUsage{Tag: tag, Digest: digest}
+			tagBlobs[tagUsage] = map[string]struct{}{}
+			for dgst, size := range blobs {
+				blobSizes[dgst] = size
+				tagBlobs[tagUsage][dgst] = struct{}{}
+				tagRefs[dgst]++
+
+				if repoRefs[dgst] == nil {
+					repoRefs[dgst] = map[string]struct{}{}
+				}
+				repoRefs[dgst][repo] = struct{}{}
+			}
+
+			repoUsage.Tags = append(repoUsage.Tags, tagUsage)
+		}
+
+		usage.Repositories = append(usage.Repositories, repoUsage)
+	}
+
+	for _, repoUsage := range usage.Repositories {
+		repoBlobs := map[string]struct{}{}
+		for _, tagUsage := range repoUsage.Tags {
+			for dgst := range tagBlobs[tagUsage] {
+				size := blobSizes[dgst]
+				tagUsage.Size += size
+				if tagRefs[dgst] > 1 {
+					tagUsage.SharedSize += size
+				} else {
+					tagUsage.UniqueSize += size
+				}
+
+				repoBlobs[dgst] = struct{}{}
+			}
+		}
+
+		for dgst := range repoBlobs {
+			size := blobSizes[dgst]
+			repoUsage.Size += size
+			if len(repoRefs[dgst]) > 1 {
+				repoUsage.SharedSize += size
+			} else {
+				repoUsage.UniqueSize += size
+			}
+		}
+	}
+
+	for _, size := range blobSizes {
+		usage.Size += size
+	}
+
+	sort.Slice(usage.Repositories, func(i, j int) bool {
+		return usage.Repositories[i].Repository < usage.Repositories[j].Repository
+	})
+
+	logrus.Infof("du: calculated disk usage [repositories: %d, blobs: %d, size: %d]", len(usage.Repositories), len(blobSizes), usage.Size)
+	return usage, nil
+}
+
+// referencedBlobs returns the size of the manifest, config and layers referenced by the tag
+// by digest, and the digest of the manifest.
+func (b *backend) referencedBlobs(ctx context.Context, repo, tag string) (map[string]int64, string, error) {
+	manifestRaw, digest, err := b.store.PullManifest(ctx, repo, tag)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to pull manifest of %s:%s: %w", repo, tag, err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal manifest of %s:%s: %w", repo, tag, err)
+	}
+
+	blobs := map[string]int64{
+		digest:                          int64(len(manifestRaw)),
+		manifest.Config.Digest.String(): manifest.Config.Size,
+	}
+	for _, layer := range manifest.Layers {
+		blobs[layer.Digest.String()] = layer.Size
+	}
+
+	return blobs, digest, nil
+}
@@ -0,0 +1,89 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelpack/modctl/test/mocks/storage"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskUsage(t *testing.T) {
+	mockStore := &storage.Storage{}
+	b := &backend{store: mockStore}
+	ctx := context.Background()
+
+	shared := ocispec.Descriptor{Digest: godigest.FromString("shared"), Size: 1000}
+	manifest1 := ocispec.Manifest{
+		Config: ocispec.Descriptor{Digest: godigest.FromString("config1"), Size: 10},
+		Layers: []ocispec.Descriptor{shared, {Digest: godigest.FromString("layer1"), Size: 100}},
+	}
+	manifest2 := ocispec.Manifest{
+		Config: ocispec.Descriptor{Digest: godigest.FromString("config2"), Size: 20},
+		Layers: []ocispec.Descriptor{shared, {Digest: godigest.FromString("layer2"), Size: 200}},
+	}
+	manifest1Raw, err := json.Marshal(manifest1)
+	require.NoError(t, err)
+	manifest2Raw, err := json.Marshal(manifest2)
+	require.NoError(t, err)
+
+	mockStore.On("ListRepositories", ctx).Return([]string{"example.com/repo2", "example.com/repo1"}, nil)
+	mockStore.On("ListTags", ctx, "example.com/repo1").Return([]string{"v1", "v2"}, nil)
+	mockStore.On("ListTags", ctx, "example.com/repo2").Return([]string{"v1"}, nil)
+	mockStore.On("PullManifest", ctx, "example.com/repo1", "v1").Return(manifest1Raw, "sha256:m1", nil)
+	mockStore.On("PullManifest", ctx, "example.com/repo1", "v2").Return(manifest2Raw, "sha256:m2", nil)
+	mockStore.On("PullManifest", ctx, "example.com/repo2", "v1").Return(manifest1Raw, "sha256:m1", nil)
+
+	usage, err := b.DiskUsage(ctx)
+	require.NoError(t, err)
+
+	m1, m2 := int64(len(manifest1Raw)), int64(len(manifest2Raw))
+	// The shared layer and the blobs of manifest1 referenced by two tags are only counted once.
+	assert.Equal(t, m1+m2+10+20+1000+100+200, usage.Size)
+	require.Len(t, usage.Repositories, 2)
+
+	repo1 := usage.Repositories[0]
+	assert.Equal(t, "example.com/repo1", repo1.Repository)
+	assert.Equal(t, m1+m2+10+20+1000+100+200, repo1.Size)
+	assert.Equal(t, m2+20+200, repo1.UniqueSize)
+	assert.Equal(t, m1+10+1000+100, repo1.SharedSize)
+	require.Len(t, repo1.Tags, 2)
+	assert.Equal(t, "v1", repo1.Tags[0].Tag)
+	assert.Equal(t, "sha256:m1", repo1.Tags[0].Digest)
+	assert.Equal(t, m1+10+1000+100, repo1.Tags[0].Size)
+	assert.Equal(t, int64(0), repo1.Tags[0].UniqueSize)
+	assert.Equal(t, m1+10+1000+100, repo1.Tags[0].SharedSize)
+	assert.Equal(t, "v2", repo1.Tags[1].Tag)
+	assert.Equal(t, m2+20+1000+200, repo1.Tags[1].Size)
+	assert.Equal(t, m2+20+200, repo1.Tags[1].UniqueSize)
+	assert.Equal(t, int64(1000), repo1.Tags[1].SharedSize)
+
+	repo2 := usage.Repositories[1]
+	assert.Equal(t, "example.com/repo2", repo2.Repository)
+	assert.Equal(t, m1+10+1000+100, repo2.Size)
+	assert.Equal(t, int64(0), repo2.UniqueSize)
+	assert.Equal(t, m1+10+1000+100, repo2.SharedSize)
+
+	mockStore.AssertExpectations(t)
+}
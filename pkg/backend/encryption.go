@@ -0,0 +1,67 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/encryption"
+)
+
+// decryptLayer returns the reader of the decrypted content if the layer is encrypted,
+// otherwise the reader is returned as is.
+func decryptLayer(desc ocispec.Descriptor, reader io.Reader, keySource string) (io.Reader, error) {
+	metadata, err := encryption.ParseMetadata(desc.Annotations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the encryption metadata of layer %s: %w", desc.Digest, err)
+	}
+
+	if metadata == nil {
+		return reader, nil
+	}
+
+	if keySource == "" {
+		return nil, fmt.Errorf("layer %s is encrypted, the decryption key is required", desc.Digest)
+	}
+
+	key, err := encryption.LoadKey(keySource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the decryption key: %w", err)
+	}
+
+	decrypted, err := encryption.Decrypt(key, metadata, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt layer %s: %w", desc.Digest, err)
+	}
+
+	return decrypted, nil
+}
+
+// checkUnencrypted checks none of the layers is encrypted, which is required by the
+// dragonfly which writes the downloaded layers to the output directly.
+func checkUnencrypted(layers []ocispec.Descriptor) error {
+	for _, layer := range layers {
+		if _, ok := layer.Annotations[encryption.AnnotationEncryption]; ok {
+			return fmt.Errorf("layer %s is encrypted, which is not supported by dragonfly", layer.Digest)
+		}
+	}
+
+	return nil
+}
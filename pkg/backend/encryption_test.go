@@ -0,0 +1,108 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/encryption"
+)
+
+// writeKey writes a random key in hex to the file and returns its path.
+func writeKey(t *testing.T, dir, name string) string {
+	key := make([]byte, encryption.KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(hex.EncodeToString(key)), 0600))
+	return path
+}
+
+func TestBuildAndExtractEncrypted(t *testing.T) {
+	workDir, keyDir := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"Modelfile":         "NAME test\nCONFIG config.json\nMODEL *.safetensors\nDOC README.md\n",
+		"config.json":       `{"hidden_size": 4096}`,
+		"model.safetensors": "confidential weights",
+		"README.md":         "# confidential",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+	key := writeKey(t, keyDir, "model.key")
+	cfg := config.NewBuild()
+	cfg.EncryptionKey = key
+	require.NoError(t, b.Build(ctx, filepath.Join(workDir, "Modelfile"), workDir, "example.com/repo:v1", cfg))
+
+	manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+	require.Len(t, manifest.Layers, 3)
+	for _, layer := range manifest.Layers {
+		// the layers are stored in ciphertext, whose digest is verified by the store.
+		assert.Contains(t, layer.Annotations, encryption.AnnotationEncryption)
+		blob := blobs["example.com/repo"][layer.Digest.String()]
+		assert.Equal(t, layer.Size, int64(len(blob)))
+		assert.False(t, bytes.Contains(blob, []byte("confidential")), layerFilepath(layer))
+	}
+
+	t.Run("extract", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 2, Output: outputDir, DecryptionKey: key}))
+		for name, content := range files {
+			if name == "Modelfile" {
+				continue
+			}
+
+			data, err := os.ReadFile(filepath.Join(outputDir, name))
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+		}
+	})
+
+	t.Run("extract with wrong key", func(t *testing.T) {
+		outputDir := t.TempDir()
+		err := b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 2, Output: outputDir, DecryptionKey: writeKey(t, keyDir, "wrong.key")})
+		assert.ErrorIs(t, err, encryption.ErrKeyMismatch)
+
+		_, err = os.Stat(filepath.Join(outputDir, "model.safetensors"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("extract without key", func(t *testing.T) {
+		err := b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 2, Output: t.TempDir()})
+		assert.ErrorContains(t, err, "the decryption key is required")
+	})
+}
@@ -17,12 +17,20 @@
 package backend
 
 import (
+	"archive/tar"
 	"bufio"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
 	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
@@ -30,8 +38,11 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/modelpack/modctl/pkg/archiver"
 	pkgcodec "github.com/modelpack/modctl/pkg/codec"
 	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/logging"
+	"github.com/modelpack/modctl/pkg/modelfile"
 	"github.com/modelpack/modctl/pkg/storage"
 )
 
@@ -42,6 +53,7 @@ const (
 
 // Extract extracts the model artifact.
 func (b *backend) Extract(ctx context.Context, target string, cfg *config.Extract) error {
+	start := time.Now()
 	logrus.Infof("extract: extracting artifact %s", target)
 	// parse the repository and tag from the target.
 	ref, err := ParseReference(target)
@@ -49,11 +61,35 @@ func (b *backend) Extract(ctx context.Context, target string, cfg *config.Extrac
 		return fmt.Errorf("failed to parse the target: %w", err)
 	}
 
+	// resolve the ownership before pulling the artifact, so the invalid ownership fails early.
+	if _, err := newLayerExtractor(cfg); err != nil {
+		return err
+	}
+
 	repo, tag := ref.Repository(), ref.Tag()
 	// pull the manifest from the storage.
-	manifestRaw, _, err := b.store.PullManifest(ctx, repo, tag)
+	manifestRaw, manifestDigest, err := b.store.PullManifest(ctx, repo, tag)
 	if err != nil {
-		return fmt.Errorf("failed to pull the manifest from storage: %w", err)
+		if !cfg.Pull || !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("failed to pull the manifest from storage: %w", err)
+		}
+
+		// pull the artifact from the remote registry if it does not exist locally.
+		logrus.Infof("extract: artifact %s not found in local storage, pulling from remote", target)
+		pullCfg := config.NewPull()
+		pullCfg.Concurrency = cfg.Concurrency
+		pullCfg.PlainHTTP = cfg.PlainHTTP
+		pullCfg.Insecure = cfg.Insecure
+		pullCfg.Proxy = cfg.Proxy
+		pullCfg.SkipSpaceCheck = cfg.SkipSpaceCheck
+		if err := b.Pull(ctx, target, pullCfg); err != nil {
+			return fmt.Errorf("failed to pull the artifact: %w", err)
+		}
+
+		manifestRaw, manifestDigest, err = b.store.PullManifest(ctx, repo, tag)
+		if err != nil {
+			return fmt.Errorf("failed to pull the manifest from storage: %w", err)
+		}
 	}
 	// unmarshal the manifest.
 	var manifest ocispec.Manifest
@@ -63,11 +99,52 @@ func (b *backend) Extract(ctx context.Context, target string, cfg *config.Extrac
 
 	logrus.Debugf("extract: loaded manifest for target %s [manifest: %s]", target, string(manifestRaw))
 
-	return exportModelArtifact(ctx, b.store, manifest, repo, cfg)
+	if err := checkMetadataOnly(ctx, b.store, repo, target, manifest); err != nil {
+		return err
+	}
+
+	if cfg.IndexDir == "" {
+		cfg.IndexDir = b.extractIndexDir()
+	}
+
+	if err := exportModelArtifact(ctx, b.store, manifest, repo, cfg); err != nil {
+		return err
+	}
+
+	logging.Event("extract", target, manifestDigest, start).Infof("extract: extracted artifact %s", target)
+	return nil
 }
 
 // exportModelArtifact exports the target model artifact to the output directory, which will open the artifact and extract to restore the original repo structure.
 func exportModelArtifact(ctx context.Context, store storage.Storage, manifest ocispec.Manifest, repo string, cfg *config.Extract) error {
+	extractor, err := newLayerExtractor(cfg)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Flatten {
+		if err := checkFlattenConflicts(ctx, store, repo, manifest.Layers, cfg.DecryptionKey); err != nil {
+			return err
+		}
+	}
+
+	// skip the whole extraction if the output directory is already up-to-date.
+	if !cfg.Force && isExtractionUpToDate(cfg.IndexDir, cfg.Output, manifest.Layers, cfg.Flatten) {
+		logrus.Infof("extract: output %s is up-to-date, skipping extraction for %s", cfg.Output, repo)
+		if cfg.ExtractDatasets {
+			return extractDatasets(cfg.Output, manifest.Layers, cfg.Flatten, cfg.OnConflict, extractor.shortener)
+		}
+
+		return nil
+	}
+
+	// fail early if the output directory is short of the space for the layers.
+	if !cfg.SkipSpaceCheck {
+		if err := checkExtractSpace(manifest.Layers, cfg); err != nil {
+			return err
+		}
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(cfg.Concurrency)
 
@@ -89,7 +166,7 @@ func exportModelArtifact(ctx context.Context, store storage.Storage, manifest oc
 			defer reader.Close()
 
 			bufferedReader := bufio.NewReaderSize(reader, defaultBufferSize)
-			if err := extractLayer(layer, cfg.Output, bufferedReader); err != nil {
+			if err := extractor.extractLayer(layer, bufferedReader); err != nil {
 				if errors.Is(err, pkgcodec.ErrAlreadyUpToDate) {
 					logrus.Debugf(
 						"extract: skipping layer %s, already up-to-date",
@@ -98,6 +175,11 @@ func exportModelArtifact(ctx context.Context, store storage.Storage, manifest oc
 					return nil
 				}
 
+				if errors.Is(err, pkgcodec.ErrSkipped) {
+					logrus.Infof("extract: skipping layer %s, the existing file is kept by the conflict policy", layer.Digest.String())
+					return nil
+				}
+
 				return fmt.Errorf("failed to extract layer %s: %w", layer.Digest.String(), err)
 			}
 
@@ -107,38 +189,352 @@ func exportModelArtifact(ctx context.Context, store storage.Storage, manifest oc
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	// record the shortened paths even if the extraction fails, so the extracted files can be located.
+	err = g.Wait()
+	if err := extractor.shortener.WriteMappings(); err != nil {
+		return err
+	}
+
+	if err != nil {
 		return err
 	}
 
-	logrus.Infof("extract: extracted artifact %s", repo)
+	layers, joined := manifest.Layers, false
+	if cfg.JoinShards {
+		layers, joined, err = joinShards(cfg.Output, manifest.Layers, cfg.Flatten, extractor.shortener)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.VerifySafetensors {
+		if err := verifyExtractedSafetensors(cfg.Output, layers, cfg.Flatten, extractor.shortener); err != nil {
+			return err
+		}
+	}
+
+	if cfg.ExtractDatasets {
+		if err := extractDatasets(cfg.Output, layers, cfg.Flatten, cfg.OnConflict, extractor.shortener); err != nil {
+			return err
+		}
+	}
+
+	// the index records the files of the layers, which no longer exist once the shards are joined.
+	if joined {
+		logrus.Debugf("extract: shards joined, skipping the extraction index for %s", repo)
+		return nil
+	}
+
+	// the index is only for the fast path of the next extraction, so just warn on failure.
+	if err := writeExtractIndex(cfg.IndexDir, cfg.Output, manifest.Layers, cfg.Flatten); err != nil {
+		logrus.Warnf("extract: failed to write extraction index to %s: %s", cfg.Output, err)
+	}
+
+	logrus.Debugf("extract: extracted %d layers for %s", len(manifest.Layers), repo)
 	return nil
 }
 
-// extractLayer extracts the layer to the output directory.
-func extractLayer(desc ocispec.Descriptor, outputDir string, reader io.Reader) error {
-	var filepath string
-	if desc.Annotations != nil {
-		if desc.Annotations[modelspec.AnnotationFilepath] != "" {
-			filepath = desc.Annotations[modelspec.AnnotationFilepath]
-		} else {
-			filepath = desc.Annotations[legacymodelspec.AnnotationFilepath]
+// verifyExtractedSafetensors checks the headers of the extracted safetensors files
+// of the layers, the directories extracted from the tar layers are walked through.
+func verifyExtractedSafetensors(outputDir string, layers []ocispec.Descriptor, flatten bool, shortener *archiver.PathShortener) error {
+	var errs []error
+	verify := func(file string) {
+		if !strings.EqualFold(filepath.Ext(file), ".safetensors") {
+			return
+		}
+
+		if err := modelfile.VerifySafetensors(file); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			return
+		}
+
+		logrus.Debugf("extract: verified safetensors file %s", file)
+	}
+
+	for _, layer := range layers {
+		relPath := layerFilepath(layer)
+		if relPath == "" {
+			continue
+		}
+
+		if flatten {
+			relPath = path.Base(relPath)
+		}
+
+		fullPath := filepath.Join(outputDir, shortener.Shorten(outputDir, relPath))
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			return fmt.Errorf("failed to stat the extracted file %s: %w", fullPath, err)
+		}
+
+		if !info.IsDir() {
+			verify(fullPath)
+			continue
 		}
 
+		if err := filepath.WalkDir(fullPath, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.Type().IsRegular() {
+				verify(file)
+			}
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to walk the extracted directory %s: %w", fullPath, err)
+		}
 	}
 
-	codec, err := pkgcodec.New(pkgcodec.TypeFromMediaType(desc.MediaType))
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to verify %d safetensors files: %w", len(errs), errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// extractDatasets extracts the raw dataset layers which are the zip, tar or gzip compressed tar
+// archives detected by the content into the directories named without the archive extension.
+func extractDatasets(outputDir string, layers []ocispec.Descriptor, flatten bool, policy config.ConflictPolicy, shortener *archiver.PathShortener) error {
+	for _, layer := range layers {
+		relPath := layerFilepath(layer)
+		if relPath == "" || layerKind(layer.MediaType) != config.LayerKindDataset || pkgcodec.TypeFromMediaType(layer.MediaType) != pkgcodec.Raw {
+			continue
+		}
+
+		if flatten {
+			relPath = path.Base(relPath)
+		}
+
+		archivePath := filepath.Join(outputDir, shortener.Shorten(outputDir, relPath))
+		format, err := archiver.DetectFormat(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to detect the format of the dataset %s: %w", relPath, err)
+		}
+
+		if format == archiver.FormatUnknown {
+			logrus.Debugf("extract: dataset %s is not an archive, skipping", relPath)
+			continue
+		}
+
+		destPath := archiver.TrimArchiveExt(archivePath)
+		if destPath == archivePath {
+			destPath += ".extracted"
+		}
+
+		if err := archiver.ExtractArchive(archivePath, destPath, archiver.WithConflictPolicy(policy)); err != nil {
+			return fmt.Errorf("failed to extract the dataset %s: %w", relPath, err)
+		}
+
+		logrus.Infof("extract: extracted the %s dataset %s to %s", format, relPath, destPath)
+	}
+
+	return nil
+}
+
+// layerExtractor extracts the layers by the extract config, the ownership and the path shortener
+// are resolved from the config once and shared by the layers, so the warnings of the ownership
+// are not repeated for each layer and the shortened paths are recorded in one mapping.
+type layerExtractor struct {
+	cfg       *config.Extract
+	ownership *archiver.Ownership
+	shortener *archiver.PathShortener
+}
+
+// newLayerExtractor creates the layer extractor of the extract config.
+func newLayerExtractor(cfg *config.Extract) (*layerExtractor, error) {
+	e := &layerExtractor{cfg: cfg}
+	if cfg.Chown != "" || len(cfg.MapUID) > 0 || len(cfg.MapGID) > 0 {
+		ownership, err := archiver.NewOwnership(cfg.Chown, cfg.MapUID, cfg.MapGID)
+		if err != nil {
+			return nil, err
+		}
+
+		e.ownership = ownership
+	}
+
+	if cfg.ShortenLongPaths {
+		e.shortener = archiver.NewPathShortener()
+	}
+
+	return e, nil
+}
+
+// extractLayer extracts the layer to the output directory, the file is placed
+// in the output directory directly by the base name if flatten is enabled.
+func (e *layerExtractor) extractLayer(desc ocispec.Descriptor, reader io.Reader) error {
+	cfg, ownership, shortener := e.cfg, e.ownership, e.shortener
+	reader, err := decryptLayer(desc, reader, cfg.DecryptionKey)
+	if err != nil {
+		return err
+	}
+
+	outputDir := cfg.Output
+	filepath := layerFilepath(desc)
+	codec, err := pkgcodec.New(pkgcodec.TypeFromMediaType(desc.MediaType), pkgcodec.WithPreallocate(cfg.Preallocate), pkgcodec.WithConflictPolicy(cfg.OnConflict), pkgcodec.WithOwnership(ownership), pkgcodec.WithPathShortener(shortener))
 	if err != nil {
 		return fmt.Errorf("failed to create codec for media type %s: %w", desc.MediaType, err)
 	}
 
+	if cfg.Flatten {
+		// the tar codec restores the structure from the tar headers, so untar
+		// it by the base names directly.
+		if codec.Type() == pkgcodec.Tar || codec.Type() == pkgcodec.TarGzip {
+			if codec.Type() == pkgcodec.TarGzip {
+				gr, err := gzip.NewReader(reader)
+				if err != nil {
+					return fmt.Errorf("failed to decompress the layer %s: %w", desc.Digest.String(), err)
+				}
+				defer gr.Close()
+
+				reader = gr
+			}
+
+			if err := archiver.Untar(reader, outputDir, archiver.WithFlatten(), archiver.WithConflictPolicy(cfg.OnConflict), archiver.WithOwnership(ownership), archiver.WithPathShortener(shortener)); err != nil {
+				return fmt.Errorf("failed to decode the layer %s to output directory: %w", desc.Digest.String(), err)
+			}
+
+			return applyExecutable(desc, outputDir, shortener.Shorten(outputDir, path.Base(filepath)))
+		}
+
+		if filepath != "" {
+			filepath = path.Base(filepath)
+		}
+	}
+
 	if err := codec.Decode(outputDir, filepath, reader, desc); err != nil {
-		if errors.Is(err, pkgcodec.ErrAlreadyUpToDate) {
+		if errors.Is(err, pkgcodec.ErrAlreadyUpToDate) || errors.Is(err, pkgcodec.ErrSkipped) {
 			return err
 		}
 
 		return fmt.Errorf("failed to decode the layer %s to output directory: %w", desc.Digest.String(), err)
 	}
 
+	return applyExecutable(desc, outputDir, shortener.Shorten(outputDir, filepath))
+}
+
+// applyExecutable sets the exec bit of the extracted file if the layer is annotated as executable.
+func applyExecutable(desc ocispec.Descriptor, outputDir, relPath string) error {
+	if desc.Annotations[pkgcodec.AnnotationExecutable] != "true" || relPath == "" {
+		return nil
+	}
+
+	fullPath := filepath.Join(outputDir, relPath)
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat the extracted file %s: %w", fullPath, err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	// grant the exec bit to the classes which can read the file.
+	mode := info.Mode() | (info.Mode()&0444)>>2
+	if err := os.Chmod(fullPath, mode); err != nil {
+		return fmt.Errorf("failed to set the exec bit of %s: %w", fullPath, err)
+	}
+
 	return nil
 }
+
+// layerFilepath returns the filepath annotation of the layer.
+func layerFilepath(desc ocispec.Descriptor) string {
+	if desc.Annotations == nil {
+		return ""
+	}
+
+	if desc.Annotations[modelspec.AnnotationFilepath] != "" {
+		return desc.Annotations[modelspec.AnnotationFilepath]
+	}
+
+	return desc.Annotations[legacymodelspec.AnnotationFilepath]
+}
+
+// checkFlattenConflicts checks whether the files of the layers, including the files archived in
+// the tar layers, have the same base name, which will overwrite each other when flattened into
+// one directory.
+func checkFlattenConflicts(ctx context.Context, store storage.Storage, repo string, layers []ocispec.Descriptor, decryptionKey string) error {
+	seen := make(map[string]string, len(layers))
+	check := func(filepath string) error {
+		name := path.Base(filepath)
+		if previous, ok := seen[name]; ok {
+			return fmt.Errorf("cannot flatten %s and %s into the same file %s", previous, filepath, name)
+		}
+
+		seen[name] = filepath
+		return nil
+	}
+
+	for _, layer := range layers {
+		filepath := layerFilepath(layer)
+		if filepath == "" {
+			continue
+		}
+
+		codecType := pkgcodec.TypeFromMediaType(layer.MediaType)
+		if codecType != pkgcodec.Tar && codecType != pkgcodec.TarGzip {
+			if err := check(filepath); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// the tar layers are flattened by the names of the archived files, which may be a directory.
+		names, err := tarLayerFiles(ctx, store, repo, layer, decryptionKey)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if err := check(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// tarLayerFiles returns the names of the regular files archived in the tar layer.
+func tarLayerFiles(ctx context.Context, store storage.Storage, repo string, layer ocispec.Descriptor, decryptionKey string) ([]string, error) {
+	reader, err := store.PullBlob(ctx, repo, layer.Digest.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull the blob from storage: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := decryptLayer(layer, bufio.NewReaderSize(reader, defaultBufferSize), decryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkgcodec.TypeFromMediaType(layer.MediaType) == pkgcodec.TarGzip {
+		gr, err := gzip.NewReader(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress the layer %s: %w", layer.Digest.String(), err)
+		}
+		defer gr.Close()
+
+		content = gr
+	}
+
+	names := []string{}
+	tr := tar.NewReader(content)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the layer %s: %w", layer.Digest.String(), err)
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, strings.ReplaceAll(header.Name, "\\", "/"))
+		}
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// extractIndexDir is the directory of the extraction indexes in the storage directory,
+	// which keeps the indexes out of the output directories.
+	extractIndexDir = "extract-index.v1"
+)
+
+// extractIndex records the files of a complete extraction, which is used to skip
+// the re-extraction quickly when the output directory is up-to-date.
+type extractIndex struct {
+	// Output is the absolute path of the output directory.
+	Output string `json:"output"`
+	// Flatten indicates whether the files are extracted in the flat layout.
+	Flatten bool `json:"flatten"`
+	// Files is the files extracted from the layers.
+	Files []extractIndexFile `json:"files"`
+}
+
+// extractIndexFile is the file extracted from the layer.
+type extractIndexFile struct {
+	Layer godigest.Digest `json:"layer"`
+	Path  string          `json:"path"`
+	Size  int64           `json:"size"`
+	// Digest is the digest of the extracted file, which is empty for the directories.
+	Digest godigest.Digest `json:"digest,omitempty"`
+	// LoadOrder is the index of the weight in the load order recorded on build.
+	LoadOrder *int `json:"loadOrder,omitempty"`
+}
+
+// extractIndexDir returns the directory of the extraction indexes, which is empty if the
+// backend has no storage directory.
+func (b *backend) extractIndexDir() string {
+	if b.storageDir == "" {
+		return ""
+	}
+
+	return filepath.Join(b.storageDir, extractIndexDir)
+}
+
+// extractIndexPath returns the path of the extraction index of the output directory,
+// which is keyed by the absolute path of the output directory.
+func extractIndexPath(indexDir, outputDir string) (string, string, error) {
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get absolute path of %s: %w", outputDir, err)
+	}
+
+	sum := sha256.Sum256([]byte(absOutput))
+	return filepath.Join(indexDir, hex.EncodeToString(sum[:])+".json"), absOutput, nil
+}
+
+// extractedPath returns the path of the file extracted from the layer relative to the output directory.
+func extractedPath(layer ocispec.Descriptor, flatten bool) string {
+	filepath := layerFilepath(layer)
+	if flatten && filepath != "" {
+		return path.Base(filepath)
+	}
+
+	return filepath
+}
+
+// isExtractionUpToDate checks whether the output directory already contains the complete
+// extraction of the layers by the index stored in the index directory, the files are
+// compared by the digests, which saves pulling and writing the layers again.
+func isExtractionUpToDate(indexDir, outputDir string, layers []ocispec.Descriptor, flatten bool) bool {
+	if indexDir == "" {
+		return false
+	}
+
+	indexPath, absOutput, err := extractIndexPath(indexDir, outputDir)
+	if err != nil {
+		return false
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return false
+	}
+
+	var index extractIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		logrus.Warnf("extract: failed to decode extraction index of %s: %s", outputDir, err)
+		return false
+	}
+
+	if index.Output != absOutput || index.Flatten != flatten || len(index.Files) != len(layers) {
+		return false
+	}
+
+	for i, layer := range layers {
+		file := index.Files[i]
+		if file.Layer != layer.Digest || file.Path != extractedPath(layer, flatten) || file.Path == "" {
+			return false
+		}
+
+		filePath := filepath.Join(outputDir, file.Path)
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return false
+		}
+
+		if info.IsDir() {
+			continue
+		}
+
+		if info.Size() != file.Size {
+			logrus.Debugf("extract: file %s changed since the last extraction", file.Path)
+			return false
+		}
+
+		digest, _, err := fileDigest(filePath)
+		if err != nil || digest != file.Digest {
+			logrus.Debugf("extract: file %s changed since the last extraction", file.Path)
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeExtractIndex writes the extraction index of the layers extracted to the output
+// directory into the index directory, it is skipped if the index directory is empty.
+func writeExtractIndex(indexDir, outputDir string, layers []ocispec.Descriptor, flatten bool) error {
+	if indexDir == "" {
+		return nil
+	}
+
+	indexPath, absOutput, err := extractIndexPath(indexDir, outputDir)
+	if err != nil {
+		return err
+	}
+
+	index := extractIndex{Output: absOutput, Flatten: flatten, Files: make([]extractIndexFile, 0, len(layers))}
+	for _, layer := range layers {
+		file := extractIndexFile{Layer: layer.Digest, Path: extractedPath(layer, flatten), LoadOrder: loadOrder(layer)}
+		if file.Path != "" {
+			filePath := filepath.Join(outputDir, file.Path)
+			info, err := os.Stat(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to stat extracted file %s: %w", file.Path, err)
+			}
+
+			if !info.IsDir() {
+				file.Digest, file.Size, err = fileDigest(filePath)
+				if err != nil {
+					return fmt.Errorf("failed to digest extracted file %s: %w", file.Path, err)
+				}
+			}
+		}
+
+		index.Files = append(index.Files, file)
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extraction index: %w", err)
+	}
+
+	if err := os.MkdirAll(indexDir, 0755); err != nil {
+		return fmt.Errorf("failed to create extraction index directory: %w", err)
+	}
+
+	// write to the temporary file then rename to avoid the partial index.
+	if err := os.WriteFile(indexPath+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write extraction index: %w", err)
+	}
+
+	return os.Rename(indexPath+".tmp", indexPath)
+}
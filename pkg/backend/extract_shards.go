@@ -0,0 +1,204 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+)
+
+// shardPartRegexp matches the parts of the file split for the size limits of the registries,
+// such as model.safetensors.part-1-of-3, which are joined into model.safetensors.
+var shardPartRegexp = regexp.MustCompile(`^(.+)\.part-0*([1-9][0-9]*)-of-0*([1-9][0-9]*)$`)
+
+// shardGroup is the parts of the file to join.
+type shardGroup struct {
+	// relPath is the path of the joined file relative to the output directory.
+	relPath string
+	// parts is the paths of the parts relative to the output directory ordered by the part number.
+	parts []string
+	// mediaType is the media type of the first part, which is the media type of the joined file.
+	mediaType string
+	// index is the position of the first part in the layers.
+	index int
+}
+
+// parseShardPart returns the path of the joined file, the part number and the number of the parts
+// of the shard part path, ok is false if the path is not a shard part.
+func parseShardPart(relPath string) (joined string, part, total int, ok bool) {
+	match := shardPartRegexp.FindStringSubmatch(path.Base(relPath))
+	if match == nil {
+		return "", 0, 0, false
+	}
+
+	part, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, 0, false
+	}
+
+	total, err = strconv.Atoi(match[3])
+	if err != nil || part > total {
+		return "", 0, 0, false
+	}
+
+	return path.Join(path.Dir(relPath), match[1]), part, total, true
+}
+
+// joinShards joins the extracted shard parts of the layers into the files named without the part
+// suffix and removes the parts, it returns the layers with the parts replaced by the joined files,
+// which are verified and extracted as the datasets after the extraction.
+func joinShards(outputDir string, layers []ocispec.Descriptor, flatten bool, shortener *archiver.PathShortener) ([]ocispec.Descriptor, bool, error) {
+	groups := make(map[string]*shardGroup)
+	order := []*shardGroup{}
+	for i, layer := range layers {
+		relPath := layerFilepath(layer)
+		if relPath == "" {
+			continue
+		}
+
+		joined, part, total, ok := parseShardPart(relPath)
+		if !ok {
+			continue
+		}
+
+		group, ok := groups[joined]
+		if !ok {
+			group = &shardGroup{relPath: joined, parts: make([]string, total), mediaType: layer.MediaType, index: i}
+			groups[joined] = group
+			order = append(order, group)
+		}
+
+		if len(group.parts) != total {
+			return nil, false, fmt.Errorf("shard %s has %d parts, but the other shards of %s have %d", relPath, total, joined, len(group.parts))
+		}
+
+		if group.parts[part-1] != "" {
+			return nil, false, fmt.Errorf("duplicate shard %s of %s", relPath, joined)
+		}
+
+		group.parts[part-1] = relPath
+	}
+
+	if len(order) == 0 {
+		return layers, false, nil
+	}
+
+	joinedLayers := make([]ocispec.Descriptor, 0, len(layers))
+	for i, layer := range layers {
+		if relPath := layerFilepath(layer); relPath != "" {
+			if joined, _, _, ok := parseShardPart(relPath); ok {
+				if group := groups[joined]; group.index == i {
+					joinedLayers = append(joinedLayers, ocispec.Descriptor{
+						MediaType:   group.mediaType,
+						Annotations: map[string]string{modelspec.AnnotationFilepath: group.relPath},
+					})
+				}
+
+				continue
+			}
+		}
+
+		joinedLayers = append(joinedLayers, layer)
+	}
+
+	resolve := func(relPath string) string {
+		if flatten {
+			relPath = path.Base(relPath)
+		}
+
+		return filepath.Join(outputDir, shortener.Shorten(outputDir, relPath))
+	}
+
+	for _, group := range order {
+		for i, part := range group.parts {
+			if part == "" {
+				return nil, false, fmt.Errorf("missing part %d of %d of the shards of %s", i+1, len(group.parts), group.relPath)
+			}
+		}
+
+		parts := make([]string, len(group.parts))
+		for i, part := range group.parts {
+			parts[i] = resolve(part)
+		}
+
+		if err := joinShardParts(resolve(group.relPath), parts); err != nil {
+			return nil, false, err
+		}
+
+		logrus.Infof("extract: joined %d shards into %s", len(parts), group.relPath)
+	}
+
+	return joinedLayers, true, nil
+}
+
+// joinShardParts concatenates the parts in order into the file atomically and removes the parts.
+func joinShardParts(fullPath string, parts []string) error {
+	info, err := os.Stat(parts[0])
+	if err != nil {
+		return fmt.Errorf("failed to stat the shard %s: %w", parts[0], err)
+	}
+
+	file, err := archiver.CreateAtomic(fullPath, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if err := appendShardPart(file, part); err != nil {
+			file.Abort()
+			return err
+		}
+	}
+
+	if err := file.Commit(); err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if err := os.Remove(part); err != nil {
+			return fmt.Errorf("failed to remove the shard %s: %w", part, err)
+		}
+	}
+
+	return nil
+}
+
+// appendShardPart appends the content of the part to the writer.
+func appendShardPart(w io.Writer, part string) error {
+	src, err := os.Open(part)
+	if err != nil {
+		return fmt.Errorf("failed to open the shard %s: %w", part, err)
+	}
+	defer src.Close()
+
+	if _, err := io.Copy(w, src); err != nil {
+		return fmt.Errorf("failed to join the shard %s: %w", part, err)
+	}
+
+	return nil
+}
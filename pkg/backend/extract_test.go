@@ -0,0 +1,593 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	pkgcodec "github.com/modelpack/modctl/pkg/codec"
+	"github.com/modelpack/modctl/pkg/config"
+	pkgstorage "github.com/modelpack/modctl/pkg/storage"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+// newExtractFixture returns the model artifact with a raw weight and a tar doc
+// in the nested directories, and the mock storage serving their blobs.
+func newExtractFixture(t *testing.T) (*storage.Storage, ocispec.Manifest, map[string]string) {
+	files := map[string]string{
+		"weights/model.safetensors": "weights",
+		"docs/README.md":            "# model",
+	}
+
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "docs", "README.md"), []byte(files["docs/README.md"]), 0644))
+	docReader, err := archiver.Tar(filepath.Join(srcDir, "docs", "README.md"), srcDir)
+	require.NoError(t, err)
+	docBlob, err := io.ReadAll(docReader)
+	require.NoError(t, err)
+
+	weightBlob := []byte(files["weights/model.safetensors"])
+	weight := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelWeightRaw,
+		Digest:      godigest.FromBytes(weightBlob),
+		Size:        int64(len(weightBlob)),
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "weights/model.safetensors"},
+	}
+	doc := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelDoc,
+		Digest:      godigest.FromBytes(docBlob),
+		Size:        int64(len(docBlob)),
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "docs/README.md"},
+	}
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", weight.Digest.String()).Return(io.NopCloser(bytes.NewReader(weightBlob)), nil).Once()
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", doc.Digest.String()).Return(io.NopCloser(bytes.NewReader(docBlob)), nil).Once()
+
+	return mockStore, ocispec.Manifest{Layers: []ocispec.Descriptor{weight, doc}}, files
+}
+
+func TestExportModelArtifact(t *testing.T) {
+	mockStore, manifest, files := newExtractFixture(t)
+	outputDir := t.TempDir()
+
+	err := exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 2, Output: outputDir})
+	require.NoError(t, err)
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+}
+
+func TestExportModelArtifactVerifySafetensors(t *testing.T) {
+	mockStore, manifest, _ := newExtractFixture(t)
+	outputDir := t.TempDir()
+
+	// the weight of the fixture is not a valid safetensors file.
+	err := exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 2, Output: outputDir, VerifySafetensors: true})
+	assert.ErrorContains(t, err, "failed to verify 1 safetensors files")
+	assert.ErrorContains(t, err, filepath.Join(outputDir, "weights", "model.safetensors"))
+}
+
+func TestExportModelArtifactFlatten(t *testing.T) {
+	mockStore, manifest, files := newExtractFixture(t)
+	outputDir := t.TempDir()
+
+	// the names of the files in the tar layer are checked for the conflicts before extracting.
+	doc := manifest.Layers[1]
+	docReader, err := mockStore.PullBlob(context.Background(), "example.com/repo", doc.Digest.String())
+	require.NoError(t, err)
+	docBlob, err := io.ReadAll(docReader)
+	require.NoError(t, err)
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", doc.Digest.String()).Return(
+		func(ctx context.Context, repo, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(docBlob)), nil
+		},
+	).Twice()
+
+	err = exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 2, Output: outputDir, Flatten: true})
+	require.NoError(t, err)
+
+	// all the files are laid out in the output directory for immediate loading.
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		assert.False(t, entry.IsDir())
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"model.safetensors", "README.md"}, names)
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(outputDir, filepath.Base(name)))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+}
+
+func TestCheckFlattenConflicts(t *testing.T) {
+	layer := func(path string) ocispec.Descriptor {
+		return ocispec.Descriptor{Annotations: map[string]string{modelspec.AnnotationFilepath: path}}
+	}
+
+	// the tar layer of the directory archives the files in the nested directories.
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "tokenizer", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "tokenizer", "vocab.txt"), []byte("vocab"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "tokenizer", "nested", "config.json"), []byte("{}"), 0644))
+	tarReader, err := archiver.Tar(filepath.Join(srcDir, "tokenizer"), srcDir)
+	require.NoError(t, err)
+	tarBlob, err := io.ReadAll(tarReader)
+	require.NoError(t, err)
+	tokenizer := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelCode,
+		Digest:      godigest.FromBytes(tarBlob),
+		Size:        int64(len(tarBlob)),
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "tokenizer"},
+	}
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", tokenizer.Digest.String()).Return(
+		func(ctx context.Context, repo, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(tarBlob)), nil
+		},
+	)
+
+	testCases := []struct {
+		name        string
+		layers      []ocispec.Descriptor
+		expectedErr string
+	}{
+		{name: "no conflict", layers: []ocispec.Descriptor{layer("a/config.json"), layer("b/model.bin"), {}}},
+		{name: "conflict of the layers", layers: []ocispec.Descriptor{layer("a/config.json"), layer("b/config.json")}, expectedErr: "config.json"},
+		{name: "no conflict with the tar layer", layers: []ocispec.Descriptor{layer("model.bin"), tokenizer}},
+		{name: "conflict with the file in the tar layer", layers: []ocispec.Descriptor{layer("config.json"), tokenizer}, expectedErr: "tokenizer/nested/config.json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkFlattenConflicts(context.Background(), mockStore, "example.com/repo", tc.layers, "")
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestExtractNotFoundLocally(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not found without pull", func(t *testing.T) {
+		store, _ := newMemoryStore()
+		b := &backend{store: store}
+		err := b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 1, Output: t.TempDir()})
+		assert.ErrorContains(t, err, "failed to pull the manifest from storage")
+		assert.ErrorIs(t, err, pkgstorage.ErrNotFound)
+	})
+
+	t.Run("other errors are not pulled", func(t *testing.T) {
+		mockStore := &storage.Storage{}
+		b := &backend{store: mockStore}
+		mockStore.On("PullManifest", ctx, "example.com/repo", "v1").Return(nil, "", errors.New("permission denied"))
+
+		err := b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 1, Output: t.TempDir(), Pull: true})
+		assert.ErrorContains(t, err, "failed to pull the manifest from storage")
+		assert.ErrorContains(t, err, "permission denied")
+	})
+}
+
+func TestExtractJoinShards(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	files := map[string][]byte{
+		"config.json":                           []byte("{}"),
+		"weights/model.safetensors.part-2-of-3": []byte("-second"),
+		"weights/model.safetensors.part-1-of-3": []byte("first"),
+		"weights/model.safetensors.part-3-of-3": []byte("-third"),
+	}
+	storeModel(t, b, "example.com/models/shards", "v1", files, []string{"config.json", "weights/model.safetensors.part-2-of-3", "weights/model.safetensors.part-1-of-3", "weights/model.safetensors.part-3-of-3"})
+	storeModel(t, b, "example.com/models/shards", "incomplete", files, []string{"config.json", "weights/model.safetensors.part-1-of-3", "weights/model.safetensors.part-3-of-3"})
+
+	t.Run("nested", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/models/shards:v1", &config.Extract{Concurrency: 2, Output: outputDir, JoinShards: true, IndexDir: t.TempDir()}))
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "weights", "model.safetensors"))
+		require.NoError(t, err)
+		assert.Equal(t, "first-second-third", string(data))
+
+		entries, err := os.ReadDir(filepath.Join(outputDir, "weights"))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("flatten", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/models/shards:v1", &config.Extract{Concurrency: 2, Output: outputDir, JoinShards: true, Flatten: true}))
+
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, []string{"config.json", "model.safetensors"}, names)
+	})
+
+	t.Run("without joining", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/models/shards:v1", &config.Extract{Concurrency: 2, Output: outputDir}))
+		assert.FileExists(t, filepath.Join(outputDir, "weights", "model.safetensors.part-1-of-3"))
+		assert.NoFileExists(t, filepath.Join(outputDir, "weights", "model.safetensors"))
+	})
+
+	t.Run("missing part", func(t *testing.T) {
+		outputDir := t.TempDir()
+		err := b.Extract(ctx, "example.com/models/shards:incomplete", &config.Extract{Concurrency: 2, Output: outputDir, JoinShards: true})
+		assert.ErrorContains(t, err, "missing part 2 of 3")
+		assert.NoFileExists(t, filepath.Join(outputDir, "weights", "model.safetensors"))
+	})
+}
+
+func TestParseShardPart(t *testing.T) {
+	testCases := []struct {
+		path   string
+		joined string
+		part   int
+		total  int
+		ok     bool
+	}{
+		{path: "model.safetensors.part-1-of-3", joined: "model.safetensors", part: 1, total: 3, ok: true},
+		{path: "weights/model.bin.part-002-of-010", joined: "weights/model.bin", part: 2, total: 10, ok: true},
+		{path: "model.safetensors.part-4-of-3"},
+		{path: "model.safetensors.part-0-of-3"},
+		{path: "model-00001-of-00003.safetensors"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			joined, part, total, ok := parseShardPart(tc.path)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.joined, joined)
+			assert.Equal(t, tc.part, part)
+			assert.Equal(t, tc.total, total)
+		})
+	}
+}
+
+func TestExportModelArtifactUpToDate(t *testing.T) {
+	mockStore, manifest, files := newExtractFixture(t)
+	outputDir, indexDir := t.TempDir(), t.TempDir()
+	cfg := &config.Extract{Concurrency: 2, Output: outputDir, IndexDir: indexDir}
+
+	require.NoError(t, exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", cfg))
+	mockStore.AssertNumberOfCalls(t, "PullBlob", 2)
+
+	// the index is kept out of the output directory.
+	indexPath, _, err := extractIndexPath(indexDir, outputDir)
+	require.NoError(t, err)
+	assert.FileExists(t, indexPath)
+	assert.NoFileExists(t, filepath.Join(outputDir, ".modctl-extract.json"))
+
+	// the second extraction is a no-op without pulling any blob.
+	require.NoError(t, exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", cfg))
+	mockStore.AssertNumberOfCalls(t, "PullBlob", 2)
+
+	// the flatten layout is not the same extraction.
+	assert.False(t, isExtractionUpToDate(indexDir, outputDir, manifest.Layers, true))
+
+	// the extraction without the index directory is never up-to-date.
+	assert.False(t, isExtractionUpToDate("", outputDir, manifest.Layers, false))
+
+	// the modified file invalidates the extraction, even if its size and mtime are kept.
+	readme := filepath.Join(outputDir, "docs/README.md")
+	info, err := os.Stat(readme)
+	require.NoError(t, err)
+	modified := []byte(strings.Repeat("x", len(files["docs/README.md"])))
+	require.NoError(t, os.WriteFile(readme, modified, 0644))
+	require.NoError(t, os.Chtimes(readme, info.ModTime(), info.ModTime()))
+	assert.False(t, isExtractionUpToDate(indexDir, outputDir, manifest.Layers, false))
+
+	// the removed file invalidates the extraction.
+	require.NoError(t, os.Remove(readme))
+	assert.False(t, isExtractionUpToDate(indexDir, outputDir, manifest.Layers, false))
+
+	// the different layers invalidate the extraction.
+	require.NoError(t, os.WriteFile(readme, []byte(files["docs/README.md"]), 0644))
+	assert.True(t, isExtractionUpToDate(indexDir, outputDir, manifest.Layers, false))
+	assert.False(t, isExtractionUpToDate(indexDir, outputDir, manifest.Layers[:1], false))
+}
+
+func TestExportModelArtifactForce(t *testing.T) {
+	mockStore, manifest, _ := newExtractFixture(t)
+	outputDir := t.TempDir()
+	require.NoError(t, exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 1, Output: outputDir}))
+
+	// the forced extraction pulls the blobs again.
+	for _, layer := range manifest.Layers {
+		mockStore.On("PullBlob", mock.Anything, "example.com/repo", layer.Digest.String()).Return(io.NopCloser(bytes.NewReader(nil)), nil).Once()
+	}
+
+	_ = exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 1, Output: outputDir, Force: true})
+	mockStore.AssertNumberOfCalls(t, "PullBlob", 4)
+}
+
+func TestExtractLayerExecutable(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "scripts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "scripts", "serve.sh"), []byte("#!/bin/sh"), 0644))
+	tarReader, err := archiver.Tar(filepath.Join(srcDir, "scripts", "serve.sh"), srcDir)
+	require.NoError(t, err)
+	tarBlob, err := io.ReadAll(tarReader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		mediaType  string
+		blob       []byte
+		executable bool
+		flatten    bool
+		expected   string
+		mode       os.FileMode
+	}{
+		{name: "raw script", mediaType: modelspec.MediaTypeModelCodeRaw, blob: []byte("#!/bin/sh"), executable: true, expected: "scripts/serve.sh", mode: 0755},
+		{name: "tar script", mediaType: modelspec.MediaTypeModelCode, blob: tarBlob, executable: true, expected: "scripts/serve.sh", mode: 0755},
+		{name: "flattened tar script", mediaType: modelspec.MediaTypeModelCode, blob: tarBlob, executable: true, flatten: true, expected: "serve.sh", mode: 0755},
+		{name: "not annotated", mediaType: modelspec.MediaTypeModelCode, blob: tarBlob, expected: "scripts/serve.sh", mode: 0644},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			desc := ocispec.Descriptor{
+				MediaType:   tc.mediaType,
+				Digest:      godigest.FromBytes(tc.blob),
+				Size:        int64(len(tc.blob)),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: "scripts/serve.sh"},
+			}
+			if tc.executable {
+				desc.Annotations[pkgcodec.AnnotationExecutable] = "true"
+			}
+
+			outputDir := t.TempDir()
+			extractor, err := newLayerExtractor(&config.Extract{Output: outputDir, Flatten: tc.flatten})
+			require.NoError(t, err)
+			require.NoError(t, extractor.extractLayer(desc, bytes.NewReader(tc.blob)))
+
+			info, err := os.Stat(filepath.Join(outputDir, tc.expected))
+			require.NoError(t, err)
+			assert.Equal(t, tc.mode, info.Mode().Perm())
+		})
+	}
+}
+
+func TestExtractDatasets(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("images/cat.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("meow"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	modelfilePath := filepath.Join(t.TempDir(), "Modelfile")
+	require.NoError(t, os.WriteFile(modelfilePath, []byte("NAME dataset\nMODEL model.safetensors\nDATASET data/images.zip\n"), 0644))
+
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewBuild()
+	cfg.Raw = false
+	require.NoError(t, b.BuildFromFiles(ctx, modelfilePath, map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"data/images.zip":   buf.Bytes(),
+	}, "example.com/repo:v1", cfg))
+
+	manifestRaw, _, err := store.PullManifest(ctx, "example.com/repo", "v1")
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+	require.Len(t, manifest.Layers, 2)
+	// the dataset archive is stored as is even if the other layers are tarred.
+	assert.Equal(t, modelspec.MediaTypeModelDatasetRaw, manifest.Layers[1].MediaType)
+
+	t.Run("pass through by default", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 2, Output: outputDir}))
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "data/images.zip"))
+		require.NoError(t, err)
+		assert.Equal(t, buf.Bytes(), data)
+		assert.NoDirExists(t, filepath.Join(outputDir, "data/images"))
+	})
+
+	t.Run("extract datasets", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/repo:v1", &config.Extract{Concurrency: 2, Output: outputDir, ExtractDatasets: true}))
+
+		data, err := os.ReadFile(filepath.Join(outputDir, "data/images/images/cat.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "meow", string(data))
+		assert.FileExists(t, filepath.Join(outputDir, "data/images.zip"))
+	})
+}
+
+func TestExportModelArtifactConcurrency(t *testing.T) {
+	// the tar layers share the nested directories, which are created concurrently.
+	srcDir := t.TempDir()
+	files := map[string]string{}
+	blobs := map[string][]byte{}
+	manifest := ocispec.Manifest{}
+	for i := range 32 {
+		name := fmt.Sprintf("src/pkg%d/module/file%d.py", i%4, i)
+		files[name] = fmt.Sprintf("print(%d)", i)
+		require.NoError(t, os.MkdirAll(filepath.Join(srcDir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(srcDir, name), []byte(files[name]), 0644))
+
+		reader, err := archiver.Tar(filepath.Join(srcDir, name), srcDir)
+		require.NoError(t, err)
+		blob, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		digest := godigest.FromBytes(blob)
+		blobs[digest.String()] = blob
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+			MediaType:   modelspec.MediaTypeModelCode,
+			Digest:      digest,
+			Size:        int64(len(blob)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		})
+	}
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", mock.Anything).Return(
+		func(ctx context.Context, repo, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(blobs[digest])), nil
+		},
+	)
+
+	outputDir := t.TempDir()
+	require.NoError(t, exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 8, Output: outputDir}))
+
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, content, string(data), name)
+	}
+
+	// no temporary files are left behind in the shared directories.
+	require.NoError(t, filepath.WalkDir(outputDir, func(path string, d os.DirEntry, err error) error {
+		require.NoError(t, err)
+		assert.NotContains(t, d.Name(), ".tmp", path)
+		return nil
+	}))
+}
+
+func TestExportModelArtifactOverlappingDirs(t *testing.T) {
+	// the directory layers and the raw files share the directories, which are created and
+	// restored concurrently, run with -race to check the extraction for the data races.
+	srcDir := t.TempDir()
+	files := map[string]string{}
+	blobs := map[string][]byte{}
+	manifest := ocispec.Manifest{}
+	addLayer := func(name, mediaType string, blob []byte) {
+		digest := godigest.FromBytes(blob)
+		blobs[digest.String()] = blob
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+			MediaType:   mediaType,
+			Digest:      digest,
+			Size:        int64(len(blob)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		})
+	}
+
+	for i := range 8 {
+		dir := fmt.Sprintf("src/pkg%d", i%4)
+		name := fmt.Sprintf("%s/layer%d/module/file%d.py", dir, i, i)
+		files[name] = fmt.Sprintf("print(%d)", i)
+		require.NoError(t, os.MkdirAll(filepath.Join(srcDir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(srcDir, name), []byte(files[name]), 0644))
+
+		reader, err := archiver.Tar(filepath.Join(srcDir, dir, fmt.Sprintf("layer%d", i)), srcDir)
+		require.NoError(t, err)
+		blob, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		addLayer(fmt.Sprintf("%s/layer%d", dir, i), modelspec.MediaTypeModelCode, blob)
+
+		raw := fmt.Sprintf("%s/layer%d/module/weights%d.bin", dir, i, i)
+		files[raw] = fmt.Sprintf("weights %d", i)
+		addLayer(raw, modelspec.MediaTypeModelWeightRaw, []byte(files[raw]))
+	}
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", mock.Anything).Return(
+		func(ctx context.Context, repo, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(blobs[digest])), nil
+		},
+	)
+
+	for range 10 {
+		outputDir := t.TempDir()
+		require.NoError(t, exportModelArtifact(context.Background(), mockStore, manifest, "example.com/repo", &config.Extract{Concurrency: 16, Output: outputDir}))
+
+		for name, content := range files {
+			data, err := os.ReadFile(filepath.Join(outputDir, name))
+			require.NoError(t, err, name)
+			assert.Equal(t, content, string(data), name)
+		}
+	}
+}
+
+func TestExtractLongPath(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	longPath := strings.Repeat("nested/", 10) + strings.Repeat("a", 250) + ".safetensors"
+	storeModel(t, b, "example.com/models/long", "v1", map[string][]byte{
+		"config.json": []byte("{}"),
+		longPath:      []byte("weights"),
+	}, []string{"config.json", longPath})
+
+	t.Run("error", func(t *testing.T) {
+		err := b.Extract(ctx, "example.com/models/long:v1", &config.Extract{Concurrency: 1, Output: t.TempDir()})
+		assert.ErrorIs(t, err, archiver.ErrPathTooLong)
+	})
+
+	t.Run("shorten", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, b.Extract(ctx, "example.com/models/long:v1", &config.Extract{Concurrency: 1, Output: outputDir, ShortenLongPaths: true}))
+		assert.FileExists(t, filepath.Join(outputDir, "config.json"))
+
+		data, err := os.ReadFile(filepath.Join(outputDir, archiver.PathMappingFilename))
+		require.NoError(t, err)
+		var mapping map[string]string
+		require.NoError(t, json.Unmarshal(data, &mapping))
+		require.Len(t, mapping, 1)
+		for shortened, original := range mapping {
+			assert.Equal(t, longPath, original)
+			assert.True(t, strings.HasSuffix(shortened, ".safetensors"))
+
+			content, err := os.ReadFile(filepath.Join(outputDir, shortened))
+			require.NoError(t, err)
+			assert.Equal(t, "weights", string(content))
+		}
+	})
+}
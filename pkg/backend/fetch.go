@@ -20,16 +20,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
-	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
-	modelspec "github.com/modelpack/model-spec/specs-go/v1"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	internalpb "github.com/modelpack/modctl/internal/pb"
 	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/concurrency"
 	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/pkg/iometrics"
 )
@@ -76,32 +77,9 @@ func (b *backend) Fetch(ctx context.Context, target string, cfg *config.Fetch) e
 
 	logrus.Debugf("fetch: loaded manifest for target %s [manifest: %+v]", target, manifest)
 
-	layers := []ocispec.Descriptor{}
-	// filter the layers by patterns.
-	for _, layer := range manifest.Layers {
-		for _, pattern := range cfg.Patterns {
-			if anno := layer.Annotations; anno != nil {
-				path := anno[modelspec.AnnotationFilepath]
-				if path == "" {
-					path = anno[legacymodelspec.AnnotationFilepath]
-				}
-				// Use doublestar.PathMatch for pattern matching to support ** recursive matching
-				// PathMatch uses the system's native path separator (like filepath.Match) while
-				// also supporting recursive patterns like **/*.json
-				matched, err := doublestar.PathMatch(pattern, path)
-				if err != nil {
-					return fmt.Errorf("failed to match pattern: %w", err)
-				}
-
-				if matched {
-					layers = append(layers, layer)
-				}
-			}
-		}
-	}
-
-	if len(layers) == 0 {
-		return fmt.Errorf("no layers matched the patterns")
+	layers, err := matchLayers(manifest.Layers, cfg.Patterns, cfg.MediaTypes)
+	if err != nil {
+		return err
 	}
 
 	pb := internalpb.NewProgressBar()
@@ -113,6 +91,18 @@ func (b *backend) Fetch(ctx context.Context, target string, cfg *config.Fetch) e
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(cfg.Concurrency)
 
+	// adapt the concurrency by the throughput and the failures in the auto mode.
+	var controller *concurrency.Controller
+	if cfg.AutoConcurrency {
+		controller = concurrency.NewController("fetch")
+		g.SetLimit(controller.Max())
+	}
+
+	extractor, err := newLayerExtractor(&config.Extract{Output: cfg.Output, OnConflict: cfg.OnConflict, DecryptionKey: cfg.DecryptionKey})
+	if err != nil {
+		return err
+	}
+
 	logrus.Infof("fetch: fetching %d matched layers", len(layers))
 	for _, layer := range layers {
 		g.Go(func() error {
@@ -129,8 +119,10 @@ func (b *backend) Fetch(ctx context.Context, target string, cfg *config.Fetch) e
 				cfg.Hooks.AfterPullLayer(layer, true, nil)
 				return nil
 			}
-			if err := tracker.TrackTransfer(func() error {
-				return pullAndExtractFromRemote(ctx, pb, internalpb.NormalizePrompt("Fetching blob"), client, cfg.Output, layer, tracker)
+			if err := controller.Do(ctx, layer.Size, func() error {
+				return tracker.TrackTransfer(func() error {
+					return pullAndExtractFromRemote(ctx, pb, internalpb.NormalizePrompt("Fetching blob"), client, extractor, layer, tracker)
+				})
 			}); err != nil {
 				cfg.Hooks.AfterPullLayer(layer, false, err)
 				return err
@@ -150,3 +142,61 @@ func (b *backend) Fetch(ctx context.Context, target string, cfg *config.Fetch) e
 	logrus.Infof("fetch: fetched %d layers", len(layers))
 	return nil
 }
+
+// matchLayers returns the layers whose filepaths match any of the patterns and whose media types
+// match any of the media types, which are either the kinds of the layers such as config and code or
+// the full media types. The empty patterns or media types match all the layers.
+func matchLayers(layers []ocispec.Descriptor, patterns, mediaTypes []string) ([]ocispec.Descriptor, error) {
+	matched := []ocispec.Descriptor{}
+	for _, layer := range layers {
+		if len(mediaTypes) > 0 && !slices.ContainsFunc(mediaTypes, func(mediaType string) bool {
+			return mediaType == layer.MediaType || strings.EqualFold(mediaType, layerKind(layer.MediaType))
+		}) {
+			continue
+		}
+
+		ok, err := matchPatterns(patterns, layerFilepath(layer))
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			matched = append(matched, layer)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no layers matched the patterns and media types")
+	}
+
+	return matched, nil
+}
+
+// matchPatterns reports whether the path matches any of the patterns, the empty patterns match
+// all the paths.
+func matchPatterns(patterns []string, path string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+
+	// the layers without the filepath can't be matched by the patterns.
+	if path == "" {
+		return false, nil
+	}
+
+	for _, pattern := range patterns {
+		// Use doublestar.PathMatch for pattern matching to support ** recursive matching
+		// PathMatch uses the system's native path separator (like filepath.Match) while
+		// also supporting recursive patterns like **/*.json
+		matched, err := doublestar.PathMatch(pattern, path)
+		if err != nil {
+			return false, fmt.Errorf("failed to match pattern: %w", err)
+		}
+
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
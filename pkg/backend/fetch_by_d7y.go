@@ -28,7 +28,6 @@ import (
 	common "d7y.io/api/v2/pkg/apis/common/v2"
 	dfdaemon "d7y.io/api/v2/pkg/apis/dfdaemon/v2"
 	"github.com/avast/retry-go/v4"
-	"github.com/bmatcuk/doublestar/v4"
 	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -73,32 +72,10 @@ func (b *backend) fetchByDragonfly(ctx context.Context, target string, cfg *conf
 
 	logrus.Debugf("fetch: loaded manifest for target %s [manifest: %+v]", target, manifest)
 
-	// Filter layers by patterns.
-	layers := []ocispec.Descriptor{}
-	for _, layer := range manifest.Layers {
-		for _, pattern := range cfg.Patterns {
-			if anno := layer.Annotations; anno != nil {
-				path := anno[modelspec.AnnotationFilepath]
-				if path == "" {
-					path = anno[legacymodelspec.AnnotationFilepath]
-				}
-				// Use doublestar.PathMatch for pattern matching to support ** recursive matching
-				// PathMatch uses the system's native path separator (like filepath.Match) while
-				// also supporting recursive patterns like **/*.json
-				matched, err := doublestar.PathMatch(pattern, path)
-				if err != nil {
-					return fmt.Errorf("failed to match pattern: %w", err)
-				}
-
-				if matched {
-					layers = append(layers, layer)
-				}
-			}
-		}
-	}
-
-	if len(layers) == 0 {
-		return fmt.Errorf("no layers matched the patterns")
+	// Filter layers by patterns and media types.
+	layers, err := matchLayers(manifest.Layers, cfg.Patterns, cfg.MediaTypes)
+	if err != nil {
+		return err
 	}
 
 	// Get authentication token.
@@ -119,6 +96,10 @@ func (b *backend) fetchByDragonfly(ctx context.Context, target string, cfg *conf
 		internalpb.SetDisableProgress(true)
 	}
 
+	if err := checkUnencrypted(layers); err != nil {
+		return err
+	}
+
 	pb := internalpb.NewProgressBar(cfg.ProgressWriter)
 	pb.Start()
 	defer pb.Stop()
@@ -210,6 +191,18 @@ func downloadAndExtractFetchLayer(ctx context.Context, pb *internalpb.ProgressBa
 	isTar := strings.HasSuffix(desc.MediaType, mediaTypeTarSuffix)
 	if isTar {
 		outputPath += mediaTypeTarSuffix
+	} else {
+		// resolve the conflict with the existing file before downloading, the tar is resolved per entry on untar.
+		extract, err := archiver.ResolveConflictByDigest(outputPath, cfg.OnConflict, desc.Digest)
+		if err != nil {
+			return err
+		}
+
+		if !extract {
+			logrus.Infof("fetch: skipping layer %s, the existing file is kept by the conflict policy", desc.Digest.String())
+			pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
+			return nil
+		}
 	}
 
 	// Download layer via Dragonfly.
@@ -263,24 +256,30 @@ func downloadAndExtractFetchLayer(ctx context.Context, pb *internalpb.ProgressBa
 
 	// Extract tar if applicable.
 	if isTar {
-		return extractFetchTar(outputPath, outputAbs)
+		return extractFetchTar(outputPath, outputAbs, cfg.OnConflict, cfg.KeepTar)
 	}
 
 	return nil
 }
 
-// extractFetchTar untars a file and removes it afterward.
-func extractFetchTar(tarPath, extractDir string) error {
+// extractFetchTar untars a file and removes it afterward unless keepTar is set.
+func extractFetchTar(tarPath, extractDir string, policy config.ConflictPolicy, keepTar bool) error {
 	file, err := os.Open(tarPath)
 	if err != nil {
 		return fmt.Errorf("failed to open tar: %w", err)
 	}
 	defer file.Close()
 
-	if err := archiver.Untar(file, extractDir); err != nil {
+	if err := archiver.Untar(file, extractDir, archiver.WithConflictPolicy(policy)); err != nil {
 		return fmt.Errorf("failed to untar: %w", err)
 	}
 
+	if keepTar {
+		logrus.Infof("fetch: kept the staged tar %s", tarPath)
+		fmt.Fprintf(os.Stderr, "Kept tar: %s\n", tarPath)
+		return nil
+	}
+
 	if err := os.Remove(tarPath); err != nil {
 		return fmt.Errorf("failed to remove tar: %w", err)
 	}
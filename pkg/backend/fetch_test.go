@@ -23,7 +23,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
@@ -186,3 +188,112 @@ func TestFetch(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchMediaTypes(t *testing.T) {
+	files := map[string]struct {
+		mediaType string
+		content   string
+	}{
+		"config.json":       {modelspec.MediaTypeModelWeightConfigRaw, `{"hidden_size": 4096}`},
+		"modeling.py":       {modelspec.MediaTypeModelCodeRaw, "import torch"},
+		"model.safetensors": {modelspec.MediaTypeModelWeightRaw, "weights"},
+		"README.md":         {modelspec.MediaTypeModelDocRaw, "# model"},
+	}
+
+	manifest := ocispec.Manifest{}
+	blobs := map[string]string{}
+	for _, name := range []string{"config.json", "modeling.py", "model.safetensors", "README.md"} {
+		file := files[name]
+		digest := godigest.FromString(file.content)
+		blobs[fmt.Sprintf("/v2/test/model/blobs/%s", digest)] = file.content
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+			MediaType:   file.mediaType,
+			Digest:      digest,
+			Size:        int64(len(file.content)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		})
+	}
+
+	var mu sync.Mutex
+	fetched := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/v2/test/model/manifests/latest":
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(manifest))
+		case blobs[r.URL.Path] != "":
+			mu.Lock()
+			fetched[blobs[r.URL.Path]] = true
+			mu.Unlock()
+			_, err := w.Write([]byte(blobs[r.URL.Path]))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	b := &backend{}
+	target := strings.TrimPrefix(server.URL, "http://") + "/test/model:latest"
+
+	t.Run("config and code", func(t *testing.T) {
+		output := t.TempDir()
+		cfg := &config.Fetch{Output: output, MediaTypes: []string{"config", "CODE"}, PlainHTTP: true, Concurrency: 2}
+		require.NoError(t, b.Fetch(context.Background(), target, cfg))
+
+		assert.FileExists(t, filepath.Join(output, "config.json"))
+		assert.FileExists(t, filepath.Join(output, "modeling.py"))
+		assert.NoFileExists(t, filepath.Join(output, "model.safetensors"))
+		assert.NoFileExists(t, filepath.Join(output, "README.md"))
+		assert.False(t, fetched[files["model.safetensors"].content], "weights must be skipped")
+	})
+
+	t.Run("combined with patterns", func(t *testing.T) {
+		output := t.TempDir()
+		cfg := &config.Fetch{Output: output, Patterns: []string{"*.md", "*.py"}, MediaTypes: []string{modelspec.MediaTypeModelDocRaw}, PlainHTTP: true, Concurrency: 2}
+		require.NoError(t, b.Fetch(context.Background(), target, cfg))
+
+		assert.FileExists(t, filepath.Join(output, "README.md"))
+		assert.NoFileExists(t, filepath.Join(output, "modeling.py"))
+	})
+
+	t.Run("no matched layers", func(t *testing.T) {
+		cfg := &config.Fetch{Output: t.TempDir(), Patterns: []string{"*.json"}, MediaTypes: []string{"code"}, PlainHTTP: true, Concurrency: 2}
+		assert.ErrorContains(t, b.Fetch(context.Background(), target, cfg), "no layers matched")
+	})
+}
+
+func TestMatchLayers(t *testing.T) {
+	layers := []ocispec.Descriptor{
+		{MediaType: modelspec.MediaTypeModelWeightRaw, Annotations: map[string]string{modelspec.AnnotationFilepath: "model.safetensors"}},
+		{MediaType: modelspec.MediaTypeModelWeightConfigRaw, Annotations: map[string]string{modelspec.AnnotationFilepath: "config.json"}},
+		{MediaType: modelspec.MediaTypeModelCode},
+	}
+
+	testCases := []struct {
+		name       string
+		patterns   []string
+		mediaTypes []string
+		expected   []string
+	}{
+		{name: "patterns", patterns: []string{"*.json", "config.*"}, expected: []string{"config.json"}},
+		{name: "kinds", mediaTypes: []string{"weights", "code"}, expected: []string{"model.safetensors", ""}},
+		{name: "full media type", mediaTypes: []string{modelspec.MediaTypeModelWeightConfigRaw}, expected: []string{"config.json"}},
+		{name: "combined", patterns: []string{"*"}, mediaTypes: []string{"code", "config"}, expected: []string{"config.json"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matched, err := matchLayers(layers, tc.patterns, tc.mediaTypes)
+			require.NoError(t, err)
+
+			paths := []string{}
+			for _, layer := range matched {
+				paths = append(paths, layerFilepath(layer))
+			}
+			assert.Equal(t, tc.expected, paths)
+		})
+	}
+}
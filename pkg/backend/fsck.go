@@ -0,0 +1,312 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// FsckReport is the data model to represent the result of the local storage check.
+type FsckReport struct {
+	// Checked is the number of the checked blobs.
+	Checked int `json:"checked"`
+	// Corrupted is the list of the corrupted blobs.
+	Corrupted []*CorruptedBlob `json:"corrupted"`
+	// Invalid is the list of the model artifacts whose manifest or config violates the model spec.
+	Invalid []*InvalidArtifact `json:"invalid"`
+}
+
+// CorruptedBlob is the data model to represent a corrupted blob.
+type CorruptedBlob struct {
+	// Repository is the repository of the model artifact referencing the blob.
+	Repository string `json:"repository"`
+	// Reference is the tag or digest of the model artifact referencing the blob.
+	Reference string `json:"reference"`
+	// Digest is the digest of the blob.
+	Digest string `json:"digest"`
+	// Filepath is the filepath annotation of the blob if exists.
+	Filepath string `json:"filepath,omitempty"`
+	// Reason is the reason why the blob is considered as corrupted.
+	Reason string `json:"reason"`
+}
+
+// InvalidArtifact is the data model to represent a model artifact failed the schema validation.
+type InvalidArtifact struct {
+	// Repository is the repository of the model artifact.
+	Repository string `json:"repository"`
+	// Reference is the tag or digest of the model artifact.
+	Reference string `json:"reference"`
+	// Problems is the list of the problems found by the validation.
+	Problems []string `json:"problems"`
+}
+
+// Fsck checks the integrity of the blobs in the local storage, it checks all the model
+// artifacts if the target is empty. The layers annotated with the fast checksum are checked
+// by the fast checksum first, and fall back to the sha256 digest if the fast checksum is
+// absent or the full check is required. The progress of hashing the large blobs is
+// checkpointed if the checkpoint directory is configured, so the interrupted check resumes
+// from the last checkpoint instead of restarting from zero.
+func (b *backend) Fsck(ctx context.Context, target string, cfg *config.Fsck) (*FsckReport, error) {
+	logrus.Infof("fsck: checking local storage [target: %s]", target)
+
+	refs, err := b.fsckReferences(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the blobs to check, the blobs shared by multiple references are only checked once.
+	jobs := []fsckJob{}
+	invalid := []*InvalidArtifact{}
+	checked := map[string]struct{}{}
+	for _, ref := range refs {
+		manifestRaw, _, err := b.store.PullManifest(ctx, ref.repo, ref.reference)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pull manifest of %s:%s: %w", ref.repo, ref.reference, err)
+		}
+
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal manifest of %s:%s: %w", ref.repo, ref.reference, err)
+		}
+
+		problems := b.fsckValidate(ctx, ref.repo, &manifest)
+		if cfg.MerkleRoot != "" {
+			if actual := merkleRoot(manifest.Layers); actual != cfg.MerkleRoot {
+				problems = append(problems, fmt.Sprintf("manifest: merkle root %s does not match the expected %s", actual, cfg.MerkleRoot))
+			}
+		}
+
+		if len(problems) > 0 {
+			for _, problem := range problems {
+				logrus.Warnf("fsck: found invalid model artifact %s:%s: %s", ref.repo, ref.reference, problem)
+			}
+
+			invalid = append(invalid, &InvalidArtifact{Repository: ref.repo, Reference: ref.reference, Problems: problems})
+		}
+
+		for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+			if _, ok := checked[desc.Digest.String()]; ok {
+				continue
+			}
+			checked[desc.Digest.String()] = struct{}{}
+			jobs = append(jobs, fsckJob{ref: ref, desc: desc})
+		}
+	}
+
+	// check the blobs concurrently as the digest computation is CPU-bound for the large blobs.
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// persist the progress of hashing the blobs to resume the interrupted check.
+	var checkpoints *checksum.CheckpointStore
+	if cfg.CheckpointDir != "" {
+		checkpoints, err = checksum.NewCheckpointStore(cfg.CheckpointDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reasons := make([]string, len(jobs))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, job := range jobs {
+		g.Go(func() error {
+			reason, err := b.fsckBlob(gctx, job.ref.repo, job.desc, cfg.Full, checkpoints)
+			if err != nil {
+				return err
+			}
+
+			reasons[i] = reason
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	report := &FsckReport{Checked: len(jobs), Corrupted: []*CorruptedBlob{}, Invalid: invalid}
+	for i, job := range jobs {
+		if reasons[i] == "" {
+			continue
+		}
+
+		logrus.Warnf("fsck: found corrupted blob %s in %s:%s: %s", job.desc.Digest, job.ref.repo, job.ref.reference, reasons[i])
+		report.Corrupted = append(report.Corrupted, &CorruptedBlob{
+			Repository: job.ref.repo,
+			Reference:  job.ref.reference,
+			Digest:     job.desc.Digest.String(),
+			Filepath:   job.desc.Annotations[modelspec.AnnotationFilepath],
+			Reason:     reasons[i],
+		})
+	}
+
+	logrus.Infof("fsck: checked local storage [checked: %d, corrupted: %d, invalid: %d]", report.Checked, len(report.Corrupted), len(report.Invalid))
+	return report, nil
+}
+
+// fsckReference is the repository and the tag or digest of the model artifact to check.
+type fsckReference struct {
+	repo      string
+	reference string
+}
+
+// fsckJob is the blob to check and the reference of the model artifact referencing it.
+type fsckJob struct {
+	ref  fsckReference
+	desc ocispec.Descriptor
+}
+
+// fsckReferences returns the references of the model artifacts to check.
+func (b *backend) fsckReferences(ctx context.Context, target string) ([]fsckReference, error) {
+	if target != "" {
+		ref, err := ParseReference(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse target: %w", err)
+		}
+
+		reference := ref.Tag()
+		if ref.Digest() != "" {
+			reference = ref.Digest()
+		}
+
+		if reference == "" {
+			return nil, fmt.Errorf("invalid reference, tag or digest must be provided")
+		}
+
+		return []fsckReference{{repo: ref.Repository(), reference: reference}}, nil
+	}
+
+	repos, err := b.store.ListRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	refs := []fsckReference{}
+	for _, repo := range repos {
+		tags, err := b.store.ListTags(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags in repository %s: %w", repo, err)
+		}
+
+		for _, tag := range tags {
+			refs = append(refs, fsckReference{repo: repo, reference: tag})
+		}
+	}
+
+	return refs, nil
+}
+
+// fsckValidate validates the manifest and the model config of the model artifact, the config
+// is only validated if it can be decoded as the unreadable config is reported by the blob check.
+func (b *backend) fsckValidate(ctx context.Context, repo string, manifest *ocispec.Manifest) []string {
+	reader, err := b.store.PullBlob(ctx, repo, manifest.Config.Digest.String())
+	if err != nil {
+		return validateModelArtifact(manifest, nil)
+	}
+	defer reader.Close()
+
+	var model modelspec.Model
+	if err := json.NewDecoder(reader).Decode(&model); err != nil {
+		return append(validateModelArtifact(manifest, nil), fmt.Sprintf("config: failed to decode: %s", err))
+	}
+
+	return validateModelArtifact(manifest, &model)
+}
+
+// fsckBlob checks the integrity of the blob, it returns the reason if the blob is corrupted,
+// otherwise returns empty. The sha256 digest is resumed from and checkpointed to the
+// checkpoints if not nil.
+func (b *backend) fsckBlob(ctx context.Context, repo string, desc ocispec.Descriptor, full bool, checkpoints *checksum.CheckpointStore) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if expected := desc.Annotations[checksum.AnnotationFastChecksum]; expected != "" {
+		reader, err := b.store.PullBlob(ctx, repo, desc.Digest.String())
+		if err != nil {
+			return fmt.Sprintf("failed to read blob: %s", err), nil
+		}
+
+		ok, err := checksum.Verify(expected, reader)
+		reader.Close()
+		switch {
+		case errors.Is(err, checksum.ErrUnsupportedAlgorithm):
+			logrus.Warnf("fsck: %s, fall back to verify digest of blob %s", err, desc.Digest)
+		case err != nil:
+			return fmt.Sprintf("failed to read blob: %s", err), nil
+		case !ok:
+			return "fast checksum mismatch", nil
+		case !full:
+			return "", nil
+		}
+	}
+
+	reader, err := b.store.PullBlob(ctx, repo, desc.Digest.String())
+	if err != nil {
+		return fmt.Sprintf("failed to read blob: %s", err), nil
+	}
+	defer reader.Close()
+
+	// compute the sha256 digest by the SIMD accelerated hash with the pipelined reads,
+	// and fall back to the verifier of the digest for other algorithms.
+	var (
+		size     int64
+		verified bool
+	)
+	if desc.Digest.Algorithm() == godigest.SHA256 {
+		var digest string
+		digest, size, err = checksum.ResumableSHA256(ctx, reader, desc.Digest.String(), checkpoints, checksum.DefaultCheckpointInterval)
+		verified = digest == desc.Digest.String()
+	} else {
+		verifier := desc.Digest.Verifier()
+		size, err = checksum.PipelinedCopy(verifier, reader)
+		verified = verifier.Verified()
+	}
+	if err != nil {
+		// the interrupted check is not a corruption, which is resumed next time.
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		return fmt.Sprintf("failed to read blob: %s", err), nil
+	}
+
+	if size != desc.Size {
+		return fmt.Sprintf("size mismatch, expected %d but got %d", desc.Size, size), nil
+	}
+
+	if !verified {
+		return "digest mismatch", nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,372 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"testing"
+	"testing/iotest"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestFsck(t *testing.T) {
+	ctx := context.Background()
+	repo := "example.com/repo"
+
+	healthyContent := []byte("healthy weights")
+	corruptedContent := []byte("corrupted weights")
+	plainContent := []byte("plain weights without fast checksum")
+
+	fastChecksum := func(content []byte) string {
+		sum, err := checksum.Compute(bytes.NewReader(content))
+		require.NoError(t, err)
+		return sum
+	}
+
+	healthyDesc := ocispec.Descriptor{
+		MediaType: modelspec.MediaTypeModelWeightRaw,
+		Digest:    godigest.FromBytes(healthyContent),
+		Size:      int64(len(healthyContent)),
+		Annotations: map[string]string{
+			modelspec.AnnotationFilepath:    "healthy.safetensors",
+			checksum.AnnotationFastChecksum: fastChecksum(healthyContent),
+		},
+	}
+	corruptedDesc := ocispec.Descriptor{
+		MediaType: modelspec.MediaTypeModelWeightRaw,
+		Digest:    godigest.FromBytes(corruptedContent),
+		Size:      int64(len(corruptedContent)),
+		Annotations: map[string]string{
+			modelspec.AnnotationFilepath:    "corrupted.safetensors",
+			checksum.AnnotationFastChecksum: fastChecksum(corruptedContent),
+		},
+	}
+	plainDesc := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelWeightRaw,
+		Digest:      godigest.FromBytes(plainContent),
+		Size:        int64(len(plainContent)),
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "plain.safetensors"},
+	}
+
+	configContent, err := json.Marshal(modelspec.Model{
+		Descriptor: modelspec.ModelDescriptor{Name: "test"},
+		ModelFS:    modelspec.ModelFS{Type: "layers", DiffIDs: []godigest.Digest{healthyDesc.Digest, corruptedDesc.Digest, plainDesc.Digest}},
+	})
+	require.NoError(t, err)
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configContent), Size: int64(len(configContent))}
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Config: configDesc,
+		Layers: []ocispec.Descriptor{healthyDesc, corruptedDesc, plainDesc},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name              string
+		blobs             map[godigest.Digest][]byte
+		full              bool
+		expectedCorrupted map[string]string
+		expectedPulls     int
+	}{
+		{
+			name: "healthy blobs",
+			blobs: map[godigest.Digest][]byte{
+				configDesc.Digest:    configContent,
+				healthyDesc.Digest:   healthyContent,
+				corruptedDesc.Digest: corruptedContent,
+				plainDesc.Digest:     plainContent,
+			},
+			expectedCorrupted: map[string]string{},
+			expectedPulls:     5,
+		},
+		{
+			name: "fast checksum catches corrupted blob",
+			blobs: map[godigest.Digest][]byte{
+				configDesc.Digest:    configContent,
+				healthyDesc.Digest:   healthyContent,
+				corruptedDesc.Digest: []byte("corrupted weightz"),
+				plainDesc.Digest:     plainContent,
+			},
+			expectedCorrupted: map[string]string{"corrupted.safetensors": "fast checksum mismatch"},
+			expectedPulls:     5,
+		},
+		{
+			name: "digest catches corrupted blob without fast checksum",
+			blobs: map[godigest.Digest][]byte{
+				configDesc.Digest:    configContent,
+				healthyDesc.Digest:   healthyContent,
+				corruptedDesc.Digest: corruptedContent,
+				plainDesc.Digest:     []byte("plain weights without fast checksuM"),
+			},
+			expectedCorrupted: map[string]string{"plain.safetensors": "digest mismatch"},
+			expectedPulls:     5,
+		},
+		{
+			name: "full check verifies digest after fast checksum",
+			blobs: map[godigest.Digest][]byte{
+				configDesc.Digest:    configContent,
+				healthyDesc.Digest:   healthyContent,
+				corruptedDesc.Digest: corruptedContent,
+				plainDesc.Digest:     plainContent[:4],
+			},
+			full:              true,
+			expectedCorrupted: map[string]string{"plain.safetensors": "size mismatch, expected 35 but got 4"},
+			expectedPulls:     7,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockStore := &storage.Storage{}
+			b := &backend{store: mockStore}
+
+			mockStore.On("PullManifest", ctx, repo, "v1").Return(manifestRaw, "sha256:manifest", nil)
+			mockStore.On("PullBlob", mock.Anything, repo, mock.Anything).Return(
+				func(ctx context.Context, repo string, digest string) (io.ReadCloser, error) {
+					return io.NopCloser(bytes.NewReader(tc.blobs[godigest.Digest(digest)])), nil
+				},
+				nil,
+			)
+
+			report, err := b.Fsck(ctx, repo+":v1", &config.Fsck{Full: tc.full})
+			require.NoError(t, err)
+			assert.Equal(t, 4, report.Checked)
+
+			corrupted := map[string]string{}
+			for _, blob := range report.Corrupted {
+				assert.Equal(t, repo, blob.Repository)
+				assert.Equal(t, "v1", blob.Reference)
+				corrupted[blob.Filepath] = blob.Reason
+			}
+			assert.Equal(t, tc.expectedCorrupted, corrupted)
+			assert.Empty(t, report.Invalid)
+			mockStore.AssertNumberOfCalls(t, "PullBlob", tc.expectedPulls)
+		})
+	}
+}
+
+func TestFsckInvalidArtifact(t *testing.T) {
+	ctx := context.Background()
+	repo := "example.com/repo"
+
+	weightContent := []byte("weights")
+	weightDesc := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelWeightRaw,
+		Digest:      godigest.FromBytes(weightContent),
+		Size:        int64(len(weightContent)),
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "model.safetensors"},
+	}
+	configContent := []byte(`{"descriptor":{"name":"test"},"modelfs":{"type":"layers"}}`)
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configContent), Size: int64(len(configContent))}
+	manifestRaw, err := json.Marshal(ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{weightDesc}})
+	require.NoError(t, err)
+
+	blobs := map[godigest.Digest][]byte{configDesc.Digest: configContent, weightDesc.Digest: weightContent}
+	mockStore := &storage.Storage{}
+	mockStore.On("PullManifest", ctx, repo, "v1").Return(manifestRaw, "sha256:manifest", nil)
+	mockStore.On("PullBlob", mock.Anything, repo, mock.Anything).Return(
+		func(ctx context.Context, repo string, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(blobs[godigest.Digest(digest)])), nil
+		},
+		nil,
+	)
+	b := &backend{store: mockStore}
+
+	report, err := b.Fsck(ctx, repo+":v1", &config.Fsck{Concurrency: 1})
+	require.NoError(t, err)
+	assert.Empty(t, report.Corrupted)
+	require.Len(t, report.Invalid, 1)
+	assert.Equal(t, repo, report.Invalid[0].Repository)
+	assert.Equal(t, "v1", report.Invalid[0].Reference)
+	assert.Equal(t, []string{"config: modelfs.diffIds must not be empty for the model with weights"}, report.Invalid[0].Problems)
+}
+
+// newFsckManyBlobs returns the manifest with many layers and the mock storage serving them,
+// the layers in the corrupted set are served with the modified content.
+func newFsckManyBlobs(tb testing.TB, count, size int, corrupted map[int]bool) (*storage.Storage, []byte) {
+	rng := rand.New(rand.NewSource(1))
+	blobs := map[godigest.Digest][]byte{}
+	layers := make([]ocispec.Descriptor, 0, count)
+	for i := range count {
+		content := make([]byte, size)
+		rng.Read(content)
+		desc := ocispec.Descriptor{
+			MediaType:   modelspec.MediaTypeModelWeightRaw,
+			Digest:      godigest.FromBytes(content),
+			Size:        int64(size),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: fmt.Sprintf("model-%05d.safetensors", i)},
+		}
+
+		if corrupted[i] {
+			content = append([]byte{}, content...)
+			content[size/2] ^= 0xff
+		}
+
+		blobs[desc.Digest] = content
+		layers = append(layers, desc)
+	}
+
+	configContent := []byte(`{}`)
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configContent), Size: int64(len(configContent))}
+	blobs[configDesc.Digest] = configContent
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{Config: configDesc, Layers: layers})
+	require.NoError(tb, err)
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullBlob", mock.Anything, "example.com/repo", mock.Anything).Return(
+		func(ctx context.Context, repo string, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(blobs[godigest.Digest(digest)])), nil
+		},
+		nil,
+	)
+
+	return mockStore, manifestRaw
+}
+
+// seekableBlob is the seekable blob reader counting the bytes read.
+type seekableBlob struct {
+	*bytes.Reader
+	read int
+}
+
+func (r *seekableBlob) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func (r *seekableBlob) Close() error {
+	return nil
+}
+
+func TestFsckResume(t *testing.T) {
+	ctx := context.Background()
+	repo := "example.com/repo"
+
+	content := make([]byte, 10*1024*1024)
+	rand.New(rand.NewSource(1)).Read(content)
+	layer := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelWeightRaw,
+		Digest:      godigest.FromBytes(content),
+		Size:        int64(len(content)),
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "model.safetensors"},
+	}
+
+	configContent, err := json.Marshal(modelspec.Model{
+		Descriptor: modelspec.ModelDescriptor{Name: "test"},
+		ModelFS:    modelspec.ModelFS{Type: "layers", DiffIDs: []godigest.Digest{layer.Digest}},
+	})
+	require.NoError(t, err)
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configContent), Size: int64(len(configContent))}
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{layer}})
+	require.NoError(t, err)
+
+	cfg := config.NewFsck()
+	cfg.CheckpointDir = t.TempDir()
+
+	// the first check is interrupted by the read error in the middle of the layer.
+	interrupted := 6 * 1024 * 1024
+	mockStore := &storage.Storage{}
+	mockStore.On("PullManifest", ctx, repo, "v1").Return(manifestRaw, "sha256:manifest", nil)
+	mockStore.On("PullBlob", mock.Anything, repo, configDesc.Digest.String()).Return(
+		func(ctx context.Context, repo string, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(configContent)), nil
+		},
+		nil,
+	)
+	mockStore.On("PullBlob", mock.Anything, repo, layer.Digest.String()).Return(
+		io.NopCloser(io.MultiReader(bytes.NewReader(content[:interrupted]), iotest.ErrReader(errors.New("disk error")))), nil,
+	).Once()
+
+	b := &backend{store: mockStore}
+	report, err := b.Fsck(ctx, repo+":v1", cfg)
+	require.NoError(t, err)
+	require.Len(t, report.Corrupted, 1)
+	assert.Contains(t, report.Corrupted[0].Reason, "disk error")
+
+	// the second check resumes from the checkpoint and only reads the rest of the layer.
+	blob := &seekableBlob{Reader: bytes.NewReader(content)}
+	mockStore.On("PullBlob", mock.Anything, repo, layer.Digest.String()).Return(blob, nil).Once()
+
+	report, err = b.Fsck(ctx, repo+":v1", cfg)
+	require.NoError(t, err)
+	assert.Empty(t, report.Corrupted)
+	assert.Equal(t, len(content)-interrupted, blob.read)
+
+	// the checkpoint is removed once the check completes.
+	entries, err := os.ReadDir(cfg.CheckpointDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFsckManyBlobs(t *testing.T) {
+	ctx := context.Background()
+	corrupted := map[int]bool{3: true, 17: true, 42: true, 99: true}
+	mockStore, manifestRaw := newFsckManyBlobs(t, 100, 64*1024, corrupted)
+	mockStore.On("PullManifest", ctx, "example.com/repo", "v1").Return(manifestRaw, "sha256:manifest", nil)
+	b := &backend{store: mockStore}
+
+	report, err := b.Fsck(ctx, "example.com/repo:v1", &config.Fsck{Concurrency: 8})
+	require.NoError(t, err)
+	assert.Equal(t, 101, report.Checked)
+
+	// the corrupted blobs are reported in the order of the layers.
+	filepaths := []string{}
+	for _, blob := range report.Corrupted {
+		assert.Equal(t, "digest mismatch", blob.Reason)
+		filepaths = append(filepaths, blob.Filepath)
+	}
+	assert.Equal(t, []string{"model-00003.safetensors", "model-00017.safetensors", "model-00042.safetensors", "model-00099.safetensors"}, filepaths)
+}
+
+func BenchmarkFsck(b *testing.B) {
+	ctx := context.Background()
+	mockStore, manifestRaw := newFsckManyBlobs(b, 16, 16*1024*1024, nil)
+	mockStore.On("PullManifest", ctx, "example.com/repo", "v1").Return(manifestRaw, "sha256:manifest", nil)
+	backend := &backend{store: mockStore}
+
+	for _, concurrency := range []int{1, runtime.NumCPU()} {
+		b.Run(fmt.Sprintf("concurrency %d", concurrency), func(b *testing.B) {
+			b.SetBytes(16 * 16 * 1024 * 1024)
+			for i := 0; i < b.N; i++ {
+				if _, err := backend.Fsck(ctx, "example.com/repo:v1", &config.Fsck{Concurrency: concurrency}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
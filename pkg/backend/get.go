@@ -0,0 +1,53 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/internal/fieldpath"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// Get gets the field of the model config by the dot-separated path, such as config.precision,
+// the unset field returns nil.
+func (b *backend) Get(ctx context.Context, target string, cfg *config.Get) (any, error) {
+	logrus.Infof("get: getting field %s of target %s", cfg.Field, target)
+	if _, err := ParseReference(target); err != nil {
+		return nil, fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	manifest, err := b.getManifest(ctx, target, cfg.Remote, cfg.PlainHTTP, cfg.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest: %w", err)
+	}
+
+	model, err := b.getModelConfig(ctx, target, manifest.Config, cfg.Remote, cfg.PlainHTTP, cfg.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	value, err := fieldpath.Lookup(model, cfg.Field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get field %s: %w", cfg.Field, err)
+	}
+
+	return value, nil
+}
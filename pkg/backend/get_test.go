@@ -0,0 +1,108 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/internal/fieldpath"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	configRaw := []byte(`{
+  "descriptor": {"name": "llama3", "family": "llama", "licenses": ["Apache-2.0"]},
+  "config": {"precision": "bf16", "paramSize": "8b", "capabilities": {"reasoning": true, "input_types": ["text", "image"]}},
+  "modelfs": {"type": "layers", "diffIds": []}
+}`)
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configRaw), Size: int64(len(configRaw))}
+	_, _, err := store.PushBlob(ctx, "example.com/models/llama3", bytes.NewReader(configRaw), configDesc)
+	require.NoError(t, err)
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: modelspec.ArtifactTypeModelManifest,
+		Config:       configDesc,
+		Layers:       []ocispec.Descriptor{},
+	})
+	require.NoError(t, err)
+	_, err = store.PushManifest(ctx, "example.com/models/llama3", "v1", ocispec.MediaTypeImageManifest, manifestRaw)
+	require.NoError(t, err)
+
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{field: "config.precision", expected: "bf16"},
+		{field: "config.paramsize", expected: "8b"},
+		{field: "descriptor.name", expected: "llama3"},
+		{field: "descriptor.licenses.0", expected: "Apache-2.0"},
+		{field: "config.capabilities.reasoning", expected: "true"},
+		{field: "config.quantization", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			cfg := config.NewGet()
+			cfg.Field = tt.field
+			value, err := b.Get(ctx, "example.com/models/llama3:v1", cfg)
+			require.NoError(t, err)
+
+			text, err := fieldpath.Format(value, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+		})
+	}
+
+	t.Run("nested capabilities", func(t *testing.T) {
+		cfg := config.NewGet()
+		cfg.Field = "config.capabilities"
+		value, err := b.Get(ctx, "example.com/models/llama3:v1", cfg)
+		require.NoError(t, err)
+
+		_, err = fieldpath.Format(value, false)
+		assert.ErrorIs(t, err, fieldpath.ErrNotScalar)
+
+		text, err := fieldpath.Format(value, true)
+		require.NoError(t, err)
+		var capabilities map[string]any
+		require.NoError(t, json.Unmarshal([]byte(text), &capabilities))
+		assert.Equal(t, true, capabilities["reasoning"])
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		cfg := config.NewGet()
+		cfg.Field = "config.unknown"
+		_, err := b.Get(ctx, "example.com/models/llama3:v1", cfg)
+		assert.ErrorContains(t, err, `field "unknown" not found in "config"`)
+	})
+}
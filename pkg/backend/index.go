@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+)
+
+const (
+	// mediaTypeDockerManifest is the media type of the Docker image manifest.
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// mediaTypeDockerManifestList is the media type of the Docker manifest list.
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// isManifestMediaType returns whether the media type is of the manifest or the index, which should
+// be stored as the manifest rather than the blob.
+func isManifestMediaType(mediaType string) bool {
+	switch mediaType {
+	case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex, mediaTypeDockerManifest, mediaTypeDockerManifestList:
+		return true
+	default:
+		return false
+	}
+}
+
+// fetchManifest fetches and decodes the manifest of the reference from the remote. If the
+// reference points to an index, the child manifest matched by the selectors is resolved.
+func fetchManifest(ctx context.Context, src *remote.Repository, reference string, selectors map[string]string) (ocispec.Descriptor, ocispec.Manifest, error) {
+	_, desc, manifest, err := fetchRootManifest(ctx, src, reference, selectors)
+	return desc, manifest, err
+}
+
+// fetchRootManifest fetches the manifest as fetchManifest, and returns the descriptor the reference
+// resolves to as well, which is the index if the manifest is selected from it.
+func fetchRootManifest(ctx context.Context, src *remote.Repository, reference string, selectors map[string]string) (ocispec.Descriptor, ocispec.Descriptor, ocispec.Manifest, error) {
+	desc, reader, err := src.Manifests().FetchReference(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("failed to fetch the manifest: %w", err)
+	}
+	defer reader.Close()
+
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		var index ocispec.Index
+		if err := json.NewDecoder(reader).Decode(&index); err != nil {
+			return ocispec.Descriptor{}, ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("failed to decode the index: %w", err)
+		}
+
+		child, err := selectManifest(index, selectors)
+		if err != nil {
+			return ocispec.Descriptor{}, ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("failed to select the manifest from index %s: %w", reference, err)
+		}
+
+		logrus.Infof("pull: selected manifest %s from index %s", child.Digest, reference)
+		childDesc, manifest, err := fetchManifest(ctx, src, child.Digest.String(), nil)
+		return desc, childDesc, manifest, err
+	}
+
+	if len(selectors) > 0 {
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("%s is not an index, selectors only work with index", reference)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return ocispec.Descriptor{}, ocispec.Descriptor{}, ocispec.Manifest{}, fmt.Errorf("failed to decode the manifest: %w", err)
+	}
+
+	return desc, desc, manifest, nil
+}
+
+// selectManifest selects the only child manifest of the index whose annotations match all the
+// selectors. The selector key matches the annotation key exactly or its last dot-separated
+// segment, e.g. quantization matches org.cncf.model.quantization, and the value is compared
+// case-insensitively. The only child manifest is selected without the selectors.
+func selectManifest(index ocispec.Index, selectors map[string]string) (ocispec.Descriptor, error) {
+	if len(selectors) == 0 {
+		if len(index.Manifests) == 1 {
+			return index.Manifests[0], nil
+		}
+
+		return ocispec.Descriptor{}, fmt.Errorf("the index contains %d manifests, please specify the selectors to pick one of them: %s",
+			len(index.Manifests), describeManifests(index.Manifests))
+	}
+
+	matched := []ocispec.Descriptor{}
+	for _, desc := range index.Manifests {
+		if matchSelectors(desc.Annotations, selectors) {
+			matched = append(matched, desc)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return ocispec.Descriptor{}, fmt.Errorf("no manifest matches the selectors %s, available manifests: %s",
+			formatSelectors(selectors), describeManifests(index.Manifests))
+	case 1:
+		return matched[0], nil
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("%d manifests match the selectors %s, please specify more selectors: %s",
+			len(matched), formatSelectors(selectors), describeManifests(matched))
+	}
+}
+
+// matchSelectors returns true if the annotations match all the selectors.
+func matchSelectors(annotations, selectors map[string]string) bool {
+	for key, value := range selectors {
+		matched := false
+		for annotationKey, annotationValue := range annotations {
+			if annotationKey != key && !strings.HasSuffix(annotationKey, "."+key) {
+				continue
+			}
+
+			if strings.EqualFold(annotationValue, value) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatSelectors formats the selectors as the sorted key=value pairs.
+func formatSelectors(selectors map[string]string) string {
+	pairs := make([]string, 0, len(selectors))
+	for key, value := range selectors {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// describeManifests describes the manifests by the digest and annotations.
+func describeManifests(descs []ocispec.Descriptor) string {
+	descriptions := make([]string, 0, len(descs))
+	for _, desc := range descs {
+		descriptions = append(descriptions, fmt.Sprintf("%s [%s]", desc.Digest, formatSelectors(desc.Annotations)))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
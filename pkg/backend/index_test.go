@@ -0,0 +1,194 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+)
+
+// newIndexRegistry serves an index with the given variants, the key of the variants is the
+// name of the weight file and the value is the annotations of the child manifest.
+func newIndexRegistry(t *testing.T, variants map[string]map[string]string) (*httptest.Server, map[string]godigest.Digest) {
+	contents := map[string][]byte{}
+	mediaTypes := map[string]string{}
+	digests := map[string]godigest.Digest{}
+
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+	for name, annotations := range variants {
+		manifest, err := json.Marshal(ocispec.Manifest{
+			Versioned:    specs.Versioned{SchemaVersion: 2},
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: modelspec.ArtifactTypeModelManifest,
+			Config:       ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromString(name), Size: int64(len(name))},
+			Layers: []ocispec.Descriptor{{
+				MediaType:   modelspec.MediaTypeModelWeightRaw,
+				Digest:      godigest.FromString(name + ".gguf"),
+				Size:        int64(len(name + ".gguf")),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: name + ".gguf"},
+			}},
+		})
+		require.NoError(t, err)
+
+		dgst := godigest.FromBytes(manifest)
+		contents[dgst.String()] = manifest
+		mediaTypes[dgst.String()] = ocispec.MediaTypeImageManifest
+		digests[name] = dgst
+		index.Manifests = append(index.Manifests, ocispec.Descriptor{
+			MediaType:   ocispec.MediaTypeImageManifest,
+			Digest:      dgst,
+			Size:        int64(len(manifest)),
+			Annotations: annotations,
+		})
+	}
+
+	indexRaw, err := json.Marshal(index)
+	require.NoError(t, err)
+	contents["latest"] = indexRaw
+	mediaTypes["latest"] = ocispec.MediaTypeImageIndex
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reference := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		content, ok := contents[reference]
+		if !strings.Contains(r.URL.Path, "/manifests/") || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", mediaTypes[reference])
+		w.Header().Set("Docker-Content-Digest", godigest.FromBytes(content).String())
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, digests
+}
+
+func TestFetchManifestFromIndex(t *testing.T) {
+	server, digests := newIndexRegistry(t, map[string]map[string]string{
+		"q4":  {"org.cncf.model.quantization": "Q4_K_M", "org.cncf.model.format": "gguf"},
+		"q8":  {"org.cncf.model.quantization": "Q8_0", "org.cncf.model.format": "gguf"},
+		"f16": {"org.cncf.model.quantization": "", "org.cncf.model.format": "safetensors"},
+	})
+
+	src, err := remote.New(strings.TrimPrefix(server.URL, "http://")+"/models/test", remote.WithPlainHTTP(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name          string
+		selectors     map[string]string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:      "select by short keys",
+			selectors: map[string]string{"quantization": "Q4_K_M", "format": "gguf"},
+			expected:  "q4",
+		},
+		{
+			name:      "select by full key and case-insensitive value",
+			selectors: map[string]string{"org.cncf.model.quantization": "q8_0"},
+			expected:  "q8",
+		},
+		{
+			name:      "select by single key",
+			selectors: map[string]string{"format": "safetensors"},
+			expected:  "f16",
+		},
+		{
+			name:          "no selectors",
+			expectedError: "please specify the selectors",
+		},
+		{
+			name:          "zero matches",
+			selectors:     map[string]string{"quantization": "Q2_K"},
+			expectedError: "no manifest matches the selectors quantization=Q2_K",
+		},
+		{
+			name:          "multiple matches",
+			selectors:     map[string]string{"format": "gguf"},
+			expectedError: "2 manifests match the selectors format=gguf",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			desc, manifest, err := fetchManifest(ctx, src, "latest", tc.selectors)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, digests[tc.expected], desc.Digest)
+			assert.Equal(t, ocispec.MediaTypeImageManifest, desc.MediaType)
+			require.Len(t, manifest.Layers, 1)
+			assert.Equal(t, tc.expected+".gguf", manifest.Layers[0].Annotations[modelspec.AnnotationFilepath])
+		})
+	}
+
+	// Selectors only work with index.
+	_, _, err = fetchManifest(ctx, src, digests["q4"].String(), map[string]string{"format": "gguf"})
+	assert.ErrorContains(t, err, "is not an index")
+}
+
+func TestFetchManifestFromSingleManifestIndex(t *testing.T) {
+	server, digests := newIndexRegistry(t, map[string]map[string]string{
+		"q4": {"org.cncf.model.quantization": "Q4_K_M", "org.cncf.model.format": "gguf"},
+	})
+
+	src, err := remote.New(strings.TrimPrefix(server.URL, "http://")+"/models/test", remote.WithPlainHTTP(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The only manifest is selected without the selectors.
+	desc, manifest, err := fetchManifest(ctx, src, "latest", nil)
+	require.NoError(t, err)
+	assert.Equal(t, digests["q4"], desc.Digest)
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, "q4.gguf", manifest.Layers[0].Annotations[modelspec.AnnotationFilepath])
+
+	// The selectors still apply to the only manifest.
+	_, _, err = fetchManifest(ctx, src, "latest", map[string]string{"quantization": "Q8_0"})
+	assert.ErrorContains(t, err, "no manifest matches the selectors quantization=Q8_0")
+}
+
+func TestIsManifestMediaType(t *testing.T) {
+	assert.True(t, isManifestMediaType(ocispec.MediaTypeImageManifest))
+	assert.True(t, isManifestMediaType(ocispec.MediaTypeImageIndex))
+	assert.True(t, isManifestMediaType(mediaTypeDockerManifest))
+	assert.True(t, isManifestMediaType(mediaTypeDockerManifestList))
+	assert.False(t, isManifestMediaType(modelspec.MediaTypeModelConfig))
+	assert.False(t, isManifestMediaType(modelspec.MediaTypeModelWeightRaw))
+}
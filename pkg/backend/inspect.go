@@ -24,9 +24,9 @@ import (
 
 	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
 	modelspec "github.com/modelpack/model-spec/specs-go/v1"
-	godigest "github.com/opencontainers/go-digest"
 	"github.com/sirupsen/logrus"
 
+	"github.com/modelpack/modctl/pkg/checksum"
 	"github.com/modelpack/modctl/pkg/config"
 )
 
@@ -52,8 +52,12 @@ type InspectedModelArtifact struct {
 	Precision string `json:"Precision"`
 	// Quantization is the quantization of the model.
 	Quantization string `json:"Quantization"`
+	// MerkleRoot is the merkle root over the layers recorded in the manifest.
+	MerkleRoot string `json:"MerkleRoot,omitempty"`
 	// Layers is the layers of the model artifact.
 	Layers []InspectedModelArtifactLayer `json:"Layers"`
+	// Problems is the problems found by validating the manifest and config against the model spec.
+	Problems []string `json:"Problems,omitempty"`
 }
 
 // InspectedModelArtifactLayer is the data structure for model artifact layer that has been inspected.
@@ -66,6 +70,8 @@ type InspectedModelArtifactLayer struct {
 	Size int64 `json:"Size"`
 	// Filepath is the filepath of the model artifact layer.
 	Filepath string `json:"Filepath"`
+	// LoadOrder is the index of the weight in the load order recorded on build.
+	LoadOrder *int `json:"LoadOrder,omitempty"`
 }
 
 // Inspect inspects the target from the storage.
@@ -101,7 +107,7 @@ func (b *backend) Inspect(ctx context.Context, target string, cfg *config.Inspec
 
 	inspectedModelArtifact := &InspectedModelArtifact{
 		ID:           manifest.Config.Digest.String(),
-		Digest:       godigest.FromBytes(manifestRaw).String(),
+		Digest:       checksum.FromBytes(manifestRaw).String(),
 		Architecture: config.Config.Architecture,
 		Family:       config.Descriptor.Family,
 		Format:       config.Config.Format,
@@ -109,6 +115,7 @@ func (b *backend) Inspect(ctx context.Context, target string, cfg *config.Inspec
 		ParamSize:    config.Config.ParamSize,
 		Precision:    config.Config.Precision,
 		Quantization: config.Config.Quantization,
+		MerkleRoot:   manifest.Annotations[checksum.AnnotationMerkleRoot],
 	}
 
 	if config.Descriptor.CreatedAt != nil {
@@ -126,9 +133,15 @@ func (b *backend) Inspect(ctx context.Context, target string, cfg *config.Inspec
 			Digest:    layer.Digest.String(),
 			Size:      layer.Size,
 			Filepath:  filepath,
+			LoadOrder: loadOrder(layer),
 		})
 	}
 
+	inspectedModelArtifact.Problems = validateModelArtifact(manifest, config)
+	for _, problem := range inspectedModelArtifact.Problems {
+		logrus.Warnf("inspect: invalid model artifact %s: %s", target, problem)
+	}
+
 	logrus.Infof("inspect: inspected target %s", target)
 	return inspectedModelArtifact, nil
 }
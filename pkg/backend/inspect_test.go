@@ -149,4 +149,5 @@ func TestInspect(t *testing.T) {
 	assert.Equal(t, "sha256:5a96686deb327903f4310e9181ef2ee0bc7261e5181bd23ccdce6c575b6120a2", inspected.Layers[0].Digest)
 	assert.Equal(t, "LICENSE", inspected.Layers[0].Filepath)
 	assert.Equal(t, int64(13312), inspected.Layers[0].Size)
+	assert.Empty(t, inspected.Problems)
 }
@@ -0,0 +1,78 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/modelfile"
+)
+
+// checkLFSPointers checks the model files matching the patterns in the work directory, it fails
+// the build if any of them is a git-lfs pointer stub, which means the LFS objects are not pulled
+// and the artifact would be packaged with the placeholders. The pointers are warned instead of
+// failing the build if they are allowed explicitly.
+func checkLFSPointers(workDir string, patterns []string, cfg *config.Build) error {
+	pointers := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(workDir, pattern))
+		if err != nil {
+			return fmt.Errorf("failed to match pattern %s: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			pointer, err := modelfile.IsLFSPointer(match)
+			if err != nil {
+				return fmt.Errorf("failed to check git-lfs pointer %s: %w", match, err)
+			}
+
+			if pointer {
+				relPath, err := filepath.Rel(workDir, match)
+				if err != nil {
+					relPath = match
+				}
+
+				pointers[relPath] = true
+			}
+		}
+	}
+
+	if len(pointers) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(pointers))
+	for path := range pointers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	msg := fmt.Sprintf("the model files are git-lfs pointers, the LFS objects are not pulled, run `git lfs pull` first: %s", strings.Join(paths, ", "))
+	if !cfg.AllowLFSPointers {
+		return fmt.Errorf("%s", msg)
+	}
+
+	logrus.Warnf("build: %s", msg)
+	fmt.Fprintf(cfg.ReportWriter, "Warning: %s\n", msg)
+	return nil
+}
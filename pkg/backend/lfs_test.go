@@ -0,0 +1,56 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestCheckLFSPointers(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"model-00001.safetensors": "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n",
+		"model-00002.safetensors": "\x08\x00\x00\x00\x00\x00\x00\x00{}",
+		"config.json":             "{}",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	cfg := config.NewBuild()
+	err := checkLFSPointers(workDir, []string{"*.safetensors"}, cfg)
+	assert.ErrorContains(t, err, "git-lfs pointers")
+	assert.ErrorContains(t, err, "model-00001.safetensors")
+	assert.NotContains(t, err.Error(), "model-00002.safetensors")
+
+	assert.NoError(t, checkLFSPointers(workDir, []string{"model-00002.safetensors", "config.json"}, cfg))
+
+	var warnings bytes.Buffer
+	cfg.AllowLFSPointers = true
+	cfg.ReportWriter = &warnings
+	assert.NoError(t, checkLFSPointers(workDir, []string{"*.safetensors"}, cfg))
+	assert.Contains(t, warnings.String(), "Warning: the model files are git-lfs pointers")
+	assert.Contains(t, warnings.String(), "model-00001.safetensors")
+}
@@ -23,7 +23,9 @@ import (
 	"sort"
 	"time"
 
-	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	"github.com/modelpack/modctl/pkg/config"
+
+	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
@@ -42,8 +44,8 @@ type ModelArtifact struct {
 	CreatedAt time.Time
 }
 
-// List lists all the model artifacts.
-func (b *backend) List(ctx context.Context) ([]*ModelArtifact, error) {
+// List lists all the model artifacts, the artifacts out of the time filter are excluded.
+func (b *backend) List(ctx context.Context, cfg *config.List) ([]*ModelArtifact, error) {
 	logrus.Infof("list: listing model artifacts")
 	modelArtifacts := []*ModelArtifact{}
 
@@ -66,6 +68,11 @@ func (b *backend) List(ctx context.Context) ([]*ModelArtifact, error) {
 
 		// assemble the model artifact.
 		for _, tag := range tags {
+			// the Modelfile referrers are not the model artifacts.
+			if isModelfileReferrerTag(tag) {
+				continue
+			}
+
 			modelArtifact, err := b.assembleModelArtifact(ctx, repo, tag)
 			if err != nil {
 				return nil, fmt.Errorf("failed to assemble model artifact: %w", err)
@@ -75,6 +82,11 @@ func (b *backend) List(ctx context.Context) ([]*ModelArtifact, error) {
 		}
 	}
 
+	modelArtifacts, err = filterByCreatedAt(modelArtifacts, &cfg.TimeFilter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	sort.Slice(modelArtifacts, func(i, j int) bool {
 		return modelArtifacts[i].CreatedAt.After(modelArtifacts[j].CreatedAt)
 	})
@@ -102,16 +114,13 @@ func (b *backend) assembleModelArtifact(ctx context.Context, repo, tag string) (
 		size += layer.Size
 	}
 
+	b.cache.tag(repo, tag, godigest.Digest(digest))
+	b.cache.put(godigest.Digest(digest), manifestRaw)
+
 	// fetch and parse the model config.
-	configReader, err := b.store.PullBlob(ctx, repo, manifest.Config.Digest.String())
+	config, err := b.getModelConfig(ctx, repo, manifest.Config, false, false, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to pull config: %w", err)
-	}
-
-	defer configReader.Close()
-	var config modelspec.Model
-	if err := json.NewDecoder(configReader).Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to decode config: %w", err)
+		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
 	modelArtifact := &ModelArtifact{
@@ -127,3 +136,29 @@ func (b *backend) assembleModelArtifact(ctx context.Context, repo, tag string) (
 
 	return modelArtifact, nil
 }
+
+// filterByCreatedAt filters the model artifacts by the creation time, the artifacts
+// without creation time are excluded once the filter is set.
+func filterByCreatedAt(artifacts []*ModelArtifact, filter *config.TimeFilter, now time.Time) ([]*ModelArtifact, error) {
+	if !filter.IsSet() {
+		return artifacts, nil
+	}
+
+	filtered := make([]*ModelArtifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if artifact.CreatedAt.IsZero() {
+			continue
+		}
+
+		ok, err := filter.Contains(artifact.CreatedAt, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by created time: %w", err)
+		}
+
+		if ok {
+			filtered = append(filtered, artifact)
+		}
+	}
+
+	return filtered, nil
+}
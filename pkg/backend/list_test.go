@@ -22,7 +22,9 @@ import (
 	"encoding/json"
 	"io"
 	"testing"
+	"time"
 
+	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/test/mocks/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,7 +48,7 @@ func TestList(t *testing.T) {
 	manifestRaw, err := json.Marshal(manifest)
 	assert.NoError(t, err)
 
-	config := `{
+	configRaw := `{
   "descriptor": {
     "createdAt": "2025-02-12T17:01:43.968027+08:00",
     "family": "qwen2",
@@ -71,12 +73,12 @@ func TestList(t *testing.T) {
 	mockStore.On("PullManifest", ctx, mock.Anything, mock.Anything).Return(manifestRaw, "sha256:1234567890abcdef", nil)
 	mockStore.On("PullBlob", ctx, mock.Anything, mock.Anything).Return(
 		func(ctx context.Context, repo string, digest string) (io.ReadCloser, error) {
-			return io.NopCloser(bytes.NewReader([]byte(config))), nil
+			return io.NopCloser(bytes.NewReader([]byte(configRaw))), nil
 		},
 		nil,
 	)
 
-	artifacts, err := b.List(ctx)
+	artifacts, err := b.List(ctx, config.NewList())
 	assert.NoError(t, err, "list failed")
 	assert.Len(t, artifacts, 4, "unexpected number of artifacts")
 	assert.Equal(t, repos[0], artifacts[0].Repository, "unexpected repository")
@@ -85,3 +87,68 @@ func TestList(t *testing.T) {
 	assert.Equal(t, int64(3*1024+len(manifestRaw)), artifacts[0].Size, "unexpected size")
 	assert.Equal(t, "2025-02-12T17:01:43.968027+08:00", artifacts[0].CreatedAt.Format("2006-01-02T15:04:05.000000-07:00"), "unexpected created at")
 }
+
+func TestFilterByCreatedAt(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	artifacts := []*ModelArtifact{
+		{Tag: "old", CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{Tag: "week", CreatedAt: now.Add(-7 * 24 * time.Hour)},
+		{Tag: "day", CreatedAt: now.Add(-24 * time.Hour)},
+		{Tag: "hour", CreatedAt: now.Add(-time.Hour)},
+		{Tag: "unknown"},
+	}
+
+	testCases := []struct {
+		name     string
+		filter   config.TimeFilter
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "no filter",
+			expected: []string{"old", "week", "day", "hour", "unknown"},
+		},
+		{
+			name:     "since relative duration",
+			filter:   config.TimeFilter{Since: "48h"},
+			expected: []string{"day", "hour"},
+		},
+		{
+			name:     "until relative days is inclusive",
+			filter:   config.TimeFilter{Until: "7d"},
+			expected: []string{"old", "week"},
+		},
+		{
+			name:     "since and until RFC3339",
+			filter:   config.TimeFilter{Since: "2025-03-01T00:00:00Z", Until: "2025-03-09T23:00:00Z"},
+			expected: []string{"week", "day"},
+		},
+		{
+			name:     "since date in the location of now",
+			filter:   config.TimeFilter{Since: "2025-03-10"},
+			expected: []string{"hour"},
+		},
+		{
+			name:    "invalid since",
+			filter:  config.TimeFilter{Since: "yesterday"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered, err := filterByCreatedAt(artifacts, &tc.filter, now)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			tags := []string{}
+			for _, artifact := range filtered {
+				tags = append(tags, artifact.Tag)
+			}
+			assert.Equal(t, tc.expected, tags)
+		})
+	}
+}
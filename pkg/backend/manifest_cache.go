@@ -0,0 +1,398 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+const (
+	// manifestCacheDir is the directory of the persisted manifest cache in the storage directory.
+	manifestCacheDir = "manifest-cache.v1"
+
+	// manifestCacheETagDir is the directory of the persisted ETags in the manifest cache directory.
+	manifestCacheETagDir = "etags"
+
+	// maxCachedContentSize is the max size of the manifest or the config kept in the cache.
+	maxCachedContentSize = 4 << 20
+
+	// maxETagAge is the max duration the persisted ETag is kept since it was last used, the
+	// ETags of the tags which are not fetched anymore are evicted after it.
+	maxETagAge = 30 * 24 * time.Hour
+)
+
+// manifestCache caches the manifests and the model configs by digest, which are immutable, and
+// the digests of the tags in the current process. The contents can be persisted in the directory
+// to be shared across the runs, they are verified by the digest when loaded. The nil cache
+// disables the caching.
+type manifestCache struct {
+	mu sync.Mutex
+
+	// dir is the directory to persist the contents, the contents are kept in memory only if it is empty.
+	dir string
+
+	// tags is the digests of the manifests by the repository and the tag.
+	tags map[string]map[string]godigest.Digest
+
+	// contents is the manifests and the configs by the digest.
+	contents map[godigest.Digest][]byte
+
+	// etags is the ETags of the manifests fetched by the tags from the registries by the URLs.
+	etags map[string]*manifestETag
+
+	// etagsPruned indicates whether the stale persisted ETags have been evicted in this run.
+	etagsPruned bool
+
+	// hits is the number of the lookups served by the cache.
+	hits int
+}
+
+// newManifestCache creates the manifest cache, which is persisted in the dir if it is not empty.
+func newManifestCache(dir string) *manifestCache {
+	return &manifestCache{
+		dir:      dir,
+		tags:     map[string]map[string]godigest.Digest{},
+		contents: map[godigest.Digest][]byte{},
+		etags:    map[string]*manifestETag{},
+	}
+}
+
+// manifestETag is the ETag of the manifest fetched by the URL, the content of the manifest is
+// cached by the digest.
+type manifestETag struct {
+	URL       string          `json:"url"`
+	ETag      string          `json:"etag"`
+	MediaType string          `json:"mediaType"`
+	Digest    godigest.Digest `json:"digest"`
+}
+
+// resolve returns the cached digest of the tag in the repository.
+func (c *manifestCache) resolve(repo, tag string) (godigest.Digest, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	digest, ok := c.tags[repo][tag]
+	return digest, ok
+}
+
+// tag records the digest of the tag in the repository.
+func (c *manifestCache) tag(repo, tag string, digest godigest.Digest) {
+	if c == nil || digest.Validate() != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tags[repo] == nil {
+		c.tags[repo] = map[string]godigest.Digest{}
+	}
+	c.tags[repo][tag] = digest
+}
+
+// invalidate drops the cached tags of the repository, which is called when the tags are changed.
+func (c *manifestCache) invalidate(repo string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tags, repo)
+}
+
+// get returns the cached content of the digest from the memory, or from the persisted directory.
+func (c *manifestCache) get(digest godigest.Digest) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content, ok := c.load(digest)
+	if ok {
+		c.hits++
+	}
+
+	return content, ok
+}
+
+// load returns the content of the digest from the memory, or from the persisted directory, the
+// caller must hold the lock.
+func (c *manifestCache) load(digest godigest.Digest) ([]byte, bool) {
+	if content, ok := c.contents[digest]; ok {
+		return content, true
+	}
+
+	if c.dir == "" || digest.Validate() != nil {
+		return nil, false
+	}
+
+	content, err := os.ReadFile(c.path(digest))
+	if err != nil {
+		return nil, false
+	}
+
+	// drop the corrupted content, which is refetched and persisted again.
+	if !checksum.Matches(digest, content) {
+		logrus.Warnf("manifest cache: dropping corrupted content %s", digest)
+		os.Remove(c.path(digest))
+		return nil, false
+	}
+
+	c.contents[digest] = content
+	return content, true
+}
+
+// put caches the content of the digest, and persists it if the directory is specified.
+func (c *manifestCache) put(digest godigest.Digest, content []byte) {
+	if c == nil || digest.Validate() != nil || len(content) > maxCachedContentSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contents[digest] = content
+
+	if c.dir == "" {
+		return
+	}
+
+	if err := c.persist(digest, content); err != nil {
+		logrus.Debugf("manifest cache: failed to persist %s: %s", digest, err)
+	}
+}
+
+// LookupManifest returns the manifest fetched by the URL with its ETag, which is revalidated by
+// the conditional request of the remote client.
+func (c *manifestCache) LookupManifest(url string) (*remote.CachedManifest, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	etag, ok := c.etags[url]
+	if !ok {
+		etag, ok = c.loadETag(url)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	// drop the ETag whose manifest is no longer cached, which is refetched and stored again.
+	content, ok := c.load(etag.Digest)
+	if !ok {
+		delete(c.etags, url)
+		c.removeETag(url)
+		return nil, false
+	}
+
+	return &remote.CachedManifest{
+		ETag:      etag.ETag,
+		MediaType: etag.MediaType,
+		Digest:    etag.Digest.String(),
+		Content:   content,
+	}, true
+}
+
+// StoreManifest caches the manifest fetched by the URL with its ETag, the ETag is persisted
+// with the content if the directory is specified.
+func (c *manifestCache) StoreManifest(url string, manifest *remote.CachedManifest) {
+	digest := godigest.Digest(manifest.Digest)
+	if c == nil || manifest.ETag == "" || !checksum.Matches(digest, manifest.Content) {
+		return
+	}
+
+	c.put(digest, manifest.Content)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	etag := &manifestETag{URL: url, ETag: manifest.ETag, MediaType: manifest.MediaType, Digest: digest}
+	c.etags[url] = etag
+
+	if c.dir == "" {
+		return
+	}
+
+	if err := c.persistETag(etag); err != nil {
+		logrus.Debugf("manifest cache: failed to persist the ETag of %s: %s", url, err)
+	}
+
+	if !c.etagsPruned {
+		c.etagsPruned = true
+		c.pruneETags(time.Now().Add(-maxETagAge))
+	}
+}
+
+// loadETag loads the persisted ETag of the URL, the caller must hold the lock.
+func (c *manifestCache) loadETag(url string) (*manifestETag, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+
+	path := c.etagPath(url)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var etag manifestETag
+	if err := json.Unmarshal(data, &etag); err != nil || etag.URL != url || etag.Digest.Validate() != nil {
+		c.removeETag(url)
+		return nil, false
+	}
+
+	// refresh the modification time of the used ETag to keep it from the eviction.
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		logrus.Debugf("manifest cache: failed to touch the ETag of %s: %s", url, err)
+	}
+
+	c.etags[url] = &etag
+	return &etag, true
+}
+
+// removeETag removes the persisted ETag of the URL, the caller must hold the lock.
+func (c *manifestCache) removeETag(url string) {
+	if c.dir == "" {
+		return
+	}
+
+	if err := os.Remove(c.etagPath(url)); err != nil && !os.IsNotExist(err) {
+		logrus.Debugf("manifest cache: failed to remove the ETag of %s: %s", url, err)
+	}
+}
+
+// pruneETags evicts the persisted ETags which have not been used since the time, the caller
+// must hold the lock.
+func (c *manifestCache) pruneETags(before time.Time) {
+	dir := filepath.Join(c.dir, manifestCacheETagDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || !info.ModTime().Before(before) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			logrus.Debugf("manifest cache: failed to evict the ETag %s: %s", entry.Name(), err)
+			continue
+		}
+
+		logrus.Debugf("manifest cache: evicted the stale ETag %s", entry.Name())
+	}
+}
+
+// persistETag writes the ETag into the directory atomically, which replaces the previous one.
+func (c *manifestCache) persistETag(etag *manifestETag) error {
+	data, err := json.Marshal(etag)
+	if err != nil {
+		return err
+	}
+
+	path := c.etagPath(etag.URL)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := archiver.CreateAtomic(path, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+
+	return file.Commit()
+}
+
+// etagPath returns the path of the persisted ETag of the URL.
+func (c *manifestCache) etagPath(url string) string {
+	sum := checksum.Sum256([]byte(url))
+	return filepath.Join(c.dir, manifestCacheETagDir, hex.EncodeToString(sum[:]))
+}
+
+// persist writes the content into the directory atomically.
+func (c *manifestCache) persist(digest godigest.Digest, content []byte) error {
+	path := c.path(digest)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := archiver.CreateAtomic(path, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	if _, err := file.Write(content); err != nil {
+		return err
+	}
+
+	return file.Commit()
+}
+
+// path returns the path of the persisted content of the digest.
+func (c *manifestCache) path(digest godigest.Digest) string {
+	return filepath.Join(c.dir, digest.Algorithm().String(), digest.Encoded())
+}
+
+// cacheInvalidatingStorage invalidates the cached tags of the repository when the manifests
+// of the repository are pushed or deleted.
+type cacheInvalidatingStorage struct {
+	storage.Storage
+	cache *manifestCache
+}
+
+// PushManifest pushes the manifest and invalidates the cached tags of the repository.
+func (s *cacheInvalidatingStorage) PushManifest(ctx context.Context, repo, reference, mediaType string, body []byte) (string, error) {
+	defer s.cache.invalidate(repo)
+	return s.Storage.PushManifest(ctx, repo, reference, mediaType, body)
+}
+
+// DeleteManifest deletes the manifest and invalidates the cached tags of the repository.
+func (s *cacheInvalidatingStorage) DeleteManifest(ctx context.Context, repo, reference string) error {
+	defer s.cache.invalidate(repo)
+	return s.Storage.DeleteManifest(ctx, repo, reference)
+}
@@ -0,0 +1,187 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestManifestCache(t *testing.T) {
+	content := []byte(`{"schemaVersion":2}`)
+	digest := godigest.FromBytes(content)
+
+	t.Run("nil", func(t *testing.T) {
+		var cache *manifestCache
+		cache.put(digest, content)
+		cache.tag("example.com/repo", "v1", digest)
+		_, ok := cache.get(digest)
+		assert.False(t, ok)
+		_, ok = cache.resolve("example.com/repo", "v1")
+		assert.False(t, ok)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		cache := newManifestCache("")
+		cache.tag("example.com/repo", "v1", digest)
+		resolved, ok := cache.resolve("example.com/repo", "v1")
+		require.True(t, ok)
+		assert.Equal(t, digest, resolved)
+
+		cache.invalidate("example.com/repo")
+		_, ok = cache.resolve("example.com/repo", "v1")
+		assert.False(t, ok)
+	})
+
+	t.Run("persisted", func(t *testing.T) {
+		dir := t.TempDir()
+		newManifestCache(dir).put(digest, content)
+
+		cache := newManifestCache(dir)
+		cached, ok := cache.get(digest)
+		require.True(t, ok)
+		assert.Equal(t, content, cached)
+		assert.Equal(t, 1, cache.hits)
+
+		// the corrupted content is dropped.
+		require.NoError(t, os.WriteFile(cache.path(digest), []byte("corrupted"), 0644))
+		_, ok = newManifestCache(dir).get(digest)
+		assert.False(t, ok)
+		assert.NoFileExists(t, cache.path(digest))
+	})
+
+	t.Run("etags", func(t *testing.T) {
+		const url = "https://example.com/v2/repo/manifests/v1"
+		dir := t.TempDir()
+		manifest := &remote.CachedManifest{ETag: `"v1"`, MediaType: "application/vnd.oci.image.manifest.v1+json", Digest: digest.String(), Content: content}
+
+		var nilCache *manifestCache
+		nilCache.StoreManifest(url, manifest)
+		_, ok := nilCache.LookupManifest(url)
+		assert.False(t, ok)
+
+		newManifestCache(dir).StoreManifest(url, manifest)
+
+		// the persisted ETag is shared across the runs.
+		cache := newManifestCache(dir)
+		cached, ok := cache.LookupManifest(url)
+		require.True(t, ok)
+		assert.Equal(t, manifest, cached)
+		assert.Equal(t, 0, cache.hits)
+
+		// the changed ETag replaces the previous one.
+		changed := []byte(`{"schemaVersion":2,"layers":[]}`)
+		cache.StoreManifest(url, &remote.CachedManifest{ETag: `"v2"`, Digest: godigest.FromBytes(changed).String(), Content: changed})
+		cached, ok = newManifestCache(dir).LookupManifest(url)
+		require.True(t, ok)
+		assert.Equal(t, `"v2"`, cached.ETag)
+		assert.Equal(t, changed, cached.Content)
+
+		// the manifest mismatching the digest is not cached.
+		cache.StoreManifest("https://example.com/v2/repo/manifests/v2", &remote.CachedManifest{ETag: `"v3"`, Digest: digest.String(), Content: changed})
+		_, ok = cache.LookupManifest("https://example.com/v2/repo/manifests/v2")
+		assert.False(t, ok)
+
+		// the ETag whose manifest is no longer cached is removed.
+		require.NoError(t, os.Remove(cache.path(godigest.FromBytes(changed))))
+		_, ok = newManifestCache(dir).LookupManifest(url)
+		assert.False(t, ok)
+		assert.NoFileExists(t, cache.etagPath(url))
+	})
+
+	t.Run("evict etags", func(t *testing.T) {
+		const (
+			staleURL = "https://example.com/v2/repo/manifests/stale"
+			usedURL  = "https://example.com/v2/repo/manifests/used"
+		)
+		dir := t.TempDir()
+		manifest := &remote.CachedManifest{ETag: `"v1"`, Digest: digest.String(), Content: content}
+		cache := newManifestCache(dir)
+		cache.StoreManifest(staleURL, manifest)
+		cache.StoreManifest(usedURL, manifest)
+
+		old := time.Now().Add(-maxETagAge - time.Hour)
+		require.NoError(t, os.Chtimes(cache.etagPath(staleURL), old, old))
+		require.NoError(t, os.Chtimes(cache.etagPath(usedURL), old, old))
+
+		// the used ETag is refreshed by the lookup.
+		_, ok := newManifestCache(dir).LookupManifest(usedURL)
+		require.True(t, ok)
+
+		// the stale ETag is evicted when the ETags are stored in the next run.
+		newManifestCache(dir).StoreManifest("https://example.com/v2/repo/manifests/new", manifest)
+		assert.NoFileExists(t, cache.etagPath(staleURL))
+		assert.FileExists(t, cache.etagPath(usedURL))
+		entries, err := os.ReadDir(filepath.Join(dir, manifestCacheETagDir))
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestInspectManifestCache(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	cache := newManifestCache("")
+	b := &backend{store: &cacheInvalidatingStorage{Storage: store, cache: cache}, cache: cache}
+
+	files := map[string][]byte{"model.safetensors": []byte("weights")}
+	storeModel(t, b, "example.com/models/llama3", "v1", files, []string{"model.safetensors"})
+
+	inspect := func() *InspectedModelArtifact {
+		inspected, err := b.Inspect(ctx, "example.com/models/llama3:v1", config.NewInspect())
+		require.NoError(t, err)
+		return inspected.(*InspectedModelArtifact)
+	}
+
+	first := inspect()
+	second := inspect()
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, storeCalls(store, "PullManifest"))
+	assert.Equal(t, 1, storeCalls(store, "PullBlob"))
+	assert.Equal(t, 2, cache.hits)
+
+	// the tag is moved to another model artifact, which invalidates the cached tag.
+	files["README.md"] = []byte("# llama3")
+	storeModel(t, b, "example.com/models/llama3", "v1", files, []string{"model.safetensors", "README.md"})
+	third := inspect()
+	assert.NotEqual(t, first.Digest, third.Digest)
+	assert.Len(t, third.Layers, 2)
+	assert.Equal(t, 2, storeCalls(store, "PullManifest"))
+}
+
+// storeCalls returns the number of the calls of the method to the mock storage.
+func storeCalls(store *storage.Storage, method string) int {
+	count := 0
+	for _, call := range store.Calls {
+		if call.Method == method {
+			count++
+		}
+	}
+
+	return count
+}
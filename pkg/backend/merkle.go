@@ -0,0 +1,33 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+// merkleRoot computes the Merkle root over the digests of the layers.
+func merkleRoot(layers []ocispec.Descriptor) string {
+	digests := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		digests = append(digests, layer.Digest.String())
+	}
+
+	return checksum.MerkleRoot(digests)
+}
@@ -0,0 +1,369 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+const (
+	// ArtifactTypeModelfile is the artifact type of the referrer storing the Modelfile of the model artifact.
+	ArtifactTypeModelfile = "application/vnd.cncf.modctl.modelfile.manifest.v1+json"
+
+	// MediaTypeModelfile is the media type of the Modelfile in the referrer.
+	MediaTypeModelfile = "application/vnd.cncf.modctl.modelfile.v1"
+
+	// modelfileReferrerTagSuffix is the suffix of the tag of the Modelfile referrer in the local storage.
+	modelfileReferrerTagSuffix = ".modelfile"
+
+	// maxModelfileSize is the max size of the Modelfile read from the referrer.
+	maxModelfileSize = 1 << 20
+)
+
+// ErrModelfileNotFound is returned when the model artifact carries neither the Modelfile
+// annotation nor the Modelfile referrer.
+var ErrModelfileNotFound = errors.New("modelfile not found in the model artifact")
+
+// modelfileReferrerTag returns the tag of the Modelfile referrer of the subject in the local storage,
+// such as sha256-<hex>.modelfile, as the local storage does not support the referrers API.
+func modelfileReferrerTag(subject godigest.Digest) string {
+	return fmt.Sprintf("%s-%s%s", subject.Algorithm(), subject.Encoded(), modelfileReferrerTagSuffix)
+}
+
+// isModelfileReferrerTag returns whether the tag is of the Modelfile referrer in the local storage.
+func isModelfileReferrerTag(tag string) bool {
+	return strings.HasSuffix(tag, modelfileReferrerTagSuffix)
+}
+
+// newModelfileReferrer returns the layer and the manifest of the referrer storing the Modelfile of the subject.
+func newModelfileReferrer(subject ocispec.Descriptor, content []byte) (ocispec.Descriptor, []byte, error) {
+	layer := ocispec.Descriptor{MediaType: MediaTypeModelfile, Digest: checksum.FromBytes(content), Size: int64(len(content))}
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: ArtifactTypeModelfile,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{layer},
+		Subject:      &ocispec.Descriptor{MediaType: subject.MediaType, Digest: subject.Digest, Size: subject.Size},
+	})
+	if err != nil {
+		return layer, nil, fmt.Errorf("failed to marshal modelfile referrer manifest: %w", err)
+	}
+
+	return layer, manifestRaw, nil
+}
+
+// attachModelfile stores the Modelfile as the referrer of the subject, which is pushed to the
+// remote registry if the build outputs to the remote.
+func (b *backend) attachModelfile(ctx context.Context, repo string, subject ocispec.Descriptor, content []byte, cfg *config.Build) error {
+	layer, manifestRaw, err := newModelfileReferrer(subject, content)
+	if err != nil {
+		return err
+	}
+
+	if cfg.OutputRemote {
+		client, err := remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure))
+		if err != nil {
+			return fmt.Errorf("failed to create remote client: %w", err)
+		}
+
+		digest, err := pushModelfileReferrer(ctx, client, layer, content, manifestRaw)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("build: attached modelfile %s to %s", digest, subject.Digest)
+		return nil
+	}
+
+	emptyConfig := ocispec.DescriptorEmptyJSON
+	if _, _, err := b.store.PushBlob(ctx, repo, bytes.NewReader(emptyConfig.Data), emptyConfig); err != nil {
+		return fmt.Errorf("failed to store empty config: %w", err)
+	}
+
+	if _, _, err := b.store.PushBlob(ctx, repo, bytes.NewReader(content), layer); err != nil {
+		return fmt.Errorf("failed to store modelfile: %w", err)
+	}
+
+	digest, err := b.store.PushManifest(ctx, repo, modelfileReferrerTag(subject.Digest), ocispec.MediaTypeImageManifest, manifestRaw)
+	if err != nil {
+		return fmt.Errorf("failed to store modelfile referrer manifest: %w", err)
+	}
+
+	logrus.Infof("build: attached modelfile %s to %s", digest, subject.Digest)
+	return nil
+}
+
+// pushModelfileReferrer pushes the Modelfile and the referrer manifest to the remote registry,
+// which returns the digest of the referrer manifest.
+func pushModelfileReferrer(ctx context.Context, client *remote.Repository, layer ocispec.Descriptor, content, manifestRaw []byte) (godigest.Digest, error) {
+	emptyConfig := ocispec.DescriptorEmptyJSON
+	if err := pushBlobIfNotExist(ctx, client, emptyConfig, emptyConfig.Data); err != nil {
+		return "", fmt.Errorf("failed to push empty config: %w", err)
+	}
+
+	if err := pushBlobIfNotExist(ctx, client, layer, content); err != nil {
+		return "", fmt.Errorf("failed to push modelfile: %w", err)
+	}
+
+	manifestDesc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: checksum.FromBytes(manifestRaw), Size: int64(len(manifestRaw))}
+	if err := client.Manifests().Push(ctx, manifestDesc, bytes.NewReader(manifestRaw)); err != nil {
+		return "", fmt.Errorf("failed to push modelfile referrer manifest: %w", remote.WrapUnsupportedMediaType(err))
+	}
+
+	return manifestDesc.Digest, nil
+}
+
+// pushLocalModelfileReferrer pushes the Modelfile referrer of the subject from the local storage to
+// the remote registry, which is skipped if the subject has no Modelfile referrer.
+func (b *backend) pushLocalModelfileReferrer(ctx context.Context, client *remote.Repository, repo string, subject godigest.Digest) error {
+	manifestRaw, _, err := b.store.PullManifest(ctx, repo, modelfileReferrerTag(subject))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to pull modelfile referrer manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal modelfile referrer manifest: %w", err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != MediaTypeModelfile {
+			continue
+		}
+
+		content, err := b.readModelfile(ctx, nil, repo, layer)
+		if err != nil {
+			return err
+		}
+
+		digest, err := pushModelfileReferrer(ctx, client, layer, content, manifestRaw)
+		if err != nil {
+			return err
+		}
+
+		logrus.Infof("push: pushed modelfile referrer %s of %s", digest, subject)
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s@%s", ErrModelfileNotFound, repo, subject)
+}
+
+// removeOrphanModelfileReferrers removes the Modelfile referrers whose subject is no longer tagged
+// in the repository, as the local storage tracks the referrers by the tags of their subjects.
+func (b *backend) removeOrphanModelfileReferrers(ctx context.Context, repo string, dryRun bool) error {
+	tags, err := b.store.ListTags(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list tags in repository %s: %w", repo, err)
+	}
+
+	subjects := map[string]bool{}
+	for _, tag := range tags {
+		if isModelfileReferrerTag(tag) {
+			continue
+		}
+
+		_, digest, err := b.store.PullManifest(ctx, repo, tag)
+		if err != nil {
+			// the tag is left dangling if its manifest is removed by the digest.
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+
+			return fmt.Errorf("failed to pull manifest %s: %w", tag, err)
+		}
+
+		subjects[modelfileReferrerTag(godigest.Digest(digest))] = true
+	}
+
+	for _, tag := range tags {
+		if !isModelfileReferrerTag(tag) || subjects[tag] {
+			continue
+		}
+
+		if dryRun {
+			logrus.Infof("modelfile: would remove modelfile referrer %s", tag)
+			continue
+		}
+
+		_, digest, err := b.store.PullManifest(ctx, repo, tag)
+		if err != nil {
+			return fmt.Errorf("failed to pull modelfile referrer manifest %s: %w", tag, err)
+		}
+
+		// untag the referrer and remove its manifest, so that the blobs are pruned without the untagged ones.
+		if err := b.store.DeleteManifest(ctx, repo, tag); err != nil {
+			return fmt.Errorf("failed to delete modelfile referrer %s: %w", tag, err)
+		}
+
+		if err := b.store.DeleteManifest(ctx, repo, digest); err != nil {
+			return fmt.Errorf("failed to delete modelfile referrer manifest %s: %w", digest, err)
+		}
+
+		logrus.Infof("modelfile: removed modelfile referrer %s", tag)
+	}
+
+	return nil
+}
+
+// ExtractModelfile returns the Modelfile of the model artifact from the manifest annotation, or
+// the Modelfile referrer if the annotation is absent.
+func (b *backend) ExtractModelfile(ctx context.Context, target string, cfg *configmodelfile.ExtractConfig) ([]byte, error) {
+	logrus.Infof("modelfile: extracting modelfile from %s", target)
+	ref, err := ParseReference(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	repo, tag := ref.Repository(), ref.Tag()
+	if repo == "" || tag == "" {
+		return nil, fmt.Errorf("invalid repository or tag")
+	}
+
+	var client *remote.Repository
+	if cfg.Remote {
+		client, err = remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create remote client: %w", err)
+		}
+	}
+
+	manifestDesc, manifest, err := b.sbomManifest(ctx, client, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if content, ok := manifest.Annotations[annotationModelfile]; ok {
+		return []byte(content), nil
+	}
+
+	logrus.Infof("modelfile: no modelfile annotation in %s, discovering the modelfile referrer", target)
+	var referrer *ocispec.Manifest
+	if client != nil {
+		referrer, err = fetchRemoteModelfileReferrer(ctx, client, manifestDesc)
+	} else {
+		referrer, err = b.pullModelfileReferrer(ctx, repo, manifestDesc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layer := range referrer.Layers {
+		if layer.MediaType != MediaTypeModelfile {
+			continue
+		}
+
+		return b.readModelfile(ctx, client, repo, layer)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrModelfileNotFound, target)
+}
+
+// readModelfile reads the Modelfile layer of the referrer from the remote registry if the client
+// is specified, otherwise from the local storage.
+func (b *backend) readModelfile(ctx context.Context, client *remote.Repository, repo string, layer ocispec.Descriptor) ([]byte, error) {
+	var (
+		reader io.ReadCloser
+		err    error
+	)
+	if client != nil {
+		reader, err = client.Blobs().Fetch(ctx, layer)
+	} else {
+		reader, err = b.store.PullBlob(ctx, repo, layer.Digest.String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch modelfile: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxModelfileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read modelfile: %w", err)
+	}
+
+	if digest := checksum.FromBytesOf(layer.Digest, content); digest != layer.Digest {
+		return nil, fmt.Errorf("modelfile digest mismatch: expected %s, got %s", layer.Digest, digest)
+	}
+
+	return content, nil
+}
+
+// pullModelfileReferrer pulls the Modelfile referrer of the subject from the local storage.
+func (b *backend) pullModelfileReferrer(ctx context.Context, repo string, subject ocispec.Descriptor) (*ocispec.Manifest, error) {
+	manifestRaw, _, err := b.store.PullManifest(ctx, repo, modelfileReferrerTag(subject.Digest))
+	if err != nil {
+		logrus.Debugf("modelfile: failed to pull modelfile referrer of %s: %s", subject.Digest, err)
+		return nil, fmt.Errorf("%w: %s@%s", ErrModelfileNotFound, repo, subject.Digest)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal modelfile referrer manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// fetchRemoteModelfileReferrer fetches the latest Modelfile referrer of the subject by the referrers API.
+func fetchRemoteModelfileReferrer(ctx context.Context, client *remote.Repository, subject ocispec.Descriptor) (*ocispec.Manifest, error) {
+	var referrers []ocispec.Descriptor
+	if err := client.Referrers(ctx, subject, ArtifactTypeModelfile, func(descs []ocispec.Descriptor) error {
+		referrers = append(referrers, descs...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list referrers: %w", err)
+	}
+
+	if len(referrers) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrModelfileNotFound, subject.Digest)
+	}
+
+	// The Modelfile is attached once by the build, pick the last one if attached repeatedly.
+	reader, err := client.Manifests().Fetch(ctx, referrers[len(referrers)-1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch modelfile referrer manifest: %w", err)
+	}
+	defer reader.Close()
+
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode modelfile referrer manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,167 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+func TestBuildModelfileReferrer(t *testing.T) {
+	ctx := context.Background()
+	workDir := t.TempDir()
+	modelfile := "NAME test\nCONFIG config.json\nMODEL *.safetensors\n"
+	files := map[string]string{
+		"Modelfile":         modelfile,
+		"config.json":       "{}",
+		"model.safetensors": "weights",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	build := func(target string, referrer bool) ocispec.Manifest {
+		cfg := config.NewBuild()
+		cfg.Raw = true
+		cfg.ModelfileReferrer = referrer
+		require.NoError(t, b.Build(ctx, filepath.Join(workDir, "Modelfile"), workDir, target, cfg))
+
+		ref, err := ParseReference(target)
+		require.NoError(t, err)
+		manifestRaw, _, err := store.PullManifest(ctx, ref.Repository(), ref.Tag())
+		require.NoError(t, err)
+
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+		return manifest
+	}
+
+	t.Run("referrer", func(t *testing.T) {
+		manifest := build("example.com/models/referrer:v1", true)
+		assert.NotContains(t, manifest.Annotations, annotationModelfile)
+
+		content, err := b.ExtractModelfile(ctx, "example.com/models/referrer:v1", configmodelfile.NewExtractConfig())
+		require.NoError(t, err)
+		assert.Equal(t, modelfile, string(content))
+	})
+
+	t.Run("annotation", func(t *testing.T) {
+		manifest := build("example.com/models/annotation:v1", false)
+		assert.Equal(t, modelfile, manifest.Annotations[annotationModelfile])
+
+		content, err := b.ExtractModelfile(ctx, "example.com/models/annotation:v1", configmodelfile.NewExtractConfig())
+		require.NoError(t, err)
+		assert.Equal(t, modelfile, string(content))
+	})
+
+	t.Run("push", func(t *testing.T) {
+		build("example.com/models/referrer:v1", true)
+		server, _ := newMemoryRegistry(t)
+		host := strings.TrimPrefix(server.URL, "http://")
+
+		pushCfg := config.NewPush()
+		pushCfg.PlainHTTP = true
+		pushCfg.Destination = host + "/remote/referrer:v1"
+		require.NoError(t, b.Push(ctx, "example.com/models/referrer:v1", pushCfg))
+
+		extractCfg := configmodelfile.NewExtractConfig()
+		extractCfg.Remote = true
+		extractCfg.PlainHTTP = true
+		content, err := b.ExtractModelfile(ctx, host+"/remote/referrer:v1", extractCfg)
+		require.NoError(t, err)
+		assert.Equal(t, modelfile, string(content))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		manifest := build("example.com/models/remove:v1", true)
+		manifestRaw, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		referrerTag := modelfileReferrerTag(godigest.FromBytes(manifestRaw))
+		_, _, err = store.PullManifest(ctx, "example.com/models/remove", referrerTag)
+		require.NoError(t, err)
+
+		_, err = b.Remove(ctx, "example.com/models/remove:v1")
+		require.NoError(t, err)
+		_, _, err = store.PullManifest(ctx, "example.com/models/remove", referrerTag)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		build("example.com/models/prune:v1", true)
+		require.NoError(t, store.DeleteManifest(ctx, "example.com/models/prune", "v1"))
+
+		store.On("PerformGC", mock.Anything, false, false).Return(nil)
+		store.On("PerformPurgeUploads", mock.Anything, false).Return(nil)
+		require.NoError(t, b.Prune(ctx, &config.Prune{}))
+
+		tags, err := store.ListTags(ctx, "example.com/models/prune")
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		storeModel(t, b, "example.com/models/plain", "v1", map[string][]byte{"model.safetensors": []byte("weights")}, []string{"model.safetensors"})
+		_, err := b.ExtractModelfile(ctx, "example.com/models/plain:v1", configmodelfile.NewExtractConfig())
+		assert.ErrorIs(t, err, ErrModelfileNotFound)
+	})
+}
+
+func TestExtractModelfileRemote(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	_, manifestRaw := serveModel(t, contents, "models/llama3", "v1", []remoteFile{
+		{name: "model.safetensors", mediaType: modelspec.MediaTypeModelWeightRaw, content: []byte("weights")},
+	})
+	subject := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: godigest.FromBytes(manifestRaw), Size: int64(len(manifestRaw))}
+
+	extractCfg := configmodelfile.NewExtractConfig()
+	extractCfg.Remote = true
+	extractCfg.PlainHTTP = true
+
+	b := &backend{}
+	_, err := b.ExtractModelfile(ctx, host+"/models/llama3:v1", extractCfg)
+	assert.ErrorIs(t, err, ErrModelfileNotFound)
+
+	buildCfg := config.NewBuild()
+	buildCfg.OutputRemote = true
+	buildCfg.PlainHTTP = true
+	modelfile := []byte("NAME llama3\nMODEL model.safetensors\n")
+	require.NoError(t, b.attachModelfile(ctx, host+"/models/llama3", subject, modelfile, buildCfg))
+
+	content, err := b.ExtractModelfile(ctx, host+"/models/llama3:v1", extractCfg)
+	require.NoError(t, err)
+	assert.Equal(t, modelfile, content)
+}
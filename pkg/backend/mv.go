@@ -0,0 +1,159 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+
+	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	pkgcodec "github.com/modelpack/modctl/pkg/codec"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// Move renames the file in the model artifact by rewriting the file path annotation of its layer
+// in the manifest, the blobs and the config including the diff ids are reused as is, so nothing is
+// reprocessed or re-uploaded. The renamed artifact is tagged by the target, or the source tag if
+// the target is empty.
+func (b *backend) Move(ctx context.Context, source, oldPath, newPath string, cfg *config.Move) error {
+	logrus.Infof("mv: renaming %s to %s in %s", oldPath, newPath, source)
+	srcRef, err := ParseReference(source)
+	if err != nil {
+		return fmt.Errorf("failed to parse source: %w", err)
+	}
+
+	target := source
+	if cfg.Target != "" {
+		target = cfg.Target
+	}
+
+	targetRef, err := ParseReference(target)
+	if err != nil {
+		return fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	if targetRef.Tag() == "" {
+		return fmt.Errorf("the target %s must be tagged", target)
+	}
+
+	oldPath, newPath = path.Clean(filepath.ToSlash(oldPath)), path.Clean(filepath.ToSlash(newPath))
+	if !filepath.IsLocal(newPath) {
+		return fmt.Errorf("invalid path %s, must be relative to the model root", newPath)
+	}
+
+	reference := srcRef.Tag()
+	if srcRef.Digest() != "" {
+		reference = srcRef.Digest()
+	}
+
+	manifestRaw, _, err := b.store.PullManifest(ctx, srcRef.Repository(), reference)
+	if err != nil {
+		return fmt.Errorf("failed to pull manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	index := -1
+	for i, layer := range manifest.Layers {
+		switch layerFilepath(layer) {
+		case oldPath:
+			index = i
+		case newPath:
+			return fmt.Errorf("file %s already exists in %s", newPath, source)
+		}
+	}
+
+	if index < 0 {
+		return fmt.Errorf("file %s is not found in %s", oldPath, source)
+	}
+
+	// the tar layers restore the paths from the tar headers, which can't be renamed without repacking.
+	layer := manifest.Layers[index]
+	if pkgcodec.TypeFromMediaType(layer.MediaType) != pkgcodec.Raw {
+		return fmt.Errorf("file %s is stored in the %s layer, only the raw layers can be renamed", oldPath, layer.MediaType)
+	}
+
+	layer.Annotations, err = renameLayerAnnotations(layer.Annotations, newPath)
+	if err != nil {
+		return fmt.Errorf("failed to rename layer %s: %w", layer.Digest, err)
+	}
+	manifest.Layers[index] = layer
+
+	renamedRaw, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	// mount the blobs if the renamed artifact is tagged in another repository.
+	if targetRef.Repository() != srcRef.Repository() {
+		for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+			if err := b.store.MountBlob(ctx, srcRef.Repository(), targetRef.Repository(), desc); err != nil {
+				return fmt.Errorf("failed to mount blob %s: %w", desc.Digest, err)
+			}
+		}
+	}
+
+	digest, err := b.store.PushManifest(ctx, targetRef.Repository(), targetRef.Tag(), manifest.MediaType, renamedRaw)
+	if err != nil {
+		return fmt.Errorf("failed to push manifest: %w", err)
+	}
+
+	logrus.Infof("mv: renamed %s to %s as %s [manifest: %s]", oldPath, newPath, target, digest)
+	return nil
+}
+
+// renameLayerAnnotations returns the copy of the layer annotations with the file path and the name
+// in the file metadata renamed to the path.
+func renameLayerAnnotations(annotations map[string]string, newPath string) (map[string]string, error) {
+	renamed := maps.Clone(annotations)
+	renamed[modelspec.AnnotationFilepath] = newPath
+	if _, ok := renamed[legacymodelspec.AnnotationFilepath]; ok {
+		renamed[legacymodelspec.AnnotationFilepath] = newPath
+	}
+
+	for _, key := range []string{modelspec.AnnotationFileMetadata, legacymodelspec.AnnotationFileMetadata} {
+		if renamed[key] == "" {
+			continue
+		}
+
+		var metadata modelspec.FileMetadata
+		if err := json.Unmarshal([]byte(renamed[key]), &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal file metadata: %w", err)
+		}
+
+		metadata.Name = path.Base(newPath)
+		metadataRaw, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal file metadata: %w", err)
+		}
+
+		renamed[key] = string(metadataRaw)
+	}
+
+	return renamed, nil
+}
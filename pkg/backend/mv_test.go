@@ -0,0 +1,111 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestMove(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	repo := "example.com/models/llama3"
+	manifestRaw := storeModel(t, b, repo, "v1", map[string][]byte{
+		"weights/model.safetensors": []byte("weights"),
+		"config.json":               []byte(`{"hidden_size": 4096}`),
+	}, []string{"weights/model.safetensors", "config.json"})
+
+	var original ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &original))
+
+	pullManifest := func(t *testing.T, repo, tag string) ocispec.Manifest {
+		raw, _, err := store.PullManifest(ctx, repo, tag)
+		require.NoError(t, err)
+
+		var manifest ocispec.Manifest
+		require.NoError(t, json.Unmarshal(raw, &manifest))
+		return manifest
+	}
+
+	require.NoError(t, b.Move(ctx, repo+":v1", "weights/model.safetensors", "model.safetensors", config.NewMove()))
+
+	// only the file path is rewritten, the blobs and config are reused.
+	renamed := pullManifest(t, repo, "v1")
+	require.Len(t, renamed.Layers, 2)
+	assert.Equal(t, "model.safetensors", layerFilepath(renamed.Layers[0]))
+	assert.Equal(t, original.Layers[0].Digest, renamed.Layers[0].Digest)
+	assert.Equal(t, original.Layers[1], renamed.Layers[1])
+	assert.Equal(t, original.Config, renamed.Config)
+
+	outputDir := t.TempDir()
+	require.NoError(t, b.Extract(ctx, repo+":v1", &config.Extract{Concurrency: 1, Output: outputDir}))
+	data, err := os.ReadFile(filepath.Join(outputDir, "model.safetensors"))
+	require.NoError(t, err)
+	assert.Equal(t, "weights", string(data))
+	assert.NoFileExists(t, filepath.Join(outputDir, "weights/model.safetensors"))
+
+	t.Run("as target", func(t *testing.T) {
+		cfg := &config.Move{Target: "example.com/models/renamed:v1"}
+		require.NoError(t, b.Move(ctx, repo+":v1", "config.json", "configs/config.json", cfg))
+
+		moved := pullManifest(t, "example.com/models/renamed", "v1")
+		assert.Equal(t, "configs/config.json", layerFilepath(moved.Layers[1]))
+		assert.Equal(t, "config.json", layerFilepath(pullManifest(t, repo, "v1").Layers[1]))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := b.Move(ctx, repo+":v1", "weights/model.safetensors", "model-00001.safetensors", config.NewMove())
+		assert.ErrorContains(t, err, "is not found")
+	})
+
+	t.Run("existing file", func(t *testing.T) {
+		err := b.Move(ctx, repo+":v1", "model.safetensors", "config.json", config.NewMove())
+		assert.ErrorContains(t, err, "already exists")
+	})
+
+	t.Run("escaping path", func(t *testing.T) {
+		err := b.Move(ctx, repo+":v1", "model.safetensors", "../model.safetensors", config.NewMove())
+		assert.ErrorContains(t, err, "invalid path")
+	})
+}
+
+func TestMoveTarLayer(t *testing.T) {
+	modelfilePath := filepath.Join(t.TempDir(), "Modelfile")
+	require.NoError(t, os.WriteFile(modelfilePath, []byte("NAME test\nMODEL *.safetensors\n"), 0644))
+
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewBuild()
+	cfg.Raw = false
+	require.NoError(t, b.BuildFromFiles(ctx, modelfilePath, map[string][]byte{"model.safetensors": []byte("weights")}, "example.com/models/tar:v1", cfg))
+
+	err := b.Move(ctx, "example.com/models/tar:v1", "model.safetensors", "renamed.safetensors", config.NewMove())
+	assert.ErrorContains(t, err, "only the raw layers can be renamed")
+}
@@ -22,9 +22,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/avast/retry-go/v4"
 	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
@@ -34,8 +36,10 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/archiver"
 	"github.com/modelpack/modctl/pkg/backend/build"
 	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	"github.com/modelpack/modctl/pkg/codec"
 	"github.com/modelpack/modctl/pkg/storage"
 )
 
@@ -52,6 +56,9 @@ type base struct {
 	// which is used to store in the layer filepath annotation,
 	// it can be empty and by default is relative path to the workDir.
 	destDir string
+	// keepRaw reports whether the file is stored as-is in the raw media type regardless
+	// of the format and the compression, it can be nil.
+	keepRaw func(path string) bool
 }
 
 // Process implements the Processor interface, which can be reused by other processors.
@@ -63,43 +70,28 @@ func (b *base) Process(ctx context.Context, builder build.Builder, workDir strin
 		opt(processOpts)
 	}
 
-	absWorkDir, err := filepath.Abs(workDir)
+	var (
+		matchedPaths []string
+		err          error
+	)
+	if processOpts.archive != nil {
+		matchedPaths, err = b.matchArchive(processOpts.archive)
+	} else {
+		matchedPaths, err = b.matchWorkDir(workDir)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var matchedPaths []string
-	for _, pattern := range b.patterns {
-		// Check if the pattern is a specific file path (no wildcards)
-		if !strings.ContainsAny(pattern, "*?[]") {
-			// For specific file paths, check if the file exists
-			var fullPath string
-			if filepath.IsAbs(pattern) {
-				fullPath = pattern
-			} else {
-				fullPath = filepath.Join(absWorkDir, pattern)
-			}
-
-			if _, err := os.Stat(fullPath); err != nil {
-				if os.IsNotExist(err) {
-					return nil, fmt.Errorf("file specified in Modelfile does not exist: %s", pattern)
-				}
-				return nil, fmt.Errorf("failed to check file: %s, error: %w", pattern, err)
-			}
-
-			matchedPaths = append(matchedPaths, fullPath)
-		} else {
-			// For patterns with wildcards, use glob matching
-			matches, err := filepath.Glob(filepath.Join(absWorkDir, pattern))
-			if err != nil {
-				return nil, err
-			}
-
-			matchedPaths = append(matchedPaths, matches...)
+	if len(processOpts.overrides) > 0 {
+		matchedPaths, err = b.applyOverrides(matchedPaths, workDir, processOpts.archive, processOpts.overrides)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	sort.Strings(matchedPaths)
+	matchedPaths = slices.Compact(matchedPaths)
 
 	logrus.Infof("processor: matched %s files [count: %d]", b.name, len(matchedPaths))
 
@@ -129,6 +121,13 @@ func (b *base) Process(ctx context.Context, builder build.Builder, workDir strin
 		defer tracker.Stop()
 	}
 
+	// Track the files by a single progress bar of the processor in the aggregate progress mode.
+	var aggregate *internalpb.Aggregate
+	if processOpts.aggregateProgress && len(matchedPaths) > 0 {
+		aggregate = tracker.NewAggregate(internalpb.NormalizePrompt("Building layers"), b.name)
+		defer aggregate.Done()
+	}
+
 	for _, path := range matchedPaths {
 		if ctx.Err() != nil {
 			break
@@ -141,6 +140,7 @@ func (b *base) Process(ctx context.Context, builder build.Builder, workDir strin
 			default:
 			}
 
+			start := time.Now()
 			if err := retry.Do(func() error {
 				logrus.Debugf("processor: processing %s file %s", b.name, path)
 
@@ -149,17 +149,54 @@ func (b *base) Process(ctx context.Context, builder build.Builder, workDir strin
 					destPath = filepath.Join(b.destDir, filepath.Base(path))
 				}
 
-				desc, err := builder.BuildLayer(ctx, b.mediaType, workDir, path, destPath, hooks.NewHooks(
+				layerHooks := hooks.NewHooks(
 					hooks.WithOnStart(func(name string, size int64, reader io.Reader) io.Reader {
+						if aggregate != nil {
+							return aggregate.Add(name, size, reader)
+						}
+
 						return tracker.Add(internalpb.NormalizePrompt("Building layer"), name, size, reader)
 					}),
 					hooks.WithOnError(func(name string, err error) {
+						if aggregate != nil {
+							aggregate.Abort(name, fmt.Errorf("failed to build layer: %w", err))
+							return
+						}
+
 						tracker.Abort(name, fmt.Errorf("failed to build layer: %w", err))
 					}),
 					hooks.WithOnComplete(func(name string, desc ocispec.Descriptor) {
+						if aggregate != nil {
+							aggregate.Complete(name, fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Built layer"), desc.Digest))
+							return
+						}
+
 						tracker.Complete(name, fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Built layer"), desc.Digest))
 					}),
-				))
+				)
+
+				// Store the incompressible files uncompressed by the compression policy.
+				mediaType := b.mediaType
+				if processOpts.compressionPolicy != nil {
+					mediaType = processOpts.compressionPolicy.MediaType(mediaType, path)
+				}
+
+				// Pack the directory specified in the Modelfile, such as `CODE src/`, into a single tar layer.
+				if processOpts.archive == nil && isDir(path) {
+					mediaType = codec.TarMediaType(mediaType)
+				} else if b.keepRaw != nil && b.keepRaw(path) {
+					mediaType = codec.RawMediaType(mediaType)
+				}
+
+				var (
+					desc ocispec.Descriptor
+					err  error
+				)
+				if processOpts.archive != nil {
+					desc, err = builder.BuildLayerFromArchive(ctx, mediaType, processOpts.archive, path, destPath, layerHooks)
+				} else {
+					desc, err = builder.BuildLayer(ctx, mediaType, workDir, path, destPath, layerHooks)
+				}
 				if err != nil {
 					return fmt.Errorf("processor: failed to build layer for %s file %s: %w", b.name, path, err)
 				}
@@ -169,6 +206,15 @@ func (b *base) Process(ctx context.Context, builder build.Builder, workDir strin
 				descriptors = append(descriptors, desc)
 				mu.Unlock()
 
+				if processOpts.timings != nil {
+					// Prefer the filepath in the layer which is relative to the work directory.
+					timingPath := path
+					if layerPath := desc.Annotations[modelspec.AnnotationFilepath]; layerPath != "" {
+						timingPath = layerPath
+					}
+					processOpts.timings.Record(timingPath, desc.Size, time.Since(start))
+				}
+
 				return nil
 			}, append(defaultRetryOpts, retry.Context(ctx))...); err != nil {
 				logrus.Error(err)
@@ -214,3 +260,159 @@ func (b *base) Process(ctx context.Context, builder build.Builder, workDir strin
 
 	return descriptors, nil
 }
+
+// matchWorkDir returns the paths of the files in the work directory matching the patterns.
+func (b *base) matchWorkDir(workDir string) ([]string, error) {
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedPaths []string
+	for _, pattern := range b.patterns {
+		// Check if the pattern is a specific file path (no wildcards)
+		if !strings.ContainsAny(pattern, "*?[]") {
+			// For specific file paths, check if the file exists
+			var fullPath string
+			if filepath.IsAbs(pattern) {
+				fullPath = pattern
+			} else {
+				fullPath = filepath.Join(absWorkDir, pattern)
+			}
+
+			if _, err := os.Stat(fullPath); err != nil {
+				if os.IsNotExist(err) {
+					return nil, fmt.Errorf("file specified in Modelfile does not exist: %s", pattern)
+				}
+				return nil, fmt.Errorf("failed to check file: %s, error: %w", pattern, err)
+			}
+
+			matchedPaths = append(matchedPaths, fullPath)
+		} else {
+			// For patterns with wildcards, use glob matching
+			matches, err := filepath.Glob(filepath.Join(absWorkDir, pattern))
+			if err != nil {
+				return nil, err
+			}
+
+			matchedPaths = append(matchedPaths, matches...)
+		}
+	}
+
+	return matchedPaths, nil
+}
+
+// applyOverrides reroutes the files by the override patterns keyed by the processor names, which
+// take precedence over the patterns in the Modelfile. The files matching the override patterns of
+// this processor are added, and the ones matching the override patterns of the other processors
+// are left to them.
+func (b *base) applyOverrides(matchedPaths []string, workDir string, archive *archiver.Archive, overrides map[string][]string) ([]string, error) {
+	var root string
+	if archive == nil {
+		absWorkDir, err := filepath.Abs(workDir)
+		if err != nil {
+			return nil, err
+		}
+
+		root = absWorkDir
+	}
+
+	candidates := slices.Clone(matchedPaths)
+	for _, pattern := range overrides[b.name] {
+		var (
+			matches []string
+			err     error
+		)
+		if archive != nil {
+			matches, err = archive.Match(pattern)
+		} else {
+			matches, err = filepath.Glob(filepath.Join(root, pattern))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to match the override pattern %s: %w", pattern, err)
+		}
+
+		candidates = append(candidates, matches...)
+	}
+
+	// the processors are consulted in the order of the names to make the owner deterministic.
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := []string{}
+	for _, path := range candidates {
+		relPath := path
+		if root != "" {
+			if rel, err := filepath.Rel(root, path); err == nil {
+				relPath = rel
+			}
+		}
+
+		owner := ""
+		for _, name := range names {
+			if matchAny(overrides[name], relPath) {
+				owner = name
+				break
+			}
+		}
+
+		if owner != "" && owner != b.name {
+			logrus.Infof("processor: rerouted %s file %s to the %s processor by override", b.name, relPath, owner)
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// matchAny returns whether the path matches any of the patterns.
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, path); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDir returns whether the path is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// matchArchive returns the names of the entries in the archive matching the patterns.
+func (b *base) matchArchive(archive *archiver.Archive) ([]string, error) {
+	var matchedNames []string
+	for _, pattern := range b.patterns {
+		// Check if the pattern is a specific entry name (no wildcards).
+		if !strings.ContainsAny(pattern, "*?[]") {
+			entry, ok := archive.Entry(pattern)
+			if !ok {
+				// The directory form packing the directory into a single layer only works for the work directory.
+				if archive.IsDir(pattern) {
+					return nil, fmt.Errorf("directories are not supported in archive, use a glob pattern like %s/* instead: %s", strings.TrimSuffix(pattern, "/"), pattern)
+				}
+
+				return nil, fmt.Errorf("file specified in Modelfile does not exist in archive: %s", pattern)
+			}
+
+			matchedNames = append(matchedNames, entry.Header.Name)
+		} else {
+			matches, err := archive.Match(pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			matchedNames = append(matchedNames, matches...)
+		}
+	}
+
+	return matchedNames, nil
+}
@@ -0,0 +1,190 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processor
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	"github.com/modelpack/modctl/pkg/codec"
+	buildmock "github.com/modelpack/modctl/test/mocks/backend/build"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestProcessCompressionPolicy(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"figure.png", "README.md", "train.py"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(name), 0644))
+	}
+
+	builder := &buildmock.Builder{}
+	builder.On("BuildLayer", mock.Anything, mock.Anything, workDir, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, mediaType, workDir, path, destPath string, _ hooks.Hooks) (ocispec.Descriptor, error) {
+			name := filepath.Base(path)
+			return ocispec.Descriptor{
+				MediaType:   mediaType,
+				Digest:      godigest.FromString(name),
+				Size:        int64(len(name)),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+			}, nil
+		},
+	)
+
+	policy, err := codec.NewCompressionPolicy(nil, nil)
+	require.NoError(t, err)
+
+	process := func(p Processor) map[string]string {
+		descs, err := p.Process(context.Background(), builder, workDir, WithCompressionPolicy(policy), WithProgressTracker(pb.NewProgressBar(io.Discard)))
+		require.NoError(t, err)
+
+		mediaTypes := map[string]string{}
+		for _, desc := range descs {
+			mediaTypes[desc.Annotations[modelspec.AnnotationFilepath]] = desc.MediaType
+		}
+
+		return mediaTypes
+	}
+
+	// the incompressible png is stored uncompressed while the markdown is compressed.
+	docs := process(NewDocProcessor(&storage.Storage{}, modelspec.MediaTypeModelDocGzip, []string{"*.png", "*.md"}, ""))
+	assert.Equal(t, map[string]string{
+		"figure.png": modelspec.MediaTypeModelDoc,
+		"README.md":  modelspec.MediaTypeModelDocGzip,
+	}, docs)
+
+	codes := process(NewCodeProcessor(&storage.Storage{}, modelspec.MediaTypeModelCodeGzip, []string{"*.py"}, ""))
+	assert.Equal(t, map[string]string{"train.py": modelspec.MediaTypeModelCodeGzip}, codes)
+
+	// the uncompressed media types are not affected by the policy.
+	raws := process(NewDocProcessor(&storage.Storage{}, modelspec.MediaTypeModelDocRaw, []string{"*.png"}, ""))
+	assert.Equal(t, map[string]string{"figure.png": modelspec.MediaTypeModelDocRaw}, raws)
+}
+
+func TestProcessDirectory(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "src", "utils"), 0755))
+	for _, name := range []string{"train.py", "src/main.py", "src/utils/utils.py"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(name), 0644))
+	}
+
+	builder := &buildmock.Builder{}
+	builder.On("BuildLayer", mock.Anything, mock.Anything, workDir, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, mediaType, workDir, path, destPath string, _ hooks.Hooks) (ocispec.Descriptor, error) {
+			relPath, err := filepath.Rel(workDir, path)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+
+			return ocispec.Descriptor{
+				MediaType:   mediaType,
+				Digest:      godigest.FromString(relPath),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: relPath},
+			}, nil
+		},
+	)
+
+	// the directory is packed into a single tar layer while the file stays raw.
+	p := NewCodeProcessor(&storage.Storage{}, modelspec.MediaTypeModelCodeRaw, []string{"train.py", "src/"}, "")
+	descs, err := p.Process(context.Background(), builder, workDir, WithProgressTracker(pb.NewProgressBar(io.Discard)))
+	require.NoError(t, err)
+	require.Len(t, descs, 2)
+	assert.Equal(t, "src", descs[0].Annotations[modelspec.AnnotationFilepath])
+	assert.Equal(t, modelspec.MediaTypeModelCode, descs[0].MediaType)
+	assert.Equal(t, "train.py", descs[1].Annotations[modelspec.AnnotationFilepath])
+	assert.Equal(t, modelspec.MediaTypeModelCodeRaw, descs[1].MediaType)
+}
+
+func TestProcessArchiveDirectory(t *testing.T) {
+	archive, err := archiver.NewMemoryArchive(map[string][]byte{
+		"train.py":    []byte("train.py"),
+		"src/main.py": []byte("src/main.py"),
+	})
+	require.NoError(t, err)
+	defer archive.Close()
+
+	// the directory is not packed from the archive, the glob pattern matches its files instead.
+	p := NewCodeProcessor(&storage.Storage{}, modelspec.MediaTypeModelCodeRaw, []string{"train.py", "src/"}, "")
+	_, err = p.Process(context.Background(), &buildmock.Builder{}, "", WithArchive(archive), WithProgressTracker(pb.NewProgressBar(io.Discard)))
+	assert.ErrorContains(t, err, "directories are not supported in archive")
+
+	p = NewCodeProcessor(&storage.Storage{}, modelspec.MediaTypeModelCodeRaw, []string{"missing/"}, "")
+	_, err = p.Process(context.Background(), &buildmock.Builder{}, "", WithArchive(archive), WithProgressTracker(pb.NewProgressBar(io.Discard)))
+	assert.ErrorContains(t, err, "does not exist in archive")
+}
+
+func TestProcessOverrides(t *testing.T) {
+	workDir := t.TempDir()
+	for _, name := range []string{"model.bin", "tokenizer.bin", "config.json", "vocab.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(name), 0644))
+	}
+
+	builder := &buildmock.Builder{}
+	builder.On("BuildLayer", mock.Anything, mock.Anything, workDir, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, mediaType, workDir, path, destPath string, _ hooks.Hooks) (ocispec.Descriptor, error) {
+			name := filepath.Base(path)
+			return ocispec.Descriptor{
+				MediaType:   mediaType,
+				Digest:      godigest.FromString(name),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+			}, nil
+		},
+	)
+
+	overrides := WithOverrides(map[string][]string{
+		ModelConfigProcessorName: {"tokenizer*.bin", "vocab.txt"},
+	})
+	process := func(p Processor) map[string]string {
+		descs, err := p.Process(context.Background(), builder, workDir, overrides, WithProgressTracker(pb.NewProgressBar(io.Discard)))
+		require.NoError(t, err)
+
+		mediaTypes := map[string]string{}
+		for _, desc := range descs {
+			mediaTypes[desc.Annotations[modelspec.AnnotationFilepath]] = desc.MediaType
+		}
+
+		return mediaTypes
+	}
+
+	// the tokenizer matching *.bin of the Modelfile is rerouted to the config processor.
+	models := process(NewModelProcessor(&storage.Storage{}, modelspec.MediaTypeModelWeightRaw, []string{"*.bin"}, ""))
+	assert.Equal(t, map[string]string{"model.bin": modelspec.MediaTypeModelWeightRaw}, models)
+
+	// the overridden files are added even if they are not in the Modelfile, and not duplicated.
+	configs := process(NewModelConfigProcessor(&storage.Storage{}, modelspec.MediaTypeModelWeightConfigRaw, []string{"config.json", "vocab.txt"}, ""))
+	assert.Equal(t, map[string]string{
+		"config.json":   modelspec.MediaTypeModelWeightConfigRaw,
+		"tokenizer.bin": modelspec.MediaTypeModelWeightConfigRaw,
+		"vocab.txt":     modelspec.MediaTypeModelWeightConfigRaw,
+	}, configs)
+
+	// the doc processor leaves the overridden vocab.txt to the config processor.
+	docs := process(NewDocProcessor(&storage.Storage{}, modelspec.MediaTypeModelDocRaw, []string{"*.txt"}, ""))
+	assert.Empty(t, docs)
+}
@@ -26,14 +26,15 @@ import (
 )
 
 const (
-	codeProcessorName = "code"
+	// CodeProcessorName is the name of the code processor.
+	CodeProcessorName = "code"
 )
 
 // NewCodeProcessor creates a new code processor.
 func NewCodeProcessor(store storage.Storage, mediaType string, patterns []string, destDir string) Processor {
 	return &codeProcessor{
 		base: &base{
-			name:      codeProcessorName,
+			name:      CodeProcessorName,
 			store:     store,
 			mediaType: mediaType,
 			patterns:  patterns,
@@ -48,7 +49,7 @@ type codeProcessor struct {
 }
 
 func (p *codeProcessor) Name() string {
-	return codeProcessorName
+	return CodeProcessorName
 }
 
 func (p *codeProcessor) Process(ctx context.Context, builder build.Builder, workDir string, opts ...ProcessOption) ([]ocispec.Descriptor, error) {
@@ -0,0 +1,60 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processor
+
+import (
+	"context"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/backend/build"
+	"github.com/modelpack/modctl/pkg/storage"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// DatasetProcessorName is the name of the dataset processor.
+	DatasetProcessorName = "dataset"
+)
+
+// NewDatasetProcessor creates a new dataset processor, the datasets shipped as
+// archives such as *.zip and *.tar.gz are stored as-is in the raw media type.
+func NewDatasetProcessor(store storage.Storage, mediaType string, patterns []string, destDir string) Processor {
+	return &datasetProcessor{
+		base: &base{
+			name:      DatasetProcessorName,
+			store:     store,
+			mediaType: mediaType,
+			patterns:  patterns,
+			destDir:   destDir,
+			keepRaw:   archiver.IsArchiveName,
+		},
+	}
+}
+
+// datasetProcessor is the processor to process the dataset file.
+type datasetProcessor struct {
+	base *base
+}
+
+func (p *datasetProcessor) Name() string {
+	return DatasetProcessorName
+}
+
+func (p *datasetProcessor) Process(ctx context.Context, builder build.Builder, workDir string, opts ...ProcessOption) ([]ocispec.Descriptor, error) {
+	return p.base.Process(ctx, builder, workDir, opts...)
+}
@@ -0,0 +1,92 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	buildmock "github.com/modelpack/modctl/test/mocks/backend/build"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+type datasetProcessorSuite struct {
+	suite.Suite
+	mockStore   *storage.Storage
+	mockBuilder *buildmock.Builder
+	processor   Processor
+	workDir     string
+}
+
+func (s *datasetProcessorSuite) SetupTest() {
+	s.mockStore = &storage.Storage{}
+	s.mockBuilder = &buildmock.Builder{}
+	s.processor = NewDatasetProcessor(s.mockStore, modelspec.MediaTypeModelDataset, []string{"data/*"}, "")
+	// generate test files for process.
+	s.workDir = s.Suite.T().TempDir()
+	if err := os.MkdirAll(filepath.Join(s.workDir, "data"), 0755); err != nil {
+		s.Suite.T().Fatal(err)
+	}
+	for _, name := range []string{"train.csv", "images.zip"} {
+		if err := os.WriteFile(filepath.Join(s.workDir, "data", name), []byte(""), 0644); err != nil {
+			s.Suite.T().Fatal(err)
+		}
+	}
+}
+
+func (s *datasetProcessorSuite) TestName() {
+	assert.Equal(s.Suite.T(), "dataset", s.processor.Name())
+}
+
+func (s *datasetProcessorSuite) TestProcess() {
+	ctx := context.Background()
+	s.mockBuilder.On("BuildLayer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, mediaType, workDir, path, destPath string, _ hooks.Hooks) ocispec.Descriptor {
+			return ocispec.Descriptor{
+				MediaType:   mediaType,
+				Digest:      godigest.FromString(path),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: filepath.Base(path)},
+			}
+		}, nil)
+
+	descs, err := s.processor.Process(ctx, s.mockBuilder, s.workDir)
+	assert.NoError(s.Suite.T(), err)
+	assert.Len(s.Suite.T(), descs, 2)
+
+	mediaTypes := map[string]string{}
+	for _, desc := range descs {
+		mediaTypes[desc.Annotations[modelspec.AnnotationFilepath]] = desc.MediaType
+	}
+
+	// the archive is stored as-is, the other files follow the media type of the processor.
+	assert.Equal(s.Suite.T(), modelspec.MediaTypeModelDatasetRaw, mediaTypes["images.zip"])
+	assert.Equal(s.Suite.T(), modelspec.MediaTypeModelDataset, mediaTypes["train.csv"])
+}
+
+func TestDatasetProcessorSuite(t *testing.T) {
+	suite.Run(t, new(datasetProcessorSuite))
+}
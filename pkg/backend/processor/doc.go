@@ -26,14 +26,15 @@ import (
 )
 
 const (
-	docProcessorName = "doc"
+	// DocProcessorName is the name of the doc processor.
+	DocProcessorName = "doc"
 )
 
 // NewDocProcessor creates a new doc processor.
 func NewDocProcessor(store storage.Storage, mediaType string, patterns []string, destDir string) Processor {
 	return &docProcessor{
 		base: &base{
-			name:      docProcessorName,
+			name:      DocProcessorName,
 			store:     store,
 			mediaType: mediaType,
 			patterns:  patterns,
@@ -48,7 +49,7 @@ type docProcessor struct {
 }
 
 func (p *docProcessor) Name() string {
-	return docProcessorName
+	return DocProcessorName
 }
 
 func (p *docProcessor) Process(ctx context.Context, builder build.Builder, workDir string, opts ...ProcessOption) ([]ocispec.Descriptor, error) {
@@ -26,14 +26,15 @@ import (
 )
 
 const (
-	modelProcessorName = "model"
+	// ModelProcessorName is the name of the model processor.
+	ModelProcessorName = "model"
 )
 
 // NewModelProcessor creates a new model processor.
 func NewModelProcessor(store storage.Storage, mediaType string, patterns []string, destDir string) Processor {
 	return &modelProcessor{
 		base: &base{
-			name:      modelProcessorName,
+			name:      ModelProcessorName,
 			store:     store,
 			mediaType: mediaType,
 			patterns:  patterns,
@@ -48,7 +49,7 @@ type modelProcessor struct {
 }
 
 func (p *modelProcessor) Name() string {
-	return modelProcessorName
+	return ModelProcessorName
 }
 
 func (p *modelProcessor) Process(ctx context.Context, builder build.Builder, workDir string, opts ...ProcessOption) ([]ocispec.Descriptor, error) {
@@ -26,14 +26,15 @@ import (
 )
 
 const (
-	modelConfigProcessorName = "config"
+	// ModelConfigProcessorName is the name of the model config processor.
+	ModelConfigProcessorName = "config"
 )
 
 // NewModelConfigProcessor creates a new model config processor.
 func NewModelConfigProcessor(store storage.Storage, mediaType string, patterns []string, destDir string) Processor {
 	return &modelConfigProcessor{
 		base: &base{
-			name:      modelConfigProcessorName,
+			name:      ModelConfigProcessorName,
 			store:     store,
 			mediaType: mediaType,
 			patterns:  patterns,
@@ -48,7 +49,7 @@ type modelConfigProcessor struct {
 }
 
 func (p *modelConfigProcessor) Name() string {
-	return modelConfigProcessorName
+	return ModelConfigProcessorName
 }
 
 func (p *modelConfigProcessor) Process(ctx context.Context, builder build.Builder, workDir string, opts ...ProcessOption) ([]ocispec.Descriptor, error) {
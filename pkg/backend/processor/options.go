@@ -22,6 +22,9 @@ import (
 	retry "github.com/avast/retry-go/v4"
 
 	"github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/codec"
 )
 
 type ProcessOption func(*processOptions)
@@ -31,6 +34,16 @@ type processOptions struct {
 	concurrency int
 	// progressTracker is the progress bar to use for tracking progress.
 	progressTracker *pb.ProgressBar
+	// archive is the tar archive to process the files from instead of the work directory.
+	archive *archiver.Archive
+	// timings is the collector of the build duration of the files.
+	timings *Timings
+	// compressionPolicy decides whether the files are compressed with the compressed media type.
+	compressionPolicy *codec.CompressionPolicy
+	// aggregateProgress tracks the files by a single progress bar instead of one bar per file.
+	aggregateProgress bool
+	// overrides is the patterns overriding the classification of the files keyed by the processor names.
+	overrides map[string][]string
 }
 
 func WithConcurrency(concurrency int) ProcessOption {
@@ -45,9 +58,50 @@ func WithProgressTracker(tracker *pb.ProgressBar) ProcessOption {
 	}
 }
 
+// WithArchive processes the entries of the tar archive instead of the files in the work directory.
+func WithArchive(archive *archiver.Archive) ProcessOption {
+	return func(o *processOptions) {
+		o.archive = archive
+	}
+}
+
+// WithTimings records the build duration of the files into the timings.
+func WithTimings(timings *Timings) ProcessOption {
+	return func(o *processOptions) {
+		o.timings = timings
+	}
+}
+
+// WithCompressionPolicy stores the incompressible files by the policy uncompressed when the
+// media type of the processor is compressed.
+func WithCompressionPolicy(policy *codec.CompressionPolicy) ProcessOption {
+	return func(o *processOptions) {
+		o.compressionPolicy = policy
+	}
+}
+
+// WithAggregateProgress tracks the files of the processor by a single progress bar with the
+// number of the files and the total bytes, which reduces the noise of many small files.
+func WithAggregateProgress(aggregate bool) ProcessOption {
+	return func(o *processOptions) {
+		o.aggregateProgress = aggregate
+	}
+}
+
+// WithOverrides routes the files matching the patterns to the processor of the name regardless of
+// the patterns of the processors, such as the tokenizer*.bin to the config processor instead of
+// the model processor matching *.bin.
+func WithOverrides(overrides map[string][]string) ProcessOption {
+	return func(o *processOptions) {
+		o.overrides = overrides
+	}
+}
+
 var defaultRetryOpts = []retry.Option{
 	retry.Attempts(6),
 	retry.DelayType(retry.BackOffDelay),
 	retry.Delay(5 * time.Second),
 	retry.MaxDelay(60 * time.Second),
+	// fail fast on the registry errors which won't succeed on retry, such as 400, 401 and 404.
+	retry.RetryIf(remote.IsRetryable),
 }
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processor
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// FileTiming is the build duration of a file.
+type FileTiming struct {
+	// Path is the path of the file.
+	Path string
+	// Size is the size of the built layer.
+	Size int64
+	// Duration is the duration of building the layer including the retries.
+	Duration time.Duration
+}
+
+// Timings collects the build duration of the files processed concurrently.
+type Timings struct {
+	mu      sync.Mutex
+	timings []FileTiming
+}
+
+// NewTimings creates a new timings collector.
+func NewTimings() *Timings {
+	return &Timings{}
+}
+
+// Record records the build duration of the file.
+func (t *Timings) Record(path string, size int64, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.timings = append(t.timings, FileTiming{Path: path, Size: size, Duration: duration})
+}
+
+// Slowest returns the n slowest files in the descending order of the duration.
+func (t *Timings) Slowest(n int) []FileTiming {
+	t.mu.Lock()
+	timings := append([]FileTiming(nil), t.timings...)
+	t.mu.Unlock()
+
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Duration > timings[j].Duration
+	})
+
+	if n < len(timings) {
+		timings = timings[:n]
+	}
+
+	return timings
+}
+
+// Report writes the report of the n slowest files to the writer.
+func (t *Timings) Report(w io.Writer, n int) {
+	slowest := t.Slowest(n)
+	if len(slowest) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Slowest %d files:\n", len(slowest))
+	for _, timing := range slowest {
+		fmt.Fprintf(w, "  %10s  %10s  %s\n", timing.Duration.Round(time.Millisecond), humanize.IBytes(uint64(timing.Size)), timing.Path)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/backend/build/hooks"
+	buildmock "github.com/modelpack/modctl/test/mocks/backend/build"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestProcessTimings(t *testing.T) {
+	workDir := t.TempDir()
+	names := []string{"fast-1.safetensors", "slow.safetensors", "fast-2.safetensors"}
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(name), 0644))
+	}
+
+	builder := &buildmock.Builder{}
+	builder.On("BuildLayer", mock.Anything, mock.Anything, workDir, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, mediaType, workDir, path, destPath string, _ hooks.Hooks) (ocispec.Descriptor, error) {
+			name := filepath.Base(path)
+			// delay the build of the slow file intentionally.
+			if name == "slow.safetensors" {
+				time.Sleep(200 * time.Millisecond)
+			}
+
+			return ocispec.Descriptor{
+				Digest:      godigest.FromString(name),
+				Size:        int64(len(name)),
+				Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+			}, nil
+		},
+	)
+
+	timings := NewTimings()
+	p := NewModelProcessor(&storage.Storage{}, modelspec.MediaTypeModelWeight, []string{"*.safetensors"}, "")
+	descs, err := p.Process(context.Background(), builder, workDir, WithConcurrency(3), WithTimings(timings), WithProgressTracker(pb.NewProgressBar(io.Discard)))
+	require.NoError(t, err)
+	require.Len(t, descs, 3)
+
+	slowest := timings.Slowest(1)
+	require.Len(t, slowest, 1)
+	assert.Equal(t, "slow.safetensors", slowest[0].Path)
+	assert.GreaterOrEqual(t, slowest[0].Duration, 200*time.Millisecond)
+	assert.Len(t, timings.Slowest(10), 3)
+
+	var report bytes.Buffer
+	timings.Report(&report, 2)
+	assert.Contains(t, report.String(), "Slowest 2 files:")
+	assert.Contains(t, report.String(), "slow.safetensors")
+}
@@ -20,21 +20,75 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/modelpack/modctl/pkg/config"
+
 	"github.com/sirupsen/logrus"
 )
 
-// Prune prunes the unused blobs and clean up the storage.
-func (b *backend) Prune(ctx context.Context, dryRun, removeUntagged bool) error {
+// Prune prunes the unused blobs and clean up the storage. If the time filter is set,
+// the model artifacts created within the time range are removed before pruning.
+func (b *backend) Prune(ctx context.Context, cfg *config.Prune) error {
+	if cfg.TimeFilter.IsSet() {
+		if err := b.pruneByCreatedAt(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := b.pruneModelfileReferrers(ctx, cfg); err != nil {
+		return err
+	}
+
 	logrus.Infof("prune: pruning unused blobs")
 
-	if err := b.store.PerformGC(ctx, dryRun, removeUntagged); err != nil {
+	if err := b.store.PerformGC(ctx, cfg.DryRun, cfg.RemoveUntagged); err != nil {
 		return fmt.Errorf("faile to perform gc: %w", err)
 	}
 
-	if err := b.store.PerformPurgeUploads(ctx, dryRun); err != nil {
+	if err := b.store.PerformPurgeUploads(ctx, cfg.DryRun); err != nil {
 		return fmt.Errorf("failed to perform purge uploads: %w", err)
 	}
 
 	logrus.Infof("prune: pruned unused blobs")
 	return nil
 }
+
+// pruneByCreatedAt removes the model artifacts created within the time range of the filter.
+func (b *backend) pruneByCreatedAt(ctx context.Context, cfg *config.Prune) error {
+	artifacts, err := b.List(ctx, &config.List{TimeFilter: cfg.TimeFilter})
+	if err != nil {
+		return fmt.Errorf("failed to list model artifacts: %w", err)
+	}
+
+	logrus.Infof("prune: found %d model artifacts within the time range", len(artifacts))
+
+	for _, artifact := range artifacts {
+		target := fmt.Sprintf("%s:%s", artifact.Repository, artifact.Tag)
+		if cfg.DryRun {
+			logrus.Infof("prune: would remove model artifact %s", target)
+			continue
+		}
+
+		if _, err := b.Remove(ctx, target); err != nil {
+			return fmt.Errorf("failed to remove model artifact %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// pruneModelfileReferrers removes the Modelfile referrers whose subject is no longer tagged,
+// such as the ones left by the remove of the previous versions.
+func (b *backend) pruneModelfileReferrers(ctx context.Context, cfg *config.Prune) error {
+	repos, err := b.store.ListRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	for _, repo := range repos {
+		if err := b.removeOrphanModelfileReferrers(ctx, repo, cfg.DryRun); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
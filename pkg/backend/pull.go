@@ -17,28 +17,36 @@
 package backend
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	retry "github.com/avast/retry-go/v4"
-	sha256 "github.com/minio/sha256-simd"
+	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
 	internalpb "github.com/modelpack/modctl/internal/pb"
 	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/breaker"
+	"github.com/modelpack/modctl/pkg/checksum"
 	"github.com/modelpack/modctl/pkg/codec"
+	"github.com/modelpack/modctl/pkg/concurrency"
 	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/pkg/iometrics"
+	"github.com/modelpack/modctl/pkg/logging"
+	"github.com/modelpack/modctl/pkg/signature"
 	"github.com/modelpack/modctl/pkg/storage"
 )
 
 // Pull pulls an artifact from a registry.
 func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) error {
+	start := time.Now()
 	logrus.Infof("pull: pulling artifact %s", target)
 
 	// Apply default hooks when caller leaves it unset to avoid nil deref.
@@ -47,6 +55,11 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 		cfg.Hooks = defaults.Hooks
 	}
 
+	// pull the tags matching the pattern, or all the tags of the repository.
+	if cfg.Tags != "" || cfg.AllTags {
+		return b.pullTags(ctx, target, cfg)
+	}
+
 	// pullByDragonfly is called if a Dragonfly endpoint is specified in the configuration.
 	if cfg.DragonflyEndpoint != "" {
 		logrus.Infof("pull: using dragonfly for %s", target)
@@ -65,20 +78,38 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 		return fmt.Errorf("failed to create the remote client: %w", err)
 	}
 
-	manifestDesc, manifestReader, err := src.Manifests().FetchReference(ctx, tag)
+	rootDesc, manifestDesc, manifest, err := fetchRootManifest(ctx, src, tag, cfg.Select)
 	if err != nil {
-		return fmt.Errorf("failed to fetch the manifest: %w", err)
+		return err
 	}
 
-	defer manifestReader.Close()
+	if err := checkExpectedDigest(target, manifestDesc, cfg.Expect); err != nil {
+		return err
+	}
 
-	var manifest ocispec.Manifest
-	if err := json.NewDecoder(manifestReader).Decode(&manifest); err != nil {
-		return fmt.Errorf("failed to decode the manifest: %w", err)
+	// abort the pull before pulling the layers if the manifest is not signed by the key.
+	if cfg.VerifySignature {
+		key, err := signature.LoadPublicKey(cfg.SignatureKey)
+		if err != nil {
+			return err
+		}
+
+		// the signature is made on the digest the target resolves to, which is the index if the
+		// manifest is selected from it.
+		if err := verifyPullSignature(ctx, src, target, rootDesc, key); err != nil {
+			return err
+		}
 	}
 
 	logrus.Debugf("pull: loaded manifest for target %s [manifest: %+v]", target, manifest)
 
+	// fail early if the storage or the extract dir is short of the space for the model artifact.
+	if !cfg.SkipSpaceCheck {
+		if err := b.checkPullSpace(ctx, repo, manifest, cfg); err != nil {
+			return err
+		}
+	}
+
 	// TODO: need refactor as currently use a global flag to control the progress bar render.
 	if cfg.DisableProgress {
 		internalpb.SetDisableProgress(true)
@@ -102,10 +133,23 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 	g, gctx := errgroup.WithContext(ctx)
 	g.SetLimit(cfg.Concurrency)
 
+	// adapt the concurrency by the throughput and the failures in the auto mode.
+	var controller *concurrency.Controller
+	if cfg.AutoConcurrency {
+		controller = concurrency.NewController("pull")
+		g.SetLimit(controller.Max())
+	}
+
+	// the layers extracted from remote share the config, so the shortened paths are recorded in one mapping.
+	remoteExtractor, err := newLayerExtractor(&config.Extract{Output: cfg.ExtractDir, Preallocate: cfg.Preallocate, OnConflict: cfg.OnConflict, DecryptionKey: cfg.DecryptionKey, ShortenLongPaths: cfg.ShortenLongPaths})
+	if err != nil {
+		return err
+	}
+
 	var fn func(desc ocispec.Descriptor) error
 	if cfg.ExtractFromRemote {
 		fn = func(desc ocispec.Descriptor) error {
-			return pullAndExtractFromRemote(gctx, pb, internalpb.NormalizePrompt("Pulling blob"), src, cfg.ExtractDir, desc, tracker)
+			return pullAndExtractFromRemote(gctx, pb, internalpb.NormalizePrompt("Pulling blob"), src, remoteExtractor, desc, tracker)
 		}
 	} else {
 		fn = func(desc ocispec.Descriptor) error {
@@ -113,8 +157,21 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 		}
 	}
 
-	logrus.Infof("pull: pulling %d layers for %s", len(manifest.Layers), target)
-	for _, layer := range manifest.Layers {
+	// share the retry budget across the layers to back off all of them when the remote is flapping.
+	retryBreaker := breaker.New("pull", breaker.WithRetryable(remote.IsRetryable))
+
+	// track the results of the layers to report the partial success if the pull fails.
+	report := newPullReport()
+
+	// skip the layers to store the manifest and the config only.
+	layers := manifest.Layers
+	if cfg.ManifestOnly {
+		logrus.Infof("pull: skipping %d layers for %s with manifest only", len(layers), target)
+		layers = nil
+	}
+
+	logrus.Infof("pull: pulling %d layers for %s", len(layers), target)
+	for _, layer := range layers {
 		g.Go(func() error {
 			select {
 			case <-gctx.Done():
@@ -129,32 +186,57 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 					logrus.Debugf("pull: layer %s skipped by hook", layer.Digest)
 					pb.Complete(layer.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), layer.Digest.String()))
 					cfg.Hooks.AfterPullLayer(layer, true, nil)
+					report.skip(layer)
 					return nil
 				}
-				err := tracker.TrackTransfer(func() error {
-					return fn(layer)
+				err := retryBreaker.Do(gctx, func() error {
+					return controller.Do(gctx, layer.Size, func() error {
+						return tracker.TrackTransfer(func() error {
+							return fn(layer)
+						})
+					})
 				})
 				// call the after hook.
 				cfg.Hooks.AfterPullLayer(layer, false, err)
 				if err != nil {
+					report.fail(layer, err)
 					err = fmt.Errorf("pull: failed to process layer %s: %w", layer.Digest, err)
 					logrus.Error(err)
+					return err
 				}
 
-				return err
+				report.complete(layer)
+				return nil
 			}, append(defaultRetryOpts, retry.Context(gctx))...)
 		})
 	}
 
-	if err := g.Wait(); err != nil {
-		return fmt.Errorf("failed to pull blob to local: %w", err)
+	// record the shortened paths even if the pull fails, so the extracted files can be located.
+	err = g.Wait()
+	if cfg.ExtractFromRemote {
+		if err := remoteExtractor.shortener.WriteMappings(); err != nil {
+			return err
+		}
 	}
 
-	logrus.Infof("pull: layers pulled [count: %d]", len(manifest.Layers))
+	if err != nil {
+		pullErr := report.error(target, manifest.Layers, err)
+		logrus.Errorf("pull: partially pulled %s [completed: %d, skipped: %d, failed: %d, remaining: %d]", target, len(pullErr.Completed), len(pullErr.Skipped), len(pullErr.Failed), len(pullErr.Remaining))
+		return pullErr
+	}
+
+	logrus.Infof("pull: layers pulled [count: %d]", len(layers))
 
 	// return earlier if extract from remote is enabled as config and manifest
 	// are not needed for this operation.
 	if cfg.ExtractFromRemote {
+		// the layers are extracted one by one, so the datasets are extracted after all of them.
+		if cfg.ExtractDatasets {
+			if err := extractDatasets(cfg.ExtractDir, manifest.Layers, false, cfg.OnConflict, remoteExtractor.shortener); err != nil {
+				return err
+			}
+		}
+
 		tracker.Summary()
 		return nil
 	}
@@ -168,9 +250,14 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 		return fmt.Errorf("failed to pull config to local: %w", err)
 	}
 
-	// copy the manifest.
+	// copy the manifest, which is annotated as metadata-only without the layers, so it never appears
+	// complete and is replaced by the original one when pulled again with the layers.
 	if err := retry.Do(func() error {
 		return tracker.TrackTransfer(func() error {
+			if cfg.ManifestOnly {
+				return pullManifest(ctx, pb, internalpb.NormalizePrompt("Pulling manifest"), src, dst, manifestDesc, repo, tag, tracker, annotateMetadataOnly)
+			}
+
 			return pullIfNotExist(ctx, pb, internalpb.NormalizePrompt("Pulling manifest"), src, dst, manifestDesc, repo, tag, tracker)
 		})
 	}, append(defaultRetryOpts, retry.Context(ctx))...); err != nil {
@@ -179,8 +266,8 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 
 	// export the target model artifact to the output directory if needed.
 	if cfg.ExtractDir != "" {
-		// set the concurrency to 1 because the pull already has concurrency control.
-		extractCfg := &config.Extract{Concurrency: 1, Output: cfg.ExtractDir}
+		// the free space of the extract dir is already checked before pulling.
+		extractCfg := &config.Extract{Concurrency: cfg.ExtractConcurrency, Output: cfg.ExtractDir, Preallocate: cfg.Preallocate, OnConflict: cfg.OnConflict, DecryptionKey: cfg.DecryptionKey, ExtractDatasets: cfg.ExtractDatasets, ShortenLongPaths: cfg.ShortenLongPaths, SkipSpaceCheck: true, IndexDir: b.extractIndexDir()}
 		if err := exportModelArtifact(ctx, dst, manifest, repo, extractCfg); err != nil {
 			return fmt.Errorf("failed to export the artifact to the output directory: %w", err)
 		}
@@ -188,70 +275,113 @@ func (b *backend) Pull(ctx context.Context, target string, cfg *config.Pull) err
 	}
 
 	tracker.Summary()
-	logrus.Infof("pull: pulled artifact %s", target)
+	logging.Event("pull", target, manifestDesc.Digest.String(), start).Infof("pull: pulled artifact %s", target)
 	return nil
 }
 
+// blobFlights deduplicates the concurrent pulls of the same blob into the same repository, such as
+// the blobs shared by the tags pulled concurrently, so that the blob is fetched only once.
+var blobFlights singleflight.Group
+
 // pullIfNotExist copies the content from the src storage to the dst storage if the content does not exist.
 func pullIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src *remote.Repository, dst storage.Storage, desc ocispec.Descriptor, repo, tag string, tracker *iometrics.Tracker) error {
-	// fetch the content from the source storage.
+	// the manifest is always stored to tag it, even if it exists by another tag.
+	if isManifestMediaType(desc.MediaType) {
+		return pullManifest(ctx, pb, prompt, src, dst, desc, repo, tag, tracker, nil)
+	}
+
+	// the pull is detached from the context of the first caller, as the other callers waiting
+	// for it should not fail if the first caller is canceled, and each caller waits for the
+	// pull until its own context is done.
+	leader := false
+	flight := blobFlights.DoChan(repo+"@"+desc.Digest.String(), func() (any, error) {
+		leader = true
+		return nil, pullBlobIfNotExist(context.WithoutCancel(ctx), pb, prompt, src, dst, desc, repo, tracker)
+	})
+
+	select {
+	case <-ctx.Done():
+		pb.Abort(desc.Digest.String(), ctx.Err())
+		return ctx.Err()
+	case result := <-flight:
+		// the bar of the first caller is aborted by the pull itself.
+		if result.Err != nil && !leader {
+			pb.Abort(desc.Digest.String(), result.Err)
+		}
+
+		return result.Err
+	}
+}
+
+// pullManifest copies the manifest from the src storage to the dst storage and tags it, the manifest
+// is rewritten by the transform before storing if it is not nil.
+func pullManifest(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src *remote.Repository, dst storage.Storage, desc ocispec.Descriptor, repo, tag string, tracker *iometrics.Tracker, transform func(body []byte, digest godigest.Digest) ([]byte, error)) error {
 	content, err := src.Fetch(ctx, desc)
 	if err != nil {
 		return err
 	}
-
 	defer content.Close()
 
 	reader := pb.Add(prompt, desc.Digest.String(), desc.Size, tracker.WrapReader(content))
-	hash := sha256.New()
-	reader = io.TeeReader(reader, hash)
+	hash := checksum.NewSHA256()
+	body, err := io.ReadAll(io.TeeReader(reader, hash))
+	if err != nil {
+		err = fmt.Errorf("failed to read manifest %s, err: %w", desc.Digest.String(), err)
+		pb.Abort(desc.Digest.String(), err)
+		return err
+	}
 
-	// push the content to the destination, and wrap the content reader for progress bar,
-	// manifest should use dst.Manifests().Push, others should use dst.Blobs().Push.
-	if desc.MediaType == ocispec.MediaTypeImageManifest {
-		// check whether the content exists in the destination storage.
-		exist, err := dst.StatManifest(ctx, repo, desc.Digest.String())
-		if err != nil {
-			err = fmt.Errorf("failed to check manifest %s, err: %w", desc.Digest.String(), err)
+	// validate the digest of the manifest before storing it.
+	if err := validateDigest(desc.Digest.String(), hash.Sum(nil)); err != nil {
+		err = fmt.Errorf("failed to validate the digest of the manifest %s, err: %w", desc.Digest.String(), err)
+		pb.Abort(desc.Digest.String(), err)
+		return err
+	}
+
+	if transform != nil {
+		if body, err = transform(body, desc.Digest); err != nil {
 			pb.Abort(desc.Digest.String(), err)
 			return err
 		}
+	}
 
-		if exist {
-			pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
-			return nil
-		}
+	if _, err := dst.PushManifest(ctx, repo, tag, desc.MediaType, body); err != nil {
+		err = fmt.Errorf("failed to store manifest %s, err: %w", desc.Digest.String(), err)
+		pb.Abort(desc.Digest.String(), err)
+		return err
+	}
 
-		body, err := io.ReadAll(reader)
-		if err != nil {
-			err = fmt.Errorf("failed to read manifest %s, err: %w", desc.Digest.String(), err)
-			pb.Abort(desc.Digest.String(), err)
-			return err
-		}
+	return nil
+}
 
-		if _, err := dst.PushManifest(ctx, repo, tag, body); err != nil {
-			err = fmt.Errorf("failed to store manifest %s, err: %w", desc.Digest.String(), err)
-			pb.Abort(desc.Digest.String(), err)
-			return err
-		}
-	} else {
-		exist, err := dst.StatBlob(ctx, repo, desc.Digest.String())
-		if err != nil {
-			err = fmt.Errorf("failed to check blob %s, err: %w", desc.Digest.String(), err)
-			pb.Abort(desc.Digest.String(), err)
-			return err
-		}
+// pullBlobIfNotExist copies the blob from the src storage to the dst storage if the blob does not exist,
+// the existence is checked before fetching to avoid the request of the existing blob.
+func pullBlobIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src *remote.Repository, dst storage.Storage, desc ocispec.Descriptor, repo string, tracker *iometrics.Tracker) error {
+	exist, err := dst.StatBlob(ctx, repo, desc.Digest.String())
+	if err != nil {
+		return fmt.Errorf("failed to check blob %s, err: %w", desc.Digest.String(), err)
+	}
 
-		if exist {
-			pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
-			return nil
-		}
+	if exist {
+		pb.Add(prompt, desc.Digest.String(), desc.Size, bytes.NewReader([]byte{}))
+		pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
+		return nil
+	}
 
-		if _, _, err := dst.PushBlob(ctx, repo, reader, desc); err != nil {
-			err = fmt.Errorf("failed to store blob %s, err: %w", desc.Digest.String(), err)
-			pb.Abort(desc.Digest.String(), err)
-			return err
-		}
+	content, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	reader := pb.Add(prompt, desc.Digest.String(), desc.Size, tracker.WrapReader(content))
+	hash := checksum.NewSHA256()
+	reader = io.TeeReader(reader, hash)
+
+	if _, _, err := dst.PushBlob(ctx, repo, reader, desc); err != nil {
+		err = fmt.Errorf("failed to store blob %s, err: %w", desc.Digest.String(), err)
+		pb.Abort(desc.Digest.String(), err)
+		return err
 	}
 
 	// validate the digest of the blob.
@@ -266,7 +396,7 @@ func pullIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt stri
 
 // pullAndExtractFromRemote pulls the layer and extract it to the target output path directly,
 // and will not store the layer to the local storage.
-func pullAndExtractFromRemote(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src *remote.Repository, outputDir string, desc ocispec.Descriptor, tracker *iometrics.Tracker) error {
+func pullAndExtractFromRemote(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src *remote.Repository, extractor *layerExtractor, desc ocispec.Descriptor, tracker *iometrics.Tracker) error {
 	// fetch the content from the source storage.
 	content, err := src.Fetch(ctx, desc)
 	if err != nil {
@@ -275,10 +405,10 @@ func pullAndExtractFromRemote(ctx context.Context, pb *internalpb.ProgressBar, p
 	defer content.Close()
 
 	reader := pb.Add(prompt, desc.Digest.String(), desc.Size, tracker.WrapReader(content))
-	hash := sha256.New()
+	hash := checksum.NewSHA256()
 	reader = io.TeeReader(reader, hash)
 
-	if err := extractLayer(desc, outputDir, reader); err != nil {
+	if err := extractor.extractLayer(desc, reader); err != nil {
 		if errors.Is(err, codec.ErrAlreadyUpToDate) {
 			logrus.Debugf(
 				"pull: skipping extraction for blob %s, already up-to-date",
@@ -288,6 +418,12 @@ func pullAndExtractFromRemote(ctx context.Context, pb *internalpb.ProgressBar, p
 			return nil
 		}
 
+		if errors.Is(err, codec.ErrSkipped) {
+			logrus.Infof("pull: skipping extraction for blob %s, the existing file is kept by the conflict policy", desc.Digest.String())
+			pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
+			return nil
+		}
+
 		wrapped := fmt.Errorf("failed to extract the blob %s to output directory: %w", desc.Digest.String(), err)
 		pb.Abort(desc.Digest.String(), wrapped)
 		return wrapped
@@ -309,7 +445,7 @@ func validateDigest(digest string, hash []byte) error {
 		return fmt.Errorf("digest is empty")
 	}
 
-	if len(hash) != sha256.Size {
+	if len(hash) != checksum.SHA256Size {
 		return fmt.Errorf("invalid hash length")
 	}
 
@@ -319,3 +455,17 @@ func validateDigest(digest string, hash []byte) error {
 
 	return nil
 }
+
+// checkExpectedDigest checks the digest of the resolved manifest against the expected digest,
+// which guards the pull from a tag that was moved to another artifact.
+func checkExpectedDigest(target string, desc ocispec.Descriptor, expect string) error {
+	if expect == "" {
+		return nil
+	}
+
+	if desc.Digest.String() != expect {
+		return fmt.Errorf("manifest digest %s of %s does not match the expected %s", desc.Digest, target, expect)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	retry "github.com/avast/retry-go/v4"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/iometrics"
+)
+
+func TestPullAllTags(t *testing.T) {
+	ctx := context.Background()
+	registry, contents := newMemoryRegistry(t)
+
+	// count the blob requests in front of the registry.
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	registryURL, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+	proxy := httputil.NewSingleHostReverseProxy(registryURL)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			mu.Lock()
+			requests[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]++
+			mu.Unlock()
+		}
+
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+
+	shared := []byte("weights shared by the tags")
+	for _, tag := range []string{"v1", "v2", "v3"} {
+		serveModel(t, contents, "models/llama3", tag, []remoteFile{
+			{"model.safetensors", modelspec.MediaTypeModelWeightRaw, shared},
+			{"README.md", modelspec.MediaTypeModelDoc, []byte("# llama3 " + tag)},
+		})
+	}
+
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	var report bytes.Buffer
+	cfg := config.NewPull()
+	cfg.PlainHTTP = true
+	cfg.ProgressWriter = io.Discard
+	cfg.DisableProgress = true
+	cfg.ReportWriter = &report
+	cfg.AllTags = true
+	cfg.TagConcurrency = 3
+	require.NoError(t, b.Pull(ctx, repo, cfg))
+
+	assert.Equal(t, 1, requests[godigest.FromBytes(shared).String()])
+	for _, tag := range []string{"v1", "v2", "v3"} {
+		assert.Equal(t, 1, requests[godigest.FromString("# llama3 "+tag).String()], tag)
+		assert.Contains(t, report.String(), repo+":"+tag+"\tpulled\n")
+
+		_, _, err := store.PullManifest(ctx, repo, tag)
+		assert.NoError(t, err, tag)
+	}
+	assert.Contains(t, blobs[repo], godigest.FromBytes(shared).String())
+
+	t.Run("failed tag", func(t *testing.T) {
+		serveModel(t, contents, "models/llama3", "v4", []remoteFile{
+			{"model.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("missing weights")},
+		})
+		delete(contents, "models/llama3/blobs/"+godigest.FromString("missing weights").String())
+
+		retryOpts := defaultRetryOpts
+		defaultRetryOpts = []retry.Option{retry.Attempts(1)}
+		t.Cleanup(func() { defaultRetryOpts = retryOpts })
+
+		report.Reset()
+		err := b.Pull(ctx, repo, cfg)
+		assert.ErrorContains(t, err, "failed to pull 1 of 4 tags")
+		assert.Contains(t, report.String(), repo+":v1\tpulled\n")
+		assert.Contains(t, report.String(), repo+":v4\tfailed\t")
+	})
+}
+
+func TestPullIfNotExistCanceledCaller(t *testing.T) {
+	content := []byte("weights shared by the tags")
+	desc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelWeightRaw, Digest: godigest.FromBytes(content), Size: int64(len(content))}
+
+	// the registry holds the blob until released.
+	var requests atomic.Int32
+	requested, release := make(chan struct{}, 1), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		requested <- struct{}{}
+		<-release
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+	src, err := remote.New(repo, remote.WithPlainHTTP(true))
+	require.NoError(t, err)
+	store, blobs := newMemoryStore()
+
+	pull := func(ctx context.Context) error {
+		pb := internalpb.NewProgressBar(io.Discard)
+		pb.Start()
+		defer pb.Stop()
+
+		return pullIfNotExist(ctx, pb, "Copying blob", src, store, desc, repo, "", iometrics.NewTracker("pull"))
+	}
+
+	// the first caller is canceled while the blob is fetched.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() { first <- pull(ctx) }()
+	<-requested
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	// the second caller shares the fetch, which is not canceled with the first caller.
+	second := make(chan error, 1)
+	go func() { second <- pull(context.Background()) }()
+	close(release)
+	require.NoError(t, <-second)
+
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Contains(t, blobs[repo], desc.Digest.String())
+}
@@ -18,7 +18,6 @@ package backend
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -63,15 +62,13 @@ func (b *backend) pullByDragonfly(ctx context.Context, target string, cfg *confi
 	}
 
 	// Fetch and decode manifest.
-	_, manifestReader, err := src.Manifests().FetchReference(ctx, tag)
+	manifestDesc, manifest, err := fetchManifest(ctx, src, tag, cfg.Select)
 	if err != nil {
-		return fmt.Errorf("failed to fetch manifest: %w", err)
+		return err
 	}
-	defer manifestReader.Close()
 
-	var manifest ocispec.Manifest
-	if err := json.NewDecoder(manifestReader).Decode(&manifest); err != nil {
-		return fmt.Errorf("failed to decode manifest: %w", err)
+	if err := checkExpectedDigest(target, manifestDesc, cfg.Expect); err != nil {
+		return err
 	}
 
 	logrus.Debugf("pull: loaded manifest for target %s [manifest: %+v]", target, manifest)
@@ -95,10 +92,20 @@ func (b *backend) pullByDragonfly(ctx context.Context, target string, cfg *confi
 		internalpb.SetDisableProgress(true)
 	}
 
+	if err := checkUnencrypted(manifest.Layers); err != nil {
+		return err
+	}
+
 	pb := internalpb.NewProgressBar(cfg.ProgressWriter)
 	pb.Start()
 	defer pb.Stop()
 
+	// The layers share the shortener, so the shortened paths are recorded in one mapping.
+	var shortener *archiver.PathShortener
+	if cfg.ShortenLongPaths {
+		shortener = archiver.NewPathShortener()
+	}
+
 	// Process layers concurrently.
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(cfg.Concurrency)
@@ -113,7 +120,7 @@ func (b *backend) pullByDragonfly(ctx context.Context, target string, cfg *confi
 			}
 
 			logrus.Debugf("pull: processing layer %s via dragonfly", layer.Digest)
-			if err := processLayer(ctx, pb, dfdaemon.NewDfdaemonDownloadClient(conn), ref, manifest, layer, authToken, cfg); err != nil {
+			if err := processLayer(ctx, pb, dfdaemon.NewDfdaemonDownloadClient(conn), ref, manifest, layer, authToken, shortener, cfg); err != nil {
 				return err
 			}
 			logrus.Debugf("pull: successfully processed layer %s via dragonfly", layer.Digest)
@@ -121,10 +128,22 @@ func (b *backend) pullByDragonfly(ctx context.Context, target string, cfg *confi
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	// Record the shortened paths even if the pull fails, so the extracted files can be located.
+	err = g.Wait()
+	if err := shortener.WriteMappings(); err != nil {
+		return err
+	}
+
+	if err != nil {
 		return err
 	}
 
+	if cfg.ExtractDatasets {
+		if err := extractDatasets(cfg.ExtractDir, manifest.Layers, false, cfg.OnConflict, shortener); err != nil {
+			return err
+		}
+	}
+
 	logrus.Infof("pull: pulled artifact %s via dragonfly", target)
 	return nil
 }
@@ -178,7 +197,7 @@ func buildBlobURL(ref Referencer, plainHTTP bool, digest string) string {
 }
 
 // processLayer handles downloading and extracting a single layer.
-func processLayer(ctx context.Context, pb *internalpb.ProgressBar, client dfdaemon.DfdaemonDownloadClient, ref Referencer, manifest ocispec.Manifest, desc ocispec.Descriptor, authToken string, cfg *config.Pull) error {
+func processLayer(ctx context.Context, pb *internalpb.ProgressBar, client dfdaemon.DfdaemonDownloadClient, ref Referencer, manifest ocispec.Manifest, desc ocispec.Descriptor, authToken string, shortener *archiver.PathShortener, cfg *config.Pull) error {
 	err := retry.Do(func() error {
 		logrus.Debugf("pull: processing layer %s", desc.Digest)
 		if cfg.Hooks.BeforePullLayer(desc, manifest) {
@@ -187,7 +206,7 @@ func processLayer(ctx context.Context, pb *internalpb.ProgressBar, client dfdaem
 			cfg.Hooks.AfterPullLayer(desc, true, nil)
 			return nil
 		}
-		err := downloadAndExtractLayer(ctx, pb, client, ref, desc, authToken, cfg)
+		err := downloadAndExtractLayer(ctx, pb, client, ref, desc, authToken, shortener, cfg)
 		cfg.Hooks.AfterPullLayer(desc, false, err) // Call after hook
 		if err != nil {
 			err = fmt.Errorf("pull: failed to download and extract layer %s: %w", desc.Digest, err)
@@ -201,7 +220,7 @@ func processLayer(ctx context.Context, pb *internalpb.ProgressBar, client dfdaem
 }
 
 // downloadAndExtractLayer downloads a layer and extracts it if necessary.
-func downloadAndExtractLayer(ctx context.Context, pb *internalpb.ProgressBar, client dfdaemon.DfdaemonDownloadClient, ref Referencer, desc ocispec.Descriptor, authToken string, cfg *config.Pull) error {
+func downloadAndExtractLayer(ctx context.Context, pb *internalpb.ProgressBar, client dfdaemon.DfdaemonDownloadClient, ref Referencer, desc ocispec.Descriptor, authToken string, shortener *archiver.PathShortener, cfg *config.Pull) error {
 	// Resolve output path.
 	extractDirAbs, err := filepath.Abs(cfg.ExtractDir)
 	if err != nil {
@@ -221,7 +240,7 @@ func downloadAndExtractLayer(ctx context.Context, pb *internalpb.ProgressBar, cl
 		return fmt.Errorf("missing annotation filepath")
 	}
 
-	outputPath := filepath.Join(extractDirAbs, annoFilepath)
+	outputPath := filepath.Join(extractDirAbs, shortener.Shorten(extractDirAbs, annoFilepath))
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -229,6 +248,18 @@ func downloadAndExtractLayer(ctx context.Context, pb *internalpb.ProgressBar, cl
 	isTar := strings.HasSuffix(desc.MediaType, mediaTypeTarSuffix)
 	if isTar {
 		outputPath += mediaTypeTarSuffix
+	} else {
+		// resolve the conflict with the existing file before downloading, the tar is resolved per entry on untar.
+		extract, err := archiver.ResolveConflictByDigest(outputPath, cfg.OnConflict, desc.Digest)
+		if err != nil {
+			return err
+		}
+
+		if !extract {
+			logrus.Infof("pull: skipping layer %s, the existing file is kept by the conflict policy", desc.Digest.String())
+			pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
+			return nil
+		}
 	}
 
 	// Download layer.
@@ -282,24 +313,30 @@ func downloadAndExtractLayer(ctx context.Context, pb *internalpb.ProgressBar, cl
 
 	// Extract tar if applicable.
 	if isTar {
-		return extractTar(outputPath, extractDirAbs)
+		return extractTar(outputPath, extractDirAbs, cfg.OnConflict, shortener, cfg.KeepTar)
 	}
 
 	return nil
 }
 
-// extractTar untars a file and removes it afterward.
-func extractTar(tarPath, extractDir string) error {
+// extractTar untars a file and removes it afterward unless keepTar is set.
+func extractTar(tarPath, extractDir string, policy config.ConflictPolicy, shortener *archiver.PathShortener, keepTar bool) error {
 	file, err := os.Open(tarPath)
 	if err != nil {
 		return fmt.Errorf("failed to open tar: %w", err)
 	}
 	defer file.Close()
 
-	if err := archiver.Untar(file, extractDir); err != nil {
+	if err := archiver.Untar(file, extractDir, archiver.WithConflictPolicy(policy), archiver.WithPathShortener(shortener)); err != nil {
 		return fmt.Errorf("failed to untar: %w", err)
 	}
 
+	if keepTar {
+		logrus.Infof("pull: kept the staged tar %s", tarPath)
+		fmt.Fprintf(os.Stderr, "Kept tar: %s\n", tarPath)
+		return nil
+	}
+
 	if err := os.Remove(tarPath); err != nil {
 		return fmt.Errorf("failed to remove tar: %w", err)
 	}
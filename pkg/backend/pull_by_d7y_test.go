@@ -0,0 +1,75 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// writeTestTar writes a tar of a single file to the path.
+func writeTestTar(t *testing.T, path, name, content string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+}
+
+func TestExtractTarKeepTar(t *testing.T) {
+	extractors := map[string]func(tarPath, extractDir string, policy config.ConflictPolicy, keepTar bool) error{
+		"pull": func(tarPath, extractDir string, policy config.ConflictPolicy, keepTar bool) error {
+			return extractTar(tarPath, extractDir, policy, nil, keepTar)
+		},
+		"fetch": extractFetchTar,
+	}
+
+	for name, extract := range extractors {
+		for _, keepTar := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s keep-tar %t", name, keepTar), func(t *testing.T) {
+				dir := t.TempDir()
+				tarPath := filepath.Join(dir, "code.tar")
+				writeTestTar(t, tarPath, "code/main.py", "print('hello')")
+
+				require.NoError(t, extract(tarPath, dir, config.ConflictOverwrite, keepTar))
+
+				content, err := os.ReadFile(filepath.Join(dir, "code", "main.py"))
+				require.NoError(t, err)
+				assert.Equal(t, "print('hello')", string(content))
+
+				_, err = os.Stat(tarPath)
+				if keepTar {
+					assert.NoError(t, err)
+				} else {
+					assert.True(t, os.IsNotExist(err))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	retry "github.com/avast/retry-go/v4"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestPullExpect(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+
+	_, manifestRaw := serveModel(t, contents, "models/llama3", "v1", []remoteFile{
+		{"model.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights")},
+	})
+
+	newConfig := func(expect string) *config.Pull {
+		cfg := config.NewPull()
+		cfg.PlainHTTP = true
+		cfg.ProgressWriter = io.Discard
+		cfg.DisableProgress = true
+		cfg.Expect = expect
+		return cfg
+	}
+
+	t.Run("mismatch", func(t *testing.T) {
+		store, blobs := newMemoryStore()
+		b := &backend{store: store}
+
+		err := b.Pull(ctx, repo+":v1", newConfig(godigest.FromString("other").String()))
+		assert.ErrorContains(t, err, "does not match the expected")
+		assert.Empty(t, blobs[repo])
+	})
+
+	t.Run("match", func(t *testing.T) {
+		store, blobs := newMemoryStore()
+		b := &backend{store: store}
+
+		require.NoError(t, b.Pull(ctx, repo+":v1", newConfig(godigest.FromBytes(manifestRaw).String())))
+		assert.Len(t, blobs[repo], 2)
+	})
+}
+
+func TestPullManifestOnly(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+
+	manifest, manifestRaw := serveModel(t, contents, "models/llama3", "v1", []remoteFile{
+		{"model.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights")},
+		{"README.md", modelspec.MediaTypeModelDocRaw, []byte("# llama3")},
+	})
+
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewPull()
+	cfg.PlainHTTP = true
+	cfg.ProgressWriter = io.Discard
+	cfg.DisableProgress = true
+	cfg.ManifestOnly = true
+	require.NoError(t, b.Pull(ctx, repo+":v1", cfg))
+
+	// only the config is stored besides the manifest annotated by the original digest.
+	assert.Len(t, blobs[repo], 1)
+	assert.Contains(t, blobs[repo], manifest.Config.Digest.String())
+	for _, layer := range manifest.Layers {
+		assert.NotContains(t, blobs[repo], layer.Digest.String())
+	}
+
+	stored, _, err := store.PullManifest(ctx, repo, "v1")
+	require.NoError(t, err)
+	var annotated ocispec.Manifest
+	require.NoError(t, json.Unmarshal(stored, &annotated))
+	assert.Equal(t, godigest.FromBytes(manifestRaw).String(), annotated.Annotations[AnnotationMetadataOnly])
+	assert.Equal(t, manifest.Layers, annotated.Layers)
+
+	err = b.Extract(ctx, repo+":v1", &config.Extract{Concurrency: 1, Output: t.TempDir()})
+	assert.ErrorContains(t, err, "--manifest-only")
+
+	t.Run("pull again with the layers", func(t *testing.T) {
+		cfg.ManifestOnly = false
+		require.NoError(t, b.Pull(ctx, repo+":v1", cfg))
+		for _, layer := range manifest.Layers {
+			assert.Contains(t, blobs[repo], layer.Digest.String())
+		}
+
+		// the original manifest replaces the annotated one.
+		stored, _, err := store.PullManifest(ctx, repo, "v1")
+		require.NoError(t, err)
+		assert.Equal(t, manifestRaw, stored)
+
+		assert.NoError(t, b.Extract(ctx, repo+":v1", &config.Extract{Concurrency: 1, Output: t.TempDir()}))
+	})
+}
+
+func TestPullExtractConcurrency(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+
+	files := []remoteFile{}
+	for i := range 16 {
+		files = append(files, remoteFile{fmt.Sprintf("shards/part%d/model-%05d.safetensors", i%2, i), modelspec.MediaTypeModelWeightRaw, []byte(fmt.Sprintf("weights %d", i))})
+	}
+	serveModel(t, contents, "models/llama3", "v1", files)
+
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	cfg := config.NewPull()
+	cfg.PlainHTTP = true
+	cfg.ProgressWriter = io.Discard
+	cfg.DisableProgress = true
+	cfg.ExtractDir = t.TempDir()
+	cfg.ExtractConcurrency = 8
+	require.NoError(t, b.Pull(ctx, repo+":v1", cfg))
+
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(cfg.ExtractDir, file.name))
+		require.NoError(t, err, file.name)
+		assert.Equal(t, file.content, data, file.name)
+	}
+}
+
+func TestPullShortenLongPaths(t *testing.T) {
+	// the long path fails the pull without retrying.
+	retryOpts := defaultRetryOpts
+	defaultRetryOpts = []retry.Option{retry.Attempts(1)}
+	t.Cleanup(func() { defaultRetryOpts = retryOpts })
+
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/long"
+
+	longPath := strings.Repeat("nested/", 10) + strings.Repeat("a", 250) + ".safetensors"
+	serveModel(t, contents, "models/long", "v1", []remoteFile{
+		{"config.json", modelspec.MediaTypeModelWeightRaw, []byte("{}")},
+		{longPath, modelspec.MediaTypeModelWeightRaw, []byte("weights")},
+	})
+
+	for _, extractFromRemote := range []bool{false, true} {
+		t.Run(fmt.Sprintf("extract from remote %t", extractFromRemote), func(t *testing.T) {
+			store, _ := newMemoryStore()
+			b := &backend{store: store}
+			cfg := config.NewPull()
+			cfg.PlainHTTP = true
+			cfg.ProgressWriter = io.Discard
+			cfg.DisableProgress = true
+			cfg.ExtractDir = t.TempDir()
+			cfg.ExtractFromRemote = extractFromRemote
+			assert.ErrorIs(t, b.Pull(ctx, repo+":v1", cfg), archiver.ErrPathTooLong)
+
+			cfg.ExtractDir = t.TempDir()
+			cfg.ShortenLongPaths = true
+			require.NoError(t, b.Pull(ctx, repo+":v1", cfg))
+			assert.FileExists(t, filepath.Join(cfg.ExtractDir, "config.json"))
+
+			data, err := os.ReadFile(filepath.Join(cfg.ExtractDir, archiver.PathMappingFilename))
+			require.NoError(t, err)
+			var mapping map[string]string
+			require.NoError(t, json.Unmarshal(data, &mapping))
+			require.Len(t, mapping, 1)
+			for shortened, original := range mapping {
+				assert.Equal(t, longPath, original)
+
+				content, err := os.ReadFile(filepath.Join(cfg.ExtractDir, shortened))
+				require.NoError(t, err)
+				assert.Equal(t, "weights", string(content))
+			}
+		})
+	}
+}
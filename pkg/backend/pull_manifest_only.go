@@ -0,0 +1,78 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+// AnnotationMetadataOnly is the annotation of the manifest pulled without the layers by
+// --manifest-only, whose value is the digest of the original manifest in the registry.
+const AnnotationMetadataOnly = "org.cncf.modctl.metadata-only"
+
+// annotateMetadataOnly annotates the manifest as metadata-only by the digest of the original
+// manifest, the other fields of the manifest are kept as is.
+func annotateMetadataOnly(body []byte, digest godigest.Digest) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the manifest %s: %w", digest, err)
+	}
+
+	annotations := map[string]string{}
+	if raw, ok := fields["annotations"]; ok {
+		if err := json.Unmarshal(raw, &annotations); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the annotations of the manifest %s: %w", digest, err)
+		}
+	}
+
+	annotations[AnnotationMetadataOnly] = digest.String()
+	raw, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the annotations of the manifest %s: %w", digest, err)
+	}
+
+	fields["annotations"] = raw
+	return json.Marshal(fields)
+}
+
+// checkMetadataOnly returns the error if the model artifact is pulled by --manifest-only and the
+// layers are still missing, the artifact pulled again with the layers replaces the annotated manifest.
+func checkMetadataOnly(ctx context.Context, store storage.Storage, repo, target string, manifest ocispec.Manifest) error {
+	if manifest.Annotations[AnnotationMetadataOnly] == "" {
+		return nil
+	}
+
+	for _, layer := range manifest.Layers {
+		exist, err := store.StatBlob(ctx, repo, layer.Digest.String())
+		if err != nil {
+			return fmt.Errorf("failed to check blob %s: %w", layer.Digest, err)
+		}
+
+		if !exist {
+			return fmt.Errorf("%s is pulled by --manifest-only without the layers, pull it again without --manifest-only to extract the files", target)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// LayerFailure is the layer failed to pull and the reason.
+type LayerFailure struct {
+	Layer ocispec.Descriptor
+	Err   error
+}
+
+// PullError is returned when the pull of the layers fails partway, it enumerates the layers
+// completed and failed so that the user can tell what is left. The completed layers are kept in
+// the local storage, the subsequent pull of the same target skips them and pulls the remaining
+// layers only.
+type PullError struct {
+	Target string
+
+	// Completed is the layers pulled or already present in the local storage.
+	Completed []ocispec.Descriptor
+
+	// Skipped is the layers skipped by the hooks.
+	Skipped []ocispec.Descriptor
+
+	// Failed is the layers failed to pull.
+	Failed []LayerFailure
+
+	// Remaining is the layers not completed or skipped, including the failed layers and the layers
+	// not attempted before the pull was aborted.
+	Remaining []ocispec.Descriptor
+
+	// Err is the error aborting the pull.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *PullError) Error() string {
+	return fmt.Sprintf("failed to pull blob to local, %d of %d layers completed and %d remaining: %s",
+		len(e.Completed), len(e.Completed)+len(e.Skipped)+len(e.Remaining), len(e.Remaining), e.Err)
+}
+
+// Unwrap returns the error aborting the pull.
+func (e *PullError) Unwrap() error {
+	return e.Err
+}
+
+// Report writes the layers completed and failed in the human-readable form.
+func (e *PullError) Report(w io.Writer) {
+	fmt.Fprintf(w, "Partially pulled %s: %d completed, %d skipped, %d failed, %d remaining\n",
+		e.Target, len(e.Completed), len(e.Skipped), len(e.Failed), len(e.Remaining))
+	for _, layer := range e.Completed {
+		fmt.Fprintf(w, "  completed  %s %s\n", layer.Digest, layerName(layer))
+	}
+
+	for _, failure := range e.Failed {
+		fmt.Fprintf(w, "  failed     %s %s: %s\n", failure.Layer.Digest, layerName(failure.Layer), failure.Err)
+	}
+
+	if len(e.Remaining) > 0 {
+		fmt.Fprintf(w, "Rerun the pull to resume, only the %d remaining layers will be pulled\n", len(e.Remaining))
+	}
+}
+
+// layerName returns the filepath of the layer for the report, or empty if it is absent.
+func layerName(layer ocispec.Descriptor) string {
+	return layer.Annotations[modelspec.AnnotationFilepath]
+}
+
+// pullReport tracks the results of the layers pulled concurrently.
+type pullReport struct {
+	mu        sync.Mutex
+	completed map[string]bool
+	skipped   map[string]bool
+	failed    map[string]error
+}
+
+// newPullReport returns the empty pull report.
+func newPullReport() *pullReport {
+	return &pullReport{
+		completed: map[string]bool{},
+		skipped:   map[string]bool{},
+		failed:    map[string]error{},
+	}
+}
+
+// complete records the layer as completed, which clears the failure of the previous attempts.
+func (r *pullReport) complete(layer ocispec.Descriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failed, layer.Digest.String())
+	r.completed[layer.Digest.String()] = true
+}
+
+// skip records the layer as skipped.
+func (r *pullReport) skip(layer ocispec.Descriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skipped[layer.Digest.String()] = true
+}
+
+// fail records the failure of the latest attempt of the layer.
+func (r *pullReport) fail(layer ocispec.Descriptor, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[layer.Digest.String()] = err
+}
+
+// error returns the PullError of the layers in the order of the manifest.
+func (r *pullReport) error(target string, layers []ocispec.Descriptor, err error) *PullError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pullErr := &PullError{Target: target, Err: err}
+	for _, layer := range layers {
+		digest := layer.Digest.String()
+		switch {
+		case r.completed[digest]:
+			pullErr.Completed = append(pullErr.Completed, layer)
+		case r.skipped[digest]:
+			pullErr.Skipped = append(pullErr.Skipped, layer)
+		default:
+			if failure, ok := r.failed[digest]; ok {
+				pullErr.Failed = append(pullErr.Failed, LayerFailure{Layer: layer, Err: failure})
+			}
+			pullErr.Remaining = append(pullErr.Remaining, layer)
+		}
+	}
+
+	return pullErr
+}
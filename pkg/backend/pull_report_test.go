@@ -0,0 +1,101 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	retry "github.com/avast/retry-go/v4"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestPullPartialReport(t *testing.T) {
+	// fail the layer at the first attempt to keep the test fast.
+	retryOpts := defaultRetryOpts
+	defaultRetryOpts = []retry.Option{retry.Attempts(1)}
+	t.Cleanup(func() { defaultRetryOpts = retryOpts })
+
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+
+	manifest, _ := serveModel(t, contents, "models/llama3", "v1", []remoteFile{
+		{"config.json", modelspec.MediaTypeModelWeightConfig, []byte(`{"hidden_size": 4096}`)},
+		{"model-00001.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights-1")},
+		{"model-00002.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights-2")},
+	})
+
+	// the second layer is missing in the registry.
+	missingKey := "models/llama3/blobs/" + manifest.Layers[1].Digest.String()
+	missing := contents[missingKey]
+	delete(contents, missingKey)
+
+	cfg := config.NewPull()
+	cfg.PlainHTTP = true
+	cfg.Concurrency = 1
+	cfg.ProgressWriter = io.Discard
+	cfg.DisableProgress = true
+
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	err := b.Pull(ctx, repo+":v1", cfg)
+	var pullErr *PullError
+	require.True(t, errors.As(err, &pullErr), err)
+	assert.Equal(t, []ocispec.Descriptor{manifest.Layers[0]}, pullErr.Completed)
+	require.Len(t, pullErr.Failed, 1)
+	assert.Equal(t, manifest.Layers[1], pullErr.Failed[0].Layer)
+	assert.Equal(t, manifest.Layers[1:], pullErr.Remaining)
+	assert.ErrorContains(t, err, "1 of 3 layers completed and 2 remaining")
+
+	var buf bytes.Buffer
+	pullErr.Report(&buf)
+	assert.Contains(t, buf.String(), "completed  "+manifest.Layers[0].Digest.String()+" config.json")
+	assert.Contains(t, buf.String(), "failed     "+manifest.Layers[1].Digest.String()+" model-00001.safetensors")
+
+	// the completed layers are kept, and the resumed pull only stores the remaining layers.
+	assert.Contains(t, blobs[repo], manifest.Layers[0].Digest.String())
+	contents[missingKey] = missing
+	require.NoError(t, b.Pull(ctx, repo+":v1", cfg))
+	assert.Equal(t, 1, pushBlobCalls(store, manifest.Layers[0].Digest))
+	assert.Equal(t, 1, pushBlobCalls(store, manifest.Layers[1].Digest))
+	assert.Equal(t, 1, pushBlobCalls(store, manifest.Layers[2].Digest))
+}
+
+// pushBlobCalls returns the number of the blobs of the digest pushed to the memory store.
+func pushBlobCalls(store *storage.Storage, digest godigest.Digest) int {
+	count := 0
+	for _, call := range store.Calls {
+		if call.Method == "PushBlob" && call.Arguments.Get(3).(ocispec.Descriptor).Digest == digest {
+			count++
+		}
+	}
+
+	return count
+}
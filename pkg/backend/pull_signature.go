@@ -0,0 +1,141 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/signature"
+)
+
+const (
+	// maxSignaturePayloadSize is the max size of the signature payload read from the registry.
+	maxSignaturePayloadSize = 1 << 20
+)
+
+// verifyPullSignature verifies the manifest of the target is signed by the key before pulling the
+// layers. The signatures are discovered by the referrers API and the sha256-<hex>.sig tag of cosign,
+// and the manifest is trusted if any of them is verified.
+func verifyPullSignature(ctx context.Context, src *remote.Repository, target string, manifestDesc ocispec.Descriptor, key crypto.PublicKey) error {
+	var manifests []ocispec.Descriptor
+	if err := src.Referrers(ctx, manifestDesc, signature.ArtifactTypeSignature, func(referrers []ocispec.Descriptor) error {
+		manifests = append(manifests, referrers...)
+		return nil
+	}); err != nil {
+		logrus.Debugf("pull: failed to list signature referrers of %s: %s", target, err)
+	}
+
+	if desc, err := src.Manifests().Resolve(ctx, signature.Tag(manifestDesc.Digest)); err == nil {
+		manifests = append(manifests, desc)
+	} else {
+		logrus.Debugf("pull: failed to resolve signature tag of %s: %s", target, err)
+	}
+
+	if len(manifests) == 0 {
+		return fmt.Errorf("failed to verify signature of %s: no signature found for manifest %s", target, manifestDesc.Digest)
+	}
+
+	var errs []error
+	for _, desc := range manifests {
+		err := verifySignatureManifest(ctx, src, desc, manifestDesc.Digest, key)
+		if err == nil {
+			logrus.Infof("pull: verified signature %s of %s", desc.Digest, target)
+			return nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return fmt.Errorf("failed to verify signature of %s: %w", target, errors.Join(errs...))
+}
+
+// verifySignatureManifest verifies the signatures in the layers of the signature manifest, which is
+// verified if any of them is verified by the key.
+func verifySignatureManifest(ctx context.Context, src *remote.Repository, desc ocispec.Descriptor, digest godigest.Digest, key crypto.PublicKey) error {
+	reader, err := src.Manifests().Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature manifest %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return fmt.Errorf("failed to decode signature manifest %s: %w", desc.Digest, err)
+	}
+
+	var errs []error
+	for _, layer := range manifest.Layers {
+		sig, ok := layer.Annotations[signature.AnnotationSignature]
+		if layer.MediaType != signature.MediaTypeSimpleSigning || !ok {
+			continue
+		}
+
+		payload, err := fetchSignaturePayload(ctx, src, layer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := signature.Verify(key, payload, sig, digest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		return nil
+	}
+
+	if len(errs) == 0 {
+		return fmt.Errorf("no signature found in signature manifest %s", desc.Digest)
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetchSignaturePayload fetches the payload of the signature and checks its digest.
+func fetchSignaturePayload(ctx context.Context, src *remote.Repository, layer ocispec.Descriptor) ([]byte, error) {
+	if layer.Size > maxSignaturePayloadSize {
+		return nil, fmt.Errorf("signature payload %s is too large: %d bytes", layer.Digest, layer.Size)
+	}
+
+	reader, err := src.Blobs().Fetch(ctx, layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signature payload %s: %w", layer.Digest, err)
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(reader, maxSignaturePayloadSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature payload %s: %w", layer.Digest, err)
+	}
+
+	if digest := checksum.FromBytesOf(layer.Digest, payload); digest != layer.Digest {
+		return nil, fmt.Errorf("signature payload digest mismatch: expected %s, got %s", layer.Digest, digest)
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,173 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/signature"
+)
+
+// serveSignature signs the manifest digest by the key as cosign and stores the signature under
+// the sha256-<hex>.sig tag into the contents of the memory registry, the payload is referenced
+// by the digest of the algorithm.
+func serveSignature(t *testing.T, contents map[string][]byte, repo string, digest godigest.Digest, key *ecdsa.PrivateKey, algorithm godigest.Algorithm) {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, repo, digest))
+	hash := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	require.NoError(t, err)
+
+	layer := ocispec.Descriptor{
+		MediaType:   signature.MediaTypeSimpleSigning,
+		Digest:      algorithm.FromBytes(payload),
+		Size:        int64(len(payload)),
+		Annotations: map[string]string{signature.AnnotationSignature: base64.StdEncoding.EncodeToString(sig)},
+	}
+	contents[repo+"/blobs/"+layer.Digest.String()] = payload
+	contents[repo+"/blobs/"+ocispec.DescriptorEmptyJSON.Digest.String()] = ocispec.DescriptorEmptyJSON.Data
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	require.NoError(t, err)
+
+	contents[repo+"/manifests/"+signature.Tag(digest)] = manifestRaw
+	contents[repo+"/manifests/"+godigest.FromBytes(manifestRaw).String()] = manifestRaw
+}
+
+// writeSignatureKey writes the PEM encoded public key of the key and returns the path.
+func writeSignatureKey(t *testing.T, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "cosign.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	return path
+}
+
+func TestPullVerifySignature(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	files := []remoteFile{{"model.safetensors", modelspec.MediaTypeModelWeightRaw, []byte("weights")}}
+	_, signedRaw := serveModel(t, contents, "models/signed", "v1", files)
+	serveSignature(t, contents, "models/signed", godigest.FromBytes(signedRaw), signer, godigest.SHA256)
+	serveModel(t, contents, "models/unsigned", "v1", files)
+
+	// the payload referenced by the sha512 digest is accepted.
+	_, sha512Raw := serveModel(t, contents, "models/sha512", "v1", files)
+	serveSignature(t, contents, "models/sha512", godigest.FromBytes(sha512Raw), signer, godigest.SHA512)
+
+	// the signature of another manifest is not accepted.
+	_, movedRaw := serveModel(t, contents, "models/moved", "v1", files[:0])
+	serveSignature(t, contents, "models/moved", godigest.FromBytes(movedRaw), signer, godigest.SHA256)
+	contents["models/moved/manifests/v1"] = signedRaw
+	contents["models/moved/manifests/"+signature.Tag(godigest.FromBytes(signedRaw))] = contents["models/moved/manifests/"+signature.Tag(godigest.FromBytes(movedRaw))]
+
+	// the index is signed instead of the manifest selected from it.
+	serveIndex := func(repo string) godigest.Digest {
+		_, manifestRaw := serveModel(t, contents, repo, "manifest", files)
+		indexRaw, err := json.Marshal(ocispec.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: []ocispec.Descriptor{{MediaType: ocispec.MediaTypeImageManifest, Digest: godigest.FromBytes(manifestRaw), Size: int64(len(manifestRaw))}},
+		})
+		require.NoError(t, err)
+
+		contents[repo+"/manifests/v1"] = indexRaw
+		contents[repo+"/manifests/"+godigest.FromBytes(indexRaw).String()] = indexRaw
+		return godigest.FromBytes(manifestRaw)
+	}
+	serveIndex("models/index")
+	serveSignature(t, contents, "models/index", godigest.FromBytes(contents["models/index/manifests/v1"]), signer, godigest.SHA256)
+	childDigest := serveIndex("models/child")
+	serveSignature(t, contents, "models/child", childDigest, signer, godigest.SHA256)
+
+	newConfig := func(key *ecdsa.PrivateKey) *config.Pull {
+		cfg := config.NewPull()
+		cfg.PlainHTTP = true
+		cfg.ProgressWriter = io.Discard
+		cfg.DisableProgress = true
+		cfg.VerifySignature = true
+		cfg.SignatureKey = writeSignatureKey(t, key)
+		return cfg
+	}
+
+	testCases := []struct {
+		name      string
+		repo      string
+		key       *ecdsa.PrivateKey
+		expectErr string
+	}{
+		{name: "valid signature", repo: "models/signed", key: signer},
+		{name: "sha512 payload digest", repo: "models/sha512", key: signer},
+		{name: "signed by another key", repo: "models/signed", key: other, expectErr: "invalid signature"},
+		{name: "no signature", repo: "models/unsigned", key: signer, expectErr: "no signature found"},
+		{name: "signature of another manifest", repo: "models/moved", key: signer, expectErr: "invalid signature"},
+		{name: "signed index", repo: "models/index", key: signer},
+		{name: "signature of the manifest selected from index", repo: "models/child", key: signer, expectErr: "no signature found"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, blobs := newMemoryStore()
+			b := &backend{store: store}
+			repo := host + "/" + tc.repo
+
+			err := b.Pull(ctx, repo+":v1", newConfig(tc.key))
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				assert.Empty(t, blobs[repo], "the pull must be aborted before pulling the layers")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, blobs[repo], 2)
+		})
+	}
+}
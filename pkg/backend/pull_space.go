@@ -0,0 +1,144 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/diskspace"
+)
+
+// checkDiskSpace checks the free space of the filesystems, which is replaced in tests to fake the free space.
+var checkDiskSpace = diskspace.Check
+
+// checkPullSpace checks the storage and the extract dir have enough free space for the model
+// artifact before pulling it, the blobs already in the storage and the files already in the
+// extract dir are not counted. The space of the extract dir is estimated by the uncompressed
+// sizes of the files in the layer annotations, see extractSpace.
+func (b *backend) checkPullSpace(ctx context.Context, repo string, manifest ocispec.Manifest, cfg *config.Pull) error {
+	if cfg.ManifestOnly {
+		return nil
+	}
+
+	var reqs []diskspace.Requirement
+	if !cfg.ExtractFromRemote && b.storageDir != "" {
+		var size int64
+		for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+			exist, err := b.store.StatBlob(ctx, repo, desc.Digest.String())
+			if err != nil {
+				return fmt.Errorf("failed to check blob %s exists: %w", desc.Digest, err)
+			}
+
+			if !exist {
+				size += desc.Size
+			}
+		}
+
+		reqs = append(reqs, diskspace.Requirement{Path: b.storageDir, Size: size})
+	}
+
+	if cfg.ExtractDir != "" {
+		reqs = append(reqs, diskspace.Requirement{Path: cfg.ExtractDir, Size: extractSpace(cfg.ExtractDir, manifest.Layers, false)})
+	}
+
+	return checkSpace(reqs...)
+}
+
+// checkExtractSpace checks the output directory has enough free space for the layers before extracting them.
+func checkExtractSpace(layers []ocispec.Descriptor, cfg *config.Extract) error {
+	return checkSpace(diskspace.Requirement{Path: cfg.Output, Size: extractSpace(cfg.Output, layers, cfg.Flatten)})
+}
+
+// checkSpace checks the free space for the requirements, the check is skipped with a warning
+// if the free space cannot be determined, such as on the unsupported platforms.
+func checkSpace(reqs ...diskspace.Requirement) error {
+	err := checkDiskSpace(reqs...)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, diskspace.ErrInsufficient):
+		return fmt.Errorf("%w, free up the space or use --skip-space-check to skip the check", err)
+	default:
+		logrus.Warnf("skip checking the free space: %s", err)
+		return nil
+	}
+}
+
+// extractSpace returns the space required to extract the layers into the directory. The size of
+// the file is taken from the file metadata of the layer, which is the uncompressed size, or the size
+// of the layer without it, and the size of the existing file to be replaced is subtracted.
+func extractSpace(dir string, layers []ocispec.Descriptor, flatten bool) int64 {
+	var size int64
+	for _, layer := range layers {
+		fileSize := layer.Size
+		metadata, ok := layerFileMetadata(layer)
+		if ok && metadata.Typeflag != 0 {
+			// the size of the directory layer is the one of the tar archive.
+			size += fileSize
+			continue
+		}
+
+		if ok {
+			fileSize = metadata.Size
+		}
+
+		if relPath := layerFilepath(layer); relPath != "" {
+			if flatten {
+				relPath = path.Base(relPath)
+			}
+
+			if info, err := os.Stat(filepath.Join(dir, relPath)); err == nil && info.Mode().IsRegular() {
+				fileSize = max(fileSize-info.Size(), 0)
+			}
+		}
+
+		size += fileSize
+	}
+
+	return size
+}
+
+// layerFileMetadata returns the file metadata in the annotations of the layer, ok is false if the
+// layer has no valid file metadata.
+func layerFileMetadata(layer ocispec.Descriptor) (metadata modelspec.FileMetadata, ok bool) {
+	raw := layer.Annotations[modelspec.AnnotationFileMetadata]
+	if raw == "" {
+		raw = layer.Annotations[legacymodelspec.AnnotationFileMetadata]
+	}
+
+	if raw == "" {
+		return metadata, false
+	}
+
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return metadata, false
+	}
+
+	return metadata, true
+}
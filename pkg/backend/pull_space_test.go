@@ -0,0 +1,208 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/diskspace"
+)
+
+// fakeDiskSpace fakes the free space of all the filesystems and records the requirements checked.
+func fakeDiskSpace(t *testing.T, available int64) *[]diskspace.Requirement {
+	t.Helper()
+	original := checkDiskSpace
+	t.Cleanup(func() { checkDiskSpace = original })
+
+	var checked []diskspace.Requirement
+	checkDiskSpace = func(reqs ...diskspace.Requirement) error {
+		checked = append(checked, reqs...)
+		for _, req := range reqs {
+			if req.Size > available {
+				return fmt.Errorf("%w on the filesystem of %s", diskspace.ErrInsufficient, req.Path)
+			}
+		}
+
+		return nil
+	}
+
+	return &checked
+}
+
+func TestCheckPullSpace(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store, storageDir: t.TempDir()}
+
+	repo := "example.com/models/llama"
+	manifestRaw := storeModel(t, b, repo, "v1", map[string][]byte{
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+		"model.safetensors": make([]byte, 100),
+	}, []string{"config.json", "model.safetensors"})
+
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+	// the new version only adds a layer missing in the storage.
+	added := make([]byte, 300)
+	manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+		MediaType: modelspec.MediaTypeModelWeightRaw,
+		Digest:    godigest.FromBytes(added),
+		Size:      int64(len(added)),
+	})
+	extractDir := t.TempDir()
+
+	checked := fakeDiskSpace(t, 1000)
+	require.NoError(t, b.checkPullSpace(ctx, repo, manifest, &config.Pull{ExtractDir: extractDir}))
+	assert.Equal(t, []diskspace.Requirement{
+		{Path: b.storageDir, Size: 300},
+		{Path: extractDir, Size: 421},
+	}, *checked)
+
+	// the blobs are not stored when extracting from remote.
+	*checked = nil
+	require.NoError(t, b.checkPullSpace(ctx, repo, manifest, &config.Pull{ExtractDir: extractDir, ExtractFromRemote: true}))
+	assert.Equal(t, []diskspace.Requirement{{Path: extractDir, Size: 421}}, *checked)
+
+	// only the manifest and the config are pulled.
+	*checked = nil
+	require.NoError(t, b.checkPullSpace(ctx, repo, manifest, &config.Pull{ManifestOnly: true}))
+	assert.Empty(t, *checked)
+
+	fakeDiskSpace(t, 400)
+	err := b.checkPullSpace(ctx, repo, manifest, &config.Pull{ExtractDir: extractDir})
+	assert.ErrorIs(t, err, diskspace.ErrInsufficient)
+	assert.Contains(t, err.Error(), "--skip-space-check")
+}
+
+func TestExtractSpace(t *testing.T) {
+	fileLayer := func(relPath string, size int64, metadata string) ocispec.Descriptor {
+		annotations := map[string]string{modelspec.AnnotationFilepath: relPath}
+		if metadata != "" {
+			annotations[modelspec.AnnotationFileMetadata] = metadata
+		}
+
+		return ocispec.Descriptor{MediaType: modelspec.MediaTypeModelWeight, Size: size, Annotations: annotations}
+	}
+
+	testCases := []struct {
+		name     string
+		existing map[string]int
+		layers   []ocispec.Descriptor
+		flatten  bool
+		expected int64
+	}{
+		{
+			name:     "layer size without file metadata",
+			layers:   []ocispec.Descriptor{fileLayer("model.safetensors", 100, "")},
+			expected: 100,
+		},
+		{
+			name:     "uncompressed size of compressed layer",
+			layers:   []ocispec.Descriptor{fileLayer("model.safetensors", 100, `{"size": 300, "typeflag": 0}`)},
+			expected: 300,
+		},
+		{
+			name:     "tar size of directory layer",
+			layers:   []ocispec.Descriptor{fileLayer("tokenizer", 100, `{"size": 0, "typeflag": 5}`)},
+			expected: 100,
+		},
+		{
+			name:     "existing files subtracted",
+			existing: map[string]int{"model.safetensors": 200, "sub/config.json": 50},
+			layers: []ocispec.Descriptor{
+				fileLayer("model.safetensors", 100, `{"size": 300, "typeflag": 0}`),
+				fileLayer("sub/config.json", 10, ""),
+			},
+			expected: 100,
+		},
+		{
+			name:     "existing flattened files subtracted",
+			existing: map[string]int{"config.json": 4},
+			layers:   []ocispec.Descriptor{fileLayer("sub/config.json", 10, "")},
+			flatten:  true,
+			expected: 6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for relPath, size := range tc.existing {
+				fullPath := filepath.Join(dir, relPath)
+				require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+				require.NoError(t, os.WriteFile(fullPath, make([]byte, size), 0644))
+			}
+
+			assert.Equal(t, tc.expected, extractSpace(dir, tc.layers, tc.flatten))
+		})
+	}
+}
+
+func TestCheckSpaceUnsupported(t *testing.T) {
+	original := checkDiskSpace
+	t.Cleanup(func() { checkDiskSpace = original })
+
+	// the check is skipped if the free space cannot be determined.
+	checkDiskSpace = func(reqs ...diskspace.Requirement) error {
+		return diskspace.ErrUnsupported
+	}
+	assert.NoError(t, checkSpace(diskspace.Requirement{Path: t.TempDir(), Size: 1 << 40}))
+
+	checkDiskSpace = func(reqs ...diskspace.Requirement) error {
+		return errors.New("permission denied")
+	}
+	assert.NoError(t, checkSpace(diskspace.Requirement{Path: t.TempDir(), Size: 1 << 40}))
+}
+
+func TestExtractInsufficientSpace(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	repo := "example.com/models/llama"
+	manifestRaw := storeModel(t, b, repo, "v1", map[string][]byte{
+		"model.safetensors": make([]byte, 100),
+	}, []string{"model.safetensors"})
+
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+	fakeDiskSpace(t, 50)
+	cfg := config.NewExtract()
+	cfg.Output = t.TempDir()
+	err := exportModelArtifact(ctx, store, manifest, repo, cfg)
+	assert.ErrorIs(t, err, diskspace.ErrInsufficient)
+	assert.NoFileExists(t, filepath.Join(cfg.Output, "model.safetensors"))
+
+	cfg.SkipSpaceCheck = true
+	require.NoError(t, exportModelArtifact(ctx, store, manifest, repo, cfg))
+	assert.FileExists(t, filepath.Join(cfg.Output, "model.safetensors"))
+}
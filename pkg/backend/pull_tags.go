@@ -0,0 +1,243 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// taggedArtifact is the tag of the model artifact and its creation time in the model config.
+type taggedArtifact struct {
+	tag       string
+	createdAt time.Time
+}
+
+// tagPullResult is the result of pulling a tag of the repository.
+type tagPullResult struct {
+	tag string
+	err error
+}
+
+// pullTags pulls the tags of the repository matching the pattern, or all the tags of the repository,
+// the newest tags are pulled first and only the latest N tags are pulled if the latest is specified.
+// The tags are pulled concurrently and the blobs shared by the tags are fetched only once, the
+// result of each tag is reported after all the tags are pulled.
+func (b *backend) pullTags(ctx context.Context, target string, cfg *config.Pull) error {
+	ref, err := ParseReference(target)
+	if err != nil {
+		return fmt.Errorf("failed to parse the target: %w", err)
+	}
+
+	if ref.Tag() != "" || ref.Digest() != "" {
+		return fmt.Errorf("the target %s must be a repository without tag or digest when pulling by the tag pattern", target)
+	}
+
+	repo := ref.Repository()
+	src, err := remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure), remote.WithProxy(cfg.Proxy))
+	if err != nil {
+		return fmt.Errorf("failed to create the remote client: %w", err)
+	}
+
+	pattern := cfg.Tags
+	if cfg.AllTags {
+		pattern = "*"
+	}
+
+	var tags []string
+	if cfg.AllTags && cfg.Latest == 0 {
+		// the order does not matter when pulling all the tags, skip fetching the creation times.
+		tags, err = listTags(ctx, src, pattern)
+	} else {
+		tags, err = resolveLatestTags(ctx, src, pattern, cfg.Latest, cfg.Concurrency, cfg.Select)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return fmt.Errorf("no tag matches the pattern %s in repository %s", pattern, repo)
+	}
+
+	logrus.Infof("pull: resolved %d tags matching %s in %s [tags: %s]", len(tags), pattern, repo, strings.Join(tags, ","))
+
+	tagCfg := *cfg
+	tagCfg.Tags = ""
+	tagCfg.AllTags = false
+	tagCfg.Latest = 0
+
+	// the progress bars of the tags pulled concurrently interleave with each other.
+	if cfg.TagConcurrency > 1 && len(tags) > 1 {
+		tagCfg.DisableProgress = true
+	}
+
+	results := make([]tagPullResult, len(tags))
+	g := &errgroup.Group{}
+	g.SetLimit(max(cfg.TagConcurrency, 1))
+	for i, tag := range tags {
+		g.Go(func() error {
+			err := b.Pull(ctx, fmt.Sprintf("%s:%s", repo, tag), &tagCfg)
+			if err != nil {
+				logrus.Errorf("pull: failed to pull tag %s of %s: %s", tag, repo, err)
+			}
+
+			results[i] = tagPullResult{tag: tag, err: err}
+			return nil
+		})
+	}
+	g.Wait()
+
+	return reportTagPullResults(cfg.ReportWriter, repo, results)
+}
+
+// reportTagPullResults reports the result of each tag, and returns the error listing the failed tags.
+func reportTagPullResults(w io.Writer, repo string, results []tagPullResult) error {
+	failed := []string{}
+	for _, result := range results {
+		if result.err != nil {
+			failed = append(failed, result.tag)
+			if w != nil {
+				fmt.Fprintf(w, "%s:%s\tfailed\t%s\n", repo, result.tag, result.err)
+			}
+			continue
+		}
+
+		if w != nil {
+			fmt.Fprintf(w, "%s:%s\tpulled\n", repo, result.tag)
+		}
+	}
+
+	logrus.Infof("pull: pulled %d of %d tags of %s", len(results)-len(failed), len(results), repo)
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to pull %d of %d tags of %s: %s", len(failed), len(results), repo, strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// listTags lists the tags of the repository matching the pattern.
+func listTags(ctx context.Context, src *remote.Repository, pattern string) ([]string, error) {
+	matched := []string{}
+	if err := src.Tags(ctx, "", func(tags []string) error {
+		for _, tag := range tags {
+			if ok, _ := path.Match(pattern, tag); ok {
+				matched = append(matched, tag)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return matched, nil
+}
+
+// resolveLatestTags lists the tags matching the pattern and sorts them by the creation time in
+// the model config from newest to oldest, the tags without the creation time are placed last.
+// All the matched tags are returned if the latest is zero.
+func resolveLatestTags(ctx context.Context, src *remote.Repository, pattern string, latest, concurrency int, selectors map[string]string) ([]string, error) {
+	matched, err := listTags(ctx, src, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Debugf("pull: matched %d tags by pattern %s [tags: %s]", len(matched), pattern, strings.Join(matched, ","))
+
+	// fetch the creation time of the tags concurrently as each of them requires two round trips.
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	artifacts := make([]taggedArtifact, len(matched))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, tag := range matched {
+		g.Go(func() error {
+			createdAt, err := fetchCreatedAt(gctx, src, tag, selectors)
+			if err != nil {
+				return fmt.Errorf("failed to get the creation time of tag %s: %w", tag, err)
+			}
+
+			artifacts[i] = taggedArtifact{tag: tag, createdAt: createdAt}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		if !artifacts[i].createdAt.Equal(artifacts[j].createdAt) {
+			return artifacts[i].createdAt.After(artifacts[j].createdAt)
+		}
+
+		return artifacts[i].tag > artifacts[j].tag
+	})
+
+	if latest > 0 && len(artifacts) > latest {
+		artifacts = artifacts[:latest]
+	}
+
+	tags := make([]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		tags = append(tags, artifact.tag)
+	}
+
+	return tags, nil
+}
+
+// fetchCreatedAt fetches the creation time of the tag from the model config, it returns the
+// zero time if the creation time is absent.
+func fetchCreatedAt(ctx context.Context, src *remote.Repository, tag string, selectors map[string]string) (time.Time, error) {
+	_, manifest, err := fetchManifest(ctx, src, tag, selectors)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	reader, err := src.Blobs().Fetch(ctx, manifest.Config)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch the config: %w", err)
+	}
+	defer reader.Close()
+
+	var model modelspec.Model
+	if err := json.NewDecoder(reader).Decode(&model); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode the config: %w", err)
+	}
+
+	if model.Descriptor.CreatedAt == nil {
+		logrus.Debugf("pull: tag %s has no creation time", tag)
+		return time.Time{}, nil
+	}
+
+	return *model.Descriptor.CreatedAt, nil
+}
@@ -0,0 +1,186 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+// newTagsRegistry serves the repository models/test with the given tags, the value of the
+// tags is the creation time in the model config, and the zero time means absent.
+func newTagsRegistry(t *testing.T, tags map[string]time.Time) *httptest.Server {
+	contents := map[string][]byte{}
+	mediaTypes := map[string]string{}
+	names := []string{}
+	for tag, createdAt := range tags {
+		model := modelspec.Model{ModelFS: modelspec.ModelFS{Type: "layers"}}
+		if !createdAt.IsZero() {
+			model.Descriptor.CreatedAt = &createdAt
+		}
+
+		weight := []byte("weight of " + tag)
+		weightDesc := ocispec.Descriptor{
+			MediaType:   modelspec.MediaTypeModelWeightRaw,
+			Digest:      godigest.FromBytes(weight),
+			Size:        int64(len(weight)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: "model.safetensors"},
+		}
+		model.ModelFS.DiffIDs = []godigest.Digest{weightDesc.Digest}
+
+		config, err := json.Marshal(model)
+		require.NoError(t, err)
+		configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(config), Size: int64(len(config))}
+
+		manifest, err := json.Marshal(ocispec.Manifest{
+			Versioned:    specs.Versioned{SchemaVersion: 2},
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: modelspec.ArtifactTypeModelManifest,
+			Config:       configDesc,
+			Layers:       []ocispec.Descriptor{weightDesc},
+		})
+		require.NoError(t, err)
+
+		contents["/blobs/"+weightDesc.Digest.String()] = weight
+		contents["/blobs/"+configDesc.Digest.String()] = config
+		contents["/manifests/"+tag] = manifest
+		contents["/manifests/"+godigest.FromBytes(manifest).String()] = manifest
+		mediaTypes["/manifests/"+tag] = ocispec.MediaTypeImageManifest
+		mediaTypes["/manifests/"+godigest.FromBytes(manifest).String()] = ocispec.MediaTypeImageManifest
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/models/test")
+		if path == "/tags/list" {
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"name": "models/test", "tags": names}))
+			return
+		}
+
+		content, ok := contents[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if mediaType, ok := mediaTypes[path]; ok {
+			w.Header().Set("Content-Type", mediaType)
+		} else {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+		w.Header().Set("Docker-Content-Digest", godigest.FromBytes(content).String())
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestPullLatestTags(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// v1.2 is a backport released after v2.0, and latest does not match the pattern.
+	server := newTagsRegistry(t, map[string]time.Time{
+		"v1.0":   day(1),
+		"v1.1":   day(2),
+		"v2.0":   day(3),
+		"v1.2":   day(4),
+		"v2.1":   day(5),
+		"latest": day(6),
+		"v0.9":   {},
+	})
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/test"
+
+	t.Run("resolve tags", func(t *testing.T) {
+		src, err := remote.New(repo, remote.WithPlainHTTP(true))
+		require.NoError(t, err)
+
+		tags, err := resolveLatestTags(context.Background(), src, "v*", 3, 2, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v2.1", "v1.2", "v2.0"}, tags)
+
+		tags, err = resolveLatestTags(context.Background(), src, "v*", 0, 2, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v2.1", "v1.2", "v2.0", "v1.1", "v1.0", "v0.9"}, tags)
+
+		tags, err = resolveLatestTags(context.Background(), src, "nightly-*", 3, 2, nil)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+
+	t.Run("pull newest tags", func(t *testing.T) {
+		mockStore := &storage.Storage{}
+		mockStore.On("StatBlob", mock.Anything, repo, mock.Anything).Return(true, nil)
+		mockStore.On("StatManifest", mock.Anything, repo, mock.Anything).Return(false, nil)
+
+		var (
+			mu     sync.Mutex
+			pulled []string
+		)
+		mockStore.On("PushManifest", mock.Anything, repo, mock.Anything, mock.Anything, mock.Anything).Return(
+			func(ctx context.Context, repo, reference, mediaType string, body []byte) (string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				pulled = append(pulled, reference)
+				return godigest.FromBytes(body).String(), nil
+			},
+		)
+		b := &backend{store: mockStore}
+
+		cfg := config.NewPull()
+		cfg.PlainHTTP = true
+		cfg.ProgressWriter = io.Discard
+		cfg.ReportWriter = io.Discard
+		cfg.Tags = "v*"
+		cfg.Latest = 3
+		cfg.TagConcurrency = 1
+		require.NoError(t, b.Pull(context.Background(), repo, cfg))
+		assert.Equal(t, []string{"v2.1", "v1.2", "v2.0"}, pulled)
+	})
+
+	t.Run("target with tag", func(t *testing.T) {
+		cfg := config.NewPull()
+		cfg.PlainHTTP = true
+		cfg.Tags = "v*"
+		err := (&backend{}).Pull(context.Background(), repo+":v1.0", cfg)
+		assert.ErrorContains(t, err, "must be a repository without tag or digest")
+	})
+}
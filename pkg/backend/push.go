@@ -22,22 +22,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	retry "github.com/avast/retry-go/v4"
-	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	internalpb "github.com/modelpack/modctl/internal/pb"
 	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/breaker"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/concurrency"
 	"github.com/modelpack/modctl/pkg/config"
 	"github.com/modelpack/modctl/pkg/iometrics"
+	"github.com/modelpack/modctl/pkg/logging"
 	"github.com/modelpack/modctl/pkg/storage"
 )
 
-// Push pushes the image to the registry.
+// Push pushes the image to the registry, the image is pushed to the destination in the config
+// if specified, which re-tags the image without tagging it in the local storage.
 func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) error {
+	start := time.Now()
 	logrus.Infof("push: pushing artifact %s", target)
 	// parse the repository and tag from the target.
 	ref, err := ParseReference(target)
@@ -47,9 +53,29 @@ func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) err
 
 	repo, tag := ref.Repository(), ref.Tag()
 
+	// parse the repository and tag of the destination, which defaults to the target.
+	destination := target
+	if cfg.Destination != "" {
+		destination = cfg.Destination
+	}
+
+	dstRef, err := ParseReference(destination)
+	if err != nil {
+		return fmt.Errorf("failed to parse the destination: %w", err)
+	}
+
+	dstRepo, dstTag := dstRef.Repository(), dstRef.Tag()
+	if cfg.Destination != "" {
+		if dstTag == "" {
+			return fmt.Errorf("the destination %s must be tagged", destination)
+		}
+
+		logrus.Infof("push: pushing artifact %s as %s", target, destination)
+	}
+
 	// create the src storage from the image storage path.
 	src := b.store
-	dst, err := remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure))
+	dst, err := remote.New(dstRepo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure), remote.WithProxy(cfg.Proxy))
 	if err != nil {
 		return fmt.Errorf("failed to create the destination: %w", err)
 	}
@@ -66,6 +92,37 @@ func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) err
 		return fmt.Errorf("failed to decode the manifest: %w", err)
 	}
 
+	if err := checkPushLimits(destination, &manifest, int64(len(manifestRaw)), cfg); err != nil {
+		return err
+	}
+
+	if cfg.VerifyRemote {
+		identical, err := checkRemoteOverwrite(ctx, dst, destination, dstTag, manifestRaw, manifest, cfg)
+		if err != nil {
+			return err
+		}
+
+		if identical {
+			logrus.Infof("push: remote %s is identical to the local artifact, skipping", destination)
+			return nil
+		}
+	}
+
+	// skip the blobs unchanged from the remote tag, which are neither checked nor mounted.
+	var changes *pushChanges
+	if cfg.OnlyChanged {
+		changes, err = resolvePushChanges(ctx, dst, destination, dstTag, manifest, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	// mount the blobs shared with the remote references instead of uploading them.
+	mounts, err := resolveSharedBlobs(ctx, dstRef, manifest, cfg)
+	if err != nil {
+		return err
+	}
+
 	// create the progress bar to track the progress of push.
 	pb := internalpb.NewProgressBar()
 	pb.Start()
@@ -83,8 +140,23 @@ func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) err
 	g, gctx := errgroup.WithContext(ctx)
 	g.SetLimit(cfg.Concurrency)
 
+	// adapt the concurrency by the throughput and the failures in the auto mode.
+	var controller *concurrency.Controller
+	if cfg.AutoConcurrency {
+		controller = concurrency.NewController("push")
+		g.SetLimit(controller.Max())
+	}
+
+	// share the retry budget across the layers to back off all of them when the remote is flapping.
+	retryBreaker := breaker.New("push", breaker.WithRetryable(remote.IsRetryable))
+
 	logrus.Infof("push: pushing %d layers for %s", len(manifest.Layers), target)
 	for _, layer := range manifest.Layers {
+		if changes.unchanged(layer.Digest) {
+			logrus.Debugf("push: skipped unchanged layer %s", layer.Digest)
+			continue
+		}
+
 		g.Go(func() error {
 			select {
 			case <-gctx.Done():
@@ -94,8 +166,16 @@ func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) err
 
 			return retry.Do(func() error {
 				logrus.Debugf("push: processing layer %s", layer.Digest)
-				if err := tracker.TrackTransfer(func() error {
-					return pushIfNotExist(gctx, pb, internalpb.NormalizePrompt("Copying blob"), src, dst, layer, repo, tag, tracker)
+				if err := retryBreaker.Do(gctx, func() error {
+					return controller.Do(gctx, layer.Size, func() error {
+						return tracker.TrackTransfer(func() error {
+							if fromRepo, ok := mounts[layer.Digest]; ok {
+								return mountIfNotExist(gctx, pb, internalpb.NormalizePrompt("Mounting blob"), src, dst, layer, repo, fromRepo)
+							}
+
+							return pushIfNotExist(gctx, pb, internalpb.NormalizePrompt("Copying blob"), src, dst, layer, repo, dstTag, tracker)
+						})
+					})
 				}); err != nil {
 					return err
 				}
@@ -111,8 +191,16 @@ func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) err
 
 	// copy the config.
 	if err := retry.Do(func() error {
+		if changes.unchanged(manifest.Config.Digest) {
+			return nil
+		}
+
 		return tracker.TrackTransfer(func() error {
-			return pushIfNotExist(ctx, pb, internalpb.NormalizePrompt("Copying config"), src, dst, manifest.Config, repo, tag, tracker)
+			if fromRepo, ok := mounts[manifest.Config.Digest]; ok {
+				return mountIfNotExist(ctx, pb, internalpb.NormalizePrompt("Mounting config"), src, dst, manifest.Config, repo, fromRepo)
+			}
+
+			return pushIfNotExist(ctx, pb, internalpb.NormalizePrompt("Copying config"), src, dst, manifest.Config, repo, dstTag, tracker)
 		})
 	}, append(defaultRetryOpts, retry.Context(ctx))...); err != nil {
 		return fmt.Errorf("failed to push config to remote: %w", err)
@@ -124,20 +212,27 @@ func (b *backend) Push(ctx context.Context, target string, cfg *config.Push) err
 			return pushIfNotExist(ctx, pb, internalpb.NormalizePrompt("Copying manifest"), src, dst, ocispec.Descriptor{
 				MediaType: manifest.MediaType,
 				Size:      int64(len(manifestRaw)),
-				Digest:    godigest.FromBytes(manifestRaw),
+				Digest:    checksum.FromBytes(manifestRaw),
 				Data:      manifestRaw,
-			}, repo, tag, tracker)
+			}, repo, dstTag, tracker)
 		})
 	}, append(defaultRetryOpts, retry.Context(ctx))...); err != nil {
 		return fmt.Errorf("failed to push manifest to remote: %w", err)
 	}
 
+	// push the Modelfile referrer along with the manifest, as the referrer replaces its annotation.
+	if err := b.pushLocalModelfileReferrer(ctx, dst, repo, checksum.FromBytes(manifestRaw)); err != nil {
+		return fmt.Errorf("failed to push modelfile referrer to remote: %w", err)
+	}
+
 	tracker.Summary()
-	logrus.Infof("push: pushed artifact %s", target)
+	changes.report(cfg, destination)
+	logging.Event("push", destination, checksum.FromBytes(manifestRaw).String(), start).Infof("push: pushed artifact %s", destination)
 	return nil
 }
 
-// pushIfNotExist copies the content from the src storage to the dst storage if the content does not exist.
+// pushIfNotExist copies the content from the src storage to the dst storage if the content does not exist,
+// the content is pulled from the repo of the src storage and the manifest is tagged by the tag in the dst storage.
 func pushIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src storage.Storage, dst *remote.Repository, desc ocispec.Descriptor, repo, tag string, tracker *iometrics.Tracker) error {
 	// check whether the content exists in the destination storage.
 	exist, err := dst.Exists(ctx, desc)
@@ -148,7 +243,7 @@ func pushIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt stri
 	if exist {
 		pb.Add(prompt, desc.Digest.String(), desc.Size, bytes.NewReader([]byte{}))
 		// if the descriptor is the manifest, should check the tag existence as well.
-		if desc.MediaType == ocispec.MediaTypeImageManifest {
+		if isManifestMediaType(desc.MediaType) {
 			_, _, err := dst.FetchReference(ctx, tag)
 			if err != nil {
 				// try to push the tag if error occurred when fetch reference.
@@ -166,12 +261,12 @@ func pushIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt stri
 
 	// push the content to the destination, and wrap the content reader for progress bar,
 	// manifest should use dst.Manifests().Push, others should use dst.Blobs().Push.
-	if desc.MediaType == ocispec.MediaTypeImageManifest {
+	if isManifestMediaType(desc.MediaType) {
 		reader := pb.Add(prompt, desc.Digest.String(), desc.Size, tracker.WrapReader(bytes.NewReader(desc.Data)))
 		if err := dst.Manifests().Push(ctx, desc, reader); err != nil {
-			err = fmt.Errorf("failed to push manifest %s, err: %w", desc.Digest.String(), err)
+			err = fmt.Errorf("failed to push manifest %s, err: %w", desc.Digest.String(), remote.WrapUnsupportedMediaType(err))
 			pb.Abort(desc.Digest.String(), err)
-			return err
+			return remote.UnrecoverableOnUnsupportedMediaType(err)
 		}
 
 		// push tag
@@ -193,9 +288,9 @@ func pushIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt stri
 		// always return the error when Close() is called.
 		// refer: https://github.com/distribution/distribution/blob/63d3892315c817c931b88779399a8e9142899a8e/registry/storage/filereader.go#L105
 		if err := dst.Blobs().Push(ctx, desc, io.NopCloser(reader)); err != nil {
-			err = fmt.Errorf("failed to push blob %s, err: %w", desc.Digest.String(), err)
+			err = fmt.Errorf("failed to push blob %s, err: %w", desc.Digest.String(), remote.WrapUnsupportedMediaType(err))
 			pb.Abort(desc.Digest.String(), err)
-			return err
+			return remote.UnrecoverableOnUnsupportedMediaType(err)
 		}
 	}
 
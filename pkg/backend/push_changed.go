@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	humanize "github.com/dustin/go-humanize"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// pushChanges is the difference of the artifact from the one tagged in the destination.
+type pushChanges struct {
+	// remoteBlobs is the digests of the blobs referenced by the remote tag, which are skipped.
+	remoteBlobs map[godigest.Digest]bool
+	// uploaded and reused are the number of the changed and unchanged blobs.
+	uploaded, reused int
+	// uploadedSize and reusedSize are the total size of the changed and unchanged blobs.
+	uploadedSize, reusedSize int64
+}
+
+// resolvePushChanges diffs the artifact against the manifest tagged in the destination, the
+// differences of the files are reported and the blobs referenced by the remote manifest are
+// returned as unchanged, which already exist in the remote and are neither checked nor mounted.
+func resolvePushChanges(ctx context.Context, dst *remote.Repository, destination, tag string, manifest ocispec.Manifest, cfg *config.Push) (*pushChanges, error) {
+	changes := &pushChanges{remoteBlobs: map[godigest.Digest]bool{}}
+	remoteManifest, _, err := fetchRemoteManifest(ctx, dst, destination, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if remoteManifest != nil {
+		for _, desc := range append([]ocispec.Descriptor{remoteManifest.Config}, remoteManifest.Layers...) {
+			changes.remoteBlobs[desc.Digest] = true
+		}
+
+		if cfg.ReportWriter != nil {
+			fmt.Fprintf(cfg.ReportWriter, "Changes from %s:\n", destination)
+			for _, diff := range diffManifests(manifest, *remoteManifest) {
+				fmt.Fprintf(cfg.ReportWriter, "  %s\n", diff)
+			}
+		}
+	}
+
+	for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+		if changes.remoteBlobs[desc.Digest] {
+			changes.reused++
+			changes.reusedSize += desc.Size
+		} else {
+			changes.uploaded++
+			changes.uploadedSize += desc.Size
+		}
+	}
+
+	logrus.Infof("push: %d blobs changed from %s [size: %d], %d blobs unchanged [size: %d]", changes.uploaded, destination, changes.uploadedSize, changes.reused, changes.reusedSize)
+	return changes, nil
+}
+
+// unchanged returns true if the blob is referenced by the remote tag, it's nil safe.
+func (c *pushChanges) unchanged(digest godigest.Digest) bool {
+	return c != nil && c.remoteBlobs[digest]
+}
+
+// report reports the summary of the uploaded and reused blobs, it's nil safe.
+func (c *pushChanges) report(cfg *config.Push, destination string) {
+	if c == nil || cfg.ReportWriter == nil {
+		return
+	}
+
+	fmt.Fprintf(cfg.ReportWriter, "Uploaded %d blobs (%s), reused %d blobs (%s) from %s\n", c.uploaded, humanize.Bytes(uint64(c.uploadedSize)), c.reused, humanize.Bytes(uint64(c.reusedSize)), destination)
+}
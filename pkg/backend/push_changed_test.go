@@ -0,0 +1,89 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestPushOnlyChanged(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	weight, readme, adapter := []byte("base weight"), []byte("# llama3"), []byte("adapter weight")
+	storeModel(t, b, "example.com/models/llama3", "v1", map[string][]byte{
+		"model.safetensors": weight,
+		"README.md":         readme,
+	}, []string{"model.safetensors", "README.md"})
+	v2ManifestRaw := storeModel(t, b, "example.com/models/llama3", "v2", map[string][]byte{
+		"model.safetensors":   weight,
+		"README.md":           readme,
+		"adapter.safetensors": adapter,
+	}, []string{"model.safetensors", "README.md", "adapter.safetensors"})
+
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	destination := host + "/models/llama3:latest"
+
+	cfg := config.NewPush()
+	cfg.PlainHTTP = true
+	cfg.Destination = destination
+	require.NoError(t, b.Push(ctx, "example.com/models/llama3:v1", cfg))
+
+	// drop the unchanged blobs from the registry, which would be uploaded again if they were
+	// checked, to prove they are skipped.
+	weightKey := "models/llama3/blobs/" + godigest.FromBytes(weight).String()
+	readmeKey := "models/llama3/blobs/" + godigest.FromBytes(readme).String()
+	delete(contents, weightKey)
+	delete(contents, readmeKey)
+
+	var report bytes.Buffer
+	cfg = config.NewPush()
+	cfg.PlainHTTP = true
+	cfg.Destination = destination
+	cfg.OnlyChanged = true
+	cfg.ReportWriter = &report
+	require.NoError(t, b.Push(ctx, "example.com/models/llama3:v2", cfg))
+
+	// only the new layer and the changed config are uploaded.
+	assert.Equal(t, adapter, contents["models/llama3/blobs/"+godigest.FromBytes(adapter).String()])
+	assert.NotContains(t, contents, weightKey)
+	assert.NotContains(t, contents, readmeKey)
+	assert.Equal(t, v2ManifestRaw, contents["models/llama3/manifests/latest"])
+
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "Changes from "+destination+":", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "  config "), lines[1])
+	assert.Equal(t, "  added adapter.safetensors", lines[2])
+	assert.Equal(t, "Uploaded 2 blobs (70 B), reused 2 blobs (19 B) from "+destination, lines[3])
+
+	// the report is skipped without the writer.
+	cfg.ReportWriter = nil
+	require.NoError(t, b.Push(ctx, "example.com/models/llama3:v2", cfg))
+	assert.Equal(t, v2ManifestRaw, contents["models/llama3/manifests/latest"])
+}
@@ -0,0 +1,255 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// newMemoryRegistry serves a writable registry which stores the blobs and manifests in memory,
+// the stored contents are keyed by the repository and the digest or tag.
+func newMemoryRegistry(t *testing.T) (*httptest.Server, map[string][]byte) {
+	var mu sync.Mutex
+	contents := map[string][]byte{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := strings.TrimPrefix(r.URL.Path, "/v2/")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(path, "/blobs/uploads/") && r.URL.Query().Get("mount") != "":
+			// the mounted blobs are recorded by the repository they are mounted from.
+			repo := strings.TrimSuffix(path, "/blobs/uploads/")
+			digest, from := r.URL.Query().Get("mount"), r.URL.Query().Get("from")
+			content, ok := contents[from+"/blobs/"+digest]
+			if !ok {
+				w.Header().Set("Location", r.URL.Path+"session")
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+
+			contents[repo+"/blobs/"+digest] = content
+			contents[repo+"/mounts/"+digest] = []byte(from)
+			w.Header().Set("Docker-Content-Digest", digest)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost && strings.HasSuffix(path, "/blobs/uploads/"):
+			w.Header().Set("Location", r.URL.Path+"session")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && strings.Contains(path, "/blobs/uploads/"):
+			content, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			repo := path[:strings.Index(path, "/blobs/uploads/")]
+			contents[repo+"/blobs/"+r.URL.Query().Get("digest")] = content
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.Contains(path, "/manifests/"):
+			content, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			repo, reference, _ := strings.Cut(path, "/manifests/")
+			contents[repo+"/manifests/"+reference] = content
+			contents[repo+"/manifests/"+godigest.FromBytes(content).String()] = content
+			var manifest ocispec.Manifest
+			if err := json.Unmarshal(content, &manifest); err == nil && manifest.Subject != nil {
+				w.Header().Set("OCI-Subject", manifest.Subject.Digest.String())
+			}
+			w.Header().Set("Docker-Content-Digest", godigest.FromBytes(content).String())
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && strings.Contains(path, "/referrers/"):
+			repo, subject, _ := strings.Cut(path, "/referrers/")
+			artifactType := r.URL.Query().Get("artifactType")
+			index := ocispec.Index{Versioned: specs.Versioned{SchemaVersion: 2}, MediaType: ocispec.MediaTypeImageIndex, Manifests: []ocispec.Descriptor{}}
+			for key, content := range contents {
+				digest, ok := strings.CutPrefix(key, repo+"/manifests/")
+				if !ok || digest != godigest.FromBytes(content).String() {
+					continue
+				}
+
+				var manifest ocispec.Manifest
+				if err := json.Unmarshal(content, &manifest); err != nil || manifest.Subject == nil || manifest.Subject.Digest.String() != subject {
+					continue
+				}
+				if artifactType != "" && manifest.ArtifactType != artifactType {
+					continue
+				}
+
+				index.Manifests = append(index.Manifests, ocispec.Descriptor{
+					MediaType:    manifest.MediaType,
+					ArtifactType: manifest.ArtifactType,
+					Digest:       godigest.FromBytes(content),
+					Size:         int64(len(content)),
+				})
+			}
+
+			content, err := json.Marshal(index)
+			require.NoError(t, err)
+			if artifactType != "" {
+				w.Header().Set("OCI-Filters-Applied", "artifactType")
+			}
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+			w.Write(content)
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/tags/list"):
+			repo := strings.TrimSuffix(path, "/tags/list")
+			tags := []string{}
+			for key := range contents {
+				if tag, ok := strings.CutPrefix(key, repo+"/manifests/"); ok && !strings.Contains(tag, ":") {
+					tags = append(tags, tag)
+				}
+			}
+			sort.Strings(tags)
+
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"name": repo, "tags": tags}))
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			content, ok := contents[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if strings.Contains(path, "/manifests/") {
+				// the manifests are served by their media type, which defaults to the image manifest.
+				mediaType := ocispec.MediaTypeImageManifest
+				var versioned struct {
+					MediaType string `json:"mediaType"`
+				}
+				if err := json.Unmarshal(content, &versioned); err == nil && versioned.MediaType != "" {
+					mediaType = versioned.MediaType
+				}
+				w.Header().Set("Content-Type", mediaType)
+			} else {
+				w.Header().Set("Content-Type", "application/octet-stream")
+			}
+			// the content requested by digest is answered with the digest of the same algorithm.
+			digest := godigest.FromBytes(content)
+			if requested, err := godigest.Parse(path[strings.LastIndex(path, "/")+1:]); err == nil {
+				digest = requested.Algorithm().FromBytes(content)
+			}
+			w.Header().Set("Docker-Content-Digest", digest.String())
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			if r.Method == http.MethodGet {
+				w.Write(content)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, contents
+}
+
+// remoteFile is the file of the model artifact served by the memory registry.
+type remoteFile struct {
+	name      string
+	mediaType string
+	content   []byte
+}
+
+// serveModel stores the model artifact of the files into the contents of the memory registry.
+func serveModel(t *testing.T, contents map[string][]byte, repo, tag string, files []remoteFile) (ocispec.Manifest, []byte) {
+	layers := []ocispec.Descriptor{}
+	for _, file := range files {
+		desc := ocispec.Descriptor{
+			MediaType:   file.mediaType,
+			Digest:      godigest.FromBytes(file.content),
+			Size:        int64(len(file.content)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: file.name},
+		}
+		contents[repo+"/blobs/"+desc.Digest.String()] = file.content
+		layers = append(layers, desc)
+	}
+
+	configRaw := []byte(fmt.Sprintf(`{"descriptor":{"name":%q},"modelfs":{"type":"layers"}}`, repo))
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configRaw), Size: int64(len(configRaw))}
+	contents[repo+"/blobs/"+configDesc.Digest.String()] = configRaw
+
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: modelspec.ArtifactTypeModelManifest,
+		Config:       configDesc,
+		Layers:       layers,
+	}
+	manifestRaw, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	contents[repo+"/manifests/"+tag] = manifestRaw
+	contents[repo+"/manifests/"+godigest.FromBytes(manifestRaw).String()] = manifestRaw
+	return manifest, manifestRaw
+}
+
+func TestPushDestination(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	files := map[string][]byte{
+		"model.safetensors": []byte("weight"),
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+	}
+	manifestRaw := storeModel(t, b, "example.com/models/llama3", "rc", files, []string{"model.safetensors", "config.json"})
+
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	cfg := config.NewPush()
+	cfg.PlainHTTP = true
+	cfg.Destination = host + "/remote/llama3:stable"
+	require.NoError(t, b.Push(ctx, "example.com/models/llama3:rc", cfg))
+
+	// the manifest is tagged by the destination tag, and the blobs are pushed to the destination repository.
+	assert.Equal(t, manifestRaw, contents["remote/llama3/manifests/stable"])
+	assert.NotContains(t, contents, "remote/llama3/manifests/rc")
+	for name, content := range files {
+		assert.Equal(t, content, contents["remote/llama3/blobs/"+godigest.FromBytes(content).String()], name)
+	}
+
+	// the local storage is not re-tagged.
+	_, _, err := store.PullManifest(ctx, "example.com/models/llama3", "stable")
+	assert.Error(t, err)
+
+	t.Run("push again", func(t *testing.T) {
+		cfg.Destination = host + "/remote/llama3:v1"
+		require.NoError(t, b.Push(ctx, "example.com/models/llama3:rc", cfg))
+		assert.Equal(t, manifestRaw, contents["remote/llama3/manifests/v1"])
+	})
+
+	t.Run("untagged destination", func(t *testing.T) {
+		cfg.Destination = host + "/remote/llama3"
+		err := b.Push(ctx, "example.com/models/llama3:rc", cfg)
+		assert.ErrorContains(t, err, "must be tagged")
+	})
+}
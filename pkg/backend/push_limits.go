@@ -0,0 +1,80 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// pushLimitsHint is the suggestion for the artifact exceeding the limits of the registry.
+const pushLimitsHint = "consider splitting the model artifact into multiple artifacts and grouping them with an index"
+
+// checkPushLimits checks the artifact against the limits of the registry before pushing, the
+// violations are warned, or returned as the error in the strict mode. The limits are configured
+// rather than queried, as the registries have no API to advertise them.
+func checkPushLimits(target string, manifest *ocispec.Manifest, manifestSize int64, cfg *config.Push) error {
+	violations := pushLimitViolations(manifest, manifestSize, cfg)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if cfg.StrictLimits {
+		return fmt.Errorf("artifact %s exceeds the registry limits: %s, %s", target, strings.Join(violations, "; "), pushLimitsHint)
+	}
+
+	for _, violation := range violations {
+		logrus.Warnf("push: artifact %s %s", target, violation)
+		if cfg.WarningWriter != nil {
+			fmt.Fprintf(cfg.WarningWriter, "Warning: %s\n", violation)
+		}
+	}
+
+	if cfg.WarningWriter != nil {
+		fmt.Fprintf(cfg.WarningWriter, "Warning: the registry may reject the artifact, %s\n", pushLimitsHint)
+	}
+
+	return nil
+}
+
+// pushLimitViolations returns the violations of the limits, the zero limit is not checked.
+func pushLimitViolations(manifest *ocispec.Manifest, manifestSize int64, cfg *config.Push) []string {
+	violations := []string{}
+	if cfg.MaxLayers > 0 && len(manifest.Layers) > cfg.MaxLayers {
+		violations = append(violations, fmt.Sprintf("the artifact has %d layers, exceeding the limit of %d layers", len(manifest.Layers), cfg.MaxLayers))
+	}
+
+	if cfg.MaxManifestSize > 0 && manifestSize > cfg.MaxManifestSize {
+		violations = append(violations, fmt.Sprintf("the manifest is %s, exceeding the limit of %s", humanize.IBytes(uint64(manifestSize)), humanize.IBytes(uint64(cfg.MaxManifestSize))))
+	}
+
+	if cfg.MaxBlobSize > 0 {
+		for _, layer := range manifest.Layers {
+			if layer.Size > cfg.MaxBlobSize {
+				violations = append(violations, fmt.Sprintf("the layer %s (%s) is %s, exceeding the blob limit of %s", layer.Digest, layerFilepath(layer), humanize.IBytes(uint64(layer.Size)), humanize.IBytes(uint64(cfg.MaxBlobSize))))
+			}
+		}
+	}
+
+	return violations
+}
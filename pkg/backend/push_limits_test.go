@@ -0,0 +1,150 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func newLayersManifest(count int, size int64) *ocispec.Manifest {
+	manifest := &ocispec.Manifest{}
+	for i := range count {
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+			MediaType:   modelspec.MediaTypeModelWeightRaw,
+			Digest:      godigest.FromString(fmt.Sprintf("layer-%d", i)),
+			Size:        size,
+			Annotations: map[string]string{modelspec.AnnotationFilepath: fmt.Sprintf("model-%05d.safetensors", i)},
+		})
+	}
+
+	return manifest
+}
+
+func TestCheckPushLimits(t *testing.T) {
+	testCases := []struct {
+		name         string
+		layers       int
+		layerSize    int64
+		manifestSize int64
+		setup        func(cfg *config.Push)
+		expected     []string
+	}{
+		{
+			name:         "within the limits",
+			layers:       10,
+			layerSize:    1 << 20,
+			manifestSize: 4 << 10,
+			expected:     []string{},
+		},
+		{
+			name:         "above the configured layer count",
+			layers:       11,
+			layerSize:    1 << 20,
+			manifestSize: 4 << 10,
+			setup:        func(cfg *config.Push) { cfg.MaxLayers = 10 },
+			expected:     []string{"the artifact has 11 layers, exceeding the limit of 10 layers"},
+		},
+		{
+			name:         "above the default layer count",
+			layers:       1001,
+			layerSize:    1,
+			manifestSize: 4 << 10,
+			expected:     []string{"the artifact has 1001 layers, exceeding the limit of 1000 layers"},
+		},
+		{
+			name:         "disabled layer count",
+			layers:       1001,
+			layerSize:    1,
+			manifestSize: 4 << 10,
+			setup:        func(cfg *config.Push) { cfg.MaxLayers = 0 },
+			expected:     []string{},
+		},
+		{
+			name:         "large manifest and blob",
+			layers:       1,
+			layerSize:    2 << 30,
+			manifestSize: 5 << 20,
+			setup:        func(cfg *config.Push) { cfg.MaxBlobSize = 1 << 30 },
+			expected: []string{
+				"the manifest is 5.0 MiB, exceeding the limit of 4.0 MiB",
+				"the layer " + godigest.FromString("layer-0").String() + " (model-00000.safetensors) is 2.0 GiB, exceeding the blob limit of 1.0 GiB",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings bytes.Buffer
+			cfg := config.NewPush()
+			cfg.WarningWriter = &warnings
+			if tc.setup != nil {
+				tc.setup(cfg)
+			}
+
+			manifest := newLayersManifest(tc.layers, tc.layerSize)
+			assert.Equal(t, tc.expected, pushLimitViolations(manifest, tc.manifestSize, cfg))
+
+			require.NoError(t, checkPushLimits("example.com/repo:v1", manifest, tc.manifestSize, cfg))
+			for _, violation := range tc.expected {
+				assert.Contains(t, warnings.String(), "Warning: "+violation)
+			}
+
+			if len(tc.expected) == 0 {
+				assert.Empty(t, warnings.String())
+			} else {
+				assert.Contains(t, warnings.String(), pushLimitsHint)
+			}
+
+			cfg.StrictLimits = true
+			err := checkPushLimits("example.com/repo:v1", manifest, tc.manifestSize, cfg)
+			if len(tc.expected) == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "exceeds the registry limits")
+			}
+		})
+	}
+}
+
+func TestPushStrictLimits(t *testing.T) {
+	ctx := context.Background()
+	manifestRaw, err := json.Marshal(newLayersManifest(3, 1))
+	require.NoError(t, err)
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullManifest", ctx, "example.com/repo", "v1").Return(manifestRaw, "sha256:manifest", nil)
+	b := &backend{store: mockStore}
+
+	cfg := config.NewPush()
+	cfg.MaxLayers = 2
+	cfg.StrictLimits = true
+	err = b.Push(ctx, "example.com/repo:v1", cfg)
+	assert.ErrorContains(t, err, "the artifact has 3 layers, exceeding the limit of 2 layers")
+}
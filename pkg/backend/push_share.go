@@ -0,0 +1,136 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/storage"
+)
+
+// resolveSharedBlobs fetches the manifests of the remote references sharing the content with the
+// artifact and reports the blobs shared with each of them, it returns the repositories to mount the
+// shared blobs from by digest, including the destination repository itself whose existing blobs are
+// skipped by the mount. The blobs shared with another registry can not be mounted, so they are not returned.
+func resolveSharedBlobs(ctx context.Context, dstRef Referencer, manifest ocispec.Manifest, cfg *config.Push) (map[godigest.Digest]string, error) {
+	blobs := append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...)
+	mounts := make(map[godigest.Digest]string)
+	for _, shareFrom := range cfg.ShareFrom {
+		ref, err := ParseReference(shareFrom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the shared reference %s: %w", shareFrom, err)
+		}
+
+		reference := ref.Tag()
+		if ref.Digest() != "" {
+			reference = ref.Digest()
+		}
+
+		if reference == "" {
+			return nil, fmt.Errorf("the shared reference %s must be tagged or digested", shareFrom)
+		}
+
+		repo, err := remote.New(ref.Repository(), remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure), remote.WithProxy(cfg.Proxy))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the repository of %s: %w", shareFrom, err)
+		}
+
+		_, reader, err := repo.Manifests().FetchReference(ctx, reference)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the manifest of %s: %w", shareFrom, err)
+		}
+
+		manifestRaw, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifest of %s: %w", shareFrom, err)
+		}
+
+		var sharedManifest ocispec.Manifest
+		if err := json.Unmarshal(manifestRaw, &sharedManifest); err != nil {
+			return nil, fmt.Errorf("failed to decode the manifest of %s: %w", shareFrom, err)
+		}
+
+		sharedDigests := make(map[godigest.Digest]bool)
+		for _, desc := range append([]ocispec.Descriptor{sharedManifest.Config}, sharedManifest.Layers...) {
+			sharedDigests[desc.Digest] = true
+		}
+
+		var (
+			count int
+			size  int64
+		)
+		for _, desc := range blobs {
+			if !sharedDigests[desc.Digest] {
+				continue
+			}
+
+			count++
+			size += desc.Size
+			if _, ok := mounts[desc.Digest]; !ok && ref.Domain() == dstRef.Domain() {
+				mounts[desc.Digest] = strings.TrimPrefix(ref.Repository(), ref.Domain()+"/")
+			}
+		}
+
+		logrus.Infof("push: %d of %d blobs are shared with %s [size: %d]", count, len(blobs), shareFrom, size)
+		if cfg.ReportWriter != nil {
+			fmt.Fprintf(cfg.ReportWriter, "Shared %d of %d blobs (%s) with %s\n", count, len(blobs), humanize.Bytes(uint64(size)), shareFrom)
+		}
+	}
+
+	return mounts, nil
+}
+
+// mountIfNotExist mounts the blob from the repository of the same registry instead of uploading it,
+// the registry not supporting the mount falls back to uploading the content from the src storage.
+func mountIfNotExist(ctx context.Context, pb *internalpb.ProgressBar, prompt string, src storage.Storage, dst *remote.Repository, desc ocispec.Descriptor, repo, fromRepo string) error {
+	exist, err := dst.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+
+	pb.Add(prompt, desc.Digest.String(), desc.Size, bytes.NewReader([]byte{}))
+	if exist {
+		pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Skipped blob"), desc.Digest.String()))
+		return nil
+	}
+
+	if err := dst.Mount(ctx, desc, fromRepo, func() (io.ReadCloser, error) {
+		return src.PullBlob(ctx, repo, desc.Digest.String())
+	}); err != nil {
+		err = fmt.Errorf("failed to mount blob %s from %s, err: %w", desc.Digest.String(), fromRepo, err)
+		pb.Abort(desc.Digest.String(), err)
+		return err
+	}
+
+	logrus.Debugf("push: mounted blob %s from %s", desc.Digest, fromRepo)
+	pb.Complete(desc.Digest.String(), fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Mounted blob"), desc.Digest.String()))
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestPushShareFrom(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	readme := []byte("# llama3")
+	chatWeight := []byte("chat weight")
+	storeModel(t, b, "example.com/models/llama3", "base", map[string][]byte{
+		"model.safetensors": []byte("base weight"),
+		"README.md":         readme,
+	}, []string{"model.safetensors", "README.md"})
+	storeModel(t, b, "example.com/models/llama3", "chat", map[string][]byte{
+		"model.safetensors": chatWeight,
+		"README.md":         readme,
+	}, []string{"model.safetensors", "README.md"})
+
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	cfg := config.NewPush()
+	cfg.PlainHTTP = true
+	cfg.Destination = host + "/models/llama3:base"
+	require.NoError(t, b.Push(ctx, "example.com/models/llama3:base", cfg))
+
+	readmeDigest := godigest.FromBytes(readme).String()
+	t.Run("mount from sibling repository", func(t *testing.T) {
+		var report bytes.Buffer
+		cfg := config.NewPush()
+		cfg.PlainHTTP = true
+		cfg.Destination = host + "/models/llama3-chat:v1"
+		cfg.ShareFrom = []string{host + "/models/llama3:base"}
+		cfg.ReportWriter = &report
+		require.NoError(t, b.Push(ctx, "example.com/models/llama3:chat", cfg))
+
+		// the shared doc is mounted from the sibling, and the weight is uploaded.
+		assert.Equal(t, "models/llama3", string(contents["models/llama3-chat/mounts/"+readmeDigest]))
+		assert.Equal(t, readme, contents["models/llama3-chat/blobs/"+readmeDigest])
+		assert.NotContains(t, contents, "models/llama3-chat/mounts/"+godigest.FromBytes(chatWeight).String())
+		assert.Equal(t, chatWeight, contents["models/llama3-chat/blobs/"+godigest.FromBytes(chatWeight).String()])
+		assert.Equal(t, "Shared 1 of 3 blobs (8 B) with "+host+"/models/llama3:base\n", report.String())
+	})
+
+	t.Run("sibling tag", func(t *testing.T) {
+		var report bytes.Buffer
+		cfg := config.NewPush()
+		cfg.PlainHTTP = true
+		cfg.Destination = host + "/models/llama3:chat"
+		cfg.ShareFrom = []string{host + "/models/llama3:base"}
+		cfg.ReportWriter = &report
+		require.NoError(t, b.Push(ctx, "example.com/models/llama3:chat", cfg))
+
+		// the shared doc is mounted within the repository, which skips the existing blob.
+		assert.NotContains(t, contents, "models/llama3/mounts/"+readmeDigest)
+		assert.Contains(t, contents, "models/llama3/manifests/chat")
+		assert.Equal(t, "Shared 1 of 3 blobs (8 B) with "+host+"/models/llama3:base\n", report.String())
+	})
+
+	t.Run("without report writer", func(t *testing.T) {
+		cfg := config.NewPush()
+		cfg.PlainHTTP = true
+		cfg.Destination = host + "/models/llama3-chat:v3"
+		cfg.ShareFrom = []string{host + "/models/llama3:base"}
+		cfg.ReportWriter = nil
+		require.NoError(t, b.Push(ctx, "example.com/models/llama3:chat", cfg))
+	})
+
+	t.Run("untagged reference", func(t *testing.T) {
+		cfg := config.NewPush()
+		cfg.PlainHTTP = true
+		cfg.Destination = host + "/models/llama3-chat:v2"
+		cfg.ShareFrom = []string{host + "/models/llama3"}
+		err := b.Push(ctx, "example.com/models/llama3:chat", cfg)
+		assert.ErrorContains(t, err, "must be tagged or digested")
+	})
+}
@@ -0,0 +1,138 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/errdef"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// checkRemoteOverwrite compares the local manifest with the one tagged in the remote before the
+// push, which prevents overwriting the differing remote artifact accidentally. It returns true
+// if the remote is identical to the local so the push can be skipped, and the error listing the
+// differences if the remote differs unless the force flag is set.
+func checkRemoteOverwrite(ctx context.Context, dst *remote.Repository, destination, tag string, manifestRaw []byte, manifest ocispec.Manifest, cfg *config.Push) (bool, error) {
+	remoteManifest, remoteDigest, err := fetchRemoteManifest(ctx, dst, destination, tag)
+	if err != nil || remoteManifest == nil {
+		return false, err
+	}
+
+	if checksum.Matches(remoteDigest, manifestRaw) {
+		return true, nil
+	}
+
+	diffs := diffManifests(manifest, *remoteManifest)
+	if cfg.Force {
+		logrus.Warnf("push: overwriting the differing remote %s by force [%s]", destination, strings.Join(diffs, ", "))
+		return false, nil
+	}
+
+	return false, fmt.Errorf("remote %s differs from the local artifact (%s), use --force to overwrite it", destination, strings.Join(diffs, ", "))
+}
+
+// fetchRemoteManifest fetches the manifest tagged by the tag in the destination and its digest,
+// it returns nil if the remote tag does not exist.
+func fetchRemoteManifest(ctx context.Context, dst *remote.Repository, destination, tag string) (*ocispec.Manifest, godigest.Digest, error) {
+	desc, reader, err := dst.Manifests().FetchReference(ctx, tag)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			logrus.Infof("push: remote %s does not exist", destination)
+			return nil, "", nil
+		}
+
+		return nil, "", fmt.Errorf("failed to fetch the remote manifest of %s: %w", destination, err)
+	}
+	defer reader.Close()
+
+	remoteRaw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read the remote manifest of %s: %w", destination, err)
+	}
+
+	var remoteManifest ocispec.Manifest
+	if err := json.Unmarshal(remoteRaw, &remoteManifest); err != nil {
+		return nil, "", fmt.Errorf("failed to decode the remote manifest of %s: %w", destination, err)
+	}
+
+	return &remoteManifest, desc.Digest, nil
+}
+
+// diffManifests describes the differences of the local manifest from the remote one, which are
+// the changed config and the files added, removed or changed by the file path.
+func diffManifests(local, remote ocispec.Manifest) []string {
+	diffs := []string{}
+	if local.Config.Digest != remote.Config.Digest {
+		diffs = append(diffs, fmt.Sprintf("config %s -> %s", remote.Config.Digest, local.Config.Digest))
+	}
+
+	files := []string{}
+	localLayers, remoteLayers := layersByFilepath(local.Layers), layersByFilepath(remote.Layers)
+	for name, layer := range localLayers {
+		remoteLayer, ok := remoteLayers[name]
+		if !ok {
+			files = append(files, "added "+name)
+		} else if remoteLayer.Digest != layer.Digest {
+			files = append(files, "changed "+name)
+		}
+	}
+
+	for name := range remoteLayers {
+		if _, ok := localLayers[name]; !ok {
+			files = append(files, "removed "+name)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return strings.SplitN(files[i], " ", 2)[1] < strings.SplitN(files[j], " ", 2)[1]
+	})
+
+	diffs = append(diffs, files...)
+	if len(diffs) == 0 {
+		diffs = append(diffs, "manifest annotations or layer order")
+	}
+
+	return diffs
+}
+
+// layersByFilepath indexes the layers by the file path, the layers without it are keyed by the digest.
+func layersByFilepath(layers []ocispec.Descriptor) map[string]ocispec.Descriptor {
+	indexed := make(map[string]ocispec.Descriptor, len(layers))
+	for _, layer := range layers {
+		name := layerFilepath(layer)
+		if name == "" {
+			name = layer.Digest.String()
+		}
+
+		indexed[name] = layer
+	}
+
+	return indexed
+}
@@ -0,0 +1,109 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestPushVerifyRemote(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+
+	server, contents := newMemoryRegistry(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+	target := host + "/models/llama3:v1"
+
+	weight := []byte("weight")
+	manifestRaw := storeModel(t, b, host+"/models/llama3", "v1", map[string][]byte{
+		"model.safetensors": weight,
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+	}, []string{"model.safetensors", "config.json"})
+
+	cfg := config.NewPush()
+	cfg.PlainHTTP = true
+	cfg.VerifyRemote = true
+
+	t.Run("absent remote", func(t *testing.T) {
+		require.NoError(t, b.Push(ctx, target, cfg))
+		assert.Equal(t, manifestRaw, contents["models/llama3/manifests/v1"])
+	})
+
+	t.Run("identical remote", func(t *testing.T) {
+		// the push is skipped entirely, so the removed blob is not uploaded again.
+		weightKey := "models/llama3/blobs/" + godigest.FromBytes(weight).String()
+		delete(contents, weightKey)
+		require.NoError(t, b.Push(ctx, target, cfg))
+		assert.NotContains(t, contents, weightKey)
+		contents[weightKey] = weight
+	})
+
+	t.Run("differing remote", func(t *testing.T) {
+		localRaw := storeModel(t, b, host+"/models/llama3", "v1", map[string][]byte{
+			"model.safetensors": []byte("tuned weight"),
+			"tokenizer.json":    []byte(`{}`),
+		}, []string{"model.safetensors", "tokenizer.json"})
+
+		err := b.Push(ctx, target, cfg)
+		assert.ErrorContains(t, err, "differs from the local artifact")
+		assert.ErrorContains(t, err, "removed config.json, changed model.safetensors, added tokenizer.json")
+		assert.ErrorContains(t, err, "--force")
+		assert.Equal(t, manifestRaw, contents["models/llama3/manifests/v1"])
+
+		cfg.Force = true
+		require.NoError(t, b.Push(ctx, target, cfg))
+		assert.Equal(t, localRaw, contents["models/llama3/manifests/v1"])
+	})
+}
+
+func TestDiffManifests(t *testing.T) {
+	layer := func(name, content string) ocispec.Descriptor {
+		return ocispec.Descriptor{
+			Digest:      godigest.FromString(content),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: name},
+		}
+	}
+
+	remote := ocispec.Manifest{
+		Config: ocispec.Descriptor{Digest: godigest.FromString("config")},
+		Layers: []ocispec.Descriptor{layer("a.bin", "a"), layer("b.bin", "b")},
+	}
+
+	assert.Equal(t, []string{"manifest annotations or layer order"}, diffManifests(remote, remote))
+
+	local := ocispec.Manifest{
+		Config: ocispec.Descriptor{Digest: godigest.FromString("new config")},
+		Layers: []ocispec.Descriptor{layer("c.bin", "c"), layer("a.bin", "a2")},
+	}
+	assert.Equal(t, []string{
+		"config " + godigest.FromString("config").String() + " -> " + godigest.FromString("new config").String(),
+		"changed a.bin",
+		"removed b.bin",
+		"added c.bin",
+	}, diffManifests(local, remote))
+}
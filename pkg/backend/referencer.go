@@ -16,7 +16,25 @@
 
 package backend
 
-import "github.com/distribution/reference"
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/distribution/reference"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultShortNameTag is the tag of the expanded short name without the tag or digest.
+const defaultShortNameTag = "latest"
+
+var (
+	// referencePrefix is the repository prefix prepended to the short names.
+	referencePrefix string
+	// referenceAliases maps the short names to the fully qualified references.
+	referenceAliases   map[string]string
+	referenceResolveMu sync.RWMutex
+)
 
 // Referencer is the interface for the reference.
 type Referencer interface {
@@ -34,9 +52,88 @@ type referencer struct {
 	named reference.Named
 }
 
-// ParseReference parses the reference.
+// SetReferenceResolver sets the resolution of the short names which have no registry domain,
+// such as llama3. The aliases map the short names to the fully qualified references, and the
+// other short names are prefixed by the prefix, such as registry.com/models, the empty prefix
+// leaves them unresolved.
+func SetReferenceResolver(prefix string, aliases map[string]string) error {
+	for alias, ref := range aliases {
+		if isQualifiedName(splitReference(alias)) {
+			return fmt.Errorf("alias %s must be a short name without the registry domain", alias)
+		}
+
+		if _, err := reference.ParseNamed(ref); err != nil {
+			return fmt.Errorf("invalid reference %s of alias %s: %w", ref, alias, err)
+		}
+	}
+
+	referenceResolveMu.Lock()
+	defer referenceResolveMu.Unlock()
+
+	referencePrefix = strings.TrimSuffix(prefix, "/")
+	referenceAliases = aliases
+	return nil
+}
+
+// ResolveReference expands the short name to the fully qualified reference by the alias or the
+// prefix, the explicit tag or digest of the short name is kept, otherwise the tag of the alias
+// or latest is used. The fully qualified references are returned as is.
+func ResolveReference(ref string) string {
+	name := splitReference(ref)
+	if isQualifiedName(name) {
+		return ref
+	}
+
+	referenceResolveMu.RLock()
+	defer referenceResolveMu.RUnlock()
+
+	suffix := strings.TrimPrefix(ref, name)
+	resolved := ref
+	if alias, ok := referenceAliases[name]; ok {
+		if suffix == "" {
+			resolved = alias
+		} else {
+			resolved = splitReference(alias) + suffix
+		}
+	} else if referencePrefix != "" {
+		if suffix == "" {
+			suffix = ":" + defaultShortNameTag
+		}
+
+		resolved = referencePrefix + "/" + name + suffix
+	}
+
+	if resolved != ref {
+		logrus.Debugf("reference: resolved short name %s to %s", ref, resolved)
+	}
+
+	return resolved
+}
+
+// splitReference returns the name of the reference without the tag and digest.
+func splitReference(ref string) string {
+	name, _, _ := strings.Cut(ref, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// isQualifiedName returns whether the name starts with the registry domain, which is the first
+// component containing the dot or port, or localhost.
+func isQualifiedName(name string) bool {
+	domain, _, found := strings.Cut(name, "/")
+	if !found {
+		return false
+	}
+
+	return strings.ContainsAny(domain, ".:") || domain == "localhost"
+}
+
+// ParseReference parses the reference, the short name is resolved by ResolveReference first.
 func ParseReference(ref string) (Referencer, error) {
-	named, err := reference.ParseNamed(ref)
+	named, err := reference.ParseNamed(ResolveReference(ref))
 	if err != nil {
 		return nil, err
 	}
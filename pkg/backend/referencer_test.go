@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseReference(t *testing.T) {
@@ -87,3 +88,66 @@ func TestReferencer(t *testing.T) {
 	assert.Equal(t, "tag", ref.Tag())
 	assert.Equal(t, "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", ref.Digest())
 }
+
+func TestResolveReference(t *testing.T) {
+	require.NoError(t, SetReferenceResolver("registry.internal/models/", map[string]string{
+		"llama3":  "registry.internal/models/llama3:latest",
+		"qwen":    "mirror.com/qwen/qwen2.5:7b",
+		"team/mt": "registry.internal/team/translation@sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+	}))
+	t.Cleanup(func() { SetReferenceResolver("", nil) })
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// the aliases are expanded with the explicit tag or digest kept.
+		{"llama3", "registry.internal/models/llama3:latest"},
+		{"llama3:v2", "registry.internal/models/llama3:v2"},
+		{"qwen", "mirror.com/qwen/qwen2.5:7b"},
+		{"qwen@sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", "mirror.com/qwen/qwen2.5@sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"},
+		{"team/mt:v1", "registry.internal/team/translation:v1"},
+		// the other short names are prefixed.
+		{"mistral", "registry.internal/models/mistral:latest"},
+		{"mistral:v0.3", "registry.internal/models/mistral:v0.3"},
+		{"team/phi", "registry.internal/models/team/phi:latest"},
+		// the explicit references are passed through.
+		{"example.com/llama3:v1", "example.com/llama3:v1"},
+		{"localhost/llama3", "localhost/llama3"},
+		{"localhost:5000/llama3@sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", "localhost:5000/llama3@sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, ResolveReference(test.input))
+		})
+	}
+
+	ref, err := ParseReference("llama3")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.internal/models/llama3", ref.Repository())
+	assert.Equal(t, "latest", ref.Tag())
+
+	ref, err = ParseReference("example.com/llama3:v1")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/llama3", ref.Repository())
+	assert.Equal(t, "v1", ref.Tag())
+}
+
+func TestResolveReferenceWithoutPrefix(t *testing.T) {
+	require.NoError(t, SetReferenceResolver("", map[string]string{"llama3": "registry.internal/models/llama3:latest"}))
+	t.Cleanup(func() { SetReferenceResolver("", nil) })
+
+	assert.Equal(t, "registry.internal/models/llama3:latest", ResolveReference("llama3"))
+	assert.Equal(t, "mistral", ResolveReference("mistral"))
+
+	_, err := ParseReference("mistral")
+	assert.Error(t, err)
+}
+
+func TestSetReferenceResolver(t *testing.T) {
+	t.Cleanup(func() { SetReferenceResolver("", nil) })
+
+	assert.ErrorContains(t, SetReferenceResolver("", map[string]string{"example.com/llama3": "registry.internal/models/llama3"}), "must be a short name")
+	assert.ErrorContains(t, SetReferenceResolver("", map[string]string{"llama3": "llama3"}), "invalid reference")
+}
@@ -24,6 +24,7 @@ import (
 	"net/url"
 	"os"
 	"runtime"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"oras.land/oras-go/v2/registry/remote"
@@ -36,6 +37,33 @@ import (
 
 type Repository = remote.Repository
 
+var (
+	// defaultUserAgent is the User-Agent of the requests sent by the clients without WithUserAgent.
+	defaultUserAgent   string
+	defaultUserAgentMu sync.RWMutex
+)
+
+// SetUserAgent sets the default User-Agent of the requests sent by all the remote clients, the
+// empty user agent restores modctl/<version>.
+func SetUserAgent(userAgent string) {
+	defaultUserAgentMu.Lock()
+	defer defaultUserAgentMu.Unlock()
+
+	defaultUserAgent = userAgent
+}
+
+// getUserAgent returns the default User-Agent, which is modctl/<version> if not set.
+func getUserAgent() string {
+	defaultUserAgentMu.RLock()
+	defer defaultUserAgentMu.RUnlock()
+
+	if defaultUserAgent == "" {
+		return fmt.Sprintf("modctl/%s", version.GitVersion)
+	}
+
+	return defaultUserAgent
+}
+
 type Option func(*client)
 
 type client struct {
@@ -43,22 +71,64 @@ type client struct {
 	plainHTTP bool
 	insecure  bool
 	proxy     string
+	tokenFile string
+	userAgent string
+	// manifestCache revalidates the manifests fetched by the tags by the conditional requests.
+	manifestCache ManifestCache
 }
 
 func New(repo string, opts ...Option) (*remote.Repository, error) {
-	client := &client{}
+	client := &client{tokenFile: getTokenFile(), userAgent: getUserAgent()}
 	for _, opt := range opts {
 		opt(client)
 	}
 
+	repository, err := remote.NewRepository(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository: %w", err)
+	}
+
+	repository.Client, err = client.authClient(repository.Reference.Host())
+	if err != nil {
+		return nil, err
+	}
+
+	repository.PlainHTTP = client.plainHTTP
+	return repository, nil
+}
+
+// NewRegistry creates the client of the registry authenticated in the same way as the repositories.
+func NewRegistry(registry string, opts ...Option) (*remote.Registry, error) {
+	client := &client{tokenFile: getTokenFile(), userAgent: getUserAgent()}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	reg, err := remote.NewRegistry(registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create registry: %w", err)
+	}
+
+	reg.Client, err = client.authClient(reg.Reference.Host())
+	if err != nil {
+		return nil, err
+	}
+
+	reg.PlainHTTP = client.plainHTTP
+	return reg, nil
+}
+
+// authClient creates the auth client with the credentials from the token file or the Docker config,
+// the token from the token file is only sent to the host of the registry.
+func (c *client) authClient(host string) (*auth.Client, error) {
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: client.insecure,
+			InsecureSkipVerify: c.insecure,
 		},
 	}
 
-	if client.proxy != "" {
-		proxyURL, err := url.Parse(client.proxy)
+	if c.proxy != "" {
+		proxyURL, err := url.Parse(c.proxy)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse the proxy URL: %w", err)
 		}
@@ -66,16 +136,19 @@ func New(repo string, opts ...Option) (*remote.Repository, error) {
 		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
+	// Pace the requests by the rate limits of the registry, including the retries.
+	rateLimited := &rateLimitTransport{base: transport, limiter: defaultRateLimiter}
+
 	httpClient := &http.Client{}
-	if client.retry {
-		httpClient.Transport = retry.NewTransport(transport)
+	if c.retry {
+		httpClient.Transport = retry.NewTransport(rateLimited)
 	} else {
-		httpClient.Transport = transport
+		httpClient.Transport = rateLimited
 	}
 
-	repository, err := remote.NewRepository(repo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create repository: %w", err)
+	// Revalidate the cached manifests by the ETags instead of refetching them.
+	if c.manifestCache != nil {
+		httpClient.Transport = &conditionalTransport{base: httpClient.Transport, cache: c.manifestCache}
 	}
 
 	// Load credentials from Docker config.
@@ -84,15 +157,18 @@ func New(repo string, opts ...Option) (*remote.Repository, error) {
 		return nil, fmt.Errorf("failed to create credential store: %w", err)
 	}
 
-	repository.Client = &auth.Client{
-		Cache:      auth.NewCache(),
-		Credential: credentials.Credential(credStore),
-		Client:     httpClient,
-		Header:     makeHeader(),
+	// Prefer the token from the token file to the credential store if specified.
+	credential := credentials.Credential(credStore)
+	if c.tokenFile != "" {
+		credential = tokenFileCredential(c.tokenFile, host, credential)
 	}
 
-	repository.PlainHTTP = client.plainHTTP
-	return repository, nil
+	return &auth.Client{
+		Cache:      auth.NewCache(),
+		Credential: credential,
+		Client:     httpClient,
+		Header:     makeHeader(c.userAgent),
+	}, nil
 }
 
 func WithRetry(retry bool) Option {
@@ -119,10 +195,36 @@ func WithPlainHTTP(plainHTTP bool) Option {
 	}
 }
 
+// WithTokenFile sets the file of the bearer token to authenticate with the registry,
+// which overrides the default token file set by SetTokenFile.
+func WithTokenFile(tokenFile string) Option {
+	return func(c *client) {
+		c.tokenFile = tokenFile
+	}
+}
+
+// WithUserAgent sets the User-Agent of the requests, which overrides the default user agent set
+// by SetUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(c *client) {
+		if userAgent != "" {
+			c.userAgent = userAgent
+		}
+	}
+}
+
+// WithManifestCache sets the cache of the manifests fetched by the tags, which are requested
+// conditionally by the cached ETags and served from the cache if they are not modified.
+func WithManifestCache(cache ManifestCache) Option {
+	return func(c *client) {
+		c.manifestCache = cache
+	}
+}
+
 // makeHeader creates a new http.Header with default headers.
-func makeHeader() http.Header {
+func makeHeader(userAgent string) http.Header {
 	header := make(http.Header)
-	header.Set("User-Agent", fmt.Sprintf("modctl/%s", version.GitVersion))
+	header.Set("User-Agent", userAgent)
 
 	hostname, err := os.Hostname()
 	if err != nil {
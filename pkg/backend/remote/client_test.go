@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/version"
+)
+
+// newUserAgentRegistry serves a manifest and records the User-Agent of the requests.
+func newUserAgentRegistry(t *testing.T) (*httptest.Server, func() []string) {
+	var (
+		mu         sync.Mutex
+		userAgents []string
+	)
+
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", godigest.FromBytes(manifest).String())
+		w.Write(manifest)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, userAgents...)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		set      string
+		opts     []Option
+		expected string
+	}{
+		{name: "default", expected: "modctl/" + version.GitVersion},
+		{name: "set", set: "pipeline/1.0", expected: "pipeline/1.0"},
+		{name: "option", set: "pipeline/1.0", opts: []Option{WithUserAgent("job/42")}, expected: "job/42"},
+		{name: "empty option", set: "pipeline/1.0", opts: []Option{WithUserAgent("")}, expected: "pipeline/1.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetUserAgent(tc.set)
+			t.Cleanup(func() { SetUserAgent("") })
+
+			server, userAgents := newUserAgentRegistry(t)
+			repo, err := New(strings.TrimPrefix(server.URL, "http://")+"/models/test", append([]Option{WithPlainHTTP(true)}, tc.opts...)...)
+			require.NoError(t, err)
+
+			_, err = repo.Manifests().Resolve(context.Background(), "v1")
+			require.NoError(t, err)
+			require.NotEmpty(t, userAgents())
+			for _, userAgent := range userAgents() {
+				assert.Equal(t, tc.expected, userAgent)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+// maxConditionalManifestSize is the max size of the manifests cached for the conditional requests.
+const maxConditionalManifestSize = 4 << 20
+
+// CachedManifest is the manifest fetched by the tag, which is cached with its ETag.
+type CachedManifest struct {
+	// ETag is the entity tag of the manifest responded by the registry.
+	ETag string
+	// MediaType is the media type of the manifest.
+	MediaType string
+	// Digest is the digest of the manifest.
+	Digest string
+	// Content is the content of the manifest.
+	Content []byte
+}
+
+// ManifestCache caches the manifests fetched by the tags with the ETags, which are revalidated
+// by If-None-Match, so the unchanged manifests are served from the cache on 304 Not Modified.
+type ManifestCache interface {
+	// LookupManifest returns the cached manifest of the URL.
+	LookupManifest(url string) (*CachedManifest, bool)
+	// StoreManifest caches the manifest of the URL.
+	StoreManifest(url string, manifest *CachedManifest)
+}
+
+// conditionalTransport is the transport sending the manifest requests by the tags conditionally
+// by the ETags of the cached manifests, the 304 responses are turned into the 200 ones with the
+// cached manifests, so the callers are unaware of the revalidation.
+type conditionalTransport struct {
+	base  http.RoundTripper
+	cache ManifestCache
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || !isTagManifestURL(req.URL) || req.Header.Get("If-None-Match") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	manifestURL := req.URL.String()
+	cached, ok := t.cache.LookupManifest(manifestURL)
+	if ok && cached.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		resp.Body.Close()
+		logrus.Debugf("remote: manifest %s is not modified, served from the cache", manifestURL)
+		return cachedManifestResponse(req, resp, cached), nil
+	case resp.StatusCode == http.StatusOK:
+		return t.store(manifestURL, resp)
+	default:
+		return resp, nil
+	}
+}
+
+// store caches the manifest of the response with the ETag, the body of the response is replaced
+// by the read content.
+func (t *conditionalTransport) store(manifestURL string, resp *http.Response) (*http.Response, error) {
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.ContentLength > maxConditionalManifestSize {
+		return resp, nil
+	}
+
+	body := resp.Body
+	content, err := io.ReadAll(io.LimitReader(body, maxConditionalManifestSize+1))
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	// Hand over the rest of the oversized manifest without caching it.
+	if len(content) > maxConditionalManifestSize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(content), body), body}
+		return resp, nil
+	}
+
+	body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = checksum.FromBytes(content).String()
+	}
+
+	t.cache.StoreManifest(manifestURL, &CachedManifest{
+		ETag:      etag,
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    digest,
+		Content:   content,
+	})
+
+	return resp, nil
+}
+
+// cachedManifestResponse creates the 200 response of the cached manifest for the 304 response.
+func cachedManifestResponse(req *http.Request, notModified *http.Response, cached *CachedManifest) *http.Response {
+	header := notModified.Header.Clone()
+	header.Set("Content-Type", cached.MediaType)
+	header.Set("Content-Length", strconv.Itoa(len(cached.Content)))
+	header.Set("Docker-Content-Digest", cached.Digest)
+	if header.Get("ETag") == "" {
+		header.Set("ETag", cached.ETag)
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Content)),
+		ContentLength: int64(len(cached.Content)),
+		Request:       req,
+	}
+}
+
+// isTagManifestURL checks if the URL is of the manifest referenced by the tag, the manifests
+// referenced by the digests are immutable and need no revalidation.
+func isTagManifestURL(u *url.URL) bool {
+	if !strings.HasPrefix(u.Path, "/v2/") {
+		return false
+	}
+
+	idx := strings.LastIndex(u.Path, "/manifests/")
+	if idx < 0 {
+		return false
+	}
+
+	ref := u.Path[idx+len("/manifests/"):]
+	return ref != "" && !strings.Contains(ref, ":")
+}
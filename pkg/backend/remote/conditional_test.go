@@ -0,0 +1,150 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryManifestCache is the ManifestCache in memory for the tests.
+type memoryManifestCache struct {
+	mu        sync.Mutex
+	manifests map[string]*CachedManifest
+}
+
+func (c *memoryManifestCache) LookupManifest(url string) (*CachedManifest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	manifest, ok := c.manifests[url]
+	return manifest, ok
+}
+
+func (c *memoryManifestCache) StoreManifest(url string, manifest *CachedManifest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifests[url] = manifest
+}
+
+func TestConditionalTransport(t *testing.T) {
+	const mediaType = "application/vnd.oci.image.manifest.v1+json"
+	var (
+		mu       sync.Mutex
+		manifest = []byte(`{"schemaVersion":2,"layers":[]}`)
+		version  = 1
+		fetches  int
+		notMod   int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		etag := fmt.Sprintf(`"v%d"`, version)
+		if r.Header.Get("If-None-Match") == etag {
+			notMod++
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fetches++
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", mediaType)
+		w.Header().Set("Docker-Content-Digest", godigest.FromBytes(manifest).String())
+		w.Write(manifest)
+	}))
+	defer server.Close()
+
+	cache := &memoryManifestCache{manifests: map[string]*CachedManifest{}}
+	client := &http.Client{Transport: &conditionalTransport{base: http.DefaultTransport, cache: cache}}
+
+	get := func(path string) (*http.Response, []byte) {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	// the first fetch caches the manifest with the ETag.
+	resp, body := get("/v2/models/llama3/manifests/v1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, manifest, body)
+	assert.Equal(t, 1, fetches)
+
+	// the unchanged manifest is served from the cache on 304.
+	resp, body = get("/v2/models/llama3/manifests/v1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, manifest, body)
+	assert.Equal(t, mediaType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, godigest.FromBytes(manifest).String(), resp.Header.Get("Docker-Content-Digest"))
+	assert.Equal(t, int64(len(manifest)), resp.ContentLength)
+	assert.Equal(t, 1, fetches)
+	assert.Equal(t, 1, notMod)
+
+	// the changed ETag refetches the manifest and updates the cache.
+	mu.Lock()
+	manifest = []byte(`{"schemaVersion":2,"layers":[{}]}`)
+	version = 2
+	mu.Unlock()
+
+	resp, body = get("/v2/models/llama3/manifests/v1")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, manifest, body)
+	assert.Equal(t, 2, fetches)
+
+	cached, ok := cache.LookupManifest(server.URL + "/v2/models/llama3/manifests/v1")
+	require.True(t, ok)
+	assert.Equal(t, `"v2"`, cached.ETag)
+	assert.Equal(t, manifest, cached.Content)
+
+	// the manifests by the digests and the blobs are not requested conditionally.
+	get("/v2/models/llama3/manifests/" + godigest.FromBytes(manifest).String())
+	get("/v2/models/llama3/blobs/sha256:0000")
+	assert.Equal(t, 4, fetches)
+	assert.Len(t, cache.manifests, 1)
+}
+
+func TestIsTagManifestURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://example.com/v2/models/llama3/manifests/v1", true},
+		{"https://example.com/v2/models/llama3/manifests/sha256:1234", false},
+		{"https://example.com/v2/models/llama3/blobs/sha256:1234", false},
+		{"https://example.com/v2/models/llama3/manifests/", false},
+		{"https://example.com/other/manifests/v1", false},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, isTagManifestURL(req.URL), tc.url)
+	}
+}
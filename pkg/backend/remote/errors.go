@@ -0,0 +1,127 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	retry "github.com/avast/retry-go/v4"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// ErrUnsupportedMediaType is returned when the registry rejects the media types of the model artifact.
+var ErrUnsupportedMediaType = errors.New("registry does not support the media types of the model artifact")
+
+// mediaTypeRejectionHints are the keywords in the error message or detail of the registry,
+// which indicate the manifest is rejected because of the media type.
+var mediaTypeRejectionHints = []string{
+	"media type",
+	"mediatype",
+	"artifact type",
+	"artifacttype",
+}
+
+// IsUnsupportedMediaType returns true if the error is the response of the registry
+// which rejects the media types of the model artifact, which is the 415 status or the
+// MANIFEST_INVALID error mentioning the media type.
+func IsUnsupportedMediaType(err error) bool {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		return true
+	}
+
+	var errResp *errcode.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return false
+	}
+
+	if errResp.StatusCode == http.StatusUnsupportedMediaType {
+		return true
+	}
+
+	for _, e := range errResp.Errors {
+		if e.Code == errcode.ErrorCodeManifestInvalid && (containsRejectionHint(e.Message) || containsRejectionHint(fmt.Sprint(e.Detail))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WrapUnsupportedMediaType wraps the error with an actionable hint if the registry rejects
+// the media types of the model artifact, otherwise returns the original error.
+func WrapUnsupportedMediaType(err error) error {
+	if err == nil || errors.Is(err, ErrUnsupportedMediaType) || !IsUnsupportedMediaType(err) {
+		return err
+	}
+
+	return fmt.Errorf("%w (config media type %s, artifact type %s): the registry may not support OCI artifacts "+
+		"with custom media types, please enable the OCI artifact support of the registry or use a registry "+
+		"compliant with the OCI distribution spec v1.1, such as Harbor, Zot or Distribution: %w",
+		ErrUnsupportedMediaType, modelspec.MediaTypeModelConfig, modelspec.ArtifactTypeModelManifest, err)
+}
+
+// UnrecoverableOnUnsupportedMediaType marks the error as unrecoverable if the registry rejects
+// the media types, so the retry stops immediately, otherwise returns the original error.
+func UnrecoverableOnUnsupportedMediaType(err error) error {
+	if IsUnsupportedMediaType(err) {
+		return retry.Unrecoverable(err)
+	}
+
+	return err
+}
+
+// IsRetryable returns false if the error is the response of the registry which won't succeed on
+// retry, such as the bad request, the failed authentication or the missing content, and true for
+// the others such as the network errors, the server errors and the rate limiting.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, errdef.ErrNotFound) || IsUnsupportedMediaType(err) {
+		return false
+	}
+
+	var errResp *errcode.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return true
+	}
+
+	switch errResp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return errResp.StatusCode < http.StatusBadRequest || errResp.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// containsRejectionHint returns true if the message contains any keyword of the media type rejection.
+func containsRejectionHint(message string) bool {
+	message = strings.ToLower(message)
+	for _, hint := range mediaTypeRejectionHints {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,189 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	retry "github.com/avast/retry-go/v4"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+func TestIsUnsupportedMediaType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil error",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "generic error",
+			err:      errors.New("connection reset by peer"),
+			expected: false,
+		},
+		{
+			name:     "unsupported media type status",
+			err:      &errcode.ErrorResponse{StatusCode: http.StatusUnsupportedMediaType},
+			expected: true,
+		},
+		{
+			name: "unsupported error code",
+			err: &errcode.ErrorResponse{StatusCode: http.StatusBadRequest, Errors: errcode.Errors{
+				{Code: errcode.ErrorCodeUnsupported, Message: "The operation is unsupported."},
+			}},
+			expected: false,
+		},
+		{
+			name: "manifest invalid by unsupported feature",
+			err: &errcode.ErrorResponse{StatusCode: http.StatusBadRequest, Errors: errcode.Errors{
+				{Code: errcode.ErrorCodeManifestInvalid, Message: "manifest invalid", Detail: "foreign layers are not supported"},
+			}},
+			expected: false,
+		},
+		{
+			name: "manifest invalid by media type",
+			err: fmt.Errorf("wrapped: %w", &errcode.ErrorResponse{StatusCode: http.StatusBadRequest, Errors: errcode.Errors{
+				{Code: errcode.ErrorCodeManifestInvalid, Message: "manifest invalid", Detail: "unknown media type"},
+			}}),
+			expected: true,
+		},
+		{
+			name: "manifest invalid by other reasons",
+			err: &errcode.ErrorResponse{StatusCode: http.StatusBadRequest, Errors: errcode.Errors{
+				{Code: errcode.ErrorCodeManifestInvalid, Message: "manifest invalid", Detail: "blob unknown to registry"},
+			}},
+			expected: false,
+		},
+		{
+			name: "unauthorized",
+			err: &errcode.ErrorResponse{StatusCode: http.StatusUnauthorized, Errors: errcode.Errors{
+				{Code: errcode.ErrorCodeUnauthorized, Message: "authentication required"},
+			}},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsUnsupportedMediaType(tc.err))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil error", err: nil, expected: false},
+		{name: "network error", err: errors.New("connection reset by peer"), expected: true},
+		{name: "not found", err: fmt.Errorf("%s: %w", "sha256:abc", errdef.ErrNotFound), expected: false},
+		{name: "bad request", err: &errcode.ErrorResponse{StatusCode: http.StatusBadRequest}, expected: false},
+		{name: "unauthorized", err: fmt.Errorf("wrapped: %w", &errcode.ErrorResponse{StatusCode: http.StatusUnauthorized}), expected: false},
+		{name: "forbidden", err: &errcode.ErrorResponse{StatusCode: http.StatusForbidden}, expected: false},
+		{name: "not found response", err: &errcode.ErrorResponse{StatusCode: http.StatusNotFound}, expected: false},
+		{name: "unsupported media type", err: &errcode.ErrorResponse{StatusCode: http.StatusUnsupportedMediaType}, expected: false},
+		{name: "request timeout", err: &errcode.ErrorResponse{StatusCode: http.StatusRequestTimeout}, expected: true},
+		{name: "too many requests", err: &errcode.ErrorResponse{StatusCode: http.StatusTooManyRequests}, expected: true},
+		{name: "internal server error", err: &errcode.ErrorResponse{StatusCode: http.StatusInternalServerError}, expected: true},
+		{name: "service unavailable", err: &errcode.ErrorResponse{StatusCode: http.StatusServiceUnavailable}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestWrapUnsupportedMediaType(t *testing.T) {
+	assert.NoError(t, WrapUnsupportedMediaType(nil))
+
+	generic := errors.New("timeout")
+	assert.Equal(t, generic, WrapUnsupportedMediaType(generic))
+
+	rejected := &errcode.ErrorResponse{StatusCode: http.StatusUnsupportedMediaType}
+	err := WrapUnsupportedMediaType(rejected)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+	assert.ErrorIs(t, err, rejected)
+	assert.Contains(t, err.Error(), "OCI artifacts")
+
+	// Wrap twice should not duplicate the hint.
+	assert.Equal(t, err, WrapUnsupportedMediaType(err))
+}
+
+func TestUnrecoverableOnUnsupportedMediaType(t *testing.T) {
+	assert.NoError(t, UnrecoverableOnUnsupportedMediaType(nil))
+
+	generic := errors.New("timeout")
+	assert.Equal(t, generic, UnrecoverableOnUnsupportedMediaType(generic))
+	assert.True(t, retry.IsRecoverable(UnrecoverableOnUnsupportedMediaType(generic)))
+
+	rejected := WrapUnsupportedMediaType(&errcode.ErrorResponse{StatusCode: http.StatusUnsupportedMediaType})
+	err := UnrecoverableOnUnsupportedMediaType(rejected)
+	assert.False(t, retry.IsRecoverable(err))
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+}
+
+func TestPushToMediaTypeRejectingRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":[{"code":"MANIFEST_INVALID","message":"manifest invalid","detail":"unsupported config media type"}]}`)
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	repo, err := New(strings.TrimPrefix(server.URL, "http://")+"/models/test", WithPlainHTTP(true))
+	require.NoError(t, err)
+
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    godigest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+
+	err = repo.Manifests().Push(context.Background(), desc, bytes.NewReader(manifest))
+	require.Error(t, err)
+	assert.True(t, IsUnsupportedMediaType(err))
+
+	err = WrapUnsupportedMediaType(err)
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+	assert.Contains(t, err.Error(), "please enable the OCI artifact support of the registry")
+}
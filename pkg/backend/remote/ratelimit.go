@@ -0,0 +1,252 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// lowRateLimitRatio is the ratio of the remaining requests to the limit, below which the
+	// requests are slowed down proactively to avoid being rate limited.
+	lowRateLimitRatio = 0.1
+
+	// defaultRateLimitDelay is the delay between the requests when the remaining requests are
+	// low and the window of the rate limit is unknown.
+	defaultRateLimitDelay = time.Second
+
+	// maxRateLimitDelay is the max delay between the requests when slowing down proactively.
+	maxRateLimitDelay = 30 * time.Second
+
+	// maxRetryAfter is the max duration to honor the Retry-After header of the registry.
+	maxRetryAfter = 5 * time.Minute
+)
+
+// defaultRateLimiter is shared by all the remote clients, so the retries by the new clients
+// still honor the rate limits of the registries.
+var defaultRateLimiter = newRateLimiter()
+
+// rateLimiter paces the requests to the registries by the rate-limit headers, such as
+// RateLimit-Remaining of Docker Hub, and the Retry-After header of the 429 responses.
+type rateLimiter struct {
+	mu sync.Mutex
+	// hosts is the rate limit of each host.
+	hosts map[string]*hostRateLimit
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// hostRateLimit is the rate limit of a host.
+type hostRateLimit struct {
+	// notBefore is the time before which the requests to the host should wait, which is
+	// also the slot handed out to the next waiter.
+	notBefore time.Time
+	// interval is the duration between the slots handed out to the waiters.
+	interval time.Duration
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		hosts: make(map[string]*hostRateLimit),
+		now:   time.Now,
+		sleep: sleepContext,
+	}
+}
+
+// wait waits until the requests to the host are allowed. The waiters are handed out the slots
+// one at a time, so they are spread by the interval instead of released all at once.
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	limit, ok := l.hosts[host]
+	if !ok {
+		l.mu.Unlock()
+		return nil
+	}
+
+	delay := limit.notBefore.Sub(l.now())
+	if delay > 0 {
+		limit.notBefore = limit.notBefore.Add(limit.interval)
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	logrus.Debugf("remote: waiting %s for the rate limit of %s", delay, host)
+	return l.sleep(ctx, delay)
+}
+
+// observe schedules the next request to the host by the rate-limit headers of the response.
+func (l *rateLimiter) observe(host string, resp *http.Response) {
+	now := l.now()
+	var delay, interval time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests {
+		delay = defaultRateLimitDelay
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			delay = retryAfter
+		}
+
+		// The waiters for the Retry-After are resumed by the default delay one after another.
+		interval = defaultRateLimitDelay
+		logrus.Warnf("remote: rate limited by %s, retry after %s", host, delay)
+	} else if remainingDelay, ok := rateLimitDelay(resp.Header); ok {
+		delay, interval = remainingDelay, remainingDelay
+		logrus.Debugf("remote: the remaining requests to %s are low, slow down by %s", host, delay)
+	}
+
+	if delay <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit, ok := l.hosts[host]
+	if !ok {
+		limit = &hostRateLimit{}
+		l.hosts[host] = limit
+	}
+
+	if notBefore := now.Add(delay); notBefore.After(limit.notBefore) {
+		limit.notBefore = notBefore
+		limit.interval = interval
+	}
+}
+
+// rateLimitDelay returns the delay before the next request if the remaining requests in the
+// RateLimit-Remaining header are low, the remaining requests are spread over the window.
+func rateLimitDelay(header http.Header) (time.Duration, bool) {
+	remaining, window, ok := parseRateLimit(header.Get("RateLimit-Remaining"))
+	if !ok {
+		return 0, false
+	}
+
+	// Use the window of the limit if the remaining one does not carry it.
+	limit, limitWindow, ok := parseRateLimit(header.Get("RateLimit-Limit"))
+	if window == 0 {
+		window = limitWindow
+	}
+
+	if ok && limit > 0 && float64(remaining) >= float64(limit)*lowRateLimitRatio {
+		return 0, false
+	}
+
+	// Without the limit, only slow down when the requests are exhausted.
+	if !ok && remaining > 0 {
+		return 0, false
+	}
+
+	if window == 0 {
+		return defaultRateLimitDelay, true
+	}
+
+	return min(window/time.Duration(remaining+1), maxRateLimitDelay), true
+}
+
+// parseRateLimit parses the value of the rate-limit header, such as "76;w=21600", which is the
+// count and the window in seconds.
+func parseRateLimit(value string) (int, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+
+	parts := strings.Split(value, ";")
+	count, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || count < 0 {
+		return 0, 0, false
+	}
+
+	var window time.Duration
+	for _, param := range parts[1:] {
+		key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || key != "w" {
+			continue
+		}
+
+		if seconds, err := strconv.Atoi(val); err == nil && seconds > 0 {
+			window = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return count, window, true
+}
+
+// parseRetryAfter parses the value of the Retry-After header, which is either the seconds to
+// wait or the HTTP date, the duration is capped by maxRetryAfter.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	return min(delay, maxRetryAfter), true
+}
+
+// sleepContext sleeps for the duration unless the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimitTransport is the transport waiting for the rate limits of the registries before
+// sending the requests, which is wrapped by the retry transport so the retries are paced as well.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.limiter.observe(req.URL.Host, resp)
+	return resp, nil
+}
@@ -0,0 +1,212 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeRateLimiter returns the rate limiter with the fake clock, which advances by the sleeps
+// recorded into the waits instead of sleeping.
+func newFakeRateLimiter() (*rateLimiter, *[]time.Duration) {
+	var (
+		mu    sync.Mutex
+		now   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		waits []time.Duration
+	)
+
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+		waits = append(waits, d)
+		return nil
+	}
+
+	return limiter, &waits
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Run("retry after 429", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		limiter, waits := newFakeRateLimiter()
+		client := &http.Client{Transport: &rateLimitTransport{base: http.DefaultTransport, limiter: limiter}}
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Empty(t, *waits)
+
+		// the retry waits for the duration of the Retry-After header.
+		resp, err = client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []time.Duration{7 * time.Second}, *waits)
+	})
+
+	t.Run("low remaining requests", func(t *testing.T) {
+		remaining := 100
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			remaining -= 50
+			w.Header().Set("RateLimit-Limit", "100;w=60")
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining)+";w=60")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		limiter, waits := newFakeRateLimiter()
+		client := &http.Client{Transport: &rateLimitTransport{base: http.DefaultTransport, limiter: limiter}}
+
+		// half of the requests remain, no slowing down.
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		// the requests are exhausted, the next request is delayed.
+		resp, err = client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Empty(t, *waits)
+
+		resp, err = client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, []time.Duration{30 * time.Second}, *waits)
+	})
+}
+
+func TestRateLimiterWaiters(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		waits []time.Duration
+	)
+
+	// the clock is frozen, so the concurrent waiters wait for the rate limit at the same time.
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, d)
+		return nil
+	}
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}}
+	limiter.observe("registry.com", resp)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, limiter.wait(context.Background(), "registry.com"))
+		}()
+	}
+	wg.Wait()
+
+	// the waiters are resumed one at a time instead of all at once.
+	assert.ElementsMatch(t, []time.Duration{7 * time.Second, 8 * time.Second, 9 * time.Second}, waits)
+
+	// the other hosts are not affected.
+	require.NoError(t, limiter.wait(context.Background(), "other.com"))
+	assert.Len(t, waits, 3)
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	testCases := []struct {
+		name      string
+		limit     string
+		remaining string
+		expected  time.Duration
+		slowDown  bool
+	}{
+		{name: "no headers"},
+		{name: "plenty remaining", limit: "100;w=21600", remaining: "76;w=21600"},
+		{name: "low remaining", limit: "100;w=21600", remaining: "5;w=21600", expected: maxRateLimitDelay, slowDown: true},
+		{name: "low remaining in short window", limit: "100;w=60", remaining: "5;w=60", expected: 10 * time.Second, slowDown: true},
+		{name: "window from the limit", limit: "100;w=60", remaining: "2", expected: 20 * time.Second, slowDown: true},
+		{name: "unknown window", limit: "100", remaining: "1", expected: defaultRateLimitDelay, slowDown: true},
+		{name: "remaining without limit", remaining: "3;w=60"},
+		{name: "exhausted without limit", remaining: "0;w=60", expected: maxRateLimitDelay, slowDown: true},
+		{name: "invalid remaining", limit: "100", remaining: "many"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			if tc.limit != "" {
+				header.Set("RateLimit-Limit", tc.limit)
+			}
+			if tc.remaining != "" {
+				header.Set("RateLimit-Remaining", tc.remaining)
+			}
+
+			delay, ok := rateLimitDelay(header)
+			assert.Equal(t, tc.slowDown, ok)
+			assert.Equal(t, tc.expected, delay)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+
+	delay, ok = parseRetryAfter("86400", now)
+	assert.True(t, ok)
+	assert.Equal(t, maxRetryAfter, delay)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,80 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+var (
+	// defaultTokenFile is the registry token file used by the clients without WithTokenFile.
+	defaultTokenFile   string
+	defaultTokenFileMu sync.RWMutex
+)
+
+// SetTokenFile sets the default registry token file for all the remote clients, the empty
+// path disables it.
+func SetTokenFile(path string) {
+	defaultTokenFileMu.Lock()
+	defer defaultTokenFileMu.Unlock()
+
+	defaultTokenFile = path
+}
+
+// getTokenFile returns the default registry token file.
+func getTokenFile() string {
+	defaultTokenFileMu.RLock()
+	defer defaultTokenFileMu.RUnlock()
+
+	return defaultTokenFile
+}
+
+// tokenFileCredential returns the credential func sending the bearer token read from the file
+// to the registry host only, and falls back to the credential func if the file is empty. The
+// other hosts contacted by the client, such as the ones redirected to, get no credential so the
+// token never leaks to them. The file is read every time the credential is requested, which
+// happens when the cached token is rejected by the registry, so the short-lived token rotated
+// in the file is picked up without restarting.
+func tokenFileCredential(path, host string, fallback auth.CredentialFunc) auth.CredentialFunc {
+	return func(ctx context.Context, hostport string) (auth.Credential, error) {
+		if hostport != host {
+			logrus.Debugf("remote: not sending the token from registry token file %s to %s other than %s", path, hostport, host)
+			return auth.EmptyCredential, nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return auth.EmptyCredential, fmt.Errorf("failed to read registry token file: %w", err)
+		}
+
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			logrus.Warnf("remote: registry token file %s is empty, fall back to the credential store for %s", path, hostport)
+			return fallback(ctx, hostport)
+		}
+
+		logrus.Debugf("remote: using the token from registry token file %s for %s", path, hostport)
+		return auth.Credential{AccessToken: token}, nil
+	}
+}
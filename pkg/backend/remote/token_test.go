@@ -0,0 +1,145 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// newTokenRegistry serves a manifest only to the requests carrying the valid bearer token,
+// and records the tokens sent by the client.
+func newTokenRegistry(t *testing.T) (*httptest.Server, func(token string), func() []string) {
+	var (
+		mu       sync.Mutex
+		valid    string
+		received []string
+	)
+
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		received = append(received, token)
+		if token == "" || token != valid {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="http://`+r.Host+`/token",service="registry",scope="repository:models/test:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", godigest.FromBytes(manifest).String())
+		w.Write(manifest)
+	}))
+	t.Cleanup(server.Close)
+
+	setValid := func(token string) {
+		mu.Lock()
+		defer mu.Unlock()
+		valid = token
+	}
+
+	tokens := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, received...)
+	}
+
+	return server, setValid, tokens
+}
+
+func TestTokenFile(t *testing.T) {
+	server, setValid, tokens := newTokenRegistry(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-1\n"), 0600))
+	setValid("token-1")
+
+	repo, err := New(strings.TrimPrefix(server.URL, "http://")+"/models/test", WithPlainHTTP(true), WithTokenFile(tokenFile))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = repo.Manifests().Resolve(ctx, "v1")
+	require.NoError(t, err)
+	assert.Contains(t, tokens(), "token-1")
+
+	// rotate the token, the client reads the token file again once the cached token is rejected.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-2"), 0600))
+	setValid("token-2")
+	_, err = repo.Manifests().Resolve(ctx, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", tokens()[len(tokens())-1])
+}
+
+func TestDefaultTokenFile(t *testing.T) {
+	server, setValid, _ := newTokenRegistry(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0600))
+	setValid("token")
+
+	SetTokenFile(tokenFile)
+	t.Cleanup(func() { SetTokenFile("") })
+
+	repo, err := New(strings.TrimPrefix(server.URL, "http://")+"/models/test", WithPlainHTTP(true))
+	require.NoError(t, err)
+
+	_, err = repo.Manifests().Resolve(context.Background(), "v1")
+	require.NoError(t, err)
+}
+
+func TestTokenFileCredential(t *testing.T) {
+	ctx := context.Background()
+	fallback := auth.StaticCredential("registry.com", auth.Credential{Username: "user", Password: "password"})
+	tokenFile := filepath.Join(t.TempDir(), "token")
+
+	// the token in the file takes precedence over the credential store.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("  token\n"), 0600))
+	cred, err := tokenFileCredential(tokenFile, "registry.com", fallback)(ctx, "registry.com")
+	require.NoError(t, err)
+	assert.Equal(t, auth.Credential{AccessToken: "token"}, cred)
+
+	// fall back to the credential store if the token file is empty.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("\n"), 0600))
+	cred, err = tokenFileCredential(tokenFile, "registry.com", fallback)(ctx, "registry.com")
+	require.NoError(t, err)
+	assert.Equal(t, "user", cred.Username)
+
+	// the missing token file is an error instead of falling back silently.
+	_, err = tokenFileCredential(filepath.Join(t.TempDir(), "missing"), "registry.com", fallback)(ctx, "registry.com")
+	assert.ErrorContains(t, err, "failed to read registry token file")
+
+	// the token is never sent to the other hosts.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0600))
+	for _, hostport := range []string{"other.com", "registry.com:5000", "cdn.registry.com"} {
+		cred, err = tokenFileCredential(tokenFile, "registry.com", fallback)(ctx, hostport)
+		require.NoError(t, err)
+		assert.Equal(t, auth.EmptyCredential, cred, hostport)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"time"
 
 	retry "github.com/avast/retry-go/v4"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
 )
 
 var defaultRetryOpts = []retry.Option{
@@ -27,4 +29,6 @@ var defaultRetryOpts = []retry.Option{
 	retry.DelayType(retry.BackOffDelay),
 	retry.Delay(5 * time.Second),
 	retry.MaxDelay(60 * time.Second),
+	// fail fast on the registry errors which won't succeed on retry, such as 400, 401 and 404.
+	retry.RetryIf(remote.IsRetryable),
 }
@@ -19,11 +19,13 @@ package backend
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
 	retry "github.com/avast/retry-go/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/errcode"
 )
 
 // testRetryOpts creates retry options with zero delay so tests run fast and deterministically.
@@ -94,3 +96,30 @@ func TestRetrySucceedsOnFirstAttempt(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 1, attempts)
 }
+
+func TestRetryNonRetryable(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{name: "not found", err: &errcode.ErrorResponse{StatusCode: http.StatusNotFound}, attempts: 1},
+		{name: "unauthorized", err: &errcode.ErrorResponse{StatusCode: http.StatusUnauthorized}, attempts: 1},
+		{name: "service unavailable", err: &errcode.ErrorResponse{StatusCode: http.StatusServiceUnavailable}, attempts: 6},
+		{name: "network error", err: errors.New("connection reset by peer"), attempts: 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			err := retry.Do(func() error {
+				attempts++
+				return tc.err
+			}, append(defaultRetryOpts, retry.Delay(0), retry.MaxDelay(0), retry.Context(ctx))...)
+
+			assert.Error(t, err)
+			assert.Equal(t, tc.attempts, attempts)
+		})
+	}
+}
@@ -47,6 +47,11 @@ func (b *backend) Remove(ctx context.Context, target string) (string, error) {
 		return "", fmt.Errorf("failed to delete manifest %s: %w", reference, err)
 	}
 
+	// remove the Modelfile referrer of the manifest if it is no longer tagged.
+	if err := b.removeOrphanModelfileReferrers(ctx, repo, false); err != nil {
+		return "", err
+	}
+
 	logrus.Infof("remove: removed manifest %s", reference)
 	return reference, nil
 }
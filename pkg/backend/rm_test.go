@@ -34,6 +34,7 @@ func TestRemove(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockStore.On("DeleteManifest", ctx, ref.Repository(), ref.Tag()).Return(nil)
+	mockStore.On("ListTags", ctx, ref.Repository()).Return([]string{}, nil)
 
 	result, err := b.Remove(ctx, target)
 	assert.NoError(t, err)
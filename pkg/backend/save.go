@@ -0,0 +1,420 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// annotationImageName is the annotation of the fully qualified reference of the manifest in the
+// index.json of the saved archive, which follows the containerd convention as the standard
+// org.opencontainers.image.ref.name only carries the tag.
+const annotationImageName = "io.containerd.image.name"
+
+// errStopWalk stops walking the entries of the archive early.
+var errStopWalk = errors.New("stop walk")
+
+// Save saves the model artifact in the local storage to a single tar archive of the OCI image
+// layout, which contains the manifest, config and all the blobs to be loaded elsewhere.
+func (b *backend) Save(ctx context.Context, target string, cfg *config.Save) error {
+	logrus.Infof("save: saving artifact %s to %s", target, cfg.Output)
+	ref, err := ParseReference(target)
+	if err != nil {
+		return fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	repo, name, reference := ref.Repository(), ref.Repository()+":"+ref.Tag(), ref.Tag()
+	if ref.Digest() != "" {
+		name, reference = ref.Repository()+"@"+ref.Digest(), ref.Digest()
+	}
+
+	manifestRaw, manifestDigest, err := b.store.PullManifest(ctx, repo, reference)
+	if err != nil {
+		return fmt.Errorf("failed to pull manifest: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	manifestDesc := ocispec.Descriptor{
+		MediaType:    manifest.MediaType,
+		ArtifactType: manifest.ArtifactType,
+		Digest:       godigest.Digest(manifestDigest),
+		Size:         int64(len(manifestRaw)),
+		Annotations:  map[string]string{annotationImageName: name},
+	}
+	if manifestDesc.MediaType == "" {
+		manifestDesc.MediaType = ocispec.MediaTypeImageManifest
+	}
+
+	if ref.Tag() != "" {
+		manifestDesc.Annotations[ocispec.AnnotationRefName] = ref.Tag()
+	}
+
+	layoutRaw, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return fmt.Errorf("failed to marshal image layout: %w", err)
+	}
+
+	indexRaw, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	file, err := archiver.CreateAtomic(cfg.Output, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	// the layout and index are written first, so the archive can be inspected without reading the blobs.
+	tw := tar.NewWriter(file)
+	if err := writeLayoutFile(tw, ocispec.ImageLayoutFile, layoutRaw); err != nil {
+		return err
+	}
+
+	if err := writeLayoutFile(tw, ocispec.ImageIndexFile, indexRaw); err != nil {
+		return err
+	}
+
+	if err := writeLayoutFile(tw, layoutBlobPath(manifestDesc.Digest), manifestRaw); err != nil {
+		return err
+	}
+
+	written := map[godigest.Digest]bool{}
+	for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+		if written[desc.Digest] {
+			continue
+		}
+
+		logrus.Debugf("save: writing blob %s", desc.Digest)
+		if err := b.saveBlob(ctx, tw, repo, desc); err != nil {
+			return err
+		}
+
+		written[desc.Digest] = true
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	if err := file.Commit(); err != nil {
+		return err
+	}
+
+	logrus.Infof("save: saved artifact %s to %s [blobs: %d]", target, cfg.Output, len(written))
+	return nil
+}
+
+// saveBlob writes the blob of the repository to the archive and verifies its digest.
+func (b *backend) saveBlob(ctx context.Context, tw *tar.Writer, repo string, desc ocispec.Descriptor) error {
+	reader, err := b.store.PullBlob(ctx, repo, desc.Digest.String())
+	if err != nil {
+		return fmt.Errorf("failed to pull blob %s: %w", desc.Digest, err)
+	}
+	defer reader.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     layoutBlobPath(desc.Digest),
+		Mode:     0644,
+		Size:     desc.Size,
+	}); err != nil {
+		return fmt.Errorf("failed to write header of blob %s: %w", desc.Digest, err)
+	}
+
+	verifier := desc.Digest.Verifier()
+	if _, err := io.Copy(tw, io.TeeReader(reader, verifier)); err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", desc.Digest, err)
+	}
+
+	if !verifier.Verified() {
+		return fmt.Errorf("blob %s is corrupted in the local storage", desc.Digest)
+	}
+
+	return nil
+}
+
+// Load loads the model artifacts from the archive saved by Save, or the tar archive of any OCI
+// image layout whose manifests are annotated with the references, into the local storage. The
+// target overrides the reference of the only artifact in the archive. It returns the loaded
+// references.
+func (b *backend) Load(ctx context.Context, cfg *config.Load) ([]string, error) {
+	logrus.Infof("load: loading artifacts from %s", cfg.Input)
+	files, err := readLayoutFiles(cfg.Input, ocispec.ImageLayoutFile, ocispec.ImageIndexFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := files[ocispec.ImageLayoutFile]; !ok {
+		return nil, fmt.Errorf("%s is not an OCI image layout archive, missing %s", cfg.Input, ocispec.ImageLayoutFile)
+	}
+
+	var index ocispec.Index
+	if err := json.Unmarshal(files[ocispec.ImageIndexFile], &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+
+	if cfg.Target != "" && len(index.Manifests) != 1 {
+		return nil, fmt.Errorf("the target can only be specified for the archive of a single artifact, found %d", len(index.Manifests))
+	}
+
+	manifestPaths := []string{}
+	for _, desc := range index.Manifests {
+		manifestPaths = append(manifestPaths, layoutBlobPath(desc.Digest))
+	}
+
+	manifestFiles, err := readLayoutFiles(cfg.Input, manifestPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve the artifacts and the repositories referencing each blob.
+	type artifact struct {
+		name      string
+		repo      string
+		reference string
+		mediaType string
+		raw       []byte
+	}
+
+	artifacts := []artifact{}
+	blobs := map[godigest.Digest]ocispec.Descriptor{}
+	blobRepos := map[godigest.Digest][]string{}
+	for _, desc := range index.Manifests {
+		name := cfg.Target
+		if name == "" {
+			name = desc.Annotations[annotationImageName]
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("manifest %s has no reference in the archive, please specify the target", desc.Digest)
+		}
+
+		ref, err := ParseReference(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse reference %s: %w", name, err)
+		}
+
+		raw, ok := manifestFiles[layoutBlobPath(desc.Digest)]
+		if !ok {
+			return nil, fmt.Errorf("manifest %s is missing in the archive", desc.Digest)
+		}
+
+		if !checksum.Matches(desc.Digest, raw) {
+			return nil, fmt.Errorf("manifest %s is corrupted in the archive", desc.Digest)
+		}
+
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal manifest %s: %w", desc.Digest, err)
+		}
+
+		reference := ref.Tag()
+		if reference == "" {
+			reference = desc.Digest.String()
+		}
+
+		artifacts = append(artifacts, artifact{name: name, repo: ref.Repository(), reference: reference, mediaType: desc.MediaType, raw: raw})
+		for _, blob := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+			blobs[blob.Digest] = blob
+			blobRepos[blob.Digest] = append(blobRepos[blob.Digest], ref.Repository())
+		}
+	}
+
+	// push each blob into the first repository referencing it and mount it into the others.
+	loaded := map[godigest.Digest]bool{}
+	if err := walkLayout(cfg.Input, func(name string, reader io.Reader) error {
+		digest, ok := layoutBlobDigest(name)
+		if !ok || loaded[digest] || len(blobRepos[digest]) == 0 {
+			return nil
+		}
+
+		desc, repos := blobs[digest], blobRepos[digest]
+		exists, err := b.store.StatBlob(ctx, repos[0], digest.String())
+		if err != nil {
+			return fmt.Errorf("failed to stat blob %s: %w", digest, err)
+		}
+
+		if !exists {
+			logrus.Debugf("load: pushing blob %s to %s", digest, repos[0])
+			if _, _, err := b.store.PushBlob(ctx, repos[0], reader, desc); err != nil {
+				return fmt.Errorf("failed to push blob %s: %w", digest, err)
+			}
+		}
+
+		for _, repo := range repos[1:] {
+			if repo == repos[0] {
+				continue
+			}
+
+			if err := b.store.MountBlob(ctx, repos[0], repo, desc); err != nil {
+				return fmt.Errorf("failed to mount blob %s: %w", digest, err)
+			}
+		}
+
+		loaded[digest] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for digest := range blobs {
+		if !loaded[digest] {
+			return nil, fmt.Errorf("blob %s is missing in the archive", digest)
+		}
+	}
+
+	names := []string{}
+	for _, artifact := range artifacts {
+		if _, err := b.store.PushManifest(ctx, artifact.repo, artifact.reference, artifact.mediaType, artifact.raw); err != nil {
+			return nil, fmt.Errorf("failed to push manifest of %s: %w", artifact.name, err)
+		}
+
+		logrus.Infof("load: loaded artifact %s", artifact.name)
+		names = append(names, artifact.name)
+	}
+
+	return names, nil
+}
+
+// writeLayoutFile writes the file of the content to the archive.
+func writeLayoutFile(tw *tar.Writer, name string, content []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(content)),
+	}); err != nil {
+		return fmt.Errorf("failed to write header of %s: %w", name, err)
+	}
+
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// readLayoutFiles reads the files of the names from the archive.
+func readLayoutFiles(archive string, names ...string) (map[string][]byte, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	files := map[string][]byte{}
+	if err := walkLayout(archive, func(name string, reader io.Reader) error {
+		if !wanted[name] {
+			return nil
+		}
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		files[name] = content
+		if len(files) == len(wanted) {
+			return errStopWalk
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// walkLayout walks the regular files of the archive by the cleaned names, the archive is opened
+// by every walk and the skipped content is seeked over without being read.
+func walkLayout(archive string, fn func(name string, reader io.Reader) error) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read archive %s: %w", archive, err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := fn(path.Clean(strings.TrimPrefix(header.Name, "./")), tr); err != nil {
+			if errors.Is(err, errStopWalk) {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
+
+// layoutBlobPath returns the path of the blob in the OCI image layout.
+func layoutBlobPath(digest godigest.Digest) string {
+	return path.Join(ocispec.ImageBlobsDir, digest.Algorithm().String(), digest.Encoded())
+}
+
+// layoutBlobDigest returns the digest of the blob path in the OCI image layout.
+func layoutBlobDigest(name string) (godigest.Digest, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 || parts[0] != ocispec.ImageBlobsDir {
+		return "", false
+	}
+
+	digest := godigest.NewDigestFromEncoded(godigest.Algorithm(parts[1]), parts[2])
+	if digest.Validate() != nil {
+		return "", false
+	}
+
+	return digest, true
+}
@@ -0,0 +1,128 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	ctx := context.Background()
+	store, blobs := newMemoryStore()
+	b := &backend{store: store}
+
+	files := map[string][]byte{
+		"model.safetensors": []byte("weights"),
+		"config.json":       []byte(`{"hidden_size": 4096}`),
+		"README.md":         []byte("# llama3"),
+	}
+	manifestRaw := storeModel(t, b, "example.com/models/llama3", "v1", files, []string{"model.safetensors", "config.json", "README.md"})
+
+	output := filepath.Join(t.TempDir(), "llama3.modelpack")
+	require.NoError(t, b.Save(ctx, "example.com/models/llama3:v1", &config.Save{Output: output}))
+
+	// the archive is an OCI image layout with the layout and index first.
+	file, err := os.Open(output)
+	require.NoError(t, err)
+	defer file.Close()
+
+	names := []string{}
+	var index ocispec.Index
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+
+		if header.Name == ocispec.ImageIndexFile {
+			require.NoError(t, json.NewDecoder(tr).Decode(&index))
+		}
+	}
+
+	require.Len(t, names, 7)
+	assert.Equal(t, []string{ocispec.ImageLayoutFile, ocispec.ImageIndexFile}, names[:2])
+	require.Len(t, index.Manifests, 1)
+	assert.Equal(t, "example.com/models/llama3:v1", index.Manifests[0].Annotations[annotationImageName])
+	assert.Equal(t, "v1", index.Manifests[0].Annotations[ocispec.AnnotationRefName])
+
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(manifestRaw, &manifest))
+
+	assertLoaded := func(t *testing.T, store *storage.Storage, loadBlobs map[string]map[string][]byte, repo, tag string) {
+		loadedRaw, _, err := store.PullManifest(ctx, repo, tag)
+		require.NoError(t, err)
+		assert.Equal(t, manifestRaw, loadedRaw)
+
+		for _, desc := range append([]ocispec.Descriptor{manifest.Config}, manifest.Layers...) {
+			content, ok := loadBlobs[repo][desc.Digest.String()]
+			require.True(t, ok, desc.Digest.String())
+			assert.Equal(t, blobs["example.com/models/llama3"][desc.Digest.String()], content)
+		}
+	}
+
+	t.Run("load", func(t *testing.T) {
+		loadStore, loadBlobs := newMemoryStore()
+		lb := &backend{store: loadStore}
+
+		names, err := lb.Load(ctx, &config.Load{Input: output})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example.com/models/llama3:v1"}, names)
+		assertLoaded(t, loadStore, loadBlobs, "example.com/models/llama3", "v1")
+	})
+
+	t.Run("load as target", func(t *testing.T) {
+		loadStore, loadBlobs := newMemoryStore()
+		lb := &backend{store: loadStore}
+
+		names, err := lb.Load(ctx, &config.Load{Input: output, Target: "registry.internal/models/llama3:v1.0.0"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"registry.internal/models/llama3:v1.0.0"}, names)
+		assertLoaded(t, loadStore, loadBlobs, "registry.internal/models/llama3", "v1.0.0")
+	})
+
+	t.Run("load non-layout", func(t *testing.T) {
+		input := filepath.Join(t.TempDir(), "empty.tar")
+		file, err := os.Create(input)
+		require.NoError(t, err)
+		require.NoError(t, tar.NewWriter(file).Close())
+		require.NoError(t, file.Close())
+
+		_, err = b.Load(ctx, &config.Load{Input: input})
+		assert.ErrorContains(t, err, "is not an OCI image layout archive")
+	})
+
+	t.Run("save missing", func(t *testing.T) {
+		err := b.Save(ctx, "example.com/models/llama3:v2", &config.Save{Output: filepath.Join(t.TempDir(), "missing.modelpack")})
+		assert.ErrorContains(t, err, "failed to pull manifest")
+	})
+}
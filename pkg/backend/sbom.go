@@ -0,0 +1,320 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/checksum"
+	pkgcodec "github.com/modelpack/modctl/pkg/codec"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/encryption"
+	"github.com/modelpack/modctl/pkg/license"
+	"github.com/modelpack/modctl/pkg/sbom"
+	"github.com/modelpack/modctl/pkg/version"
+)
+
+// maxLicenseFileSize is the max size of the license file read to detect the license.
+const maxLicenseFileSize = 1 << 20
+
+// SBOM generates the SBOM document of the model artifact from its manifest, config and the
+// license files, and attaches the document to the model artifact as a referrer if required.
+func (b *backend) SBOM(ctx context.Context, target string, cfg *config.SBOM) ([]byte, error) {
+	logrus.Infof("sbom: generating %s sbom for target %s", cfg.Format, target)
+	ref, err := ParseReference(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target: %w", err)
+	}
+
+	repo, tag := ref.Repository(), ref.Tag()
+	if repo == "" || tag == "" {
+		return nil, fmt.Errorf("invalid repository or tag")
+	}
+
+	var client *remote.Repository
+	if cfg.Remote {
+		client, err = remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create remote client: %w", err)
+		}
+	}
+
+	manifestDesc, manifest, err := b.sbomManifest(ctx, client, repo, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := b.getModelConfig(ctx, target, manifest.Config, cfg.Remote, cfg.PlainHTTP, cfg.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	artifact := &sbom.Artifact{
+		Name:      repo,
+		Version:   tag,
+		Digest:    manifestDesc.Digest,
+		ModelName: model.Descriptor.Name,
+		SourceURL: model.Descriptor.SourceURL,
+		Revision:  model.Descriptor.Revision,
+		Licenses:  append([]string{}, model.Descriptor.Licenses...),
+	}
+
+	if model.Descriptor.CreatedAt != nil {
+		artifact.CreatedAt = *model.Descriptor.CreatedAt
+	}
+
+	for _, layer := range manifest.Layers {
+		files, err := b.sbomFiles(ctx, client, repo, layer)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if file.License != "" {
+				artifact.Licenses = append(artifact.Licenses, file.License)
+			}
+		}
+
+		artifact.Files = append(artifact.Files, files...)
+	}
+
+	doc, err := sbom.Generate(cfg.Format, artifact, version.GitVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sbom: %w", err)
+	}
+
+	if cfg.Attach {
+		if err := attachSBOM(ctx, client, manifestDesc, cfg.Format, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	logrus.Infof("sbom: generated %s sbom for target %s", cfg.Format, target)
+	return doc, nil
+}
+
+// sbomManifest gets the manifest and its descriptor from the local storage, or from the remote
+// if the client is specified.
+func (b *backend) sbomManifest(ctx context.Context, client *remote.Repository, repo, tag string) (ocispec.Descriptor, *ocispec.Manifest, error) {
+	var (
+		desc        ocispec.Descriptor
+		manifestRaw []byte
+	)
+
+	if client == nil {
+		raw, digest, err := b.store.PullManifest(ctx, repo, tag)
+		if err != nil {
+			return desc, nil, fmt.Errorf("failed to pull manifest: %w", err)
+		}
+
+		manifestRaw = raw
+		desc = ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: godigest.Digest(digest), Size: int64(len(raw))}
+	} else {
+		fetched, reader, err := client.Manifests().FetchReference(ctx, tag)
+		if err != nil {
+			return desc, nil, fmt.Errorf("failed to fetch manifest: %w", err)
+		}
+		defer reader.Close()
+
+		raw, err := io.ReadAll(reader)
+		if err != nil {
+			return desc, nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+
+		manifestRaw = raw
+		desc = fetched
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return desc, nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	return desc, &manifest, nil
+}
+
+// sbomFiles returns the files packed in the layer with the digests and the sizes of their content.
+// The content of the raw layer is the file itself, so its digest is the one of the layer, and the
+// files archived in the tar layers are read to compute their digests. The digests of the files in
+// the encrypted layers and the layers of the other codecs are unknown.
+func (b *backend) sbomFiles(ctx context.Context, client *remote.Repository, repo string, layer ocispec.Descriptor) ([]sbom.File, error) {
+	filepath := layerFilepath(layer)
+	codecType := pkgcodec.TypeFromMediaType(layer.MediaType)
+	if _, ok := layer.Annotations[encryption.AnnotationEncryption]; ok || (codecType != pkgcodec.Raw && codecType != pkgcodec.Tar && codecType != pkgcodec.TarGzip) {
+		logrus.Warnf("sbom: skipped computing the digest of %s in the layer %s with media type %s", filepath, layer.Digest, layer.MediaType)
+		return []sbom.File{{Path: filepath, Size: layer.Size, MediaType: layer.MediaType}}, nil
+	}
+
+	// the license file is read to detect the license, the other raw files are not fetched.
+	if codecType == pkgcodec.Raw && !license.IsLicenseFile(filepath) {
+		return []sbom.File{{Path: filepath, Digest: layer.Digest, Size: layer.Size, MediaType: layer.MediaType}}, nil
+	}
+
+	var (
+		reader io.ReadCloser
+		err    error
+	)
+
+	if client == nil {
+		reader, err = b.store.PullBlob(ctx, repo, layer.Digest.String())
+	} else {
+		reader, err = client.Blobs().Fetch(ctx, layer)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blob %s: %w", layer.Digest, err)
+	}
+	defer reader.Close()
+
+	if codecType == pkgcodec.Raw {
+		file, err := sbomFile(filepath, layer.MediaType, reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return []sbom.File{file}, nil
+	}
+
+	var content io.Reader = reader
+	if codecType == pkgcodec.TarGzip {
+		gr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress the layer %s: %w", layer.Digest, err)
+		}
+		defer gr.Close()
+
+		content = gr
+	}
+
+	files := []sbom.File{}
+	tr := tar.NewReader(content)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the layer %s: %w", layer.Digest, err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		file, err := sbomFile(strings.ReplaceAll(header.Name, "\\", "/"), layer.MediaType, tr)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+}
+
+// sbomFile computes the digest and the size of the file content, and detects the license of the
+// file if it is a license file.
+func sbomFile(filepath, mediaType string, r io.Reader) (sbom.File, error) {
+	file := sbom.File{Path: filepath, MediaType: mediaType}
+	digester := godigest.Canonical.Digester()
+
+	var head []byte
+	if license.IsLicenseFile(filepath) {
+		var err error
+		head, err = io.ReadAll(io.LimitReader(r, maxLicenseFileSize))
+		if err != nil {
+			return file, fmt.Errorf("failed to read license file %s: %w", filepath, err)
+		}
+
+		digester.Hash().Write(head)
+		if file.License = license.Detect(head); file.License != "" {
+			logrus.Debugf("sbom: detected license %s from %s", file.License, filepath)
+		}
+	}
+
+	n, err := io.Copy(digester.Hash(), r)
+	if err != nil {
+		return file, fmt.Errorf("failed to read file %s: %w", filepath, err)
+	}
+
+	file.Digest = digester.Digest()
+	file.Size = int64(len(head)) + n
+	return file, nil
+}
+
+// attachSBOM pushes the SBOM document to the remote as an artifact referring to the model artifact.
+func attachSBOM(ctx context.Context, client *remote.Repository, subject ocispec.Descriptor, format string, doc []byte) error {
+	mediaType, err := sbom.MediaType(format)
+	if err != nil {
+		return err
+	}
+
+	emptyConfig := ocispec.DescriptorEmptyJSON
+	if err := pushBlobIfNotExist(ctx, client, emptyConfig, emptyConfig.Data); err != nil {
+		return fmt.Errorf("failed to push empty config: %w", err)
+	}
+
+	docDesc := ocispec.Descriptor{MediaType: mediaType, Digest: checksum.FromBytes(doc), Size: int64(len(doc))}
+	if err := pushBlobIfNotExist(ctx, client, docDesc, doc); err != nil {
+		return fmt.Errorf("failed to push sbom: %w", err)
+	}
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: mediaType,
+		Config:       emptyConfig,
+		Layers:       []ocispec.Descriptor{docDesc},
+		Subject:      &ocispec.Descriptor{MediaType: subject.MediaType, Digest: subject.Digest, Size: subject.Size},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal sbom manifest: %w", err)
+	}
+
+	manifestDesc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: checksum.FromBytes(manifestRaw), Size: int64(len(manifestRaw))}
+	if err := client.Manifests().Push(ctx, manifestDesc, bytes.NewReader(manifestRaw)); err != nil {
+		return fmt.Errorf("failed to push sbom manifest: %w", remote.WrapUnsupportedMediaType(err))
+	}
+
+	logrus.Infof("sbom: attached sbom %s to %s", manifestDesc.Digest, subject.Digest)
+	return nil
+}
+
+// pushBlobIfNotExist pushes the blob to the remote if it does not exist.
+func pushBlobIfNotExist(ctx context.Context, client *remote.Repository, desc ocispec.Descriptor, content []byte) error {
+	exist, err := client.Blobs().Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+
+	if exist {
+		return nil
+	}
+
+	return client.Blobs().Push(ctx, desc, bytes.NewReader(content))
+}
@@ -0,0 +1,175 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/sbom"
+	"github.com/modelpack/modctl/test/mocks/storage"
+)
+
+func TestSBOM(t *testing.T) {
+	ctx := context.Background()
+	repo := "example.com/models/test"
+
+	var tarred bytes.Buffer
+	tw := tar.NewWriter(&tarred)
+	mit := []byte("Permission is hereby granted, free of charge, to any person obtaining a copy")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "LICENSE-MIT", Mode: 0644, Size: int64(len(mit)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(mit)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	blobs := map[godigest.Digest][]byte{}
+	layer := func(mediaType, filepath string, content []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{
+			MediaType:   mediaType,
+			Digest:      godigest.FromBytes(content),
+			Size:        int64(len(content)),
+			Annotations: map[string]string{modelspec.AnnotationFilepath: filepath},
+		}
+		blobs[desc.Digest] = content
+		return desc
+	}
+
+	layers := []ocispec.Descriptor{
+		layer(modelspec.MediaTypeModelWeightRaw, "model.safetensors", []byte("weight")),
+		layer(modelspec.MediaTypeModelWeightConfigRaw, "config.json", []byte("{}")),
+		layer(modelspec.MediaTypeModelDocRaw, "LICENSE", []byte("Apache License\nVersion 2.0, January 2004")),
+		layer(modelspec.MediaTypeModelDoc, "LICENSE-MIT", tarred.Bytes()),
+		layer(modelspec.MediaTypeModelDocRaw, "README.md", []byte("# test")),
+	}
+
+	// the digest of the file archived in the tar layer is the one of the file content.
+	digests := []godigest.Digest{layers[0].Digest, layers[1].Digest, layers[2].Digest, godigest.FromBytes(mit), layers[4].Digest}
+	assert.NotEqual(t, layers[3].Digest, digests[3])
+
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	model := modelspec.Model{
+		Descriptor: modelspec.ModelDescriptor{
+			CreatedAt: &createdAt,
+			Name:      "test",
+			SourceURL: "https://github.com/example/test",
+			Revision:  "abc123",
+		},
+		ModelFS: modelspec.ModelFS{Type: "layers"},
+	}
+	for _, l := range layers {
+		model.ModelFS.DiffIDs = append(model.ModelFS.DiffIDs, l.Digest)
+	}
+
+	configRaw, err := json.Marshal(model)
+	require.NoError(t, err)
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromBytes(configRaw), Size: int64(len(configRaw))}
+	blobs[configDesc.Digest] = configRaw
+
+	manifestRaw, err := json.Marshal(ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Config: configDesc, Layers: layers})
+	require.NoError(t, err)
+	manifestDigest := godigest.FromBytes(manifestRaw)
+
+	mockStore := &storage.Storage{}
+	mockStore.On("PullManifest", ctx, repo, "v1").Return(manifestRaw, manifestDigest.String(), nil)
+	mockStore.On("PullBlob", mock.Anything, repo, mock.Anything).Return(
+		func(ctx context.Context, repo string, digest string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(blobs[godigest.Digest(digest)])), nil
+		},
+		nil,
+	)
+	b := &backend{store: mockStore}
+
+	t.Run("spdx", func(t *testing.T) {
+		cfg := config.NewSBOM()
+		data, err := b.SBOM(ctx, repo+":v1", cfg)
+		require.NoError(t, err)
+
+		var doc struct {
+			Packages []struct {
+				DownloadLocation string `json:"downloadLocation"`
+				LicenseDeclared  string `json:"licenseDeclared"`
+			} `json:"packages"`
+			Files []struct {
+				FileName  string `json:"fileName"`
+				Checksums []struct {
+					Algorithm     string `json:"algorithm"`
+					ChecksumValue string `json:"checksumValue"`
+				} `json:"checksums"`
+			} `json:"files"`
+		}
+		require.NoError(t, json.Unmarshal(data, &doc))
+		require.Len(t, doc.Packages, 1)
+		assert.Equal(t, "https://github.com/example/test", doc.Packages[0].DownloadLocation)
+		assert.Equal(t, "Apache-2.0 AND MIT", doc.Packages[0].LicenseDeclared)
+
+		require.Len(t, doc.Files, len(layers))
+		for i, l := range layers {
+			assert.Equal(t, "./"+l.Annotations[modelspec.AnnotationFilepath], doc.Files[i].FileName)
+			require.Len(t, doc.Files[i].Checksums, 1)
+			assert.Equal(t, "SHA256", doc.Files[i].Checksums[0].Algorithm)
+			assert.Equal(t, digests[i].Encoded(), doc.Files[i].Checksums[0].ChecksumValue)
+		}
+	})
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		cfg := config.NewSBOM()
+		cfg.Format = sbom.FormatCycloneDX
+		data, err := b.SBOM(ctx, repo+":v1", cfg)
+		require.NoError(t, err)
+
+		var bom struct {
+			Metadata struct {
+				Component struct {
+					Hashes []struct {
+						Content string `json:"content"`
+					} `json:"hashes"`
+				} `json:"component"`
+			} `json:"metadata"`
+			Components []struct {
+				Name   string `json:"name"`
+				Hashes []struct {
+					Alg     string `json:"alg"`
+					Content string `json:"content"`
+				} `json:"hashes"`
+			} `json:"components"`
+		}
+		require.NoError(t, json.Unmarshal(data, &bom))
+		require.Len(t, bom.Metadata.Component.Hashes, 1)
+		assert.Equal(t, manifestDigest.Encoded(), bom.Metadata.Component.Hashes[0].Content)
+
+		require.Len(t, bom.Components, len(layers))
+		for i, l := range layers {
+			assert.Equal(t, l.Annotations[modelspec.AnnotationFilepath], bom.Components[i].Name)
+			require.Len(t, bom.Components[i].Hashes, 1)
+			assert.Equal(t, "SHA-256", bom.Components[i].Hashes[0].Alg)
+			assert.Equal(t, digests[i].Encoded(), bom.Components[i].Hashes[0].Content)
+		}
+	})
+}
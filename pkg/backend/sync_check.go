@@ -0,0 +1,166 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// SyncCheckReport is the drift of the tags in the local storage from the remote repository.
+type SyncCheckReport struct {
+	// Repository is the name of the repository.
+	Repository string `json:"repository"`
+	// InSync is the tags referencing the same manifest locally and remotely.
+	InSync []string `json:"inSync"`
+	// Missing is the remote tags which do not exist in the local storage.
+	Missing []string `json:"missing"`
+	// Extra is the local tags which do not exist in the remote repository.
+	Extra []string `json:"extra"`
+	// Divergent is the tags referencing the different manifests locally and remotely.
+	Divergent []*DivergentTag `json:"divergent"`
+}
+
+// DivergentTag is the tag referencing the different manifests locally and remotely.
+type DivergentTag struct {
+	// Tag is the name of the tag.
+	Tag string `json:"tag"`
+	// LocalDigest is the digest of the manifest referenced by the local tag.
+	LocalDigest string `json:"localDigest"`
+	// RemoteDigest is the digest of the manifest referenced by the remote tag.
+	RemoteDigest string `json:"remoteDigest"`
+}
+
+// Drifted returns true if any tag is missing, extra or divergent.
+func (r *SyncCheckReport) Drifted() bool {
+	return len(r.Missing) > 0 || len(r.Extra) > 0 || len(r.Divergent) > 0
+}
+
+// SyncCheck compares the tags of the repository in the local storage with the remote ones by
+// the manifest digests, which reports the missing, extra and divergent tags of the local mirror.
+func (b *backend) SyncCheck(ctx context.Context, repo string, cfg *config.SyncCheck) (*SyncCheckReport, error) {
+	logrus.Infof("sync-check: checking repository %s", repo)
+	ref, err := ParseReference(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the repository: %w", err)
+	}
+
+	if ref.Tag() != "" || ref.Digest() != "" {
+		return nil, fmt.Errorf("the repository %s must not have tag or digest", repo)
+	}
+
+	repo = ref.Repository()
+	localTags, err := b.listLocalTags(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := remote.New(repo, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure), remote.WithProxy(cfg.Proxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the remote client: %w", err)
+	}
+
+	remoteTags, err := listTags(ctx, src, "*")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SyncCheckReport{Repository: repo, InSync: []string{}, Missing: []string{}, Extra: []string{}, Divergent: []*DivergentTag{}}
+	common := []string{}
+	for _, tag := range remoteTags {
+		if slices.Contains(localTags, tag) {
+			common = append(common, tag)
+		} else {
+			report.Missing = append(report.Missing, tag)
+		}
+	}
+
+	for _, tag := range localTags {
+		if !slices.Contains(remoteTags, tag) {
+			report.Extra = append(report.Extra, tag)
+		}
+	}
+
+	// resolve the remote digests concurrently as each of them requires a round trip.
+	compared := make([]*DivergentTag, len(common))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.Concurrency)
+	for i, tag := range common {
+		g.Go(func() error {
+			_, localDigest, err := b.store.PullManifest(gctx, repo, tag)
+			if err != nil {
+				return fmt.Errorf("failed to pull the local manifest of tag %s: %w", tag, err)
+			}
+
+			desc, err := src.Resolve(gctx, tag)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the remote tag %s: %w", tag, err)
+			}
+
+			compared[i] = &DivergentTag{Tag: tag, LocalDigest: localDigest, RemoteDigest: desc.Digest.String()}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for _, tag := range compared {
+		if tag.LocalDigest == tag.RemoteDigest {
+			report.InSync = append(report.InSync, tag.Tag)
+		} else {
+			report.Divergent = append(report.Divergent, tag)
+		}
+	}
+
+	sort.Strings(report.InSync)
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+	sort.Slice(report.Divergent, func(i, j int) bool { return report.Divergent[i].Tag < report.Divergent[j].Tag })
+
+	logrus.Infof("sync-check: checked repository %s [inSync: %d, missing: %d, extra: %d, divergent: %d]", repo, len(report.InSync), len(report.Missing), len(report.Extra), len(report.Divergent))
+	return report, nil
+}
+
+// listLocalTags lists the tags of the repository in the local storage, which is empty if the
+// repository does not exist locally.
+func (b *backend) listLocalTags(ctx context.Context, repo string) ([]string, error) {
+	repos, err := b.store.ListRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	if !slices.Contains(repos, repo) {
+		return []string{}, nil
+	}
+
+	tags, err := b.store.ListTags(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags in repository %s: %w", repo, err)
+	}
+
+	return tags, nil
+}
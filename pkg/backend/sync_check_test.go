@@ -0,0 +1,71 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+func TestSyncCheck(t *testing.T) {
+	ctx := context.Background()
+	server, contents := newMemoryRegistry(t)
+	repo := strings.TrimPrefix(server.URL, "http://") + "/models/llama3"
+
+	store, _ := newMemoryStore()
+	b := &backend{store: store}
+	files := map[string][]byte{"model.safetensors": []byte("weight")}
+	v1Raw := storeModel(t, b, repo, "v1", files, []string{"model.safetensors"})
+	v2Raw := storeModel(t, b, repo, "v2", files, []string{"model.safetensors"})
+	storeModel(t, b, repo, "local", files, []string{"model.safetensors"})
+	store.On("ListRepositories", mock.Anything).Return([]string{repo}, nil)
+	store.On("ListTags", mock.Anything, repo).Return([]string{"local", "v1", "v2"}, nil)
+
+	// v1 is in sync, v2 is retagged remotely and v3 is not mirrored.
+	contents["models/llama3/manifests/v1"] = v1Raw
+	contents["models/llama3/manifests/"+godigest.FromBytes(v1Raw).String()] = v1Raw
+	serveModel(t, contents, "models/llama3", "v2", []remoteFile{{name: "model.safetensors", content: []byte("retrained")}})
+	serveModel(t, contents, "models/llama3", "v3", []remoteFile{{name: "model.safetensors", content: []byte("v3")}})
+
+	cfg := config.NewSyncCheck()
+	cfg.PlainHTTP = true
+	report, err := b.SyncCheck(ctx, repo, cfg)
+	require.NoError(t, err)
+
+	assert.True(t, report.Drifted())
+	assert.Equal(t, repo, report.Repository)
+	assert.Equal(t, []string{"v1"}, report.InSync)
+	assert.Equal(t, []string{"v3"}, report.Missing)
+	assert.Equal(t, []string{"local"}, report.Extra)
+	require.Len(t, report.Divergent, 1)
+	assert.Equal(t, "v2", report.Divergent[0].Tag)
+	assert.Equal(t, godigest.FromBytes(v2Raw).String(), report.Divergent[0].LocalDigest)
+	assert.Equal(t, godigest.FromBytes(contents["models/llama3/manifests/v2"]).String(), report.Divergent[0].RemoteDigest)
+
+	t.Run("tagged repository", func(t *testing.T) {
+		_, err := b.SyncCheck(ctx, repo+":v1", cfg)
+		assert.ErrorContains(t, err, "must not have tag or digest")
+	})
+}
@@ -64,7 +64,7 @@ func (b *backend) Tag(ctx context.Context, source, target string) error {
 		logrus.Debugf("tag: successfully mounted blob %s", layer.Digest.String())
 	}
 
-	if _, err := b.store.PushManifest(ctx, targetRef.Repository(), targetRef.Tag(), manifestRaw); err != nil {
+	if _, err := b.store.PushManifest(ctx, targetRef.Repository(), targetRef.Tag(), manifest.MediaType, manifestRaw); err != nil {
 		return fmt.Errorf("failed to push manifest: %w", err)
 	}
 
@@ -73,7 +73,7 @@ func TestTag(t *testing.T) {
 						Return(nil)
 				}
 
-				s.On("PushManifest", mock.Anything, "localhost:5000/repo", "tag2", manifestBytes).
+				s.On("PushManifest", mock.Anything, "localhost:5000/repo", "tag2", "", manifestBytes).
 					Return("sha256:manifest", nil)
 			},
 			expectedErr: "",
@@ -155,7 +155,7 @@ func TestTag(t *testing.T) {
 				s.On("MountBlob", mock.Anything, "localhost:5000/repo", "localhost:5000/repo", manifest.Config).
 					Return(nil)
 
-				s.On("PushManifest", mock.Anything, "localhost:5000/repo", "tag2", manifestBytes).
+				s.On("PushManifest", mock.Anything, "localhost:5000/repo", "tag2", "", manifestBytes).
 					Return("", errors.New("push manifest failed"))
 			},
 			expectedErr: "failed to push manifest",
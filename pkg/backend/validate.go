@@ -0,0 +1,152 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"fmt"
+
+	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+const (
+	// modelFSTypeLayers is the only supported type of the model filesystem.
+	modelFSTypeLayers = "layers"
+)
+
+// weightMediaTypes is the media types of the model weight layers.
+var weightMediaTypes = map[string]struct{}{
+	modelspec.MediaTypeModelWeightRaw:        {},
+	modelspec.MediaTypeModelWeight:           {},
+	modelspec.MediaTypeModelWeightGzip:       {},
+	modelspec.MediaTypeModelWeightZstd:       {},
+	legacymodelspec.MediaTypeModelWeightRaw:  {},
+	legacymodelspec.MediaTypeModelWeight:     {},
+	legacymodelspec.MediaTypeModelWeightGzip: {},
+	legacymodelspec.MediaTypeModelWeightZstd: {},
+}
+
+// validateModelArtifact validates the manifest and the model config against the model spec,
+// it returns the list of the problems found, or empty if the model artifact is well-formed.
+func validateModelArtifact(manifest *ocispec.Manifest, model *modelspec.Model) []string {
+	problems := validateManifest(manifest)
+	if model != nil {
+		problems = append(problems, validateModelConfig(manifest, model)...)
+	}
+
+	return problems
+}
+
+// validateManifest validates the config and layer descriptors of the manifest.
+func validateManifest(manifest *ocispec.Manifest) []string {
+	problems := []string{}
+	if manifest.Config.MediaType != modelspec.MediaTypeModelConfig && manifest.Config.MediaType != legacymodelspec.MediaTypeModelConfig {
+		problems = append(problems, fmt.Sprintf("manifest: unexpected config media type %q", manifest.Config.MediaType))
+	}
+
+	if err := manifest.Config.Digest.Validate(); err != nil {
+		problems = append(problems, fmt.Sprintf("manifest: invalid config digest %q: %s", manifest.Config.Digest, err))
+	}
+
+	filepaths := map[string]int{}
+	for i, layer := range manifest.Layers {
+		if layer.MediaType == "" {
+			problems = append(problems, fmt.Sprintf("manifest: layer %d has no media type", i))
+		}
+
+		if err := layer.Digest.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("manifest: layer %d has invalid digest %q: %s", i, layer.Digest, err))
+		}
+
+		if layer.Size < 0 {
+			problems = append(problems, fmt.Sprintf("manifest: layer %d has negative size %d", i, layer.Size))
+		}
+
+		filepath := layer.Annotations[modelspec.AnnotationFilepath]
+		if filepath == "" {
+			filepath = layer.Annotations[legacymodelspec.AnnotationFilepath]
+		}
+
+		if filepath == "" {
+			problems = append(problems, fmt.Sprintf("manifest: layer %d has no filepath annotation", i))
+			continue
+		}
+
+		if j, ok := filepaths[filepath]; ok {
+			problems = append(problems, fmt.Sprintf("manifest: layer %d and %d have the same filepath %q", j, i, filepath))
+			continue
+		}
+		filepaths[filepath] = i
+	}
+
+	if expected := manifest.Annotations[checksum.AnnotationMerkleRoot]; expected != "" {
+		if actual := merkleRoot(manifest.Layers); actual != expected {
+			problems = append(problems, fmt.Sprintf("manifest: merkle root %s does not match the layers, got %s", expected, actual))
+		}
+	}
+
+	return problems
+}
+
+// validateModelConfig validates the required fields of the model config and checks
+// the diff ids are consistent with the layers of the manifest.
+func validateModelConfig(manifest *ocispec.Manifest, model *modelspec.Model) []string {
+	problems := []string{}
+	if model.ModelFS.Type != modelFSTypeLayers {
+		problems = append(problems, fmt.Sprintf("config: modelfs.type must be %q, but got %q", modelFSTypeLayers, model.ModelFS.Type))
+	}
+
+	hasWeights := false
+	for _, layer := range manifest.Layers {
+		if _, ok := weightMediaTypes[layer.MediaType]; ok {
+			hasWeights = true
+			break
+		}
+	}
+
+	if len(model.ModelFS.DiffIDs) == 0 {
+		if hasWeights {
+			problems = append(problems, "config: modelfs.diffIds must not be empty for the model with weights")
+		}
+
+		return problems
+	}
+
+	diffIDs := make(map[godigest.Digest]struct{}, len(model.ModelFS.DiffIDs))
+	for i, diffID := range model.ModelFS.DiffIDs {
+		if err := diffID.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("config: modelfs.diffIds[%d] is invalid %q: %s", i, diffID, err))
+		}
+		diffIDs[diffID] = struct{}{}
+	}
+
+	if len(model.ModelFS.DiffIDs) != len(manifest.Layers) {
+		problems = append(problems, fmt.Sprintf("config: modelfs.diffIds has %d entries, but the manifest has %d layers", len(model.ModelFS.DiffIDs), len(manifest.Layers)))
+	}
+
+	for i, layer := range manifest.Layers {
+		if _, ok := diffIDs[layer.Digest]; !ok {
+			problems = append(problems, fmt.Sprintf("config: layer %d digest %s is missing in modelfs.diffIds", i, layer.Digest))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,121 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"encoding/json"
+	"testing"
+
+	modelspec "github.com/modelpack/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateModelArtifact(t *testing.T) {
+	weight := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelWeightRaw,
+		Digest:      godigest.FromString("weight"),
+		Size:        6,
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "model.safetensors"},
+	}
+	doc := ocispec.Descriptor{
+		MediaType:   modelspec.MediaTypeModelDocRaw,
+		Digest:      godigest.FromString("doc"),
+		Size:        3,
+		Annotations: map[string]string{modelspec.AnnotationFilepath: "README.md"},
+	}
+	configDesc := ocispec.Descriptor{MediaType: modelspec.MediaTypeModelConfig, Digest: godigest.FromString("config"), Size: 6}
+
+	testCases := []struct {
+		name     string
+		manifest ocispec.Manifest
+		config   string
+		expected []string
+	}{
+		{
+			name:     "valid model artifact",
+			manifest: ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{weight, doc}},
+			config:   `{"modelfs":{"type":"layers","diffIds":["` + weight.Digest.String() + `","` + doc.Digest.String() + `"]}}`,
+			expected: []string{},
+		},
+		{
+			name:     "empty diff ids without weights",
+			manifest: ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{doc}},
+			config:   `{"modelfs":{"type":"layers","diffIds":null}}`,
+			expected: []string{},
+		},
+		{
+			name:     "missing modelfs",
+			manifest: ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{weight}},
+			config:   `{"descriptor":{"name":"test"}}`,
+			expected: []string{
+				`config: modelfs.type must be "layers", but got ""`,
+				"config: modelfs.diffIds must not be empty for the model with weights",
+			},
+		},
+		{
+			name:     "unexpected modelfs type",
+			manifest: ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{doc}},
+			config:   `{"modelfs":{"type":"rootfs","diffIds":["` + doc.Digest.String() + `"]}}`,
+			expected: []string{`config: modelfs.type must be "layers", but got "rootfs"`},
+		},
+		{
+			name:     "diff ids mismatch layers",
+			manifest: ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{weight, doc}},
+			config:   `{"modelfs":{"type":"layers","diffIds":["` + weight.Digest.String() + `","sha256:invalid"]}}`,
+			expected: []string{
+				`config: modelfs.diffIds[1] is invalid "sha256:invalid": invalid checksum digest length`,
+				"config: layer 1 digest " + doc.Digest.String() + " is missing in modelfs.diffIds",
+			},
+		},
+		{
+			name:     "diff ids count mismatch",
+			manifest: ocispec.Manifest{Config: configDesc, Layers: []ocispec.Descriptor{weight}},
+			config:   `{"modelfs":{"type":"layers","diffIds":["` + weight.Digest.String() + `","` + doc.Digest.String() + `"]}}`,
+			expected: []string{"config: modelfs.diffIds has 2 entries, but the manifest has 1 layers"},
+		},
+		{
+			name: "malformed manifest",
+			manifest: ocispec.Manifest{
+				Config: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: configDesc.Digest},
+				Layers: []ocispec.Descriptor{
+					{Digest: doc.Digest, Size: -1, Annotations: map[string]string{modelspec.AnnotationFilepath: "README.md"}},
+					doc,
+					{MediaType: modelspec.MediaTypeModelDocRaw, Digest: doc.Digest},
+				},
+			},
+			config: `{"modelfs":{"type":"layers","diffIds":["` + doc.Digest.String() + `","` + doc.Digest.String() + `","` + doc.Digest.String() + `"]}}`,
+			expected: []string{
+				`manifest: unexpected config media type "application/vnd.oci.image.config.v1+json"`,
+				"manifest: layer 0 has no media type",
+				"manifest: layer 0 has negative size -1",
+				`manifest: layer 0 and 1 have the same filepath "README.md"`,
+				"manifest: layer 2 has no filepath annotation",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var model modelspec.Model
+			require.NoError(t, json.Unmarshal([]byte(tc.config), &model))
+			assert.Equal(t, tc.expected, validateModelArtifact(&tc.manifest, &model))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/modelpack/modctl/pkg/backend/remote"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// Identity is the identity authenticated by the registry.
+type Identity struct {
+	// Registry is the registry authenticated with.
+	Registry string `json:"registry"`
+	// Anonymous is true if no credential is sent to the registry.
+	Anonymous bool `json:"anonymous"`
+	// Account is the account authenticated by the registry, which is the subject of the token if
+	// it is a JWT, otherwise the username of the credential.
+	Account string `json:"account,omitempty"`
+	// Scheme is the auth scheme challenged by the registry, empty if the registry requires no auth.
+	Scheme string `json:"scheme,omitempty"`
+	// Scopes is the access granted by the token, such as repository:models/llama3:pull.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// tokenClaims is the claims of the registry token in the JWT format issued by the distribution
+// compatible token servers.
+type tokenClaims struct {
+	Subject string `json:"sub"`
+	Access  []struct {
+		Type    string   `json:"type"`
+		Name    string   `json:"name"`
+		Actions []string `json:"actions"`
+	} `json:"access"`
+}
+
+// Whoami pings the registry with the credential used by the other commands, and reports the
+// identity authenticated by the token request.
+func (b *backend) Whoami(ctx context.Context, registry string, cfg *config.Whoami) (*Identity, error) {
+	logrus.Infof("whoami: authenticating with registry %s", registry)
+	reg, err := remote.NewRegistry(registry, remote.WithPlainHTTP(cfg.PlainHTTP), remote.WithInsecure(cfg.Insecure), remote.WithProxy(cfg.Proxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the remote client: %w", err)
+	}
+
+	client, ok := reg.Client.(*auth.Client)
+	if !ok {
+		return nil, fmt.Errorf("the remote client of registry %s is not an auth client", registry)
+	}
+
+	// record the credential resolved for the registry, the ping is the only request.
+	var credential auth.Credential
+	resolve := client.Credential
+	client.Credential = func(ctx context.Context, hostport string) (auth.Credential, error) {
+		cred, err := resolve(ctx, hostport)
+		credential = cred
+		return cred, err
+	}
+
+	if err := reg.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("failed to authenticate with registry %s: %w", registry, err)
+	}
+
+	identity := &Identity{
+		Registry:  registry,
+		Anonymous: credential == auth.EmptyCredential,
+		Account:   credential.Username,
+	}
+
+	host := reg.Reference.Host()
+	scheme, err := client.Cache.GetScheme(ctx, host)
+	if err != nil || scheme == auth.SchemeUnknown {
+		logrus.Infof("whoami: registry %s requires no auth", registry)
+		return identity, nil
+	}
+
+	identity.Scheme = scheme.String()
+	if scheme == auth.SchemeBearer {
+		// the token of the ping is cached without any scope.
+		token, err := client.Cache.GetToken(ctx, host, scheme, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token: %w", err)
+		}
+
+		if claims, err := parseTokenClaims(token); err != nil {
+			logrus.Debugf("whoami: token of registry %s is opaque: %v", registry, err)
+		} else {
+			if claims.Subject != "" {
+				identity.Account = claims.Subject
+			}
+
+			for _, access := range claims.Access {
+				identity.Scopes = append(identity.Scopes, fmt.Sprintf("%s:%s:%s", access.Type, access.Name, strings.Join(access.Actions, ",")))
+			}
+		}
+	}
+
+	logrus.Infof("whoami: authenticated with registry %s [account: %s, anonymous: %t]", registry, identity.Account, identity.Anonymous)
+	return identity, nil
+}
+
+// parseTokenClaims decodes the claims of the token in the JWT format without verifying the
+// signature, which is only used for display.
+func parseTokenClaims(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token payload: %w", err)
+	}
+
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token claims: %w", err)
+	}
+
+	return &claims, nil
+}
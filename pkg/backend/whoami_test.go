@@ -0,0 +1,134 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backend
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// newTokenRegistry serves a registry challenging the bearer auth, whose token server issues the
+// JWT for the account of the basic auth or the anonymous token without subject.
+func newTokenRegistry(t *testing.T) *httptest.Server {
+	jwt := func(claims map[string]any) string {
+		payload, err := json.Marshal(claims)
+		require.NoError(t, err)
+		encode := base64.RawURLEncoding.EncodeToString
+		return encode([]byte(`{"alg":"none"}`)) + "." + encode(payload) + ".signature"
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			claims := map[string]any{}
+			if username, password, ok := r.BasicAuth(); ok {
+				if username != "alice" || password != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				claims["sub"] = username
+				claims["access"] = []map[string]any{{"type": "registry", "name": "catalog", "actions": []string{"*"}}}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"token":%q}`, jwt(claims))
+		case "/v2/":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// setDockerAuth points the docker config to the temporary file with the credential of the registry.
+func setDockerAuth(t *testing.T, registry, username, password string) {
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	content := fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`, registry, auth)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0600))
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+func TestWhoami(t *testing.T) {
+	server := newTokenRegistry(t)
+	registry := strings.TrimPrefix(server.URL, "http://")
+	cfg := config.NewWhoami()
+	cfg.PlainHTTP = true
+	b := &backend{}
+
+	t.Run("authenticated", func(t *testing.T) {
+		setDockerAuth(t, registry, "alice", "secret")
+		identity, err := b.Whoami(context.Background(), registry, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, &Identity{
+			Registry: registry,
+			Account:  "alice",
+			Scheme:   "Bearer",
+			Scopes:   []string{"registry:catalog:*"},
+		}, identity)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		t.Setenv("DOCKER_CONFIG", t.TempDir())
+		identity, err := b.Whoami(context.Background(), registry, cfg)
+		require.NoError(t, err)
+		assert.True(t, identity.Anonymous)
+		assert.Empty(t, identity.Account)
+		assert.Equal(t, "Bearer", identity.Scheme)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		setDockerAuth(t, registry, "alice", "wrong")
+		_, err := b.Whoami(context.Background(), registry, cfg)
+		assert.ErrorContains(t, err, "failed to authenticate")
+	})
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"bob","access":[{"type":"repository","name":"models/llama3","actions":["pull","push"]}]}`))
+	claims, err := parseTokenClaims("header." + payload + ".signature")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", claims.Subject)
+	require.Len(t, claims.Access, 1)
+	assert.Equal(t, []string{"pull", "push"}, claims.Access[0].Actions)
+
+	_, err = parseTokenClaims("opaque-token")
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultThreshold is the default number of consecutive failures to trip the breaker.
+	DefaultThreshold = 5
+
+	// DefaultCooldown is the default duration the breaker keeps open after tripped.
+	DefaultCooldown = 5 * time.Second
+
+	// DefaultMaxCooldown is the default upper bound of the cooldown, the cooldown
+	// doubles every time the probe fails.
+	DefaultMaxCooldown = 60 * time.Second
+)
+
+// state is the state of the breaker.
+type state int
+
+const (
+	// stateClosed allows all the attempts.
+	stateClosed state = iota
+	// stateOpen blocks all the attempts until the cooldown expires.
+	stateOpen
+	// stateHalfOpen allows only one probe attempt at a time.
+	stateHalfOpen
+)
+
+// Option is the option of the breaker.
+type Option func(*Breaker)
+
+// WithThreshold sets the number of consecutive failures to trip the breaker.
+func WithThreshold(threshold int) Option {
+	return func(b *Breaker) {
+		b.threshold = threshold
+	}
+}
+
+// WithCooldown sets the initial and the maximum cooldown of the breaker.
+func WithCooldown(cooldown, maxCooldown time.Duration) Option {
+	return func(b *Breaker) {
+		b.initialCooldown = cooldown
+		b.maxCooldown = maxCooldown
+	}
+}
+
+// WithRetryable sets the classifier of the errors counted as the failures of the remote, such
+// as remote.IsRetryable. The other errors, e.g. the missing content, are the responses of a
+// healthy remote, so they neither trip nor reset the breaker. All the errors count by default.
+func WithRetryable(retryable func(error) bool) Option {
+	return func(b *Breaker) {
+		b.retryable = retryable
+	}
+}
+
+// Breaker is the retry budget shared across the concurrent transfers of an operation.
+// Create one per operation (push/pull/build) and pass it to each goroutine. When the
+// transfers fail consecutively, the breaker trips and blocks all the transfers for a
+// cooldown, then lets one probe through before the others resume, which avoids the
+// thundering herd of retries against a flapping registry.
+type Breaker struct {
+	operation       string
+	threshold       int
+	initialCooldown time.Duration
+	maxCooldown     time.Duration
+	retryable       func(error) bool
+
+	mu        sync.Mutex
+	state     state
+	failures  int
+	cooldown  time.Duration
+	openUntil time.Time
+	probing   bool
+	// changed is closed and replaced when the state changes to wake up the waiters.
+	changed chan struct{}
+}
+
+// New creates a new breaker for the operation.
+func New(operation string, opts ...Option) *Breaker {
+	b := &Breaker{
+		operation:       operation,
+		threshold:       DefaultThreshold,
+		initialCooldown: DefaultCooldown,
+		maxCooldown:     DefaultMaxCooldown,
+		changed:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.cooldown = b.initialCooldown
+	return b
+}
+
+// Do waits until the breaker allows the attempt, then runs the fn and records its result.
+// Place this inside retry.Do so each retry attempt is guarded by the breaker. A nil breaker
+// runs the fn directly.
+func (b *Breaker) Do(ctx context.Context, fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+
+	if err := b.wait(ctx); err != nil {
+		return err
+	}
+
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// wait blocks until the breaker is closed or the probe is allowed.
+func (b *Breaker) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if b.state == stateOpen && !time.Now().Before(b.openUntil) {
+			b.transition(stateHalfOpen)
+		}
+
+		var timer <-chan time.Time
+		switch b.state {
+		case stateClosed:
+			b.mu.Unlock()
+			return nil
+		case stateHalfOpen:
+			if !b.probing {
+				b.probing = true
+				b.mu.Unlock()
+				return nil
+			}
+		case stateOpen:
+			timer = time.After(time.Until(b.openUntil))
+		}
+
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-timer:
+		}
+	}
+}
+
+// record records the result of the attempt.
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The cancellation and the non-retryable errors are not the failures of the remote.
+	if errors.Is(err, context.Canceled) || (err != nil && b.retryable != nil && !b.retryable(err)) {
+		if b.state == stateHalfOpen && b.probing {
+			b.probing = false
+			b.notify()
+		}
+
+		return
+	}
+
+	if err == nil {
+		b.failures = 0
+		if b.state != stateClosed {
+			logrus.Infof("%s: retry breaker closed", b.operation)
+			b.cooldown = b.initialCooldown
+			b.transition(stateClosed)
+		}
+
+		return
+	}
+
+	b.failures++
+	switch b.state {
+	case stateHalfOpen:
+		b.cooldown = min(b.cooldown*2, b.maxCooldown)
+		b.trip()
+	case stateClosed:
+		if b.failures >= b.threshold {
+			b.trip()
+		}
+	}
+}
+
+// trip opens the breaker for the cooldown.
+func (b *Breaker) trip() {
+	logrus.Warnf("%s: retry breaker opened after %d consecutive failures, backing off for %s", b.operation, b.failures, b.cooldown)
+	b.openUntil = time.Now().Add(b.cooldown)
+	b.transition(stateOpen)
+}
+
+// transition changes the state of the breaker and wakes up the waiters.
+func (b *Breaker) transition(s state) {
+	b.state = s
+	b.probing = false
+	b.notify()
+}
+
+// notify wakes up the waiters.
+func (b *Breaker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,182 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	retry "github.com/avast/retry-go/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFlapping = errors.New("503 service unavailable")
+
+// simulateBroadFailure runs the concurrent transfers against a remote which always
+// fails within the window, and returns the number of requests hitting the remote.
+func simulateBroadFailure(t *testing.T, b *Breaker, window time.Duration) int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), window)
+	defer cancel()
+
+	var requests atomic.Int64
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = retry.Do(func() error {
+				return b.Do(ctx, func() error {
+					requests.Add(1)
+					return errFlapping
+				})
+			}, retry.Attempts(0), retry.Delay(time.Millisecond), retry.DelayType(retry.FixedDelay), retry.Context(ctx))
+		}()
+	}
+
+	wg.Wait()
+	return requests.Load()
+}
+
+func TestBreakerReducesRequestRate(t *testing.T) {
+	window := 300 * time.Millisecond
+
+	// nil breaker does not guard the transfers.
+	unguarded := simulateBroadFailure(t, nil, window)
+	guarded := simulateBroadFailure(t, New("test", WithThreshold(4), WithCooldown(50*time.Millisecond, 100*time.Millisecond)), window)
+
+	t.Logf("requests without breaker: %d, with breaker: %d", unguarded, guarded)
+	require.Greater(t, unguarded, int64(100))
+	// the breaker allows the threshold plus one probe per cooldown, and a few
+	// in-flight attempts racing with the trip.
+	assert.Less(t, guarded, int64(30))
+}
+
+func TestBreakerTripAndRecover(t *testing.T) {
+	b := New("test", WithThreshold(2), WithCooldown(20*time.Millisecond, 40*time.Millisecond))
+	ctx := context.Background()
+
+	require.ErrorIs(t, b.Do(ctx, func() error { return errFlapping }), errFlapping)
+	require.ErrorIs(t, b.Do(ctx, func() error { return errFlapping }), errFlapping)
+	assert.Equal(t, stateOpen, b.state)
+
+	// the attempt is blocked until the cooldown expires.
+	start := time.Now()
+	require.NoError(t, b.Do(ctx, func() error { return nil }))
+	assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+	assert.Equal(t, stateClosed, b.state)
+	assert.Equal(t, 0, b.failures)
+}
+
+func TestBreakerCooldownBackoff(t *testing.T) {
+	b := New("test", WithThreshold(1), WithCooldown(10*time.Millisecond, 25*time.Millisecond))
+	ctx := context.Background()
+
+	_ = b.Do(ctx, func() error { return errFlapping })
+	assert.Equal(t, 10*time.Millisecond, b.cooldown)
+
+	// the failed probe doubles the cooldown.
+	_ = b.Do(ctx, func() error { return errFlapping })
+	assert.Equal(t, 20*time.Millisecond, b.cooldown)
+
+	// the cooldown is capped by the max cooldown.
+	_ = b.Do(ctx, func() error { return errFlapping })
+	assert.Equal(t, 25*time.Millisecond, b.cooldown)
+
+	// the successful probe resets the cooldown.
+	require.NoError(t, b.Do(ctx, func() error { return nil }))
+	assert.Equal(t, 10*time.Millisecond, b.cooldown)
+}
+
+func TestBreakerSingleProbe(t *testing.T) {
+	b := New("test", WithThreshold(1), WithCooldown(10*time.Millisecond, 10*time.Millisecond))
+	ctx := context.Background()
+	_ = b.Do(ctx, func() error { return errFlapping })
+
+	var inflight, maxInflight atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = b.Do(ctx, func() error {
+				n := inflight.Add(1)
+				for {
+					m := maxInflight.Load()
+					if n <= m || maxInflight.CompareAndSwap(m, n) {
+						break
+					}
+				}
+
+				<-release
+				inflight.Add(-1)
+				return nil
+			})
+		}()
+	}
+
+	// only the probe runs until it succeeds.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), maxInflight.Load())
+	close(release)
+	wg.Wait()
+}
+
+func TestBreakerContextCanceled(t *testing.T) {
+	b := New("test", WithThreshold(1), WithCooldown(time.Minute, time.Minute))
+	_ = b.Do(context.Background(), func() error { return errFlapping })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	called := false
+	err := b.Do(ctx, func() error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, called)
+}
+
+func TestBreakerRetryable(t *testing.T) {
+	errNotFound := errors.New("404 not found")
+	b := New("test", WithThreshold(2), WithCooldown(time.Minute, time.Minute), WithRetryable(func(err error) bool {
+		return !errors.Is(err, errNotFound)
+	}))
+	ctx := context.Background()
+
+	// the non-retryable errors neither trip nor reset the breaker.
+	require.ErrorIs(t, b.Do(ctx, func() error { return errFlapping }), errFlapping)
+	for range 3 {
+		require.ErrorIs(t, b.Do(ctx, func() error { return errNotFound }), errNotFound)
+	}
+	assert.Equal(t, stateClosed, b.state)
+	assert.Equal(t, 1, b.failures)
+
+	require.ErrorIs(t, b.Do(ctx, func() error { return errFlapping }), errFlapping)
+	assert.Equal(t, stateOpen, b.state)
+}
+
+func TestNilBreaker(t *testing.T) {
+	var b *Breaker
+	assert.ErrorIs(t, b.Do(context.Background(), func() error { return errFlapping }), errFlapping)
+}
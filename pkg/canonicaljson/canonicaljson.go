@@ -0,0 +1,66 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package canonicaljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Marshal returns the canonical JSON encoding of v, the keys of the objects are sorted, the
+// characters are not HTML-escaped and there is no insignificant whitespace, so the encoding is
+// deterministic regardless of the field order of the types and the version of Go.
+func Marshal(v any) ([]byte, error) {
+	return MarshalIndent(v, 0)
+}
+
+// MarshalIndent is like Marshal but indents the nested elements by the number of spaces, the
+// encoding is compact if the indent is zero.
+func MarshalIndent(v any, indent int) ([]byte, error) {
+	if indent < 0 {
+		return nil, fmt.Errorf("invalid indent %d, must not be negative", indent)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode into the generic values whose object keys are sorted on encoding, the numbers are
+	// kept as is instead of being converted to float64.
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if indent > 0 {
+		encoder.SetIndent("", strings.Repeat(" ", indent))
+	}
+
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
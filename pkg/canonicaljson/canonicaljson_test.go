@@ -0,0 +1,117 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package canonicaljson
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	legacymodelspec "github.com/dragonflyoss/model-spec/specs-go/v1"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{
+			name:     "sorted keys",
+			value:    struct{ B, A int }{B: 1, A: 2},
+			expected: `{"A":2,"B":1}`,
+		},
+		{
+			name:     "nested objects",
+			value:    map[string]any{"z": map[string]any{"y": []any{map[string]any{"b": true, "a": nil}}}, "a": "x"},
+			expected: `{"a":"x","z":{"y":[{"a":null,"b":true}]}}`,
+		},
+		{
+			name:     "no html escaping",
+			value:    map[string]string{"key": "<a&b>"},
+			expected: `{"key":"<a&b>"}`,
+		},
+		{
+			name:     "large numbers",
+			value:    map[string]int64{"size": 1<<62 + 1},
+			expected: `{"size":4611686018427387905}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Marshal(tc.value)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	data, err := MarshalIndent(map[string]any{"b": []int{1}, "a": "x"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": \"x\",\n  \"b\": [\n    1\n  ]\n}", string(data))
+
+	_, err = MarshalIndent(nil, -1)
+	assert.ErrorContains(t, err, "invalid indent")
+}
+
+func TestMarshalDeterministic(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	reasoning := true
+	model := legacymodelspec.Model{
+		Descriptor: legacymodelspec.ModelDescriptor{CreatedAt: &createdAt, Name: "llama3", Family: "llama"},
+		ModelFS:    legacymodelspec.ModelFS{Type: "layers", DiffIDs: []godigest.Digest{godigest.FromString("weights")}},
+		Config:     legacymodelspec.ModelConfig{Format: "safetensors", Capabilities: &legacymodelspec.ModelCapabilities{Reasoning: &reasoning}},
+	}
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: legacymodelspec.ArtifactTypeModelManifest,
+		Config:       ocispec.Descriptor{MediaType: legacymodelspec.MediaTypeModelConfig, Digest: godigest.FromString("config"), Size: 6},
+		Layers: []ocispec.Descriptor{{
+			MediaType:   legacymodelspec.MediaTypeModelWeightRaw,
+			Digest:      godigest.FromString("weights"),
+			Size:        7,
+			Annotations: map[string]string{legacymodelspec.AnnotationFilepath: "model.safetensors", "z": "1", "a": "2"},
+		}},
+		Annotations: map[string]string{"org.example.b": "1", "org.example.a": "2"},
+	}
+
+	for _, value := range []any{model, manifest} {
+		expected, err := Marshal(value)
+		require.NoError(t, err)
+
+		for range 10 {
+			data, err := Marshal(value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, data)
+		}
+
+		// the encoding of the generic value with the same content is byte-identical.
+		var generic map[string]any
+		require.NoError(t, json.Unmarshal(expected, &generic))
+		data, err := Marshal(generic)
+		require.NoError(t, err)
+		assert.Equal(t, expected, data)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package checksum provides the fast non-cryptographic checksum of the blobs,
+// which is only used to detect the corruption of the local storage quickly,
+// the sha256 digest is still the canonical identity of the blobs. It also provides
+// the Merkle root over the layer digests as the compact integrity token of the artifact.
+package checksum
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const (
+	// AnnotationFastChecksum is the annotation key of the layer descriptor which
+	// stores the fast checksum of the layer blob.
+	AnnotationFastChecksum = "org.cncf.modctl.checksum.fast"
+
+	// AlgorithmXXH64 is the algorithm of the xxhash 64-bit checksum.
+	AlgorithmXXH64 = "xxh64"
+)
+
+// ErrUnsupportedAlgorithm is returned when the algorithm of the checksum is unknown,
+// the caller should fall back to verify the sha256 digest.
+var ErrUnsupportedAlgorithm = errors.New("unsupported checksum algorithm")
+
+// New creates a new hash of the fast checksum.
+func New() hash.Hash64 {
+	return xxhash.New()
+}
+
+// Format formats the sum of the hash as the fast checksum, such as xxh64:0123456789abcdef.
+func Format(h hash.Hash64) string {
+	return fmt.Sprintf("%s:%016x", AlgorithmXXH64, h.Sum64())
+}
+
+// Compute computes the fast checksum of the content of the reader.
+func Compute(r io.Reader) (string, error) {
+	h := New()
+	if _, err := PipelinedCopy(h, r); err != nil {
+		return "", err
+	}
+
+	return Format(h), nil
+}
+
+// Verify computes the fast checksum of the content of the reader and compares
+// it with the expected one. It returns ErrUnsupportedAlgorithm if the algorithm
+// of the expected checksum is unknown.
+func Verify(expected string, r io.Reader) (bool, error) {
+	algorithm, _, ok := strings.Cut(expected, ":")
+	if !ok || algorithm != AlgorithmXXH64 {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, expected)
+	}
+
+	actual, err := Compute(r)
+	if err != nil {
+		return false, err
+	}
+
+	return actual == expected, nil
+}
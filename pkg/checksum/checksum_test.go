@@ -0,0 +1,67 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	sum, err := Compute(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "xxh64:ef46db3751d8e999", sum)
+
+	h := New()
+	_, err = h.Write([]byte("model weights"))
+	require.NoError(t, err)
+	sum, err = Compute(strings.NewReader("model weights"))
+	require.NoError(t, err)
+	assert.Equal(t, Format(h), sum)
+}
+
+func TestVerify(t *testing.T) {
+	blob := bytes.Repeat([]byte("safetensors"), 1024)
+	expected, err := Compute(bytes.NewReader(blob))
+	require.NoError(t, err)
+
+	ok, err := Verify(expected, bytes.NewReader(blob))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Flip a single byte to simulate the corrupted blob.
+	corrupted := bytes.Clone(blob)
+	corrupted[len(corrupted)/2] ^= 0xff
+	ok, err = Verify(expected, bytes.NewReader(corrupted))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Truncated blob.
+	ok, err = Verify(expected, bytes.NewReader(blob[:len(blob)-1]))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = Verify("crc32:deadbeef", bytes.NewReader(blob))
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = Verify("deadbeef", bytes.NewReader(blob))
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
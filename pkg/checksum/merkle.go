@@ -0,0 +1,89 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"encoding/hex"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// AnnotationMerkleRoot is the annotation key of the manifest which stores the Merkle
+	// root over the layer digests, which represents the integrity of the whole artifact.
+	AnnotationMerkleRoot = "org.cncf.modctl.merkle-root"
+
+	// AlgorithmMerkleSHA256 is the algorithm of the Merkle root.
+	AlgorithmMerkleSHA256 = "sha256"
+)
+
+var (
+	// leafPrefix and nodePrefix are the domain separation prefixes of the leaf and the inner
+	// nodes following RFC 6962, which prevents the second preimage attacks.
+	leafPrefix = []byte{0x00}
+	nodePrefix = []byte{0x01}
+)
+
+// MerkleRoot computes the Merkle root over the digests of the layers, such as sha256:e3b0c4...
+// The digests are sorted and deduplicated first, so the root is deterministic regardless
+// of the layer order. The tree is built as the Merkle tree hash of RFC 6962.
+func MerkleRoot(digests []string) string {
+	sorted := make([]string, 0, len(digests))
+	seen := make(map[string]struct{}, len(digests))
+	for _, digest := range digests {
+		if _, ok := seen[digest]; ok {
+			continue
+		}
+
+		seen[digest] = struct{}{}
+		sorted = append(sorted, digest)
+	}
+	sort.Strings(sorted)
+
+	leaves := make([][]byte, len(sorted))
+	for i, digest := range sorted {
+		leaves[i] = hashNode(leafPrefix, []byte(digest))
+	}
+
+	return AlgorithmMerkleSHA256 + ":" + hex.EncodeToString(merkleTreeHash(leaves))
+}
+
+// merkleTreeHash computes the Merkle tree hash of the leaf hashes.
+func merkleTreeHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		sum := Sum256(nil)
+		return sum[:]
+	case 1:
+		return leaves[0]
+	}
+
+	// split at the largest power of two smaller than the number of the leaves.
+	k := 1 << (bits.Len(uint(len(leaves)-1)) - 1)
+	return hashNode(nodePrefix, merkleTreeHash(leaves[:k]), merkleTreeHash(leaves[k:]))
+}
+
+// hashNode hashes the prefix and the parts.
+func hashNode(prefix []byte, parts ...[]byte) []byte {
+	h := NewSHA256()
+	h.Write(prefix)
+	for _, part := range parts {
+		h.Write(part)
+	}
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,67 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	digests := []string{
+		"sha256:9ca701e8784e5656e2c36f10f82410a0af4c44f859590a28a3d1519ee1eea89d",
+		"sha256:e31b55920173ba79526491fbd01efe609c1d0d72c3a83df85b2c4fe74df2eea2",
+		"sha256:1f2ec5c4ed3a6f6a6e5e8e0bb0d6c7e1d8a9c3b2a1f0e9d8c7b6a5f4e3d2c1b0",
+	}
+
+	root := MerkleRoot(digests)
+	assert.Regexp(t, `^sha256:[a-f0-9]{64}$`, root)
+
+	// the root is stable regardless of the layer order and the duplicated layers.
+	assert.Equal(t, root, MerkleRoot([]string{digests[2], digests[0], digests[1]}))
+	assert.Equal(t, root, MerkleRoot(append(digests, digests[0])))
+
+	// the root changes when a layer changes, is added or removed.
+	changed := []string{digests[0], digests[1], "sha256:0000000000000000000000000000000000000000000000000000000000000000"}
+	assert.NotEqual(t, root, MerkleRoot(changed))
+	assert.NotEqual(t, root, MerkleRoot(digests[:2]))
+	assert.NotEqual(t, root, MerkleRoot(append(digests, changed[2])))
+}
+
+func TestMerkleRootTree(t *testing.T) {
+	leaf := func(digest string) []byte {
+		return hashNode(leafPrefix, []byte(digest))
+	}
+	node := func(left, right []byte) []byte {
+		return hashNode(nodePrefix, left, right)
+	}
+
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, "sha256:"+hex.EncodeToString(empty[:]), MerkleRoot(nil))
+	assert.Equal(t, "sha256:"+hex.EncodeToString(leaf("a")), MerkleRoot([]string{"a"}))
+
+	// the unbalanced tree of three leaves is split into ((a, b), c).
+	expected := node(node(leaf("a"), leaf("b")), leaf("c"))
+	assert.Equal(t, "sha256:"+hex.EncodeToString(expected), MerkleRoot([]string{"c", "b", "a"}))
+
+	// the tree of five leaves is split into (((a, b), (c, d)), e).
+	expected = node(node(node(leaf("a"), leaf("b")), node(leaf("c"), leaf("d"))), leaf("e"))
+	assert.Equal(t, "sha256:"+hex.EncodeToString(expected), MerkleRoot([]string{"a", "b", "c", "d", "e"}))
+}
@@ -0,0 +1,125 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// pipelineChunkSize is the size of the chunk read ahead from the reader.
+	pipelineChunkSize = 4 * 1024 * 1024
+
+	// pipelineDepth is the number of the chunks read ahead from the reader.
+	pipelineDepth = 4
+)
+
+// chunkPool is the pool of the chunks used by the pipelined copy.
+var chunkPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, pipelineChunkSize)
+		return &buf
+	},
+}
+
+// chunk is the data read from the reader.
+type chunk struct {
+	buf *[]byte
+	n   int
+	err error
+}
+
+// PipelinedCopy copies from src to dst like io.Copy, but reads the src in a separate
+// goroutine ahead of writing to dst, so the IO of reading overlaps the CPU of the
+// writer such as hashing. The goroutine has exited when it returns, so the src can
+// be closed by the caller safely.
+func PipelinedCopy(dst io.Writer, src io.Reader) (int64, error) {
+	chunks := make(chan chunk, pipelineDepth)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		// wait for the reader goroutine to exit, which closes the chunks, and
+		// put back the chunks read ahead.
+		for c := range chunks {
+			chunkPool.Put(c.buf)
+		}
+	}()
+
+	go func() {
+		defer close(chunks)
+		for {
+			buf := chunkPool.Get().(*[]byte)
+			n, err := io.ReadFull(src, *buf)
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+
+			select {
+			case chunks <- chunk{buf: buf, n: n, err: err}:
+			case <-done:
+				chunkPool.Put(buf)
+				return
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var written int64
+	for c := range chunks {
+		if c.n > 0 {
+			n, err := dst.Write((*c.buf)[:c.n])
+			written += int64(n)
+			if err == nil && n != c.n {
+				err = io.ErrShortWrite
+			}
+
+			if err != nil {
+				chunkPool.Put(c.buf)
+				return written, err
+			}
+		}
+
+		chunkPool.Put(c.buf)
+		if c.err == io.EOF {
+			return written, nil
+		}
+
+		if c.err != nil {
+			return written, c.err
+		}
+	}
+
+	return written, nil
+}
+
+// SHA256 computes the sha256 digest and the size of the content of the reader by the
+// selected backend with the pipelined reads, the digest is formatted
+// as sha256:<hex>.
+func SHA256(r io.Reader) (string, int64, error) {
+	h := NewSHA256()
+	size, err := PipelinedCopy(h, r)
+	if err != nil {
+		return "", size, err
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), size, nil
+}
@@ -0,0 +1,135 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSHA256(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// the sizes cover the empty content, the partial chunk, the exact chunks and multiple chunks.
+	for _, size := range []int{0, 1, 1024, pipelineChunkSize - 1, pipelineChunkSize, pipelineChunkSize + 1, 3*pipelineChunkSize + 17} {
+		t.Run(fmt.Sprintf("size %d", size), func(t *testing.T) {
+			content := make([]byte, size)
+			rng.Read(content)
+
+			// the reader returning one byte at a time exercises the short reads.
+			var reader io.Reader = bytes.NewReader(content)
+			if size < pipelineChunkSize {
+				reader = iotest.OneByteReader(reader)
+			}
+
+			digest, n, err := SHA256(reader)
+			require.NoError(t, err)
+			assert.Equal(t, int64(size), n)
+			assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256(content)), digest)
+		})
+	}
+}
+
+func TestPipelinedCopyError(t *testing.T) {
+	readErr := errors.New("read error")
+	_, _, err := SHA256(io.MultiReader(bytes.NewReader(make([]byte, pipelineChunkSize+1)), iotest.ErrReader(readErr)))
+	assert.ErrorIs(t, err, readErr)
+
+	// the reader goroutine exits when the writer fails.
+	writeErr := errors.New("write error")
+	_, err = PipelinedCopy(errWriter{err: writeErr}, bytes.NewReader(make([]byte, 4*pipelineChunkSize)))
+	assert.ErrorIs(t, err, writeErr)
+}
+
+func TestPipelinedCopyWaitsReader(t *testing.T) {
+	// the src is not read after the copy returns, as the caller may close it.
+	src := &trackingReader{}
+	_, err := PipelinedCopy(errWriter{err: errors.New("write error")}, src)
+	assert.Error(t, err)
+
+	src.mu.Lock()
+	src.returned = true
+	src.mu.Unlock()
+
+	// the read in progress would finish after the sleep.
+	time.Sleep(50 * time.Millisecond)
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	assert.False(t, src.readAfterReturn)
+}
+
+// trackingReader reads the zeros slowly and records whether it is read after the copy returns.
+type trackingReader struct {
+	mu              sync.Mutex
+	returned        bool
+	readAfterReturn bool
+}
+
+func (r *trackingReader) Read(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	clear(p)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.returned {
+		r.readAfterReturn = true
+	}
+
+	return len(p), nil
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func BenchmarkSHA256(b *testing.B) {
+	content := make([]byte, 64*1024*1024)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	b.Run("stdlib", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			h := sha256.New()
+			if _, err := io.Copy(h, bytes.NewReader(content)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pipelined", func(b *testing.B) {
+		b.SetBytes(int64(len(content)))
+		for i := 0; i < b.N; i++ {
+			if _, _, err := SHA256(bytes.NewReader(content)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,228 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCheckpointInterval is the default number of the bytes hashed between the checkpoints.
+const DefaultCheckpointInterval = 256 * 1024 * 1024
+
+// Checkpoint is the progress of hashing a blob, which is the offset of the hashed bytes
+// and the serialized state of the hash at the offset.
+type Checkpoint struct {
+	// Digest is the expected digest of the blob.
+	Digest string `json:"digest"`
+	// Offset is the number of the hashed bytes.
+	Offset int64 `json:"offset"`
+	// State is the serialized state of the hash after hashing the bytes before the offset.
+	State []byte `json:"state"`
+}
+
+// CheckpointStore persists the checkpoints of hashing the blobs in the directory, one file
+// per blob, so the hashing interrupted can be resumed from the last checkpoint.
+type CheckpointStore struct {
+	dir string
+}
+
+// NewCheckpointStore creates the checkpoint store in the directory.
+func NewCheckpointStore(dir string) (*CheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory %s: %w", dir, err)
+	}
+
+	return &CheckpointStore{dir: dir}, nil
+}
+
+// path returns the path of the checkpoint file of the digest.
+func (s *CheckpointStore) path(digest string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(digest, ":", "-")+".json")
+}
+
+// Load loads the checkpoint of the digest, it returns nil if the checkpoint does not
+// exist or is unreadable, in which case the hashing restarts from the beginning.
+func (s *CheckpointStore) Load(digest string) *Checkpoint {
+	content, err := os.ReadFile(s.path(digest))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logrus.Warnf("checksum: failed to read checkpoint of %s, restart hashing: %s", digest, err)
+		}
+
+		return nil
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(content, &checkpoint); err != nil || checkpoint.Digest != digest || checkpoint.Offset < 0 {
+		logrus.Warnf("checksum: invalid checkpoint of %s, restart hashing", digest)
+		return nil
+	}
+
+	return &checkpoint
+}
+
+// Save persists the checkpoint, which is written to the temporary file and renamed to
+// never leave a partially-written checkpoint.
+func (s *CheckpointStore) Save(checkpoint *Checkpoint) error {
+	content, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	path := s.path(checkpoint.Digest)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// Remove removes the checkpoint of the digest.
+func (s *CheckpointStore) Remove(digest string) error {
+	if err := os.Remove(s.path(digest)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ResumableSHA256 computes the sha256 digest and the size of the content of the reader like
+// SHA256, but resumes from the checkpoint of the expected digest in the store and saves the
+// checkpoint every interval bytes and on the interruption, such as the cancellation of the
+// context. The skipped bytes are seeked if the reader is an io.Seeker, otherwise discarded.
+// The checkpoint is removed once the hashing completes. It's the same as SHA256 if the store
+// is nil.
+func ResumableSHA256(ctx context.Context, r io.Reader, digest string, store *CheckpointStore, interval int64) (string, int64, error) {
+	if store == nil {
+		return SHA256(r)
+	}
+
+	h := NewSHA256()
+	var offset int64
+	if checkpoint := store.Load(digest); checkpoint != nil {
+		if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(checkpoint.State); err != nil {
+			logrus.Warnf("checksum: invalid hash state in checkpoint of %s, restart hashing: %s", digest, err)
+			h.Reset()
+		} else if err := skip(r, checkpoint.Offset); err != nil {
+			return "", 0, fmt.Errorf("failed to skip to the checkpoint at offset %d: %w", checkpoint.Offset, err)
+		} else {
+			logrus.Infof("checksum: resuming hashing of %s from offset %d", digest, checkpoint.Offset)
+			offset = checkpoint.Offset
+		}
+	}
+
+	w := &checkpointWriter{ctx: ctx, hash: h, store: store, digest: digest, interval: interval, offset: offset, saved: offset}
+	if _, err := PipelinedCopy(w, r); err != nil {
+		// Save the progress hashed so far to resume from it next time.
+		if saveErr := w.save(); saveErr != nil {
+			logrus.Warnf("checksum: failed to save checkpoint of %s: %s", digest, saveErr)
+		}
+
+		return "", w.offset, err
+	}
+
+	if err := store.Remove(digest); err != nil {
+		logrus.Warnf("checksum: %s", err)
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), w.offset, nil
+}
+
+// skip skips the first n bytes of the reader.
+func skip(r io.Reader, n int64) error {
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekStart)
+		return err
+	}
+
+	skipped, err := io.CopyN(io.Discard, r, n)
+	if err == io.EOF {
+		return fmt.Errorf("content is shorter than the checkpoint: %d bytes", skipped)
+	}
+
+	return err
+}
+
+// checkpointWriter writes to the hash and saves the checkpoint every interval bytes.
+type checkpointWriter struct {
+	ctx      context.Context
+	hash     hash.Hash
+	store    *CheckpointStore
+	digest   string
+	interval int64
+	// offset is the number of the hashed bytes.
+	offset int64
+	// saved is the offset of the last saved checkpoint.
+	saved int64
+}
+
+// Write writes the content to the hash, it fails if the context is done.
+func (w *checkpointWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := w.hash.Write(p)
+	w.offset += int64(n)
+	if err != nil {
+		return n, err
+	}
+
+	if w.interval > 0 && w.offset-w.saved >= w.interval {
+		if err := w.save(); err != nil {
+			logrus.Warnf("checksum: failed to save checkpoint of %s: %s", w.digest, err)
+		}
+	}
+
+	return n, nil
+}
+
+// save saves the checkpoint at the current offset.
+func (w *checkpointWriter) save() error {
+	if w.offset == w.saved {
+		return nil
+	}
+
+	state, err := w.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal hash state: %w", err)
+	}
+
+	if err := w.store.Save(&Checkpoint{Digest: w.digest, Offset: w.offset, State: state}); err != nil {
+		return err
+	}
+
+	w.saved = w.offset
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cancelReader cancels the context once the bytes read reach the limit.
+type cancelReader struct {
+	r      io.Reader
+	read   int
+	limit  int
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += n
+	if r.read >= r.limit {
+		r.cancel()
+	}
+
+	return n, err
+}
+
+func TestResumableSHA256(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	content := make([]byte, 12*pipelineChunkSize+17)
+	rng.Read(content)
+	expected := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+
+	t.Run("resume after read error", func(t *testing.T) {
+		store, err := NewCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+
+		readErr := errors.New("read error")
+		interrupted := 2*pipelineChunkSize + 5
+		_, _, err = ResumableSHA256(context.Background(), io.MultiReader(bytes.NewReader(content[:interrupted]), iotest.ErrReader(readErr)), expected, store, pipelineChunkSize)
+		assert.ErrorIs(t, err, readErr)
+
+		checkpoint := store.Load(expected)
+		require.NotNil(t, checkpoint)
+		assert.Equal(t, int64(interrupted), checkpoint.Offset)
+
+		// the seekable reader is seeked to the checkpoint.
+		digest, size, err := ResumableSHA256(context.Background(), bytes.NewReader(content), expected, store, pipelineChunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+		assert.Equal(t, int64(len(content)), size)
+		assert.Nil(t, store.Load(expected))
+	})
+
+	t.Run("resume after cancellation", func(t *testing.T) {
+		store, err := NewCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+
+		// the reads are ahead of the hashing by the pipeline depth at most, so some chunks
+		// are hashed before the cancellation.
+		ctx, cancel := context.WithCancel(context.Background())
+		reader := &cancelReader{r: bytes.NewReader(content), limit: 10 * pipelineChunkSize, cancel: cancel}
+		_, _, err = ResumableSHA256(ctx, reader, expected, store, pipelineChunkSize)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		checkpoint := store.Load(expected)
+		require.NotNil(t, checkpoint)
+		assert.Greater(t, checkpoint.Offset, int64(0))
+		assert.Less(t, checkpoint.Offset, int64(len(content)))
+
+		// the non-seekable reader discards the bytes before the checkpoint.
+		digest, size, err := ResumableSHA256(context.Background(), struct{ io.Reader }{bytes.NewReader(content)}, expected, store, pipelineChunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+		assert.Equal(t, int64(len(content)), size)
+		assert.Nil(t, store.Load(expected))
+	})
+
+	t.Run("detect corruption after resume", func(t *testing.T) {
+		store, err := NewCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+
+		_, _, err = ResumableSHA256(context.Background(), io.MultiReader(bytes.NewReader(content[:pipelineChunkSize]), iotest.ErrReader(errors.New("read error"))), expected, store, pipelineChunkSize)
+		require.Error(t, err)
+
+		corrupted := bytes.Clone(content)
+		corrupted[len(corrupted)-1]++
+		digest, _, err := ResumableSHA256(context.Background(), bytes.NewReader(corrupted), expected, store, pipelineChunkSize)
+		require.NoError(t, err)
+		assert.NotEqual(t, expected, digest)
+	})
+
+	t.Run("invalid checkpoint", func(t *testing.T) {
+		store, err := NewCheckpointStore(t.TempDir())
+		require.NoError(t, err)
+
+		require.NoError(t, store.Save(&Checkpoint{Digest: expected, Offset: pipelineChunkSize, State: []byte("invalid")}))
+		digest, size, err := ResumableSHA256(context.Background(), bytes.NewReader(content), expected, store, pipelineChunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+		assert.Equal(t, int64(len(content)), size)
+
+		require.NoError(t, os.WriteFile(store.path(expected), []byte("{"), 0644))
+		assert.Nil(t, store.Load(expected))
+	})
+
+	t.Run("without store", func(t *testing.T) {
+		digest, size, err := ResumableSHA256(context.Background(), bytes.NewReader(content), expected, nil, pipelineChunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+		assert.Equal(t, int64(len(content)), size)
+	})
+}
@@ -0,0 +1,116 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	stdsha256 "crypto/sha256"
+	_ "crypto/sha512" // register sha512 to verify the sha512 digests
+	"fmt"
+	"hash"
+	"sync/atomic"
+
+	simdsha256 "github.com/minio/sha256-simd"
+	godigest "github.com/opencontainers/go-digest"
+)
+
+const (
+	// SHA256BackendSIMD is the backend of the SIMD accelerated sha256 implementation, which
+	// falls back to the standard library if the CPU has no SHA or AVX extensions.
+	SHA256BackendSIMD = "simd"
+
+	// SHA256BackendStdlib is the backend of the sha256 implementation of the standard library,
+	// which is required by the FIPS builds.
+	SHA256BackendStdlib = "stdlib"
+
+	// SHA256Size is the size of the sha256 checksum in bytes.
+	SHA256Size = stdsha256.Size
+)
+
+// sha256Backend is the backend of all the sha256 digest computations, which defaults to the
+// one selected at build time.
+var sha256Backend atomic.Value
+
+func init() {
+	sha256Backend.Store(defaultSHA256Backend)
+}
+
+// SetSHA256Backend selects the backend of all the sha256 digest computations, the empty name
+// keeps the default one selected at build time, which is stdlib with the stdlib_sha256 build
+// tag and simd otherwise. All the backends produce the identical digests.
+func SetSHA256Backend(name string) error {
+	switch name {
+	case "":
+		sha256Backend.Store(defaultSHA256Backend)
+	case SHA256BackendSIMD, SHA256BackendStdlib:
+		sha256Backend.Store(name)
+	default:
+		return fmt.Errorf("invalid sha256 backend: %s, must be %s or %s", name, SHA256BackendSIMD, SHA256BackendStdlib)
+	}
+
+	return nil
+}
+
+// SHA256Backend returns the backend of the sha256 digest computations.
+func SHA256Backend() string {
+	return sha256Backend.Load().(string)
+}
+
+// NewSHA256 creates a new sha256 hash by the selected backend, which implements
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler in the same format for all the
+// backends, so the hash state saved by one backend can be restored by the other.
+func NewSHA256() hash.Hash {
+	if SHA256Backend() == SHA256BackendStdlib {
+		return stdsha256.New()
+	}
+
+	return simdsha256.New()
+}
+
+// Sum256 returns the sha256 checksum of the data by the selected backend.
+func Sum256(data []byte) [SHA256Size]byte {
+	if SHA256Backend() == SHA256BackendStdlib {
+		return stdsha256.Sum256(data)
+	}
+
+	return simdsha256.Sum256(data)
+}
+
+// FromBytes returns the sha256 digest of the data by the selected backend.
+func FromBytes(data []byte) godigest.Digest {
+	sum := Sum256(data)
+	return godigest.NewDigestFromBytes(godigest.SHA256, sum[:])
+}
+
+// FromBytesOf returns the digest of the data by the algorithm of the expected digest, the sha256
+// digests are computed by the selected backend and the digests of the other algorithms by
+// go-digest. It returns empty if the expected digest is invalid.
+func FromBytesOf(expected godigest.Digest, data []byte) godigest.Digest {
+	if expected.Validate() != nil {
+		return ""
+	}
+
+	if expected.Algorithm() == godigest.SHA256 {
+		return FromBytes(data)
+	}
+
+	return expected.Algorithm().FromBytes(data)
+}
+
+// Matches checks if the data matches the digest.
+func Matches(digest godigest.Digest, data []byte) bool {
+	return digest.Validate() == nil && FromBytesOf(digest, data) == digest
+}
@@ -0,0 +1,137 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checksum
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withSHA256Backend runs the function with the sha256 backend and restores the default one.
+func withSHA256Backend(t *testing.T, name string, fn func()) {
+	t.Helper()
+	require.NoError(t, SetSHA256Backend(name))
+	defer func() {
+		require.NoError(t, SetSHA256Backend(""))
+	}()
+
+	fn()
+}
+
+func TestSetSHA256Backend(t *testing.T) {
+	assert.Equal(t, defaultSHA256Backend, SHA256Backend())
+
+	withSHA256Backend(t, SHA256BackendStdlib, func() {
+		assert.Equal(t, SHA256BackendStdlib, SHA256Backend())
+	})
+	assert.Equal(t, defaultSHA256Backend, SHA256Backend())
+
+	assert.Error(t, SetSHA256Backend("sha256"))
+	assert.Equal(t, defaultSHA256Backend, SHA256Backend())
+}
+
+func TestSHA256BackendsIdentical(t *testing.T) {
+	contents := [][]byte{
+		nil,
+		[]byte("model weights"),
+		bytes.Repeat([]byte("safetensors"), 1024*1024),
+	}
+
+	for _, content := range contents {
+		digests := map[string]string{}
+		for _, backend := range []string{SHA256BackendSIMD, SHA256BackendStdlib} {
+			withSHA256Backend(t, backend, func() {
+				digest, size, err := SHA256(bytes.NewReader(content))
+				require.NoError(t, err)
+				assert.Equal(t, int64(len(content)), size)
+				assert.Equal(t, fmt.Sprintf("sha256:%x", Sum256(content)), digest)
+
+				digests[backend] = digest
+			})
+		}
+
+		assert.Equal(t, digests[SHA256BackendSIMD], digests[SHA256BackendStdlib], "content of %d bytes", len(content))
+	}
+
+	// The Merkle root is computed by the backend as well.
+	layers := []string{
+		"sha256:e31b55920173ba79526491fbd01efe609c1d0d72c3a83df85b2c4fe74df2eea2",
+		"sha256:9ca701e8784e5656e2c36f10f82410a0af4c44f859590a28a3d1519ee1eea89d",
+	}
+	roots := map[string]string{}
+	for _, backend := range []string{SHA256BackendSIMD, SHA256BackendStdlib} {
+		withSHA256Backend(t, backend, func() {
+			roots[backend] = MerkleRoot(layers)
+		})
+	}
+	assert.Equal(t, roots[SHA256BackendSIMD], roots[SHA256BackendStdlib])
+}
+
+func TestSHA256BackendsStatePortable(t *testing.T) {
+	content := bytes.Repeat([]byte("safetensors"), 4096)
+	half := len(content) / 2
+
+	// Save the hash state by one backend and restore it by the other like the checkpoints.
+	for _, pair := range [][2]string{{SHA256BackendSIMD, SHA256BackendStdlib}, {SHA256BackendStdlib, SHA256BackendSIMD}} {
+		var state []byte
+		withSHA256Backend(t, pair[0], func() {
+			h := NewSHA256()
+			h.Write(content[:half])
+
+			var err error
+			state, err = h.(encoding.BinaryMarshaler).MarshalBinary()
+			require.NoError(t, err)
+		})
+
+		withSHA256Backend(t, pair[1], func() {
+			h := NewSHA256()
+			require.NoError(t, h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state))
+			h.Write(content[half:])
+
+			sum := Sum256(content)
+			assert.Equal(t, sum[:], h.Sum(nil), "%s to %s", pair[0], pair[1])
+		})
+	}
+}
+
+func TestFromBytesAndMatches(t *testing.T) {
+	data := []byte("model artifact")
+	for _, backend := range []string{SHA256BackendSIMD, SHA256BackendStdlib} {
+		withSHA256Backend(t, backend, func() {
+			digest := FromBytes(data)
+			assert.Equal(t, godigest.FromBytes(data), digest, backend)
+			assert.True(t, Matches(digest, data), backend)
+			assert.False(t, Matches(digest, []byte("other")), backend)
+		})
+	}
+
+	// the digests of the other algorithms are verified as well.
+	assert.True(t, Matches(godigest.SHA512.FromBytes(data), data))
+	assert.False(t, Matches(godigest.SHA512.FromBytes(data), []byte("other")))
+	assert.False(t, Matches("sha256:invalid", data))
+
+	// the digest is computed by the algorithm of the expected one.
+	assert.Equal(t, godigest.FromBytes(data), FromBytesOf(godigest.FromString("other"), data))
+	assert.Equal(t, godigest.SHA512.FromBytes(data), FromBytesOf(godigest.SHA512.FromString("other"), data))
+	assert.Empty(t, FromBytesOf("sha256:invalid", data))
+}
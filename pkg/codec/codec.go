@@ -20,18 +20,34 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/config"
 )
 
 type Type = string
 
+const (
+	// AnnotationExecutable is the annotation key indicating the file of the layer
+	// should be executable on extraction regardless of the source permissions.
+	AnnotationExecutable = "org.cncf.modctl.file.executable"
+)
+
 const (
 	// Raw is the raw codec type.
 	Raw Type = "raw"
 
 	// Tar is the tar codec type.
 	Tar Type = "tar"
+
+	// TarGzip is the gzip compressed tar codec type.
+	TarGzip Type = "tar+gzip"
+
+	// gzipSuffix is the suffix of the gzip compressed media types.
+	gzipSuffix = "+gzip"
 )
 
 // Codec is an interface for encoding and decoding the data.
@@ -46,25 +62,172 @@ type Codec interface {
 	Decode(outputDir, filePath string, reader io.Reader, desc ocispec.Descriptor) error
 }
 
-func New(codecType Type) (Codec, error) {
+// Option is the option of the builtin codecs.
+type Option func(*options)
+
+type options struct {
+	// preallocate indicates whether to preallocate the disk space of the decoded file.
+	preallocate bool
+	// conflictPolicy is the policy to resolve the conflict with the existing file of the decoded file.
+	conflictPolicy config.ConflictPolicy
+	// ownership maps the stored owners to the owners of the decoded files.
+	ownership *archiver.Ownership
+	// shortener shortens the paths of the decoded files exceeding the limits of the filesystem.
+	shortener *archiver.PathShortener
+}
+
+// WithPreallocate preallocates the disk space of the decoded file to the size of
+// the descriptor before writing, which reduces the fragmentation of the huge files.
+// It is skipped gracefully where unsupported.
+func WithPreallocate(preallocate bool) Option {
+	return func(o *options) {
+		o.preallocate = preallocate
+	}
+}
+
+// WithConflictPolicy resolves the conflict with the existing file by the policy when decoding,
+// the files identical to the layer are not regarded as conflicts.
+func WithConflictPolicy(policy config.ConflictPolicy) Option {
+	return func(o *options) {
+		o.conflictPolicy = policy
+	}
+}
+
+// WithOwnership sets the owners of the decoded files mapped from the owners stored in the file
+// metadata or the tar headers, the files are owned by the decoding user by default.
+func WithOwnership(ownership *archiver.Ownership) Option {
+	return func(o *options) {
+		o.ownership = ownership
+	}
+}
+
+// WithPathShortener shortens the paths of the decoded files exceeding the limits of the filesystem
+// by the shortener, the decoding fails with archiver.ErrPathTooLong by default.
+func WithPathShortener(shortener *archiver.PathShortener) Option {
+	return func(o *options) {
+		o.shortener = shortener
+	}
+}
+
+// Factory creates a new codec instance.
+type Factory func() Codec
+
+var (
+	// registryMu guards the registered codecs.
+	registryMu sync.RWMutex
+	// factories is the registered codec factories by the codec type.
+	factories = map[Type]Factory{}
+	// registeredMediaTypes is the registered codec types by the media type.
+	registeredMediaTypes = map[string]Type{}
+)
+
+// Register registers the codec factory for the media types, so the layers with the media
+// types are encoded and decoded by the custom codec. It panics if the codec type or any of the
+// media types is registered twice, the factory is nil or the codec type conflicts with the
+// builtin codecs.
+func Register(factory Factory, mediaTypes ...string) {
+	if factory == nil {
+		panic("codec: register nil codec factory")
+	}
+
+	codecType := factory().Type()
+	if codecType == "" || codecType == Raw || codecType == Tar || codecType == TarGzip {
+		panic(fmt.Sprintf("codec: register codec with invalid type %q", codecType))
+	}
+
+	if len(mediaTypes) == 0 {
+		panic(fmt.Sprintf("codec: register codec %s without media types", codecType))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := factories[codecType]; ok {
+		panic(fmt.Sprintf("codec: register codec %s twice", codecType))
+	}
+
+	seen := make(map[string]struct{}, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		if mediaType == "" {
+			panic(fmt.Sprintf("codec: register codec %s with empty media type", codecType))
+		}
+
+		if _, ok := registeredMediaTypes[mediaType]; ok {
+			panic("codec: register codec twice for media type " + mediaType)
+		}
+
+		if _, ok := seen[mediaType]; ok {
+			panic("codec: register codec twice for media type " + mediaType)
+		}
+
+		seen[mediaType] = struct{}{}
+	}
+
+	factories[codecType] = factory
+	for mediaType := range seen {
+		registeredMediaTypes[mediaType] = codecType
+	}
+}
+
+func New(codecType Type, opts ...Option) (Codec, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	switch codecType {
 	case Raw:
-		return newRaw(), nil
+		r := newRaw()
+		r.preallocate = o.preallocate
+		r.conflictPolicy = o.conflictPolicy
+		r.ownership = o.ownership
+		r.shortener = o.shortener
+		return r, nil
 	case Tar:
-		return newTar(), nil
-	default:
+		t := newTar()
+		t.conflictPolicy = o.conflictPolicy
+		t.ownership = o.ownership
+		t.shortener = o.shortener
+		return t, nil
+	case TarGzip:
+		t := newTarGzip()
+		t.conflictPolicy = o.conflictPolicy
+		t.ownership = o.ownership
+		t.shortener = o.shortener
+		return t, nil
+	}
+
+	registryMu.RLock()
+	factory, ok := factories[codecType]
+	registryMu.RUnlock()
+	if !ok {
 		return nil, fmt.Errorf("unsupported codec type: %s", codecType)
 	}
+
+	return factory(), nil
 }
 
 // TypeFromMediaType returns the codec type from the media type,
 // return empty string if not supported.
 func TypeFromMediaType(mediaType string) Type {
+	// The registered media types take precedence over the builtin ones.
+	registryMu.RLock()
+	codecType, ok := registeredMediaTypes[mediaType]
+	registryMu.RUnlock()
+	if ok {
+		return codecType
+	}
+
 	// If the mediaType ends with ".tar", return Tar.
 	if strings.HasSuffix(mediaType, ".tar") {
 		return Tar
 	}
 
+	// If the mediaType ends with ".tar+gzip", return TarGzip.
+	if strings.HasSuffix(mediaType, ".tar"+gzipSuffix) {
+		return TarGzip
+	}
+
 	// If the mediaType ends with ".raw", return Raw.
 	if strings.HasSuffix(mediaType, ".raw") {
 		return Raw
@@ -72,3 +235,25 @@ func TypeFromMediaType(mediaType string) Type {
 
 	return ""
 }
+
+// TarMediaType returns the tar media type of the raw media type, which is used for the content
+// that cannot be stored as a single raw file, such as a directory packed into a single layer.
+func TarMediaType(mediaType string) string {
+	if TypeFromMediaType(mediaType) != Raw || !strings.HasSuffix(mediaType, ".raw") {
+		return mediaType
+	}
+
+	return strings.TrimSuffix(mediaType, ".raw") + ".tar"
+}
+
+// RawMediaType returns the raw media type of the tar media type, which is used for the content
+// stored as-is, such as a dataset shipped as an archive.
+func RawMediaType(mediaType string) string {
+	for _, suffix := range []string{".tar" + gzipSuffix, ".tar"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return strings.TrimSuffix(mediaType, suffix) + ".raw"
+		}
+	}
+
+	return mediaType
+}
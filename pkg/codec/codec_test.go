@@ -18,15 +18,21 @@ package codec
 
 import (
 	"bytes"
+	"encoding/base64"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/encryption"
 )
 
 // --- Raw Codec Tests ---
@@ -66,6 +72,87 @@ func TestRawEncodeDecode(t *testing.T) {
 	assert.Equal(t, content, decoded)
 }
 
+func TestRawDecodePreallocate(t *testing.T) {
+	t.Parallel()
+	outputDir := t.TempDir()
+	content := bytes.Repeat([]byte("preallocated weights "), 64*1024)
+
+	c, err := New(Raw, WithPreallocate(true))
+	require.NoError(t, err)
+
+	desc := ocispec.Descriptor{Size: int64(len(content))}
+	require.NoError(t, c.Decode(outputDir, "model.bin", bytes.NewReader(content), desc))
+
+	decoded, err := os.ReadFile(filepath.Join(outputDir, "model.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, content, decoded)
+
+	// the size mismatch of the descriptor does not leave the preallocated space in the file size.
+	desc = ocispec.Descriptor{Size: int64(len(content)) * 2}
+	require.NoError(t, c.Decode(outputDir, "larger.bin", bytes.NewReader(content), desc))
+	info, err := os.Stat(filepath.Join(outputDir, "larger.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size())
+}
+
+func TestRawDecodeConflictPolicy(t *testing.T) {
+	t.Parallel()
+	content := []byte("new weights")
+	desc := ocispec.Descriptor{Size: int64(len(content))}
+
+	// decode decodes the content into the output directory pre-populated with the old file.
+	decode := func(t *testing.T, policy config.ConflictPolicy) (string, error) {
+		outputDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, "model.bin"), []byte("old"), 0644))
+
+		c, err := New(Raw, WithConflictPolicy(policy))
+		require.NoError(t, err)
+		return outputDir, c.Decode(outputDir, "model.bin", bytes.NewReader(content), desc)
+	}
+
+	t.Run("overwrite", func(t *testing.T) {
+		outputDir, err := decode(t, config.ConflictOverwrite)
+		require.NoError(t, err)
+		decoded, err := os.ReadFile(filepath.Join(outputDir, "model.bin"))
+		require.NoError(t, err)
+		assert.Equal(t, content, decoded)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		outputDir, err := decode(t, config.ConflictSkip)
+		assert.ErrorIs(t, err, ErrSkipped)
+		decoded, err := os.ReadFile(filepath.Join(outputDir, "model.bin"))
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(decoded))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := decode(t, config.ConflictError)
+		assert.ErrorIs(t, err, archiver.ErrConflict)
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		outputDir, err := decode(t, config.ConflictBackup)
+		require.NoError(t, err)
+		decoded, err := os.ReadFile(filepath.Join(outputDir, "model.bin"))
+		require.NoError(t, err)
+		assert.Equal(t, content, decoded)
+		backup, err := os.ReadFile(filepath.Join(outputDir, "model.bin"+archiver.BackupSuffix))
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(backup))
+	})
+
+	t.Run("backup identical", func(t *testing.T) {
+		outputDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, "model.bin"), content, 0644))
+
+		c, err := New(Raw, WithConflictPolicy(config.ConflictBackup))
+		require.NoError(t, err)
+		require.NoError(t, c.Decode(outputDir, "model.bin", bytes.NewReader(content), desc))
+		assert.NoFileExists(t, filepath.Join(outputDir, "model.bin"+archiver.BackupSuffix))
+	})
+}
+
 func TestRawEncodeEmpty(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -100,6 +187,23 @@ func TestRawDecodeInvalidInput(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRawDecodeInterrupted(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	r := newRaw()
+
+	// The reader fails in the middle of the content, no partial file should remain.
+	reader := io.MultiReader(strings.NewReader("partial"), &errorReader{})
+	desc := ocispec.Descriptor{Size: 100}
+
+	err := r.Decode(dir, "model.bin", reader, desc)
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 // errorReader is a reader that always returns an error.
 type errorReader struct{}
 
@@ -109,6 +213,29 @@ func (e *errorReader) Read([]byte) (int, error) {
 
 // --- Tar Codec Tests ---
 
+func TestRawDecodedContent(t *testing.T) {
+	ciphertext := godigest.FromString("ciphertext")
+	plaintext := godigest.FromString("plaintext")
+
+	size, digest, ok := decodedContent(ocispec.Descriptor{Digest: plaintext, Size: 9})
+	assert.True(t, ok)
+	assert.Equal(t, int64(9), size)
+	assert.Equal(t, plaintext.String(), digest)
+
+	// the encrypted layer is compared by the plaintext recorded in the encryption metadata.
+	size, digest, ok = decodedContent(ocispec.Descriptor{Digest: ciphertext, Size: 25, Annotations: map[string]string{
+		encryption.AnnotationEncryption: `{"algorithm":"AES-256-GCM","chunkSize":1024,"digest":"` + plaintext.String() + `","size":9}`,
+	}})
+	assert.True(t, ok)
+	assert.Equal(t, int64(9), size)
+	assert.Equal(t, plaintext.String(), digest)
+
+	_, _, ok = decodedContent(ocispec.Descriptor{Digest: ciphertext, Size: 25, Annotations: map[string]string{
+		encryption.AnnotationEncryption: `{"algorithm":"AES-256-GCM","chunkSize":1024}`,
+	}})
+	assert.False(t, ok)
+}
+
 func TestTarArchiveSingleFile(t *testing.T) {
 	t.Parallel()
 	srcDir := t.TempDir()
@@ -225,3 +352,171 @@ func TestTarInvalidArchive(t *testing.T) {
 	err := c.Decode(extractDir, "file.txt", strings.NewReader("this is not a tar"), desc)
 	assert.Error(t, err)
 }
+
+// --- Tar+Gzip Codec Tests ---
+
+func TestTarGzipExtractRoundtrip(t *testing.T) {
+	t.Parallel()
+	srcDir := t.TempDir()
+	content := []byte(strings.Repeat("compressible data ", 1024))
+
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "train.py"), content, 0644))
+
+	c, err := New(TypeFromMediaType("application/vnd.cncf.model.code.v1.tar+gzip"))
+	require.NoError(t, err)
+	assert.Equal(t, TarGzip, c.Type())
+
+	encode := func() []byte {
+		reader, err := c.Encode(filepath.Join(srcDir, "train.py"), srcDir)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return data
+	}
+
+	compressed := encode()
+	assert.Less(t, len(compressed), len(content)/4)
+	// the compressed content is reproducible.
+	assert.Equal(t, compressed, encode())
+
+	extractDir := t.TempDir()
+	require.NoError(t, c.Decode(extractDir, "train.py", bytes.NewReader(compressed), ocispec.Descriptor{}))
+
+	got, err := os.ReadFile(filepath.Join(extractDir, "train.py"))
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	assert.Error(t, c.Decode(extractDir, "train.py", strings.NewReader("this is not a gzip"), ocispec.Descriptor{}))
+}
+
+// --- Compression Policy Tests ---
+
+func TestTarMediaType(t *testing.T) {
+	assert.Equal(t, "application/vnd.cnai.model.code.v1.tar", TarMediaType("application/vnd.cnai.model.code.v1.raw"))
+	assert.Equal(t, "application/vnd.cnai.model.code.v1.tar", TarMediaType("application/vnd.cnai.model.code.v1.tar"))
+	assert.Equal(t, "application/vnd.cnai.model.code.v1.tar+gzip", TarMediaType("application/vnd.cnai.model.code.v1.tar+gzip"))
+}
+
+func TestRawMediaType(t *testing.T) {
+	assert.Equal(t, "application/vnd.cnai.model.dataset.v1.raw", RawMediaType("application/vnd.cnai.model.dataset.v1.tar"))
+	assert.Equal(t, "application/vnd.cnai.model.dataset.v1.raw", RawMediaType("application/vnd.cnai.model.dataset.v1.tar+gzip"))
+	assert.Equal(t, "application/vnd.cnai.model.dataset.v1.raw", RawMediaType("application/vnd.cnai.model.dataset.v1.raw"))
+}
+
+func TestCompressionPolicy(t *testing.T) {
+	policy, err := NewCompressionPolicy(nil, []string{"*.bin"})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		filename string
+		expected bool
+	}{
+		{"train.py", true},
+		{"config.json", true},
+		{"docs/figure.png", false},
+		{"model-Q4_K_M.GGUF", false},
+		{"weights.bin", false},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, policy.ShouldCompress(tc.filename), tc.filename)
+	}
+
+	assert.Equal(t, "application/vnd.cncf.model.code.v1.tar+gzip", policy.MediaType("application/vnd.cncf.model.code.v1.tar+gzip", "train.py"))
+	assert.Equal(t, "application/vnd.cncf.model.doc.v1.tar", policy.MediaType("application/vnd.cncf.model.doc.v1.tar+gzip", "figure.png"))
+	assert.Equal(t, "application/vnd.cncf.model.doc.v1.raw", policy.MediaType("application/vnd.cncf.model.doc.v1.raw", "README.md"))
+
+	// only the files matching the include patterns are compressed.
+	policy, err = NewCompressionPolicy([]string{"*.py", "*.png"}, nil)
+	require.NoError(t, err)
+	assert.True(t, policy.ShouldCompress("train.py"))
+	assert.False(t, policy.ShouldCompress("config.json"))
+	assert.False(t, policy.ShouldCompress("figure.png"))
+
+	_, err = NewCompressionPolicy([]string{"["}, nil)
+	assert.ErrorContains(t, err, "invalid compression pattern")
+}
+
+// --- Custom Codec Tests ---
+
+// base64Codec is a trivial custom codec which encodes the file content in base64.
+type base64Codec struct{}
+
+func (base64Codec) Type() Type {
+	return "base64"
+}
+
+func (base64Codec) Encode(targetFilePath, workDirPath string) (io.Reader, error) {
+	content, err := os.ReadFile(targetFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.NewReader(base64.StdEncoding.EncodeToString(content)), nil
+}
+
+func (base64Codec) Decode(outputDir, filePath string, reader io.Reader, desc ocispec.Descriptor) error {
+	content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, reader))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(outputDir, filePath), content, 0644)
+}
+
+// otherCodec is the base64 codec registered with another codec type.
+type otherCodec struct{ base64Codec }
+
+func (otherCodec) Type() Type {
+	return "other"
+}
+
+func TestRegister(t *testing.T) {
+	const mediaType = "application/vnd.example.model.weight.v1.base64"
+	const aliasMediaType = "application/vnd.example.model.dataset.v1.base64"
+	Register(func() Codec { return base64Codec{} }, mediaType, aliasMediaType)
+
+	// the media type is resolved to the custom codec.
+	assert.Equal(t, Type("base64"), TypeFromMediaType(mediaType))
+	assert.Equal(t, Type("base64"), TypeFromMediaType(aliasMediaType))
+	// the builtin media types are not affected.
+	assert.Equal(t, Raw, TypeFromMediaType("application/vnd.cncf.model.weight.v1.raw"))
+
+	srcDir := t.TempDir()
+	content := []byte("custom codec content")
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "model.bin"), content, 0644))
+
+	// build the layer with the custom codec.
+	c, err := New(TypeFromMediaType(mediaType))
+	require.NoError(t, err)
+	reader, err := c.Encode(filepath.Join(srcDir, "model.bin"), srcDir)
+	require.NoError(t, err)
+	encoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(content), string(encoded))
+
+	// extract the layer with the custom codec.
+	extractDir := t.TempDir()
+	c, err = New(TypeFromMediaType(mediaType))
+	require.NoError(t, err)
+	require.NoError(t, c.Decode(extractDir, "model.bin", bytes.NewReader(encoded), ocispec.Descriptor{MediaType: mediaType}))
+	got, err := os.ReadFile(filepath.Join(extractDir, "model.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	// the codec type can not be registered twice, even for the new media types.
+	assert.Panics(t, func() { Register(func() Codec { return base64Codec{} }, "application/vnd.example.other.base64") })
+	assert.Equal(t, Type(""), TypeFromMediaType("application/vnd.example.other.base64"))
+	// the media type can not be registered twice.
+	assert.Panics(t, func() { Register(func() Codec { return otherCodec{} }, mediaType) })
+	assert.Panics(t, func() {
+		Register(func() Codec { return otherCodec{} }, "application/vnd.example.dup", "application/vnd.example.dup")
+	})
+	assert.Equal(t, Type(""), TypeFromMediaType("application/vnd.example.dup"))
+	// the builtin codec types can not be overridden.
+	assert.Panics(t, func() { Register(func() Codec { return newRaw() }, "application/vnd.example.raw") })
+	assert.Panics(t, func() { Register(nil, "application/vnd.example.nil") })
+	assert.Panics(t, func() { Register(func() Codec { return otherCodec{} }) })
+
+	_, err = New("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultIncompressiblePatterns is the patterns of the files which are already compressed, such as
+// the quantized weights, the images and the archives, compressing them wastes the CPU.
+var DefaultIncompressiblePatterns = []string{
+	"*.gguf", "*.ggml",
+	"*.png", "*.jpg", "*.jpeg", "*.gif", "*.webp", "*.heic", "*.heif",
+	"*.mp3", "*.mp4", "*.mov", "*.mkv", "*.webm", "*.avi",
+	"*.zip", "*.gz", "*.tgz", "*.zst", "*.xz", "*.bz2", "*.7z", "*.rar", "*.lz4",
+	"*.npz", "*.ftz",
+}
+
+// CompressionPolicy decides whether the file is compressed when the compression is enabled.
+type CompressionPolicy struct {
+	// Include is the patterns of the files to compress, all the files are compressible if it is empty.
+	Include []string
+	// Exclude is the patterns of the incompressible files, which are stored uncompressed even if
+	// they match the include patterns.
+	Exclude []string
+}
+
+// NewCompressionPolicy creates the compression policy, the exclude patterns extend the default
+// incompressible patterns.
+func NewCompressionPolicy(include, exclude []string) (*CompressionPolicy, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid compression pattern %q: %w", pattern, err)
+		}
+	}
+
+	return &CompressionPolicy{
+		Include: include,
+		Exclude: append(append([]string{}, DefaultIncompressiblePatterns...), exclude...),
+	}, nil
+}
+
+// ShouldCompress returns whether the file should be compressed by the base name, the patterns
+// are matched case-insensitively.
+func (p *CompressionPolicy) ShouldCompress(filename string) bool {
+	name := strings.ToLower(filepath.Base(filename))
+	match := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if matched, err := filepath.Match(strings.ToLower(pattern), name); err == nil && matched {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(p.Include) > 0 && !match(p.Include) {
+		return false
+	}
+
+	return !match(p.Exclude)
+}
+
+// MediaType returns the media type of the file by the policy, the compressed media type is
+// turned into the uncompressed one if the file should not be compressed.
+func (p *CompressionPolicy) MediaType(mediaType, filename string) string {
+	if !IsCompressed(mediaType) || p.ShouldCompress(filename) {
+		return mediaType
+	}
+
+	return strings.TrimSuffix(mediaType, gzipSuffix)
+}
+
+// IsCompressed returns whether the media type is compressed by gzip.
+func IsCompressed(mediaType string) bool {
+	return strings.HasSuffix(mediaType, gzipSuffix)
+}
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package codec
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/config"
+)
+
+// tarGzip is a codec for gzip compressed tar files.
+type tarGzip struct {
+	// conflictPolicy is the policy to resolve the conflict with the existing files.
+	conflictPolicy config.ConflictPolicy
+	// ownership maps the owners in the tar headers to the owners of the decoded files.
+	ownership *archiver.Ownership
+	// shortener shortens the paths exceeding the limits of the filesystem.
+	shortener *archiver.PathShortener
+}
+
+// newTarGzip creates a new tar+gzip codec instance.
+func newTarGzip() *tarGzip {
+	return &tarGzip{}
+}
+
+// Type returns the type of the codec.
+func (t *tarGzip) Type() string {
+	return TarGzip
+}
+
+// Encode tars the target file and compresses it by gzip into a reader.
+func (t *tarGzip) Encode(targetFilePath, workDirPath string) (io.Reader, error) {
+	reader, err := archiver.Tar(targetFilePath, workDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return Gzip(reader), nil
+}
+
+// Decode decompresses the input reader and decodes the tar into the output path.
+func (t *tarGzip) Decode(outputDir, filePath string, reader io.Reader, desc ocispec.Descriptor) error {
+	gr, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gr.Close()
+
+	return archiver.Untar(gr, outputDir, archiver.WithConflictPolicy(t.conflictPolicy), archiver.WithOwnership(t.ownership), archiver.WithPathShortener(t.shortener))
+}
+
+// Gzip compresses the reader by gzip in a streaming way. The gzip header carries neither the
+// name nor the modification time, so the same content is always compressed into the same digest.
+func Gzip(reader io.Reader) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		gw := gzip.NewWriter(pw)
+		if _, err := io.Copy(gw, reader); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		pw.CloseWithError(gw.Close())
+	}()
+
+	return pr
+}
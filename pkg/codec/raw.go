@@ -30,14 +30,30 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 
+	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/config"
+	"github.com/modelpack/modctl/pkg/encryption"
+	"github.com/modelpack/modctl/pkg/fallocate"
 	"github.com/modelpack/modctl/pkg/xattr"
 )
 
 // ErrAlreadyUpToDate is returned when the target output already matches the descriptor metadata.
 var ErrAlreadyUpToDate = errors.New("codec: target already up-to-date")
 
+// ErrSkipped is returned when the target output exists and is kept by the conflict policy.
+var ErrSkipped = errors.New("codec: target exists and is skipped by the conflict policy")
+
 // raw is a codec that for raw files.
-type raw struct{}
+type raw struct {
+	// preallocate indicates whether to preallocate the disk space of the decoded file.
+	preallocate bool
+	// conflictPolicy is the policy to resolve the conflict with the existing file.
+	conflictPolicy config.ConflictPolicy
+	// ownership maps the owner in the file metadata to the owner of the decoded file.
+	ownership *archiver.Ownership
+	// shortener shortens the paths exceeding the limits of the filesystem.
+	shortener *archiver.PathShortener
+}
 
 // newRaw creates a new raw codec instance.
 func newRaw() *raw {
@@ -54,9 +70,30 @@ func (r *raw) Encode(targetFilePath, workDirPath string) (io.Reader, error) {
 	return os.Open(targetFilePath)
 }
 
+// decodedContent returns the size and the digest of the decoded file, which are the ones of the
+// plaintext recorded in the encryption metadata for the encrypted layer, it returns false if the
+// plaintext is unknown, such as the layer encrypted without recording it.
+func decodedContent(desc ocispec.Descriptor) (int64, string, bool) {
+	if _, ok := desc.Annotations[encryption.AnnotationEncryption]; !ok {
+		return desc.Size, desc.Digest.String(), true
+	}
+
+	metadata, err := encryption.ParseMetadata(desc.Annotations)
+	if err != nil || metadata.Digest == "" {
+		return 0, "", false
+	}
+
+	return metadata.Size, metadata.Digest, true
+}
+
 // fileNeedsUpdate checks if the file exists and whether its size and digest match.
 // Returns true if the file needs to be updated/written, false if it can be skipped.
 func (r *raw) fileNeedsUpdate(fullPath string, desc ocispec.Descriptor) (bool, error) {
+	expectedSize, expectedDigest, ok := decodedContent(desc)
+	if !ok {
+		return true, nil
+	}
+
 	// Check if file exists.
 	info, err := os.Stat(fullPath)
 	if err != nil {
@@ -70,7 +107,7 @@ func (r *raw) fileNeedsUpdate(fullPath string, desc ocispec.Descriptor) (bool, e
 	}
 
 	// File exists, check size first (quick check).
-	if info.Size() != desc.Size {
+	if info.Size() != expectedSize {
 		return true, nil
 	}
 
@@ -90,10 +127,7 @@ func (r *raw) fileNeedsUpdate(fullPath string, desc ocispec.Descriptor) (bool, e
 	}
 
 	// Compare stored values with descriptor.
-	expectedSize := strconv.FormatInt(desc.Size, 10)
-	expectedDigest := desc.Digest.String()
-
-	if string(storedSize) == expectedSize && string(storedDigest) == expectedDigest {
+	if string(storedSize) == strconv.FormatInt(expectedSize, 10) && string(storedDigest) == expectedDigest {
 		// File is up-to-date, no need to write.
 		logrus.Debugf("codec: file %s is up-to-date", fullPath)
 		return false, nil
@@ -105,13 +139,18 @@ func (r *raw) fileNeedsUpdate(fullPath string, desc ocispec.Descriptor) (bool, e
 
 // storeFileMetadata stores the size and digest in xattrs.
 func (r *raw) storeFileMetadata(fullPath string, desc ocispec.Descriptor) error {
+	size, digest, ok := decodedContent(desc)
+	if !ok {
+		return nil
+	}
+
 	sizeKey := xattr.MakeKey(xattr.KeySize)
-	if err := xattr.Set(fullPath, sizeKey, []byte(strconv.FormatInt(desc.Size, 10))); err != nil {
+	if err := xattr.Set(fullPath, sizeKey, []byte(strconv.FormatInt(size, 10))); err != nil {
 		return fmt.Errorf("failed to set size xattr: %w", err)
 	}
 
 	digestKey := xattr.MakeKey(xattr.KeySha256)
-	if err := xattr.Set(fullPath, digestKey, []byte(desc.Digest.String())); err != nil {
+	if err := xattr.Set(fullPath, digestKey, []byte(digest)); err != nil {
 		return fmt.Errorf("failed to set digest xattr: %w", err)
 	}
 
@@ -120,7 +159,11 @@ func (r *raw) storeFileMetadata(fullPath string, desc ocispec.Descriptor) error
 
 // Decode reads the input reader and decodes the data into the output path.
 func (r *raw) Decode(outputDir, filePath string, reader io.Reader, desc ocispec.Descriptor) error {
-	fullPath := filepath.Join(outputDir, filePath)
+	fullPath := filepath.Join(outputDir, r.shortener.Shorten(outputDir, filePath))
+	if err := archiver.CheckPathLength(fullPath); err != nil {
+		return err
+	}
+
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -137,12 +180,34 @@ func (r *raw) Decode(outputDir, filePath string, reader io.Reader, desc ocispec.
 		return ErrAlreadyUpToDate
 	}
 
-	// File needs to be written/updated.
-	file, err := os.Create(fullPath)
+	// Resolve the conflict with the existing file which differs from the layer.
+	extract, err := archiver.ResolveConflict(fullPath, r.conflictPolicy)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+
+	if !extract {
+		return ErrSkipped
+	}
+
+	// File needs to be written/updated, write to the temporary file and rename it on success,
+	// so an interrupted decoding never leaves a partially-written file at the full path.
+	file, err := archiver.CreateAtomic(fullPath, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	// Preallocate the disk space as the size is known from the descriptor.
+	if r.preallocate {
+		if err := fallocate.Preallocate(file.File, desc.Size); err != nil {
+			if errors.Is(err, fallocate.ErrUnsupported) {
+				logrus.Debugf("codec: skip preallocation for %s: %s", fullPath, err)
+			} else {
+				logrus.Warnf("codec: failed to preallocate %s: %s", fullPath, err)
+			}
+		}
+	}
 
 	if _, err := io.Copy(file, reader); err != nil {
 		return err
@@ -173,13 +238,28 @@ func (r *raw) Decode(outputDir, filePath string, reader io.Reader, desc ocispec.
 		}
 	}
 
-	// Restore modification time if available.
+	// Set the owner mapped from the one in the file metadata, which is unknown without it
+	// or if the metadata is stripped on build, in which case the mode is zero as well.
+	uid, gid := -1, -1
+	if fileMetadata != nil && fileMetadata.Mode != 0 {
+		uid, gid = int(fileMetadata.Uid), int(fileMetadata.Gid)
+	}
+
+	if err := r.ownership.Chown(file.Name(), uid, gid); err != nil {
+		return err
+	}
+
+	// Restore modification time if available, which is preserved by the rename.
 	if fileMetadata != nil && !fileMetadata.ModTime.IsZero() {
-		if err := os.Chtimes(fullPath, fileMetadata.ModTime, fileMetadata.ModTime); err != nil {
+		if err := os.Chtimes(file.Name(), fileMetadata.ModTime, fileMetadata.ModTime); err != nil {
 			return err
 		}
 	}
 
+	if err := file.CommitWithPolicy(r.conflictPolicy); err != nil {
+		return err
+	}
+
 	// Store size and digest in xattrs after successful write.
 	// Ignore errors as xattrs might not be supported on all filesystems.
 	if err := r.storeFileMetadata(fullPath, desc); err != nil {
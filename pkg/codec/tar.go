@@ -22,10 +22,18 @@ import (
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"github.com/modelpack/modctl/pkg/archiver"
+	"github.com/modelpack/modctl/pkg/config"
 )
 
 // tar is a codec for tar files.
-type tar struct{}
+type tar struct {
+	// conflictPolicy is the policy to resolve the conflict with the existing files.
+	conflictPolicy config.ConflictPolicy
+	// ownership maps the owners in the tar headers to the owners of the decoded files.
+	ownership *archiver.Ownership
+	// shortener shortens the paths exceeding the limits of the filesystem.
+	shortener *archiver.PathShortener
+}
 
 // newTar creates a new tar codec instance.
 func newTar() *tar {
@@ -46,5 +54,5 @@ func (t *tar) Encode(targetFilePath, workDirPath string) (io.Reader, error) {
 func (t *tar) Decode(outputDir, filePath string, reader io.Reader, desc ocispec.Descriptor) error {
 	// As the file name has been provided in the tar header,
 	// so we do not care about the filePath.
-	return archiver.Untar(reader, outputDir)
+	return archiver.Untar(reader, outputDir, archiver.WithConflictPolicy(t.conflictPolicy), archiver.WithOwnership(t.ownership), archiver.WithPathShortener(t.shortener))
 }
@@ -0,0 +1,212 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInitial is the default concurrency the controller starts with.
+	DefaultInitial = 2
+
+	// throughputDropRatio is the ratio of the throughput to the previous round below
+	// which the bandwidth is considered saturated by the last increase.
+	throughputDropRatio = 0.8
+)
+
+// Option is the option of the controller.
+type Option func(*Controller)
+
+// WithInitial sets the concurrency the controller starts with.
+func WithInitial(initial int) Option {
+	return func(c *Controller) {
+		c.limit = initial
+	}
+}
+
+// WithMax sets the upper bound of the concurrency.
+func WithMax(maxConcurrency int) Option {
+	return func(c *Controller) {
+		c.max = maxConcurrency
+	}
+}
+
+// Controller adapts the concurrency of the transfers of an operation in the AIMD style.
+// It starts conservative and increases the concurrency by one after each round of the
+// successful transfers, where a round is as many transfers as the current concurrency,
+// until the throughput stops growing. It halves the concurrency when a transfer fails.
+// The concurrency is capped by the number of CPUs by default.
+type Controller struct {
+	operation string
+	max       int
+	now       func() time.Time
+
+	mu       sync.Mutex
+	limit    int
+	inflight int
+	// successes and bytes are the successful transfers and their bytes in the current round.
+	successes  int
+	bytes      int64
+	roundStart time.Time
+	// throughput is the throughput of the last round in bytes per second.
+	throughput float64
+	// changed is closed and replaced when a slot is released or the limit changes.
+	changed chan struct{}
+}
+
+// NewController creates a new concurrency controller for the operation.
+func NewController(operation string, opts ...Option) *Controller {
+	c := &Controller{
+		operation: operation,
+		max:       runtime.NumCPU(),
+		now:       time.Now,
+		limit:     DefaultInitial,
+		changed:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.max = max(c.max, 1)
+	c.limit = min(max(c.limit, 1), c.max)
+	c.roundStart = c.now()
+	return c
+}
+
+// Max returns the upper bound of the concurrency, which is used as the limit of the
+// errgroup driving the transfers.
+func (c *Controller) Max() int {
+	return c.max
+}
+
+// Limit returns the current concurrency.
+func (c *Controller) Limit() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.limit
+}
+
+// Do waits for a slot, then runs the fn transferring size bytes and records its result.
+// Place this inside retry.Do so each retry attempt is accounted. A nil controller runs
+// the fn directly.
+func (c *Controller) Do(ctx context.Context, size int64, fn func() error) error {
+	if c == nil {
+		return fn()
+	}
+
+	if err := c.acquire(ctx); err != nil {
+		return err
+	}
+
+	err := fn()
+	c.release(size, err)
+	return err
+}
+
+// acquire blocks until the number of the inflight transfers is below the limit.
+func (c *Controller) acquire(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		if c.inflight < c.limit {
+			c.inflight++
+			c.mu.Unlock()
+			return nil
+		}
+
+		changed := c.changed
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// release frees the slot and adjusts the limit by the result of the transfer.
+func (c *Controller) release(size int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+
+	c.inflight--
+
+	// The cancellation is not the failure of the remote.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if err != nil {
+		if limit := max(c.limit/2, 1); limit != c.limit {
+			logrus.Infof("%s: decreased concurrency from %d to %d after transfer failed", c.operation, c.limit, limit)
+			c.limit = limit
+		}
+
+		c.resetRound()
+		c.throughput = 0
+		return
+	}
+
+	c.successes++
+	c.bytes += size
+	if c.successes < c.limit {
+		return
+	}
+
+	// The throughput is unmeasurable if the round completes instantly.
+	throughput := c.throughput
+	elapsed := c.now().Sub(c.roundStart).Seconds()
+	if elapsed > 0 {
+		throughput = float64(c.bytes) / elapsed
+	}
+
+	switch {
+	case elapsed > 0 && c.throughput > 0 && throughput < c.throughput*throughputDropRatio && c.limit > 1:
+		// The last increase saturated the bandwidth, step back.
+		logrus.Infof("%s: decreased concurrency from %d to %d as throughput dropped", c.operation, c.limit, c.limit-1)
+		c.limit--
+	case c.limit < c.max:
+		logrus.Debugf("%s: increased concurrency from %d to %d", c.operation, c.limit, c.limit+1)
+		c.limit++
+	}
+
+	c.throughput = throughput
+	c.resetRound()
+}
+
+// resetRound starts a new round of the transfers.
+func (c *Controller) resetRound() {
+	c.successes = 0
+	c.bytes = 0
+	c.roundStart = c.now()
+}
+
+// notify wakes up the waiters.
+func (c *Controller) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
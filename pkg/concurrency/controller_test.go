@@ -0,0 +1,180 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is the clock advanced manually by the tests.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func newTestController(clock *fakeClock, opts ...Option) *Controller {
+	c := NewController("test", opts...)
+	c.now = clock.Now
+	c.roundStart = clock.Now()
+	return c
+}
+
+func TestControllerIncreasesUnderSuccess(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	c := newTestController(clock, WithInitial(1), WithMax(8))
+	ctx := context.Background()
+
+	// every round takes one second, so the throughput grows with the concurrency.
+	limits := []int{c.Limit()}
+	for range 10 {
+		clock.Advance(time.Second)
+		for range c.Limit() {
+			require.NoError(t, c.Do(ctx, 100, func() error { return nil }))
+		}
+		limits = append(limits, c.Limit())
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 8, 8, 8}, limits)
+
+	// the concurrency is capped by the max.
+	for range 20 {
+		require.NoError(t, c.Do(ctx, 100, func() error { return nil }))
+	}
+	assert.Equal(t, 8, c.Limit())
+}
+
+func TestControllerBacksOffUnderFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	c := newTestController(clock, WithInitial(8), WithMax(8))
+	ctx := context.Background()
+	errTransfer := errors.New("transfer failed")
+
+	assert.ErrorIs(t, c.Do(ctx, 100, func() error { return errTransfer }), errTransfer)
+	assert.Equal(t, 4, c.Limit())
+	assert.ErrorIs(t, c.Do(ctx, 100, func() error { return errTransfer }), errTransfer)
+	assert.Equal(t, 2, c.Limit())
+	assert.ErrorIs(t, c.Do(ctx, 100, func() error { return errTransfer }), errTransfer)
+	assert.Equal(t, 1, c.Limit())
+	assert.ErrorIs(t, c.Do(ctx, 100, func() error { return errTransfer }), errTransfer)
+	assert.Equal(t, 1, c.Limit(), "concurrency should not go below one")
+
+	// the cancellation is not counted as the failure.
+	c = newTestController(clock, WithInitial(4), WithMax(8))
+	assert.ErrorIs(t, c.Do(ctx, 0, func() error { return context.Canceled }), context.Canceled)
+	assert.Equal(t, 4, c.Limit())
+}
+
+func TestControllerBacksOffWhenThroughputDrops(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	c := newTestController(clock, WithInitial(2), WithMax(8))
+	ctx := context.Background()
+
+	// the first round establishes the throughput of 200 bytes per second.
+	clock.Advance(time.Second)
+	for range 2 {
+		require.NoError(t, c.Do(ctx, 100, func() error { return nil }))
+	}
+	assert.Equal(t, 3, c.Limit())
+
+	// the second round is much slower as the bandwidth is saturated.
+	clock.Advance(3 * time.Second)
+	for range 3 {
+		require.NoError(t, c.Do(ctx, 100, func() error { return nil }))
+	}
+	assert.Equal(t, 2, c.Limit())
+}
+
+func TestControllerLimitsInflight(t *testing.T) {
+	c := NewController("test", WithInitial(3), WithMax(3))
+	ctx := context.Background()
+
+	var (
+		inflight    atomic.Int32
+		maxInflight atomic.Int32
+		wg          sync.WaitGroup
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, c.Do(ctx, 1, func() error {
+				n := inflight.Add(1)
+				for {
+					m := maxInflight.Load()
+					if n <= m || maxInflight.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				inflight.Add(-1)
+				return nil
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxInflight.Load(), int32(3))
+}
+
+func TestControllerAcquireCanceled(t *testing.T) {
+	c := NewController("test", WithInitial(1), WithMax(1))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = c.Do(context.Background(), 0, func() error {
+			close(started)
+			<-done
+			return nil
+		})
+	}()
+	<-started
+
+	cancel()
+	assert.ErrorIs(t, c.Do(ctx, 0, func() error { return nil }), context.Canceled)
+	close(done)
+}
+
+func TestNilController(t *testing.T) {
+	var c *Controller
+	called := false
+	assert.NoError(t, c.Do(context.Background(), 0, func() error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+}
@@ -16,11 +16,48 @@
 
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
 
 const (
 	// defaultBuildConcurrency is the default number of concurrent builds.
 	defaultBuildConcurrency = 5
+
+	// defaultBuildMaxLayers is the default max number of the layers, which is the cap of the common registries.
+	defaultBuildMaxLayers = 1000
+
+	// LayerOrderMetadataFirst orders the layers of the manifest by placing the small metadata
+	// layers, such as the weight configs, docs and code, before the large weights.
+	LayerOrderMetadataFirst = "metadata-first"
+
+	// LoadOrderShard records the load order of the weights by the shard naming, such as
+	// model-00002-of-00010.safetensors is loaded after model-00001-of-00010.safetensors, the
+	// numbers in the filepaths are compared numerically.
+	LoadOrderShard = "shard"
+
+	// LoadOrderName records the load order of the weights by the lexical order of the filepaths.
+	LoadOrderName = "name"
+
+	// OnlyWeights builds the model artifact with the weights only, the weight configs, code and
+	// docs in the Modelfile are skipped.
+	OnlyWeights = "weights"
+
+	// ProgressFile displays one progress bar per file, which is the default progress mode.
+	ProgressFile = "file"
+
+	// ProgressAggregate displays a single progress bar per processor with the number of the
+	// files and the total bytes, which reduces the noise of many small files.
+	ProgressAggregate = "aggregate"
+
+	// VerifyMetadataWarn warns when the metadata in the Modelfile mismatches the detected one.
+	VerifyMetadataWarn = "warn"
+
+	// VerifyMetadataError fails the build when the metadata in the Modelfile mismatches the detected one.
+	VerifyMetadataError = "error"
 )
 
 type Build struct {
@@ -36,22 +73,124 @@ type Build struct {
 	Raw            bool
 	Reasoning      bool
 	NoCreationTime bool
+	FastChecksum   bool
+	// VerifyCache is the fraction of the files hitting the digest cache whose digests are recomputed
+	// and compared with the cached ones, 0 trusts the cache and 1 verifies all of them.
+	VerifyCache float64
+	// MerkleRoot stores the Merkle root over the layers as the manifest annotation.
+	MerkleRoot bool
+	// NoOverwrite fails the build if the target tag already exists.
+	NoOverwrite bool
+	// Force overwrites the existing target tag regardless of NoOverwrite.
+	Force bool
+	// ModelfileReferrer stores the Modelfile as the referrer of the artifact instead of the manifest annotation.
+	ModelfileReferrer bool
+	// AllowLFSPointers warns instead of failing the build if the model files are git-lfs pointers.
+	AllowLFSPointers bool
+	// Compress compresses the tar layers by gzip, except the incompressible files.
+	Compress bool
+	// CompressPatterns is the patterns of the files to compress, all the files are compressible if it is empty.
+	CompressPatterns []string
+	// NoCompressPatterns is the patterns of the incompressible files extending the defaults, such as *.gguf and *.png.
+	NoCompressPatterns []string
+	ExecPatterns       []string
+	LayerOrder         string
+	Only               string
+	// EncryptionKey is the source of the key to encrypt the layers, such as the path of the key
+	// file, env:<name> or cmd:<command>, the layers are not encrypted if it is empty.
+	EncryptionKey string
+	// Progress is the progress mode of the layers, file or aggregate.
+	Progress string
+	// MaxLayers is the max number of the built layers, zero disables the check.
+	MaxLayers int
+	// StrictLimits fails the build instead of warning when the artifact exceeds the max layers.
+	StrictLimits bool
+	// ReportSlow is the number of the slowest files to report after the build, zero disables the report.
+	ReportSlow int
+	// ReportWriter is the writer of the slow-file report and the warnings of the build.
+	ReportWriter io.Writer
+	// AsConfig, AsModel, AsCode, AsDoc and AsDataset are the patterns of the files classified as
+	// the kind regardless of the Modelfile, such as the tokenizer*.bin as the config.
+	AsConfig  []string
+	AsModel   []string
+	AsCode    []string
+	AsDoc     []string
+	AsDataset []string
+	// VerifyMetadata compares the paramsize and the precision in the Modelfile with the ones
+	// detected from the safetensors files, warn or error, empty disables the check.
+	VerifyMetadata string
+	// FromHF is the HuggingFace model to download and build by the generated Modelfile instead of
+	// the work directory, such as owner/repo or the full URL.
+	FromHF string
+	// StripMetadata omits the mode, owner and mtime from the file metadata annotations of the
+	// layers, only the name, size and type are kept for the extraction.
+	StripMetadata bool
+	// KeepEmptyDirs builds the empty directories in the work directory as the layers, which are
+	// recreated on extraction, the hidden directories are skipped.
+	KeepEmptyDirs bool
+	// LoadOrder records the load order of the weights in the layer annotations for the inference
+	// loaders requiring the shards loaded in order, shard or name, empty disables the recording.
+	LoadOrder string
+	// CanonicalJSON marshals the config and manifest in the canonical JSON with the keys sorted,
+	// so the digests are deterministic regardless of the field order of the types.
+	CanonicalJSON bool
+	// JSONIndent is the number of spaces to indent the config and manifest JSON, zero is compact.
+	JSONIndent int
+	// OllamaModelfile parses the Modelfile in the interop mode recognizing the FROM, PARAMETER,
+	// TEMPLATE and SYSTEM directives of Ollama, which are recorded in the manifest annotations.
+	OllamaModelfile bool
+	// ResumeBuild records the built layers in the build journal and skips the ones recorded by the
+	// interrupted build of the same target and Modelfile.
+	ResumeBuild bool
 }
 
 func NewBuild() *Build {
 	return &Build{
-		Concurrency:    defaultBuildConcurrency,
-		Target:         "",
-		Modelfile:      "Modelfile",
-		OutputRemote:   false,
-		PlainHTTP:      false,
-		Insecure:       false,
-		Nydusify:       false,
-		SourceURL:      "",
-		SourceRevision: "",
-		Raw:            false,
-		Reasoning:      false,
-		NoCreationTime: false,
+		Concurrency:        defaultBuildConcurrency,
+		Target:             "",
+		Modelfile:          "Modelfile",
+		OutputRemote:       false,
+		PlainHTTP:          false,
+		Insecure:           false,
+		Nydusify:           false,
+		SourceURL:          "",
+		SourceRevision:     "",
+		Raw:                false,
+		Reasoning:          false,
+		NoCreationTime:     false,
+		FastChecksum:       false,
+		VerifyCache:        0,
+		MerkleRoot:         false,
+		NoOverwrite:        false,
+		Force:              false,
+		ModelfileReferrer:  false,
+		AllowLFSPointers:   false,
+		Compress:           false,
+		CompressPatterns:   []string{},
+		NoCompressPatterns: []string{},
+		ExecPatterns:       []string{},
+		LayerOrder:         "",
+		Only:               "",
+		EncryptionKey:      "",
+		Progress:           ProgressFile,
+		MaxLayers:          defaultBuildMaxLayers,
+		StrictLimits:       false,
+		ReportSlow:         0,
+		ReportWriter:       os.Stderr,
+		AsConfig:           []string{},
+		AsModel:            []string{},
+		AsCode:             []string{},
+		AsDoc:              []string{},
+		AsDataset:          []string{},
+		VerifyMetadata:     "",
+		FromHF:             "",
+		StripMetadata:      false,
+		KeepEmptyDirs:      false,
+		LoadOrder:          "",
+		CanonicalJSON:      false,
+		JSONIndent:         0,
+		OllamaModelfile:    false,
+		ResumeBuild:        false,
 	}
 }
 
@@ -68,11 +207,112 @@ func (b *Build) Validate() error {
 		return fmt.Errorf("model file path is required")
 	}
 
+	for _, pattern := range b.ExecPatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exec pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, b.CompressPatterns...), b.NoCompressPatterns...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid compression pattern %q: %w", pattern, err)
+		}
+	}
+
+	// A file classified as multiple kinds is ambiguous.
+	kinds := map[string]string{}
+	overrides := b.ClassOverrides()
+	for _, kind := range LayerKinds {
+		for _, pattern := range overrides[kind] {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid %s override pattern %q: %w", kind, pattern, err)
+			}
+
+			if other, ok := kinds[pattern]; ok && other != kind {
+				return fmt.Errorf("override pattern %q cannot be both %s and %s", pattern, other, kind)
+			}
+			kinds[pattern] = kind
+		}
+	}
+
+	if (len(b.CompressPatterns) > 0 || len(b.NoCompressPatterns) > 0) && !b.Compress {
+		return fmt.Errorf("compression patterns only work with compress")
+	}
+
+	if b.Only != "" && b.Only != OnlyWeights {
+		return fmt.Errorf("invalid only %q, only %q is supported", b.Only, OnlyWeights)
+	}
+
+	if b.LayerOrder != "" && b.LayerOrder != LayerOrderMetadataFirst {
+		return fmt.Errorf("invalid layer order %q, only %q is supported", b.LayerOrder, LayerOrderMetadataFirst)
+	}
+
+	if b.LoadOrder != "" && b.LoadOrder != LoadOrderShard && b.LoadOrder != LoadOrderName {
+		return fmt.Errorf("invalid load order %q, must be %s or %s", b.LoadOrder, LoadOrderShard, LoadOrderName)
+	}
+
+	// The empty progress mode means file.
+	if b.Progress != "" && b.Progress != ProgressFile && b.Progress != ProgressAggregate {
+		return fmt.Errorf("invalid progress mode %q, must be %s or %s", b.Progress, ProgressFile, ProgressAggregate)
+	}
+
+	if b.VerifyCache < 0 || b.VerifyCache > 1 {
+		return fmt.Errorf("invalid verify cache rate %v, must be between 0 and 1", b.VerifyCache)
+	}
+
+	if b.VerifyMetadata != "" && b.VerifyMetadata != VerifyMetadataWarn && b.VerifyMetadata != VerifyMetadataError {
+		return fmt.Errorf("invalid verify metadata mode %q, must be %s or %s", b.VerifyMetadata, VerifyMetadataWarn, VerifyMetadataError)
+	}
+
+	if b.MaxLayers < 0 {
+		return fmt.Errorf("the max layers must not be negative")
+	}
+
+	if b.JSONIndent < 0 {
+		return fmt.Errorf("the json indent must not be negative")
+	}
+
+	if b.ReportSlow < 0 {
+		return fmt.Errorf("the number of the slow files to report must not be negative")
+	}
+
+	if b.ResumeBuild {
+		if b.OutputRemote {
+			return fmt.Errorf("resume build does not work with output remote")
+		}
+
+		if b.EncryptionKey != "" {
+			return fmt.Errorf("resume build does not work with the encryption")
+		}
+	}
+
 	if b.Nydusify {
 		if !b.OutputRemote {
 			return fmt.Errorf("nydusify only works with output remote")
 		}
+
+		if b.EncryptionKey != "" {
+			return fmt.Errorf("nydusify does not work with the encryption")
+		}
 	}
 
 	return nil
 }
+
+// ClassOverrides returns the patterns overriding the classification of the files by the layer kinds.
+func (b *Build) ClassOverrides() map[string][]string {
+	overrides := map[string][]string{}
+	for kind, patterns := range map[string][]string{
+		LayerKindConfig:  b.AsConfig,
+		LayerKindWeights: b.AsModel,
+		LayerKindCode:    b.AsCode,
+		LayerKindDoc:     b.AsDoc,
+		LayerKindDataset: b.AsDataset,
+	} {
+		if len(patterns) > 0 {
+			overrides[kind] = patterns
+		}
+	}
+
+	return overrides
+}
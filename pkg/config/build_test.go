@@ -75,6 +75,234 @@ func TestBuild_Validate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "metadata-first layer order",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				LayerOrder:  LayerOrderMetadataFirst,
+			},
+			expectErr: false,
+		},
+		{
+			name: "compression patterns",
+			build: &Build{
+				Concurrency:        1,
+				Target:             "target",
+				Modelfile:          "Modelfile",
+				Compress:           true,
+				CompressPatterns:   []string{"*.py"},
+				NoCompressPatterns: []string{"*.bin"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "compression patterns without compress",
+			build: &Build{
+				Concurrency:        1,
+				Target:             "target",
+				Modelfile:          "Modelfile",
+				NoCompressPatterns: []string{"*.bin"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid compression pattern",
+			build: &Build{
+				Concurrency:      1,
+				Target:           "target",
+				Modelfile:        "Modelfile",
+				Compress:         true,
+				CompressPatterns: []string{"["},
+			},
+			expectErr: true,
+		},
+		{
+			name: "class overrides",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				AsConfig:    []string{"tokenizer*.bin"},
+				AsDoc:       []string{"*.txt"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid class override pattern",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				AsConfig:    []string{"["},
+			},
+			expectErr: true,
+		},
+		{
+			name: "ambiguous class override pattern",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				AsConfig:    []string{"tokenizer*.bin"},
+				AsModel:     []string{"tokenizer*.bin"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "verify metadata",
+			build: &Build{
+				Concurrency:    1,
+				Target:         "target",
+				Modelfile:      "Modelfile",
+				VerifyMetadata: VerifyMetadataError,
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid verify metadata mode",
+			build: &Build{
+				Concurrency:    1,
+				Target:         "target",
+				Modelfile:      "Modelfile",
+				VerifyMetadata: "strict",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid verify cache rate",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				VerifyCache: 1.5,
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid layer order",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				LayerOrder:  "largest-first",
+			},
+			expectErr: true,
+		},
+		{
+			name: "shard load order",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				LoadOrder:   LoadOrderShard,
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid load order",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				LoadOrder:   "size",
+			},
+			expectErr: true,
+		},
+		{
+			name: "only weights",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				Only:        OnlyWeights,
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid only",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				Only:        "docs",
+			},
+			expectErr: true,
+		},
+		{
+			name: "nydusify with encryption",
+			build: &Build{
+				Concurrency:   1,
+				Target:        "target",
+				Modelfile:     "Modelfile",
+				OutputRemote:  true,
+				Nydusify:      true,
+				EncryptionKey: "/path/to/key",
+			},
+			expectErr: true,
+		},
+		{
+			name: "aggregate progress",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				Progress:    ProgressAggregate,
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid progress",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				Progress:    "verbose",
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative max layers",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				MaxLayers:   -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative report slow",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				ReportSlow:  -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative json indent",
+			build: &Build{
+				Concurrency: 1,
+				Target:      "target",
+				Modelfile:   "Modelfile",
+				JSONIndent:  -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "resume build with output remote",
+			build: &Build{
+				Concurrency:  1,
+				Target:       "target",
+				Modelfile:    "Modelfile",
+				ResumeBuild:  true,
+				OutputRemote: true,
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,84 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+const (
+	// defaultCacheWarmConcurrency is the default number of concurrent fetches of the cache warm.
+	defaultCacheWarmConcurrency = 5
+
+	// LayerKindWeights is the kind of the model weight layers.
+	LayerKindWeights = "weights"
+
+	// LayerKindConfig is the kind of the model weight config layers.
+	LayerKindConfig = "config"
+
+	// LayerKindCode is the kind of the model code layers.
+	LayerKindCode = "code"
+
+	// LayerKindDoc is the kind of the model doc layers.
+	LayerKindDoc = "doc"
+
+	// LayerKindDataset is the kind of the dataset layers.
+	LayerKindDataset = "dataset"
+)
+
+// LayerKinds is the supported kinds of the layers to filter.
+var LayerKinds = []string{LayerKindWeights, LayerKindConfig, LayerKindCode, LayerKindDoc, LayerKindDataset}
+
+type CacheWarm struct {
+	Concurrency int
+	PlainHTTP   bool
+	Insecure    bool
+	Proxy       string
+	// Only is the kinds of the layers to prefetch, all the layers are prefetched if it is empty.
+	Only            []string
+	ProgressWriter  io.Writer
+	DisableProgress bool
+}
+
+func NewCacheWarm() *CacheWarm {
+	return &CacheWarm{
+		Concurrency:     defaultCacheWarmConcurrency,
+		PlainHTTP:       false,
+		Insecure:        false,
+		Proxy:           "",
+		Only:            []string{},
+		ProgressWriter:  os.Stdout,
+		DisableProgress: false,
+	}
+}
+
+func (c *CacheWarm) Validate() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("invalid concurrency: %d", c.Concurrency)
+	}
+
+	for _, kind := range c.Only {
+		if !slices.Contains(LayerKinds, kind) {
+			return fmt.Errorf("invalid layer kind %q, must be one of %v", kind, LayerKinds)
+		}
+	}
+
+	return nil
+}
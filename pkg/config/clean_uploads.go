@@ -0,0 +1,44 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type CleanUploads struct {
+	DryRun bool
+	// OlderThan only cleans the uploads started earlier than the duration ago,
+	// which protects the uploads of the running pushes and builds.
+	OlderThan time.Duration
+}
+
+func NewCleanUploads() *CleanUploads {
+	return &CleanUploads{
+		DryRun:    false,
+		OlderThan: 0,
+	}
+}
+
+func (c *CleanUploads) Validate() error {
+	if c.OlderThan < 0 {
+		return fmt.Errorf("older than must not be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import "fmt"
+
+// ConflictPolicy is the policy to resolve the conflict with the existing file on extraction.
+type ConflictPolicy = string
+
+const (
+	// ConflictOverwrite overwrites the existing file, which is the default policy.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+
+	// ConflictSkip keeps the existing file and skips extracting the file.
+	ConflictSkip ConflictPolicy = "skip"
+
+	// ConflictError fails the extraction if the file exists.
+	ConflictError ConflictPolicy = "error"
+
+	// ConflictBackup renames the existing file with the backup suffix before extracting the file,
+	// the existing file with the identical content is kept without the backup.
+	ConflictBackup ConflictPolicy = "backup"
+)
+
+// ValidateConflictPolicy validates the conflict policy, the empty policy means overwrite.
+func ValidateConflictPolicy(policy ConflictPolicy) error {
+	switch policy {
+	case "", ConflictOverwrite, ConflictSkip, ConflictError, ConflictBackup:
+		return nil
+	default:
+		return fmt.Errorf("invalid conflict policy %q, must be one of %s, %s, %s and %s", policy, ConflictOverwrite, ConflictSkip, ConflictError, ConflictBackup)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type Delta struct {
+	// Concurrency is the number of the files diffed or patched concurrently.
+	Concurrency int
+}
+
+func NewDelta() *Delta {
+	return &Delta{
+		Concurrency: runtime.NumCPU(),
+	}
+}
+
+func (d *Delta) Validate() error {
+	if d.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+
+	return nil
+}
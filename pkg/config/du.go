@@ -0,0 +1,40 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import "fmt"
+
+type DiskUsage struct {
+	// JSON outputs the disk usage in JSON format, which is the shorthand of --format json.
+	JSON bool
+	OutputFormat
+}
+
+func NewDiskUsage() *DiskUsage {
+	return &DiskUsage{
+		JSON:         false,
+		OutputFormat: OutputFormat{Format: OutputFormatTable},
+	}
+}
+
+func (d *DiskUsage) Validate() error {
+	if d.JSON && d.Format != OutputFormatTable && d.Format != OutputFormatJSON {
+		return fmt.Errorf("--json conflicts with the format %s", d.Format)
+	}
+
+	return d.OutputFormat.Validate()
+}
@@ -26,12 +26,70 @@ const (
 type Extract struct {
 	Output      string
 	Concurrency int
+	// Flatten lays out all the files in the output directory without the directory structure.
+	Flatten bool
+	// Preallocate preallocates the disk space of the extracted files before writing.
+	Preallocate bool
+	// Force extracts the artifact even if the output directory is up-to-date.
+	Force bool
+	// OnConflict is the policy to resolve the conflict with the existing files in the output directory.
+	OnConflict ConflictPolicy
+	// VerifySafetensors checks the headers of the extracted safetensors files are
+	// loadable and the tensor data is not truncated.
+	VerifySafetensors bool
+	// DecryptionKey is the source of the key to decrypt the encrypted layers, such as the
+	// path of the key file, env:<name> or cmd:<command>.
+	DecryptionKey string
+	// ExtractDatasets extracts the datasets shipped as archives such as *.zip and *.tar.gz
+	// into the directories next to them, the archives are kept as well.
+	ExtractDatasets bool
+	// Chown is the owner of all the extracted files in the uid:gid format, or current for the
+	// current user, which takes precedence over MapUID and MapGID.
+	Chown string
+	// MapUID and MapGID map the uids and gids stored in the artifact to the ones of the extracted
+	// files in the from:to format, the extracted files are owned by the extracting user if none
+	// of the ownership options is set.
+	MapUID []string
+	MapGID []string
+	// ShortenLongPaths shortens the paths exceeding the limits of the filesystem and records the
+	// mapping to the original paths in the output directory, the extraction fails by default.
+	ShortenLongPaths bool
+	// JoinShards joins the files split into the parts named with the .part-<i>-of-<n> suffix, such
+	// as model.safetensors.part-1-of-3, into the files named without the suffix after extracting.
+	JoinShards bool
+	// Pull pulls the artifact from the remote registry if it does not exist in the local storage.
+	Pull      bool
+	PlainHTTP bool
+	Insecure  bool
+	Proxy     string
+	// SkipSpaceCheck skips checking the output directory has enough free space before extracting.
+	SkipSpaceCheck bool
+	// IndexDir is the directory of the extraction indexes keyed by the output directory, which
+	// skips the extraction into the up-to-date output directory, no index is recorded if empty.
+	IndexDir string
 }
 
 func NewExtract() *Extract {
 	return &Extract{
-		Output:      "",
-		Concurrency: defaultExtractConcurrency,
+		Output:            "",
+		Concurrency:       defaultExtractConcurrency,
+		Flatten:           false,
+		Force:             false,
+		OnConflict:        ConflictOverwrite,
+		Preallocate:       false,
+		VerifySafetensors: false,
+		DecryptionKey:     "",
+		ExtractDatasets:   false,
+		Chown:             "",
+		MapUID:            []string{},
+		MapGID:            []string{},
+		ShortenLongPaths:  false,
+		JoinShards:        false,
+		Pull:              true,
+		PlainHTTP:         false,
+		Insecure:          false,
+		Proxy:             "",
+		SkipSpaceCheck:    false,
 	}
 }
 
@@ -44,5 +102,9 @@ func (e *Extract) Validate() error {
 		return fmt.Errorf("output is required")
 	}
 
+	if err := ValidateConflictPolicy(e.OnConflict); err != nil {
+		return err
+	}
+
 	return nil
 }
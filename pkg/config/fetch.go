@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 )
 
 const (
@@ -29,6 +31,7 @@ const (
 
 type Fetch struct {
 	Concurrency       int
+	AutoConcurrency   bool
 	PlainHTTP         bool
 	Proxy             string
 	Insecure          bool
@@ -38,11 +41,20 @@ type Fetch struct {
 	ProgressWriter    io.Writer
 	DisableProgress   bool
 	Hooks             PullHooks
+	OnConflict        ConflictPolicy
+	// DecryptionKey is the source of the key to decrypt the encrypted layers.
+	DecryptionKey string
+	// KeepTar keeps the staged tar of the tar layers after the extraction for debugging.
+	KeepTar bool
+	// MediaTypes is the kinds of the layers, such as config and code, or the full media types to
+	// fetch, which are combined with the patterns.
+	MediaTypes []string
 }
 
 func NewFetch() *Fetch {
 	return &Fetch{
 		Concurrency:       defaultFetchConcurrency,
+		AutoConcurrency:   false,
 		PlainHTTP:         false,
 		Proxy:             "",
 		Insecure:          false,
@@ -52,6 +64,10 @@ func NewFetch() *Fetch {
 		ProgressWriter:    os.Stdout,
 		DisableProgress:   false,
 		Hooks:             &emptyPullHook{},
+		OnConflict:        ConflictOverwrite,
+		DecryptionKey:     "",
+		KeepTar:           false,
+		MediaTypes:        []string{},
 	}
 }
 
@@ -64,8 +80,18 @@ func (f *Fetch) Validate() error {
 		return fmt.Errorf("output is required")
 	}
 
-	if len(f.Patterns) == 0 {
-		return fmt.Errorf("patterns are required")
+	if len(f.Patterns) == 0 && len(f.MediaTypes) == 0 {
+		return fmt.Errorf("patterns or media types are required")
+	}
+
+	for _, mediaType := range f.MediaTypes {
+		if !strings.Contains(mediaType, "/") && !slices.Contains(LayerKinds, strings.ToLower(mediaType)) {
+			return fmt.Errorf("invalid media type %q, must be a full media type or one of %v", mediaType, LayerKinds)
+		}
+	}
+
+	if err := ValidateConflictPolicy(f.OnConflict); err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,58 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+type Fsck struct {
+	// Full always verifies the sha256 digest even if the fast checksum matches.
+	Full bool
+	// Concurrency is the number of the blobs checked concurrently.
+	Concurrency int
+	// MerkleRoot is the expected merkle root over the layers of the target.
+	MerkleRoot string
+	// CheckpointDir is the directory to persist the progress of hashing the blobs, the
+	// interrupted check resumes from it, empty disables the checkpoints.
+	CheckpointDir string
+	OutputFormat
+}
+
+func NewFsck() *Fsck {
+	return &Fsck{
+		Full:          false,
+		Concurrency:   runtime.NumCPU(),
+		MerkleRoot:    "",
+		CheckpointDir: "",
+		OutputFormat:  OutputFormat{Format: OutputFormatTable},
+	}
+}
+
+func (f *Fsck) Validate() error {
+	if f.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+
+	if f.MerkleRoot != "" && !strings.HasPrefix(f.MerkleRoot, "sha256:") {
+		return fmt.Errorf("invalid merkle root %q, must be in the form of sha256:<hex>", f.MerkleRoot)
+	}
+
+	return f.OutputFormat.Validate()
+}
@@ -0,0 +1,56 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import "fmt"
+
+type Get struct {
+	// Field is the dot-separated path of the field in the model config, such as config.precision.
+	Field string
+	// JSON prints the field as JSON, which is the shorthand of --format json.
+	JSON bool
+	// Remote gets the field from the model artifact in the remote registry.
+	Remote bool
+	// PlainHTTP uses plain HTTP instead of HTTPS.
+	PlainHTTP bool
+	// Insecure allows insecure connections.
+	Insecure bool
+	OutputFormat
+}
+
+func NewGet() *Get {
+	return &Get{
+		Field:        "",
+		JSON:         false,
+		Remote:       false,
+		PlainHTTP:    false,
+		Insecure:     false,
+		OutputFormat: OutputFormat{Format: OutputFormatTable},
+	}
+}
+
+func (g *Get) Validate() error {
+	if g.Field == "" {
+		return fmt.Errorf("field is required, such as config.precision")
+	}
+
+	if g.JSON && g.Format != OutputFormatTable && g.Format != OutputFormatJSON {
+		return fmt.Errorf("--json conflicts with the format %s", g.Format)
+	}
+
+	return g.OutputFormat.Validate()
+}
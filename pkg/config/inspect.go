@@ -21,6 +21,7 @@ type Inspect struct {
 	PlainHTTP bool
 	Insecure  bool
 	Config    bool
+	OutputFormat
 }
 
 func NewInspect() *Inspect {
@@ -29,5 +30,11 @@ func NewInspect() *Inspect {
 		PlainHTTP: false,
 		Insecure:  false,
 		Config:    false,
+		// keep JSON as the default format of inspect for the compatibility.
+		OutputFormat: OutputFormat{Format: OutputFormatJSON},
 	}
 }
+
+func (i *Inspect) Validate() error {
+	return i.OutputFormat.Validate()
+}
@@ -0,0 +1,36 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+type List struct {
+	TimeFilter
+	OutputFormat
+}
+
+func NewList() *List {
+	return &List{
+		OutputFormat: OutputFormat{Format: OutputFormatTable},
+	}
+}
+
+func (l *List) Validate() error {
+	if err := l.TimeFilter.Validate(); err != nil {
+		return err
+	}
+
+	return l.OutputFormat.Validate()
+}
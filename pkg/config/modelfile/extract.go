@@ -0,0 +1,52 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"fmt"
+	"os"
+)
+
+type ExtractConfig struct {
+	Remote    bool
+	PlainHTTP bool
+	Insecure  bool
+	Output    string // Output file of the modelfile, the modelfile is printed to stdout if it is empty
+	Overwrite bool
+}
+
+func NewExtractConfig() *ExtractConfig {
+	return &ExtractConfig{
+		Remote:    false,
+		PlainHTTP: false,
+		Insecure:  false,
+		Output:    "",
+		Overwrite: false,
+	}
+}
+
+func (e *ExtractConfig) Validate() error {
+	if e.Output == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(e.Output); err == nil && !e.Overwrite {
+		return fmt.Errorf("Modelfile already exists at %s - use --overwrite to overwrite", e.Output)
+	}
+
+	return nil
+}
@@ -26,6 +26,9 @@ import (
 // DefaultModelfileName is the default name of the modelfile.
 const DefaultModelfileName = "Modelfile"
 
+// DefaultDownloadConcurrency is the default number of the files downloaded concurrently.
+const DefaultDownloadConcurrency = 4
+
 type GenerateConfig struct {
 	Workspace                   string
 	Name                        string
@@ -42,8 +45,11 @@ type GenerateConfig struct {
 	ModelURL                    string
 	Provider                    string // Explicit provider for short-form URLs (e.g., "huggingface", "modelscope")
 	DownloadDir                 string // Custom directory for downloading models (optional)
+	Resume                      bool   // Resume the interrupted downloads in the download directory
+	DownloadConcurrency         int    // Number of the files downloaded concurrently
 	ExcludePatterns             []string
 	IncludePatterns             []string
+	RulesFile                   string // YAML file of the rules to classify the files (optional)
 }
 
 func NewGenerateConfig() *GenerateConfig {
@@ -63,8 +69,11 @@ func NewGenerateConfig() *GenerateConfig {
 		ModelURL:                    "",
 		Provider:                    "",
 		DownloadDir:                 "",
+		Resume:                      false,
+		DownloadConcurrency:         DefaultDownloadConcurrency,
 		ExcludePatterns:             []string{},
 		IncludePatterns:             []string{},
+		RulesFile:                   "",
 	}
 }
 
@@ -97,5 +106,9 @@ func (g *GenerateConfig) Validate() error {
 		}
 	}
 
+	if g.DownloadConcurrency < 1 {
+		return fmt.Errorf("invalid download concurrency: %d", g.DownloadConcurrency)
+	}
+
 	return nil
 }
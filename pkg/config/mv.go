@@ -0,0 +1,32 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+type Move struct {
+	// Target is the reference to tag the renamed artifact as, the source is retagged if empty.
+	Target string
+}
+
+func NewMove() *Move {
+	return &Move{
+		Target: "",
+	}
+}
+
+func (m *Move) Validate() error {
+	return nil
+}
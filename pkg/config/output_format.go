@@ -0,0 +1,46 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import "fmt"
+
+const (
+	// OutputFormatTable renders the output as the human readable table.
+	OutputFormatTable = "table"
+
+	// OutputFormatJSON renders the output as JSON.
+	OutputFormatJSON = "json"
+
+	// OutputFormatYAML renders the output as YAML.
+	OutputFormatYAML = "yaml"
+)
+
+// OutputFormat selects the format of the output of the read commands, which is one of
+// table, json and yaml.
+type OutputFormat struct {
+	Format string
+}
+
+// Validate validates the format of the output.
+func (o *OutputFormat) Validate() error {
+	switch o.Format {
+	case OutputFormatTable, OutputFormatJSON, OutputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q, must be one of %s, %s or %s", o.Format, OutputFormatTable, OutputFormatJSON, OutputFormatYAML)
+	}
+}
@@ -0,0 +1,39 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputFormatValidate(t *testing.T) {
+	for _, format := range []string{OutputFormatTable, OutputFormatJSON, OutputFormatYAML} {
+		assert.NoError(t, (&OutputFormat{Format: format}).Validate(), format)
+	}
+
+	for _, format := range []string{"", "xml", "JSON"} {
+		assert.Error(t, (&OutputFormat{Format: format}).Validate(), format)
+	}
+
+	du := NewDiskUsage()
+	du.JSON = true
+	assert.NoError(t, du.Validate())
+	du.Format = OutputFormatYAML
+	assert.Error(t, du.Validate())
+}
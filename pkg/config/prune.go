@@ -17,6 +17,7 @@
 package config
 
 type Prune struct {
+	TimeFilter
 	DryRun         bool
 	RemoveUntagged bool
 }
@@ -27,3 +28,7 @@ func NewPrune() *Prune {
 		RemoveUntagged: true,
 	}
 }
+
+func (p *Prune) Validate() error {
+	return p.TimeFilter.Validate()
+}
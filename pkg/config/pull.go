@@ -20,17 +20,23 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 
+	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 const (
 	// defaultPullConcurrency is the default number of concurrent pull operations.
 	defaultPullConcurrency = 5
+
+	// defaultPullTagConcurrency is the default number of the tags pulled concurrently.
+	defaultPullTagConcurrency = 2
 )
 
 type Pull struct {
 	Concurrency       int
+	AutoConcurrency   bool
 	PlainHTTP         bool
 	Proxy             string
 	Insecure          bool
@@ -40,20 +46,67 @@ type Pull struct {
 	ProgressWriter    io.Writer
 	DisableProgress   bool
 	DragonflyEndpoint string
+	Select            map[string]string
+	Preallocate       bool
+	Tags              string
+	Latest            int
+	OnConflict        ConflictPolicy
+	Expect            string
+	AllTags           bool
+	TagConcurrency    int
+	ReportWriter      io.Writer
+	// DecryptionKey is the source of the key to decrypt the encrypted layers on extraction.
+	DecryptionKey string
+	// KeepTar keeps the staged tar of the tar layers after the extraction for debugging.
+	KeepTar bool
+	// ExtractDatasets extracts the datasets shipped as archives on extraction.
+	ExtractDatasets bool
+	// ManifestOnly pulls the manifest and the config only without the layers.
+	ManifestOnly bool
+	// ExtractConcurrency is the number of the layers extracted concurrently to the extract dir.
+	ExtractConcurrency int
+	// ShortenLongPaths shortens the paths exceeding the limits of the filesystem on extraction and
+	// records the mapping to the original paths in the extract dir, the extraction fails by default.
+	ShortenLongPaths bool
+	// SkipSpaceCheck skips checking the storage and the extract dir have enough free space before pulling.
+	SkipSpaceCheck bool
+	// VerifySignature verifies the signature of the manifest by the key before pulling the layers.
+	VerifySignature bool
+	// SignatureKey is the path of the PEM encoded public key to verify the signature.
+	SignatureKey string
 }
 
 func NewPull() *Pull {
 	return &Pull{
-		Concurrency:       defaultPullConcurrency,
-		PlainHTTP:         false,
-		Proxy:             "",
-		Insecure:          false,
-		ExtractDir:        "",
-		ExtractFromRemote: false,
-		Hooks:             &emptyPullHook{},
-		ProgressWriter:    os.Stdout,
-		DisableProgress:   false,
-		DragonflyEndpoint: "",
+		Concurrency:        defaultPullConcurrency,
+		AutoConcurrency:    false,
+		PlainHTTP:          false,
+		Proxy:              "",
+		Insecure:           false,
+		ExtractDir:         "",
+		ExtractFromRemote:  false,
+		Hooks:              &emptyPullHook{},
+		ProgressWriter:     os.Stdout,
+		DisableProgress:    false,
+		DragonflyEndpoint:  "",
+		Select:             map[string]string{},
+		Preallocate:        false,
+		Tags:               "",
+		Latest:             0,
+		OnConflict:         ConflictOverwrite,
+		Expect:             "",
+		AllTags:            false,
+		TagConcurrency:     defaultPullTagConcurrency,
+		ReportWriter:       os.Stdout,
+		DecryptionKey:      "",
+		KeepTar:            false,
+		ExtractDatasets:    false,
+		ManifestOnly:       false,
+		ExtractConcurrency: defaultExtractConcurrency,
+		ShortenLongPaths:   false,
+		SkipSpaceCheck:     false,
+		VerifySignature:    false,
+		SignatureKey:       "",
 	}
 }
 
@@ -74,6 +127,84 @@ func (p *Pull) Validate() error {
 		return fmt.Errorf("dragonfly endpoint only can work with extract from remote scenario")
 	}
 
+	if p.Tags != "" {
+		if _, err := path.Match(p.Tags, ""); err != nil {
+			return fmt.Errorf("invalid tag pattern %s: %w", p.Tags, err)
+		}
+
+		if p.AllTags {
+			return fmt.Errorf("the tag pattern cannot be specified when pulling all the tags")
+		}
+	}
+
+	if p.Tags != "" || p.AllTags {
+		// The tags are extracted to the same directory and overwrite each other.
+		if p.ExtractDir != "" {
+			return fmt.Errorf("the extract dir cannot be specified when pulling by the tag pattern")
+		}
+
+		if p.TagConcurrency < 1 {
+			return fmt.Errorf("invalid tag concurrency: %d", p.TagConcurrency)
+		}
+	}
+
+	if p.DecryptionKey != "" && p.ExtractDir == "" {
+		return fmt.Errorf("the decryption key only works with the extract dir")
+	}
+
+	if p.ExtractConcurrency < 1 {
+		return fmt.Errorf("invalid extract concurrency: %d", p.ExtractConcurrency)
+	}
+
+	if p.ExtractDatasets && p.ExtractDir == "" {
+		return fmt.Errorf("extracting the datasets only works with the extract dir")
+	}
+
+	if p.ShortenLongPaths && p.ExtractDir == "" {
+		return fmt.Errorf("shortening the long paths only works with the extract dir")
+	}
+
+	// The layers are required to extract the model artifact.
+	if p.ManifestOnly && (p.ExtractDir != "" || p.ExtractFromRemote) {
+		return fmt.Errorf("the manifest only pull cannot be extracted")
+	}
+
+	if err := ValidateConflictPolicy(p.OnConflict); err != nil {
+		return err
+	}
+
+	if p.Latest < 0 {
+		return fmt.Errorf("invalid latest: %d", p.Latest)
+	}
+
+	if p.Latest > 0 && p.Tags == "" && !p.AllTags {
+		return fmt.Errorf("the tag pattern must be specified when pulling the latest tags")
+	}
+
+	if p.Expect != "" {
+		if _, err := godigest.Parse(p.Expect); err != nil {
+			return fmt.Errorf("invalid expected digest %s: %w", p.Expect, err)
+		}
+
+		// Each tag matching the pattern resolves to a different manifest.
+		if p.Tags != "" || p.AllTags {
+			return fmt.Errorf("the expected digest cannot be specified when pulling by the tag pattern")
+		}
+	}
+
+	if p.VerifySignature {
+		if p.SignatureKey == "" {
+			return fmt.Errorf("the key must be specified when verifying the signature")
+		}
+
+		// The manifest is resolved by the Dragonfly client without the registry.
+		if p.DragonflyEndpoint != "" {
+			return fmt.Errorf("verifying the signature does not work with dragonfly")
+		}
+	} else if p.SignatureKey != "" {
+		return fmt.Errorf("the key only works with verifying the signature")
+	}
+
 	return nil
 }
 
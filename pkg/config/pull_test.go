@@ -95,3 +95,110 @@ func TestNewFetch_DefaultHooks(t *testing.T) {
 	assert.False(t, f.Hooks.BeforePullLayer(desc, ocispec.Manifest{}))
 	f.Hooks.AfterPullLayer(desc, false, nil)
 }
+
+func TestPull_ValidateTags(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tags      string
+		allTags   bool
+		latest    int
+		extract   string
+		tagConc   int
+		key       string
+		datasets  bool
+		manifest  bool
+		extConc   int
+		expectErr bool
+	}{
+		{name: "tag pattern with latest", tags: "v*", latest: 3},
+		{name: "tag pattern without latest", tags: "v*"},
+		{name: "invalid tag pattern", tags: "v[", expectErr: true},
+		{name: "negative latest", tags: "v*", latest: -1, expectErr: true},
+		{name: "latest without tag pattern", latest: 3, expectErr: true},
+		{name: "tag pattern with extract dir", tags: "v*", extract: "/tmp/model", expectErr: true},
+		{name: "all tags", allTags: true},
+		{name: "all tags with latest", allTags: true, latest: 3},
+		{name: "all tags with tag pattern", tags: "v*", allTags: true, expectErr: true},
+		{name: "all tags with extract dir", allTags: true, extract: "/tmp/model", expectErr: true},
+		{name: "all tags with invalid tag concurrency", allTags: true, tagConc: -1, expectErr: true},
+		{name: "decryption key with extract dir", extract: "/tmp/model", key: "/path/to/key"},
+		{name: "decryption key without extract dir", key: "/path/to/key", expectErr: true},
+		{name: "extract datasets with extract dir", extract: "/tmp/model", datasets: true},
+		{name: "extract datasets without extract dir", datasets: true, expectErr: true},
+		{name: "manifest only", manifest: true},
+		{name: "manifest only with tag pattern", tags: "v*", manifest: true},
+		{name: "manifest only with extract dir", extract: "/tmp/model", manifest: true, expectErr: true},
+		{name: "extract concurrency", extract: "/tmp/model", extConc: 8},
+		{name: "invalid extract concurrency", extract: "/tmp/model", extConc: -1, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPull()
+			p.Tags = tc.tags
+			p.AllTags = tc.allTags
+			p.Latest = tc.latest
+			if tc.tagConc != 0 {
+				p.TagConcurrency = tc.tagConc
+			}
+			p.ExtractDir = tc.extract
+			p.DecryptionKey = tc.key
+			p.ExtractDatasets = tc.datasets
+			p.ManifestOnly = tc.manifest
+			if tc.extConc != 0 {
+				p.ExtractConcurrency = tc.extConc
+			}
+			if tc.expectErr {
+				assert.Error(t, p.Validate())
+			} else {
+				assert.NoError(t, p.Validate())
+			}
+		})
+	}
+}
+
+func TestPull_ValidateOnConflict(t *testing.T) {
+	p := NewPull()
+	assert.Equal(t, ConflictOverwrite, p.OnConflict)
+
+	for _, policy := range []string{ConflictOverwrite, ConflictSkip, ConflictError, ConflictBackup} {
+		p.OnConflict = policy
+		assert.NoError(t, p.Validate(), policy)
+	}
+
+	p.OnConflict = "rename"
+	assert.ErrorContains(t, p.Validate(), "invalid conflict policy")
+}
+
+func TestPull_ValidateExpect(t *testing.T) {
+	p := NewPull()
+	assert.NoError(t, p.Validate())
+
+	p.Expect = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	assert.NoError(t, p.Validate())
+
+	p.Tags = "v*"
+	assert.Error(t, p.Validate())
+
+	p.Tags = ""
+	p.Expect = "sha256:cafe"
+	assert.Error(t, p.Validate())
+}
+
+func TestPull_ValidateVerifySignature(t *testing.T) {
+	p := NewPull()
+	p.VerifySignature = true
+	assert.ErrorContains(t, p.Validate(), "the key must be specified")
+
+	p.SignatureKey = "/path/to/pub.pem"
+	assert.NoError(t, p.Validate())
+
+	p.ExtractFromRemote = true
+	p.ExtractDir = "/tmp/model"
+	p.DragonflyEndpoint = "127.0.0.1:65001"
+	assert.ErrorContains(t, p.Validate(), "dragonfly")
+
+	p = NewPull()
+	p.SignatureKey = "/path/to/pub.pem"
+	assert.ErrorContains(t, p.Validate(), "only works with verifying the signature")
+}
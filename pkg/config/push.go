@@ -16,25 +16,76 @@
 
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 const (
 	// defaultPushConcurrency is the default number of concurrent push operations.
 	defaultPushConcurrency = 5
+
+	// defaultPushMaxLayers is the default max number of the layers, which is the cap of the common registries.
+	defaultPushMaxLayers = 1000
+
+	// defaultPushMaxManifestSize is the default max size of the manifest, which is the cap of the common registries.
+	defaultPushMaxManifestSize = 4 << 20
 )
 
 type Push struct {
-	Concurrency int
-	PlainHTTP   bool
-	Insecure    bool
-	Nydusify    bool
+	Concurrency     int
+	AutoConcurrency bool
+	PlainHTTP       bool
+	Insecure        bool
+	Proxy           string
+	Nydusify        bool
+	// Destination is the reference to push the artifact as, such as registry.com/models/llama3:stable,
+	// the artifact is pushed as the source reference if empty.
+	Destination string
+	// MaxLayers is the max number of the layers accepted by the registry, zero disables the check.
+	MaxLayers int
+	// MaxManifestSize is the max size of the manifest accepted by the registry, zero disables the check.
+	MaxManifestSize int64
+	// MaxBlobSize is the max size of a blob accepted by the registry, zero disables the check.
+	MaxBlobSize int64
+	// StrictLimits fails the push instead of warning when the artifact exceeds the limits.
+	StrictLimits bool
+	// WarningWriter is the writer of the warnings about the limits.
+	WarningWriter io.Writer
+	// VerifyRemote compares the local artifact with the existing remote tag before the push, the
+	// push is skipped if they are identical and fails if they differ unless Force is set.
+	VerifyRemote bool
+	// Force overwrites the differing remote tag with VerifyRemote.
+	Force bool
+	// ShareFrom are the remote references sharing the content with the artifact, such as the sibling
+	// tags sharing the docs and configs, the shared blobs are reported and mounted from them.
+	ShareFrom []string
+	// OnlyChanged diffs the artifact against the remote tag of the destination and only uploads the
+	// blobs not referenced by it, the unchanged blobs are skipped without checking or mounting.
+	OnlyChanged bool
+	// ReportWriter is the writer of the report of the shared blobs and the changes.
+	ReportWriter io.Writer
 }
 
 func NewPush() *Push {
 	return &Push{
-		Concurrency: defaultPushConcurrency,
-		PlainHTTP:   false,
-		Nydusify:    false,
+		Concurrency:     defaultPushConcurrency,
+		AutoConcurrency: false,
+		PlainHTTP:       false,
+		Proxy:           "",
+		Nydusify:        false,
+		Destination:     "",
+		MaxLayers:       defaultPushMaxLayers,
+		MaxManifestSize: defaultPushMaxManifestSize,
+		MaxBlobSize:     0,
+		StrictLimits:    false,
+		WarningWriter:   os.Stderr,
+		VerifyRemote:    false,
+		Force:           false,
+		ShareFrom:       []string{},
+		OnlyChanged:     false,
+		ReportWriter:    os.Stdout,
 	}
 }
 
@@ -43,5 +94,13 @@ func (p *Push) Validate() error {
 		return fmt.Errorf("invalid concurrency: %d", p.Concurrency)
 	}
 
+	if p.MaxLayers < 0 || p.MaxManifestSize < 0 || p.MaxBlobSize < 0 {
+		return fmt.Errorf("the limits must not be negative")
+	}
+
+	if p.Force && !p.VerifyRemote {
+		return fmt.Errorf("force only works with verify remote, please specify --verify-remote")
+	}
+
 	return nil
 }
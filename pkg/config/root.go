@@ -17,17 +17,42 @@
 package config
 
 import (
+	"os"
 	"os/user"
 	"path/filepath"
 )
 
+const (
+	// EnvRegistryTokenFile is the environment variable of the default registry token file.
+	EnvRegistryTokenFile = "MODCTL_REGISTRY_TOKEN_FILE"
+
+	// EnvReferencePrefix is the environment variable of the default repository prefix of the short names.
+	EnvReferencePrefix = "MODCTL_REFERENCE_PREFIX"
+
+	// EnvSHA256Backend is the environment variable of the default backend of the sha256 digest computations.
+	EnvSHA256Backend = "MODCTL_SHA256_BACKEND"
+)
+
 type Root struct {
-	StorageDir      string
-	Pprof           bool
-	PprofAddr       string
-	DisableProgress bool
-	LogDir          string
-	LogLevel        string
+	StorageDir           string
+	Pprof                bool
+	PprofAddr            string
+	DisableProgress      bool
+	ProgressWidth        int
+	LogDir               string
+	LogLevel             string
+	RegistryTokenFile    string
+	UserAgent            string
+	ShortDigest          bool
+	LogFormat            string
+	PersistManifestCache bool
+	LogFile              string
+	LogMaxSize           int
+	LogMaxBackups        int
+	ReferencePrefix      string
+	ReferenceAliases     map[string]string
+	SHA256Backend        string
+	ProgressTop          bool
 }
 
 func NewRoot() (*Root, error) {
@@ -37,11 +62,24 @@ func NewRoot() (*Root, error) {
 	}
 
 	return &Root{
-		StorageDir:      filepath.Join(user.HomeDir, ".modctl"),
-		Pprof:           false,
-		PprofAddr:       "localhost:6060",
-		DisableProgress: false,
-		LogDir:          filepath.Join(user.HomeDir, ".modctl/logs"),
-		LogLevel:        "info",
+		StorageDir:           filepath.Join(user.HomeDir, ".modctl"),
+		Pprof:                false,
+		PprofAddr:            "localhost:6060",
+		DisableProgress:      false,
+		ProgressWidth:        0,
+		LogDir:               filepath.Join(user.HomeDir, ".modctl/logs"),
+		LogLevel:             "info",
+		RegistryTokenFile:    os.Getenv(EnvRegistryTokenFile),
+		UserAgent:            "",
+		ShortDigest:          false,
+		LogFormat:            "text",
+		PersistManifestCache: false,
+		LogFile:              "",
+		LogMaxSize:           0,
+		LogMaxBackups:        3,
+		ReferencePrefix:      os.Getenv(EnvReferencePrefix),
+		ReferenceAliases:     map[string]string{},
+		SHA256Backend:        os.Getenv(EnvSHA256Backend),
+		ProgressTop:          false,
 	}, nil
 }
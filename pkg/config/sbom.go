@@ -0,0 +1,61 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/modelpack/modctl/pkg/sbom"
+)
+
+type SBOM struct {
+	// Format is the format of the SBOM document, spdx or cyclonedx.
+	Format string
+	// Output is the file to write the SBOM document, stdout is used if empty.
+	Output string
+	// Remote generates the SBOM document from the model artifact in the remote registry.
+	Remote bool
+	// PlainHTTP uses plain HTTP instead of HTTPS.
+	PlainHTTP bool
+	// Insecure allows insecure connections.
+	Insecure bool
+	// Attach pushes the SBOM document to the registry as a referrer of the model artifact.
+	Attach bool
+}
+
+func NewSBOM() *SBOM {
+	return &SBOM{
+		Format:    sbom.FormatSPDX,
+		Output:    "",
+		Remote:    false,
+		PlainHTTP: false,
+		Insecure:  false,
+		Attach:    false,
+	}
+}
+
+func (s *SBOM) Validate() error {
+	if s.Format != sbom.FormatSPDX && s.Format != sbom.FormatCycloneDX {
+		return fmt.Errorf("invalid sbom format %q, must be %s or %s", s.Format, sbom.FormatSPDX, sbom.FormatCycloneDX)
+	}
+
+	if s.Attach && !s.Remote {
+		return fmt.Errorf("attaching the sbom requires the remote model artifact, please specify --remote")
+	}
+
+	return nil
+}
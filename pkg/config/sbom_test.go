@@ -0,0 +1,41 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSBOM_Validate(t *testing.T) {
+	s := NewSBOM()
+	assert.NoError(t, s.Validate())
+
+	s.Format = "cyclonedx"
+	assert.NoError(t, s.Validate())
+
+	s.Format = "swid"
+	assert.ErrorContains(t, s.Validate(), `invalid sbom format "swid"`)
+
+	s = NewSBOM()
+	s.Attach = true
+	assert.ErrorContains(t, s.Validate(), "requires the remote model artifact")
+
+	s.Remote = true
+	assert.NoError(t, s.Validate())
+}
@@ -0,0 +1,58 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import "fmt"
+
+const (
+	// defaultSyncCheckConcurrency is the default number of the remote tags resolved concurrently.
+	defaultSyncCheckConcurrency = 5
+)
+
+type SyncCheck struct {
+	// Concurrency is the number of the remote tags resolved concurrently.
+	Concurrency int
+	// JSON outputs the drift report in JSON format, which is the shorthand of --format json.
+	JSON      bool
+	PlainHTTP bool
+	Insecure  bool
+	Proxy     string
+	OutputFormat
+}
+
+func NewSyncCheck() *SyncCheck {
+	return &SyncCheck{
+		Concurrency:  defaultSyncCheckConcurrency,
+		JSON:         false,
+		PlainHTTP:    false,
+		Insecure:     false,
+		Proxy:        "",
+		OutputFormat: OutputFormat{Format: OutputFormatTable},
+	}
+}
+
+func (s *SyncCheck) Validate() error {
+	if s.Concurrency <= 0 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
+
+	if s.JSON && s.Format != OutputFormatTable && s.Format != OutputFormatJSON {
+		return fmt.Errorf("--json conflicts with the format %s", s.Format)
+	}
+
+	return s.OutputFormat.Validate()
+}
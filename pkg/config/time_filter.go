@@ -0,0 +1,132 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFilter filters the model artifacts by the creation time in the model config.
+// Both Since and Until accept a RFC3339 timestamp (e.g. 2025-01-02T15:04:05Z), a date
+// (e.g. 2025-01-02) in the local time zone or a duration relative to now (e.g. 30m, 24h, 7d).
+// The date of Since is the start of the day and the date of Until is the end of the day,
+// so that the whole day is included.
+type TimeFilter struct {
+	Since string
+	Until string
+}
+
+// Validate validates the since and until of the time filter.
+func (f *TimeFilter) Validate() error {
+	since, until, err := f.Range(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return fmt.Errorf("since %q must not be after until %q", f.Since, f.Until)
+	}
+
+	return nil
+}
+
+// IsSet returns true if any bound of the time filter is specified.
+func (f *TimeFilter) IsSet() bool {
+	return f.Since != "" || f.Until != ""
+}
+
+// Range returns the bounds of the time filter relative to now, the zero time
+// means the bound is not specified.
+func (f *TimeFilter) Range(now time.Time) (time.Time, time.Time, error) {
+	since, err := ParseTime(f.Since, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid since: %w", err)
+	}
+
+	until, err := parseUntil(f.Until, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid until: %w", err)
+	}
+
+	return since, until, nil
+}
+
+// Contains returns true if the time is within the bounds of the time filter relative to now,
+// the bounds are inclusive.
+func (f *TimeFilter) Contains(t, now time.Time) (bool, error) {
+	since, until, err := f.Range(now)
+	if err != nil {
+		return false, err
+	}
+
+	if !since.IsZero() && t.Before(since) {
+		return false, nil
+	}
+
+	if !until.IsZero() && t.After(until) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// parseUntil parses the value as the upper bound like ParseTime, except that the date is the
+// end of the day instead of the midnight.
+func parseUntil(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(value), now.Location()); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	return ParseTime(value, now)
+}
+
+// ParseTime parses the value as a RFC3339 timestamp, a date or a duration relative to now.
+// The date is the midnight in the location of now, and the duration supports the units of
+// time.ParseDuration and the day unit "d". An empty value returns the zero time.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("%q is neither a RFC3339 timestamp nor a relative duration", value)
+		}
+
+		return now.Add(-time.Duration(n * float64(24*time.Hour))), nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("%q is neither a RFC3339 timestamp nor a relative duration", value)
+	}
+
+	return now.Add(-d), nil
+}
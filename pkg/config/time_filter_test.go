@@ -0,0 +1,98 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: time.Time{}},
+		{name: "rfc3339", value: "2025-03-01T08:00:00Z", want: time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{name: "date", value: "2025-03-01", want: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "duration", value: "90m", want: now.Add(-90 * time.Minute)},
+		{name: "days", value: "7d", want: now.Add(-7 * 24 * time.Hour)},
+		{name: "fractional days", value: "1.5d", want: now.Add(-36 * time.Hour)},
+		{name: "negative duration", value: "-1h", wantErr: true},
+		{name: "garbage", value: "last week", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseTime(tc.value, now)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, tc.want.Equal(got), "want %s, got %s", tc.want, got)
+		})
+	}
+}
+
+func TestParseTimeDateLocation(t *testing.T) {
+	// the date is the midnight in the location of now regardless of the local time zone.
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	got, err := ParseTime("2025-03-01", time.Date(2025, 3, 10, 12, 0, 0, 0, tokyo))
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2025, 2, 28, 15, 0, 0, 0, time.UTC).Equal(got), "got %s", got)
+}
+
+func TestTimeFilter_ContainsDate(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	f := &TimeFilter{Since: "2025-01-31", Until: "2025-01-31"}
+
+	cases := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{name: "day before", t: time.Date(2025, 1, 30, 23, 59, 59, 0, time.UTC), want: false},
+		{name: "start of day", t: time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), want: true},
+		{name: "noon", t: time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC), want: true},
+		{name: "end of day", t: time.Date(2025, 1, 31, 23, 59, 59, 999999999, time.UTC), want: true},
+		{name: "next midnight", t: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := f.Contains(tc.t, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTimeFilter_Validate(t *testing.T) {
+	assert.NoError(t, (&TimeFilter{}).Validate())
+	assert.NoError(t, (&TimeFilter{Since: "7d", Until: "1d"}).Validate())
+	assert.Error(t, (&TimeFilter{Since: "1d", Until: "7d"}).Validate())
+	assert.NoError(t, (&TimeFilter{Since: "2025-01-31", Until: "2025-01-31"}).Validate())
+	assert.Error(t, (&TimeFilter{Until: "tomorrow"}).Validate())
+}
@@ -0,0 +1,40 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+type Whoami struct {
+	// PlainHTTP uses plain HTTP instead of HTTPS.
+	PlainHTTP bool
+	// Insecure allows insecure connections.
+	Insecure bool
+	// Proxy is the proxy URL to connect to the registry.
+	Proxy string
+	OutputFormat
+}
+
+func NewWhoami() *Whoami {
+	return &Whoami{
+		PlainHTTP:    false,
+		Insecure:     false,
+		Proxy:        "",
+		OutputFormat: OutputFormat{Format: OutputFormatTable},
+	}
+}
+
+func (w *Whoami) Validate() error {
+	return w.OutputFormat.Validate()
+}
@@ -0,0 +1,517 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package delta implements the binary diff between two versions of a file. The patch is a
+// gzip compressed stream of the bsdiff-style operations, which copy the ranges matched in
+// the base, add the bytewise difference to the base for the slightly changed ranges such
+// as the fine-tuned weights, and insert the new bytes.
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultBlockSize is the default size of the blocks matched between the base and the target.
+	DefaultBlockSize = 64 << 10
+
+	// maxChunkSize is the max size of the bytes carried by one add or insert operation.
+	maxChunkSize = 1 << 20
+
+	// maxCandidates is the max number of the base blocks indexed by the same weak hash.
+	maxCandidates = 8
+)
+
+const (
+	opEnd byte = iota
+	opCopy
+	opAdd
+	opInsert
+)
+
+// magic is the header of the patch.
+var magic = []byte("MCDELTA1")
+
+// Option is the option of the encoding.
+type Option func(*encoder)
+
+// WithBlockSize sets the size of the blocks matched between the base and the target, the
+// smaller blocks find more matches but index more blocks of the base in memory.
+func WithBlockSize(size int) Option {
+	return func(e *encoder) {
+		e.blockSize = size
+	}
+}
+
+// Encode writes the patch reconstructing the target from the base to the w.
+func Encode(w io.Writer, base io.ReaderAt, baseSize int64, target io.ReaderAt, targetSize int64, opts ...Option) error {
+	zw, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+
+	e := &encoder{
+		w:          bufio.NewWriter(zw),
+		base:       base,
+		baseSize:   baseSize,
+		target:     target,
+		targetSize: targetSize,
+		blockSize:  DefaultBlockSize,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.blockSize <= 0 {
+		return fmt.Errorf("invalid block size %d", e.blockSize)
+	}
+
+	if err := e.encode(); err != nil {
+		return err
+	}
+
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// Decode writes the target reconstructed from the base and the patch to the w.
+func Decode(w io.Writer, base io.ReaderAt, patch io.Reader) error {
+	zr, err := gzip.NewReader(patch)
+	if err != nil {
+		return fmt.Errorf("failed to open patch: %w", err)
+	}
+	defer zr.Close()
+
+	r := bufio.NewReader(zr)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.Equal(header, magic) {
+		return fmt.Errorf("invalid patch header")
+	}
+
+	targetSize, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("failed to read target size: %w", err)
+	}
+
+	var (
+		written  uint64
+		baseBuf  = make([]byte, maxChunkSize)
+		patchBuf = make([]byte, maxChunkSize)
+	)
+
+	for {
+		op, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("failed to read operation: %w", err)
+		}
+
+		if op == opEnd {
+			break
+		}
+
+		var offset uint64
+		if op == opCopy || op == opAdd {
+			if offset, err = binary.ReadUvarint(r); err != nil {
+				return fmt.Errorf("failed to read offset: %w", err)
+			}
+		}
+
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("failed to read length: %w", err)
+		}
+
+		switch op {
+		case opCopy:
+			n, err := io.Copy(w, io.NewSectionReader(base, int64(offset), int64(length)))
+			if err != nil {
+				return fmt.Errorf("failed to copy from base: %w", err)
+			}
+
+			if uint64(n) != length {
+				return fmt.Errorf("base is shorter than the copied range at offset %d", offset)
+			}
+		case opAdd:
+			if length > maxChunkSize {
+				return fmt.Errorf("invalid add length %d", length)
+			}
+
+			n, err := base.ReadAt(baseBuf[:length], int64(offset))
+			if err != nil && !(errors.Is(err, io.EOF) && uint64(n) == length) {
+				return fmt.Errorf("failed to read base: %w", err)
+			}
+
+			if _, err := io.ReadFull(r, patchBuf[:length]); err != nil {
+				return fmt.Errorf("failed to read patch: %w", err)
+			}
+
+			for i := range patchBuf[:length] {
+				patchBuf[i] += baseBuf[i]
+			}
+
+			if _, err := w.Write(patchBuf[:length]); err != nil {
+				return err
+			}
+		case opInsert:
+			if _, err := io.CopyN(w, r, int64(length)); err != nil {
+				return fmt.Errorf("failed to insert: %w", err)
+			}
+		default:
+			return fmt.Errorf("unknown operation %d", op)
+		}
+
+		written += length
+	}
+
+	if written != targetSize {
+		return fmt.Errorf("patch produced %d bytes, but expected %d", written, targetSize)
+	}
+
+	return nil
+}
+
+// encoder finds the blocks of the target matched in the base by the rolling hash, as rsync
+// does, and encodes the ranges in between as the difference to the base.
+type encoder struct {
+	w          *bufio.Writer
+	base       io.ReaderAt
+	baseSize   int64
+	target     io.ReaderAt
+	targetSize int64
+	blockSize  int
+
+	// shift is the offset in the base minus the offset in the target of the last match,
+	// the unmatched ranges are diffed against the base at the same shift.
+	shift int64
+}
+
+// encode writes the header, the operations and the end of the patch.
+func (e *encoder) encode() error {
+	if _, err := e.w.Write(magic); err != nil {
+		return err
+	}
+
+	if err := e.writeUvarint(uint64(e.targetSize)); err != nil {
+		return err
+	}
+
+	if err := e.scan(); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte(opEnd)
+}
+
+// scan rolls the window of the block size over the target and emits the matched ranges
+// as the copy operations.
+func (e *encoder) scan() error {
+	block := int64(e.blockSize)
+	if e.targetSize < block || e.baseSize < block {
+		return e.emitLiteral(0, e.targetSize)
+	}
+
+	index, err := e.indexBase()
+	if err != nil {
+		return err
+	}
+
+	window := newCachedReader(e.target, e.targetSize, max(4*e.blockSize, maxChunkSize))
+	targetBlock := make([]byte, e.blockSize)
+	baseBlock := make([]byte, e.blockSize)
+
+	var (
+		pos, literalStart int64
+		hash              rollingHash
+	)
+
+	if err := e.readFull(e.target, targetBlock, 0); err != nil {
+		return err
+	}
+	hash.init(targetBlock)
+
+	for {
+		matched := false
+		if candidates, ok := index[hash.sum()]; ok {
+			if err := e.readFull(e.target, targetBlock, pos); err != nil {
+				return err
+			}
+
+			for _, offset := range candidates {
+				if err := e.readFull(e.base, baseBlock, offset); err != nil {
+					return err
+				}
+
+				if !bytes.Equal(targetBlock, baseBlock) {
+					continue
+				}
+
+				if err := e.emitLiteral(literalStart, pos); err != nil {
+					return err
+				}
+
+				length, err := e.extend(offset+block, pos+block)
+				if err != nil {
+					return err
+				}
+				length += block
+
+				if err := e.emitCopy(offset, length); err != nil {
+					return err
+				}
+
+				e.shift = offset - pos
+				pos += length
+				literalStart = pos
+				matched = true
+				break
+			}
+
+			if matched {
+				if pos+block > e.targetSize {
+					break
+				}
+
+				if err := e.readFull(e.target, targetBlock, pos); err != nil {
+					return err
+				}
+				hash.init(targetBlock)
+				continue
+			}
+		}
+
+		if pos+block >= e.targetSize {
+			break
+		}
+
+		out, err := window.byteAt(pos)
+		if err != nil {
+			return err
+		}
+
+		in, err := window.byteAt(pos + block)
+		if err != nil {
+			return err
+		}
+
+		hash.roll(out, in)
+		pos++
+	}
+
+	return e.emitLiteral(literalStart, e.targetSize)
+}
+
+// indexBase indexes the aligned blocks of the base by the weak hash.
+func (e *encoder) indexBase() (map[uint32][]int64, error) {
+	index := make(map[uint32][]int64, e.baseSize/int64(e.blockSize))
+	reader := bufio.NewReaderSize(io.NewSectionReader(e.base, 0, e.baseSize), maxChunkSize)
+	buf := make([]byte, e.blockSize)
+	for offset := int64(0); offset+int64(e.blockSize) <= e.baseSize; offset += int64(e.blockSize) {
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, fmt.Errorf("failed to read base: %w", err)
+		}
+
+		var hash rollingHash
+		hash.init(buf)
+		if sum := hash.sum(); len(index[sum]) < maxCandidates {
+			index[sum] = append(index[sum], offset)
+		}
+	}
+
+	return index, nil
+}
+
+// extend returns the length of the common bytes of the base and the target from the offsets.
+func (e *encoder) extend(baseOffset, targetOffset int64) (int64, error) {
+	baseBuf := make([]byte, e.blockSize)
+	targetBuf := make([]byte, e.blockSize)
+
+	var length int64
+	for {
+		n := min(int64(e.blockSize), e.baseSize-baseOffset-length, e.targetSize-targetOffset-length)
+		if n <= 0 {
+			return length, nil
+		}
+
+		if err := e.readFull(e.base, baseBuf[:n], baseOffset+length); err != nil {
+			return 0, err
+		}
+
+		if err := e.readFull(e.target, targetBuf[:n], targetOffset+length); err != nil {
+			return 0, err
+		}
+
+		for i := range n {
+			if baseBuf[i] != targetBuf[i] {
+				return length + i, nil
+			}
+		}
+
+		length += n
+	}
+}
+
+// emitLiteral emits the unmatched range [start, end) of the target, the part overlapped with
+// the base at the last shift is emitted as the difference, and the rest is inserted.
+func (e *encoder) emitLiteral(start, end int64) error {
+	targetBuf := make([]byte, min(end-start, maxChunkSize))
+	baseBuf := make([]byte, len(targetBuf))
+	for start < end {
+		n := min(end-start, maxChunkSize)
+		if err := e.readFull(e.target, targetBuf[:n], start); err != nil {
+			return err
+		}
+
+		baseOffset := start + e.shift
+		if baseOffset >= 0 && baseOffset < e.baseSize {
+			n = min(n, e.baseSize-baseOffset)
+			if err := e.readFull(e.base, baseBuf[:n], baseOffset); err != nil {
+				return err
+			}
+
+			for i := range n {
+				baseBuf[i] = targetBuf[i] - baseBuf[i]
+			}
+
+			if err := e.emit(opAdd, baseOffset, baseBuf[:n]); err != nil {
+				return err
+			}
+		} else if err := e.emit(opInsert, 0, targetBuf[:n]); err != nil {
+			return err
+		}
+
+		start += n
+	}
+
+	return nil
+}
+
+// emitCopy emits the range copied from the base.
+func (e *encoder) emitCopy(offset, length int64) error {
+	if err := e.w.WriteByte(opCopy); err != nil {
+		return err
+	}
+
+	if err := e.writeUvarint(uint64(offset)); err != nil {
+		return err
+	}
+
+	return e.writeUvarint(uint64(length))
+}
+
+// emit emits the add or insert operation with the data.
+func (e *encoder) emit(op byte, offset int64, data []byte) error {
+	if err := e.w.WriteByte(op); err != nil {
+		return err
+	}
+
+	if op == opAdd {
+		if err := e.writeUvarint(uint64(offset)); err != nil {
+			return err
+		}
+	}
+
+	if err := e.writeUvarint(uint64(len(data))); err != nil {
+		return err
+	}
+
+	_, err := e.w.Write(data)
+	return err
+}
+
+// writeUvarint writes the unsigned varint.
+func (e *encoder) writeUvarint(v uint64) error {
+	_, err := e.w.Write(binary.AppendUvarint(nil, v))
+	return err
+}
+
+// readFull reads the len(buf) bytes at the offset.
+func (e *encoder) readFull(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && n == len(buf)) {
+		return fmt.Errorf("failed to read at offset %d: %w", offset, err)
+	}
+
+	return nil
+}
+
+// rollingHash is the weak rolling checksum of rsync.
+type rollingHash struct {
+	a, b uint32
+	size uint32
+}
+
+// init computes the hash of the block.
+func (h *rollingHash) init(block []byte) {
+	h.a, h.b, h.size = 0, 0, uint32(len(block))
+	for i, c := range block {
+		h.a += uint32(c)
+		h.b += (h.size - uint32(i)) * uint32(c)
+	}
+}
+
+// roll moves the window by one byte.
+func (h *rollingHash) roll(out, in byte) {
+	h.a += uint32(in) - uint32(out)
+	h.b += h.a - h.size*uint32(out)
+}
+
+// sum returns the hash.
+func (h *rollingHash) sum() uint32 {
+	return (h.a & 0xffff) | (h.b&0xffff)<<16
+}
+
+// cachedReader reads the bytes sequentially from the ReaderAt with the buffer.
+type cachedReader struct {
+	r     io.ReaderAt
+	size  int64
+	buf   []byte
+	start int64
+	end   int64
+}
+
+// newCachedReader creates a cached reader with the buffer size.
+func newCachedReader(r io.ReaderAt, size int64, bufSize int) *cachedReader {
+	return &cachedReader{r: r, size: size, buf: make([]byte, bufSize)}
+}
+
+// byteAt returns the byte at the offset, the buffer is refilled from a block before the
+// offset on miss, so the outgoing byte of the rolling window stays cached.
+func (c *cachedReader) byteAt(offset int64) (byte, error) {
+	if offset < c.start || offset >= c.end {
+		start := max(offset-int64(len(c.buf))/4, 0)
+		n, err := c.r.ReadAt(c.buf[:min(int64(len(c.buf)), c.size-start)], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("failed to read at offset %d: %w", start, err)
+		}
+
+		c.start, c.end = start, start+int64(n)
+		if offset >= c.end {
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+
+	return c.buf[offset-c.start], nil
+}
@@ -0,0 +1,101 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delta
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	data := make([]byte, n)
+	r.Read(data)
+	return data
+}
+
+func roundTrip(t *testing.T, base, target []byte, opts ...Option) []byte {
+	var patch bytes.Buffer
+	require.NoError(t, Encode(&patch, bytes.NewReader(base), int64(len(base)), bytes.NewReader(target), int64(len(target)), opts...))
+
+	var output bytes.Buffer
+	require.NoError(t, Decode(&output, bytes.NewReader(base), bytes.NewReader(patch.Bytes())))
+	require.True(t, bytes.Equal(target, output.Bytes()), "reconstructed target differs")
+	return patch.Bytes()
+}
+
+func TestEncodeDecode(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	base := randomBytes(r, 1<<20)
+
+	t.Run("identical", func(t *testing.T) {
+		patch := roundTrip(t, base, base, WithBlockSize(4096))
+		assert.Less(t, len(patch), 1024)
+	})
+
+	t.Run("slightly changed", func(t *testing.T) {
+		// fine-tuning changes the bytes in place, keep the most bytes and perturb some.
+		target := bytes.Clone(base)
+		for i := 0; i < len(target); i += 97 {
+			target[i]++
+		}
+
+		patch := roundTrip(t, base, target, WithBlockSize(4096))
+		assert.Less(t, len(patch), len(target)/4)
+	})
+
+	t.Run("inserted and removed", func(t *testing.T) {
+		target := append([]byte{}, base[:300000]...)
+		target = append(target, randomBytes(r, 12345)...)
+		target = append(target, base[400000:]...)
+
+		patch := roundTrip(t, base, target, WithBlockSize(4096))
+		assert.Less(t, len(patch), 64<<10)
+	})
+
+	t.Run("unaligned shift", func(t *testing.T) {
+		target := append([]byte("new header"), base...)
+		patch := roundTrip(t, base, target, WithBlockSize(4096))
+		assert.Less(t, len(patch), 16<<10)
+	})
+
+	t.Run("unrelated", func(t *testing.T) {
+		roundTrip(t, base, randomBytes(r, 3<<20), WithBlockSize(4096))
+	})
+
+	t.Run("small and empty", func(t *testing.T) {
+		roundTrip(t, base, []byte("tiny"))
+		roundTrip(t, base, []byte{})
+		roundTrip(t, []byte{}, base[:5000])
+		roundTrip(t, []byte("abc"), []byte("abd"))
+	})
+}
+
+func TestDecodeInvalidPatch(t *testing.T) {
+	var patch bytes.Buffer
+	require.NoError(t, Encode(&patch, bytes.NewReader([]byte("base")), 4, bytes.NewReader([]byte("target")), 6))
+
+	// the base is shorter than the diffed range.
+	err := Decode(&bytes.Buffer{}, bytes.NewReader([]byte("b")), bytes.NewReader(patch.Bytes()))
+	assert.Error(t, err)
+
+	err = Decode(&bytes.Buffer{}, bytes.NewReader([]byte("base")), bytes.NewReader([]byte("not a patch")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package diskspace checks the free space of the filesystems before writing the
+// large model artifacts, so the operations fail early instead of running out of
+// the space halfway.
+package diskspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// ErrInsufficient is returned when the filesystem has not enough free space.
+var ErrInsufficient = errors.New("insufficient disk space")
+
+// ErrUnsupported is returned when the platform does not support the free space check,
+// the caller should skip the check gracefully.
+var ErrUnsupported = errors.New("free space check is not supported")
+
+// filesystem is the free space and the device of the filesystem.
+type filesystem struct {
+	// device identifies the filesystem hosting the path.
+	device uint64
+	// available is the free space in bytes available to the unprivileged users.
+	available uint64
+}
+
+// statFilesystem stats the filesystem of the path, which is replaced in tests to fake the free space.
+var statFilesystem = statfs
+
+// Requirement is the space required to be written under the path.
+type Requirement struct {
+	// Path is the directory written, which may not exist yet.
+	Path string
+	// Size is the number of the bytes written.
+	Size int64
+}
+
+// Available returns the free space in bytes available to the unprivileged users on the
+// filesystem of the path, the nearest existing parent is used if the path does not exist.
+func Available(path string) (uint64, error) {
+	fs, err := stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return fs.available, nil
+}
+
+// Check checks the filesystems have enough free space for the requirements, the requirements
+// on the same filesystem are summed up. It returns ErrInsufficient with the required and
+// available space if any filesystem is short of the space.
+func Check(reqs ...Requirement) error {
+	type usage struct {
+		filesystem
+		path     string
+		required uint64
+	}
+
+	var usages []*usage
+	byDevice := map[uint64]*usage{}
+	for _, req := range reqs {
+		if req.Size <= 0 {
+			continue
+		}
+
+		fs, err := stat(req.Path)
+		if err != nil {
+			return err
+		}
+
+		u, ok := byDevice[fs.device]
+		if !ok {
+			u = &usage{filesystem: fs, path: req.Path}
+			byDevice[fs.device] = u
+			usages = append(usages, u)
+		}
+
+		u.required += uint64(req.Size)
+	}
+
+	for _, u := range usages {
+		if u.required > u.available {
+			return fmt.Errorf("%w on the filesystem of %s: %s required, %s available", ErrInsufficient, u.path, humanize.IBytes(u.required), humanize.IBytes(u.available))
+		}
+	}
+
+	return nil
+}
+
+// stat stats the filesystem of the nearest existing parent of the path.
+func stat(path string) (filesystem, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filesystem{}, fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+
+	for {
+		fs, err := statFilesystem(absPath)
+		if err == nil || !os.IsNotExist(err) {
+			return fs, err
+		}
+
+		parent := filepath.Dir(absPath)
+		if parent == absPath {
+			return filesystem{}, err
+		}
+
+		absPath = parent
+	}
+}
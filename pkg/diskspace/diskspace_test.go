@@ -0,0 +1,93 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diskspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFilesystems fakes the filesystems mounted at the paths, the path not mounted belongs
+// to the filesystem of its parent.
+func fakeFilesystems(t *testing.T, mounts map[string]filesystem) {
+	t.Helper()
+	original := statFilesystem
+	t.Cleanup(func() { statFilesystem = original })
+
+	statFilesystem = func(path string) (filesystem, error) {
+		if _, err := os.Stat(path); err != nil {
+			return filesystem{}, err
+		}
+
+		for ; ; path = filepath.Dir(path) {
+			if fs, ok := mounts[path]; ok {
+				return fs, nil
+			}
+
+			if filepath.Dir(path) == path {
+				return filesystem{}, errors.New("not mounted")
+			}
+		}
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	available, err := Available(t.TempDir())
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("free space check is not supported on this platform")
+	}
+	require.NoError(t, err)
+	assert.Greater(t, available, uint64(0))
+
+	// The nearest existing parent is used for the path not created yet.
+	_, err = Available(filepath.Join(t.TempDir(), "not", "exist"))
+	assert.NoError(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	storageDir, extractDir := t.TempDir(), t.TempDir()
+	fakeFilesystems(t, map[string]filesystem{
+		storageDir: {device: 1, available: 100},
+		extractDir: {device: 2, available: 50},
+	})
+
+	assert.NoError(t, Check(Requirement{Path: storageDir, Size: 100}, Requirement{Path: extractDir, Size: 50}))
+	assert.NoError(t, Check(Requirement{Path: filepath.Join(extractDir, "model"), Size: 0}))
+
+	err := Check(Requirement{Path: storageDir, Size: 100}, Requirement{Path: filepath.Join(extractDir, "model"), Size: 51})
+	assert.ErrorIs(t, err, ErrInsufficient)
+	assert.Contains(t, err.Error(), "51 B required, 50 B available")
+}
+
+func TestCheckSameFilesystem(t *testing.T) {
+	root := t.TempDir()
+	storageDir, extractDir := filepath.Join(root, "storage"), filepath.Join(root, "extract")
+	require.NoError(t, os.Mkdir(storageDir, 0755))
+	fakeFilesystems(t, map[string]filesystem{root: {device: 1, available: 1 << 30}})
+
+	// The requirements on the same filesystem are summed up.
+	assert.NoError(t, Check(Requirement{Path: storageDir, Size: 512 << 20}, Requirement{Path: extractDir, Size: 512 << 20}))
+
+	err := Check(Requirement{Path: storageDir, Size: 512 << 20}, Requirement{Path: extractDir, Size: 512<<20 + 1})
+	assert.ErrorIs(t, err, ErrInsufficient)
+	assert.Contains(t, err.Error(), "1.0 GiB required, 1.0 GiB available")
+}
@@ -0,0 +1,40 @@
+//go:build linux || darwin
+
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diskspace
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func statfs(path string) (filesystem, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return filesystem{}, &os.PathError{Op: "stat", Path: path, Err: err}
+	}
+
+	var sfs unix.Statfs_t
+	if err := unix.Statfs(path, &sfs); err != nil {
+		return filesystem{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+
+	// Bavail excludes the blocks reserved for the root user.
+	return filesystem{device: uint64(st.Dev), available: uint64(sfs.Bavail) * uint64(sfs.Bsize)}, nil
+}
@@ -0,0 +1,329 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+const (
+	// AnnotationEncryption is the annotation of the layer which records the encryption
+	// metadata in JSON, the layer is encrypted if the annotation is present.
+	AnnotationEncryption = "org.cncf.modctl.encryption"
+
+	// AlgorithmAES256GCM is the algorithm which seals the content in chunks by AES-256-GCM.
+	AlgorithmAES256GCM = "AES-256-GCM"
+
+	// KeySize is the size of the key in bytes.
+	KeySize = 32
+
+	// defaultChunkSize is the size of the plaintext sealed in each chunk.
+	defaultChunkSize = 64 * 1024
+
+	// maxChunkSize is the upper bound of the chunk size accepted on decryption.
+	maxChunkSize = 16 * 1024 * 1024
+)
+
+var (
+	// ErrDecrypt is returned when the content fails the authentication on decryption.
+	ErrDecrypt = errors.New("failed to decrypt, the key may be wrong or the content is corrupted")
+
+	// ErrKeyMismatch is returned when the content is encrypted by another key.
+	ErrKeyMismatch = errors.New("the content is encrypted by another key")
+)
+
+// Metadata is the encryption metadata recorded in the layer descriptor.
+type Metadata struct {
+	// Algorithm is the algorithm of the encryption.
+	Algorithm string `json:"algorithm"`
+	// ChunkSize is the size of the plaintext sealed in each chunk.
+	ChunkSize int `json:"chunkSize"`
+	// KeyID is the fingerprint of the key which wraps the data key.
+	KeyID string `json:"keyID"`
+	// WrappedKey is the data key of the layer sealed by the key.
+	WrappedKey []byte `json:"wrappedKey"`
+	// Digest is the digest of the plaintext, which checks the decrypted file is up-to-date.
+	Digest string `json:"digest,omitempty"`
+	// Size is the size of the plaintext.
+	Size int64 `json:"size"`
+}
+
+// ParseMetadata parses the encryption metadata from the annotations of the layer,
+// it returns nil if the layer is not encrypted.
+func ParseMetadata(annotations map[string]string) (*Metadata, error) {
+	raw, ok := annotations[AnnotationEncryption]
+	if !ok {
+		return nil, nil
+	}
+
+	var metadata Metadata
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode encryption metadata: %w", err)
+	}
+
+	if metadata.Algorithm != AlgorithmAES256GCM {
+		return nil, fmt.Errorf("unsupported encryption algorithm %q", metadata.Algorithm)
+	}
+
+	if metadata.ChunkSize <= 0 || metadata.ChunkSize > maxChunkSize {
+		return nil, fmt.Errorf("invalid encryption chunk size %d", metadata.ChunkSize)
+	}
+
+	return &metadata, nil
+}
+
+// KeyID returns the fingerprint of the key, which identifies the key without revealing it.
+func KeyID(key []byte) string {
+	sum := checksum.Sum256(key)
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// Envelope encrypts the content of a layer by the random data key, which is wrapped
+// by the key and recorded in the metadata. The encryption of the same envelope is
+// deterministic, so the content can be read twice for the digest and the output.
+type Envelope struct {
+	dataKey  []byte
+	metadata Metadata
+}
+
+// NewEnvelope creates the envelope with a random data key wrapped by the key.
+func NewEnvelope(key []byte) (*Envelope, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrappedKey, err := wrapKey(key, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		dataKey: dataKey,
+		metadata: Metadata{
+			Algorithm:  AlgorithmAES256GCM,
+			ChunkSize:  defaultChunkSize,
+			KeyID:      KeyID(key),
+			WrappedKey: wrappedKey,
+		},
+	}, nil
+}
+
+// Encrypt returns the reader of the ciphertext of the content, the digest and the size of
+// the plaintext are recorded in the metadata once the content is read to the end.
+func (e *Envelope) Encrypt(r io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(e.dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := &plaintextReader{src: r, hash: checksum.NewSHA256(), metadata: &e.metadata}
+	return &chunkReader{src: bufio.NewReader(plaintext), aead: aead, chunkSize: e.metadata.ChunkSize, seal: true}, nil
+}
+
+// plaintextReader hashes the plaintext read from the source, and records its digest and
+// size in the metadata at the end.
+type plaintextReader struct {
+	src      io.Reader
+	hash     hash.Hash
+	size     int64
+	metadata *Metadata
+}
+
+// Read reads the plaintext from the source.
+func (r *plaintextReader) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	r.hash.Write(p[:n])
+	r.size += int64(n)
+	if err == io.EOF {
+		r.metadata.Digest = fmt.Sprintf("sha256:%x", r.hash.Sum(nil))
+		r.metadata.Size = r.size
+	}
+
+	return n, err
+}
+
+// Annotation returns the encryption metadata in JSON for the layer annotation.
+func (e *Envelope) Annotation() (string, error) {
+	data, err := json.Marshal(e.metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode encryption metadata: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// Decrypt returns the reader of the plaintext of the content encrypted with the metadata,
+// the returned reader fails with ErrDecrypt if the content is tampered or truncated.
+func Decrypt(key []byte, metadata *Metadata, r io.Reader) (io.Reader, error) {
+	if metadata.KeyID != "" && metadata.KeyID != KeyID(key) {
+		return nil, fmt.Errorf("%w: encrypted by key %s, but got key %s", ErrKeyMismatch, metadata.KeyID, KeyID(key))
+	}
+
+	dataKey, err := unwrapKey(key, metadata.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chunkReader{src: bufio.NewReader(r), aead: aead, chunkSize: metadata.ChunkSize + aead.Overhead()}, nil
+}
+
+// newAEAD creates the AES-GCM cipher of the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d, expected %d", len(key), KeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// wrapKey seals the data key by the key with a random nonce prefixed.
+func wrapKey(key, dataKey []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+// unwrapKey opens the data key sealed by wrapKey.
+func unwrapKey(key, wrappedKey []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	dataKey, err := aead.Open(nil, wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return dataKey, nil
+}
+
+// chunkReader seals or opens the content in chunks. The nonce of each chunk is derived
+// from its index and whether it is the last one, as the data key is never reused across
+// layers, so the reordered, dropped or truncated chunks fail the authentication.
+type chunkReader struct {
+	src       *bufio.Reader
+	aead      cipher.AEAD
+	chunkSize int
+	seal      bool
+
+	index  uint64
+	buf    []byte
+	result []byte
+	out    []byte
+	done   bool
+	err    error
+}
+
+// Read implements io.Reader.
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.out) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+
+		if c.done {
+			return 0, io.EOF
+		}
+
+		c.err = c.next()
+	}
+
+	n := copy(p, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+// next processes the next chunk of the source.
+func (c *chunkReader) next() error {
+	if c.buf == nil {
+		c.buf = make([]byte, c.chunkSize)
+	}
+
+	n, err := io.ReadFull(c.src, c.buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	// the chunk is the last one if the source is drained.
+	last := err != nil
+	if !last {
+		if _, err := c.src.Peek(1); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			last = true
+		}
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[c.aead.NonceSize()-9:], c.index)
+	if last {
+		nonce[c.aead.NonceSize()-1] = 1
+	}
+
+	if c.seal {
+		c.result = c.aead.Seal(c.result[:0], nonce, c.buf[:n], nil)
+	} else {
+		c.result, err = c.aead.Open(c.result[:0], nonce, c.buf[:n], nil)
+		if err != nil {
+			return ErrDecrypt
+		}
+	}
+
+	c.out = c.result
+
+	c.index++
+	c.done = last
+	return nil
+}
@@ -0,0 +1,173 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newKey returns a random key.
+func newKey(t *testing.T) []byte {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+// encrypt encrypts the content by the envelope.
+func encrypt(t *testing.T, envelope *Envelope, content []byte) []byte {
+	reader, err := envelope.Encrypt(bytes.NewReader(content))
+	require.NoError(t, err)
+	ciphertext, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return ciphertext
+}
+
+// decrypt decrypts the ciphertext by the key with the metadata of the envelope.
+func decrypt(t *testing.T, key []byte, envelope *Envelope, ciphertext []byte) ([]byte, error) {
+	annotation, err := envelope.Annotation()
+	require.NoError(t, err)
+	metadata, err := ParseMetadata(map[string]string{AnnotationEncryption: annotation})
+	require.NoError(t, err)
+
+	reader, err := Decrypt(key, metadata, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+func TestEnvelope(t *testing.T) {
+	key := newKey(t)
+	for _, size := range []int{0, 1, defaultChunkSize - 1, defaultChunkSize, defaultChunkSize + 1, 3*defaultChunkSize + 7} {
+		content := make([]byte, size)
+		_, err := rand.Read(content)
+		require.NoError(t, err)
+
+		envelope, err := NewEnvelope(key)
+		require.NoError(t, err)
+
+		// the encryption is deterministic for the envelope, so the digest matches the output.
+		ciphertext := encrypt(t, envelope, content)
+		assert.Equal(t, ciphertext, encrypt(t, envelope, content))
+		chunks := (size + defaultChunkSize - 1) / defaultChunkSize
+		assert.Equal(t, size+16*max(chunks, 1), len(ciphertext))
+
+		plaintext, err := decrypt(t, key, envelope, ciphertext)
+		require.NoError(t, err, "size %d", size)
+		assert.True(t, bytes.Equal(content, plaintext), "size %d", size)
+
+		// the digest and the size of the plaintext are recorded once the content is read.
+		assert.Equal(t, godigest.FromBytes(content).String(), envelope.metadata.Digest, "size %d", size)
+		assert.Equal(t, int64(size), envelope.metadata.Size, "size %d", size)
+	}
+}
+
+func TestDecryptFailure(t *testing.T) {
+	key := newKey(t)
+	envelope, err := NewEnvelope(key)
+	require.NoError(t, err)
+
+	content := bytes.Repeat([]byte("weights"), defaultChunkSize/2)
+	ciphertext := encrypt(t, envelope, content)
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := decrypt(t, newKey(t), envelope, ciphertext)
+		assert.ErrorIs(t, err, ErrKeyMismatch)
+
+		// the wrapped data key fails the authentication even without the key id.
+		envelope.metadata.KeyID = ""
+		defer func() { envelope.metadata.KeyID = KeyID(key) }()
+		_, err = decrypt(t, newKey(t), envelope, ciphertext)
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("truncated at chunk boundary", func(t *testing.T) {
+		_, err := decrypt(t, key, envelope, ciphertext[:defaultChunkSize+16])
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := decrypt(t, key, envelope, ciphertext[:len(ciphertext)-1])
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := bytes.Clone(ciphertext)
+		tampered[10] ^= 0xff
+		_, err := decrypt(t, key, envelope, tampered)
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+}
+
+func TestParseMetadata(t *testing.T) {
+	metadata, err := ParseMetadata(map[string]string{})
+	require.NoError(t, err)
+	assert.Nil(t, metadata)
+
+	_, err = ParseMetadata(map[string]string{AnnotationEncryption: `{"algorithm":"ROT13","chunkSize":1024}`})
+	assert.ErrorContains(t, err, "unsupported encryption algorithm")
+
+	_, err = ParseMetadata(map[string]string{AnnotationEncryption: `{"algorithm":"AES-256-GCM","chunkSize":0}`})
+	assert.ErrorContains(t, err, "invalid encryption chunk size")
+}
+
+func TestLoadKey(t *testing.T) {
+	key := newKey(t)
+	tempDir := t.TempDir()
+
+	raw := filepath.Join(tempDir, "raw.key")
+	require.NoError(t, os.WriteFile(raw, key, 0600))
+	loaded, err := LoadKey(raw)
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	encoded := filepath.Join(tempDir, "hex.key")
+	require.NoError(t, os.WriteFile(encoded, []byte(hex.EncodeToString(key)+"\n"), 0600))
+	loaded, err = LoadKey("file:" + encoded)
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	t.Setenv("MODCTL_TEST_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(key))
+	loaded, err = LoadKey("env:MODCTL_TEST_ENCRYPTION_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	loaded, err = LoadKey("cmd:cat " + encoded)
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	_, err = LoadKey("env:MODCTL_TEST_MISSING_KEY")
+	assert.Error(t, err)
+
+	invalid := filepath.Join(tempDir, "invalid.key")
+	require.NoError(t, os.WriteFile(invalid, []byte("short"), 0600))
+	_, err = LoadKey(invalid)
+	assert.ErrorContains(t, err, "invalid key")
+}
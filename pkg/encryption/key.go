@@ -0,0 +1,103 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const (
+	// keySourceEnv is the prefix of the key source reading the key from the environment variable.
+	keySourceEnv = "env:"
+	// keySourceFile is the prefix of the key source reading the key from the file.
+	keySourceFile = "file:"
+	// keySourceCmd is the prefix of the key source fetching the key by the command, such as
+	// the CLI of the KMS, which prints the key to the stdout.
+	keySourceCmd = "cmd:"
+)
+
+// loadedKeys caches the loaded keys by the source, so the key is fetched from the KMS
+// once for all the layers.
+var loadedKeys sync.Map
+
+// LoadKey loads the key from the source, which is env:<name>, cmd:<command>, file:<path>
+// or the path of the file. The key is 32 bytes in raw, hex or base64 encoding.
+func LoadKey(source string) ([]byte, error) {
+	if key, ok := loadedKeys.Load(source); ok {
+		return key.([]byte), nil
+	}
+
+	var (
+		content []byte
+		err     error
+	)
+	switch {
+	case strings.HasPrefix(source, keySourceEnv):
+		name := strings.TrimPrefix(source, keySourceEnv)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s of the key is not set", name)
+		}
+
+		content = []byte(value)
+	case strings.HasPrefix(source, keySourceCmd):
+		command := strings.TrimPrefix(source, keySourceCmd)
+		content, err = exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the key by command: %w", err)
+		}
+	default:
+		path := strings.TrimPrefix(source, keySourceFile)
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the key file: %w", err)
+		}
+	}
+
+	key, err := decodeKey(content)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedKeys.Store(source, key)
+	return key, nil
+}
+
+// decodeKey decodes the key in raw, hex or base64 encoding.
+func decodeKey(content []byte) ([]byte, error) {
+	if len(content) == KeySize {
+		return content, nil
+	}
+
+	trimmed := string(bytes.TrimSpace(content))
+	if key, err := hex.DecodeString(trimmed); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(trimmed); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("invalid key, expected %d bytes in raw, hex or base64 encoding", KeySize)
+}
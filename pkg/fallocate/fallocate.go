@@ -0,0 +1,37 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package fallocate preallocates the disk space of the files to their known size
+// before writing, which reduces the fragmentation of the huge model files.
+package fallocate
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrUnsupported is returned when the platform or the filesystem does not support
+// the preallocation, the caller should skip the preallocation gracefully.
+var ErrUnsupported = errors.New("preallocation is not supported")
+
+// Preallocate preallocates the disk space of the file to the size, the file size is
+// kept unchanged so the content can be streamed from the beginning.
+func Preallocate(f *os.File, size int64) error {
+	if size <= 0 {
+		return nil
+	}
+
+	return preallocate(f, size)
+}
@@ -0,0 +1,64 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package fallocate
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreallocate(t *testing.T) {
+	content := bytes.Repeat([]byte("model weights "), 100*1024)
+	path := filepath.Join(t.TempDir(), "model.safetensors")
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	err = Preallocate(f, int64(len(content)))
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("preallocation is not supported on this platform")
+	}
+	require.NoError(t, err)
+
+	// the file size is kept unchanged after preallocation.
+	info, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	_, err = io.Copy(f, bytes.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+func TestPreallocateEmpty(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "empty"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.NoError(t, Preallocate(f, 0))
+}
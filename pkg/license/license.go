@@ -0,0 +1,95 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package license
+
+import (
+	"path"
+	"strings"
+)
+
+// licenseFilePrefixes is the prefixes of the upper-cased base name of the license files.
+var licenseFilePrefixes = []string{"LICENSE", "LICENCE", "COPYING"}
+
+// rule detects the license by the phrases all present in the normalized license text.
+type rule struct {
+	id      string
+	phrases []string
+}
+
+// rules is the rules to detect the licenses, the more specific rules go first, e.g. the
+// LGPL and AGPL are checked before the GPL as their texts mention the GPL as well.
+var rules = []rule{
+	{id: "Apache-2.0", phrases: []string{"apache license", "version 2.0"}},
+	{id: "MIT", phrases: []string{"permission is hereby granted, free of charge"}},
+	{id: "AGPL-3.0", phrases: []string{"gnu affero general public license", "version 3"}},
+	{id: "LGPL-3.0", phrases: []string{"gnu lesser general public license", "version 3"}},
+	{id: "LGPL-2.1", phrases: []string{"gnu lesser general public license", "version 2.1"}},
+	{id: "GPL-3.0", phrases: []string{"gnu general public license", "version 3"}},
+	{id: "GPL-2.0", phrases: []string{"gnu general public license", "version 2"}},
+	{id: "MPL-2.0", phrases: []string{"mozilla public license", "2.0"}},
+	{id: "BSD-3-Clause", phrases: []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{id: "BSD-2-Clause", phrases: []string{"redistribution and use in source and binary forms"}},
+	{id: "CC-BY-NC-4.0", phrases: []string{"attribution-noncommercial 4.0 international"}},
+	{id: "CC-BY-SA-4.0", phrases: []string{"attribution-sharealike 4.0 international"}},
+	{id: "CC-BY-4.0", phrases: []string{"attribution 4.0 international"}},
+	{id: "Unlicense", phrases: []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// IsLicenseFile returns true if the base name of the path looks like a license file,
+// such as LICENSE, LICENSE.txt, LICENSE-MIT and COPYING.
+func IsLicenseFile(filepath string) bool {
+	base := strings.ToUpper(path.Base(filepath))
+	for _, prefix := range licenseFilePrefixes {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Detect detects the SPDX identifier of the license from the text of the license file,
+// it returns empty if the license is unknown.
+func Detect(content []byte) string {
+	text := strings.Join(strings.Fields(strings.ToLower(string(content))), " ")
+	for _, r := range rules {
+		matched := true
+		for _, phrase := range r.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return r.id
+		}
+	}
+
+	return ""
+}
+
+// Known returns true if the id is one of the SPDX identifiers could be detected.
+func Known(id string) bool {
+	for _, r := range rules {
+		if r.id == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package license
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLicenseFile(t *testing.T) {
+	for _, path := range []string{"LICENSE", "LICENSE.txt", "docs/license.md", "LICENSE-MIT", "COPYING", "Licence"} {
+		assert.True(t, IsLicenseFile(path), path)
+	}
+
+	for _, path := range []string{"README.md", "config.json", "LICENSE/model.safetensors", "my-license.txt"} {
+		assert.False(t, IsLicenseFile(path), path)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "apache",
+			content:  "                                 Apache License\n                           Version 2.0, January 2004\n",
+			expected: "Apache-2.0",
+		},
+		{
+			name:     "mit",
+			content:  "MIT License\n\nPermission is hereby granted, free of charge, to any person obtaining a copy",
+			expected: "MIT",
+		},
+		{
+			name:     "lgpl before gpl",
+			content:  "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n... the GNU General Public License ...",
+			expected: "LGPL-3.0",
+		},
+		{
+			name:     "gpl",
+			content:  "GNU GENERAL PUBLIC LICENSE\n   Version 2, June 1991",
+			expected: "GPL-2.0",
+		},
+		{
+			name:     "bsd 3 clause",
+			content:  "Redistribution and use in source and binary forms, with or without modification ... Neither the name of the copyright holder",
+			expected: "BSD-3-Clause",
+		},
+		{
+			name:     "cc by nc",
+			content:  "Creative Commons Attribution-NonCommercial 4.0 International Public License",
+			expected: "CC-BY-NC-4.0",
+		},
+		{
+			name:     "agpl",
+			content:  "                    GNU AFFERO GENERAL PUBLIC LICENSE\n                       Version 3, 19 November 2007\n",
+			expected: "AGPL-3.0",
+		},
+		{
+			name:     "mpl",
+			content:  "Mozilla Public License Version 2.0\n==================================\n\n1. Definitions",
+			expected: "MPL-2.0",
+		},
+		{
+			name:     "bsd 2 clause",
+			content:  "BSD 2-Clause License\n\nRedistribution and use in source and binary forms, with or without\nmodification, are permitted provided that the following conditions are met:",
+			expected: "BSD-2-Clause",
+		},
+		{
+			name:     "cc by",
+			content:  "Attribution 4.0 International\n\n=======================================================================\n\nCreative Commons Corporation",
+			expected: "CC-BY-4.0",
+		},
+		{
+			name:     "unlicense",
+			content:  "This is free and unencumbered software released into the public domain.\n\nAnyone is free to copy, modify, publish, use",
+			expected: "Unlicense",
+		},
+		{
+			name:     "unknown",
+			content:  "LLAMA 3 COMMUNITY LICENSE AGREEMENT",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Detect([]byte(tc.content)))
+		})
+	}
+}
+
+func TestKnown(t *testing.T) {
+	assert.True(t, Known("Apache-2.0"))
+	assert.False(t, Known("llama3"))
+}
@@ -0,0 +1,65 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logging
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatText is the human readable text format of the logs.
+	FormatText = "text"
+
+	// FormatJSON is the JSON format of the logs, one object per line for the log aggregation.
+	FormatJSON = "json"
+)
+
+// NewFormatter returns the logrus formatter of the format.
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case FormatText, "":
+		return &logrus.TextFormatter{}, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}, nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// Event returns the log entry of the key event of the operation with the structured fields,
+// such as the pushed reference, the manifest digest and the duration since the start.
+func Event(operation, reference, digest string, start time.Time) *logrus.Entry {
+	return EventWithLogger(logrus.StandardLogger(), operation, reference, digest, start)
+}
+
+// EventWithLogger returns the log entry of the key event by the logger.
+func EventWithLogger(logger *logrus.Logger, operation, reference, digest string, start time.Time) *logrus.Entry {
+	fields := logrus.Fields{
+		"operation": operation,
+		"reference": reference,
+		"duration":  time.Since(start).Round(time.Millisecond).String(),
+	}
+
+	if digest != "" {
+		fields["digest"] = digest
+	}
+
+	return logger.WithFields(fields)
+}
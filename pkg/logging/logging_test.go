@@ -0,0 +1,79 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFormatter(t *testing.T) {
+	formatter, err := NewFormatter(FormatText)
+	require.NoError(t, err)
+	assert.IsType(t, &logrus.TextFormatter{}, formatter)
+
+	formatter, err = NewFormatter(FormatJSON)
+	require.NoError(t, err)
+	assert.IsType(t, &logrus.JSONFormatter{}, formatter)
+
+	_, err = NewFormatter("xml")
+	assert.ErrorContains(t, err, "invalid log format")
+}
+
+func TestJSONEvent(t *testing.T) {
+	formatter, err := NewFormatter(FormatJSON)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(formatter)
+
+	start := time.Now().Add(-1500 * time.Millisecond)
+	EventWithLogger(logger, "push", "registry.com/models/llama3:v1", "sha256:abc", start).Infof("push: pushed artifact %s", "registry.com/models/llama3:v1")
+	EventWithLogger(logger, "build", "registry.com/models/llama3:v2", "", start).Info("build: built artifact")
+
+	lines := []map[string]any{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		lines = append(lines, line)
+	}
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "info", lines[0]["level"])
+	assert.Equal(t, "push: pushed artifact registry.com/models/llama3:v1", lines[0]["msg"])
+	assert.Equal(t, "push", lines[0]["operation"])
+	assert.Equal(t, "registry.com/models/llama3:v1", lines[0]["reference"])
+	assert.Equal(t, "sha256:abc", lines[0]["digest"])
+	duration, err := time.ParseDuration(lines[0]["duration"].(string))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, duration, 1500*time.Millisecond)
+	_, err = time.Parse(time.RFC3339Nano, lines[0]["time"].(string))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "build", lines[1]["operation"])
+	assert.NotContains(t, lines[1], "digest")
+}
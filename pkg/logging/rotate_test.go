@@ -0,0 +1,89 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "pull.log")
+	file, err := OpenRotatingFile(path, 0, 0)
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetOutput(file)
+	logger.Infof("pull: pulling artifact %s", "registry.com/models/llama3:v1")
+	logger.Warn("pull: retrying the layer")
+	require.NoError(t, file.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "pull: pulling artifact registry.com/models/llama3:v1")
+	assert.Contains(t, string(content), "pull: retrying the layer")
+
+	// the logs are appended to the existing file.
+	file, err = OpenRotatingFile(path, 0, 0)
+	require.NoError(t, err)
+	logger.SetOutput(file)
+	logger.Info("pull: resumed")
+	require.NoError(t, file.Close())
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "pull: pulling artifact")
+	assert.Contains(t, string(content), "pull: resumed")
+}
+
+func TestRotatingFileRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "modctl.log")
+	file, err := OpenRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+
+	read := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	assert.Equal(t, "fourth\n", read(path))
+	assert.Equal(t, "third\n", read(path+".1"))
+	assert.Equal(t, "second\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+
+	// the line larger than the max size is written to the empty file without rotation.
+	file, err = OpenRotatingFile(path, 10, 0)
+	require.NoError(t, err)
+	_, err = file.Write([]byte(strings.Repeat("x", 20) + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.Equal(t, strings.Repeat("x", 20)+"\n", read(path))
+	assert.Equal(t, "third\n", read(path+".1"))
+}
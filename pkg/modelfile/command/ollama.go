@@ -0,0 +1,47 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+// Define the directives of the Ollama Modelfile recognized in the interop mode of the parser,
+// which helps to migrate the Modelfiles of Ollama. The native commands are authoritative.
+const (
+	// FROM is the directive to set the base model, which is either the name of the model, such
+	// as llama3.2, or the path of the model file, such as ./model.gguf. The path relative to the
+	// work directory is built as the model file if there is no MODEL command in the modelfile.
+	FROM = "FROM"
+
+	// PARAMETER is the directive to set the parameter of the model for the inference, such as
+	// temperature 0.7. The PARAMETER directive can be used multiple times in a modelfile, and
+	// the values of the same parameter are kept in order, such as the stop sequences.
+	PARAMETER = "PARAMETER"
+
+	// TEMPLATE is the directive to set the prompt template of the model, which can span
+	// multiple lines by the triple quotes.
+	TEMPLATE = "TEMPLATE"
+
+	// SYSTEM is the directive to set the system message of the model, which can span
+	// multiple lines by the triple quotes.
+	SYSTEM = "SYSTEM"
+)
+
+// OllamaCommands is the directives of the Ollama Modelfile recognized in the interop mode.
+var OllamaCommands = []string{
+	FROM,
+	PARAMETER,
+	TEMPLATE,
+	SYSTEM,
+}
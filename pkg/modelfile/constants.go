@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/dustin/go-humanize"
 )
 
@@ -146,12 +147,32 @@ var (
 		"*.circle",      // Samsung Circle format
 		"*.nb",          // Neural Network Binary format
 
-		// Data and dataset formats.
-		"*.arrow",   // Apache Arrow columnar format
-		"*.parquet", // Apache Parquet columnar format
-		"*.ftz",     // FastText compressed model
-		"*.ark",     // Kaldi ark format (speech/audio models)
-		"*.db",      // Database files (LMDB, etc.)
+		// Data formats of the models.
+		"*.ftz", // FastText compressed model
+		"*.ark", // Kaldi ark format (speech/audio models)
+		"*.db",  // Database files (LMDB, etc.)
+	}
+
+	// Dataset file patterns - supported dataset formats, the csv and jsonl files are only
+	// classified as datasets by the split names as they are commonly used for the configs
+	// and docs as well.
+	DatasetFilePatterns = []string{
+		// Columnar and record formats.
+		"*.arrow",     // Apache Arrow columnar format
+		"*.parquet",   // Apache Parquet columnar format
+		"*.feather",   // Apache Arrow Feather format
+		"*.orc",       // Apache ORC columnar format
+		"*.avro",      // Apache Avro record format
+		"*.tfrecord",  // TensorFlow TFRecord format
+		"*.tfrecords", // TensorFlow TFRecord format
+		"*.jsonl.gz",  // Compressed JSON Lines format
+		"*.csv.gz",    // Compressed Comma-Separated Values
+
+		// Dataset splits in csv, tsv and jsonl.
+		"train*.csv", "train*.tsv", "train*.jsonl",
+		"test*.csv", "test*.tsv", "test*.jsonl",
+		"valid*.csv", "valid*.tsv", "valid*.jsonl",
+		"eval*.csv", "eval*.tsv", "eval*.jsonl",
 	}
 
 	// Code file patterns - supported script and notebook files.
@@ -441,8 +462,45 @@ var (
 		"*.pyo",       // Python optimized bytecode
 		"*.pyd",       // Python dynamic modules
 	}
+
+	// Text file rules - the .txt files are classified by the path and the name as the same
+	// extension is shared by the tokenizer vocabularies, the requirements and the docs, the
+	// first matching rule wins and the .txt files matching none of them are docs.
+	TextFileRules = []TextFileRule{
+		// Tokenizer files.
+		{"**/vocab.txt", FileTypeConfig},
+		{"**/merges.txt", FileTypeConfig},
+		{"**/added_tokens.txt", FileTypeConfig},
+
+		// Python requirements, including the ones in the requirements directory.
+		{"**/requirements*.txt", FileTypeCode},
+		{"**/*[-_.]requirements.txt", FileTypeCode},
+		{"**/requirements/*.txt", FileTypeCode},
+		{"**/constraints*.txt", FileTypeCode},
+
+		// Build files.
+		{"**/CMakeLists.txt", FileTypeCode},
+
+		// Docs named by the convention.
+		{"**/README*.txt", FileTypeDoc},
+		{"**/LICENSE*.txt", FileTypeDoc},
+		{"**/NOTICE*.txt", FileTypeDoc},
+
+		// Dataset files in the data directories.
+		{"**/data/**/*.txt", FileTypeDataset},
+		{"**/datasets/**/*.txt", FileTypeDataset},
+	}
 )
 
+// TextFileRule classifies the .txt files whose path matches the pattern as the file type.
+type TextFileRule struct {
+	// Pattern is the doublestar pattern matched against the slash-separated path relative
+	// to the workspace case-insensitively.
+	Pattern string `yaml:"pattern"`
+	// Type is the file type of the matched files.
+	Type FileType `yaml:"type"`
+}
+
 // FileType represents the inferred type of a file.
 type FileType int
 
@@ -451,13 +509,37 @@ const (
 	FileTypeModel
 	FileTypeCode
 	FileTypeDoc
+	FileTypeDataset
 )
 
 // InferFileType determines the file type by extension matching first,
 // then falls back to a size-based heuristic for unrecognized files:
-// >128MB -> FileTypeModel, otherwise -> FileTypeCode.
+// >128MB -> FileTypeModel, otherwise -> FileTypeCode. The patterns extended
+// by the classification rules take precedence over the text rules of the
+// classification rules, then the overridden patterns, and the other .txt files
+// are classified by the TextFileRules against the path.
 func InferFileType(filename string, fileSize int64) FileType {
+	if fileType, ok := inferExtendedFileType(filename); ok {
+		return fileType
+	}
+
+	if fileType, ok := matchTextFileRules(filename, userTextFileRules); ok {
+		return fileType
+	}
+
+	if fileType, ok := inferOverriddenFileType(filename); ok {
+		return fileType
+	}
+
+	if fileType, ok := inferTextFileType(filename); ok {
+		return fileType
+	}
+
+	// The datasets are matched first as the split names are more specific than the
+	// generic csv and jsonl patterns of the configs and docs.
 	switch {
+	case IsFileType(filename, DatasetFilePatterns):
+		return FileTypeDataset
 	case IsFileType(filename, ConfigFilePatterns):
 		return FileTypeConfig
 	case IsFileType(filename, ModelFilePatterns):
@@ -497,6 +579,36 @@ func IsFileType(filename string, patterns []string) bool {
 	return false
 }
 
+// inferTextFileType returns the file type of the first text file rule matching the path of the
+// .txt file, the .txt files matching none of the rules are docs.
+func inferTextFileType(path string) (FileType, bool) {
+	if !strings.HasSuffix(strings.ToLower(path), ".txt") {
+		return 0, false
+	}
+
+	if fileType, ok := matchTextFileRules(path, TextFileRules); ok {
+		return fileType, true
+	}
+
+	return FileTypeDoc, true
+}
+
+// matchTextFileRules returns the file type of the first rule matching the path of the .txt file.
+func matchTextFileRules(path string, rules []TextFileRule) (FileType, bool) {
+	lowerPath := strings.TrimPrefix(filepath.ToSlash(strings.ToLower(path)), "/")
+	if !strings.HasSuffix(lowerPath, ".txt") {
+		return 0, false
+	}
+
+	for _, rule := range rules {
+		if matched, err := doublestar.Match(strings.ToLower(rule.Pattern), lowerPath); err == nil && matched {
+			return rule.Type, true
+		}
+	}
+
+	return 0, false
+}
+
 // isSkippable checks if the filename matches any of the skip patterns
 func isSkippable(filename string) bool {
 	// Special handling for current and parent directory
@@ -37,9 +37,7 @@ func TestIsFileTypeModelPatterns(t *testing.T) {
 		filename string
 		expected bool
 	}{
-		// New data/dataset formats.
-		{"dataset.arrow", true},
-		{"train.parquet", true},
+		// Data formats of the models.
 		{"model.ftz", true},
 		{"feats.ark", true},
 		{"training.db", true},
@@ -66,6 +64,8 @@ func TestIsFileTypeModelPatterns(t *testing.T) {
 		{"merges.txt", false},
 		{"readme.txt", false},
 		{"script.py", false},
+		{"dataset.arrow", false}, // arrow and parquet moved to DatasetFilePatterns
+		{"train.parquet", false},
 		{"events.out.tfevents.1679012345.hostname", false}, // tfevents moved to DocFilePatterns
 	}
 
@@ -141,6 +141,12 @@ func TestInferFileType(t *testing.T) {
 		{"sentencepiece bpe model", "sentencepiece.bpe.model", 1024, FileTypeConfig},
 		{"tiktoken model", "tiktoken.model", 1024, FileTypeConfig},
 		{"chat template jinja", "chat_template.jinja", 1024, FileTypeConfig},
+		{"dataset parquet", "train.parquet", 1024, FileTypeDataset},
+		{"dataset tfrecord", "shard-0.tfrecord", 1024, FileTypeDataset},
+		{"dataset split jsonl", "train.jsonl", 1024, FileTypeDataset},
+		{"dataset split csv", "validation.csv", 1024, FileTypeDataset},
+		{"generic jsonl is config", "data.jsonl", 1024, FileTypeConfig},
+		{"generic csv is doc", "notes.csv", 1024, FileTypeDoc},
 
 		// Dotfile with known secondary extension
 		{".cache.json is config", ".cache.json", 1024, FileTypeConfig},
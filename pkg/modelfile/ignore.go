@@ -0,0 +1,158 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFilename is the name of the file at the workspace root listing the paths to
+// exclude from the workspace scan in the gitignore style.
+const IgnoreFilename = ".modctlignore"
+
+// ignoreRule is a parsed pattern of the ignore file.
+type ignoreRule struct {
+	// pattern is the doublestar pattern matched against the slash-separated relative path.
+	pattern string
+	// negate indicates the pattern is prefixed by ! to re-include the matched paths.
+	negate bool
+	// dirOnly indicates the pattern ends with / to match the directories only.
+	dirOnly bool
+}
+
+// ignoreMatcher matches the relative paths of the workspace against the ignore rules,
+// the last matching rule decides whether the path is ignored as gitignore does.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// loadIgnorePatterns loads the patterns of the ignore file at the workspace root, the blank
+// lines and the comments starting with # are dropped, it returns no patterns if the file
+// does not exist.
+func loadIgnorePatterns(workspace string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(workspace, IgnoreFilename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFilename, err)
+	}
+
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFilename, err)
+	}
+
+	return patterns, nil
+}
+
+// NewWorkspaceIgnore returns the function reporting whether the relative path of the workspace
+// is left out of the workspace scan, by the skip patterns such as the hidden files and by the
+// .modctlignore at the workspace root.
+func NewWorkspaceIgnore(workspace string) (func(relPath string, isDir bool) bool, error) {
+	patterns, err := loadIgnorePatterns(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher, err := newIgnoreMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(relPath string, isDir bool) bool {
+		return isSkippable(filepath.Base(relPath)) || matcher.Match(relPath, isDir)
+	}, nil
+}
+
+// newIgnoreMatcher creates the matcher by the gitignore-style patterns, the pattern without a
+// slash except the trailing one matches the name at any depth, otherwise it is anchored at the
+// workspace root, and the leading \ escapes the # and ! of the names.
+func newIgnoreMatcher(patterns []string) (*ignoreMatcher, error) {
+	matcher := &ignoreMatcher{}
+	for _, pattern := range patterns {
+		p := pattern
+		var rule ignoreRule
+		if strings.HasPrefix(p, "!") {
+			rule.negate = true
+			p = p[1:]
+		} else if strings.HasPrefix(p, `\#`) || strings.HasPrefix(p, `\!`) {
+			p = p[1:]
+		}
+
+		if strings.HasSuffix(p, "/") {
+			rule.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+
+		if p == "" {
+			continue
+		}
+
+		if strings.Contains(p, "/") {
+			p = strings.TrimPrefix(p, "/")
+		} else {
+			p = "**/" + p
+		}
+
+		if !doublestar.ValidatePattern(p) {
+			return nil, fmt.Errorf("invalid pattern %q in %s", pattern, IgnoreFilename)
+		}
+
+		rule.pattern = p
+		matcher.rules = append(matcher.rules, rule)
+	}
+
+	return matcher, nil
+}
+
+// Match checks if the relative path of the workspace is ignored.
+func (m *ignoreMatcher) Match(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		if matched, _ := doublestar.Match(rule.pattern, relPath); matched {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
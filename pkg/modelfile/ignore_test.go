@@ -0,0 +1,98 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadIgnorePatterns(t *testing.T) {
+	workspace := t.TempDir()
+
+	// No ignore file.
+	patterns, err := loadIgnorePatterns(workspace)
+	require.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	content := "# checkpoints\ncheckpoint-*/\n\n*.bin  \n!keep.bin\n\\#notes.md\n"
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, IgnoreFilename), []byte(content), 0644))
+
+	patterns, err = loadIgnorePatterns(workspace)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"checkpoint-*/", "*.bin", "!keep.bin", `\#notes.md`}, patterns)
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	matcher, err := newIgnoreMatcher([]string{
+		"checkpoint-*/",
+		"*.bin",
+		"!keep.bin",
+		"/scratch",
+		"logs/*.txt",
+		`\#notes.md`,
+		"cache/",
+	})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"checkpoint-100", true, true},
+		{"runs/checkpoint-200", true, true},
+		{"checkpoint-100", false, false},
+		{"model.bin", false, true},
+		{"sub/model.bin", false, true},
+		{"keep.bin", false, false},
+		{"sub/keep.bin", false, false},
+		{"scratch", true, true},
+		{"sub/scratch", true, false},
+		{"logs/train.txt", false, true},
+		{"logs/sub/train.txt", false, false},
+		{"#notes.md", false, true},
+		{"cache", true, true},
+		{"cache", false, false},
+		{"model.safetensors", false, false},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, matcher.Match(tc.path, tc.isDir), "path: %s", tc.path)
+	}
+
+	_, err = newIgnoreMatcher([]string{"[invalid"})
+	assert.ErrorContains(t, err, `invalid pattern "[invalid"`)
+}
+
+func TestNewWorkspaceIgnore(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, IgnoreFilename), []byte("logs/\n"), 0644))
+
+	ignored, err := NewWorkspaceIgnore(workspace)
+	require.NoError(t, err)
+	assert.True(t, ignored(".cache", true))
+	assert.True(t, ignored("keep/.gitkeep", false))
+	assert.True(t, ignored("src/__pycache__", true))
+	assert.True(t, ignored("outputs/logs", true))
+	assert.False(t, ignored("outputs/checkpoints", true))
+	assert.False(t, ignored("model.safetensors", false))
+}
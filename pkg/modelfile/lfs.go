@@ -0,0 +1,59 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// lfsPointerPrefix is the prefix of the git-lfs pointer file, which is the version line
+	// such as "version https://git-lfs.github.com/spec/v1".
+	lfsPointerPrefix = "version https://git-lfs"
+
+	// lfsPointerMaxSize is the maximum size of the git-lfs pointer file defined by the spec.
+	lfsPointerMaxSize = 1024
+)
+
+// IsLFSPointer returns whether the file is a git-lfs pointer stub instead of the actual object,
+// which happens when the repository is cloned without pulling the LFS objects.
+func IsLFSPointer(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if !info.Mode().IsRegular() || info.Size() >= lfsPointerMaxSize || info.Size() < int64(len(lfsPointerPrefix)) {
+		return false, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	prefix := make([]byte, len(lfsPointerPrefix))
+	if _, err := io.ReadFull(file, prefix); err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return bytes.Equal(prefix, []byte(lfsPointerPrefix)), nil
+}
@@ -0,0 +1,60 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lfsPointer = `version https://git-lfs.github.com/spec/v1
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`
+
+func TestIsLFSPointer(t *testing.T) {
+	dir := t.TempDir()
+	testcases := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"pointer", lfsPointer, true},
+		{"weights", "\x08\x00\x00\x00\x00\x00\x00\x00{}", false},
+		{"empty", "", false},
+		{"large file with the pointer prefix", lfsPointer + strings.Repeat("x", lfsPointerMaxSize), false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+
+			pointer, err := IsLFSPointer(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, pointer)
+		})
+	}
+
+	_, err := IsLFSPointer(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// paramsizeUnits is the multiplier of the unit suffixes of the paramsize.
+var paramsizeUnits = map[byte]float64{
+	'k': 1e3,
+	'm': 1e6,
+	'b': 1e9,
+	't': 1e12,
+}
+
+// precisionAliases maps the common aliases of the precisions to the torch_dtype names,
+// which are the names generated from the config.json and the safetensors.
+var precisionAliases = map[string]string{
+	"fp16":     "float16",
+	"f16":      "float16",
+	"half":     "float16",
+	"bf16":     "bfloat16",
+	"fp32":     "float32",
+	"f32":      "float32",
+	"float":    "float32",
+	"fp64":     "float64",
+	"f64":      "float64",
+	"double":   "float64",
+	"i8":       "int8",
+	"fp8":      "float8_e4m3fn",
+	"f8_e4m3":  "float8_e4m3fn",
+	"fp8_e4m3": "float8_e4m3fn",
+	"f8_e5m2":  "float8_e5m2",
+	"fp8_e5m2": "float8_e5m2",
+}
+
+// Metadata is the metadata of the model detected from the safetensors files.
+type Metadata struct {
+	// ParamCount is the number of the parameters summed over the tensors.
+	ParamCount uint64
+	// Precision is the precision of the most common dtype of the tensors.
+	Precision string
+}
+
+// DetectMetadata detects the metadata of the model from the headers of the safetensors
+// files without reading the tensor data.
+func DetectMetadata(paths []string) (*Metadata, error) {
+	var params uint64
+	dtypes := make(map[string]int)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, _, err := readSafetensorsHeader(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read safetensors %s: %w", path, err)
+		}
+
+		for name, raw := range entries {
+			if name == safetensorsMetadataKey {
+				continue
+			}
+
+			var tensor safetensorsTensor
+			if err := json.Unmarshal(raw, &tensor); err != nil {
+				return nil, fmt.Errorf("failed to decode safetensors tensor %s: %w", name, err)
+			}
+
+			elements := uint64(1)
+			for _, dim := range tensor.Shape {
+				elements *= dim
+			}
+
+			params += elements
+			if tensor.Dtype != "" {
+				dtypes[strings.ToUpper(tensor.Dtype)]++
+			}
+		}
+	}
+
+	return &Metadata{ParamCount: params, Precision: dominantPrecision(dtypes)}, nil
+}
+
+// ParseParamsize parses the paramsize to the number of the parameters, such as 7B, 1.5b,
+// 500M, or 8x7B of the mixture of experts which is counted as the product.
+func ParseParamsize(paramsize string) (float64, error) {
+	value := strings.ToLower(strings.TrimSpace(paramsize))
+	if value == "" {
+		return 0, fmt.Errorf("empty paramsize")
+	}
+
+	multiplier, ok := paramsizeUnits[value[len(value)-1]]
+	if ok {
+		value = value[:len(value)-1]
+	} else {
+		multiplier = 1
+	}
+
+	experts := 1.0
+	if before, after, found := strings.Cut(value, "x"); found {
+		n, err := strconv.ParseFloat(before, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid paramsize %q: %w", paramsize, err)
+		}
+
+		experts, value = n, after
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid paramsize %q", paramsize)
+	}
+
+	return experts * n * multiplier, nil
+}
+
+// FormatParamsize formats the number of the parameters in the paramsize notation, such as 6.7B.
+func FormatParamsize(params uint64) string {
+	value := float64(params)
+	for _, unit := range []struct {
+		suffix string
+		size   float64
+	}{{"T", 1e12}, {"B", 1e9}, {"M", 1e6}, {"K", 1e3}} {
+		if value >= unit.size {
+			return strconv.FormatFloat(value/unit.size, 'f', 1, 64) + unit.suffix
+		}
+	}
+
+	return strconv.FormatUint(params, 10)
+}
+
+// NormalizePrecision normalizes the precision to the torch_dtype name to compare the aliases,
+// such as bf16 and torch.bfloat16 to bfloat16.
+func NormalizePrecision(precision string) string {
+	value := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(precision)), "torch.")
+	if alias, ok := precisionAliases[value]; ok {
+		return alias
+	}
+
+	return value
+}
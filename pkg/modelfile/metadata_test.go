@@ -0,0 +1,109 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSafetensorsShapes writes a synthetic safetensors file of the tensors with the shapes in the dtype.
+func writeSafetensorsShapes(t *testing.T, path, dtype string, shapes ...[]uint64) {
+	header := map[string]interface{}{}
+	for i, shape := range shapes {
+		header["tensor"+string(rune('a'+i))] = map[string]interface{}{
+			"dtype":        dtype,
+			"shape":        shape,
+			"data_offsets": []int{0, 0},
+		}
+	}
+
+	data, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	buf := make([]byte, 8, 8+len(data))
+	binary.LittleEndian.PutUint64(buf, uint64(len(data)))
+	buf = append(buf, data...)
+	require.NoError(t, os.WriteFile(path, buf, 0644))
+}
+
+func TestDetectMetadata(t *testing.T) {
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "model-00001.safetensors")
+	second := filepath.Join(tempDir, "model-00002.safetensors")
+	writeSafetensorsShapes(t, first, "BF16", []uint64{4096, 4096}, []uint64{4096})
+	writeSafetensorsShapes(t, second, "BF16", []uint64{32000, 4096})
+	writeSafetensors(t, filepath.Join(tempDir, "extra.safetensors"), "F32")
+
+	metadata, err := DetectMetadata([]string{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4096*4096+4096+32000*4096), metadata.ParamCount)
+	assert.Equal(t, "bfloat16", metadata.Precision)
+
+	_, err = DetectMetadata([]string{filepath.Join(tempDir, "missing.safetensors")})
+	assert.Error(t, err)
+}
+
+func TestParseParamsize(t *testing.T) {
+	testCases := []struct {
+		paramsize string
+		expected  float64
+		expectErr bool
+	}{
+		{"7B", 7e9, false},
+		{"1.5b", 1.5e9, false},
+		{"500M", 5e8, false},
+		{"8x7B", 56e9, false},
+		{"1T", 1e12, false},
+		{"123456", 123456, false},
+		{"", 0, true},
+		{"seven", 0, true},
+		{"-1B", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.paramsize, func(t *testing.T) {
+			actual, err := ParseParamsize(tc.paramsize)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.InDelta(t, tc.expected, actual, 1)
+		})
+	}
+}
+
+func TestFormatParamsize(t *testing.T) {
+	assert.Equal(t, "6.7B", FormatParamsize(6_738_415_616))
+	assert.Equal(t, "125.0M", FormatParamsize(125_000_000))
+	assert.Equal(t, "512", FormatParamsize(512))
+}
+
+func TestNormalizePrecision(t *testing.T) {
+	assert.Equal(t, "bfloat16", NormalizePrecision("BF16"))
+	assert.Equal(t, "bfloat16", NormalizePrecision("torch.bfloat16"))
+	assert.Equal(t, "float16", NormalizePrecision("fp16"))
+	assert.Equal(t, "int4", NormalizePrecision("INT4"))
+}
@@ -20,10 +20,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
 	modefilecommand "github.com/modelpack/modctl/pkg/modelfile/command"
@@ -32,6 +35,10 @@ import (
 	"github.com/emirpasic/gods/sets/hashset"
 )
 
+// paramsizePattern matches the paramsize token in the model name, such as 7b, 72B, 1.5B,
+// 8x7B and 350M. The token must be surrounded by the separators or the boundaries.
+var paramsizePattern = regexp.MustCompile(`(?i)(?:^|[-_.\s])((?:\d+x)?\d+(?:\.\d+)?[bmt])(?:$|[-_.\s])`)
+
 // Modelfile is the interface for the modelfile. It is used to parse
 // the modelfile by the path and get the information of the modelfile.
 type Modelfile interface {
@@ -81,6 +88,22 @@ type Modelfile interface {
 	// GetQuantization returns the value of the quantization command in the modelfile.
 	GetQuantization() string
 
+	// GetFrom returns the value of the FROM directive of the Ollama Modelfile,
+	// which is only recognized in the interop mode.
+	GetFrom() string
+
+	// GetParameters returns the values of the PARAMETER directives of the Ollama
+	// Modelfile by the names, which are only recognized in the interop mode.
+	GetParameters() map[string][]string
+
+	// GetTemplate returns the value of the TEMPLATE directive of the Ollama Modelfile,
+	// which is only recognized in the interop mode.
+	GetTemplate() string
+
+	// GetSystem returns the value of the SYSTEM directive of the Ollama Modelfile,
+	// which is only recognized in the interop mode.
+	GetSystem() string
+
 	// Content returns the content of the modelfile.
 	Content() []byte
 }
@@ -100,20 +123,26 @@ type modelfile struct {
 	paramsize    string
 	precision    string
 	quantization string
+	from         string
+	parameters   map[string][]string
+	template     string
+	system       string
 }
 
 // NewModelfile creates a new modelfile by the path of the modelfile.
-// It parses the modelfile and returns the modelfile interface.
-func NewModelfile(path string) (Modelfile, error) {
+// It parses the modelfile and returns the modelfile interface, the
+// options are passed to the parser, such as the interop with Ollama.
+func NewModelfile(path string, opts ...parser.Option) (Modelfile, error) {
 	mf := &modelfile{
-		config:  hashset.New(),
-		model:   hashset.New(),
-		code:    hashset.New(),
-		dataset: hashset.New(),
-		doc:     hashset.New(),
+		config:     hashset.New(),
+		model:      hashset.New(),
+		code:       hashset.New(),
+		dataset:    hashset.New(),
+		doc:        hashset.New(),
+		parameters: map[string][]string{},
 	}
 
-	if err := mf.parseFile(path); err != nil {
+	if err := mf.parseFile(path, opts...); err != nil {
 		return nil, err
 	}
 
@@ -121,14 +150,14 @@ func NewModelfile(path string) (Modelfile, error) {
 }
 
 // parseFile parses the modelfile by the path, and validates the args of the commands.
-func (mf *modelfile) parseFile(path string) error {
+func (mf *modelfile) parseFile(path string, opts ...parser.Option) error {
 	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	ast, err := parser.Parse(f)
+	ast, err := parser.Parse(f, opts...)
 	if err != nil {
 		return err
 	}
@@ -180,20 +209,63 @@ func (mf *modelfile) parseFile(path string) error {
 				return fmt.Errorf("duplicate quantization command on line %d", child.GetStartLine())
 			}
 			mf.quantization = child.GetNext().GetValue()
+		case modefilecommand.FROM:
+			if mf.from != "" {
+				return fmt.Errorf("duplicate from command on line %d", child.GetStartLine())
+			}
+			mf.from = child.GetNext().GetValue()
+		case modefilecommand.PARAMETER:
+			name := child.GetNext().GetValue()
+			mf.parameters[name] = append(mf.parameters[name], child.GetNext().GetNext().GetValue())
+		case modefilecommand.TEMPLATE:
+			if mf.template != "" {
+				return fmt.Errorf("duplicate template command on line %d", child.GetStartLine())
+			}
+			mf.template = child.GetNext().GetValue()
+		case modefilecommand.SYSTEM:
+			if mf.system != "" {
+				return fmt.Errorf("duplicate system command on line %d", child.GetStartLine())
+			}
+			mf.system = child.GetNext().GetValue()
 		default:
 			return fmt.Errorf("unknown command %s on line %d", child.GetValue(), child.GetStartLine())
 		}
 	}
 
+	// The model file referenced by FROM is built as the model only if there is no MODEL
+	// command, as the native commands are authoritative.
+	if model := fromModelPath(mf.from); model != "" && mf.model.Empty() {
+		mf.model.Add(model)
+	}
+
 	return nil
 }
 
+// fromModelPath returns the path of the model file referenced by the FROM directive of the
+// Ollama Modelfile, such as ./model.gguf, or empty if it references the model by the name or
+// the path outside of the work directory.
+func fromModelPath(from string) string {
+	modelPath := path.Clean(filepath.ToSlash(from))
+	if modelPath == "." || !filepath.IsLocal(modelPath) {
+		return ""
+	}
+
+	if !strings.HasPrefix(from, "./") && !IsFileType(modelPath, ModelFilePatterns) {
+		return ""
+	}
+
+	return modelPath
+}
+
 // NewModelfileByWorkspace creates a new modelfile by the workspace.
 //
 // It generates the modelfile by the following steps:
 //  1. It walks the workspace and gets the files, and generates the modelfile by the files.
-//  2. It generates the modelfile by the model config, such as config.json and generation_config.json.
-//  3. It generates the modelfile by the generate config, such as name, arch, family, format,
+//  2. It generates the modelfile by the model config, such as config.json and generation_config.json,
+//     or the model_index.json of the diffusers pipeline.
+//  3. It generates the precision by the dtype of the safetensors files if it is not found
+//     in the model config.
+//  4. It generates the modelfile by the generate config, such as name, arch, family, format,
 //     paramsize, precision, and quantization.
 func NewModelfileByWorkspace(workspace string, config *configmodelfile.GenerateConfig) (Modelfile, error) {
 	mf := &modelfile{
@@ -217,6 +289,7 @@ func NewModelfileByWorkspace(workspace string, config *configmodelfile.GenerateC
 		return nil, err
 	}
 
+	mf.generateBySafetensors()
 	mf.generateByConfig(config)
 	return mf, nil
 }
@@ -263,6 +336,17 @@ func (mf *modelfile) generateByWorkspace(config *configmodelfile.GenerateConfig)
 		return err
 	}
 
+	// Load the ignore patterns of the .modctlignore at the workspace root.
+	ignorePatterns, err := loadIgnorePatterns(mf.workspace)
+	if err != nil {
+		return err
+	}
+
+	ignore, err := newIgnoreMatcher(ignorePatterns)
+	if err != nil {
+		return err
+	}
+
 	// Walk the path and get the files.
 	if err := filepath.Walk(mf.workspace, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -282,6 +366,14 @@ func (mf *modelfile) generateByWorkspace(config *configmodelfile.GenerateConfig)
 			return filepath.SkipDir
 		}
 
+		// Paths ignored by the .modctlignore are skipped regardless of --include.
+		if relPath != "." && ignore.Match(relPath, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Check skipPatterns — include can rescue skippable entries.
 		if isSkippable(filename) {
 			if info.IsDir() {
@@ -330,7 +422,7 @@ func (mf *modelfile) generateByWorkspace(config *configmodelfile.GenerateConfig)
 			return fmt.Errorf("workspace exceeds maximum total size limit of %d bytes (%s)", MaxTotalWorkspaceSize, formatBytes(MaxTotalWorkspaceSize))
 		}
 
-		switch InferFileType(filename, info.Size()) {
+		switch InferFileType(relPath, info.Size()) {
 		case FileTypeConfig:
 			mf.config.Add(relPath)
 		case FileTypeModel:
@@ -339,6 +431,8 @@ func (mf *modelfile) generateByWorkspace(config *configmodelfile.GenerateConfig)
 			mf.code.Add(relPath)
 		case FileTypeDoc:
 			mf.doc.Add(relPath)
+		case FileTypeDataset:
+			mf.dataset.Add(relPath)
 		}
 
 		return nil
@@ -353,28 +447,21 @@ func (mf *modelfile) generateByWorkspace(config *configmodelfile.GenerateConfig)
 	return nil
 }
 
-// generateByModelConfig generates the modelfile by the model config, such as config.json and generation_config.json.
+// generateByModelConfig generates the modelfile by the model config, such as config.json and generation_config.json,
+// and the model_index.json of the diffusers pipeline.
 func (mf *modelfile) generateByModelConfig() error {
 	// Get config map from json files. Collect all the keys and values from the config files
 	// and store them in the modelConfig map.
 	configFiles := []string{"config.json", "generation_config.json"}
 	modelConfig := make(map[string]interface{})
 	for _, filename := range configFiles {
-		path := filepath.Join(mf.workspace, filename)
-		data, err := os.ReadFile(path)
+		config, err := readJSONConfig(filepath.Join(mf.workspace, filename))
 		if err != nil {
-			if os.IsNotExist(err) {
-				continue
-			} else {
-				return err
-			}
+			return err
 		}
 
-		var config map[string]interface{}
-		if err := json.Unmarshal(data, &config); err == nil {
-			for k, v := range config {
-				modelConfig[k] = v
-			}
+		for k, v := range config {
+			modelConfig[k] = v
 		}
 	}
 
@@ -390,9 +477,130 @@ func (mf *modelfile) generateByModelConfig() error {
 		mf.arch = "transformer"
 	}
 
+	return mf.generateByDiffusersIndex()
+}
+
+// generateByDiffusersIndex generates the modelfile by the model_index.json of the diffusers
+// pipeline, which lists the components in the subfolders instead of a single config.json.
+// The family is derived from the pipeline class name, such as stable-diffusion-xl for
+// StableDiffusionXLPipeline, and the precision is taken from the component configs if
+// it is not found in the model config.
+func (mf *modelfile) generateByDiffusersIndex() error {
+	index, err := readJSONConfig(filepath.Join(mf.workspace, "model_index.json"))
+	if err != nil || index == nil {
+		return err
+	}
+
+	className, ok := index["_class_name"].(string)
+	if !ok {
+		return nil
+	}
+
+	mf.arch = "diffusion"
+	if family := diffusersFamily(className); family != "" {
+		mf.family = family
+	}
+
+	if mf.precision != "" {
+		return nil
+	}
+
+	// The components are the entries of [library, class] pairs, such as
+	// "unet": ["diffusers", "UNet2DConditionModel"]. Sort them for the stable result.
+	components := make([]string, 0, len(index))
+	for name, value := range index {
+		if pair, ok := value.([]interface{}); ok && len(pair) == 2 {
+			components = append(components, name)
+		}
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		config, err := readJSONConfig(filepath.Join(mf.workspace, component, "config.json"))
+		if err != nil {
+			return err
+		}
+
+		if torchDtype, ok := config["torch_dtype"].(string); ok {
+			mf.precision = torchDtype
+			break
+		}
+	}
+
 	return nil
 }
 
+// readJSONConfig reads the json config file, it returns nil if the file does not exist
+// or is not a valid json object.
+func readJSONConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil
+	}
+
+	return config, nil
+}
+
+// diffusersFamily derives the family from the class name of the diffusers pipeline,
+// the Pipeline suffix is trimmed and the camel case is converted to the kebab case,
+// such as StableDiffusionXLPipeline to stable-diffusion-xl.
+func diffusersFamily(className string) string {
+	name := []rune(strings.TrimSuffix(className, "Pipeline"))
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := name[i-1]
+			nextLower := i+1 < len(name) && unicode.IsLower(name[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('-')
+			}
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// generateBySafetensors generates the precision by the dtype of the tensors in the
+// safetensors headers, it only takes effect when the precision is not found in the
+// model config. For mixed precision models, the most common dtype is used.
+func (mf *modelfile) generateBySafetensors() {
+	if mf.precision != "" || mf.model == nil {
+		return
+	}
+
+	dtypes := make(map[string]int)
+	for _, rawModel := range mf.model.Values() {
+		model, ok := rawModel.(string)
+		if !ok || !strings.EqualFold(filepath.Ext(model), ".safetensors") {
+			continue
+		}
+
+		counts, err := readSafetensorsDtypes(filepath.Join(mf.workspace, model))
+		if err != nil {
+			// Skip the unreadable safetensors file, the precision can be
+			// specified by the generate config.
+			continue
+		}
+
+		for dtype, count := range counts {
+			dtypes[dtype] += count
+		}
+	}
+
+	mf.precision = dominantPrecision(dtypes)
+}
+
 // generateByConfig generates the modelfile by the generate config, such as name, arch, family, format,
 // paramsize, precision, and quantization.
 func (mf *modelfile) generateByConfig(config *configmodelfile.GenerateConfig) {
@@ -416,6 +624,8 @@ func (mf *modelfile) generateByConfig(config *configmodelfile.GenerateConfig) {
 
 	if config.ParamSize != "" {
 		mf.paramsize = config.ParamSize
+	} else if mf.paramsize == "" {
+		mf.paramsize = inferParamsizeFromName(mf.name)
 	}
 
 	if config.Precision != "" {
@@ -427,6 +637,20 @@ func (mf *modelfile) generateByConfig(config *configmodelfile.GenerateConfig) {
 	}
 }
 
+// inferParamsizeFromName infers the paramsize from the model name as a fallback heuristic,
+// such as 7B from Llama-2-7b-chat or 0.5B from Qwen2.5-0.5B-Instruct. The size token must be
+// delimited by the separators to avoid false positives, returns empty if none found.
+func inferParamsizeFromName(name string) string {
+	matches := paramsizePattern.FindStringSubmatch(name)
+	if len(matches) < 2 {
+		return ""
+	}
+
+	// Normalize the unit to uppercase, such as 8x7b to 8x7B.
+	token := matches[1]
+	return strings.ToLower(token[:len(token)-1]) + strings.ToUpper(token[len(token)-1:])
+}
+
 // GetConfigs returns the args of the config command in the modelfile,
 // and deduplicates the args. The order of the args is the same as the
 // order in the modelfile.
@@ -547,6 +771,26 @@ func (mf *modelfile) GetQuantization() string {
 	return mf.quantization
 }
 
+// GetFrom returns the value of the FROM directive of the Ollama Modelfile.
+func (mf *modelfile) GetFrom() string {
+	return mf.from
+}
+
+// GetParameters returns the values of the PARAMETER directives of the Ollama Modelfile by the names.
+func (mf *modelfile) GetParameters() map[string][]string {
+	return mf.parameters
+}
+
+// GetTemplate returns the value of the TEMPLATE directive of the Ollama Modelfile.
+func (mf *modelfile) GetTemplate() string {
+	return mf.template
+}
+
+// GetSystem returns the value of the SYSTEM directive of the Ollama Modelfile.
+func (mf *modelfile) GetSystem() string {
+	return mf.system
+}
+
 // Content returns the content of the modelfile.
 func (mf *modelfile) Content() []byte {
 	content := ""
@@ -558,7 +802,7 @@ func (mf *modelfile) Content() []byte {
 	content += mf.writeField("Model family (Generated from model_type in config.json)", modefilecommand.FAMILY, mf.family)
 	content += mf.writeField("Model format", modefilecommand.FORMAT, mf.format)
 	content += mf.writeField("Model paramsize", modefilecommand.PARAMSIZE, mf.paramsize)
-	content += mf.writeField("Model precision (Generated from torch_dtype in config.json)", modefilecommand.PRECISION, mf.precision)
+	content += mf.writeField("Model precision (Generated from torch_dtype in config.json or dtype in safetensors)", modefilecommand.PRECISION, mf.precision)
 	content += mf.writeField("Model quantization", modefilecommand.QUANTIZATION, mf.quantization)
 
 	// Add multi-value commands.
@@ -566,6 +810,7 @@ func (mf *modelfile) Content() []byte {
 	content += mf.writeMultiField("Code files (Generated from the files in the workspace directory)", modefilecommand.CODE, mf.GetCodes(), CodeFilePatterns)
 	content += mf.writeMultiField("Model files (Generated from the files in the workspace directory)", modefilecommand.MODEL, mf.GetModels(), ModelFilePatterns)
 	content += mf.writeMultiField("Documentation files (Generated from the files in the workspace directory)", modefilecommand.DOC, mf.GetDocs(), DocFilePatterns)
+	content += mf.writeMultiField("Dataset files (Generated from the files in the workspace directory)", modefilecommand.DATASET, mf.GetDatasets(), DatasetFilePatterns)
 	return []byte(content)
 }
 
@@ -31,6 +31,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+	"github.com/modelpack/modctl/pkg/modelfile/parser"
 )
 
 func TestNewModelfile(t *testing.T) {
@@ -271,6 +272,86 @@ NAME bar
 	}
 }
 
+func TestNewModelfileOllama(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		expectErr  bool
+		from       string
+		models     []string
+		parameters map[string][]string
+		template   string
+		system     string
+	}{
+		{
+			name: "model file referenced by from",
+			input: `FROM ./llama3.2.gguf
+PARAMETER temperature 0.7
+PARAMETER stop "<|eot_id|>"
+PARAMETER stop "<|end_of_text|>"
+TEMPLATE """{{ .System }}
+{{ .Prompt }}"""
+SYSTEM You are a helpful assistant.
+`,
+			from:       "./llama3.2.gguf",
+			models:     []string{"llama3.2.gguf"},
+			parameters: map[string][]string{"temperature": {"0.7"}, "stop": {"<|eot_id|>", "<|end_of_text|>"}},
+			template:   "{{ .System }}\n{{ .Prompt }}",
+			system:     "You are a helpful assistant.",
+		},
+		{
+			name:       "native model is authoritative",
+			input:      "FROM ./llama3.2.gguf\nMODEL *.safetensors\n",
+			from:       "./llama3.2.gguf",
+			models:     []string{"*.safetensors"},
+			parameters: map[string][]string{},
+		},
+		{
+			name:       "base model by name",
+			input:      "FROM llama3.2:3b\nNAME llama3.2\n",
+			from:       "llama3.2:3b",
+			parameters: map[string][]string{},
+		},
+		{
+			name:       "model file outside of the work directory",
+			input:      "FROM ../llama3.2.gguf\n",
+			from:       "../llama3.2.gguf",
+			parameters: map[string][]string{},
+		},
+		{
+			name:      "duplicate from",
+			input:     "FROM llama3.2\nFROM llama3.1\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Modelfile")
+			require.NoError(t, os.WriteFile(path, []byte(tc.input), 0644))
+
+			mf, err := NewModelfile(path, parser.WithOllama())
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.from, mf.GetFrom())
+			assert.Equal(t, tc.models, mf.GetModels())
+			assert.Equal(t, tc.parameters, mf.GetParameters())
+			assert.Equal(t, tc.template, mf.GetTemplate())
+			assert.Equal(t, tc.system, mf.GetSystem())
+		})
+	}
+
+	// the directives of Ollama are rejected without the interop.
+	path := filepath.Join(t.TempDir(), "Modelfile")
+	require.NoError(t, os.WriteFile(path, []byte("FROM llama3.2\n"), 0644))
+	_, err := NewModelfile(path)
+	assert.Error(t, err)
+}
+
 func TestNewModelfileByWorkspace(t *testing.T) {
 	testcases := []struct {
 		name               string
@@ -284,6 +365,7 @@ func TestNewModelfileByWorkspace(t *testing.T) {
 		expectModels       []string
 		expectCodes        []string
 		expectDocs         []string
+		expectDatasets     []string
 		expectName         string
 		expectArch         string
 		expectFamily       string
@@ -312,6 +394,56 @@ func TestNewModelfileByWorkspace(t *testing.T) {
 			expectDocs:    []string{"README.md", "LICENSE"},
 			expectName:    "test-model",
 		},
+		{
+			name: "with dataset files",
+			setupFiles: map[string]string{
+				"config.json":               "",
+				"model.safetensors":         "",
+				"README.md":                 "",
+				"notes.csv":                 "",
+				"data/train.parquet":        "",
+				"data/test-00000.arrow":     "",
+				"data/train.jsonl":          "",
+				"data/validation.csv":       "",
+				"records/shard-0.tfrecord":  "",
+				"records/shard-1.tfrecords": "",
+			},
+			config: &configmodelfile.GenerateConfig{
+				Name: "dataset-model",
+			},
+			expectConfigs: []string{"config.json"},
+			expectModels:  []string{"model.safetensors"},
+			expectDocs:    []string{"README.md", "notes.csv"},
+			expectDatasets: []string{
+				"data/train.parquet",
+				"data/test-00000.arrow",
+				"data/train.jsonl",
+				"data/validation.csv",
+				"records/shard-0.tfrecord",
+				"records/shard-1.tfrecords",
+			},
+			expectName: "dataset-model",
+		},
+		{
+			name: "with modctlignore",
+			setupFiles: map[string]string{
+				".modctlignore":              "# scratch files\nscratch/\ncheckpoint-*/\n*.bin\n!model.bin\n",
+				"config.json":                "",
+				"model.bin":                  "",
+				"optimizer.bin":              "",
+				"README.md":                  "",
+				"scratch/notes.md":           "",
+				"checkpoint-100/model.bin":   "",
+				"checkpoint-100/config.json": "",
+			},
+			config: &configmodelfile.GenerateConfig{
+				Name: "ignore-model",
+			},
+			expectConfigs: []string{"config.json"},
+			expectModels:  []string{"model.bin"},
+			expectDocs:    []string{"README.md"},
+			expectName:    "ignore-model",
+		},
 		{
 			name:       "empty workspace",
 			setupFiles: map[string]string{},
@@ -883,6 +1015,7 @@ func TestNewModelfileByWorkspace(t *testing.T) {
 			assert.ElementsMatch(tc.expectModels, mf.GetModels())
 			assert.ElementsMatch(tc.expectCodes, mf.GetCodes())
 			assert.ElementsMatch(tc.expectDocs, mf.GetDocs())
+			assert.ElementsMatch(tc.expectDatasets, mf.GetDatasets())
 		})
 	}
 }
@@ -1055,6 +1188,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:        createHashSet([]string{"model.gguf"}),
 				code:         createHashSet([]string{}),
 				doc:          createHashSet([]string{}),
+				dataset:      createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1093,6 +1227,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:        createHashSet([]string{"shard-00001.bin", "shard-00002.bin"}),
 				code:         createHashSet([]string{}),
 				doc:          createHashSet([]string{}),
+				dataset:      createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1127,6 +1262,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:     createHashSet([]string{"models/weights/pytorch_model.bin"}),
 				code:      createHashSet([]string{"src/utils.py", "src/models/model.py"}),
 				doc:       createHashSet([]string{}),
+				dataset:   createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1156,6 +1292,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:     createHashSet([]string{"model.bin"}),
 				code:      createHashSet([]string{}),
 				doc:       createHashSet([]string{}),
+				dataset:   createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1186,6 +1323,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:        createHashSet([]string{}),
 				code:         createHashSet([]string{}),
 				doc:          createHashSet([]string{}),
+				dataset:      createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1208,6 +1346,25 @@ func TestModelfile_Content(t *testing.T) {
 				"CODE", "CONFIG", "DOC",
 			},
 		},
+		{
+			name: "dataset files",
+			modelfile: &modelfile{
+				name:    "dataset-model",
+				config:  createHashSet([]string{"config.json"}),
+				model:   createHashSet([]string{"model.safetensors"}),
+				code:    createHashSet([]string{}),
+				doc:     createHashSet([]string{}),
+				dataset: createHashSet([]string{"data/train.parquet", "data/test.jsonl"}),
+			},
+			expectedParts: []string{
+				"# Dataset files",
+				"DATASET data/test.jsonl",
+				"DATASET data/train.parquet",
+			},
+			notExpectParts: []string{
+				"CODE", "DOC",
+			},
+		},
 		{
 			name: "files only no metadata",
 			modelfile: &modelfile{
@@ -1244,6 +1401,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:     createHashSet([]string{"model1.bin", "model2.bin", "model3.bin", "model4.bin"}),
 				code:      createHashSet([]string{"script1.py", "script2.py"}),
 				doc:       createHashSet([]string{"README1.md", "README2.md"}),
+				dataset:   createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1280,6 +1438,7 @@ func TestModelfile_Content(t *testing.T) {
 				model:     createHashSet([]string{"model-v1.0_beta.bin"}),
 				code:      createHashSet([]string{"spaces/script.py"}),
 				doc:       createHashSet([]string{"weird-name!.md"}),
+				dataset:   createHashSet([]string{}),
 			},
 			expectedParts: []string{
 				"# Generated at",
@@ -1573,6 +1732,56 @@ func TestGenerateByModelConfig(t *testing.T) {
 			expectedArch: "transformer",
 			expectError:  false,
 		},
+		{
+			name: "diffusers model_index.json",
+			configFiles: map[string]map[string]interface{}{
+				"model_index.json": {
+					"_class_name":        "StableDiffusionXLPipeline",
+					"_diffusers_version": "0.25.0",
+					"scheduler":          []interface{}{"diffusers", "EulerDiscreteScheduler"},
+					"text_encoder":       []interface{}{"transformers", "CLIPTextModel"},
+					"unet":               []interface{}{"diffusers", "UNet2DConditionModel"},
+					"vae":                []interface{}{"diffusers", "AutoencoderKL"},
+				},
+				"text_encoder/config.json": {
+					"model_type":           "clip_text_model",
+					"torch_dtype":          "float16",
+					"transformers_version": "4.36.0",
+				},
+				"unet/config.json": {
+					"_class_name":        "UNet2DConditionModel",
+					"_diffusers_version": "0.25.0",
+				},
+			},
+			expectedArch:      "diffusion",
+			expectedFamily:    "stable-diffusion-xl",
+			expectedPrecision: "float16",
+			expectError:       false,
+		},
+		{
+			name: "diffusers model_index.json without component precision",
+			configFiles: map[string]map[string]interface{}{
+				"model_index.json": {
+					"_class_name": "FluxPipeline",
+					"transformer": []interface{}{"diffusers", "FluxTransformer2DModel"},
+				},
+				"transformer/config.json": {
+					"_class_name": "FluxTransformer2DModel",
+				},
+			},
+			expectedArch:   "diffusion",
+			expectedFamily: "flux",
+			expectError:    false,
+		},
+		{
+			name: "model_index.json without class name",
+			configFiles: map[string]map[string]interface{}{
+				"model_index.json": {
+					"unet": []interface{}{"diffusers", "UNet2DConditionModel"},
+				},
+			},
+			expectError: false,
+		},
 	}
 
 	assert := assert.New(t)
@@ -1584,6 +1793,7 @@ func TestGenerateByModelConfig(t *testing.T) {
 
 			// Create config files
 			for filename, content := range tc.configFiles {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tempDir, filename)), 0755))
 				if content == nil {
 					// Create invalid JSON
 					err = os.WriteFile(filepath.Join(tempDir, filename), []byte("invalid json"), 0644)
@@ -1610,6 +1820,22 @@ func TestGenerateByModelConfig(t *testing.T) {
 	}
 }
 
+// TestDiffusersFamily tests the diffusersFamily function
+func TestDiffusersFamily(t *testing.T) {
+	testcases := map[string]string{
+		"StableDiffusionPipeline":   "stable-diffusion",
+		"StableDiffusionXLPipeline": "stable-diffusion-xl",
+		"StableDiffusion3Pipeline":  "stable-diffusion3",
+		"FluxPipeline":              "flux",
+		"PixArtAlphaPipeline":       "pix-art-alpha",
+		"SDXLTurbo":                 "sdxl-turbo",
+	}
+
+	for className, expected := range testcases {
+		assert.Equal(t, expected, diffusersFamily(className), className)
+	}
+}
+
 // TestGenerateByConfig tests the generateByConfig method
 func TestGenerateByConfig(t *testing.T) {
 	testcases := []struct {
@@ -1664,6 +1890,31 @@ func TestGenerateByConfig(t *testing.T) {
 			config:       &configmodelfile.GenerateConfig{},
 			expectedName: "test-workspace",
 		},
+		{
+			name:              "paramsize inferred from workspace name",
+			workspace:         "/models/Llama-2-7b-chat-hf",
+			config:            &configmodelfile.GenerateConfig{},
+			expectedName:      "Llama-2-7b-chat-hf",
+			expectedParamsize: "7B",
+		},
+		{
+			name:      "paramsize inferred from custom name",
+			workspace: "/path/to/workspace",
+			config: &configmodelfile.GenerateConfig{
+				Name: "Qwen2.5-0.5B-Instruct",
+			},
+			expectedName:      "Qwen2.5-0.5B-Instruct",
+			expectedParamsize: "0.5B",
+		},
+		{
+			name:      "explicit paramsize overrides name",
+			workspace: "/models/Qwen-72B",
+			config: &configmodelfile.GenerateConfig{
+				ParamSize: "70B",
+			},
+			expectedName:      "Qwen-72B",
+			expectedParamsize: "70B",
+		},
 	}
 
 	assert := assert.New(t)
@@ -1683,6 +1934,39 @@ func TestGenerateByConfig(t *testing.T) {
 	}
 }
 
+func TestInferParamsizeFromName(t *testing.T) {
+	testcases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Llama-2-7b", expected: "7B"},
+		{name: "Llama-2-13b-chat-hf", expected: "13B"},
+		{name: "Qwen-72B", expected: "72B"},
+		{name: "Qwen2.5-0.5B-Instruct", expected: "0.5B"},
+		{name: "Qwen1.5-1.8B", expected: "1.8B"},
+		{name: "Meta-Llama-3.1-8B-Instruct", expected: "8B"},
+		{name: "Mixtral-8x7B-v0.1", expected: "8x7B"},
+		{name: "gpt2_350m", expected: "350M"},
+		{name: "switch-c-1.6t", expected: "1.6T"},
+		{name: "7b", expected: "7B"},
+		{name: "gpt2", expected: ""},
+		{name: "resnet50", expected: ""},
+		{name: "bert-base-uncased", expected: ""},
+		{name: "model-v2", expected: ""},
+		{name: "checkpoint-12000", expected: ""},
+		{name: "model-20240101", expected: ""},
+		{name: "e5b4c3-model", expected: ""},
+		{name: "qwen2b", expected: ""},
+		{name: "", expected: ""},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, inferParamsizeFromName(tc.name))
+		})
+	}
+}
+
 // TestValidateWorkspace tests the validateWorkspace method specifically
 func TestValidateWorkspace(t *testing.T) {
 	testcases := []struct {
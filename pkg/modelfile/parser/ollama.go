@@ -0,0 +1,139 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelpack/modctl/pkg/modelfile/command"
+)
+
+// tripleQuotes quotes the value of the directive spanning multiple lines.
+const tripleQuotes = `"""`
+
+// Option is the option of the parser.
+type Option func(*options)
+
+// options is the options of the parser.
+type options struct {
+	// ollama indicates whether to recognize the directives of the Ollama Modelfile.
+	ollama bool
+}
+
+// WithOllama recognizes the subset of the directives of the Ollama Modelfile, FROM, PARAMETER,
+// TEMPLATE and SYSTEM, in addition to the native commands for the interop.
+func WithOllama() Option {
+	return func(o *options) {
+		o.ollama = true
+	}
+}
+
+// isOllamaCommand checks if the line is a directive of the Ollama Modelfile.
+func isOllamaCommand(line string) bool {
+	cmd, _, _ := strings.Cut(line, " ")
+	cmd, _, _ = strings.Cut(cmd, "\t")
+	return slices.Contains(command.OllamaCommands, strings.ToUpper(cmd))
+}
+
+// isMultilineOpen checks if the directive line opens the triple-quoted value without closing it.
+func isMultilineOpen(line string) bool {
+	_, args := splitDirective(line)
+	return strings.HasPrefix(args, tripleQuotes) && !strings.Contains(args[len(tripleQuotes):], tripleQuotes)
+}
+
+// splitDirective splits the directive line into the upper-cased directive and the trimmed args.
+func splitDirective(line string) (string, string) {
+	index := strings.IndexAny(line, " \t")
+	if index == -1 {
+		return strings.ToUpper(line), ""
+	}
+
+	return strings.ToUpper(line[:index]), strings.TrimSpace(line[index+1:])
+}
+
+// parseOllamaCommandLine parses the directive line of the Ollama Modelfile and returns the
+// command node with the args node, the args node of the PARAMETER directive is the name of
+// the parameter followed by the value node.
+func parseOllamaCommandLine(line string, start, end int) (Node, error) {
+	cmd, args := splitDirective(line)
+	if args == "" {
+		return nil, fmt.Errorf("invalid command line: %s", line)
+	}
+
+	cmdNode := NewNode(cmd, start, end)
+	switch cmd {
+	case command.FROM, command.TEMPLATE, command.SYSTEM:
+		value, err := unquote(args)
+		if err != nil {
+			return nil, err
+		}
+
+		argsNode, err := parseStringArgs([]string{value}, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		cmdNode.AddNext(argsNode)
+	case command.PARAMETER:
+		name, value, _ := strings.Cut(strings.Replace(args, "\t", " ", 1), " ")
+		value, err := unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("missing value of parameter %s", name)
+		}
+
+		nameNode := NewNode(name, start, end)
+		nameNode.AddNext(NewNode(value, start, end))
+		cmdNode.AddNext(nameNode)
+	default:
+		return nil, fmt.Errorf("invalid command: %s", cmd)
+	}
+
+	return cmdNode, nil
+}
+
+// unquote removes the triple quotes or the double quotes around the value, the value without
+// the quotes is returned as is.
+func unquote(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, tripleQuotes):
+		if len(value) < 2*len(tripleQuotes) || !strings.HasSuffix(value, tripleQuotes) {
+			return "", errors.New("unclosed triple quotes in arguments")
+		}
+
+		return value[len(tripleQuotes) : len(value)-len(tripleQuotes)], nil
+	case strings.HasPrefix(value, `"`):
+		args, err := parseArgs(value)
+		if err != nil {
+			return "", err
+		}
+
+		if len(args) != 1 {
+			return "", errors.New("invalid args")
+		}
+
+		return args[0], nil
+	default:
+		return value, nil
+	}
+}
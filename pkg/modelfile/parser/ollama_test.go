@@ -0,0 +1,95 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOllama(t *testing.T) {
+	input := `# Migrated from Ollama
+FROM ./llama3.2.gguf
+NAME llama3.2
+PARAMETER temperature 0.7
+PARAMETER stop "<|eot_id|>"
+PARAMETER	stop "<|end of text|>"
+TEMPLATE """{{ if .System }}<|start_header_id|>system<|end_header_id|>
+
+{{ .System }}<|eot_id|>{{ end }}
+MODEL inside the template"""
+SYSTEM "You are a helpful assistant."
+`
+
+	root, err := Parse(strings.NewReader(input), WithOllama())
+	require.NoError(t, err)
+
+	type directive struct {
+		cmd    string
+		values []string
+		start  int
+		end    int
+	}
+
+	var directives []directive
+	for _, child := range root.GetChildren() {
+		d := directive{cmd: child.GetValue(), start: child.GetStartLine(), end: child.GetEndLine()}
+		for next := child.GetNext(); next != nil; next = next.GetNext() {
+			d.values = append(d.values, next.GetValue())
+		}
+		directives = append(directives, d)
+	}
+
+	assert.Equal(t, []directive{
+		{cmd: "FROM", values: []string{"./llama3.2.gguf"}, start: 1, end: 1},
+		{cmd: "NAME", values: []string{"llama3.2"}, start: 2, end: 2},
+		{cmd: "PARAMETER", values: []string{"temperature", "0.7"}, start: 3, end: 3},
+		{cmd: "PARAMETER", values: []string{"stop", "<|eot_id|>"}, start: 4, end: 4},
+		{cmd: "PARAMETER", values: []string{"stop", "<|end of text|>"}, start: 5, end: 5},
+		{cmd: "TEMPLATE", values: []string{"{{ if .System }}<|start_header_id|>system<|end_header_id|>\n\n{{ .System }}<|eot_id|>{{ end }}\nMODEL inside the template"}, start: 6, end: 9},
+		{cmd: "SYSTEM", values: []string{"You are a helpful assistant."}, start: 10, end: 10},
+	}, directives)
+}
+
+func TestParseOllamaErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "without interop", input: "FROM llama3.2\n"},
+		{name: "missing value", input: "FROM\n"},
+		{name: "missing parameter value", input: "PARAMETER temperature\n"},
+		{name: "unclosed triple quotes", input: "TEMPLATE \"\"\"{{ .Prompt }}\nMODEL foo\n"},
+		{name: "unclosed quotes", input: "SYSTEM \"You are a helpful assistant.\n"},
+		{name: "unsupported directive", input: "ADAPTER ./adapter.gguf\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []Option
+			if tc.name != "without interop" {
+				opts = append(opts, WithOllama())
+			}
+
+			_, err := Parse(strings.NewReader(tc.input), opts...)
+			assert.Error(t, err)
+		})
+	}
+}
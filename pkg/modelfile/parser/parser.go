@@ -28,7 +28,12 @@ import (
 // Parse parses the modelfile and returns the root node of the AST,
 // and the root node is the entry point of the AST. Walk the AST to
 // get the information of the modelfile.
-func Parse(reader io.Reader) (Node, error) {
+func Parse(reader io.Reader, opts ...Option) (Node, error) {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	root := NewRootNode()
 	currentLine := 0
 
@@ -64,6 +69,35 @@ func Parse(reader io.Reader) (Node, error) {
 			continue
 		}
 
+		// If the line is a directive of the Ollama Modelfile in the interop mode, parse the
+		// directive line, which may span multiple lines by the triple quotes.
+		if options.ollama && isOllamaCommand(trimmedLine) {
+			start := currentLine
+			line := trimmedLine
+			if isMultilineOpen(line) {
+				closed := false
+				for !closed && scanner.Scan() {
+					currentLine++
+					line += "\n" + scanner.Text()
+					closed = strings.Contains(scanner.Text(), tripleQuotes)
+				}
+
+				if !closed {
+					return nil, fmt.Errorf("parse error on line %d: unclosed triple quotes", start)
+				}
+				line = strings.TrimSpace(line)
+			}
+
+			node, err := parseOllamaCommandLine(line, start, currentLine)
+			if err != nil {
+				return nil, fmt.Errorf("parse command line error on line %d: %w", start, err)
+			}
+
+			root.AddChild(node)
+			currentLine++
+			continue
+		}
+
 		// If the line is not a comment, empty continuation, or a command, return an error.
 		return nil, fmt.Errorf("parse error on line %d: %s", currentLine, string(bytes))
 	}
@@ -0,0 +1,205 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// ClassificationRules is the user rules to classify the files of the workspace, which extends
+// or overrides the built-in patterns of each file type and the skip patterns, and classifies
+// the .txt files by the path. The extended patterns take precedence over the text rules, which
+// take precedence over the overridden patterns, so the prompts are configs and the other .txt
+// files are docs in the example:
+//
+//	model:
+//	  extend: ["*.weights"]
+//	doc:
+//	  override: ["*.md", "*.txt"]
+//	skip:
+//	  extend: ["*.tmp"]
+//	text:
+//	  - pattern: "**/prompts/*.txt"
+//	    type: config
+type ClassificationRules struct {
+	Config  PatternRule `yaml:"config"`
+	Model   PatternRule `yaml:"model"`
+	Code    PatternRule `yaml:"code"`
+	Doc     PatternRule `yaml:"doc"`
+	Dataset PatternRule `yaml:"dataset"`
+	Skip    PatternRule `yaml:"skip"`
+
+	// Text is the rules of the .txt files by the path, which take precedence over the overridden
+	// patterns and the built-in TextFileRules in order.
+	Text []TextFileRule `yaml:"text"`
+}
+
+// PatternRule is the rule of the patterns of a file type.
+type PatternRule struct {
+	// Override replaces the built-in patterns if it is not empty.
+	Override []string `yaml:"override"`
+	// Extend appends the patterns to the built-in or overridden patterns, the extended patterns
+	// take precedence over the patterns of the other file types to reclassify the files.
+	Extend []string `yaml:"extend"`
+}
+
+// extendedPatterns is the patterns extended by the classification rules, which are matched
+// before the built-in patterns.
+var extendedPatterns = map[FileType][]string{}
+
+// overriddenPatterns is the patterns overriding the built-in ones by the classification rules,
+// which are matched before the built-in text file rules.
+var overriddenPatterns = map[FileType][]string{}
+
+// userTextFileRules is the text file rules of the classification rules, which are matched before
+// the overridden patterns.
+var userTextFileRules []TextFileRule
+
+// LoadClassificationRules loads the classification rules from the YAML file.
+func LoadClassificationRules(path string) (*ClassificationRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classification rules: %w", err)
+	}
+
+	var rules ClassificationRules
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse classification rules %s: %w", path, err)
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid classification rules %s: %w", path, err)
+	}
+
+	return &rules, nil
+}
+
+// Validate validates the patterns of the rules.
+func (r *ClassificationRules) Validate() error {
+	for name, rule := range map[string]PatternRule{"config": r.Config, "model": r.Model, "code": r.Code, "doc": r.Doc, "dataset": r.Dataset, "skip": r.Skip} {
+		for _, pattern := range append(append([]string{}, rule.Override...), rule.Extend...) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", name, pattern, err)
+			}
+		}
+	}
+
+	for _, rule := range r.Text {
+		if !doublestar.ValidatePattern(rule.Pattern) {
+			return fmt.Errorf("invalid text pattern %q", rule.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// SetClassificationRules applies the classification rules to the patterns used by the
+// InferFileType and the workspace walk, it should be called once before generating the
+// modelfile as the patterns are not protected for the concurrent access.
+func SetClassificationRules(rules *ClassificationRules) {
+	ConfigFilePatterns = rules.Config.apply(ConfigFilePatterns)
+	ModelFilePatterns = rules.Model.apply(ModelFilePatterns)
+	CodeFilePatterns = rules.Code.apply(CodeFilePatterns)
+	DocFilePatterns = rules.Doc.apply(DocFilePatterns)
+	DatasetFilePatterns = rules.Dataset.apply(DatasetFilePatterns)
+	skipPatterns = rules.Skip.apply(skipPatterns)
+	userTextFileRules = rules.Text
+
+	extendedPatterns = map[FileType][]string{
+		FileTypeConfig:  rules.Config.Extend,
+		FileTypeModel:   rules.Model.Extend,
+		FileTypeCode:    rules.Code.Extend,
+		FileTypeDoc:     rules.Doc.Extend,
+		FileTypeDataset: rules.Dataset.Extend,
+	}
+
+	overriddenPatterns = map[FileType][]string{
+		FileTypeConfig:  rules.Config.Override,
+		FileTypeModel:   rules.Model.Override,
+		FileTypeCode:    rules.Code.Override,
+		FileTypeDoc:     rules.Doc.Override,
+		FileTypeDataset: rules.Dataset.Override,
+	}
+}
+
+// fileTypeNames is the names of the file types in the classification rules.
+var fileTypeNames = map[string]FileType{
+	"config":  FileTypeConfig,
+	"model":   FileTypeModel,
+	"code":    FileTypeCode,
+	"doc":     FileTypeDoc,
+	"dataset": FileTypeDataset,
+}
+
+// UnmarshalYAML unmarshals the file type by the name, such as code or doc.
+func (t *FileType) UnmarshalYAML(node *yaml.Node) error {
+	var name string
+	if err := node.Decode(&name); err != nil {
+		return err
+	}
+
+	fileType, ok := fileTypeNames[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown file type %q", name)
+	}
+
+	*t = fileType
+	return nil
+}
+
+// apply returns the patterns overridden and extended by the rule.
+func (r PatternRule) apply(patterns []string) []string {
+	if len(r.Override) > 0 {
+		patterns = r.Override
+	}
+
+	return append(append([]string{}, patterns...), r.Extend...)
+}
+
+// inferExtendedFileType returns the file type of the extended patterns matching the filename.
+func inferExtendedFileType(filename string) (FileType, bool) {
+	for _, fileType := range []FileType{FileTypeConfig, FileTypeModel, FileTypeCode, FileTypeDoc, FileTypeDataset} {
+		if IsFileType(filename, extendedPatterns[fileType]) {
+			return fileType, true
+		}
+	}
+
+	return 0, false
+}
+
+// inferOverriddenFileType returns the file type of the overridden patterns matching the filename,
+// which are matched in the same order as the built-in patterns.
+func inferOverriddenFileType(filename string) (FileType, bool) {
+	for _, fileType := range []FileType{FileTypeDataset, FileTypeConfig, FileTypeModel, FileTypeCode, FileTypeDoc} {
+		if IsFileType(filename, overriddenPatterns[fileType]) {
+			return fileType, true
+		}
+	}
+
+	return 0, false
+}
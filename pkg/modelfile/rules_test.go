@@ -0,0 +1,217 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+)
+
+// restoreClassificationRules restores the patterns modified by the classification rules after the test.
+func restoreClassificationRules(t *testing.T) {
+	config, model, code, doc, dataset, skip, text, extended := ConfigFilePatterns, ModelFilePatterns, CodeFilePatterns, DocFilePatterns, DatasetFilePatterns, skipPatterns, TextFileRules, extendedPatterns
+	overridden, userText := overriddenPatterns, userTextFileRules
+	t.Cleanup(func() {
+		ConfigFilePatterns, ModelFilePatterns, CodeFilePatterns, DocFilePatterns, DatasetFilePatterns, skipPatterns, TextFileRules, extendedPatterns = config, model, code, doc, dataset, skip, text, extended
+		overriddenPatterns, userTextFileRules = overridden, userText
+	})
+}
+
+func writeRules(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadClassificationRules(t *testing.T) {
+	rules, err := LoadClassificationRules(writeRules(t, `
+config:
+  extend: ["*.myconf"]
+doc:
+  override: ["*.md"]
+skip:
+  extend: ["*.tmp"]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*.myconf"}, rules.Config.Extend)
+	assert.Equal(t, []string{"*.md"}, rules.Doc.Override)
+	assert.Equal(t, []string{"*.tmp"}, rules.Skip.Extend)
+
+	rules, err = LoadClassificationRules(writeRules(t, ""))
+	require.NoError(t, err)
+	assert.Empty(t, rules.Model.Extend)
+
+	_, err = LoadClassificationRules(writeRules(t, "weights:\n  extend: [\"*.w\"]\n"))
+	assert.ErrorContains(t, err, "failed to parse classification rules")
+
+	_, err = LoadClassificationRules(writeRules(t, "model:\n  extend: [\"[\"]\n"))
+	assert.ErrorContains(t, err, "invalid model pattern")
+
+	_, err = LoadClassificationRules(writeRules(t, "text:\n  - pattern: \"**/*.txt\"\n    type: weights\n"))
+	assert.ErrorContains(t, err, `unknown file type "weights"`)
+
+	_, err = LoadClassificationRules(writeRules(t, "text:\n  - pattern: \"[\"\n    type: doc\n"))
+	assert.ErrorContains(t, err, "invalid text pattern")
+
+	_, err = LoadClassificationRules(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestTextFileRules(t *testing.T) {
+	restoreClassificationRules(t)
+
+	testcases := []struct {
+		path     string
+		expected FileType
+	}{
+		{"vocab.txt", FileTypeConfig},
+		{"tokenizer/merges.txt", FileTypeConfig},
+		{"requirements.txt", FileTypeCode},
+		{"requirements-dev.txt", FileTypeCode},
+		{"dev-requirements.txt", FileTypeCode},
+		{"test_requirements.txt", FileTypeCode},
+		{"requirements/base.txt", FileTypeCode},
+		{"constraints.txt", FileTypeCode},
+		{"CMakeLists.txt", FileTypeCode},
+		{"README.txt", FileTypeDoc},
+		{"docs/readme.TXT", FileTypeDoc},
+		{"LICENSE.txt", FileTypeDoc},
+		{"notes.txt", FileTypeDoc},
+		// the name without a separator is not a requirements file.
+		{"myrequirements.txt", FileTypeDoc},
+		{"data/train.txt", FileTypeDataset},
+		{"corpus/datasets/wiki/part-0.txt", FileTypeDataset},
+		// the named files take precedence over the directory contexts.
+		{"data/README.txt", FileTypeDoc},
+		{"data/requirements.txt", FileTypeCode},
+		{"/abs/path/requirements.txt", FileTypeCode},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, InferFileType(tc.path, 1024), tc.path)
+	}
+
+	// the large .txt file is still classified by the rules instead of the size.
+	assert.Equal(t, FileTypeDoc, InferFileType("notes.txt", 200*1024*1024))
+
+	// the user rules take precedence over the built-in ones.
+	rules, err := LoadClassificationRules(writeRules(t, `
+text:
+  - pattern: "**/prompts/*.txt"
+    type: config
+  - pattern: "data/labels.txt"
+    type: config
+`))
+	require.NoError(t, err)
+	SetClassificationRules(rules)
+
+	assert.Equal(t, FileTypeConfig, InferFileType("prompts/system.txt", 1024))
+	assert.Equal(t, FileTypeConfig, InferFileType("data/labels.txt", 1024))
+	assert.Equal(t, FileTypeDataset, InferFileType("data/train.txt", 1024))
+	assert.Equal(t, FileTypeCode, InferFileType("requirements.txt", 1024))
+
+	// the overridden patterns apply to the .txt files not matching the user text rules.
+	rules, err = LoadClassificationRules(writeRules(t, `
+doc:
+  override: ["*.md", "*.txt"]
+text:
+  - pattern: "**/prompts/*.txt"
+    type: config
+`))
+	require.NoError(t, err)
+	SetClassificationRules(rules)
+
+	assert.Equal(t, FileTypeConfig, InferFileType("prompts/system.txt", 1024))
+	assert.Equal(t, FileTypeDoc, InferFileType("vocab.txt", 1024))
+	assert.Equal(t, FileTypeDoc, InferFileType("requirements.txt", 1024))
+	assert.Equal(t, FileTypeDoc, InferFileType("data/train.txt", 1024))
+}
+
+func TestSetClassificationRules(t *testing.T) {
+	restoreClassificationRules(t)
+	SetClassificationRules(&ClassificationRules{
+		Config: PatternRule{Extend: []string{"*.myconf"}},
+		Model:  PatternRule{Extend: []string{"*.weights.json"}},
+		Doc:    PatternRule{Override: []string{"*.md"}},
+		Skip:   PatternRule{Extend: []string{"*.tmp"}},
+	})
+
+	testcases := []struct {
+		filename string
+		expected FileType
+	}{
+		{"settings.myconf", FileTypeConfig},
+		// the extended patterns take precedence over the built-in *.json of config.
+		{"model.weights.json", FileTypeModel},
+		{"config.json", FileTypeConfig},
+		{"README.md", FileTypeDoc},
+		// the built-in doc patterns are overridden, the small unknown file falls back to code.
+		{"paper.pdf", FileTypeCode},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, InferFileType(tc.filename, 1024), tc.filename)
+	}
+
+	assert.True(t, isSkippable("build.tmp"))
+	assert.True(t, isSkippable(".hidden"))
+	assert.True(t, IsFileType("settings.myconf", ConfigFilePatterns))
+	assert.Equal(t, []string{"*.md"}, DocFilePatterns)
+}
+
+func TestNewModelfileByWorkspaceWithClassificationRules(t *testing.T) {
+	restoreClassificationRules(t)
+	workspace := t.TempDir()
+	for _, name := range []string{"config.json", "model.safetensors", "model.ckpt.json", "notes.md", "cache.tmp"} {
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, name), []byte("{}"), 0644))
+	}
+
+	rules, err := LoadClassificationRules(writeRules(t, `
+model:
+  extend: ["*.ckpt.json"]
+skip:
+  extend: ["*.tmp"]
+`))
+	require.NoError(t, err)
+	SetClassificationRules(rules)
+
+	mf, err := NewModelfileByWorkspace(workspace, configmodelfile.NewGenerateConfig())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"config.json"}, mf.GetConfigs())
+	assert.ElementsMatch(t, []string{"model.safetensors", "model.ckpt.json"}, mf.GetModels())
+	assert.ElementsMatch(t, []string{"notes.md"}, mf.GetDocs())
+	assert.Empty(t, mf.GetCodes())
+}
+
+func TestNewModelfileByWorkspaceWithTextFiles(t *testing.T) {
+	workspace := t.TempDir()
+	for _, name := range []string{"model.safetensors", "vocab.txt", "requirements.txt", "requirements/dev.txt", "README.txt", "notes.txt", "data/train.txt"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(workspace, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, name), []byte("text"), 0644))
+	}
+
+	mf, err := NewModelfileByWorkspace(workspace, configmodelfile.NewGenerateConfig())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"vocab.txt"}, mf.GetConfigs())
+	assert.ElementsMatch(t, []string{"requirements.txt", "requirements/dev.txt"}, mf.GetCodes())
+	assert.ElementsMatch(t, []string{"README.txt", "notes.txt"}, mf.GetDocs())
+	assert.ElementsMatch(t, []string{"data/train.txt"}, mf.GetDatasets())
+}
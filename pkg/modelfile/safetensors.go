@@ -0,0 +1,202 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// safetensorsHeaderSizeLen is the length of the little-endian uint64
+	// prefix which holds the size of the JSON header.
+	safetensorsHeaderSizeLen = 8
+
+	// maxSafetensorsHeaderSize is the upper bound of the JSON header size,
+	// which is aligned with the limit of the safetensors reference implementation.
+	maxSafetensorsHeaderSize = 100 * 1024 * 1024
+
+	// safetensorsMetadataKey is the reserved key for the free-form metadata in the header.
+	safetensorsMetadataKey = "__metadata__"
+)
+
+// safetensorsDtypePrecisions maps the safetensors dtype to the precision,
+// the values are aligned with the torch_dtype in config.json.
+var safetensorsDtypePrecisions = map[string]string{
+	"F64":     "float64",
+	"F32":     "float32",
+	"F16":     "float16",
+	"BF16":    "bfloat16",
+	"F8_E4M3": "float8_e4m3fn",
+	"F8_E5M2": "float8_e5m2",
+	"I64":     "int64",
+	"I32":     "int32",
+	"I16":     "int16",
+	"I8":      "int8",
+	"U8":      "uint8",
+	"BOOL":    "bool",
+}
+
+// safetensorsTensor is the tensor entry in the safetensors header.
+type safetensorsTensor struct {
+	Dtype string `json:"dtype"`
+	// Shape is the dimensions of the tensor, the number of the elements is the product of them.
+	Shape []uint64 `json:"shape"`
+	// DataOffsets is the [begin, end) range of the tensor data, which is relative
+	// to the start of the byte buffer after the header.
+	DataOffsets []uint64 `json:"data_offsets"`
+}
+
+// readSafetensorsHeader reads the length-prefixed JSON header of the safetensors
+// file, and returns the raw entries and the size of the header.
+func readSafetensorsHeader(r io.Reader) (map[string]json.RawMessage, uint64, error) {
+	var sizeBuf [safetensorsHeaderSizeLen]byte
+	if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+		return nil, 0, fmt.Errorf("failed to read safetensors header size: %w", err)
+	}
+
+	size := binary.LittleEndian.Uint64(sizeBuf[:])
+	if size == 0 || size > maxSafetensorsHeaderSize {
+		return nil, 0, fmt.Errorf("invalid safetensors header size: %d", size)
+	}
+
+	header := make([]byte, size)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, fmt.Errorf("failed to read safetensors header: %w", err)
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(header, &entries); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode safetensors header: %w", err)
+	}
+
+	return entries, size, nil
+}
+
+// readSafetensorsDtypes reads the safetensors header of the file and returns
+// the number of tensors for each dtype.
+func readSafetensorsDtypes(path string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, _, err := readSafetensorsHeader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	dtypes := make(map[string]int)
+	for name, raw := range entries {
+		if name == safetensorsMetadataKey {
+			continue
+		}
+
+		var tensor safetensorsTensor
+		if err := json.Unmarshal(raw, &tensor); err != nil {
+			return nil, fmt.Errorf("failed to decode safetensors tensor %s: %w", name, err)
+		}
+
+		if tensor.Dtype != "" {
+			dtypes[strings.ToUpper(tensor.Dtype)]++
+		}
+	}
+
+	return dtypes, nil
+}
+
+// VerifySafetensors checks whether the safetensors file is loadable without reading
+// the tensor data, the header must be a valid length-prefixed JSON and the data
+// offsets of all the tensors must be within the bounds of the file. It is a
+// lightweight sanity check to detect the truncated or corrupted files.
+func VerifySafetensors(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	entries, headerSize, err := readSafetensorsHeader(f)
+	if err != nil {
+		return err
+	}
+
+	dataSize := uint64(info.Size()) - safetensorsHeaderSizeLen - headerSize
+	for name, raw := range entries {
+		if name == safetensorsMetadataKey {
+			continue
+		}
+
+		var tensor safetensorsTensor
+		if err := json.Unmarshal(raw, &tensor); err != nil {
+			return fmt.Errorf("failed to decode safetensors tensor %s: %w", name, err)
+		}
+
+		if len(tensor.DataOffsets) != 2 {
+			return fmt.Errorf("invalid data offsets of safetensors tensor %s: %v", name, tensor.DataOffsets)
+		}
+
+		begin, end := tensor.DataOffsets[0], tensor.DataOffsets[1]
+		if begin > end {
+			return fmt.Errorf("invalid data offsets of safetensors tensor %s: begin %d is greater than end %d", name, begin, end)
+		}
+
+		if end > dataSize {
+			return fmt.Errorf("safetensors tensor %s is out of bounds, data ends at %d but only %d bytes are available, the file may be truncated", name, end, dataSize)
+		}
+	}
+
+	return nil
+}
+
+// dominantPrecision returns the precision of the most common dtype. The ties are
+// broken by the dtype name to keep the result deterministic.
+func dominantPrecision(dtypes map[string]int) string {
+	if len(dtypes) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(dtypes))
+	for name := range dtypes {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if dtypes[names[i]] != dtypes[names[j]] {
+			return dtypes[names[i]] > dtypes[names[j]]
+		}
+
+		return names[i] < names[j]
+	})
+
+	if precision, ok := safetensorsDtypePrecisions[names[0]]; ok {
+		return precision
+	}
+
+	return strings.ToLower(names[0])
+}
@@ -0,0 +1,180 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package modelfile
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSafetensors writes a synthetic safetensors file which only contains the header.
+func writeSafetensors(t *testing.T, path string, dtypes ...string) {
+	header := map[string]interface{}{
+		"__metadata__": map[string]string{"format": "pt"},
+	}
+	for i, dtype := range dtypes {
+		header["tensor"+string(rune('a'+i))] = map[string]interface{}{
+			"dtype":        dtype,
+			"shape":        []int{1},
+			"data_offsets": []int{0, 0},
+		}
+	}
+
+	data, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	buf := make([]byte, 8, 8+len(data))
+	binary.LittleEndian.PutUint64(buf, uint64(len(data)))
+	buf = append(buf, data...)
+	require.NoError(t, os.WriteFile(path, buf, 0644))
+}
+
+func TestReadSafetensorsDtypes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	valid := filepath.Join(tempDir, "valid.safetensors")
+	writeSafetensors(t, valid, "BF16", "BF16", "F32")
+	dtypes, err := readSafetensorsDtypes(valid)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"BF16": 2, "F32": 1}, dtypes)
+
+	truncated := filepath.Join(tempDir, "truncated.safetensors")
+	require.NoError(t, os.WriteFile(truncated, []byte{0x01, 0x02}, 0644))
+	_, err = readSafetensorsDtypes(truncated)
+	assert.Error(t, err)
+
+	oversized := filepath.Join(tempDir, "oversized.safetensors")
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, maxSafetensorsHeaderSize+1)
+	require.NoError(t, os.WriteFile(oversized, buf, 0644))
+	_, err = readSafetensorsDtypes(oversized)
+	assert.Error(t, err)
+
+	invalid := filepath.Join(tempDir, "invalid.safetensors")
+	buf = make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, 4)
+	require.NoError(t, os.WriteFile(invalid, append(buf, []byte("nope")...), 0644))
+	_, err = readSafetensorsDtypes(invalid)
+	assert.Error(t, err)
+}
+
+func TestGenerateBySafetensors(t *testing.T) {
+	testcases := []struct {
+		name              string
+		files             map[string][]string
+		precision         string
+		expectedPrecision string
+	}{
+		{
+			name:              "single dtype",
+			files:             map[string][]string{"model.safetensors": {"F16", "F16"}},
+			expectedPrecision: "float16",
+		},
+		{
+			name: "mixed precision across shards",
+			files: map[string][]string{
+				"model-00001-of-00002.safetensors": {"BF16", "BF16", "F32"},
+				"model-00002-of-00002.safetensors": {"BF16", "F32"},
+			},
+			expectedPrecision: "bfloat16",
+		},
+		{
+			name:              "tie is broken by dtype name",
+			files:             map[string][]string{"model.safetensors": {"F32", "BF16"}},
+			expectedPrecision: "bfloat16",
+		},
+		{
+			name:              "precision from model config takes precedence",
+			files:             map[string][]string{"model.safetensors": {"F32"}},
+			precision:         "float16",
+			expectedPrecision: "float16",
+		},
+		{
+			name:              "unknown dtype is lowercased",
+			files:             map[string][]string{"model.safetensors": {"F4"}},
+			expectedPrecision: "f4",
+		},
+		{
+			name:              "invalid safetensors is ignored",
+			files:             map[string][]string{"model.safetensors": nil},
+			expectedPrecision: "",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			mf := &modelfile{workspace: tempDir, model: hashset.New(), precision: tc.precision}
+			for filename, dtypes := range tc.files {
+				path := filepath.Join(tempDir, filename)
+				if dtypes == nil {
+					require.NoError(t, os.WriteFile(path, []byte("invalid safetensors"), 0644))
+				} else {
+					writeSafetensors(t, path, dtypes...)
+				}
+				mf.model.Add(filename)
+			}
+
+			mf.generateBySafetensors()
+			assert.Equal(t, tc.expectedPrecision, mf.precision)
+		})
+	}
+}
+
+func TestVerifySafetensors(t *testing.T) {
+	tempDir := t.TempDir()
+	header, err := json.Marshal(map[string]interface{}{
+		"__metadata__": map[string]string{"format": "pt"},
+		"weight":       map[string]interface{}{"dtype": "F16", "shape": []int{2}, "data_offsets": []int{0, 4}},
+		"bias":         map[string]interface{}{"dtype": "F16", "shape": []int{2}, "data_offsets": []int{4, 8}},
+	})
+	require.NoError(t, err)
+
+	content := make([]byte, 8, 8+len(header)+8)
+	binary.LittleEndian.PutUint64(content, uint64(len(header)))
+	content = append(content, header...)
+	content = append(content, make([]byte, 8)...)
+
+	valid := filepath.Join(tempDir, "valid.safetensors")
+	require.NoError(t, os.WriteFile(valid, content, 0644))
+	assert.NoError(t, VerifySafetensors(valid))
+
+	// the data of the last tensor is cut off.
+	truncated := filepath.Join(tempDir, "truncated.safetensors")
+	require.NoError(t, os.WriteFile(truncated, content[:len(content)-2], 0644))
+	assert.ErrorContains(t, VerifySafetensors(truncated), "may be truncated")
+
+	// the header is cut off.
+	truncatedHeader := filepath.Join(tempDir, "truncated-header.safetensors")
+	require.NoError(t, os.WriteFile(truncatedHeader, content[:16], 0644))
+	assert.ErrorContains(t, VerifySafetensors(truncatedHeader), "failed to read safetensors header")
+
+	invalidOffsets := filepath.Join(tempDir, "invalid-offsets.safetensors")
+	writeSafetensors(t, invalidOffsets, "F16")
+	raw, err := os.ReadFile(invalidOffsets)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(invalidOffsets, []byte(strings.Replace(string(raw), `"data_offsets":[0,0]`, `"data_offsets":[2,1]`, 1)), 0644))
+	assert.ErrorContains(t, VerifySafetensors(invalidOffsets), "begin 2 is greater than end 1")
+}
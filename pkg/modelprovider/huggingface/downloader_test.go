@@ -17,10 +17,16 @@
 package huggingface
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestParseModelURL(t *testing.T) {
@@ -169,6 +175,101 @@ func TestProvider_Name(t *testing.T) {
 	}
 }
 
+func TestProvider_DownloadSnapshot(t *testing.T) {
+	files := map[string]string{
+		"config.json":       `{"model_type": "llama"}`,
+		"model.safetensors": "weights",
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/owner/repo/revision/main", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"siblings":[{"rfilename":"config.json"},{"rfilename":"model.safetensors"}]}`)
+	})
+	mux.HandleFunc("/owner/repo/resolve/main/", func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/owner/repo/resolve/main/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		io.WriteString(w, content)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("HF_ENDPOINT", server.URL)
+	t.Setenv("HF_TOKEN", "")
+	t.Setenv("HF_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	destDir := t.TempDir()
+	path, err := New().DownloadSnapshot(context.Background(), "owner/repo", destDir)
+	if err != nil {
+		t.Fatalf("DownloadSnapshot() returned error: %v", err)
+	}
+	if path != filepath.Join(destDir, "repo") {
+		t.Errorf("DownloadSnapshot() = %q, want %q", path, filepath.Join(destDir, "repo"))
+	}
+
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("content of %s = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := New().DownloadSnapshot(context.Background(), "invalid", destDir); err == nil {
+		t.Error("DownloadSnapshot() expected error for the invalid model identifier")
+	}
+}
+
+func TestProvider_DownloadSnapshotConcurrency(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		inflight, peak int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/owner/repo/revision/main", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"siblings":[{"rfilename":"a"},{"rfilename":"b"},{"rfilename":"c"},{"rfilename":"d"},{"rfilename":"e"}]}`)
+	})
+	mux.HandleFunc("/owner/repo/resolve/main/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inflight++
+		peak = max(peak, inflight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inflight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, "content")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("HF_ENDPOINT", server.URL)
+	t.Setenv("HF_TOKEN", "")
+	t.Setenv("HF_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	for _, concurrency := range []int{1, 2} {
+		peak = 0
+		provider := New()
+		provider.SetConcurrency(concurrency)
+		if _, err := provider.DownloadSnapshot(context.Background(), "owner/repo", t.TempDir()); err != nil {
+			t.Fatalf("DownloadSnapshot() returned error: %v", err)
+		}
+
+		if peak > concurrency {
+			t.Errorf("DownloadSnapshot() downloaded %d files concurrently, want at most %d", peak, concurrency)
+		}
+	}
+}
+
 func TestTokenFilePaths(t *testing.T) {
 	t.Run("without HF_HOME", func(t *testing.T) {
 		// Ensure HF_HOME is unset
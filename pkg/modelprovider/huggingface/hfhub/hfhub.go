@@ -0,0 +1,434 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package hfhub downloads the model files from the HuggingFace Hub over HTTP,
+// which does not require the HuggingFace CLI to be installed.
+package hfhub
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	retry "github.com/avast/retry-go/v4"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	internalpb "github.com/modelpack/modctl/internal/pb"
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+const (
+	// DefaultEndpoint is the endpoint of the HuggingFace Hub.
+	DefaultEndpoint = "https://huggingface.co"
+
+	// DefaultRevision is the revision to download by default.
+	DefaultRevision = "main"
+
+	defaultRetryAttempts = 3
+	defaultRetryDelay    = 2 * time.Second
+
+	// partialSuffix is the suffix of the partial file kept for resuming the download.
+	partialSuffix = ".incomplete"
+)
+
+// ErrDigestMismatch is returned when the downloaded file does not match the sha256 served by the hub.
+var ErrDigestMismatch = errors.New("digest mismatch")
+
+// StatusError is the error of the unexpected status code from the hub.
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from %s", e.StatusCode, e.URL)
+}
+
+// Client downloads the files of the model repositories from the HuggingFace Hub.
+type Client struct {
+	endpoint       string
+	revision       string
+	token          string
+	httpClient     *http.Client
+	progressWriter io.Writer
+	retryAttempts  uint
+	retryDelay     time.Duration
+	resume         bool
+}
+
+// Option configures the client.
+type Option func(*Client)
+
+// WithEndpoint sets the endpoint of the hub, such as a mirror of the HuggingFace Hub.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.endpoint = strings.TrimSuffix(endpoint, "/")
+	}
+}
+
+// WithRevision sets the revision of the repository to download, which can be a branch, tag or commit.
+func WithRevision(revision string) Option {
+	return func(c *Client) {
+		c.revision = revision
+	}
+}
+
+// WithToken sets the token to access the gated or private repositories.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithProgressWriter sets the writer of the progress bar.
+func WithProgressWriter(w io.Writer) Option {
+	return func(c *Client) {
+		c.progressWriter = w
+	}
+}
+
+// WithRetry sets the attempts and the initial delay of the retry for each file.
+func WithRetry(attempts uint, delay time.Duration) Option {
+	return func(c *Client) {
+		c.retryAttempts = attempts
+		c.retryDelay = delay
+	}
+}
+
+// WithResume keeps the partial file of the failed download and resumes it by HTTP Range,
+// on the retries and the later downloads into the same directory.
+func WithResume(resume bool) Option {
+	return func(c *Client) {
+		c.resume = resume
+	}
+}
+
+// New creates a new client, the endpoint defaults to HF_ENDPOINT if set.
+func New(opts ...Option) *Client {
+	endpoint := DefaultEndpoint
+	if env := os.Getenv("HF_ENDPOINT"); env != "" {
+		endpoint = strings.TrimSuffix(env, "/")
+	}
+
+	c := &Client{
+		endpoint:       endpoint,
+		revision:       DefaultRevision,
+		httpClient:     http.DefaultClient,
+		progressWriter: os.Stdout,
+		retryAttempts:  defaultRetryAttempts,
+		retryDelay:     defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ListFiles returns the paths of the files in the repository.
+func (c *Client) ListFiles(ctx context.Context, owner, repo string) ([]string, error) {
+	apiURL := fmt.Sprintf("%s/api/models/%s/%s/revision/%s", c.endpoint, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.revision))
+	resp, err := c.get(ctx, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s/%s: %w", owner, repo, err)
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		Siblings []struct {
+			Filename string `json:"rfilename"`
+		} `json:"siblings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode model info of %s/%s: %w", owner, repo, err)
+	}
+
+	files := make([]string, 0, len(info.Siblings))
+	for _, sibling := range info.Siblings {
+		files = append(files, sibling.Filename)
+	}
+
+	return files, nil
+}
+
+// DownloadFile downloads the file of the repository to the same relative path in destDir.
+func (c *Client) DownloadFile(ctx context.Context, owner, repo, file, destDir string) error {
+	return c.DownloadFiles(ctx, owner, repo, []string{file}, destDir, 1)
+}
+
+// DownloadFiles downloads the files of the repository to destDir in parallel with a shared progress bar,
+// each file is retried independently, and the failures are aggregated after all the files are attempted.
+func (c *Client) DownloadFiles(ctx context.Context, owner, repo string, files []string, destDir string, concurrency int) error {
+	if concurrency < 1 {
+		return fmt.Errorf("invalid concurrency: %d", concurrency)
+	}
+
+	pb := internalpb.NewProgressBar(c.progressWriter)
+	pb.Start()
+	defer pb.Stop()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+
+	g := &errgroup.Group{}
+	g.SetLimit(concurrency)
+	for _, file := range files {
+		g.Go(func() error {
+			err := retry.Do(func() error {
+				return c.downloadFile(ctx, pb, owner, repo, file, destDir)
+			}, retry.Context(ctx), retry.Attempts(c.retryAttempts), retry.Delay(c.retryDelay), retry.DelayType(retry.BackOffDelay), retry.LastErrorOnly(true))
+			if err != nil {
+				pb.Abort(file, err)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", file, err))
+				mu.Unlock()
+			}
+
+			// Never fail the group, so the other files are still downloaded.
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to download %d of %d files from %s/%s: %w", len(errs), len(files), owner, repo, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// downloadFile downloads a single file into a temporary file, which is renamed to the destination on success.
+// With resume, the partial file is kept on failure and the download continues from its size by HTTP Range.
+func (c *Client) downloadFile(ctx context.Context, pb *internalpb.ProgressBar, owner, repo, file, destDir string) error {
+	destPath := filepath.Join(destDir, filepath.FromSlash(file))
+	rel, err := filepath.Rel(destDir, destPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return retry.Unrecoverable(fmt.Errorf("invalid file path %q", file))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return retry.Unrecoverable(fmt.Errorf("failed to create directory: %w", err))
+	}
+
+	var (
+		tmp    *os.File
+		offset int64
+	)
+	if c.resume {
+		tmp, err = os.OpenFile(destPath+partialSuffix, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return retry.Unrecoverable(fmt.Errorf("failed to open partial file: %w", err))
+		}
+
+		if offset, err = tmp.Seek(0, io.SeekEnd); err != nil {
+			tmp.Close()
+			return retry.Unrecoverable(fmt.Errorf("failed to seek partial file: %w", err))
+		}
+	} else {
+		tmp, err = os.CreateTemp(filepath.Dir(destPath), "."+filepath.Base(destPath)+".*.tmp")
+		if err != nil {
+			return retry.Unrecoverable(fmt.Errorf("failed to create temp file: %w", err))
+		}
+		defer os.Remove(tmp.Name())
+	}
+	defer tmp.Close()
+
+	header := http.Header{}
+	if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := c.get(ctx, c.fileURL(owner, repo, file), header)
+	if err != nil {
+		var statusErr *StatusError
+		if offset > 0 && errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			// The partial file is not a prefix of the file anymore, discard it to download from scratch on retry.
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return fmt.Errorf("failed to resume download from %d bytes: %s", offset, statusErr)
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		if total >= 0 {
+			total += offset
+		}
+	} else if offset > 0 {
+		// The hub ignored the range and sent the whole file, so start over.
+		if err := tmp.Truncate(0); err != nil {
+			return retry.Unrecoverable(fmt.Errorf("failed to truncate partial file: %w", err))
+		}
+
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return retry.Unrecoverable(fmt.Errorf("failed to seek partial file: %w", err))
+		}
+		offset = 0
+	}
+
+	reader := pb.Add(internalpb.NormalizePrompt("Downloading"), file, total, resp.Body)
+	if bar := pb.Get(file); bar != nil && offset > 0 {
+		bar.SetCurrent(offset)
+	}
+
+	// Hash the resumed prefix to verify the whole file against the digest served by the hub.
+	hasher := checksum.NewSHA256()
+	if offset > 0 {
+		if err := hashPrefix(hasher, tmp.Name(), offset); err != nil {
+			return retry.Unrecoverable(err)
+		}
+	}
+
+	written, err := io.Copy(io.MultiWriter(tmp, hasher), reader)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if size := offset + written; total >= 0 && size != total {
+		if size > total {
+			os.Remove(tmp.Name())
+		}
+
+		return fmt.Errorf("size mismatch of %s: expected %d bytes, got %d bytes", file, total, size)
+	}
+
+	if expected := expectedSHA256(resp); expected != "" {
+		if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected {
+			os.Remove(tmp.Name())
+			return fmt.Errorf("%w of %s: expected sha256 %s, got %s", ErrDigestMismatch, file, expected, actual)
+		}
+	} else {
+		logrus.Debugf("hfhub: no sha256 served for %s, skipping the verification", file)
+	}
+
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		return retry.Unrecoverable(fmt.Errorf("failed to rename temp file: %w", err))
+	}
+
+	pb.Complete(file, fmt.Sprintf("%s %s", internalpb.NormalizePrompt("Downloaded"), file))
+	return nil
+}
+
+// hashPrefix writes the first size bytes of the file to the hasher.
+func hashPrefix(hasher io.Writer, path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.CopyN(hasher, f, size); err != nil {
+		return fmt.Errorf("failed to hash partial file: %w", err)
+	}
+
+	return nil
+}
+
+// expectedSHA256 returns the sha256 of the file served by the hub, which is the X-Linked-ETag of the LFS files
+// on the resolve response before redirecting to the storage, or the ETag if it is a sha256. The ETag of the
+// regular files is the git blob sha1, which is not verified.
+func expectedSHA256(resp *http.Response) string {
+	var etags []string
+	for r := resp; r != nil; {
+		etags = append(etags, r.Header.Get("X-Linked-ETag"))
+		if r.Request == nil {
+			break
+		}
+		r = r.Request.Response
+	}
+	etags = append(etags, resp.Header.Get("ETag"))
+
+	for _, etag := range etags {
+		etag = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+		if len(etag) == checksum.SHA256Size*2 {
+			if _, err := hex.DecodeString(etag); err == nil {
+				return strings.ToLower(etag)
+			}
+		}
+	}
+
+	return ""
+}
+
+// fileURL returns the URL to resolve the file of the repository.
+func (c *Client) fileURL(owner, repo, file string) string {
+	segments := strings.Split(file, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return fmt.Sprintf("%s/%s/%s/resolve/%s/%s", c.endpoint, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(c.revision), strings.Join(segments, "/"))
+}
+
+// get sends the GET request with the token and the header, the client errors except too many requests are not retried.
+func (c *Client) get(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, retry.Unrecoverable(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		err := &StatusError{StatusCode: resp.StatusCode, URL: rawURL}
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, retry.Unrecoverable(err)
+		}
+
+		return nil, err
+	}
+
+	return resp, nil
+}
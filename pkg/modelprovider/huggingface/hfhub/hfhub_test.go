@@ -0,0 +1,316 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hfhub
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHubServer returns the mock hub serving the files of owner/repo with the support of HTTP Range,
+// the file named flaky fails once and the file named dropped is cut in the middle once.
+func newHubServer(t *testing.T, files map[string]string) (*httptest.Server, *atomic.Int32) {
+	var flaky, dropped atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/models/owner/repo/revision/main", func(w http.ResponseWriter, r *http.Request) {
+		var siblings []string
+		for name := range files {
+			siblings = append(siblings, `{"rfilename":"`+name+`"}`)
+		}
+		io.WriteString(w, `{"siblings":[`+strings.Join(siblings, ",")+`]}`)
+	})
+	mux.HandleFunc("/owner/repo/resolve/main/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		name := strings.TrimPrefix(r.URL.Path, "/owner/repo/resolve/main/")
+		if name == "flaky" && flaky.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		content, ok := files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if name == "dropped" && dropped.Add(1) == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			io.WriteString(w, content[:len(content)/2])
+			return
+		}
+
+		w.Header().Set("X-Range", r.Header.Get("Range"))
+		http.ServeContent(w, r, name, time.Time{}, strings.NewReader(content))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &flaky
+}
+
+func TestDownloadFiles(t *testing.T) {
+	files := map[string]string{
+		"config.json":               `{"model_type": "llama"}`,
+		"model.safetensors":         strings.Repeat("w", 1<<16),
+		"tokenizer/tokenizer.json":  `{}`,
+		"flaky":                     "recovered",
+		"docs/README with space.md": "# readme",
+	}
+	server, flaky := newHubServer(t, files)
+	client := New(WithEndpoint(server.URL), WithToken("token"), WithProgressWriter(io.Discard), WithRetry(3, 0))
+	ctx := context.Background()
+
+	listed, err := client.ListFiles(ctx, "owner", "repo")
+	require.NoError(t, err)
+	assert.Len(t, listed, len(files))
+
+	destDir := t.TempDir()
+	require.NoError(t, client.DownloadFiles(ctx, "owner", "repo", listed, destDir, 3))
+	for name, content := range files {
+		got, err := os.ReadFile(filepath.Join(destDir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, content, string(got), name)
+	}
+	assert.Equal(t, int32(2), flaky.Load())
+
+	t.Run("single file", func(t *testing.T) {
+		destDir := t.TempDir()
+		require.NoError(t, client.DownloadFile(ctx, "owner", "repo", "config.json", destDir))
+		got, err := os.ReadFile(filepath.Join(destDir, "config.json"))
+		require.NoError(t, err)
+		assert.Equal(t, files["config.json"], string(got))
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		destDir := t.TempDir()
+		err := client.DownloadFiles(ctx, "owner", "repo", []string{"config.json", "missing-a", "missing-b", "../escape"}, destDir, 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to download 3 of 4 files")
+		assert.Contains(t, err.Error(), "missing-a")
+		assert.Contains(t, err.Error(), "missing-b")
+		assert.Contains(t, err.Error(), "invalid file path")
+
+		// The other files are still downloaded and no temp files are left.
+		_, err = os.Stat(filepath.Join(destDir, "config.json"))
+		assert.NoError(t, err)
+		entries, err := os.ReadDir(destDir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("invalid concurrency", func(t *testing.T) {
+		assert.Error(t, client.DownloadFiles(ctx, "owner", "repo", []string{"config.json"}, t.TempDir(), 0))
+	})
+}
+
+// newBlockingHubServer returns the mock hub whose downloads block until the number of the in-flight
+// downloads reaches the expected one or the timeout, and the maximum number of them observed.
+func newBlockingHubServer(t *testing.T, expected int32) (*httptest.Server, *atomic.Int32) {
+	var inflight, peak atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/owner/repo/resolve/main/", func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			current := peak.Load()
+			if n <= current || peak.CompareAndSwap(current, n) {
+				break
+			}
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for peak.Load() < expected && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		io.WriteString(w, strings.TrimPrefix(r.URL.Path, "/owner/repo/resolve/main/"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &peak
+}
+
+func TestDownloadFilesConcurrency(t *testing.T) {
+	files := []string{"model-1.safetensors", "model-2.safetensors", "model-3.safetensors", "model-4.safetensors", "model-5.safetensors", "model-6.safetensors"}
+	for _, concurrency := range []int{1, 2, 4} {
+		t.Run(strconv.Itoa(concurrency), func(t *testing.T) {
+			server, peak := newBlockingHubServer(t, int32(concurrency))
+			client := New(WithEndpoint(server.URL), WithProgressWriter(io.Discard))
+
+			destDir := t.TempDir()
+			require.NoError(t, client.DownloadFiles(context.Background(), "owner", "repo", files, destDir, concurrency))
+			assert.Equal(t, int32(concurrency), peak.Load())
+
+			for _, name := range files {
+				got, err := os.ReadFile(filepath.Join(destDir, name))
+				require.NoError(t, err)
+				assert.Equal(t, name, string(got))
+			}
+		})
+	}
+}
+
+func TestDownloadFilesResume(t *testing.T) {
+	content := strings.Repeat("0123456789", 1<<12)
+	files := map[string]string{"model.safetensors": content, "dropped": content}
+	server, _ := newHubServer(t, files)
+
+	var ranges []string
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err == nil {
+			ranges = append(ranges, resp.Header.Get("X-Range"))
+		}
+		return resp, err
+	})}
+	client := New(WithEndpoint(server.URL), WithToken("token"), WithProgressWriter(io.Discard), WithRetry(3, 0), WithResume(true), WithHTTPClient(httpClient))
+	ctx := context.Background()
+
+	t.Run("truncated partial file", func(t *testing.T) {
+		ranges = nil
+		destDir := t.TempDir()
+		partial := filepath.Join(destDir, "model.safetensors"+partialSuffix)
+		require.NoError(t, os.WriteFile(partial, []byte(content[:1000]), 0644))
+
+		require.NoError(t, client.DownloadFile(ctx, "owner", "repo", "model.safetensors", destDir))
+		got, err := os.ReadFile(filepath.Join(destDir, "model.safetensors"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+		assert.Equal(t, []string{"bytes=1000-"}, ranges)
+		assert.NoFileExists(t, partial)
+	})
+
+	t.Run("dropped connection", func(t *testing.T) {
+		ranges = nil
+		destDir := t.TempDir()
+		require.NoError(t, client.DownloadFile(ctx, "owner", "repo", "dropped", destDir))
+		got, err := os.ReadFile(filepath.Join(destDir, "dropped"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+		require.Len(t, ranges, 2)
+		assert.Equal(t, fmt.Sprintf("bytes=%d-", len(content)/2), ranges[1])
+	})
+
+	t.Run("oversized partial file", func(t *testing.T) {
+		ranges = nil
+		destDir := t.TempDir()
+		partial := filepath.Join(destDir, "model.safetensors"+partialSuffix)
+		require.NoError(t, os.WriteFile(partial, []byte(content+"garbage"), 0644))
+
+		require.NoError(t, client.DownloadFile(ctx, "owner", "repo", "model.safetensors", destDir))
+		got, err := os.ReadFile(filepath.Join(destDir, "model.safetensors"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+	})
+
+	t.Run("without resume", func(t *testing.T) {
+		destDir := t.TempDir()
+		partial := filepath.Join(destDir, "model.safetensors"+partialSuffix)
+		require.NoError(t, os.WriteFile(partial, []byte("garbage"), 0644))
+
+		client := New(WithEndpoint(server.URL), WithToken("token"), WithProgressWriter(io.Discard), WithRetry(1, 0))
+		require.NoError(t, client.DownloadFile(ctx, "owner", "repo", "model.safetensors", destDir))
+		got, err := os.ReadFile(filepath.Join(destDir, "model.safetensors"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+	})
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDownloadFileVerifyDigest(t *testing.T) {
+	content := "model weights"
+	sum := sha256.Sum256([]byte(content))
+	digest := hex.EncodeToString(sum[:])
+
+	var corrupted atomic.Bool
+	mux := http.NewServeMux()
+	// The LFS files are redirected to the storage, the sha256 is only served on the resolve response.
+	mux.HandleFunc("/owner/repo/resolve/main/model.safetensors", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Linked-ETag", `"`+digest+`"`)
+		w.Header().Set("ETag", `"git-sha1"`)
+		http.Redirect(w, r, "/storage/model.safetensors", http.StatusFound)
+	})
+	mux.HandleFunc("/storage/model.safetensors", func(w http.ResponseWriter, r *http.Request) {
+		body := content
+		if corrupted.Load() {
+			body = "model weighTs"
+		}
+		http.ServeContent(w, r, "model.safetensors", time.Time{}, strings.NewReader(body))
+	})
+	// The regular files are served with the git blob sha1, which is not verified.
+	mux.HandleFunc("/owner/repo/resolve/main/config.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"0123456789abcdef0123456789abcdef01234567"`)
+		io.WriteString(w, "{}")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(WithEndpoint(server.URL), WithProgressWriter(io.Discard), WithRetry(2, 0))
+	ctx := context.Background()
+
+	destDir := t.TempDir()
+	require.NoError(t, client.DownloadFiles(ctx, "owner", "repo", []string{"model.safetensors", "config.json"}, destDir, 2))
+	got, err := os.ReadFile(filepath.Join(destDir, "model.safetensors"))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(got))
+
+	corrupted.Store(true)
+	destDir = t.TempDir()
+	err = client.DownloadFile(ctx, "owner", "repo", "model.safetensors", destDir)
+	require.ErrorIs(t, err, ErrDigestMismatch)
+	assert.Contains(t, err.Error(), digest)
+	entries, err := os.ReadDir(destDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	t.Run("resume", func(t *testing.T) {
+		corrupted.Store(false)
+		client := New(WithEndpoint(server.URL), WithProgressWriter(io.Discard), WithRetry(1, 0), WithResume(true))
+
+		// The corrupted partial file is detected after resuming.
+		destDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(destDir, "model.safetensors"+partialSuffix), []byte("MODEL"), 0644))
+		assert.ErrorIs(t, client.DownloadFile(ctx, "owner", "repo", "model.safetensors", destDir), ErrDigestMismatch)
+
+		require.NoError(t, client.DownloadFile(ctx, "owner", "repo", "model.safetensors", destDir))
+		got, err := os.ReadFile(filepath.Join(destDir, "model.safetensors"))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(got))
+	})
+}
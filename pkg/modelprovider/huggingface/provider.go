@@ -23,14 +23,22 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	configmodelfile "github.com/modelpack/modctl/pkg/config/modelfile"
+	"github.com/modelpack/modctl/pkg/modelprovider/huggingface/hfhub"
 )
 
 // Provider implements the modelprovider.Provider interface for HuggingFace
-type Provider struct{}
+type Provider struct {
+	resume      bool
+	concurrency int
+}
 
 // New creates a new HuggingFace provider instance
 func New() *Provider {
-	return &Provider{}
+	return &Provider{concurrency: configmodelfile.DefaultDownloadConcurrency}
 }
 
 // Name returns the name of this provider
@@ -38,6 +46,16 @@ func (p *Provider) Name() string {
 	return "huggingface"
 }
 
+// SetResume enables resuming the interrupted downloads of the pure-Go downloader
+func (p *Provider) SetResume(resume bool) {
+	p.resume = resume
+}
+
+// SetConcurrency sets the number of the files downloaded concurrently by the pure-Go downloader
+func (p *Provider) SetConcurrency(concurrency int) {
+	p.concurrency = concurrency
+}
+
 // SupportsURL checks if this provider can handle the given URL
 // It only supports full HuggingFace URLs with the huggingface.co domain
 // For short-form repo identifiers (owner/repo), users must explicitly specify --provider huggingface
@@ -69,12 +87,6 @@ func (p *Provider) DownloadModel(ctx context.Context, modelURL, destDir string)
 
 	repoID := fmt.Sprintf("%s/%s", owner, repo)
 
-	// Find available HuggingFace CLI tool (hf or huggingface-cli)
-	cliPath, isLegacy, err := findHFCLI()
-	if err != nil {
-		return "", err
-	}
-
 	// Create destination directory if it doesn't exist
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create destination directory: %w", err)
@@ -83,6 +95,18 @@ func (p *Provider) DownloadModel(ctx context.Context, modelURL, destDir string)
 	// Construct the download path
 	downloadPath := filepath.Join(destDir, repo)
 
+	// Find available HuggingFace CLI tool (hf or huggingface-cli),
+	// fall back to the pure-Go downloader if neither is installed.
+	cliPath, isLegacy, err := findHFCLI()
+	if err != nil {
+		logrus.Infof("huggingface: no HuggingFace CLI found, downloading %s over HTTP", repoID)
+		if err := downloadByHub(ctx, owner, repo, downloadPath, p.resume, p.concurrency); err != nil {
+			return "", err
+		}
+
+		return downloadPath, nil
+	}
+
 	// Build CLI arguments. The legacy huggingface-cli supports
 	// --local-dir-use-symlinks to ensure files are copied, not symlinked.
 	// The modern hf CLI removed that flag; --local-dir alone is sufficient.
@@ -103,7 +127,40 @@ func (p *Provider) DownloadModel(ctx context.Context, modelURL, destDir string)
 	return downloadPath, nil
 }
 
+// DownloadSnapshot downloads the snapshot of the model by the pure-Go downloader regardless of
+// the installed HuggingFace CLI, and returns the local path of the snapshot.
+func (p *Provider) DownloadSnapshot(ctx context.Context, modelURL, destDir string) (string, error) {
+	owner, repo, err := parseModelURL(modelURL)
+	if err != nil {
+		return "", err
+	}
+
+	downloadPath := filepath.Join(destDir, repo)
+	if err := downloadByHub(ctx, owner, repo, downloadPath, p.resume, p.concurrency); err != nil {
+		return "", err
+	}
+
+	return downloadPath, nil
+}
+
 // CheckAuth verifies that the user is authenticated with HuggingFace
 func (p *Provider) CheckAuth() error {
 	return checkHuggingFaceAuth()
 }
+
+// downloadByHub downloads all the files of the repository by the pure-Go downloader,
+// the token is optional as the public repositories can be downloaded anonymously.
+func downloadByHub(ctx context.Context, owner, repo, downloadPath string, resume bool, concurrency int) error {
+	opts := []hfhub.Option{hfhub.WithResume(resume)}
+	if token, err := getToken(); err == nil {
+		opts = append(opts, hfhub.WithToken(token))
+	}
+
+	client := hfhub.New(opts...)
+	files, err := client.ListFiles(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	return client.DownloadFiles(ctx, owner, repo, files, downloadPath, concurrency)
+}
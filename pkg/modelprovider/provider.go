@@ -45,3 +45,17 @@ type Provider interface {
 	// Returns an error if authentication is missing or invalid
 	CheckAuth() error
 }
+
+// Resumable is implemented by the providers which can resume the interrupted downloads.
+type Resumable interface {
+	// SetResume enables resuming the partial files left by the interrupted downloads
+	// in the destination directory instead of downloading them from scratch.
+	SetResume(resume bool)
+}
+
+// Concurrent is implemented by the providers which can download the files concurrently.
+type Concurrent interface {
+	// SetConcurrency sets the number of the files downloaded concurrently, which is bounded
+	// to respect the rate limits of the provider.
+	SetConcurrency(concurrency int)
+}
@@ -0,0 +1,177 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/modelpack/modctl/pkg/license"
+)
+
+const (
+	cyclonedxFormat      = "CycloneDX"
+	cyclonedxSpecVersion = "1.5"
+)
+
+type cyclonedxBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cyclonedxMetadata    `json:"metadata"`
+	Components   []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cyclonedxTools     `json:"tools"`
+	Component cyclonedxComponent `json:"component"`
+}
+
+type cyclonedxTools struct {
+	Components []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxComponent struct {
+	Type               string                       `json:"type"`
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	MimeType           string                       `json:"mime-type,omitempty"`
+	PURL               string                       `json:"purl,omitempty"`
+	Hashes             []cyclonedxHash              `json:"hashes,omitempty"`
+	Licenses           []cyclonedxLicenseChoice     `json:"licenses,omitempty"`
+	ExternalReferences []cyclonedxExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cyclonedxProperty          `json:"properties,omitempty"`
+}
+
+type cyclonedxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cyclonedxLicenseChoice struct {
+	License cyclonedxLicense `json:"license"`
+}
+
+type cyclonedxLicense struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cyclonedxExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cyclonedxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// generateCycloneDX generates the CycloneDX 1.5 JSON document, the model artifact is described
+// as the machine learning model component and the files as the file components.
+func generateCycloneDX(artifact *Artifact, toolVersion string) ([]byte, error) {
+	model := cyclonedxComponent{
+		Type:    "machine-learning-model",
+		BOMRef:  documentName(artifact),
+		Name:    artifact.Name,
+		Version: artifact.Version,
+	}
+
+	if artifact.Digest != "" {
+		model.PURL = purl(artifact)
+		model.Hashes = cyclonedxHashes(artifact.Digest)
+	}
+
+	for _, l := range artifact.licenses() {
+		model.Licenses = append(model.Licenses, cyclonedxLicenseOf(l))
+	}
+
+	if artifact.SourceURL != "" {
+		model.ExternalReferences = []cyclonedxExternalReference{{Type: "vcs", URL: artifact.SourceURL}}
+	}
+
+	if artifact.Revision != "" {
+		model.Properties = append(model.Properties, cyclonedxProperty{Name: "modctl:source-revision", Value: artifact.Revision})
+	}
+
+	if artifact.ModelName != "" {
+		model.Properties = append(model.Properties, cyclonedxProperty{Name: "modctl:model-name", Value: artifact.ModelName})
+	}
+
+	bom := cyclonedxBOM{
+		BOMFormat:    cyclonedxFormat,
+		SpecVersion:  cyclonedxSpecVersion,
+		SerialNumber: "urn:uuid:" + serial(artifact),
+		Version:      1,
+		Metadata: cyclonedxMetadata{
+			Timestamp: artifact.createdAt().Format(time.RFC3339),
+			Tools:     cyclonedxTools{Components: []cyclonedxComponent{{Type: "application", Name: toolName, Version: toolVersion}}},
+			Component: model,
+		},
+		Components: make([]cyclonedxComponent, 0, len(artifact.Files)),
+	}
+
+	for i, file := range artifact.Files {
+		component := cyclonedxComponent{
+			Type:     "file",
+			BOMRef:   fmt.Sprintf("file-%d", i),
+			Name:     file.Path,
+			MimeType: file.MediaType,
+			Hashes:   cyclonedxHashes(file.Digest),
+		}
+
+		if file.License != "" {
+			component.Licenses = []cyclonedxLicenseChoice{cyclonedxLicenseOf(file.License)}
+		}
+
+		bom.Components = append(bom.Components, component)
+	}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+// cyclonedxHashes converts the digest to the CycloneDX hashes.
+func cyclonedxHashes(digest godigest.Digest) []cyclonedxHash {
+	algorithms := map[godigest.Algorithm]string{
+		godigest.SHA256: "SHA-256",
+		godigest.SHA384: "SHA-384",
+		godigest.SHA512: "SHA-512",
+	}
+
+	alg, ok := algorithms[digest.Algorithm()]
+	if !ok {
+		return nil
+	}
+
+	return []cyclonedxHash{{Alg: alg, Content: digest.Encoded()}}
+}
+
+// cyclonedxLicenseOf converts the license to the CycloneDX license, the unknown license is
+// referenced by the name.
+func cyclonedxLicenseOf(id string) cyclonedxLicenseChoice {
+	if license.Known(id) {
+		return cyclonedxLicenseChoice{License: cyclonedxLicense{ID: id}}
+	}
+
+	return cyclonedxLicenseChoice{License: cyclonedxLicense{Name: id}}
+}
@@ -0,0 +1,186 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+	"github.com/modelpack/modctl/pkg/license"
+)
+
+const (
+	// FormatSPDX is the SPDX 2.3 JSON format.
+	FormatSPDX = "spdx"
+
+	// FormatCycloneDX is the CycloneDX 1.5 JSON format.
+	FormatCycloneDX = "cyclonedx"
+
+	// MediaTypeSPDX is the media type of the SPDX JSON document.
+	MediaTypeSPDX = "application/spdx+json"
+
+	// MediaTypeCycloneDX is the media type of the CycloneDX JSON document.
+	MediaTypeCycloneDX = "application/vnd.cyclonedx+json"
+
+	// toolName is the name of the tool creating the documents.
+	toolName = "modctl"
+)
+
+// Artifact is the model artifact described by the SBOM.
+type Artifact struct {
+	// Name is the repository of the model artifact.
+	Name string
+	// Version is the tag of the model artifact.
+	Version string
+	// Digest is the digest of the manifest of the model artifact.
+	Digest godigest.Digest
+	// ModelName is the name of the model in the config.
+	ModelName string
+	// SourceURL is the source url of the model, such as the git repository.
+	SourceURL string
+	// Revision is the source revision of the model, such as the git commit.
+	Revision string
+	// Licenses is the licenses declared in the config and detected from the license files.
+	Licenses []string
+	// CreatedAt is the creation time of the model artifact, the current time is used if zero.
+	CreatedAt time.Time
+	// Files is the files packed in the model artifact.
+	Files []File
+}
+
+// File is the file packed in the layer of the model artifact.
+type File struct {
+	// Path is the filepath of the file.
+	Path string
+	// Digest is the digest of the file content, which is empty if unknown, such as in the encrypted layer.
+	Digest godigest.Digest
+	// Size is the size of the file content, or the size of the layer if the digest is unknown.
+	Size int64
+	// MediaType is the media type of the layer.
+	MediaType string
+	// License is the license detected from the file if it is a license file.
+	License string
+}
+
+// Generate generates the SBOM document of the artifact in the format.
+func Generate(format string, artifact *Artifact, toolVersion string) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return generateSPDX(artifact, toolVersion)
+	case FormatCycloneDX:
+		return generateCycloneDX(artifact, toolVersion)
+	default:
+		return nil, fmt.Errorf("unsupported sbom format %q", format)
+	}
+}
+
+// MediaType returns the media type of the SBOM document in the format.
+func MediaType(format string) (string, error) {
+	switch format {
+	case FormatSPDX:
+		return MediaTypeSPDX, nil
+	case FormatCycloneDX:
+		return MediaTypeCycloneDX, nil
+	default:
+		return "", fmt.Errorf("unsupported sbom format %q", format)
+	}
+}
+
+// createdAt returns the creation time of the document.
+func (a *Artifact) createdAt() time.Time {
+	if a.CreatedAt.IsZero() {
+		return time.Now().UTC()
+	}
+
+	return a.CreatedAt.UTC()
+}
+
+// licenses returns the deduplicated and sorted licenses of the artifact.
+func (a *Artifact) licenses() []string {
+	seen := map[string]bool{}
+	licenses := []string{}
+	for _, l := range a.Licenses {
+		if l = strings.TrimSpace(l); l != "" && !seen[l] {
+			seen[l] = true
+			licenses = append(licenses, l)
+		}
+	}
+
+	sort.Strings(licenses)
+	return licenses
+}
+
+// sanitizeID replaces the characters which are not allowed in the SPDX identifiers.
+func sanitizeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+
+		return '-'
+	}, id)
+}
+
+// spdxLicense returns the license as the SPDX identifier, the unknown license is referenced
+// by the LicenseRef- prefix.
+func spdxLicense(id string) string {
+	if license.Known(id) {
+		return id
+	}
+
+	return "LicenseRef-" + sanitizeID(id)
+}
+
+// documentName returns the name of the document, such as registry/repo:tag.
+func documentName(artifact *Artifact) string {
+	if artifact.Version == "" {
+		return artifact.Name
+	}
+
+	return fmt.Sprintf("%s:%s", artifact.Name, artifact.Version)
+}
+
+// purl returns the package url of the model artifact in the oci type.
+func purl(artifact *Artifact) string {
+	name := artifact.Name
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	p := fmt.Sprintf("pkg:oci/%s@%s?repository_url=%s", name, url.PathEscape(artifact.Digest.String()), url.QueryEscape(artifact.Name))
+	if artifact.Version != "" {
+		p += "&tag=" + url.QueryEscape(artifact.Version)
+	}
+
+	return p
+}
+
+// serial returns the uuid derived from the artifact, so the documents generated for the same
+// artifact are identical.
+func serial(artifact *Artifact) string {
+	sum := checksum.Sum256([]byte(documentName(artifact) + "@" + artifact.Digest.String()))
+	// Set the version 5 and the RFC 4122 variant bits.
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,103 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestArtifact() *Artifact {
+	return &Artifact{
+		Name:      "registry.example.com/models/llama",
+		Version:   "v1",
+		Digest:    godigest.FromString("manifest"),
+		ModelName: "llama",
+		SourceURL: "https://github.com/example/llama",
+		Revision:  "abc123",
+		Licenses:  []string{"Apache-2.0", "llama3", "Apache-2.0"},
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Files: []File{
+			{Path: "model.safetensors", Digest: godigest.FromString("weight"), Size: 6, MediaType: "application/vnd.cncf.model.weight.v1.raw"},
+			{Path: "config.json", Digest: godigest.FromString("config"), Size: 6, MediaType: "application/vnd.cncf.model.weight.config.v1.raw"},
+			{Path: "LICENSE", Digest: godigest.FromString("license"), Size: 7, MediaType: "application/vnd.cncf.model.doc.v1.raw", License: "Apache-2.0"},
+		},
+	}
+}
+
+func TestGenerateSPDX(t *testing.T) {
+	artifact := newTestArtifact()
+	data, err := Generate(FormatSPDX, artifact, "v0.0.1")
+	require.NoError(t, err)
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "2025-01-01T00:00:00Z", doc.CreationInfo.Created)
+	require.Len(t, doc.Packages, 1)
+	assert.Equal(t, "https://github.com/example/llama", doc.Packages[0].DownloadLocation)
+	assert.Equal(t, "Apache-2.0 AND LicenseRef-llama3", doc.Packages[0].LicenseDeclared)
+
+	require.Len(t, doc.Files, len(artifact.Files))
+	for i, file := range artifact.Files {
+		assert.Equal(t, "./"+file.Path, doc.Files[i].FileName)
+		assert.Equal(t, []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: file.Digest.Encoded()}}, doc.Files[i].Checksums)
+		assert.Contains(t, doc.Relationships, spdxRelationship{SPDXElementID: spdxPackageID, RelationshipType: "CONTAINS", RelatedSPDXElement: doc.Files[i].SPDXID})
+	}
+	assert.Equal(t, []string{"Apache-2.0"}, doc.Files[2].LicenseInfoInFiles)
+
+	// The document of the same artifact is reproducible.
+	again, err := Generate(FormatSPDX, artifact, "v0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestGenerateCycloneDX(t *testing.T) {
+	artifact := newTestArtifact()
+	data, err := Generate(FormatCycloneDX, artifact, "v0.0.1")
+	require.NoError(t, err)
+
+	var bom cyclonedxBOM
+	require.NoError(t, json.Unmarshal(data, &bom))
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.SerialNumber)
+	assert.Equal(t, "machine-learning-model", bom.Metadata.Component.Type)
+	assert.Equal(t, []cyclonedxExternalReference{{Type: "vcs", URL: "https://github.com/example/llama"}}, bom.Metadata.Component.ExternalReferences)
+	assert.Contains(t, bom.Metadata.Component.Properties, cyclonedxProperty{Name: "modctl:source-revision", Value: "abc123"})
+	assert.Equal(t, []cyclonedxLicenseChoice{{License: cyclonedxLicense{ID: "Apache-2.0"}}, {License: cyclonedxLicense{Name: "llama3"}}}, bom.Metadata.Component.Licenses)
+
+	require.Len(t, bom.Components, len(artifact.Files))
+	for i, file := range artifact.Files {
+		assert.Equal(t, "file", bom.Components[i].Type)
+		assert.Equal(t, file.Path, bom.Components[i].Name)
+		assert.Equal(t, []cyclonedxHash{{Alg: "SHA-256", Content: file.Digest.Encoded()}}, bom.Components[i].Hashes)
+	}
+}
+
+func TestGenerateUnsupportedFormat(t *testing.T) {
+	_, err := Generate("swid", newTestArtifact(), "v0.0.1")
+	assert.ErrorContains(t, err, `unsupported sbom format "swid"`)
+
+	_, err = MediaType("swid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDataLicense = "CC0-1.0"
+	spdxNoAssertion = "NOASSERTION"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxPackageID   = "SPDXRef-Package-model"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxFile struct {
+	SPDXID             string         `json:"SPDXID"`
+	FileName           string         `json:"fileName"`
+	Checksums          []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded   string         `json:"licenseConcluded"`
+	LicenseInfoInFiles []string       `json:"licenseInfoInFiles,omitempty"`
+	CopyrightText      string         `json:"copyrightText"`
+	Comment            string         `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// generateSPDX generates the SPDX 2.3 JSON document, the model artifact is described as a
+// package containing the files of the layers.
+func generateSPDX(artifact *Artifact, toolVersion string) ([]byte, error) {
+	licenses := artifact.licenses()
+	declared := spdxNoAssertion
+	if len(licenses) > 0 {
+		ids := make([]string, 0, len(licenses))
+		for _, l := range licenses {
+			ids = append(ids, spdxLicense(l))
+		}
+
+		declared = strings.Join(ids, " AND ")
+	}
+
+	downloadLocation := spdxNoAssertion
+	if artifact.SourceURL != "" {
+		downloadLocation = artifact.SourceURL
+	}
+
+	pkg := spdxPackage{
+		SPDXID:           spdxPackageID,
+		Name:             artifact.Name,
+		VersionInfo:      artifact.Version,
+		DownloadLocation: downloadLocation,
+		FilesAnalyzed:    true,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  declared,
+		CopyrightText:    spdxNoAssertion,
+	}
+
+	if artifact.Revision != "" {
+		pkg.SourceInfo = fmt.Sprintf("built from revision %s", artifact.Revision)
+	}
+
+	if artifact.Digest != "" {
+		pkg.Checksums = []spdxChecksum{spdxDigestChecksum(artifact.Digest.Algorithm().String(), artifact.Digest.Encoded())}
+		pkg.ExternalRefs = []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  purl(artifact),
+		}}
+	}
+
+	doc := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       spdxDataLicense,
+		SPDXID:            spdxDocumentID,
+		Name:              documentName(artifact),
+		DocumentNamespace: fmt.Sprintf("https://modelpack.org/spdxdocs/%s-%s", sanitizeID(documentName(artifact)), serial(artifact)),
+		CreationInfo: spdxCreationInfo{
+			Created:  artifact.createdAt().Format(time.RFC3339),
+			Creators: []string{fmt.Sprintf("Tool: %s-%s", toolName, toolVersion)},
+		},
+		Packages:      []spdxPackage{pkg},
+		Files:         make([]spdxFile, 0, len(artifact.Files)),
+		Relationships: []spdxRelationship{{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxPackageID}},
+	}
+
+	for i, file := range artifact.Files {
+		id := fmt.Sprintf("SPDXRef-File-%d", i)
+		f := spdxFile{
+			SPDXID:           id,
+			FileName:         "./" + strings.TrimPrefix(file.Path, "./"),
+			LicenseConcluded: spdxNoAssertion,
+			CopyrightText:    spdxNoAssertion,
+			Comment:          file.MediaType,
+		}
+
+		if file.Digest != "" {
+			f.Checksums = []spdxChecksum{spdxDigestChecksum(file.Digest.Algorithm().String(), file.Digest.Encoded())}
+		}
+
+		if file.License != "" {
+			f.LicenseInfoInFiles = []string{spdxLicense(file.License)}
+		}
+
+		doc.Files = append(doc.Files, f)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: spdxPackageID, RelationshipType: "CONTAINS", RelatedSPDXElement: id})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// spdxDigestChecksum converts the digest to the SPDX checksum.
+func spdxDigestChecksum(algorithm, encoded string) spdxChecksum {
+	return spdxChecksum{Algorithm: strings.ToUpper(algorithm), ChecksumValue: encoded}
+}
@@ -0,0 +1,138 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package signature verifies the signatures of the model artifacts signed by cosign with a key,
+// which are stored in the registry as the simple signing payloads referencing the manifest digest.
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/modelpack/modctl/pkg/checksum"
+)
+
+const (
+	// MediaTypeSimpleSigning is the media type of the layer storing the simple signing payload.
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+	// ArtifactTypeSignature is the artifact type of the signature stored as the referrer.
+	ArtifactTypeSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
+
+	// AnnotationSignature is the annotation key of the layer storing the base64 encoded signature
+	// over the payload.
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+
+	// tagSuffix is the suffix of the tag storing the signatures of the manifest.
+	tagSuffix = ".sig"
+)
+
+// ErrInvalidSignature is returned when the signature is not verified by the key.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Payload is the simple signing payload signed by the signature.
+type Payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]any `json:"optional,omitempty"`
+}
+
+// Tag returns the tag storing the signatures of the manifest, such as sha256-<hex>.sig.
+func Tag(digest godigest.Digest) string {
+	return fmt.Sprintf("%s-%s%s", digest.Algorithm(), digest.Encoded(), tagSuffix)
+}
+
+// LoadPublicKey loads the PEM encoded public key from the file, the ECDSA, Ed25519 and RSA keys
+// are supported.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in public key %s", path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// Verify verifies the base64 encoded signature over the payload by the key, and the payload
+// references the manifest digest.
+func Verify(key crypto.PublicKey, payload []byte, sig string, digest godigest.Digest) error {
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("%w: failed to decode signature: %s", ErrInvalidSignature, err)
+	}
+
+	hash := checksum.Sum256(payload)
+	var verified bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		verified = ecdsa.VerifyASN1(key, hash[:], raw)
+	case ed25519.PublicKey:
+		verified = ed25519.Verify(key, payload, raw)
+	case *rsa.PublicKey:
+		verified = rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], raw) == nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	if !verified {
+		return fmt.Errorf("%w: signature is not verified by the key", ErrInvalidSignature)
+	}
+
+	// the payload is trusted only after the signature is verified.
+	var p Payload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("%w: failed to decode payload: %s", ErrInvalidSignature, err)
+	}
+
+	if p.Critical.Image.DockerManifestDigest != digest.String() {
+		return fmt.Errorf("%w: payload references manifest %s instead of %s", ErrInvalidSignature, p.Critical.Image.DockerManifestDigest, digest)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePublicKey writes the PEM encoded public key into the directory and returns the path.
+func writePublicKey(t *testing.T, dir string, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "pub.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	return path
+}
+
+// payload returns the simple signing payload referencing the digest.
+func payload(digest godigest.Digest) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"example.com/models/llama"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+}
+
+func TestVerify(t *testing.T) {
+	digest := godigest.FromString("manifest")
+	content := payload(digest)
+	hash := sha256.Sum256(content)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaSig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, hash[:])
+	require.NoError(t, err)
+
+	ed25519Pub, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		key  crypto.PublicKey
+		sig  []byte
+	}{
+		{"ecdsa", &ecdsaKey.PublicKey, ecdsaSig},
+		{"ed25519", ed25519Pub, ed25519.Sign(ed25519Key, content)},
+		{"rsa", &rsaKey.PublicKey, rsaSig},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := LoadPublicKey(writePublicKey(t, t.TempDir(), tc.key))
+			require.NoError(t, err)
+
+			sig := base64.StdEncoding.EncodeToString(tc.sig)
+			assert.NoError(t, Verify(key, content, sig, digest))
+
+			// the signature over the payload referencing another manifest is rejected.
+			assert.ErrorIs(t, Verify(key, content, sig, godigest.FromString("other")), ErrInvalidSignature)
+
+			// the tampered payload is rejected.
+			tampered := payload(godigest.FromString("other"))
+			assert.ErrorIs(t, Verify(key, tampered, sig, godigest.FromString("other")), ErrInvalidSignature)
+		})
+	}
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	assert.ErrorIs(t, Verify(&otherKey.PublicKey, content, base64.StdEncoding.EncodeToString(ecdsaSig), digest), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(&ecdsaKey.PublicKey, content, "not base64!", digest), ErrInvalidSignature)
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LoadPublicKey(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0644))
+	_, err = LoadPublicKey(path)
+	assert.ErrorContains(t, err, "no PEM block")
+}
+
+func TestTag(t *testing.T) {
+	digest := godigest.FromString("manifest")
+	assert.Equal(t, "sha256-"+digest.Encoded()+".sig", Tag(digest))
+}
@@ -18,10 +18,13 @@ package distribution
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"path"
 	"regexp"
+	"strings"
 	"time"
 
 	distribution "github.com/distribution/distribution/v3"
@@ -29,9 +32,10 @@ import (
 	"github.com/distribution/distribution/v3/registry/storage/driver"
 	"github.com/distribution/distribution/v3/registry/storage/driver/filesystem"
 	ref "github.com/distribution/reference"
-	sha256 "github.com/minio/sha256-simd"
 	godigest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/checksum"
 )
 
 func init() {
@@ -47,8 +51,28 @@ const (
 	StorageTypeDistribution = "distribution"
 	// defaultMaxThreads is the default max threads of the storage.
 	defaultMaxThreads = 100
+	// repositoriesPath is the root path of the repositories in the storage driver.
+	repositoriesPath = "/docker/registry/v2/repositories"
+	// uploadsDir is the directory of the in-progress uploads in the repository.
+	uploadsDir = "_uploads"
 )
 
+// ErrNotFound is returned when the manifest or tag is not found in the storage.
+var ErrNotFound = errors.New("not found")
+
+// Upload is the in-progress upload in the storage, which is left by the
+// interrupted push or build.
+type Upload struct {
+	// Repository is the repository of the upload.
+	Repository string
+	// ID is the identifier of the upload.
+	ID string
+	// Size is the size of the uploaded data.
+	Size int64
+	// StartedAt is the time when the upload started, which is zero if unknown.
+	StartedAt time.Time
+}
+
 type storage struct {
 	// driver is the underlying storage implementation.
 	driver driver.StorageDriver
@@ -93,12 +117,12 @@ func (s *storage) PullManifest(ctx context.Context, repo, reference string) ([]b
 
 	tag, err := repository.Tags(ctx).Get(ctx, reference)
 	if err != nil {
-		return nil, "", err
+		return nil, "", notFound(err)
 	}
 
 	imageManifest, err := manifest.Get(ctx, tag.Digest)
 	if err != nil {
-		return nil, "", err
+		return nil, "", notFound(err)
 	}
 
 	_, payload, err := imageManifest.Payload()
@@ -109,8 +133,22 @@ func (s *storage) PullManifest(ctx context.Context, repo, reference string) ([]b
 	return payload, tag.Digest.String(), nil
 }
 
-// PushManifest pushes the manifest to the storage.
-func (s *storage) PushManifest(ctx context.Context, repo, reference string, manifestBytes []byte) (string, error) {
+// notFound wraps the unknown tag and manifest errors with ErrNotFound, so that
+// the callers can tell them apart from the other storage errors.
+func notFound(err error) error {
+	var (
+		tagErr      distribution.ErrTagUnknown
+		manifestErr distribution.ErrManifestUnknownRevision
+	)
+	if errors.As(err, &tagErr) || errors.As(err, &manifestErr) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	return err
+}
+
+// PushManifest pushes the manifest of the media type to the storage.
+func (s *storage) PushManifest(ctx context.Context, repo, reference, mediaType string, manifestBytes []byte) (string, error) {
 	repository, err := s.repository(ctx, repo)
 	if err != nil {
 		return "", err
@@ -121,8 +159,7 @@ func (s *storage) PushManifest(ctx context.Context, repo, reference string, mani
 		return "", err
 	}
 
-	// TODO: pass in the mediatype from function parameters.
-	imageManifest, desc, err := distribution.UnmarshalManifest(ocispec.MediaTypeImageManifest, manifestBytes)
+	imageManifest, desc, err := distribution.UnmarshalManifest(manifestMediaType(mediaType, manifestBytes), manifestBytes)
 	if err != nil {
 		return "", err
 	}
@@ -140,6 +177,23 @@ func (s *storage) PushManifest(ctx context.Context, repo, reference string, mani
 	return digest.String(), nil
 }
 
+// manifestMediaType returns the media type of the manifest, which is detected from the mediaType
+// field of the manifest if it is not specified, and defaults to the OCI image manifest.
+func manifestMediaType(mediaType string, manifestBytes []byte) string {
+	if mediaType != "" {
+		return mediaType
+	}
+
+	var versioned struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(manifestBytes, &versioned); err == nil && versioned.MediaType != "" {
+		return versioned.MediaType
+	}
+
+	return ocispec.MediaTypeImageManifest
+}
+
 // DeleteManifest deletes the manifest from the storage.
 func (s *storage) DeleteManifest(ctx context.Context, repo, reference string) error {
 	repository, err := s.repository(ctx, repo)
@@ -180,7 +234,7 @@ func (s *storage) PushBlob(ctx context.Context, repo string, blobReader io.Reade
 		return "", 0, err
 	}
 
-	hash := sha256.New()
+	hash := checksum.NewSHA256()
 	if provisional.Digest == "" {
 		blobReader = io.TeeReader(blobReader, hash)
 	}
@@ -318,3 +372,67 @@ func (s *storage) PerformPurgeUploads(ctx context.Context, dryRun bool) error {
 	_, errs := registry.PurgeUploads(ctx, s.driver, time.Now(), !dryRun)
 	return errors.Join(errs...)
 }
+
+// ListUploads lists the in-progress uploads of all the repositories in the storage.
+func (s *storage) ListUploads(ctx context.Context) ([]Upload, error) {
+	uploads := []Upload{}
+	if err := s.listUploads(ctx, repositoriesPath, &uploads); err != nil {
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+// listUploads walks through the repository directories recursively to collect the
+// uploads, as the repository name may contain multiple path components.
+func (s *storage) listUploads(ctx context.Context, dir string, uploads *[]Upload) error {
+	children, err := s.driver.List(ctx, dir)
+	if err != nil {
+		var pathNotFound driver.PathNotFoundError
+		if errors.As(err, &pathNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, child := range children {
+		switch path.Base(child) {
+		case "_layers", "_manifests":
+			continue
+		case uploadsDir:
+			ids, err := s.driver.List(ctx, child)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				upload := Upload{Repository: strings.TrimPrefix(dir, repositoriesPath+"/"), ID: path.Base(id)}
+				if info, err := s.driver.Stat(ctx, path.Join(id, "data")); err == nil {
+					upload.Size = info.Size()
+				}
+
+				if startedAt, err := s.driver.GetContent(ctx, path.Join(id, "startedat")); err == nil {
+					upload.StartedAt, _ = time.Parse(time.RFC3339, string(startedAt))
+				}
+
+				*uploads = append(*uploads, upload)
+			}
+		default:
+			if err := s.listUploads(ctx, child, uploads); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeleteUpload deletes the in-progress upload of the repository from the storage.
+func (s *storage) DeleteUpload(ctx context.Context, repo, id string) error {
+	if id == "" || strings.ContainsAny(id, "/\\") || id == "." || id == ".." {
+		return fmt.Errorf("invalid upload id %q", id)
+	}
+
+	return s.driver.Delete(ctx, path.Join(repositoriesPath, repo, uploadsDir, id))
+}
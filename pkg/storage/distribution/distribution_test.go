@@ -0,0 +1,190 @@
+/*
+ *     Copyright 2025 The ModelPack Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package distribution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerConfigMediaType   = "application/vnd.docker.container.image.v1+json"
+	dockerLayerMediaType    = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// pushBlob pushes the content as the blob of the media type and returns its descriptor.
+func pushBlob(t *testing.T, s *storage, repo, mediaType string, content []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: godigest.FromBytes(content), Size: int64(len(content))}
+	_, _, err := s.PushBlob(context.Background(), repo, bytes.NewReader(content), desc)
+	require.NoError(t, err)
+	return desc
+}
+
+// marshalManifest marshals the manifest of the config and the layer with the media types.
+func marshalManifest(t *testing.T, mediaType string, config, layer ocispec.Descriptor) []byte {
+	manifestRaw, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: mediaType,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	require.NoError(t, err)
+	return manifestRaw
+}
+
+func TestPushManifest(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+
+	repo := "example.com/models/llama3"
+	ociConfig := pushBlob(t, s, repo, ocispec.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux"}`))
+	ociLayer := pushBlob(t, s, repo, ocispec.MediaTypeImageLayer, []byte("oci layer"))
+	ociManifest := marshalManifest(t, ocispec.MediaTypeImageManifest, ociConfig, ociLayer)
+
+	t.Run("image manifest", func(t *testing.T) {
+		digest, err := s.PushManifest(ctx, repo, "oci", ocispec.MediaTypeImageManifest, ociManifest)
+		require.NoError(t, err)
+		assert.Equal(t, godigest.FromBytes(ociManifest).String(), digest)
+
+		content, pulledDigest, err := s.PullManifest(ctx, repo, "oci")
+		require.NoError(t, err)
+		assert.Equal(t, ociManifest, content)
+		assert.Equal(t, digest, pulledDigest)
+	})
+
+	t.Run("index", func(t *testing.T) {
+		indexRaw, err := json.Marshal(ocispec.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: []ocispec.Descriptor{{
+				MediaType: ocispec.MediaTypeImageManifest,
+				Digest:    godigest.FromBytes(ociManifest),
+				Size:      int64(len(ociManifest)),
+				Platform:  &ocispec.Platform{Architecture: "amd64", OS: "linux"},
+			}},
+		})
+		require.NoError(t, err)
+
+		digest, err := s.PushManifest(ctx, repo, "index", ocispec.MediaTypeImageIndex, indexRaw)
+		require.NoError(t, err)
+		assert.Equal(t, godigest.FromBytes(indexRaw).String(), digest)
+
+		content, _, err := s.PullManifest(ctx, repo, "index")
+		require.NoError(t, err)
+		assert.Equal(t, indexRaw, content)
+	})
+
+	t.Run("docker manifest", func(t *testing.T) {
+		dockerConfig := pushBlob(t, s, repo, dockerConfigMediaType, []byte(`{"architecture":"arm64","os":"linux"}`))
+		dockerLayer := pushBlob(t, s, repo, dockerLayerMediaType, []byte("docker layer"))
+		dockerManifest := marshalManifest(t, dockerManifestMediaType, dockerConfig, dockerLayer)
+
+		digest, err := s.PushManifest(ctx, repo, "docker", dockerManifestMediaType, dockerManifest)
+		require.NoError(t, err)
+		assert.Equal(t, godigest.FromBytes(dockerManifest).String(), digest)
+
+		content, _, err := s.PullManifest(ctx, repo, "docker")
+		require.NoError(t, err)
+		assert.Equal(t, dockerManifest, content)
+	})
+
+	t.Run("detected media type", func(t *testing.T) {
+		for _, reference := range []string{"oci", "index", "docker"} {
+			content, _, err := s.PullManifest(ctx, repo, reference)
+			require.NoError(t, err)
+
+			digest, err := s.PushManifest(ctx, repo, reference+"-detected", "", content)
+			require.NoError(t, err, reference)
+			assert.Equal(t, godigest.FromBytes(content).String(), digest, reference)
+		}
+	})
+}
+
+func TestPullManifestNotFound(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+
+	repo := "example.com/models/llama3"
+	_, _, err = s.PullManifest(ctx, repo, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	config := pushBlob(t, s, repo, ocispec.MediaTypeImageConfig, []byte(`{}`))
+	layer := pushBlob(t, s, repo, ocispec.MediaTypeImageLayer, []byte("layer"))
+	_, err = s.PushManifest(ctx, repo, "latest", ocispec.MediaTypeImageManifest, marshalManifest(t, ocispec.MediaTypeImageManifest, config, layer))
+	require.NoError(t, err)
+
+	_, _, err = s.PullManifest(ctx, repo, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = s.PullManifest(ctx, "invalid repo", "latest")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestManifestMediaType(t *testing.T) {
+	assert.Equal(t, dockerManifestMediaType, manifestMediaType(dockerManifestMediaType, []byte(`{}`)))
+	assert.Equal(t, ocispec.MediaTypeImageIndex, manifestMediaType("", []byte(`{"mediaType":"application/vnd.oci.image.index.v1+json"}`)))
+	assert.Equal(t, ocispec.MediaTypeImageManifest, manifestMediaType("", []byte(`{"schemaVersion":2}`)))
+}
+
+func TestListAndDeleteUploads(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStorage(t.TempDir())
+	require.NoError(t, err)
+
+	uploads, err := s.ListUploads(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, uploads)
+
+	// the committed blob does not leave any upload.
+	pushBlob(t, s, "example.com/models/committed", ocispec.MediaTypeImageLayer, []byte("committed"))
+
+	// the interrupted upload is left without committing.
+	repository, err := s.repository(ctx, "example.com/models/interrupted")
+	require.NoError(t, err)
+	writer, err := repository.Blobs(ctx).Create(ctx)
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	uploads, err = s.ListUploads(ctx)
+	require.NoError(t, err)
+	require.Len(t, uploads, 1)
+	assert.Equal(t, "example.com/models/interrupted", uploads[0].Repository)
+	assert.Equal(t, writer.ID(), uploads[0].ID)
+	assert.Equal(t, int64(len("partial")), uploads[0].Size)
+	assert.False(t, uploads[0].StartedAt.IsZero())
+
+	require.NoError(t, s.DeleteUpload(ctx, uploads[0].Repository, uploads[0].ID))
+	uploads, err = s.ListUploads(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, uploads)
+
+	assert.Error(t, s.DeleteUpload(ctx, "example.com/models/interrupted", "../../_layers"))
+}
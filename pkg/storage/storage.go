@@ -21,8 +21,17 @@ import (
 	"io"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/modelpack/modctl/pkg/storage/distribution"
 )
 
+// ErrNotFound is returned when the manifest or tag is not found in the storage.
+var ErrNotFound = distribution.ErrNotFound
+
+// Upload is the in-progress upload in the storage, which is left by the
+// interrupted push or build.
+type Upload = distribution.Upload
+
 // Option is the option wrapper for modifying the storage options.
 type Option func(*Options)
 
@@ -36,8 +45,9 @@ type Options struct {
 type Storage interface {
 	// PullManifest pulls the manifest from the storage.
 	PullManifest(ctx context.Context, repo, reference string) ([]byte, string, error)
-	// PushManifest pushes the manifest to the storage.
-	PushManifest(ctx context.Context, repo, reference string, body []byte) (string, error)
+	// PushManifest pushes the manifest of the media type to the storage, the media type is detected
+	// from the manifest if it is empty.
+	PushManifest(ctx context.Context, repo, reference, mediaType string, body []byte) (string, error)
 	// StatManifest stats the manifest in the storage.
 	StatManifest(ctx context.Context, repo, digest string) (bool, error)
 	// DeleteManifest deletes the manifest from the storage.
@@ -58,6 +68,10 @@ type Storage interface {
 	PerformGC(ctx context.Context, dryRun, removeUntagged bool) error
 	// PerformPurgeUploads performs the purge uploads in the storage to free up the space.
 	PerformPurgeUploads(ctx context.Context, dryRun bool) error
+	// ListUploads lists the in-progress uploads in the storage.
+	ListUploads(ctx context.Context) ([]Upload, error)
+	// DeleteUpload deletes the in-progress upload of the repository from the storage.
+	DeleteUpload(ctx context.Context, repo, id string) error
 }
 
 // WithRootDir sets the root directory of the storage.
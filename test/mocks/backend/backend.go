@@ -24,7 +24,11 @@ import (
 
 	context "context"
 
+	distribution "github.com/modelpack/modctl/pkg/storage/distribution"
+
 	mock "github.com/stretchr/testify/mock"
+
+	modelfile "github.com/modelpack/modctl/pkg/config/modelfile"
 )
 
 // Backend is an autogenerated mock type for the Backend type
@@ -40,6 +44,56 @@ func (_m *Backend) EXPECT() *Backend_Expecter {
 	return &Backend_Expecter{mock: &_m.Mock}
 }
 
+// ApplyDelta provides a mock function with given fields: ctx, base, delta, target, cfg
+func (_m *Backend) ApplyDelta(ctx context.Context, base string, delta string, target string, cfg *config.Delta) error {
+	ret := _m.Called(ctx, base, delta, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyDelta")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *config.Delta) error); ok {
+		r0 = rf(ctx, base, delta, target, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_ApplyDelta_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ApplyDelta'
+type Backend_ApplyDelta_Call struct {
+	*mock.Call
+}
+
+// ApplyDelta is a helper method to define mock.On call
+//   - ctx context.Context
+//   - base string
+//   - delta string
+//   - target string
+//   - cfg *config.Delta
+func (_e *Backend_Expecter) ApplyDelta(ctx interface{}, base interface{}, delta interface{}, target interface{}, cfg interface{}) *Backend_ApplyDelta_Call {
+	return &Backend_ApplyDelta_Call{Call: _e.mock.On("ApplyDelta", ctx, base, delta, target, cfg)}
+}
+
+func (_c *Backend_ApplyDelta_Call) Run(run func(ctx context.Context, base string, delta string, target string, cfg *config.Delta)) *Backend_ApplyDelta_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(*config.Delta))
+	})
+	return _c
+}
+
+func (_c *Backend_ApplyDelta_Call) Return(_a0 error) *Backend_ApplyDelta_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_ApplyDelta_Call) RunAndReturn(run func(context.Context, string, string, string, *config.Delta) error) *Backend_ApplyDelta_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Attach provides a mock function with given fields: ctx, filepath, cfg
 func (_m *Backend) Attach(ctx context.Context, filepath string, cfg *config.Attach) error {
 	ret := _m.Called(ctx, filepath, cfg)
@@ -138,6 +192,381 @@ func (_c *Backend_Build_Call) RunAndReturn(run func(context.Context, string, str
 	return _c
 }
 
+// BuildFromFiles provides a mock function with given fields: ctx, modelfilePath, files, target, cfg
+func (_m *Backend) BuildFromFiles(ctx context.Context, modelfilePath string, files map[string][]byte, target string, cfg *config.Build) error {
+	ret := _m.Called(ctx, modelfilePath, files, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BuildFromFiles")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string][]byte, string, *config.Build) error); ok {
+		r0 = rf(ctx, modelfilePath, files, target, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_BuildFromFiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BuildFromFiles'
+type Backend_BuildFromFiles_Call struct {
+	*mock.Call
+}
+
+// BuildFromFiles is a helper method to define mock.On call
+//   - ctx context.Context
+//   - modelfilePath string
+//   - files map[string][]byte
+//   - target string
+//   - cfg *config.Build
+func (_e *Backend_Expecter) BuildFromFiles(ctx interface{}, modelfilePath interface{}, files interface{}, target interface{}, cfg interface{}) *Backend_BuildFromFiles_Call {
+	return &Backend_BuildFromFiles_Call{Call: _e.mock.On("BuildFromFiles", ctx, modelfilePath, files, target, cfg)}
+}
+
+func (_c *Backend_BuildFromFiles_Call) Run(run func(ctx context.Context, modelfilePath string, files map[string][]byte, target string, cfg *config.Build)) *Backend_BuildFromFiles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(map[string][]byte), args[3].(string), args[4].(*config.Build))
+	})
+	return _c
+}
+
+func (_c *Backend_BuildFromFiles_Call) Return(_a0 error) *Backend_BuildFromFiles_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_BuildFromFiles_Call) RunAndReturn(run func(context.Context, string, map[string][]byte, string, *config.Build) error) *Backend_BuildFromFiles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BuildFromHF provides a mock function with given fields: ctx, modelURL, target, cfg
+func (_m *Backend) BuildFromHF(ctx context.Context, modelURL string, target string, cfg *config.Build) error {
+	ret := _m.Called(ctx, modelURL, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BuildFromHF")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *config.Build) error); ok {
+		r0 = rf(ctx, modelURL, target, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_BuildFromHF_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BuildFromHF'
+type Backend_BuildFromHF_Call struct {
+	*mock.Call
+}
+
+// BuildFromHF is a helper method to define mock.On call
+//   - ctx context.Context
+//   - modelURL string
+//   - target string
+//   - cfg *config.Build
+func (_e *Backend_Expecter) BuildFromHF(ctx interface{}, modelURL interface{}, target interface{}, cfg interface{}) *Backend_BuildFromHF_Call {
+	return &Backend_BuildFromHF_Call{Call: _e.mock.On("BuildFromHF", ctx, modelURL, target, cfg)}
+}
+
+func (_c *Backend_BuildFromHF_Call) Run(run func(ctx context.Context, modelURL string, target string, cfg *config.Build)) *Backend_BuildFromHF_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(*config.Build))
+	})
+	return _c
+}
+
+func (_c *Backend_BuildFromHF_Call) Return(_a0 error) *Backend_BuildFromHF_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_BuildFromHF_Call) RunAndReturn(run func(context.Context, string, string, *config.Build) error) *Backend_BuildFromHF_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CacheWarm provides a mock function with given fields: ctx, target, cfg
+func (_m *Backend) CacheWarm(ctx context.Context, target string, cfg *config.CacheWarm) (*backend.CacheWarmReport, error) {
+	ret := _m.Called(ctx, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheWarm")
+	}
+
+	var r0 *backend.CacheWarmReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.CacheWarm) (*backend.CacheWarmReport, error)); ok {
+		return rf(ctx, target, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.CacheWarm) *backend.CacheWarmReport); ok {
+		r0 = rf(ctx, target, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*backend.CacheWarmReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *config.CacheWarm) error); ok {
+		r1 = rf(ctx, target, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_CacheWarm_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CacheWarm'
+type Backend_CacheWarm_Call struct {
+	*mock.Call
+}
+
+// CacheWarm is a helper method to define mock.On call
+//   - ctx context.Context
+//   - target string
+//   - cfg *config.CacheWarm
+func (_e *Backend_Expecter) CacheWarm(ctx interface{}, target interface{}, cfg interface{}) *Backend_CacheWarm_Call {
+	return &Backend_CacheWarm_Call{Call: _e.mock.On("CacheWarm", ctx, target, cfg)}
+}
+
+func (_c *Backend_CacheWarm_Call) Run(run func(ctx context.Context, target string, cfg *config.CacheWarm)) *Backend_CacheWarm_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.CacheWarm))
+	})
+	return _c
+}
+
+func (_c *Backend_CacheWarm_Call) Return(_a0 *backend.CacheWarmReport, _a1 error) *Backend_CacheWarm_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_CacheWarm_Call) RunAndReturn(run func(context.Context, string, *config.CacheWarm) (*backend.CacheWarmReport, error)) *Backend_CacheWarm_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CleanUploads provides a mock function with given fields: ctx, cfg
+func (_m *Backend) CleanUploads(ctx context.Context, cfg *config.CleanUploads) ([]distribution.Upload, error) {
+	ret := _m.Called(ctx, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CleanUploads")
+	}
+
+	var r0 []distribution.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *config.CleanUploads) ([]distribution.Upload, error)); ok {
+		return rf(ctx, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *config.CleanUploads) []distribution.Upload); ok {
+		r0 = rf(ctx, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]distribution.Upload)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *config.CleanUploads) error); ok {
+		r1 = rf(ctx, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_CleanUploads_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CleanUploads'
+type Backend_CleanUploads_Call struct {
+	*mock.Call
+}
+
+// CleanUploads is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cfg *config.CleanUploads
+func (_e *Backend_Expecter) CleanUploads(ctx interface{}, cfg interface{}) *Backend_CleanUploads_Call {
+	return &Backend_CleanUploads_Call{Call: _e.mock.On("CleanUploads", ctx, cfg)}
+}
+
+func (_c *Backend_CleanUploads_Call) Run(run func(ctx context.Context, cfg *config.CleanUploads)) *Backend_CleanUploads_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*config.CleanUploads))
+	})
+	return _c
+}
+
+func (_c *Backend_CleanUploads_Call) Return(_a0 []distribution.Upload, _a1 error) *Backend_CleanUploads_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_CleanUploads_Call) RunAndReturn(run func(context.Context, *config.CleanUploads) ([]distribution.Upload, error)) *Backend_CleanUploads_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delta provides a mock function with given fields: ctx, base, derived, target, cfg
+func (_m *Backend) Delta(ctx context.Context, base string, derived string, target string, cfg *config.Delta) error {
+	ret := _m.Called(ctx, base, derived, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delta")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *config.Delta) error); ok {
+		r0 = rf(ctx, base, derived, target, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_Delta_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delta'
+type Backend_Delta_Call struct {
+	*mock.Call
+}
+
+// Delta is a helper method to define mock.On call
+//   - ctx context.Context
+//   - base string
+//   - derived string
+//   - target string
+//   - cfg *config.Delta
+func (_e *Backend_Expecter) Delta(ctx interface{}, base interface{}, derived interface{}, target interface{}, cfg interface{}) *Backend_Delta_Call {
+	return &Backend_Delta_Call{Call: _e.mock.On("Delta", ctx, base, derived, target, cfg)}
+}
+
+func (_c *Backend_Delta_Call) Run(run func(ctx context.Context, base string, derived string, target string, cfg *config.Delta)) *Backend_Delta_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(*config.Delta))
+	})
+	return _c
+}
+
+func (_c *Backend_Delta_Call) Return(_a0 error) *Backend_Delta_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_Delta_Call) RunAndReturn(run func(context.Context, string, string, string, *config.Delta) error) *Backend_Delta_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Detach provides a mock function with given fields: ctx, source, filepath, cfg
+func (_m *Backend) Detach(ctx context.Context, source string, filepath string, cfg *config.Detach) error {
+	ret := _m.Called(ctx, source, filepath, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Detach")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *config.Detach) error); ok {
+		r0 = rf(ctx, source, filepath, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_Detach_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Detach'
+type Backend_Detach_Call struct {
+	*mock.Call
+}
+
+// Detach is a helper method to define mock.On call
+//   - ctx context.Context
+//   - source string
+//   - filepath string
+//   - cfg *config.Detach
+func (_e *Backend_Expecter) Detach(ctx interface{}, source interface{}, filepath interface{}, cfg interface{}) *Backend_Detach_Call {
+	return &Backend_Detach_Call{Call: _e.mock.On("Detach", ctx, source, filepath, cfg)}
+}
+
+func (_c *Backend_Detach_Call) Run(run func(ctx context.Context, source string, filepath string, cfg *config.Detach)) *Backend_Detach_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(*config.Detach))
+	})
+	return _c
+}
+
+func (_c *Backend_Detach_Call) Return(_a0 error) *Backend_Detach_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_Detach_Call) RunAndReturn(run func(context.Context, string, string, *config.Detach) error) *Backend_Detach_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DiskUsage provides a mock function with given fields: ctx
+func (_m *Backend) DiskUsage(ctx context.Context) (*backend.DiskUsage, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiskUsage")
+	}
+
+	var r0 *backend.DiskUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*backend.DiskUsage, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *backend.DiskUsage); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*backend.DiskUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_DiskUsage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DiskUsage'
+type Backend_DiskUsage_Call struct {
+	*mock.Call
+}
+
+// DiskUsage is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Backend_Expecter) DiskUsage(ctx interface{}) *Backend_DiskUsage_Call {
+	return &Backend_DiskUsage_Call{Call: _e.mock.On("DiskUsage", ctx)}
+}
+
+func (_c *Backend_DiskUsage_Call) Run(run func(ctx context.Context)) *Backend_DiskUsage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Backend_DiskUsage_Call) Return(_a0 *backend.DiskUsage, _a1 error) *Backend_DiskUsage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_DiskUsage_Call) RunAndReturn(run func(context.Context) (*backend.DiskUsage, error)) *Backend_DiskUsage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Extract provides a mock function with given fields: ctx, target, cfg
 func (_m *Backend) Extract(ctx context.Context, target string, cfg *config.Extract) error {
 	ret := _m.Called(ctx, target, cfg)
@@ -186,50 +615,230 @@ func (_c *Backend_Extract_Call) RunAndReturn(run func(context.Context, string, *
 	return _c
 }
 
+// ExtractModelfile provides a mock function with given fields: ctx, target, cfg
+func (_m *Backend) ExtractModelfile(ctx context.Context, target string, cfg *modelfile.ExtractConfig) ([]byte, error) {
+	ret := _m.Called(ctx, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtractModelfile")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *modelfile.ExtractConfig) ([]byte, error)); ok {
+		return rf(ctx, target, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *modelfile.ExtractConfig) []byte); ok {
+		r0 = rf(ctx, target, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *modelfile.ExtractConfig) error); ok {
+		r1 = rf(ctx, target, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_ExtractModelfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExtractModelfile'
+type Backend_ExtractModelfile_Call struct {
+	*mock.Call
+}
+
+// ExtractModelfile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - target string
+//   - cfg *modelfile.ExtractConfig
+func (_e *Backend_Expecter) ExtractModelfile(ctx interface{}, target interface{}, cfg interface{}) *Backend_ExtractModelfile_Call {
+	return &Backend_ExtractModelfile_Call{Call: _e.mock.On("ExtractModelfile", ctx, target, cfg)}
+}
+
+func (_c *Backend_ExtractModelfile_Call) Run(run func(ctx context.Context, target string, cfg *modelfile.ExtractConfig)) *Backend_ExtractModelfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*modelfile.ExtractConfig))
+	})
+	return _c
+}
+
+func (_c *Backend_ExtractModelfile_Call) Return(_a0 []byte, _a1 error) *Backend_ExtractModelfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_ExtractModelfile_Call) RunAndReturn(run func(context.Context, string, *modelfile.ExtractConfig) ([]byte, error)) *Backend_ExtractModelfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Fetch provides a mock function with given fields: ctx, target, cfg
 func (_m *Backend) Fetch(ctx context.Context, target string, cfg *config.Fetch) error {
 	ret := _m.Called(ctx, target, cfg)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Fetch")
+		panic("no return value specified for Fetch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Fetch) error); ok {
+		r0 = rf(ctx, target, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_Fetch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fetch'
+type Backend_Fetch_Call struct {
+	*mock.Call
+}
+
+// Fetch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - target string
+//   - cfg *config.Fetch
+func (_e *Backend_Expecter) Fetch(ctx interface{}, target interface{}, cfg interface{}) *Backend_Fetch_Call {
+	return &Backend_Fetch_Call{Call: _e.mock.On("Fetch", ctx, target, cfg)}
+}
+
+func (_c *Backend_Fetch_Call) Run(run func(ctx context.Context, target string, cfg *config.Fetch)) *Backend_Fetch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.Fetch))
+	})
+	return _c
+}
+
+func (_c *Backend_Fetch_Call) Return(_a0 error) *Backend_Fetch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_Fetch_Call) RunAndReturn(run func(context.Context, string, *config.Fetch) error) *Backend_Fetch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Fsck provides a mock function with given fields: ctx, target, cfg
+func (_m *Backend) Fsck(ctx context.Context, target string, cfg *config.Fsck) (*backend.FsckReport, error) {
+	ret := _m.Called(ctx, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Fsck")
+	}
+
+	var r0 *backend.FsckReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Fsck) (*backend.FsckReport, error)); ok {
+		return rf(ctx, target, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Fsck) *backend.FsckReport); ok {
+		r0 = rf(ctx, target, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*backend.FsckReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *config.Fsck) error); ok {
+		r1 = rf(ctx, target, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_Fsck_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fsck'
+type Backend_Fsck_Call struct {
+	*mock.Call
+}
+
+// Fsck is a helper method to define mock.On call
+//   - ctx context.Context
+//   - target string
+//   - cfg *config.Fsck
+func (_e *Backend_Expecter) Fsck(ctx interface{}, target interface{}, cfg interface{}) *Backend_Fsck_Call {
+	return &Backend_Fsck_Call{Call: _e.mock.On("Fsck", ctx, target, cfg)}
+}
+
+func (_c *Backend_Fsck_Call) Run(run func(ctx context.Context, target string, cfg *config.Fsck)) *Backend_Fsck_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.Fsck))
+	})
+	return _c
+}
+
+func (_c *Backend_Fsck_Call) Return(_a0 *backend.FsckReport, _a1 error) *Backend_Fsck_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_Fsck_Call) RunAndReturn(run func(context.Context, string, *config.Fsck) (*backend.FsckReport, error)) *Backend_Fsck_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, target, cfg
+func (_m *Backend) Get(ctx context.Context, target string, cfg *config.Get) (interface{}, error) {
+	ret := _m.Called(ctx, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Fetch) error); ok {
+	var r0 interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Get) (interface{}, error)); ok {
+		return rf(ctx, target, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Get) interface{}); ok {
 		r0 = rf(ctx, target, cfg)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, *config.Get) error); ok {
+		r1 = rf(ctx, target, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// Backend_Fetch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fetch'
-type Backend_Fetch_Call struct {
+// Backend_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type Backend_Get_Call struct {
 	*mock.Call
 }
 
-// Fetch is a helper method to define mock.On call
+// Get is a helper method to define mock.On call
 //   - ctx context.Context
 //   - target string
-//   - cfg *config.Fetch
-func (_e *Backend_Expecter) Fetch(ctx interface{}, target interface{}, cfg interface{}) *Backend_Fetch_Call {
-	return &Backend_Fetch_Call{Call: _e.mock.On("Fetch", ctx, target, cfg)}
+//   - cfg *config.Get
+func (_e *Backend_Expecter) Get(ctx interface{}, target interface{}, cfg interface{}) *Backend_Get_Call {
+	return &Backend_Get_Call{Call: _e.mock.On("Get", ctx, target, cfg)}
 }
 
-func (_c *Backend_Fetch_Call) Run(run func(ctx context.Context, target string, cfg *config.Fetch)) *Backend_Fetch_Call {
+func (_c *Backend_Get_Call) Run(run func(ctx context.Context, target string, cfg *config.Get)) *Backend_Get_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(*config.Fetch))
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.Get))
 	})
 	return _c
 }
 
-func (_c *Backend_Fetch_Call) Return(_a0 error) *Backend_Fetch_Call {
-	_c.Call.Return(_a0)
+func (_c *Backend_Get_Call) Return(_a0 interface{}, _a1 error) *Backend_Get_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Backend_Fetch_Call) RunAndReturn(run func(context.Context, string, *config.Fetch) error) *Backend_Fetch_Call {
+func (_c *Backend_Get_Call) RunAndReturn(run func(context.Context, string, *config.Get) (interface{}, error)) *Backend_Get_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -294,9 +903,9 @@ func (_c *Backend_Inspect_Call) RunAndReturn(run func(context.Context, string, *
 	return _c
 }
 
-// List provides a mock function with given fields: ctx
-func (_m *Backend) List(ctx context.Context) ([]*backend.ModelArtifact, error) {
-	ret := _m.Called(ctx)
+// List provides a mock function with given fields: ctx, cfg
+func (_m *Backend) List(ctx context.Context, cfg *config.List) ([]*backend.ModelArtifact, error) {
+	ret := _m.Called(ctx, cfg)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
@@ -304,19 +913,19 @@ func (_m *Backend) List(ctx context.Context) ([]*backend.ModelArtifact, error) {
 
 	var r0 []*backend.ModelArtifact
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]*backend.ModelArtifact, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *config.List) ([]*backend.ModelArtifact, error)); ok {
+		return rf(ctx, cfg)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []*backend.ModelArtifact); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, *config.List) []*backend.ModelArtifact); ok {
+		r0 = rf(ctx, cfg)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*backend.ModelArtifact)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, *config.List) error); ok {
+		r1 = rf(ctx, cfg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -331,13 +940,14 @@ type Backend_List_Call struct {
 
 // List is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *Backend_Expecter) List(ctx interface{}) *Backend_List_Call {
-	return &Backend_List_Call{Call: _e.mock.On("List", ctx)}
+//   - cfg *config.List
+func (_e *Backend_Expecter) List(ctx interface{}, cfg interface{}) *Backend_List_Call {
+	return &Backend_List_Call{Call: _e.mock.On("List", ctx, cfg)}
 }
 
-func (_c *Backend_List_Call) Run(run func(ctx context.Context)) *Backend_List_Call {
+func (_c *Backend_List_Call) Run(run func(ctx context.Context, cfg *config.List)) *Backend_List_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(*config.List))
 	})
 	return _c
 }
@@ -347,7 +957,66 @@ func (_c *Backend_List_Call) Return(_a0 []*backend.ModelArtifact, _a1 error) *Ba
 	return _c
 }
 
-func (_c *Backend_List_Call) RunAndReturn(run func(context.Context) ([]*backend.ModelArtifact, error)) *Backend_List_Call {
+func (_c *Backend_List_Call) RunAndReturn(run func(context.Context, *config.List) ([]*backend.ModelArtifact, error)) *Backend_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Load provides a mock function with given fields: ctx, cfg
+func (_m *Backend) Load(ctx context.Context, cfg *config.Load) ([]string, error) {
+	ret := _m.Called(ctx, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Load")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *config.Load) ([]string, error)); ok {
+		return rf(ctx, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *config.Load) []string); ok {
+		r0 = rf(ctx, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *config.Load) error); ok {
+		r1 = rf(ctx, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_Load_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Load'
+type Backend_Load_Call struct {
+	*mock.Call
+}
+
+// Load is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cfg *config.Load
+func (_e *Backend_Expecter) Load(ctx interface{}, cfg interface{}) *Backend_Load_Call {
+	return &Backend_Load_Call{Call: _e.mock.On("Load", ctx, cfg)}
+}
+
+func (_c *Backend_Load_Call) Run(run func(ctx context.Context, cfg *config.Load)) *Backend_Load_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*config.Load))
+	})
+	return _c
+}
+
+func (_c *Backend_Load_Call) Return(_a0 []string, _a1 error) *Backend_Load_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_Load_Call) RunAndReturn(run func(context.Context, *config.Load) ([]string, error)) *Backend_Load_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -449,17 +1118,67 @@ func (_c *Backend_Logout_Call) RunAndReturn(run func(context.Context, string) er
 	return _c
 }
 
-// Prune provides a mock function with given fields: ctx, dryRun, removeUntagged
-func (_m *Backend) Prune(ctx context.Context, dryRun bool, removeUntagged bool) error {
-	ret := _m.Called(ctx, dryRun, removeUntagged)
+// Move provides a mock function with given fields: ctx, source, oldPath, newPath, cfg
+func (_m *Backend) Move(ctx context.Context, source string, oldPath string, newPath string, cfg *config.Move) error {
+	ret := _m.Called(ctx, source, oldPath, newPath, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Move")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *config.Move) error); ok {
+		r0 = rf(ctx, source, oldPath, newPath, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_Move_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Move'
+type Backend_Move_Call struct {
+	*mock.Call
+}
+
+// Move is a helper method to define mock.On call
+//   - ctx context.Context
+//   - source string
+//   - oldPath string
+//   - newPath string
+//   - cfg *config.Move
+func (_e *Backend_Expecter) Move(ctx interface{}, source interface{}, oldPath interface{}, newPath interface{}, cfg interface{}) *Backend_Move_Call {
+	return &Backend_Move_Call{Call: _e.mock.On("Move", ctx, source, oldPath, newPath, cfg)}
+}
+
+func (_c *Backend_Move_Call) Run(run func(ctx context.Context, source string, oldPath string, newPath string, cfg *config.Move)) *Backend_Move_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(*config.Move))
+	})
+	return _c
+}
+
+func (_c *Backend_Move_Call) Return(_a0 error) *Backend_Move_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_Move_Call) RunAndReturn(run func(context.Context, string, string, string, *config.Move) error) *Backend_Move_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Prune provides a mock function with given fields: ctx, cfg
+func (_m *Backend) Prune(ctx context.Context, cfg *config.Prune) error {
+	ret := _m.Called(ctx, cfg)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Prune")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, bool, bool) error); ok {
-		r0 = rf(ctx, dryRun, removeUntagged)
+	if rf, ok := ret.Get(0).(func(context.Context, *config.Prune) error); ok {
+		r0 = rf(ctx, cfg)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -474,15 +1193,14 @@ type Backend_Prune_Call struct {
 
 // Prune is a helper method to define mock.On call
 //   - ctx context.Context
-//   - dryRun bool
-//   - removeUntagged bool
-func (_e *Backend_Expecter) Prune(ctx interface{}, dryRun interface{}, removeUntagged interface{}) *Backend_Prune_Call {
-	return &Backend_Prune_Call{Call: _e.mock.On("Prune", ctx, dryRun, removeUntagged)}
+//   - cfg *config.Prune
+func (_e *Backend_Expecter) Prune(ctx interface{}, cfg interface{}) *Backend_Prune_Call {
+	return &Backend_Prune_Call{Call: _e.mock.On("Prune", ctx, cfg)}
 }
 
-func (_c *Backend_Prune_Call) Run(run func(ctx context.Context, dryRun bool, removeUntagged bool)) *Backend_Prune_Call {
+func (_c *Backend_Prune_Call) Run(run func(ctx context.Context, cfg *config.Prune)) *Backend_Prune_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(bool), args[2].(bool))
+		run(args[0].(context.Context), args[1].(*config.Prune))
 	})
 	return _c
 }
@@ -492,7 +1210,7 @@ func (_c *Backend_Prune_Call) Return(_a0 error) *Backend_Prune_Call {
 	return _c
 }
 
-func (_c *Backend_Prune_Call) RunAndReturn(run func(context.Context, bool, bool) error) *Backend_Prune_Call {
+func (_c *Backend_Prune_Call) RunAndReturn(run func(context.Context, *config.Prune) error) *Backend_Prune_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -650,6 +1368,174 @@ func (_c *Backend_Remove_Call) RunAndReturn(run func(context.Context, string) (s
 	return _c
 }
 
+// SBOM provides a mock function with given fields: ctx, target, cfg
+func (_m *Backend) SBOM(ctx context.Context, target string, cfg *config.SBOM) ([]byte, error) {
+	ret := _m.Called(ctx, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SBOM")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.SBOM) ([]byte, error)); ok {
+		return rf(ctx, target, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.SBOM) []byte); ok {
+		r0 = rf(ctx, target, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *config.SBOM) error); ok {
+		r1 = rf(ctx, target, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_SBOM_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SBOM'
+type Backend_SBOM_Call struct {
+	*mock.Call
+}
+
+// SBOM is a helper method to define mock.On call
+//   - ctx context.Context
+//   - target string
+//   - cfg *config.SBOM
+func (_e *Backend_Expecter) SBOM(ctx interface{}, target interface{}, cfg interface{}) *Backend_SBOM_Call {
+	return &Backend_SBOM_Call{Call: _e.mock.On("SBOM", ctx, target, cfg)}
+}
+
+func (_c *Backend_SBOM_Call) Run(run func(ctx context.Context, target string, cfg *config.SBOM)) *Backend_SBOM_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.SBOM))
+	})
+	return _c
+}
+
+func (_c *Backend_SBOM_Call) Return(_a0 []byte, _a1 error) *Backend_SBOM_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_SBOM_Call) RunAndReturn(run func(context.Context, string, *config.SBOM) ([]byte, error)) *Backend_SBOM_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: ctx, target, cfg
+func (_m *Backend) Save(ctx context.Context, target string, cfg *config.Save) error {
+	ret := _m.Called(ctx, target, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Save) error); ok {
+		r0 = rf(ctx, target, cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Backend_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type Backend_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - target string
+//   - cfg *config.Save
+func (_e *Backend_Expecter) Save(ctx interface{}, target interface{}, cfg interface{}) *Backend_Save_Call {
+	return &Backend_Save_Call{Call: _e.mock.On("Save", ctx, target, cfg)}
+}
+
+func (_c *Backend_Save_Call) Run(run func(ctx context.Context, target string, cfg *config.Save)) *Backend_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.Save))
+	})
+	return _c
+}
+
+func (_c *Backend_Save_Call) Return(_a0 error) *Backend_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Backend_Save_Call) RunAndReturn(run func(context.Context, string, *config.Save) error) *Backend_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SyncCheck provides a mock function with given fields: ctx, repo, cfg
+func (_m *Backend) SyncCheck(ctx context.Context, repo string, cfg *config.SyncCheck) (*backend.SyncCheckReport, error) {
+	ret := _m.Called(ctx, repo, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SyncCheck")
+	}
+
+	var r0 *backend.SyncCheckReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.SyncCheck) (*backend.SyncCheckReport, error)); ok {
+		return rf(ctx, repo, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.SyncCheck) *backend.SyncCheckReport); ok {
+		r0 = rf(ctx, repo, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*backend.SyncCheckReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *config.SyncCheck) error); ok {
+		r1 = rf(ctx, repo, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_SyncCheck_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncCheck'
+type Backend_SyncCheck_Call struct {
+	*mock.Call
+}
+
+// SyncCheck is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - cfg *config.SyncCheck
+func (_e *Backend_Expecter) SyncCheck(ctx interface{}, repo interface{}, cfg interface{}) *Backend_SyncCheck_Call {
+	return &Backend_SyncCheck_Call{Call: _e.mock.On("SyncCheck", ctx, repo, cfg)}
+}
+
+func (_c *Backend_SyncCheck_Call) Run(run func(ctx context.Context, repo string, cfg *config.SyncCheck)) *Backend_SyncCheck_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.SyncCheck))
+	})
+	return _c
+}
+
+func (_c *Backend_SyncCheck_Call) Return(_a0 *backend.SyncCheckReport, _a1 error) *Backend_SyncCheck_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_SyncCheck_Call) RunAndReturn(run func(context.Context, string, *config.SyncCheck) (*backend.SyncCheckReport, error)) *Backend_SyncCheck_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Tag provides a mock function with given fields: ctx, source, target
 func (_m *Backend) Tag(ctx context.Context, source string, target string) error {
 	ret := _m.Called(ctx, source, target)
@@ -746,6 +1632,66 @@ func (_c *Backend_Upload_Call) RunAndReturn(run func(context.Context, string, *c
 	return _c
 }
 
+// Whoami provides a mock function with given fields: ctx, registry, cfg
+func (_m *Backend) Whoami(ctx context.Context, registry string, cfg *config.Whoami) (*backend.Identity, error) {
+	ret := _m.Called(ctx, registry, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Whoami")
+	}
+
+	var r0 *backend.Identity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Whoami) (*backend.Identity, error)); ok {
+		return rf(ctx, registry, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *config.Whoami) *backend.Identity); ok {
+		r0 = rf(ctx, registry, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*backend.Identity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *config.Whoami) error); ok {
+		r1 = rf(ctx, registry, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Backend_Whoami_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Whoami'
+type Backend_Whoami_Call struct {
+	*mock.Call
+}
+
+// Whoami is a helper method to define mock.On call
+//   - ctx context.Context
+//   - registry string
+//   - cfg *config.Whoami
+func (_e *Backend_Expecter) Whoami(ctx interface{}, registry interface{}, cfg interface{}) *Backend_Whoami_Call {
+	return &Backend_Whoami_Call{Call: _e.mock.On("Whoami", ctx, registry, cfg)}
+}
+
+func (_c *Backend_Whoami_Call) Run(run func(ctx context.Context, registry string, cfg *config.Whoami)) *Backend_Whoami_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*config.Whoami))
+	})
+	return _c
+}
+
+func (_c *Backend_Whoami_Call) Return(_a0 *backend.Identity, _a1 error) *Backend_Whoami_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Backend_Whoami_Call) RunAndReturn(run func(context.Context, string, *config.Whoami) (*backend.Identity, error)) *Backend_Whoami_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewBackend creates a new instance of Backend. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewBackend(t interface {
@@ -19,6 +19,8 @@
 package build
 
 import (
+	archiver "github.com/modelpack/modctl/pkg/archiver"
+
 	context "context"
 
 	hooks "github.com/modelpack/modctl/pkg/backend/build/hooks"
@@ -161,6 +163,67 @@ func (_c *Builder_BuildLayer_Call) RunAndReturn(run func(context.Context, string
 	return _c
 }
 
+// BuildLayerFromArchive provides a mock function with given fields: ctx, mediaType, _a2, name, destPath, _a5
+func (_m *Builder) BuildLayerFromArchive(ctx context.Context, mediaType string, _a2 *archiver.Archive, name string, destPath string, _a5 hooks.Hooks) (specs_gov1.Descriptor, error) {
+	ret := _m.Called(ctx, mediaType, _a2, name, destPath, _a5)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BuildLayerFromArchive")
+	}
+
+	var r0 specs_gov1.Descriptor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *archiver.Archive, string, string, hooks.Hooks) (specs_gov1.Descriptor, error)); ok {
+		return rf(ctx, mediaType, _a2, name, destPath, _a5)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *archiver.Archive, string, string, hooks.Hooks) specs_gov1.Descriptor); ok {
+		r0 = rf(ctx, mediaType, _a2, name, destPath, _a5)
+	} else {
+		r0 = ret.Get(0).(specs_gov1.Descriptor)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *archiver.Archive, string, string, hooks.Hooks) error); ok {
+		r1 = rf(ctx, mediaType, _a2, name, destPath, _a5)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Builder_BuildLayerFromArchive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BuildLayerFromArchive'
+type Builder_BuildLayerFromArchive_Call struct {
+	*mock.Call
+}
+
+// BuildLayerFromArchive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - mediaType string
+//   - _a2 *archiver.Archive
+//   - name string
+//   - destPath string
+//   - _a5 hooks.Hooks
+func (_e *Builder_Expecter) BuildLayerFromArchive(ctx interface{}, mediaType interface{}, _a2 interface{}, name interface{}, destPath interface{}, _a5 interface{}) *Builder_BuildLayerFromArchive_Call {
+	return &Builder_BuildLayerFromArchive_Call{Call: _e.mock.On("BuildLayerFromArchive", ctx, mediaType, _a2, name, destPath, _a5)}
+}
+
+func (_c *Builder_BuildLayerFromArchive_Call) Run(run func(ctx context.Context, mediaType string, _a2 *archiver.Archive, name string, destPath string, _a5 hooks.Hooks)) *Builder_BuildLayerFromArchive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*archiver.Archive), args[3].(string), args[4].(string), args[5].(hooks.Hooks))
+	})
+	return _c
+}
+
+func (_c *Builder_BuildLayerFromArchive_Call) Return(_a0 specs_gov1.Descriptor, _a1 error) *Builder_BuildLayerFromArchive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Builder_BuildLayerFromArchive_Call) RunAndReturn(run func(context.Context, string, *archiver.Archive, string, string, hooks.Hooks) (specs_gov1.Descriptor, error)) *Builder_BuildLayerFromArchive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // BuildManifest provides a mock function with given fields: ctx, layers, config, annotations, _a4
 func (_m *Builder) BuildManifest(ctx context.Context, layers []specs_gov1.Descriptor, config specs_gov1.Descriptor, annotations map[string]string, _a4 hooks.Hooks) (specs_gov1.Descriptor, error) {
 	ret := _m.Called(ctx, layers, config, annotations, _a4)
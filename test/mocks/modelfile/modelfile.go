@@ -403,6 +403,51 @@ func (_c *Modelfile_GetFormat_Call) RunAndReturn(run func() string) *Modelfile_G
 	return _c
 }
 
+// GetFrom provides a mock function with no fields
+func (_m *Modelfile) GetFrom() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFrom")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Modelfile_GetFrom_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFrom'
+type Modelfile_GetFrom_Call struct {
+	*mock.Call
+}
+
+// GetFrom is a helper method to define mock.On call
+func (_e *Modelfile_Expecter) GetFrom() *Modelfile_GetFrom_Call {
+	return &Modelfile_GetFrom_Call{Call: _e.mock.On("GetFrom")}
+}
+
+func (_c *Modelfile_GetFrom_Call) Run(run func()) *Modelfile_GetFrom_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Modelfile_GetFrom_Call) Return(_a0 string) *Modelfile_GetFrom_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Modelfile_GetFrom_Call) RunAndReturn(run func() string) *Modelfile_GetFrom_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetModels provides a mock function with no fields
 func (_m *Modelfile) GetModels() []string {
 	ret := _m.Called()
@@ -495,6 +540,53 @@ func (_c *Modelfile_GetName_Call) RunAndReturn(run func() string) *Modelfile_Get
 	return _c
 }
 
+// GetParameters provides a mock function with no fields
+func (_m *Modelfile) GetParameters() map[string][]string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetParameters")
+	}
+
+	var r0 map[string][]string
+	if rf, ok := ret.Get(0).(func() map[string][]string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]string)
+		}
+	}
+
+	return r0
+}
+
+// Modelfile_GetParameters_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetParameters'
+type Modelfile_GetParameters_Call struct {
+	*mock.Call
+}
+
+// GetParameters is a helper method to define mock.On call
+func (_e *Modelfile_Expecter) GetParameters() *Modelfile_GetParameters_Call {
+	return &Modelfile_GetParameters_Call{Call: _e.mock.On("GetParameters")}
+}
+
+func (_c *Modelfile_GetParameters_Call) Run(run func()) *Modelfile_GetParameters_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Modelfile_GetParameters_Call) Return(_a0 map[string][]string) *Modelfile_GetParameters_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Modelfile_GetParameters_Call) RunAndReturn(run func() map[string][]string) *Modelfile_GetParameters_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetParamsize provides a mock function with no fields
 func (_m *Modelfile) GetParamsize() string {
 	ret := _m.Called()
@@ -630,6 +722,96 @@ func (_c *Modelfile_GetQuantization_Call) RunAndReturn(run func() string) *Model
 	return _c
 }
 
+// GetSystem provides a mock function with no fields
+func (_m *Modelfile) GetSystem() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSystem")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Modelfile_GetSystem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSystem'
+type Modelfile_GetSystem_Call struct {
+	*mock.Call
+}
+
+// GetSystem is a helper method to define mock.On call
+func (_e *Modelfile_Expecter) GetSystem() *Modelfile_GetSystem_Call {
+	return &Modelfile_GetSystem_Call{Call: _e.mock.On("GetSystem")}
+}
+
+func (_c *Modelfile_GetSystem_Call) Run(run func()) *Modelfile_GetSystem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Modelfile_GetSystem_Call) Return(_a0 string) *Modelfile_GetSystem_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Modelfile_GetSystem_Call) RunAndReturn(run func() string) *Modelfile_GetSystem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTemplate provides a mock function with no fields
+func (_m *Modelfile) GetTemplate() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTemplate")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Modelfile_GetTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTemplate'
+type Modelfile_GetTemplate_Call struct {
+	*mock.Call
+}
+
+// GetTemplate is a helper method to define mock.On call
+func (_e *Modelfile_Expecter) GetTemplate() *Modelfile_GetTemplate_Call {
+	return &Modelfile_GetTemplate_Call{Call: _e.mock.On("GetTemplate")}
+}
+
+func (_c *Modelfile_GetTemplate_Call) Run(run func()) *Modelfile_GetTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Modelfile_GetTemplate_Call) Return(_a0 string) *Modelfile_GetTemplate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Modelfile_GetTemplate_Call) RunAndReturn(run func() string) *Modelfile_GetTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewModelfile creates a new instance of Modelfile. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewModelfile(t interface {
@@ -20,6 +20,9 @@ package storage
 
 import (
 	context "context"
+
+	distribution "github.com/modelpack/modctl/pkg/storage/distribution"
+
 	io "io"
 
 	mock "github.com/stretchr/testify/mock"
@@ -88,6 +91,54 @@ func (_c *Storage_DeleteManifest_Call) RunAndReturn(run func(context.Context, st
 	return _c
 }
 
+// DeleteUpload provides a mock function with given fields: ctx, repo, id
+func (_m *Storage) DeleteUpload(ctx context.Context, repo string, id string) error {
+	ret := _m.Called(ctx, repo, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUpload")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, repo, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Storage_DeleteUpload_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUpload'
+type Storage_DeleteUpload_Call struct {
+	*mock.Call
+}
+
+// DeleteUpload is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - id string
+func (_e *Storage_Expecter) DeleteUpload(ctx interface{}, repo interface{}, id interface{}) *Storage_DeleteUpload_Call {
+	return &Storage_DeleteUpload_Call{Call: _e.mock.On("DeleteUpload", ctx, repo, id)}
+}
+
+func (_c *Storage_DeleteUpload_Call) Run(run func(ctx context.Context, repo string, id string)) *Storage_DeleteUpload_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Storage_DeleteUpload_Call) Return(_a0 error) *Storage_DeleteUpload_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Storage_DeleteUpload_Call) RunAndReturn(run func(context.Context, string, string) error) *Storage_DeleteUpload_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListRepositories provides a mock function with given fields: ctx
 func (_m *Storage) ListRepositories(ctx context.Context) ([]string, error) {
 	ret := _m.Called(ctx)
@@ -205,6 +256,64 @@ func (_c *Storage_ListTags_Call) RunAndReturn(run func(context.Context, string)
 	return _c
 }
 
+// ListUploads provides a mock function with given fields: ctx
+func (_m *Storage) ListUploads(ctx context.Context) ([]distribution.Upload, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUploads")
+	}
+
+	var r0 []distribution.Upload
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]distribution.Upload, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []distribution.Upload); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]distribution.Upload)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Storage_ListUploads_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUploads'
+type Storage_ListUploads_Call struct {
+	*mock.Call
+}
+
+// ListUploads is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Storage_Expecter) ListUploads(ctx interface{}) *Storage_ListUploads_Call {
+	return &Storage_ListUploads_Call{Call: _e.mock.On("ListUploads", ctx)}
+}
+
+func (_c *Storage_ListUploads_Call) Run(run func(ctx context.Context)) *Storage_ListUploads_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Storage_ListUploads_Call) Return(_a0 []distribution.Upload, _a1 error) *Storage_ListUploads_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Storage_ListUploads_Call) RunAndReturn(run func(context.Context) ([]distribution.Upload, error)) *Storage_ListUploads_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // MountBlob provides a mock function with given fields: ctx, fromRepo, toRepo, desc
 func (_m *Storage) MountBlob(ctx context.Context, fromRepo string, toRepo string, desc v1.Descriptor) error {
 	ret := _m.Called(ctx, fromRepo, toRepo, desc)
@@ -542,9 +651,9 @@ func (_c *Storage_PushBlob_Call) RunAndReturn(run func(context.Context, string,
 	return _c
 }
 
-// PushManifest provides a mock function with given fields: ctx, repo, reference, body
-func (_m *Storage) PushManifest(ctx context.Context, repo string, reference string, body []byte) (string, error) {
-	ret := _m.Called(ctx, repo, reference, body)
+// PushManifest provides a mock function with given fields: ctx, repo, reference, mediaType, body
+func (_m *Storage) PushManifest(ctx context.Context, repo string, reference string, mediaType string, body []byte) (string, error) {
+	ret := _m.Called(ctx, repo, reference, mediaType, body)
 
 	if len(ret) == 0 {
 		panic("no return value specified for PushManifest")
@@ -552,17 +661,17 @@ func (_m *Storage) PushManifest(ctx context.Context, repo string, reference stri
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, []byte) (string, error)); ok {
-		return rf(ctx, repo, reference, body)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []byte) (string, error)); ok {
+		return rf(ctx, repo, reference, mediaType, body)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, []byte) string); ok {
-		r0 = rf(ctx, repo, reference, body)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []byte) string); ok {
+		r0 = rf(ctx, repo, reference, mediaType, body)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, []byte) error); ok {
-		r1 = rf(ctx, repo, reference, body)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []byte) error); ok {
+		r1 = rf(ctx, repo, reference, mediaType, body)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -579,14 +688,15 @@ type Storage_PushManifest_Call struct {
 //   - ctx context.Context
 //   - repo string
 //   - reference string
+//   - mediaType string
 //   - body []byte
-func (_e *Storage_Expecter) PushManifest(ctx interface{}, repo interface{}, reference interface{}, body interface{}) *Storage_PushManifest_Call {
-	return &Storage_PushManifest_Call{Call: _e.mock.On("PushManifest", ctx, repo, reference, body)}
+func (_e *Storage_Expecter) PushManifest(ctx interface{}, repo interface{}, reference interface{}, mediaType interface{}, body interface{}) *Storage_PushManifest_Call {
+	return &Storage_PushManifest_Call{Call: _e.mock.On("PushManifest", ctx, repo, reference, mediaType, body)}
 }
 
-func (_c *Storage_PushManifest_Call) Run(run func(ctx context.Context, repo string, reference string, body []byte)) *Storage_PushManifest_Call {
+func (_c *Storage_PushManifest_Call) Run(run func(ctx context.Context, repo string, reference string, mediaType string, body []byte)) *Storage_PushManifest_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].([]byte))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].([]byte))
 	})
 	return _c
 }
@@ -596,7 +706,7 @@ func (_c *Storage_PushManifest_Call) Return(_a0 string, _a1 error) *Storage_Push
 	return _c
 }
 
-func (_c *Storage_PushManifest_Call) RunAndReturn(run func(context.Context, string, string, []byte) (string, error)) *Storage_PushManifest_Call {
+func (_c *Storage_PushManifest_Call) RunAndReturn(run func(context.Context, string, string, string, []byte) (string, error)) *Storage_PushManifest_Call {
 	_c.Call.Return(run)
 	return _c
 }